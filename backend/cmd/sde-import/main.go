@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"eve-profit2/internal/config"
+	"eve-profit2/internal/sdeimport"
+)
+
+// maxListedTypes limits how many type IDs are printed per change category
+const maxListedTypes = 20
+
+// downloadClient bounds connection setup so an unreachable mirror fails fast; the
+// body itself may take minutes and is only limited by the signal context
+var downloadClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   15 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+	},
+}
+
+func main() {
+	cfg := config.Load()
+
+	source := flag.String("source", "", "Official SDE archive (JSONL or YAML zip), local path or http(s) URL")
+	dbPath := flag.String("db", cfg.SDEDatabasePath, "SQLite database to create or update")
+	force := flag.Bool("force", false, "Re-import even if the archive was already imported")
+	flag.Parse()
+
+	if *source == "" {
+		fmt.Println("Usage: sde-import -source <eve-online-static-data-*.zip> [-db path] [-force]")
+		fmt.Println("Download the archive from CCP's developer portal (https://developers.eveonline.com).")
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	archivePath, cleanup, err := resolveSource(ctx, *source)
+	if err != nil {
+		fmt.Printf("Failed to fetch SDE archive: %v\n", err)
+		os.Exit(1)
+	}
+	defer cleanup()
+
+	fmt.Printf("Importing %s into %s...\n", *source, *dbPath)
+	start := time.Now()
+
+	report, err := sdeimport.Import(sdeimport.Options{
+		SourcePath:   archivePath,
+		DatabasePath: *dbPath,
+		Force:        *force,
+	})
+	if err != nil {
+		fmt.Printf("SDE import failed: %v\n", err)
+		cleanup()
+		os.Exit(1)
+	}
+
+	printReport(report, time.Since(start))
+}
+
+// resolveSource downloads URL sources into a temporary file
+func resolveSource(ctx context.Context, source string) (string, func(), error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return source, func() {}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	file, err := os.CreateTemp("", "sde-*.zip")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return file.Name(), cleanup, nil
+}
+
+func printReport(report *sdeimport.Report, duration time.Duration) {
+	if report.Skipped {
+		fmt.Printf("SDE build %d (checksum %s) is already imported, use -force to re-import\n",
+			report.BuildNumber, report.Checksum)
+		return
+	}
+
+	fmt.Printf("Imported SDE build %d in %v\n", report.BuildNumber, duration.Round(time.Millisecond))
+	fmt.Printf("Checksum: %s\n", report.Checksum)
+	fmt.Printf("Types: %d added, %d removed, %d changed\n",
+		len(report.AddedTypes), len(report.RemovedTypes), len(report.ChangedTypes))
+	printTypeIDs("Added", report.AddedTypes)
+	printTypeIDs("Removed", report.RemovedTypes)
+	printTypeIDs("Changed", report.ChangedTypes)
+
+	for table, rows := range report.Rows {
+		fmt.Printf("  %-28s %d rows\n", table, rows)
+	}
+}
+
+func printTypeIDs(label string, typeIDs []int32) {
+	if len(typeIDs) == 0 {
+		return
+	}
+
+	listed := typeIDs
+	suffix := ""
+	if len(listed) > maxListedTypes {
+		listed = listed[:maxListedTypes]
+		suffix = fmt.Sprintf(" ... (%d more)", len(typeIDs)-maxListedTypes)
+	}
+	fmt.Printf("  %s: %v%s\n", label, listed, suffix)
+}
@@ -10,7 +10,11 @@ import (
 	"time"
 
 	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/cache"
 	"eve-profit2/internal/config"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/notify"
 	"eve-profit2/internal/repository"
 	"eve-profit2/internal/service"
 	"eve-profit2/pkg/esi"
@@ -19,6 +23,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(config.Load(), os.Args[2:]))
+	}
+
 	fmt.Println("Starting EVE Profit Calculator Backend...")
 
 	// Load configuration
@@ -39,9 +47,211 @@ func main() {
 		esi.WithRetryAttempts(3),
 	)
 
+	// Initialize caches
+	cacheManager, err := cache.NewCacheManager()
+	if err != nil {
+		fmt.Printf("Failed to initialize cache manager: %v\n", err)
+		os.Exit(1)
+	}
+	defer cacheManager.Close()
+
 	// Initialize services
-	itemService := service.NewItemService(sdeRepo, nil)
+	itemService := service.NewItemService(sdeRepo, cacheManager)
+	if err := itemService.BuildSearchIndex(); err != nil {
+		// Search falls back to building the index on first use
+		fmt.Printf("Warning: failed to build item search index: %v\n", err)
+	}
 	marketService := service.NewMarketService(esiClient)
+	arbitrageService := service.NewArbitrageService(
+		esiClient,
+		service.HubsForRegions(cfg.ArbitrageRegions),
+		cfg.ArbitrageSalesTax,
+	)
+
+	industryService := service.NewIndustryService(sdeRepo, marketService, esiClient)
+	reprocessingService := service.NewReprocessingService(sdeRepo, marketService)
+	variantService := service.NewVariantService(sdeRepo, marketService)
+
+	// The app database keeps accounts and, with a configured key, encrypted SSO tokens
+	appDB, err := openAppDatabase(cfg)
+	if err != nil {
+		fmt.Printf("Failed to open app database: %v\n", err)
+		os.Exit(1)
+	}
+	defer appDB.Close()
+	if err := prepareAppSchema(appDB, cfg.AppDatabaseAutoMigrate); err != nil {
+		fmt.Printf("Failed to prepare app database schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Tokens live in the app database so background jobs keep working across restarts
+	var tokenStore service.TokenStore
+	switch {
+	case cfg.TokenEncryptionKey != "":
+		sqliteTokens, err := repository.NewSQLiteTokenStore(appDB, cfg.TokenEncryptionKey)
+		if err != nil {
+			fmt.Printf("Failed to initialize token store: %v\n", err)
+			os.Exit(1)
+		}
+		tokenStore = sqliteTokens
+	case cfg.TokenStoreMemory || cfg.ESIClientID == "":
+		// Without a client ID there are no SSO logins and thus no tokens to keep
+		fmt.Println("Warning: SSO tokens are kept in memory only and lost on restart")
+		tokenStore = service.NewMemoryTokenStore()
+	default:
+		fmt.Println("TOKEN_ENCRYPTION_KEY is required to store SSO tokens, set TOKEN_STORE_MEMORY=true to keep them in memory for development")
+		os.Exit(1)
+	}
+
+	accountStore, err := repository.NewAccountStore(appDB)
+	if err != nil {
+		fmt.Printf("Failed to initialize account store: %v\n", err)
+		os.Exit(1)
+	}
+	accountService := service.NewAccountService(accountStore)
+
+	ssoClient := esi.NewSSOClient(
+		cfg.ESIClientID,
+		cfg.ESIClientSecret,
+		cfg.ESICallbackURL,
+		esi.WithSSOEndpoints(cfg.EVESSOAuthorizeURL, cfg.EVESSOTokenURL),
+	)
+	authService := service.NewAuthService(ssoClient, tokenStore, cfg.ESIScopes).WithAccounts(accountService)
+
+	// Character data is loaded with the caller's or the persisted SSO token
+	characterService := service.NewCharacterService(esiClient, authService, cacheManager)
+	accountService.WithCharacterData(characterService)
+	characterHandler := handlers.NewCharacterHandler(characterService)
+	authHandler := handlers.NewAuthHandler(authService)
+
+	walletStore, err := repository.NewWalletStore(appDB)
+	if err != nil {
+		fmt.Printf("Failed to initialize wallet store: %v\n", err)
+		os.Exit(1)
+	}
+	walletService := service.NewWalletService(walletStore, esiClient, authService).WithMarketData(marketService)
+
+	// Undercut monitor judges sell order reprices against the synced purchase costs
+	undercutMonitor := service.NewUndercutMonitor(characterService, esiClient).
+		WithCostBasis(walletService).
+		WithTradeProfiles(characterService).
+		WithCharacters(authService)
+	if cfg.UndercutWebhookURL != "" {
+		undercutMonitor.WithNotifier(notify.NewWebhook(cfg.UndercutWebhookURL))
+	}
+
+	// Asset valuation prices character assets at a hub or with ESI average prices
+	valuationService := service.NewAssetValuationService(characterService, marketService, esiClient).WithItems(itemService)
+	consolidationService := service.NewConsolidationService(characterService, sdeRepo, marketService, itemService)
+
+	// Market snapshots keep intraday prices of tracked types beyond the market cache
+	snapshotStore, err := repository.NewMarketSnapshotStore(appDB)
+	if err != nil {
+		fmt.Printf("Failed to initialize market snapshot store: %v\n", err)
+		os.Exit(1)
+	}
+	marketArchive := service.NewMarketArchive(snapshotStore, marketService, cfg.MarketArchiveRegions, cfg.MarketArchiveTypes).
+		WithRetention(cfg.MarketArchiveRawRetention, cfg.MarketArchiveHourlyRetention)
+
+	// Diffs of consecutive order books reveal fills, modifications and cancellations
+	orderEventStore, err := repository.NewOrderEventStore(appDB)
+	if err != nil {
+		fmt.Printf("Failed to initialize order event store: %v\n", err)
+		os.Exit(1)
+	}
+	orderFlowService := service.NewOrderFlowService(snapshotStore, orderEventStore).WithRetention(cfg.OrderEventRetention)
+	if cfg.MarketArchiveOrderBooks {
+		marketArchive.WithOrderFlow(orderFlowService)
+	}
+
+	// Alert rules are evaluated against fresh market data and the undercut checks
+	alertStore, err := repository.NewAlertStore(appDB)
+	if err != nil {
+		fmt.Printf("Failed to initialize alert store: %v\n", err)
+		os.Exit(1)
+	}
+	alertEngine := service.NewAlertEngine(alertStore, marketService).
+		WithUndercuts(undercutMonitor).
+		WithItems(itemService).
+		WithCooldown(cfg.AlertDefaultCooldown)
+	if cfg.AlertWebhookURL != "" {
+		alertEngine.WithSink(models.AlertChannelWebhook, notify.NewWebhook(cfg.AlertWebhookURL))
+	}
+	if cfg.AlertDiscordWebhookURL != "" {
+		alertEngine.WithSink(models.AlertChannelDiscord, notify.NewDiscordWebhook(cfg.AlertDiscordWebhookURL))
+	}
+	if cfg.SMTPHost != "" {
+		alertEngine.WithSink(models.AlertChannelEmail, notify.NewEmailSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom,
+			notify.WithSMTPAuth(cfg.SMTPUsername, cfg.SMTPPassword),
+			notify.WithDefaultRecipient(cfg.AlertEmailTo),
+		))
+	}
+
+	// Watchlists are priced with one market data request per target region
+	watchlistStore, err := repository.NewWatchlistStore(appDB)
+	if err != nil {
+		fmt.Printf("Failed to initialize watchlist store: %v\n", err)
+		os.Exit(1)
+	}
+	watchlistService := service.NewWatchlistService(watchlistStore, marketService).
+		WithItems(itemService).
+		WithSearch(itemService)
+
+	// Price subscribers share the market cache, every fresh fetch is pushed to them
+	priceStream := service.NewPriceStream(marketService).WithItems(itemService).WithBuffer(cfg.PriceStreamBuffer)
+	marketService.OnUpdate(priceStream.Publish)
+
+	// Protected endpoints verify access tokens against the SSO key set
+	ssoAudiences := []string{esi.SSOAudience}
+	if cfg.ESIClientID != "" {
+		ssoAudiences = append(ssoAudiences, cfg.ESIClientID)
+	}
+	tokenVerifier := esi.NewJWKSVerifier(
+		cfg.EVESSOJWKSURL,
+		cfg.EVESSOIssuer,
+		ssoAudiences,
+		esi.WithJWKSRefreshInterval(cfg.EVESSOJWKSRefresh),
+	)
+
+	// Background jobs stop when the server shuts down
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if cfg.ArbitrageScanInterval > 0 {
+		arbitrageService.Start(jobCtx, cfg.ArbitrageScanInterval)
+	}
+	if cfg.TokenRefreshInterval > 0 {
+		authService.Start(jobCtx, cfg.TokenRefreshInterval)
+	}
+	if cfg.WalletSyncInterval > 0 {
+		walletService.Start(jobCtx, cfg.WalletSyncInterval)
+	}
+	if cfg.UndercutCheckInterval > 0 {
+		undercutMonitor.Start(jobCtx, cfg.UndercutCheckInterval)
+	}
+	if cfg.MarketArchiveInterval > 0 {
+		marketArchive.Start(jobCtx, cfg.MarketArchiveInterval)
+	}
+	if cfg.AlertCheckInterval > 0 {
+		alertEngine.Start(jobCtx, cfg.AlertCheckInterval)
+	}
+	if cfg.PriceStreamInterval > 0 {
+		priceStream.Start(jobCtx, cfg.PriceStreamInterval)
+	}
+
+	// Swap in a new SDE file without restarting and losing market caches
+	sdeRepo.OnSwap(func(version models.SDEVersion) {
+		if err := cacheManager.ResetSDEData(); err != nil {
+			fmt.Printf("Warning: failed to reset SDE cache: %v\n", err)
+		}
+		if err := itemService.ReloadSDE(); err != nil {
+			fmt.Printf("Warning: failed to rebuild item search index: %v\n", err)
+		}
+		consolidationService.ResetMap()
+	})
+	if cfg.SDEWatchInterval > 0 {
+		sdeRepo.Watch(jobCtx, cfg.SDEWatchInterval)
+	}
 
 	// Setup Gin router
 	if !cfg.DebugMode {
@@ -84,16 +294,15 @@ func main() {
 		})
 	})
 
+	// The default ESI_CALLBACK_URL points to /callback
+	router.GET("/callback", authHandler.HandleCallback)
+
 	// API routes
 	api := router.Group("/api/v1")
 	{
-		// Health check
-		api.GET("/health", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{
-				"status": "healthy",
-				"time":   time.Now(),
-			})
-		})
+		// Health check including the active SDE version
+		healthHandler := handlers.NewHealthHandler().WithSDEVersion(sdeRepo).WithAppSchema(appDB)
+		api.GET("/health", healthHandler.HealthCheck)
 
 		// Test ESI connection
 		api.GET("/esi/test", func(c *gin.Context) {
@@ -132,23 +341,120 @@ func main() {
 			})
 		})
 
-		// Auth placeholder
-		api.GET("/auth/login", func(c *gin.Context) {
-			// Placeholder usage to prevent unused variable warning
-			_ = marketService
-			c.JSON(http.StatusOK, gin.H{
-				"message":   "EVE SSO authentication endpoint",
-				"client_id": cfg.ESIClientID,
-				"callback":  cfg.ESICallbackURL,
-				"scopes":    cfg.ESIScopes,
-				"status":    "configured",
-			})
-		})
+		// EVE SSO login (authorization code flow with PKCE)
+		// A logged in caller links the new character to their account
+		api.GET("/auth/login", middleware.OptionalAuth(tokenVerifier), authHandler.InitiateLogin)
+		api.GET("/auth/callback", authHandler.HandleCallback)
+		api.POST("/auth/refresh", authHandler.RefreshToken)
+
+		// Multi-character account of the logged in character
+		accountHandler := handlers.NewAccountHandler(accountService)
+		account := api.Group("/account", middleware.RequireAuth(tokenVerifier))
+		account.GET("", accountHandler.GetAccount)
+		account.PUT("/active", accountHandler.SetActiveCharacter)
+		account.DELETE("/characters/:characterID", accountHandler.UnlinkCharacter)
+		account.GET("/wallet", accountHandler.GetWallet)
+		account.GET("/assets", accountHandler.GetAssets)
+		account.GET("/orders", accountHandler.GetOrders)
+
+		// Undercut checks of open orders against the regional order books
+		undercutHandler := handlers.NewUndercutHandler(undercutMonitor).WithAccounts(accountService)
+		account.GET("/undercuts", undercutHandler.GetAccountUndercuts)
+
+		// Net worth of the assets of one character or the whole account
+		valuationHandler := handlers.NewAssetValuationHandler(valuationService).WithAccounts(accountService)
+		account.GET("/networth", valuationHandler.GetAccountNetWorth)
+
+		// Plans for hauling scattered assets to a trade hub
+		consolidationHandler := handlers.NewConsolidationHandler(consolidationService).WithAccounts(accountService)
+		account.GET("/assets/consolidation", consolidationHandler.GetAccountPlan)
+
+		// Price alert rules of the logged in character
+		alertHandler := handlers.NewAlertHandler(alertEngine)
+		alerts := api.Group("/alerts", middleware.RequireAuth(tokenVerifier))
+		alerts.GET("", alertHandler.ListRules)
+		alerts.POST("", alertHandler.CreateRule)
+		alerts.GET("/:ruleID", alertHandler.GetRule)
+		alerts.PUT("/:ruleID", alertHandler.UpdateRule)
+		alerts.DELETE("/:ruleID", alertHandler.DeleteRule)
+
+		// Watchlists and saved item searches of the logged in character
+		watchlistHandler := handlers.NewWatchlistHandler(watchlistService)
+		watchlists := api.Group("/watchlists", middleware.RequireAuth(tokenVerifier))
+		watchlists.GET("", watchlistHandler.ListWatchlists)
+		watchlists.POST("", watchlistHandler.CreateWatchlist)
+		watchlists.GET("/:watchlistID", watchlistHandler.GetWatchlist)
+		watchlists.PUT("/:watchlistID", watchlistHandler.UpdateWatchlist)
+		watchlists.DELETE("/:watchlistID", watchlistHandler.DeleteWatchlist)
+		searches := api.Group("/searches", middleware.RequireAuth(tokenVerifier))
+		searches.GET("", watchlistHandler.ListSearches)
+		searches.POST("", watchlistHandler.CreateSearch)
+		searches.GET("/:searchID", watchlistHandler.GetSearch)
+		searches.PUT("/:searchID", watchlistHandler.UpdateSearch)
+		searches.DELETE("/:searchID", watchlistHandler.DeleteSearch)
+		searches.GET("/:searchID/results", watchlistHandler.RunSearch)
+
+		// Character endpoints require a token with the matching scope of a character linked to the same account
+		characters := api.Group("/characters/:characterID")
+		characterAccess := middleware.RequireCharacterAccess(accountService, "characterID")
+		characters.GET("/info", middleware.RequireAuth(tokenVerifier), characterAccess, characterHandler.GetCharacterInfo)
+		characters.GET("/assets", middleware.RequireScopes(tokenVerifier, "esi-assets.read_assets.v1"), characterAccess, characterHandler.GetAssets)
+		characters.GET("/wallet", middleware.RequireScopes(tokenVerifier, "esi-wallet.read_character_wallet.v1"), characterAccess, characterHandler.GetWallet)
+		characters.GET("/orders", middleware.RequireScopes(tokenVerifier, "esi-markets.read_character_orders.v1"), characterAccess, characterHandler.GetOrders)
+		characters.GET("/skills", middleware.RequireScopes(tokenVerifier, "esi-skills.read_skills.v1"), characterAccess, characterHandler.GetSkills)
+		characters.GET("/trade-profile", middleware.RequireScopes(tokenVerifier, "esi-skills.read_skills.v1"), characterAccess, characterHandler.GetTradeProfile)
+
+		// Synced wallet history and trading profit and loss
+		walletHandler := handlers.NewWalletHandler(walletService)
+		characters.POST("/wallet/sync", middleware.RequireScopes(tokenVerifier, "esi-wallet.read_character_wallet.v1"), characterAccess, walletHandler.SyncWallet)
+		characters.GET("/profit", middleware.RequireAuth(tokenVerifier), characterAccess, walletHandler.GetProfitLoss)
+		characters.GET("/assets/valuation", middleware.RequireScopes(tokenVerifier, "esi-assets.read_assets.v1"), characterAccess, valuationHandler.GetCharacterValuation)
+		characters.GET("/assets/consolidation", middleware.RequireScopes(tokenVerifier, "esi-assets.read_assets.v1"), characterAccess, consolidationHandler.GetCharacterPlan)
+		characters.GET("/orders/undercuts", middleware.RequireScopes(tokenVerifier, "esi-markets.read_character_orders.v1"), characterAccess, undercutHandler.GetCharacterUndercuts)
 
 		// Items API endpoints
 		itemsHandler := handlers.NewItemHandler(itemService)
 		api.GET("/items/:item_id", itemsHandler.GetItemDetails)
 		api.GET("/items/search", itemsHandler.SearchItems)
+		api.GET("/items/compare", itemsHandler.CompareItems)
+		api.GET("/items/:item_id/attributes", itemsHandler.GetItemAttributes)
+		api.GET("/items/categories", itemsHandler.GetCategories)
+		api.GET("/items/categories/:category_id/groups", itemsHandler.GetCategoryGroups)
+
+		// Meta variant price finder
+		variantHandler := handlers.NewVariantHandler(variantService)
+		api.GET("/items/:item_id/variants", variantHandler.GetVariants)
+
+		// Archived intraday price curves
+		marketArchiveHandler := handlers.NewMarketArchiveHandler(marketArchive)
+		api.GET("/items/:item_id/intraday", marketArchiveHandler.GetPriceCurve)
+
+		// Order events and traded volume inferred from archived order books
+		orderFlowHandler := handlers.NewOrderFlowHandler(orderFlowService)
+		api.GET("/items/:item_id/order-events", orderFlowHandler.GetOrderEvents)
+		api.GET("/items/:item_id/order-flow", orderFlowHandler.GetVelocity)
+
+		// Server-sent price updates of watched types
+		priceStreamHandler := handlers.NewPriceStreamHandler(priceStream)
+		api.GET("/market/stream", priceStreamHandler.StreamPrices)
+
+		// Market group hierarchy endpoints
+		api.GET("/market-groups", itemsHandler.GetMarketGroups)
+		api.GET("/market-groups/:market_group_id", itemsHandler.GetMarketGroup)
+		api.GET("/market-groups/:market_group_id/types", itemsHandler.GetMarketGroupTypes)
+
+		// Arbitrage API endpoints
+		arbitrageHandler := handlers.NewArbitrageHandler(arbitrageService)
+		api.GET("/arbitrage/opportunities", arbitrageHandler.GetOpportunities)
+		api.POST("/arbitrage/scan", arbitrageHandler.TriggerScan)
+
+		// Industry API endpoints
+		industryHandler := handlers.NewIndustryHandler(industryService)
+		api.POST("/industry/manufacturing", industryHandler.CalculateManufacturing)
+
+		// Reprocessing API endpoints
+		reprocessingHandler := handlers.NewReprocessingHandler(reprocessingService)
+		api.POST("/industry/reprocessing", reprocessingHandler.CalculateReprocessing)
 	}
 
 	// Start server
@@ -159,6 +465,8 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Open price streams would otherwise hold up the graceful shutdown
+	srv.RegisterOnShutdown(priceStream.Close)
 
 	// Graceful shutdown
 	go func() {
@@ -178,6 +486,7 @@ func main() {
 	<-quit
 
 	fmt.Println("Shutting down server...")
+	stopJobs()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
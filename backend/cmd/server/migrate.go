@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+
+	"eve-profit2/internal/config"
+	"eve-profit2/internal/repository"
+)
+
+// openAppDatabase opens the configured application database without migrating it
+func openAppDatabase(cfg *config.Config) (*repository.AppDB, error) {
+	dialect, err := repository.ParseDialect(cfg.AppDatabaseDriver)
+	if err != nil {
+		return nil, err
+	}
+	source := cfg.AppDatabasePath
+	if dialect == repository.DialectPostgres {
+		source = cfg.AppDatabaseURL
+	}
+	return repository.OpenAppDB(dialect, source)
+}
+
+// prepareAppSchema migrates the application database on startup or, with
+// auto migration disabled, refuses outdated schemas
+func prepareAppSchema(db *repository.AppDB, autoMigrate bool) error {
+	if !autoMigrate {
+		if err := db.CheckSchema(); err != nil {
+			return fmt.Errorf("%w, run `server migrate` first", err)
+		}
+		return nil
+	}
+
+	applied, err := db.Migrate()
+	for _, migration := range applied {
+		fmt.Printf("Applied app database migration %d: %s\n", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// runMigrate implements `server migrate` to apply pending migrations and
+// `server migrate status` to list them without changes
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) > 1 || (len(args) == 1 && args[0] != "status") {
+		fmt.Println("Usage: server migrate [status]")
+		return 2
+	}
+
+	db, err := openAppDatabase(cfg)
+	if err != nil {
+		fmt.Printf("Failed to open app database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if len(args) == 1 {
+		return printSchemaStatus(db)
+	}
+
+	applied, err := db.Migrate()
+	for _, migration := range applied {
+		fmt.Printf("Applied migration %d: %s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		fmt.Printf("Migration failed: %v\n", err)
+		return 1
+	}
+	if len(applied) == 0 {
+		fmt.Println("App database schema is up to date")
+	}
+	return 0
+}
+
+// printSchemaStatus lists all migrations of this build and whether they were applied
+func printSchemaStatus(db *repository.AppDB) int {
+	current, err := db.SchemaVersion()
+	if err != nil {
+		fmt.Printf("Failed to read schema version: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("App database (%s) at schema version %d of %d\n", db.Dialect(), current, repository.LatestSchemaVersion())
+	for _, migration := range repository.Migrations() {
+		state := "pending"
+		if migration.Version <= current {
+			state = "applied"
+		}
+		fmt.Printf("  %3d  %-8s %s\n", migration.Version, state, migration.Name)
+	}
+	return 0
+}
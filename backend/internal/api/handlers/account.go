@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountServiceInterface defines the contract for multi-character accounts
+type AccountServiceInterface interface {
+	GetAccount(characterID int32) (*models.Account, error)
+	SetActiveCharacter(authCharacterID, characterID int32) (*models.Account, error)
+	UnlinkCharacter(authCharacterID, characterID int32) (*models.Account, error)
+	GetAccountWallet(ctx context.Context, authCharacterID int32) (*service.AccountWallet, error)
+	GetAccountAssets(ctx context.Context, authCharacterID int32) (*service.AccountAssets, error)
+	GetAccountOrders(ctx context.Context, authCharacterID int32) (*service.AccountOrders, error)
+}
+
+// activeCharacterRequest is the body of an active character switch
+type activeCharacterRequest struct {
+	CharacterID int32 `json:"character_id"`
+}
+
+// AccountHandler serves the account of the authenticated character. All routes
+// must run behind middleware.RequireAuth.
+type AccountHandler struct {
+	accountService AccountServiceInterface
+}
+
+func NewAccountHandler(accountService AccountServiceInterface) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// GetAccount returns the account with all linked characters
+func (h *AccountHandler) GetAccount(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	account, err := h.accountService.GetAccount(characterID)
+	respondAccountResult(c, account, err)
+}
+
+// SetActiveCharacter switches the active character to another linked character
+func (h *AccountHandler) SetActiveCharacter(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	var req activeCharacterRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.CharacterID <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "character_id must be a positive number",
+		})
+		return
+	}
+
+	account, err := h.accountService.SetActiveCharacter(characterID, req.CharacterID)
+	respondAccountResult(c, account, err)
+}
+
+// UnlinkCharacter removes a character from the account
+func (h *AccountHandler) UnlinkCharacter(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	target, err := strconv.ParseInt(c.Param("characterID"), 10, 32)
+	if err != nil || target <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   ErrInvalidCharacterID,
+		})
+		return
+	}
+
+	account, err := h.accountService.UnlinkCharacter(characterID, int32(target))
+	respondAccountResult(c, account, err)
+}
+
+// GetWallet sums the wallets of all linked characters
+func (h *AccountHandler) GetWallet(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	wallet, err := h.accountService.GetAccountWallet(c.Request.Context(), characterID)
+	respondAccountResult(c, wallet, err)
+}
+
+// GetAssets sums the assets of all linked characters per type
+func (h *AccountHandler) GetAssets(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	assets, err := h.accountService.GetAccountAssets(c.Request.Context(), characterID)
+	respondAccountResult(c, assets, err)
+}
+
+// GetOrders lists the open orders of all linked characters
+func (h *AccountHandler) GetOrders(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	orders, err := h.accountService.GetAccountOrders(c.Request.Context(), characterID)
+	respondAccountResult(c, orders, err)
+}
+
+func respondAccountResult(c *gin.Context, data interface{}, err error) {
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// respondAccountError maps account service errors to HTTP status codes
+func respondAccountError(c *gin.Context, err error) {
+	status, message := http.StatusInternalServerError, "Internal server error"
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, service.ErrUnauthorized):
+		status, message = http.StatusUnauthorized, "Not authenticated"
+	case errors.Is(err, service.ErrCharacterNotLinked):
+		status, message = http.StatusForbidden, "Character is not linked to your account"
+	case errors.Is(err, service.ErrAccountNotFound):
+		status, message = http.StatusNotFound, "Account not found, please log in again"
+	case errors.Is(err, service.ErrCharacterDataUnavailable):
+		status, message = http.StatusNotImplemented, "Character data is not available yet"
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
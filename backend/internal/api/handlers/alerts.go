@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertEngineInterface defines the contract for managing alert rules
+type AlertEngineInterface interface {
+	ListRules(characterID int32) ([]models.AlertRule, error)
+	GetRule(characterID int32, ruleID int64) (*models.AlertRule, error)
+	CreateRule(characterID int32, rule models.AlertRule) (*models.AlertRule, error)
+	UpdateRule(characterID int32, ruleID int64, rule models.AlertRule) (*models.AlertRule, error)
+	DeleteRule(characterID int32, ruleID int64) error
+	Channels() []string
+}
+
+// alertRuleRequest is the body of a created or replaced alert rule
+type alertRuleRequest struct {
+	Name            string   `json:"name"`
+	TypeID          int32    `json:"type_id"`
+	Expression      string   `json:"expression" binding:"required"`
+	Channels        []string `json:"channels"`
+	Email           string   `json:"email"`
+	CooldownSeconds int      `json:"cooldown_seconds"`
+	Enabled         *bool    `json:"enabled"` // Defaults to true
+}
+
+func (r alertRuleRequest) rule() models.AlertRule {
+	enabled := r.Enabled == nil || *r.Enabled
+	return models.AlertRule{
+		Name:            r.Name,
+		TypeID:          r.TypeID,
+		Expression:      r.Expression,
+		Channels:        r.Channels,
+		Email:           r.Email,
+		CooldownSeconds: r.CooldownSeconds,
+		Enabled:         enabled,
+	}
+}
+
+// AlertHandler serves the alert rules of the authenticated character. All
+// routes must run behind middleware.RequireAuth.
+type AlertHandler struct {
+	alerts AlertEngineInterface
+}
+
+func NewAlertHandler(alerts AlertEngineInterface) *AlertHandler {
+	return &AlertHandler{
+		alerts: alerts,
+	}
+}
+
+// ListRules returns all alert rules and the configured notification channels
+func (h *AlertHandler) ListRules(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	rules, err := h.alerts.ListRules(characterID)
+	respondAlertResult(c, http.StatusOK, gin.H{"rules": rules, "channels": h.alerts.Channels()}, err)
+}
+
+// GetRule returns one alert rule with its trigger state
+func (h *AlertHandler) GetRule(c *gin.Context) {
+	characterID, ruleID, ok := alertRuleParams(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.alerts.GetRule(characterID, ruleID)
+	respondAlertResult(c, http.StatusOK, rule, err)
+}
+
+// CreateRule adds an alert rule such as {"type_id": 34, "expression": "Jita sell < 5"}
+func (h *AlertHandler) CreateRule(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	var req alertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "expression is required")
+		return
+	}
+
+	rule, err := h.alerts.CreateRule(characterID, req.rule())
+	respondAlertResult(c, http.StatusCreated, rule, err)
+}
+
+// UpdateRule replaces an alert rule and resets its trigger state
+func (h *AlertHandler) UpdateRule(c *gin.Context) {
+	characterID, ruleID, ok := alertRuleParams(c)
+	if !ok {
+		return
+	}
+
+	var req alertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "expression is required")
+		return
+	}
+
+	rule, err := h.alerts.UpdateRule(characterID, ruleID, req.rule())
+	respondAlertResult(c, http.StatusOK, rule, err)
+}
+
+// DeleteRule removes an alert rule
+func (h *AlertHandler) DeleteRule(c *gin.Context) {
+	characterID, ruleID, ok := alertRuleParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.alerts.DeleteRule(characterID, ruleID)
+	respondAlertResult(c, http.StatusOK, nil, err)
+}
+
+// alertRuleParams reads the authenticated character and the ruleID path
+// parameter and answers the request if either is missing
+func alertRuleParams(c *gin.Context) (int32, int64, bool) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return 0, 0, false
+	}
+	ruleID, err := strconv.ParseInt(c.Param("ruleID"), 10, 64)
+	if err != nil || ruleID <= 0 {
+		respondBadRequest(c, "Invalid rule ID format")
+		return 0, 0, false
+	}
+	return characterID, ruleID, true
+}
+
+// respondAlertResult answers with the data or maps alert errors to HTTP status codes
+func respondAlertResult(c *gin.Context, status int, data interface{}, err error) {
+	if err != nil {
+		if errors.Is(err, service.ErrAlertRuleNotFound) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Alert rule not found",
+			})
+			return
+		}
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
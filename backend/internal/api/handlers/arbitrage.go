@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArbitrageServiceInterface defines the contract for arbitrage scanning
+type ArbitrageServiceInterface interface {
+	GetOpportunities(page, pageSize int, minProfit float64) (*service.ArbitragePage, error)
+	TriggerScan() bool
+}
+
+type ArbitrageHandler struct {
+	arbitrageService ArbitrageServiceInterface
+}
+
+func NewArbitrageHandler(arbitrageService ArbitrageServiceInterface) *ArbitrageHandler {
+	return &ArbitrageHandler{
+		arbitrageService: arbitrageService,
+	}
+}
+
+// GetOpportunities returns a page of inter-hub arbitrage opportunities sorted by profit
+func (h *ArbitrageHandler) GetOpportunities(c *gin.Context) {
+	page, pageSize, err := parsePaging(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Invalid paging parameters",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	minProfit, err := parseOptionalFloat(c, "min_profit")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Invalid min_profit parameter",
+		})
+		return
+	}
+
+	result, err := h.arbitrageService.GetOpportunities(page, pageSize, minProfit)
+	if err != nil {
+		if errors.Is(err, service.ErrNoScanAvailable) {
+			c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+				Success: false,
+				Error:   "No arbitrage scan available yet",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// TriggerScan starts a new background scan
+func (h *ArbitrageHandler) TriggerScan(c *gin.Context) {
+	if !h.arbitrageService.TriggerScan() {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Arbitrage scan already in progress",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Message: "Arbitrage scan started",
+	})
+}
@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,142 +22,159 @@ const (
 	ErrCharacterWallet    = "Failed to get character wallet"
 	ErrCharacterOrders    = "Failed to get character orders"
 	ErrCharacterSkills    = "Failed to get character skills"
+	ErrTradeProfile       = "Failed to get trade profile"
 )
 
 // CharacterService defines the interface for character operations
 type CharacterService interface {
-	GetCharacterInfo(characterID int32) (interface{}, error)
-	GetCharacterAssets(characterID int32) (interface{}, error)
-	GetCharacterWallet(characterID int32) (interface{}, error)
-	GetCharacterOrders(characterID int32) (interface{}, error)
-	GetCharacterSkills(characterID int32) (interface{}, error)
+	GetCharacterInfo(ctx context.Context, characterID int32) (*models.Character, error)
+	GetCharacterAssets(ctx context.Context, characterID int32) ([]models.CharacterAsset, error)
+	GetCharacterWallet(ctx context.Context, characterID int32) (*models.CharacterWallet, error)
+	GetCharacterOrders(ctx context.Context, characterID int32) ([]models.CharacterOrder, error)
+	GetCharacterSkills(ctx context.Context, characterID int32) (*models.CharacterSkills, error)
+	GetTradeProfile(ctx context.Context, characterID int32) (*models.TradeProfile, error)
 }
 
 type CharacterHandler struct {
 	characterService CharacterService
 }
 
-func NewCharacterHandler(characterService interface{}) *CharacterHandler {
-	if service, ok := characterService.(CharacterService); ok {
-		return &CharacterHandler{characterService: service}
+func NewCharacterHandler(characterService CharacterService) *CharacterHandler {
+	return &CharacterHandler{
+		characterService: characterService,
 	}
-	// Return with nil service for now - Phase 4 implementation will add actual service
-	return &CharacterHandler{}
 }
 
 // GetCharacterInfo retrieves character information by ID
 func (h *CharacterHandler) GetCharacterInfo(c *gin.Context) {
 	characterID, err := h.extractCharacterIDFromPath(c)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
+		respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
 		return
 	}
 
 	if h.characterService == nil {
-		h.respondWithNotImplemented(c, "Character info endpoint")
+		respondWithNotImplemented(c, "Character info endpoint")
 		return
 	}
 
-	data, err := h.characterService.GetCharacterInfo(characterID)
+	data, err := h.characterService.GetCharacterInfo(characterRequestContext(c, characterID), characterID)
 	if err != nil {
-		h.respondWithError(c, http.StatusInternalServerError, ErrCharacterInfo, err)
+		h.respondWithServiceError(c, ErrCharacterInfo, err)
 		return
 	}
 
-	h.respondWithSuccess(c, data)
+	respondWithSuccess(c, data)
 }
 
 // GetAssets retrieves character assets
 func (h *CharacterHandler) GetAssets(c *gin.Context) {
 	characterID, err := h.extractCharacterIDFromPath(c)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
+		respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
 		return
 	}
 
 	if h.characterService == nil {
-		h.respondWithNotImplemented(c, "Character assets endpoint")
+		respondWithNotImplemented(c, "Character assets endpoint")
 		return
 	}
 
-	data, err := h.characterService.GetCharacterAssets(characterID)
+	data, err := h.characterService.GetCharacterAssets(characterRequestContext(c, characterID), characterID)
 	if err != nil {
-		h.respondWithError(c, http.StatusInternalServerError, ErrCharacterAssets, err)
+		h.respondWithServiceError(c, ErrCharacterAssets, err)
 		return
 	}
 
-	h.respondWithSuccess(c, data)
+	respondWithSuccess(c, data)
 }
 
 // GetWallet retrieves character wallet information
 func (h *CharacterHandler) GetWallet(c *gin.Context) {
 	characterID, err := h.extractCharacterIDFromPath(c)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
+		respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
 		return
 	}
 
 	if h.characterService == nil {
-		h.respondWithNotImplemented(c, "Character wallet endpoint")
+		respondWithNotImplemented(c, "Character wallet endpoint")
 		return
 	}
 
-	data, err := h.characterService.GetCharacterWallet(characterID)
+	data, err := h.characterService.GetCharacterWallet(characterRequestContext(c, characterID), characterID)
 	if err != nil {
-		h.respondWithError(c, http.StatusInternalServerError, ErrCharacterWallet, err)
+		h.respondWithServiceError(c, ErrCharacterWallet, err)
 		return
 	}
 
-	h.respondWithSuccess(c, data)
+	respondWithSuccess(c, data)
 }
 
 // GetOrders retrieves character market orders
 func (h *CharacterHandler) GetOrders(c *gin.Context) {
 	characterID, err := h.extractCharacterIDFromPath(c)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
+		respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
 		return
 	}
 
 	if h.characterService == nil {
-		h.respondWithNotImplemented(c, "Character orders endpoint")
+		respondWithNotImplemented(c, "Character orders endpoint")
 		return
 	}
 
-	data, err := h.characterService.GetCharacterOrders(characterID)
+	data, err := h.characterService.GetCharacterOrders(characterRequestContext(c, characterID), characterID)
 	if err != nil {
-		h.respondWithError(c, http.StatusInternalServerError, ErrCharacterOrders, err)
+		h.respondWithServiceError(c, ErrCharacterOrders, err)
 		return
 	}
 
-	h.respondWithSuccess(c, data)
+	respondWithSuccess(c, data)
 }
 
 // GetSkills retrieves character skills
 func (h *CharacterHandler) GetSkills(c *gin.Context) {
 	characterID, err := h.extractCharacterIDFromPath(c)
 	if err != nil {
-		h.respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
+		respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
 		return
 	}
 
 	if h.characterService == nil {
-		h.respondWithNotImplemented(c, "Character skills endpoint")
+		respondWithNotImplemented(c, "Character skills endpoint")
 		return
 	}
 
-	data, err := h.characterService.GetCharacterSkills(characterID)
+	data, err := h.characterService.GetCharacterSkills(characterRequestContext(c, characterID), characterID)
 	if err != nil {
-		h.respondWithError(c, http.StatusInternalServerError, ErrCharacterSkills, err)
+		h.respondWithServiceError(c, ErrCharacterSkills, err)
 		return
 	}
 
-	h.respondWithSuccess(c, data)
+	respondWithSuccess(c, data)
 }
 
-// InitiateLogin starts the EVE SSO login flow
-func (h *CharacterHandler) InitiateLogin(c *gin.Context) {
-	h.respondWithNotImplemented(c, "EVE SSO login endpoint")
+// GetTradeProfile returns order slots, remote order ranges and fees derived from the character's skills
+func (h *CharacterHandler) GetTradeProfile(c *gin.Context) {
+	characterID, err := h.extractCharacterIDFromPath(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, err)
+		return
+	}
+
+	if h.characterService == nil {
+		respondWithNotImplemented(c, "Trade profile endpoint")
+		return
+	}
+
+	data, err := h.characterService.GetTradeProfile(characterRequestContext(c, characterID), characterID)
+	if err != nil {
+		h.respondWithServiceError(c, ErrTradeProfile, err)
+		return
+	}
+
+	respondWithSuccess(c, data)
 }
 
 // Helper methods following DRY principle
@@ -176,15 +199,34 @@ func (h *CharacterHandler) extractCharacterIDFromPath(c *gin.Context) (int32, er
 }
 
 // respondWithError sends a standardized error response
-func (h *CharacterHandler) respondWithError(c *gin.Context, statusCode int, message string, err error) {
+func respondWithError(c *gin.Context, statusCode int, message string, err error) {
 	c.JSON(statusCode, gin.H{
 		"error":   message,
 		"details": err.Error(),
 	})
 }
 
+// characterRequestContext passes the caller's access token on when the caller
+// requests their own character. Linked characters use their persisted tokens.
+func characterRequestContext(c *gin.Context, characterID int32) context.Context {
+	ctx := c.Request.Context()
+	if authCharacterID, ok := middleware.CharacterIDFromContext(c); ok && authCharacterID == characterID {
+		ctx = service.ContextWithAccessToken(ctx, characterID, middleware.AccessTokenFromContext(c))
+	}
+	return ctx
+}
+
+// respondWithServiceError maps missing or revoked tokens to 401, all other failures to 500
+func (h *CharacterHandler) respondWithServiceError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, service.ErrUnauthorized) || errors.Is(err, service.ErrLoginRequired) {
+		status, message = http.StatusUnauthorized, "Character login required"
+	}
+	respondWithError(c, status, message, err)
+}
+
 // respondWithSuccess sends a standardized success response
-func (h *CharacterHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+func respondWithSuccess(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    data,
@@ -192,21 +234,9 @@ func (h *CharacterHandler) respondWithSuccess(c *gin.Context, data interface{})
 }
 
 // respondWithNotImplemented sends a standardized not implemented response
-func (h *CharacterHandler) respondWithNotImplemented(c *gin.Context, endpoint string) {
+func respondWithNotImplemented(c *gin.Context, endpoint string) {
 	c.JSON(http.StatusNotImplemented, gin.H{
 		"message": endpoint + " - not implemented yet (Phase 4)",
 		"phase":   "Phase 4 - API Handlers Implementation",
 	})
 }
-
-func (h *CharacterHandler) HandleCallback(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"message": "EVE SSO callback endpoint - not implemented yet",
-	})
-}
-
-func (h *CharacterHandler) RefreshToken(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Token refresh endpoint - not implemented yet",
-	})
-}
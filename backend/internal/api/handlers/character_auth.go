@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NonceCookieName holds the login nonce between InitiateLogin and the SSO callback
+const NonceCookieName = "eve_sso_nonce"
+
+// AuthServiceInterface defines the contract for the EVE SSO login flow
+type AuthServiceInterface interface {
+	BeginLogin(linkCharacterID int32) (*service.LoginRequest, error)
+	CompleteLogin(ctx context.Context, state, nonce, code string) (*models.AuthToken, error)
+	RefreshToken(ctx context.Context, characterID int32, accessToken string) (*models.AuthToken, error)
+}
+
+// LoginResponse is returned after a successful login or token refresh.
+// The refresh token never leaves the server.
+type LoginResponse struct {
+	CharacterID   int32    `json:"character_id"`
+	CharacterName string   `json:"character_name"`
+	AccessToken   string   `json:"access_token"`
+	TokenType     string   `json:"token_type"`
+	ExpiresAt     string   `json:"expires_at"`
+	Scopes        []string `json:"scopes"`
+}
+
+// refreshRequest is the body of a token refresh
+type refreshRequest struct {
+	CharacterID int32 `json:"character_id"`
+}
+
+// AuthHandler serves the EVE SSO login endpoints
+type AuthHandler struct {
+	authService AuthServiceInterface
+}
+
+func NewAuthHandler(authService AuthServiceInterface) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// InitiateLogin starts the EVE SSO login flow. The nonce is bound to the browser
+// with an HttpOnly cookie. With ?redirect=true the browser is sent to the SSO directly.
+// An already authenticated caller (see middleware.OptionalAuth) links the new
+// character to their account.
+func (h *AuthHandler) InitiateLogin(c *gin.Context) {
+	if h.authService == nil {
+		respondWithNotImplemented(c, "EVE SSO login endpoint")
+		return
+	}
+
+	linkCharacterID, _ := middleware.CharacterIDFromContext(c)
+	login, err := h.authService.BeginLogin(linkCharacterID)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to start login", err)
+		return
+	}
+
+	h.setNonceCookie(c, login.Nonce, int(service.LoginStateTTL.Seconds()))
+
+	if c.Query("redirect") == "true" {
+		c.Redirect(http.StatusFound, login.AuthorizationURL)
+		return
+	}
+	respondWithSuccess(c, login)
+}
+
+// HandleCallback completes the login after the SSO redirected back with code and state
+func (h *AuthHandler) HandleCallback(c *gin.Context) {
+	if h.authService == nil {
+		respondWithNotImplemented(c, "EVE SSO callback endpoint")
+		return
+	}
+
+	// The nonce is single use, whatever the outcome
+	nonce, _ := c.Cookie(NonceCookieName)
+	h.setNonceCookie(c, "", -1)
+
+	if ssoError := c.Query("error"); ssoError != "" {
+		respondWithError(c, http.StatusBadRequest, "Login was rejected by EVE SSO", errors.New(ssoError))
+		return
+	}
+
+	token, err := h.authService.CompleteLogin(c.Request.Context(), c.Query("state"), nonce, c.Query("code"))
+	if err != nil {
+		h.respondWithAuthError(c, "Failed to complete login", err)
+		return
+	}
+
+	respondWithSuccess(c, toLoginResponse(token))
+}
+
+// RefreshToken issues a new access token. The current access token of the
+// character must be sent as Bearer token.
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	if h.authService == nil {
+		respondWithNotImplemented(c, "Token refresh endpoint")
+		return
+	}
+
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.CharacterID <= 0 {
+		respondWithError(c, http.StatusBadRequest, ErrInvalidCharacterID, errors.New("character_id must be a positive number"))
+		return
+	}
+
+	token, err := h.authService.RefreshToken(c.Request.Context(), req.CharacterID, middleware.BearerToken(c.GetHeader("Authorization")))
+	if err != nil {
+		h.respondWithAuthError(c, "Failed to refresh token", err)
+		return
+	}
+
+	respondWithSuccess(c, toLoginResponse(token))
+}
+
+// respondWithAuthError maps auth service errors to HTTP status codes
+func (h *AuthHandler) respondWithAuthError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidLoginState), errors.Is(err, service.ErrInvalidInput):
+		respondWithError(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, service.ErrLoginRequired):
+		respondWithError(c, http.StatusUnauthorized, message, errors.New("the refresh token was revoked, please log in again"))
+	case errors.Is(err, service.ErrUnauthorized):
+		respondWithError(c, http.StatusUnauthorized, message, service.ErrUnauthorized)
+	case errors.Is(err, service.ErrSSORequest):
+		respondWithError(c, http.StatusBadGateway, message, service.ErrSSORequest)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// setNonceCookie sets or, with a negative maxAge, clears the login nonce cookie
+func (h *AuthHandler) setNonceCookie(c *gin.Context, nonce string, maxAge int) {
+	// Lax keeps the cookie on the top level redirect back from the SSO
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(NonceCookieName, nonce, maxAge, "/", "", c.Request.TLS != nil, true)
+}
+
+func toLoginResponse(token *models.AuthToken) LoginResponse {
+	return LoginResponse{
+		CharacterID:   token.CharacterID,
+		CharacterName: token.CharacterName,
+		AccessToken:   token.AccessToken,
+		TokenType:     token.TokenType,
+		ExpiresAt:     token.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"),
+		Scopes:        token.Scopes,
+	}
+}
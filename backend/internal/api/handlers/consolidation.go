@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsolidationServiceInterface defines the contract for planning asset moves to a hub
+type ConsolidationServiceInterface interface {
+	PlanCharacters(ctx context.Context, characterIDs []int32, opts service.ConsolidationOptions) (*service.ConsolidationPlan, error)
+}
+
+// ConsolidationHandler serves consolidation plans of a character or a whole account
+type ConsolidationHandler struct {
+	planner  ConsolidationServiceInterface
+	accounts AccountLookup
+}
+
+func NewConsolidationHandler(planner ConsolidationServiceInterface) *ConsolidationHandler {
+	return &ConsolidationHandler{
+		planner: planner,
+	}
+}
+
+// WithAccounts enables the account-wide plan over all linked characters
+func (h *ConsolidationHandler) WithAccounts(accounts AccountLookup) *ConsolidationHandler {
+	h.accounts = accounts
+	return h
+}
+
+// GetCharacterPlan plans moving the assets of one character to the hub
+func (h *ConsolidationHandler) GetCharacterPlan(c *gin.Context) {
+	characterID, ok := parseCharacterIDParam(c)
+	if !ok {
+		return
+	}
+
+	opts, err := parseConsolidationOptions(c)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	plan, err := h.planner.PlanCharacters(characterRequestContext(c, characterID), []int32{characterID}, opts)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    plan,
+	})
+}
+
+// GetAccountPlan plans moving the assets of all characters linked to the
+// authenticated character's account to the hub
+func (h *ConsolidationHandler) GetAccountPlan(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+	if h.accounts == nil {
+		respondAccountError(c, service.ErrCharacterDataUnavailable)
+		return
+	}
+
+	opts, err := parseConsolidationOptions(c)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	account, err := h.accounts.GetAccount(characterID)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+	characterIDs := make([]int32, len(account.Characters))
+	for i, character := range account.Characters {
+		characterIDs[i] = character.CharacterID
+	}
+
+	plan, err := h.planner.PlanCharacters(characterRequestContext(c, characterID), characterIDs, opts)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    plan,
+	})
+}
+
+// parseConsolidationOptions reads the hub, price_type and route query parameters
+func parseConsolidationOptions(c *gin.Context) (service.ConsolidationOptions, error) {
+	opts := service.ConsolidationOptions{PriceType: c.Query("price_type"), Route: c.Query("route")}
+
+	if name := c.Query("hub"); name != "" {
+		hub, ok := service.HubByName(name)
+		if !ok {
+			return opts, fmt.Errorf("%w: unknown hub %q", service.ErrInvalidInput, name)
+		}
+		opts.Hub = hub
+	}
+	return opts, nil
+}
@@ -4,20 +4,50 @@ import (
 	"net/http"
 	"time"
 
+	"eve-profit2/internal/models"
+
 	"github.com/gin-gonic/gin"
 )
 
-type HealthHandler struct{}
+// SDEVersionProvider reports the SDE version currently being served
+type SDEVersionProvider interface {
+	Version() models.SDEVersion
+}
+
+// AppSchemaProvider reports the migration state of the application database
+type AppSchemaProvider interface {
+	SchemaStatus() models.AppSchemaVersion
+}
+
+type HealthHandler struct {
+	sde       SDEVersionProvider
+	appSchema AppSchemaProvider
+}
 
 func NewHealthHandler() *HealthHandler {
 	return &HealthHandler{}
 }
 
+// WithSDEVersion adds the active SDE version to health responses
+func (h *HealthHandler) WithSDEVersion(sde SDEVersionProvider) *HealthHandler {
+	h.sde = sde
+	return h
+}
+
+// WithAppSchema adds the application database schema version to health
+// responses, a pending migration or unreachable database degrades the status
+func (h *HealthHandler) WithAppSchema(appSchema AppSchemaProvider) *HealthHandler {
+	h.appSchema = appSchema
+	return h
+}
+
 type HealthResponse struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Version   string    `json:"version"`
-	Service   string    `json:"service"`
+	Status    string                   `json:"status"`
+	Timestamp time.Time                `json:"timestamp"`
+	Version   string                   `json:"version"`
+	Service   string                   `json:"service"`
+	SDE       *models.SDEVersion       `json:"sde,omitempty"`
+	AppSchema *models.AppSchemaVersion `json:"app_schema,omitempty"`
 }
 
 func (h *HealthHandler) HealthCheck(c *gin.Context) {
@@ -28,5 +58,18 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 		Service:   "eve-profit2-backend",
 	}
 
+	if h.sde != nil {
+		version := h.sde.Version()
+		response.SDE = &version
+	}
+
+	if h.appSchema != nil {
+		schema := h.appSchema.SchemaStatus()
+		response.AppSchema = &schema
+		if schema.Error != "" || !schema.UpToDate {
+			response.Status = "degraded"
+		}
+	}
+
 	c.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IndustryServiceInterface defines the contract for industry calculations
+type IndustryServiceInterface interface {
+	CalculateManufacturing(ctx context.Context, req service.ManufacturingRequest) (*service.ManufacturingResult, error)
+}
+
+type IndustryHandler struct {
+	industryService IndustryServiceInterface
+}
+
+func NewIndustryHandler(industryService IndustryServiceInterface) *IndustryHandler {
+	return &IndustryHandler{
+		industryService: industryService,
+	}
+}
+
+// CalculateManufacturing returns manufacturing cost, market value and build-vs-buy decisions
+func (h *IndustryHandler) CalculateManufacturing(c *gin.Context) {
+	var req service.ManufacturingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+
+	result, err := h.industryService.CalculateManufacturing(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		case errors.Is(err, service.ErrNotManufacturable):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "No manufacturing blueprint for this item",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
@@ -17,14 +17,48 @@ type ItemServiceInterface interface {
 	SearchItems(query string) ([]*models.Item, error)
 }
 
+// ItemCatalogInterface defines the contract for browsing categories, groups and market groups
+type ItemCatalogInterface interface {
+	GetCategories() ([]*models.ItemCategory, error)
+	GetGroupsByCategory(categoryID int32) ([]*models.ItemGroup, error)
+	GetMarketGroupTree() ([]*models.MarketGroup, error)
+	GetMarketGroup(marketGroupID int32) (*models.MarketGroup, error)
+	GetMarketGroupTypes(marketGroupID int32) ([]*models.Item, error)
+}
+
+// ItemSearchInterface defines the contract for ranked, filtered and paginated search
+type ItemSearchInterface interface {
+	Search(opts service.ItemSearchOptions) (*service.ItemSearchResult, error)
+}
+
+// ItemAttributesInterface defines the contract for dogma attributes and item comparison
+type ItemAttributesInterface interface {
+	GetItemAttributes(typeID int32) (*models.ItemAttributes, error)
+	CompareItems(typeIDs []int32) (*models.ItemComparison, error)
+}
+
 type ItemHandler struct {
 	itemService ItemServiceInterface
+	catalog     ItemCatalogInterface
+	search      ItemSearchInterface
+	attributes  ItemAttributesInterface
 }
 
 func NewItemHandler(itemService ItemServiceInterface) *ItemHandler {
-	return &ItemHandler{
+	handler := &ItemHandler{
 		itemService: itemService,
 	}
+	// Browsing is optional - services without catalog support respond with 501
+	if catalog, ok := itemService.(ItemCatalogInterface); ok {
+		handler.catalog = catalog
+	}
+	if search, ok := itemService.(ItemSearchInterface); ok {
+		handler.search = search
+	}
+	if attributes, ok := itemService.(ItemAttributesInterface); ok {
+		handler.attributes = attributes
+	}
+	return handler
 }
 
 type ItemResponse struct {
@@ -33,6 +67,15 @@ type ItemResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
+// ItemSearchResponse is a page of search results with paging information
+type ItemSearchResponse struct {
+	Success bool           `json:"success"`
+	Data    []*models.Item `json:"data"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
 func (h *ItemHandler) GetItemDetails(c *gin.Context) {
 	itemIDStr := c.Param("item_id")
 
@@ -82,6 +125,11 @@ func (h *ItemHandler) SearchItems(c *gin.Context) {
 		return
 	}
 
+	if h.search != nil {
+		h.searchWithOptions(c, query)
+		return
+	}
+
 	// Search items using service
 	items, err := h.itemService.SearchItems(query)
 	if err != nil {
@@ -98,8 +146,214 @@ func (h *ItemHandler) SearchItems(c *gin.Context) {
 	})
 }
 
+// searchWithOptions runs a ranked search with limit/offset paging and filters
+func (h *ItemHandler) searchWithOptions(c *gin.Context, query string) {
+	opts, err := parseSearchOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ItemResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+	opts.Query = query
+
+	result, err := h.search.Search(opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ItemResponse{Success: false, Error: err.Error()})
+		case errors.Is(err, service.ErrMarketGroupNotFound):
+			c.JSON(http.StatusNotFound, ItemResponse{Success: false, Error: "Market group not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ItemResponse{Success: false, Error: "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ItemSearchResponse{
+		Success: true,
+		Data:    result.Items,
+		Total:   result.Total,
+		Limit:   result.Limit,
+		Offset:  result.Offset,
+	})
+}
+
+// GetItemAttributes returns the dogma attributes of an item with meta and tech level
+func (h *ItemHandler) GetItemAttributes(c *gin.Context) {
+	itemID, err := strconv.ParseInt(c.Param("item_id"), 10, 32)
+	if err != nil || itemID <= 0 {
+		c.JSON(http.StatusBadRequest, ItemResponse{
+			Success: false,
+			Error:   "Invalid item ID format",
+		})
+		return
+	}
+	if !h.requireAttributes(c) {
+		return
+	}
+
+	attributes, err := h.attributes.GetItemAttributes(int32(itemID))
+	h.respondWithAttributeResult(c, attributes, err)
+}
+
+// CompareItems lists the attributes of several items side by side
+func (h *ItemHandler) CompareItems(c *gin.Context) {
+	typeIDs, err := parseTypeIDList(c, "type_ids")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ItemResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+	if !h.requireAttributes(c) {
+		return
+	}
+
+	comparison, err := h.attributes.CompareItems(typeIDs)
+	h.respondWithAttributeResult(c, comparison, err)
+}
+
+// requireAttributes responds with 501 if the item service has no dogma attributes
+func (h *ItemHandler) requireAttributes(c *gin.Context) bool {
+	if h.attributes == nil {
+		c.JSON(http.StatusNotImplemented, ItemResponse{
+			Success: false,
+			Error:   "Item attributes not available",
+		})
+		return false
+	}
+	return true
+}
+
+// respondWithAttributeResult maps attribute results and errors to responses
+func (h *ItemHandler) respondWithAttributeResult(c *gin.Context, data interface{}, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ItemResponse{Success: false, Error: err.Error()})
+		case errors.Is(err, service.ErrItemNotFound):
+			c.JSON(http.StatusNotFound, ItemResponse{Success: false, Error: "Item not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ItemResponse{Success: false, Error: "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ItemResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// GetCategories lists all published item categories
 func (h *ItemHandler) GetCategories(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Categories endpoint - not implemented yet",
+	if !h.requireCatalog(c) {
+		return
+	}
+
+	categories, err := h.catalog.GetCategories()
+	h.respondWithCatalogResult(c, categories, err)
+}
+
+// GetCategoryGroups lists the published groups of a category
+func (h *ItemHandler) GetCategoryGroups(c *gin.Context) {
+	if !h.requireCatalog(c) {
+		return
+	}
+
+	categoryID, err := strconv.ParseInt(c.Param("category_id"), 10, 32)
+	if err != nil || categoryID <= 0 {
+		c.JSON(http.StatusBadRequest, ItemResponse{
+			Success: false,
+			Error:   "Invalid category ID format",
+		})
+		return
+	}
+
+	groups, err := h.catalog.GetGroupsByCategory(int32(categoryID))
+	h.respondWithCatalogResult(c, groups, err)
+}
+
+// GetMarketGroups returns the complete market group tree
+func (h *ItemHandler) GetMarketGroups(c *gin.Context) {
+	if !h.requireCatalog(c) {
+		return
+	}
+
+	tree, err := h.catalog.GetMarketGroupTree()
+	h.respondWithCatalogResult(c, tree, err)
+}
+
+// GetMarketGroup returns a single market group with its subtree
+func (h *ItemHandler) GetMarketGroup(c *gin.Context) {
+	marketGroupID, ok := h.extractMarketGroupID(c)
+	if !ok || !h.requireCatalog(c) {
+		return
+	}
+
+	marketGroup, err := h.catalog.GetMarketGroup(marketGroupID)
+	h.respondWithCatalogResult(c, marketGroup, err)
+}
+
+// GetMarketGroupTypes lists the types directly under a market group
+func (h *ItemHandler) GetMarketGroupTypes(c *gin.Context) {
+	marketGroupID, ok := h.extractMarketGroupID(c)
+	if !ok || !h.requireCatalog(c) {
+		return
+	}
+
+	items, err := h.catalog.GetMarketGroupTypes(marketGroupID)
+	h.respondWithCatalogResult(c, items, err)
+}
+
+// extractMarketGroupID validates the market group ID path parameter
+func (h *ItemHandler) extractMarketGroupID(c *gin.Context) (int32, bool) {
+	marketGroupID, err := strconv.ParseInt(c.Param("market_group_id"), 10, 32)
+	if err != nil || marketGroupID <= 0 {
+		c.JSON(http.StatusBadRequest, ItemResponse{
+			Success: false,
+			Error:   "Invalid market group ID format",
+		})
+		return 0, false
+	}
+	return int32(marketGroupID), true
+}
+
+// requireCatalog responds with 501 if the item service cannot browse the catalog
+func (h *ItemHandler) requireCatalog(c *gin.Context) bool {
+	if h.catalog == nil {
+		c.JSON(http.StatusNotImplemented, ItemResponse{
+			Success: false,
+			Error:   "Item catalog not available",
+		})
+		return false
+	}
+	return true
+}
+
+// respondWithCatalogResult maps catalog results and errors to responses
+func (h *ItemHandler) respondWithCatalogResult(c *gin.Context, data interface{}, err error) {
+	if err != nil {
+		if errors.Is(err, service.ErrMarketGroupNotFound) {
+			c.JSON(http.StatusNotFound, ItemResponse{
+				Success: false,
+				Error:   "Market group not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ItemResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ItemResponse{
+		Success: true,
+		Data:    data,
 	})
 }
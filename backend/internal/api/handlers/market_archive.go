@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MarketArchiveInterface defines the contract for archived intraday prices
+type MarketArchiveInterface interface {
+	PriceCurve(regionID, typeID int32, from, to time.Time, interval time.Duration) (*models.PriceCurve, error)
+}
+
+type MarketArchiveHandler struct {
+	archive MarketArchiveInterface
+}
+
+func NewMarketArchiveHandler(archive MarketArchiveInterface) *MarketArchiveHandler {
+	return &MarketArchiveHandler{
+		archive: archive,
+	}
+}
+
+// GetPriceCurve returns the archived intraday prices of an item, by default the
+// last 24 hours in The Forge as stored
+func (h *MarketArchiveHandler) GetPriceCurve(c *gin.Context) {
+	typeID, err := strconv.ParseInt(c.Param("item_id"), 10, 32)
+	if err != nil || typeID <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Invalid item ID format",
+		})
+		return
+	}
+
+	regionID, from, to, interval, err := parsePriceCurveParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	curve, err := h.archive.PriceCurve(regionID, int32(typeID), from, to, interval)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    curve,
+	})
+}
+
+// parsePriceCurveParams reads the market region, time range and an interval such as 15m
+func parsePriceCurveParams(c *gin.Context) (int32, time.Time, time.Time, time.Duration, error) {
+	var interval time.Duration
+
+	regionID, _, err := parseMarketScope(c)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, interval, err
+	}
+	from, to, err := parseTimeRange(c, service.DefaultPriceCurveRange)
+	if err != nil {
+		return 0, from, to, interval, err
+	}
+
+	if value := c.Query("interval"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, from, to, interval, errors.New("invalid interval parameter, use e.g. 15m or 1h")
+		}
+		interval = parsed
+	}
+	return regionID, from, to, interval, nil
+}
+
+// parseMarketScope reads the region and station of a market query. A hub selects
+// its region and station, region_id a whole region and location_id one station.
+// The default is all of The Forge.
+func parseMarketScope(c *gin.Context) (int32, int64, error) {
+	regionID := service.DefaultHubRegion
+	var locationID int64
+	if value := c.Query("hub"); value != "" {
+		hub, ok := service.HubByName(value)
+		if !ok {
+			return 0, 0, errors.New("unknown hub " + value)
+		}
+		regionID, locationID = hub.RegionID, hub.StationID
+	}
+	if value := c.Query("region_id"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed <= 0 {
+			return 0, 0, errors.New("invalid region_id parameter")
+		}
+		regionID = int32(parsed)
+	}
+	if value := c.Query("location_id"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			return 0, 0, errors.New("invalid location_id parameter")
+		}
+		locationID = parsed
+	}
+	return regionID, locationID, nil
+}
+
+// parseTimeRange reads the RFC 3339 from and to timestamps, by default the
+// given range up to now
+func parseTimeRange(c *gin.Context, defaultRange time.Duration) (time.Time, time.Time, error) {
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to parameter, use RFC 3339")
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultRange)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from parameter, use RFC 3339")
+		}
+		from = parsed
+	}
+	return from, to, nil
+}
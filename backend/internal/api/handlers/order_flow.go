@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderFlowInterface defines the contract for order events inferred from archived order books
+type OrderFlowInterface interface {
+	Events(regionID, typeID int32, locationID int64, since time.Time, cursor string, limit int) (*models.OrderEventPage, error)
+	Velocity(regionID, typeID int32, locationID int64, from, to time.Time) (*models.OrderFlowReport, error)
+}
+
+type OrderFlowHandler struct {
+	orderFlow OrderFlowInterface
+}
+
+func NewOrderFlowHandler(orderFlow OrderFlowInterface) *OrderFlowHandler {
+	return &OrderFlowHandler{
+		orderFlow: orderFlow,
+	}
+}
+
+// GetOrderEvents returns a page of the order events of an item seen after `since`,
+// by default within the last hour. Pass the `next` cursor of the page as `cursor`
+// to follow the stream, it takes precedence over `since`.
+func (h *OrderFlowHandler) GetOrderEvents(c *gin.Context) {
+	typeID, ok := parseItemIDParam(c)
+	if !ok {
+		return
+	}
+
+	regionID, locationID, err := parseMarketScope(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	since := time.Now().Add(-time.Hour)
+	if value := c.Query("since"); value != "" {
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondBadRequest(c, "invalid since parameter, use RFC 3339")
+			return
+		}
+	}
+	limit, err := parseOptionalInt(c, "limit", service.DefaultOrderEventLimit)
+	if err != nil || limit <= 0 {
+		respondBadRequest(c, "invalid limit parameter")
+		return
+	}
+
+	page, err := h.orderFlow.Events(regionID, typeID, locationID, since, c.Query("cursor"), limit)
+	respondOrderFlow(c, page, err)
+}
+
+// GetVelocity estimates the traded volume of an item per hour, by default over the last day
+func (h *OrderFlowHandler) GetVelocity(c *gin.Context) {
+	typeID, ok := parseItemIDParam(c)
+	if !ok {
+		return
+	}
+
+	regionID, locationID, err := parseMarketScope(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.orderFlow.Velocity(regionID, typeID, locationID, from, to)
+	respondOrderFlow(c, report, err)
+}
+
+// parseItemIDParam reads the item_id path parameter and answers 400 if it is invalid
+func parseItemIDParam(c *gin.Context) (int32, bool) {
+	typeID, err := strconv.ParseInt(c.Param("item_id"), 10, 32)
+	if err != nil || typeID <= 0 {
+		respondBadRequest(c, "Invalid item ID format")
+		return 0, false
+	}
+	return int32(typeID), true
+}
+
+func respondBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// respondOrderFlow answers with the data or maps order flow errors to HTTP status codes
+func respondOrderFlow(c *gin.Context, data interface{}, err error) {
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// respondServiceError answers invalid input with 400 and any other service error with 500
+func respondServiceError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidInput) {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.APIResponse{
+		Success: false,
+		Error:   "Internal server error",
+	})
+}
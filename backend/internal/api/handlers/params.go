@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePaging reads the page and page_size query parameters
+func parsePaging(c *gin.Context) (int, int, error) {
+	page, err := parseOptionalInt(c, "page", 1)
+	if err != nil {
+		return 0, 0, errors.New("invalid page parameter")
+	}
+
+	pageSize, err := parseOptionalInt(c, "page_size", service.DefaultPageSize)
+	if err != nil {
+		return 0, 0, errors.New("invalid page_size parameter")
+	}
+
+	return page, pageSize, nil
+}
+
+// parseOptionalInt reads an integer query parameter with a default
+func parseOptionalInt(c *gin.Context, name string, defaultValue int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// parseOptionalFloat reads a float query parameter defaulting to zero
+func parseOptionalFloat(c *gin.Context, name string) (float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseOptionalBool reads a boolean query parameter, returning nil if absent
+func parseOptionalBool(c *gin.Context, name string) (*bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parseSearchOptions reads the paging and filter parameters of the item search.
+// Only published types are returned unless published=false is requested.
+func parseSearchOptions(c *gin.Context) (service.ItemSearchOptions, error) {
+	var opts service.ItemSearchOptions
+
+	limit, err := parseOptionalInt(c, "limit", service.DefaultSearchLimit)
+	if err != nil || limit <= 0 {
+		return opts, errors.New("invalid limit parameter")
+	}
+	offset, err := parseOptionalInt(c, "offset", 0)
+	if err != nil || offset < 0 {
+		return opts, errors.New("invalid offset parameter")
+	}
+	categoryID, err := parseOptionalInt(c, "category_id", 0)
+	if err != nil || categoryID < 0 {
+		return opts, errors.New("invalid category_id parameter")
+	}
+	marketGroupID, err := parseOptionalInt(c, "market_group_id", 0)
+	if err != nil || marketGroupID < 0 {
+		return opts, errors.New("invalid market_group_id parameter")
+	}
+
+	published, err := parseOptionalBool(c, "published")
+	if err != nil {
+		return opts, errors.New("invalid published parameter")
+	}
+	if published == nil {
+		publishedOnly := true
+		published = &publishedOnly
+	}
+	marketable, err := parseOptionalBool(c, "marketable")
+	if err != nil {
+		return opts, errors.New("invalid marketable parameter")
+	}
+
+	opts.Limit = limit
+	opts.Offset = offset
+	opts.CategoryID = int32(categoryID)
+	opts.MarketGroupID = int32(marketGroupID)
+	opts.Published = published
+	opts.Marketable = marketable
+	return opts, nil
+}
+
+// parseTypeIDList reads a comma separated list of type IDs, also accepting repeated parameters
+func parseTypeIDList(c *gin.Context, name string) ([]int32, error) {
+	var typeIDs []int32
+	for _, value := range c.QueryArray(name) {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			typeID, err := strconv.ParseInt(part, 10, 32)
+			if err != nil || typeID <= 0 {
+				return nil, errors.New("invalid " + name + " parameter")
+			}
+			typeIDs = append(typeIDs, int32(typeID))
+		}
+	}
+	return typeIDs, nil
+}
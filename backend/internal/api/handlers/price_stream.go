@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// priceStreamKeepAlive keeps proxies from closing idle price streams
+const priceStreamKeepAlive = 30 * time.Second
+
+// PriceStreamInterface defines the contract for price subscriptions
+type PriceStreamInterface interface {
+	Subscribe(ctx context.Context, keys []service.PriceKey) (*service.PriceSubscription, error)
+	Unsubscribe(sub *service.PriceSubscription)
+}
+
+type PriceStreamHandler struct {
+	stream PriceStreamInterface
+}
+
+func NewPriceStreamHandler(stream PriceStreamInterface) *PriceStreamHandler {
+	return &PriceStreamHandler{
+		stream: stream,
+	}
+}
+
+// StreamPrices streams the prices of the requested types as server-sent events.
+// Each "price" event carries one refreshed ItemPrice, the current prices are
+// sent right away. A subscriber that falls behind receives an "error" event and
+// is disconnected, clients reconnect to resubscribe.
+func (h *PriceStreamHandler) StreamPrices(c *gin.Context) {
+	keys, err := parsePriceKeys(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	sub, err := h.stream.Subscribe(c.Request.Context(), keys)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	defer h.stream.Unsubscribe(sub)
+
+	// The stream outlives the server write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(priceStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case update := <-sub.Updates():
+			c.SSEvent("price", update)
+			return true
+		case <-sub.Done():
+			if err := sub.Err(); err != nil {
+				c.SSEvent("error", gin.H{"error": err.Error()})
+			}
+			return false
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// parsePriceKeys reads region:type pairs and/or type_ids in the region selected
+// by hub or region_id
+func parsePriceKeys(c *gin.Context) ([]service.PriceKey, error) {
+	var keys []service.PriceKey
+	for _, value := range c.QueryArray("pairs") {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			region, typ, ok := strings.Cut(part, ":")
+			regionID, regionErr := strconv.ParseInt(region, 10, 32)
+			typeID, typeErr := strconv.ParseInt(typ, 10, 32)
+			if !ok || regionErr != nil || typeErr != nil || regionID <= 0 || typeID <= 0 {
+				return nil, errors.New("invalid pairs parameter, use region_id:type_id")
+			}
+			keys = append(keys, service.PriceKey{RegionID: int32(regionID), TypeID: int32(typeID)})
+		}
+	}
+
+	typeIDs, err := parseTypeIDList(c, "type_ids")
+	if err != nil {
+		return nil, err
+	}
+	if len(typeIDs) > 0 {
+		regionID, _, err := parseMarketScope(c)
+		if err != nil {
+			return nil, err
+		}
+		for _, typeID := range typeIDs {
+			keys = append(keys, service.PriceKey{RegionID: regionID, TypeID: typeID})
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("pairs or type_ids parameter is required")
+	}
+	return keys, nil
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReprocessingServiceInterface defines the contract for reprocessing calculations
+type ReprocessingServiceInterface interface {
+	CalculateReprocessing(ctx context.Context, req service.ReprocessingRequest) (*service.ReprocessingResult, error)
+}
+
+type ReprocessingHandler struct {
+	reprocessingService ReprocessingServiceInterface
+}
+
+func NewReprocessingHandler(reprocessingService ReprocessingServiceInterface) *ReprocessingHandler {
+	return &ReprocessingHandler{
+		reprocessingService: reprocessingService,
+	}
+}
+
+// CalculateReprocessing returns the reprocessed output and the "reprocess vs sell" decision
+func (h *ReprocessingHandler) CalculateReprocessing(c *gin.Context) {
+	var req service.ReprocessingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+
+	result, err := h.reprocessingService.CalculateReprocessing(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		case errors.Is(err, service.ErrItemNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Item not found",
+			})
+		case errors.Is(err, service.ErrNotReprocessable):
+			c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+				Success: false,
+				Error:   "Item cannot be reprocessed",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UndercutMonitorInterface defines the contract for checking open orders against the market
+type UndercutMonitorInterface interface {
+	CheckCharacters(ctx context.Context, characterIDs []int32, all bool) (*service.UndercutReport, error)
+}
+
+// AccountLookup resolves the account of the authenticated character
+type AccountLookup interface {
+	GetAccount(characterID int32) (*models.Account, error)
+}
+
+// UndercutHandler serves the undercut checks of a character or a whole account
+type UndercutHandler struct {
+	monitor  UndercutMonitorInterface
+	accounts AccountLookup
+}
+
+func NewUndercutHandler(monitor UndercutMonitorInterface) *UndercutHandler {
+	return &UndercutHandler{
+		monitor: monitor,
+	}
+}
+
+// WithAccounts enables the account-wide check over all linked characters
+func (h *UndercutHandler) WithAccounts(accounts AccountLookup) *UndercutHandler {
+	h.accounts = accounts
+	return h
+}
+
+// GetCharacterUndercuts checks the open orders of one character.
+// With all=true leading orders are listed too.
+func (h *UndercutHandler) GetCharacterUndercuts(c *gin.Context) {
+	characterID, ok := parseCharacterIDParam(c)
+	if !ok {
+		return
+	}
+
+	all, _ := strconv.ParseBool(c.Query("all"))
+	report, err := h.monitor.CheckCharacters(characterRequestContext(c, characterID), []int32{characterID}, all)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetAccountUndercuts checks the open orders of all characters linked to the
+// authenticated character's account
+func (h *UndercutHandler) GetAccountUndercuts(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+	if h.accounts == nil {
+		respondAccountError(c, service.ErrCharacterDataUnavailable)
+		return
+	}
+
+	account, err := h.accounts.GetAccount(characterID)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+	characterIDs := make([]int32, len(account.Characters))
+	for i, character := range account.Characters {
+		characterIDs[i] = character.CharacterID
+	}
+
+	all, _ := strconv.ParseBool(c.Query("all"))
+	report, err := h.monitor.CheckCharacters(characterRequestContext(c, characterID), characterIDs, all)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
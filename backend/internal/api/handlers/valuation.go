@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssetValuationServiceInterface defines the contract for valuing character assets
+type AssetValuationServiceInterface interface {
+	ValueCharacters(ctx context.Context, characterIDs []int32, opts service.ValuationOptions) (*service.AssetValuation, error)
+}
+
+// AssetValuationHandler serves the net worth of a character or a whole account
+type AssetValuationHandler struct {
+	valuation AssetValuationServiceInterface
+	accounts  AccountLookup
+}
+
+func NewAssetValuationHandler(valuation AssetValuationServiceInterface) *AssetValuationHandler {
+	return &AssetValuationHandler{
+		valuation: valuation,
+	}
+}
+
+// WithAccounts enables the account-wide net worth over all linked characters
+func (h *AssetValuationHandler) WithAccounts(accounts AccountLookup) *AssetValuationHandler {
+	h.accounts = accounts
+	return h
+}
+
+// GetCharacterValuation values the assets of one character
+func (h *AssetValuationHandler) GetCharacterValuation(c *gin.Context) {
+	characterID, ok := parseCharacterIDParam(c)
+	if !ok {
+		return
+	}
+
+	opts, err := parseValuationOptions(c)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	report, err := h.valuation.ValueCharacters(characterRequestContext(c, characterID), []int32{characterID}, opts)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetAccountNetWorth values the assets of all characters linked to the
+// authenticated character's account
+func (h *AssetValuationHandler) GetAccountNetWorth(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+	if h.accounts == nil {
+		respondAccountError(c, service.ErrCharacterDataUnavailable)
+		return
+	}
+
+	opts, err := parseValuationOptions(c)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	account, err := h.accounts.GetAccount(characterID)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+	characterIDs := make([]int32, len(account.Characters))
+	for i, character := range account.Characters {
+		characterIDs[i] = character.CharacterID
+	}
+
+	report, err := h.valuation.ValueCharacters(characterRequestContext(c, characterID), characterIDs, opts)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// parseValuationOptions reads the source, hub, percentile and top query parameters
+func parseValuationOptions(c *gin.Context) (service.ValuationOptions, error) {
+	opts := service.ValuationOptions{Source: c.Query("source")}
+
+	if name := c.Query("hub"); name != "" {
+		hub, ok := service.HubByName(name)
+		if !ok {
+			return opts, fmt.Errorf("%w: unknown hub %q", service.ErrInvalidInput, name)
+		}
+		opts.Hub = hub
+	}
+
+	percentile, err := parseOptionalFloat(c, "percentile")
+	if err != nil {
+		return opts, fmt.Errorf("%w: invalid percentile parameter", service.ErrInvalidInput)
+	}
+	opts.Percentile = percentile
+
+	top, err := parseOptionalInt(c, "top", 0)
+	if err != nil {
+		return opts, fmt.Errorf("%w: invalid top parameter", service.ErrInvalidInput)
+	}
+	opts.TopItems = top
+
+	return service.NormalizeValuationOptions(opts)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VariantServiceInterface defines the contract for the meta variant price finder
+type VariantServiceInterface interface {
+	FindVariants(ctx context.Context, req service.VariantRequest) (*service.VariantPriceResult, error)
+}
+
+type VariantHandler struct {
+	variantService VariantServiceInterface
+}
+
+func NewVariantHandler(variantService VariantServiceInterface) *VariantHandler {
+	return &VariantHandler{
+		variantService: variantService,
+	}
+}
+
+// GetVariants lists all meta variants of a module with hub prices and attribute deltas
+func (h *VariantHandler) GetVariants(c *gin.Context) {
+	typeID, err := strconv.ParseInt(c.Param("item_id"), 10, 32)
+	if err != nil || typeID <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Invalid item ID format",
+		})
+		return
+	}
+
+	regionID, err := parseOptionalInt(c, "region_id", 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "invalid region_id parameter",
+		})
+		return
+	}
+
+	result, err := h.variantService.FindVariants(c.Request.Context(), service.VariantRequest{
+		TypeID:    int32(typeID),
+		RegionID:  int32(regionID),
+		Hub:       c.Query("hub"),
+		PriceType: c.Query("price_type"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		case errors.Is(err, service.ErrItemNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Item not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
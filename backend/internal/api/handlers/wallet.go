@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WalletServiceInterface defines the contract for wallet sync and profit and loss
+type WalletServiceInterface interface {
+	SyncCharacter(ctx context.Context, characterID int32) (*models.WalletSyncResult, error)
+	GetProfitLoss(ctx context.Context, characterID int32, method service.CostMethod, priceRegionID int32) (*models.ProfitLossReport, error)
+}
+
+// WalletHandler serves the synced wallet history of a character. All routes
+// must run behind middleware.RequireCharacterAccess.
+type WalletHandler struct {
+	walletService WalletServiceInterface
+}
+
+func NewWalletHandler(walletService WalletServiceInterface) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+	}
+}
+
+// SyncWallet pulls new journal entries and transactions of a character now
+func (h *WalletHandler) SyncWallet(c *gin.Context) {
+	characterID, ok := parseCharacterIDParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.walletService.SyncCharacter(characterRequestContext(c, characterID), characterID)
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// GetProfitLoss reports realized profit and loss per item, day and station
+// plus the unrealized result of remaining stock
+func (h *WalletHandler) GetProfitLoss(c *gin.Context) {
+	characterID, ok := parseCharacterIDParam(c)
+	if !ok {
+		return
+	}
+
+	method, err := service.ParseCostMethod(c.Query("method"))
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	var regionID int64
+	if value := c.Query("region_id"); value != "" {
+		regionID, err = strconv.ParseInt(value, 10, 32)
+		if err != nil || regionID <= 0 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "region_id must be a positive number",
+			})
+			return
+		}
+	}
+
+	report, err := h.walletService.GetProfitLoss(c.Request.Context(), characterID, method, int32(regionID))
+	if err != nil {
+		respondWalletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// parseCharacterIDParam reads the characterID path parameter and answers 400 if it is invalid
+func parseCharacterIDParam(c *gin.Context) (int32, bool) {
+	characterID, err := strconv.ParseInt(c.Param("characterID"), 10, 32)
+	if err != nil || characterID <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   ErrInvalidCharacterID,
+		})
+		return 0, false
+	}
+	return int32(characterID), true
+}
+
+// respondWalletError maps wallet service errors to HTTP status codes
+func respondWalletError(c *gin.Context, err error) {
+	status, message := http.StatusInternalServerError, "Internal server error"
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, service.ErrUnauthorized), errors.Is(err, service.ErrLoginRequired):
+		status, message = http.StatusUnauthorized, "Character login required"
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
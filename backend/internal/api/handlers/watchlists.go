@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WatchlistServiceInterface defines the contract for managing watchlists and saved searches
+type WatchlistServiceInterface interface {
+	ListWatchlists(characterID int32) ([]models.Watchlist, error)
+	GetWatchlist(ctx context.Context, characterID int32, watchlistID int64) (*models.Watchlist, error)
+	CreateWatchlist(ctx context.Context, characterID int32, list models.Watchlist) (*models.Watchlist, error)
+	UpdateWatchlist(ctx context.Context, characterID int32, watchlistID int64, list models.Watchlist) (*models.Watchlist, error)
+	DeleteWatchlist(characterID int32, watchlistID int64) error
+	ListSearches(characterID int32) ([]models.SavedSearch, error)
+	GetSearch(characterID int32, searchID int64) (*models.SavedSearch, error)
+	CreateSearch(characterID int32, search models.SavedSearch) (*models.SavedSearch, error)
+	UpdateSearch(characterID int32, searchID int64, search models.SavedSearch) (*models.SavedSearch, error)
+	DeleteSearch(characterID int32, searchID int64) error
+	RunSearch(characterID int32, searchID int64, limit, offset int) (*service.ItemSearchResult, error)
+}
+
+// watchlistRequest is the body of a created or replaced watchlist
+type watchlistRequest struct {
+	Name  string                 `json:"name" binding:"required"`
+	Notes string                 `json:"notes"`
+	Items []watchlistItemRequest `json:"items"`
+}
+
+// watchlistItemRequest targets a region and station directly or through a trade hub name
+type watchlistItemRequest struct {
+	TypeID    int32  `json:"type_id"`
+	Hub       string `json:"hub"`
+	RegionID  int32  `json:"region_id"`
+	StationID int64  `json:"station_id"`
+	Note      string `json:"note"`
+}
+
+func (r watchlistRequest) watchlist() (models.Watchlist, error) {
+	list := models.Watchlist{Name: r.Name, Notes: r.Notes, Items: make([]models.WatchlistItem, 0, len(r.Items))}
+	for _, item := range r.Items {
+		regionID, stationID := item.RegionID, item.StationID
+		if item.Hub != "" {
+			hub, ok := service.HubByName(item.Hub)
+			if !ok {
+				return list, errors.New("unknown hub " + item.Hub)
+			}
+			regionID, stationID = hub.RegionID, hub.StationID
+		}
+		list.Items = append(list.Items, models.WatchlistItem{
+			TypeID:    item.TypeID,
+			RegionID:  regionID,
+			StationID: stationID,
+			Note:      item.Note,
+		})
+	}
+	return list, nil
+}
+
+// savedSearchRequest is the body of a created or replaced saved search
+type savedSearchRequest struct {
+	Name          string `json:"name"`
+	Query         string `json:"query" binding:"required"`
+	CategoryID    int32  `json:"category_id"`
+	MarketGroupID int32  `json:"market_group_id"`
+	Published     *bool  `json:"published"`
+	Marketable    *bool  `json:"marketable"`
+}
+
+func (r savedSearchRequest) search() models.SavedSearch {
+	return models.SavedSearch{
+		Name:          r.Name,
+		Query:         r.Query,
+		CategoryID:    r.CategoryID,
+		MarketGroupID: r.MarketGroupID,
+		Published:     r.Published,
+		Marketable:    r.Marketable,
+	}
+}
+
+// WatchlistHandler serves the watchlists and saved item searches of the
+// authenticated character. All routes must run behind middleware.RequireAuth.
+type WatchlistHandler struct {
+	watchlists WatchlistServiceInterface
+}
+
+func NewWatchlistHandler(watchlists WatchlistServiceInterface) *WatchlistHandler {
+	return &WatchlistHandler{
+		watchlists: watchlists,
+	}
+}
+
+// ListWatchlists returns the name, notes and item count of all watchlists, items
+// with live prices are returned per watchlist
+func (h *WatchlistHandler) ListWatchlists(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	lists, err := h.watchlists.ListWatchlists(characterID)
+	respondWatchlistResult(c, http.StatusOK, lists, err)
+}
+
+// GetWatchlist returns one watchlist with live prices
+func (h *WatchlistHandler) GetWatchlist(c *gin.Context) {
+	characterID, watchlistID, ok := ownedIDParams(c, "watchlistID")
+	if !ok {
+		return
+	}
+
+	list, err := h.watchlists.GetWatchlist(c.Request.Context(), characterID, watchlistID)
+	respondWatchlistResult(c, http.StatusOK, list, err)
+}
+
+// CreateWatchlist adds a watchlist such as {"name": "Minerals", "items": [{"type_id": 34, "hub": "Jita"}]}
+func (h *WatchlistHandler) CreateWatchlist(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+	list, ok := bindWatchlist(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.watchlists.CreateWatchlist(c.Request.Context(), characterID, list)
+	respondWatchlistResult(c, http.StatusCreated, created, err)
+}
+
+// UpdateWatchlist replaces the name, notes and items of a watchlist
+func (h *WatchlistHandler) UpdateWatchlist(c *gin.Context) {
+	characterID, watchlistID, ok := ownedIDParams(c, "watchlistID")
+	if !ok {
+		return
+	}
+	list, ok := bindWatchlist(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.watchlists.UpdateWatchlist(c.Request.Context(), characterID, watchlistID, list)
+	respondWatchlistResult(c, http.StatusOK, updated, err)
+}
+
+// DeleteWatchlist removes a watchlist
+func (h *WatchlistHandler) DeleteWatchlist(c *gin.Context) {
+	characterID, watchlistID, ok := ownedIDParams(c, "watchlistID")
+	if !ok {
+		return
+	}
+
+	err := h.watchlists.DeleteWatchlist(characterID, watchlistID)
+	respondWatchlistResult(c, http.StatusOK, nil, err)
+}
+
+// ListSearches returns all saved item searches
+func (h *WatchlistHandler) ListSearches(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	searches, err := h.watchlists.ListSearches(characterID)
+	respondWatchlistResult(c, http.StatusOK, searches, err)
+}
+
+// GetSearch returns one saved item search
+func (h *WatchlistHandler) GetSearch(c *gin.Context) {
+	characterID, searchID, ok := ownedIDParams(c, "searchID")
+	if !ok {
+		return
+	}
+
+	search, err := h.watchlists.GetSearch(characterID, searchID)
+	respondWatchlistResult(c, http.StatusOK, search, err)
+}
+
+// CreateSearch saves an item search such as {"query": "tritanium", "marketable": true}
+func (h *WatchlistHandler) CreateSearch(c *gin.Context) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return
+	}
+
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "query is required")
+		return
+	}
+
+	search, err := h.watchlists.CreateSearch(characterID, req.search())
+	respondWatchlistResult(c, http.StatusCreated, search, err)
+}
+
+// UpdateSearch replaces the query and filters of a saved search
+func (h *WatchlistHandler) UpdateSearch(c *gin.Context) {
+	characterID, searchID, ok := ownedIDParams(c, "searchID")
+	if !ok {
+		return
+	}
+
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "query is required")
+		return
+	}
+
+	search, err := h.watchlists.UpdateSearch(characterID, searchID, req.search())
+	respondWatchlistResult(c, http.StatusOK, search, err)
+}
+
+// DeleteSearch removes a saved search
+func (h *WatchlistHandler) DeleteSearch(c *gin.Context) {
+	characterID, searchID, ok := ownedIDParams(c, "searchID")
+	if !ok {
+		return
+	}
+
+	err := h.watchlists.DeleteSearch(characterID, searchID)
+	respondWatchlistResult(c, http.StatusOK, nil, err)
+}
+
+// RunSearch returns one page of the current results of a saved search
+func (h *WatchlistHandler) RunSearch(c *gin.Context) {
+	characterID, searchID, ok := ownedIDParams(c, "searchID")
+	if !ok {
+		return
+	}
+	limit, err := parseOptionalInt(c, "limit", service.DefaultSearchLimit)
+	if err != nil || limit <= 0 {
+		respondBadRequest(c, "invalid limit parameter")
+		return
+	}
+	offset, err := parseOptionalInt(c, "offset", 0)
+	if err != nil || offset < 0 {
+		respondBadRequest(c, "invalid offset parameter")
+		return
+	}
+
+	result, err := h.watchlists.RunSearch(characterID, searchID, limit, offset)
+	respondWatchlistResult(c, http.StatusOK, result, err)
+}
+
+// bindWatchlist reads a watchlist body and answers the request if it is invalid
+func bindWatchlist(c *gin.Context) (models.Watchlist, bool) {
+	var req watchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "name is required")
+		return models.Watchlist{}, false
+	}
+	list, err := req.watchlist()
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return list, false
+	}
+	return list, true
+}
+
+// ownedIDParams reads the authenticated character and an ID path parameter
+// and answers the request if either is missing
+func ownedIDParams(c *gin.Context, name string) (int32, int64, bool) {
+	characterID, ok := middleware.CharacterIDFromContext(c)
+	if !ok {
+		respondAccountError(c, service.ErrUnauthorized)
+		return 0, 0, false
+	}
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		respondBadRequest(c, "Invalid "+name+" format")
+		return 0, 0, false
+	}
+	return characterID, id, true
+}
+
+// respondWatchlistResult answers with the data or maps watchlist errors to HTTP status codes
+func respondWatchlistResult(c *gin.Context, status int, data interface{}, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWatchlistNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Watchlist not found"})
+		case errors.Is(err, service.ErrSavedSearchNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Saved search not found"})
+		case errors.Is(err, service.ErrMarketGroupNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Market group not found"})
+		default:
+			respondAccountError(c, err)
+		}
+		return
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
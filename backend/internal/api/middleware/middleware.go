@@ -1,6 +1,13 @@
 package middleware
 
 import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eve-profit2/internal/models"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -31,15 +38,153 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
-// RequireAuth middleware for protected endpoints
-func RequireAuth() gin.HandlerFunc {
+// Gin context keys set by RequireAuth
+const (
+	ContextCharacterID   = "auth_character_id"
+	ContextCharacterName = "auth_character_name"
+	ContextScopes        = "auth_scopes"
+	ContextAccessToken   = "auth_access_token"
+)
+
+// TokenVerifier validates EVE SSO access tokens
+type TokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, accessToken string) (*models.AccessClaims, error)
+}
+
+// RequireAuth middleware for protected endpoints. It requires a valid EVE SSO
+// access token as Bearer token and stores the character and its scopes in the context.
+func RequireAuth(verifier TokenVerifier) gin.HandlerFunc {
+	return RequireScopes(verifier)
+}
+
+// RequireScopes works like RequireAuth and additionally requires all given scopes
+func RequireScopes(verifier TokenVerifier, scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// TODO: Implement JWT token validation
-		// For now, just pass through
+		accessToken := BearerToken(c.GetHeader("Authorization"))
+		if accessToken == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="eve-profit2"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+			return
+		}
+
+		claims, err := verifier.VerifyAccessToken(c.Request.Context(), accessToken)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="eve-profit2", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid access token"})
+			return
+		}
+
+		missing := missingScopes(claims.Scopes, scopes)
+		if len(missing) > 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":          "Insufficient scopes",
+				"missing_scopes": missing,
+			})
+			return
+		}
+
+		c.Set(ContextCharacterID, claims.CharacterID)
+		c.Set(ContextCharacterName, claims.CharacterName)
+		c.Set(ContextScopes, claims.Scopes)
+		c.Set(ContextAccessToken, accessToken)
 		c.Next()
 	}
 }
 
+// OptionalAuth stores the character like RequireAuth when a bearer token is
+// sent, requests without one pass unauthenticated. Invalid tokens are rejected.
+func OptionalAuth(verifier TokenVerifier) gin.HandlerFunc {
+	required := RequireAuth(verifier)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
+
+// CharacterAuthorizer decides whether an authenticated character may access another character
+type CharacterAuthorizer interface {
+	CanAccessCharacter(authCharacterID, characterID int32) (bool, error)
+}
+
+// RequireCharacterAccess rejects requests for characters that are not linked to
+// the authenticated character's account. It must run after RequireAuth or RequireScopes.
+func RequireCharacterAccess(authorizer CharacterAuthorizer, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		characterID, ok := CharacterIDFromContext(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+			return
+		}
+
+		requested, err := strconv.ParseInt(c.Param(param), 10, 32)
+		if err != nil || requested <= 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+			return
+		}
+
+		allowed, err := authorizer.CanAccessCharacter(characterID, int32(requested))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access to this character is not allowed"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CharacterIDFromContext returns the authenticated character
+func CharacterIDFromContext(c *gin.Context) (int32, bool) {
+	characterID, ok := c.Get(ContextCharacterID)
+	if !ok {
+		return 0, false
+	}
+	id, ok := characterID.(int32)
+	return id, ok
+}
+
+// ScopesFromContext returns the scopes granted to the authenticated character
+func ScopesFromContext(c *gin.Context) []string {
+	scopes, _ := c.Get(ContextScopes)
+	list, _ := scopes.([]string)
+	return list
+}
+
+// AccessTokenFromContext returns the verified access token of the authenticated character
+func AccessTokenFromContext(c *gin.Context) string {
+	return c.GetString(ContextAccessToken)
+}
+
+// BearerToken extracts the token of an "Authorization: Bearer <token>" header
+func BearerToken(header string) string {
+	const prefix = "Bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+// missingScopes returns the required scopes that were not granted
+func missingScopes(granted, required []string) []string {
+	grantedSet := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		grantedSet[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !grantedSet[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
 // RateLimit middleware for API rate limiting
 func RateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
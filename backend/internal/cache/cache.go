@@ -171,6 +171,11 @@ func (c *CacheManager) Reset() error {
 	return nil
 }
 
+// ResetSDEData drops all cached SDE data, e.g. after a new SDE was loaded
+func (c *CacheManager) ResetSDEData() error {
+	return c.sdeCache.Reset()
+}
+
 // Close all caches
 func (c *CacheManager) Close() error {
 	if err := c.marketCache.Close(); err != nil {
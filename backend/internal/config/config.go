@@ -29,6 +29,9 @@ type Config struct {
 	EVESSOAuthorizeURL string
 	EVESSOTokenURL     string
 	EVESSOVerifyURL    string
+	EVESSOJWKSURL      string
+	EVESSOIssuer       string
+	EVESSOJWKSRefresh  time.Duration
 
 	// Rate Limiting
 	ESIRateLimit   int
@@ -42,7 +45,52 @@ type Config struct {
 	CacheTTLCharacterInfo time.Duration
 
 	// Database Configuration
-	SDEDatabasePath string
+	SDEDatabasePath  string
+	SDEWatchInterval time.Duration // 0 disables SDE hot swapping
+
+	// Application Database (SSO tokens and other user data)
+	AppDatabaseDriver      string        // sqlite or postgres
+	AppDatabasePath        string        // SQLite file
+	AppDatabaseURL         string        // Postgres connection URL
+	AppDatabaseAutoMigrate bool          // false requires running `server migrate` before upgrades
+	TokenEncryptionKey     string        // Required for SSO unless TokenStoreMemory is set
+	TokenStoreMemory       bool          // Development only, tokens are lost on restart
+	TokenRefreshInterval   time.Duration // 0 disables the background token refresh
+	WalletSyncInterval     time.Duration // 0 disables the background wallet sync
+
+	// Order Undercut Monitor
+	UndercutCheckInterval time.Duration // 0 disables the background check
+	UndercutWebhookURL    string        // Receives newly undercut orders, empty disables notifications
+
+	// Market Snapshot Archive
+	MarketArchiveInterval        time.Duration // 0 disables the collector
+	MarketArchiveRegions         []int32
+	MarketArchiveTypes           []int32
+	MarketArchiveOrderBooks      bool
+	MarketArchiveRawRetention    time.Duration
+	MarketArchiveHourlyRetention time.Duration
+	OrderEventRetention          time.Duration // Order events inferred from archived order books
+
+	// Price Alerts
+	AlertCheckInterval     time.Duration // 0 disables the background evaluation
+	AlertDefaultCooldown   time.Duration
+	AlertWebhookURL        string // Empty disables the channel
+	AlertDiscordWebhookURL string // Empty disables the channel
+	SMTPHost               string // Empty disables the email channel
+	SMTPPort               int
+	SMTPUsername           string
+	SMTPPassword           string
+	SMTPFrom               string
+	AlertEmailTo           string // Recipient of alerts without their own email address
+
+	// Price Stream
+	PriceStreamInterval time.Duration // 0 only pushes prices fetched for other requests
+	PriceStreamBuffer   int
+
+	// Arbitrage Scanner Configuration
+	ArbitrageRegions      []int32
+	ArbitrageSalesTax     float64
+	ArbitrageScanInterval time.Duration
 }
 
 // Load reads configuration from environment variables with sensible defaults
@@ -70,6 +118,7 @@ func Load() *Config {
 			"esi-assets.read_assets.v1",
 			"esi-fittings.read_fittings.v1",
 			"esi-characters.read_standings.v1",
+			"esi-markets.read_character_orders.v1",
 		}),
 
 		// EVE SSO Configuration
@@ -77,6 +126,9 @@ func Load() *Config {
 		EVESSOAuthorizeURL: getEnv("EVE_SSO_AUTHORIZE_URL", "https://login.eveonline.com/v2/oauth/authorize"),
 		EVESSOTokenURL:     getEnv("EVE_SSO_TOKEN_URL", "https://login.eveonline.com/v2/oauth/token"),
 		EVESSOVerifyURL:    getEnv("EVE_SSO_VERIFY_URL", "https://login.eveonline.com/oauth/verify"),
+		EVESSOJWKSURL:      getEnv("EVE_SSO_JWKS_URL", "https://login.eveonline.com/oauth/jwks"),
+		EVESSOIssuer:       getEnv("EVE_SSO_ISSUER", "https://login.eveonline.com"),
+		EVESSOJWKSRefresh:  time.Duration(getEnvInt("EVE_SSO_JWKS_REFRESH", 3600)) * time.Second,
 
 		// Rate Limiting
 		ESIRateLimit:   getEnvInt("ESI_RATE_LIMIT", 150),
@@ -90,7 +142,52 @@ func Load() *Config {
 		CacheTTLCharacterInfo: time.Duration(getEnvInt("CACHE_TTL_CHARACTER_INFO", 1800)) * time.Second,
 
 		// Database Configuration
-		SDEDatabasePath: getEnv("SDE_DATABASE_PATH", "./data/sqlite-latest.sqlite"),
+		SDEDatabasePath:  getEnv("SDE_DATABASE_PATH", "./data/sqlite-latest.sqlite"),
+		SDEWatchInterval: time.Duration(getEnvInt("SDE_WATCH_INTERVAL", 60)) * time.Second,
+
+		// Application Database
+		AppDatabaseDriver:      getEnv("APP_DATABASE_DRIVER", "sqlite"),
+		AppDatabasePath:        getEnv("APP_DATABASE_PATH", "./data/app.sqlite"),
+		AppDatabaseURL:         getEnv("APP_DATABASE_URL", ""),
+		AppDatabaseAutoMigrate: getEnvBool("APP_DATABASE_AUTO_MIGRATE", true),
+		TokenEncryptionKey:     getEnv("TOKEN_ENCRYPTION_KEY", ""),
+		TokenStoreMemory:       getEnvBool("TOKEN_STORE_MEMORY", false),
+		TokenRefreshInterval:   time.Duration(getEnvInt("TOKEN_REFRESH_INTERVAL", 60)) * time.Second,
+		WalletSyncInterval:     time.Duration(getEnvInt("WALLET_SYNC_INTERVAL", 3600)) * time.Second,
+
+		// Order Undercut Monitor
+		UndercutCheckInterval: time.Duration(getEnvInt("UNDERCUT_CHECK_INTERVAL", 0)) * time.Second,
+		UndercutWebhookURL:    getEnv("UNDERCUT_WEBHOOK_URL", ""),
+
+		// Market Snapshot Archive (minerals and PLEX in The Forge)
+		MarketArchiveInterval:        time.Duration(getEnvInt("MARKET_ARCHIVE_INTERVAL", 0)) * time.Second,
+		MarketArchiveRegions:         getEnvInt32Slice("MARKET_ARCHIVE_REGIONS", []int32{10000002}),
+		MarketArchiveTypes:           getEnvInt32Slice("MARKET_ARCHIVE_TYPES", []int32{34, 35, 36, 37, 38, 39, 40, 11399, 44992}),
+		MarketArchiveOrderBooks:      getEnvBool("MARKET_ARCHIVE_ORDER_BOOKS", false),
+		MarketArchiveRawRetention:    time.Duration(getEnvInt("MARKET_ARCHIVE_RAW_RETENTION", 48)) * time.Hour,
+		MarketArchiveHourlyRetention: time.Duration(getEnvInt("MARKET_ARCHIVE_HOURLY_RETENTION", 90)) * 24 * time.Hour,
+		OrderEventRetention:          time.Duration(getEnvInt("ORDER_EVENT_RETENTION", 30)) * 24 * time.Hour,
+
+		// Price Alerts
+		AlertCheckInterval:     time.Duration(getEnvInt("ALERT_CHECK_INTERVAL", 0)) * time.Second,
+		AlertDefaultCooldown:   time.Duration(getEnvInt("ALERT_DEFAULT_COOLDOWN", 3600)) * time.Second,
+		AlertWebhookURL:        getEnv("ALERT_WEBHOOK_URL", ""),
+		AlertDiscordWebhookURL: getEnv("ALERT_DISCORD_WEBHOOK_URL", ""),
+		SMTPHost:               getEnv("SMTP_HOST", ""),
+		SMTPPort:               getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:               getEnv("SMTP_FROM", "eve-profit@localhost"),
+		AlertEmailTo:           getEnv("ALERT_EMAIL_TO", ""),
+
+		// Price Stream
+		PriceStreamInterval: time.Duration(getEnvInt("PRICE_STREAM_INTERVAL", 60)) * time.Second,
+		PriceStreamBuffer:   getEnvInt("PRICE_STREAM_BUFFER", 256),
+
+		// Arbitrage Scanner Configuration (The Forge, Domain, Sinq Laison, Heimatar, Metropolis)
+		ArbitrageRegions:      getEnvInt32Slice("ARBITRAGE_REGIONS", []int32{10000002, 10000043, 10000032, 10000030, 10000042}),
+		ArbitrageSalesTax:     getEnvFloat("ARBITRAGE_SALES_TAX", 0.075),
+		ArbitrageScanInterval: time.Duration(getEnvInt("ARBITRAGE_SCAN_INTERVAL", 0)) * time.Second,
 	}
 }
 
@@ -124,6 +221,36 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+		// Log warning for invalid values but continue with default
+		fmt.Printf("Warning: Invalid float value for %s: %s, using default: %g\n", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvInt32Slice(key string, defaultValue []int32) []int32 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []int32
+	for _, field := range strings.Fields(value) {
+		intVal, err := strconv.ParseInt(field, 10, 32)
+		if err != nil {
+			// Log warning for invalid values but continue with default
+			fmt.Printf("Warning: Invalid integer list for %s: %s, using default\n", key, value)
+			return defaultValue
+		}
+		result = append(result, int32(intVal))
+	}
+	return result
+}
+
 func getEnvSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		return strings.Fields(value) // Split by whitespace
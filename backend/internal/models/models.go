@@ -28,6 +28,13 @@ type MarketHistory struct {
 	Volume     int64     `json:"volume"`
 }
 
+// MarketPrice represents universe-wide average and adjusted prices from ESI
+type MarketPrice struct {
+	TypeID        int32   `json:"type_id"`
+	AveragePrice  float64 `json:"average_price"`
+	AdjustedPrice float64 `json:"adjusted_price"`
+}
+
 // TypeInfo represents type information from ESI
 type TypeInfo struct {
 	TypeID      int32   `json:"type_id"`
@@ -59,6 +66,76 @@ type Item struct {
 	Volume       float64 `json:"volume" db:"volume"`
 	Mass         float64 `json:"mass" db:"mass"`
 	Description  string  `json:"description" db:"description"`
+	MarketGroup  int32   `json:"market_group_id,omitempty" db:"marketGroupID"`
+}
+
+// ItemCategory represents an item category from SDE
+type ItemCategory struct {
+	CategoryID   int32  `json:"category_id"`
+	CategoryName string `json:"category_name"`
+}
+
+// ItemGroup represents an item group from SDE
+type ItemGroup struct {
+	GroupID    int32  `json:"group_id"`
+	GroupName  string `json:"group_name"`
+	CategoryID int32  `json:"category_id"`
+}
+
+// MarketGroup represents a node of the in-game market browser tree
+type MarketGroup struct {
+	MarketGroupID int32          `json:"market_group_id"`
+	ParentGroupID int32          `json:"parent_group_id,omitempty"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description,omitempty"`
+	HasTypes      bool           `json:"has_types"`
+	Children      []*MarketGroup `json:"children,omitempty"`
+}
+
+// ItemAttribute is a dogma attribute value of an item
+type ItemAttribute struct {
+	AttributeID int32   `json:"attribute_id"`
+	Name        string  `json:"name"`
+	DisplayName string  `json:"display_name"`
+	Value       float64 `json:"value"`
+	Unit        string  `json:"unit,omitempty"`
+	HighIsGood  bool    `json:"high_is_good"`
+}
+
+// ItemAttributes lists the published dogma attributes of an item
+type ItemAttributes struct {
+	TypeID     int32           `json:"type_id"`
+	TypeName   string          `json:"type_name"`
+	MetaLevel  int32           `json:"meta_level"`
+	TechLevel  int32           `json:"tech_level"` // 0 if the type has no tech level
+	Attributes []ItemAttribute `json:"attributes"`
+}
+
+// ComparedItem is one column of an item comparison
+type ComparedItem struct {
+	TypeID    int32  `json:"type_id"`
+	TypeName  string `json:"type_name"`
+	MetaLevel int32  `json:"meta_level"`
+	TechLevel int32  `json:"tech_level"`
+}
+
+// ComparedAttribute is one row of an item comparison. Values are in the order of
+// ItemComparison.Items, nil where an item lacks the attribute.
+type ComparedAttribute struct {
+	AttributeID int32      `json:"attribute_id"`
+	Name        string     `json:"name"`
+	DisplayName string     `json:"display_name"`
+	Unit        string     `json:"unit,omitempty"`
+	HighIsGood  bool       `json:"high_is_good"`
+	Values      []*float64 `json:"values"`
+	Differs     bool       `json:"differs"`
+	BestTypeID  int32      `json:"best_type_id,omitempty"` // Omitted if all values are equal
+}
+
+// ItemComparison lists the attributes of several items side by side
+type ItemComparison struct {
+	Items      []ComparedItem       `json:"items"`
+	Attributes []*ComparedAttribute `json:"attributes"`
 }
 
 // Station represents a station/structure from SDE
@@ -97,13 +174,14 @@ type Character struct {
 
 // CharacterAsset represents character assets
 type CharacterAsset struct {
-	ItemID       int64  `json:"item_id"`
-	TypeID       int32  `json:"type_id"`
-	LocationID   int64  `json:"location_id"`
-	LocationFlag string `json:"location_flag"`
-	LocationType string `json:"location_type"`
-	Quantity     int32  `json:"quantity"`
-	IsSingleton  bool   `json:"is_singleton"`
+	ItemID          int64  `json:"item_id"`
+	TypeID          int32  `json:"type_id"`
+	LocationID      int64  `json:"location_id"`
+	LocationFlag    string `json:"location_flag"`
+	LocationType    string `json:"location_type"`
+	Quantity        int32  `json:"quantity"`
+	IsSingleton     bool   `json:"is_singleton"`
+	IsBlueprintCopy bool   `json:"is_blueprint_copy,omitempty"`
 }
 
 // CharacterWallet represents wallet balance
@@ -129,6 +207,35 @@ type CharacterOrder struct {
 	Escrow       float64   `json:"escrow,omitempty"`
 }
 
+// Order competition states reported by the undercut monitor
+const (
+	OrderStatusLeading  = "leading"  // No better competing order in range
+	OrderStatusUndercut = "undercut" // A cheaper sell order at the same station
+	OrderStatusOutbid   = "outbid"   // A higher buy order covering the station
+)
+
+// OrderUndercut is the competition check of one open character order
+type OrderUndercut struct {
+	CharacterID       int32   `json:"character_id"`
+	OrderID           int64   `json:"order_id"`
+	TypeID            int32   `json:"type_id"`
+	RegionID          int32   `json:"region_id"`
+	LocationID        int64   `json:"location_id"`
+	IsBuyOrder        bool    `json:"is_buy_order"`
+	Price             float64 `json:"price"`
+	VolumeRemain      int32   `json:"volume_remain"`
+	Status            string  `json:"status"`
+	CompetitorOrderID int64   `json:"competitor_order_id,omitempty"`
+	CompetitorPrice   float64 `json:"competitor_price,omitempty"`
+	Difference        float64 `json:"difference"` // ISK per unit the competitor is better
+	DifferencePercent float64 `json:"difference_percent"`
+	SuggestedPrice    float64 `json:"suggested_price,omitempty"` // One price tick better than the competitor
+	ModificationFee   float64 `json:"modification_fee,omitempty"`
+	ExpectedProfit    float64 `json:"expected_profit,omitempty"` // Result of the remaining volume at the suggested price after fees
+	ProfitKnown       bool    `json:"profit_known"`
+	RepriceProfitable bool    `json:"reprice_profitable"`
+}
+
 // CharacterSkill represents character skills
 type CharacterSkill struct {
 	SkillID            int32 `json:"skill_id"`
@@ -137,14 +244,175 @@ type CharacterSkill struct {
 	ActiveSkillLevel   int32 `json:"active_skill_level"`
 }
 
+// CharacterSkills represents the trained skills of a character
+type CharacterSkills struct {
+	CharacterID   int32            `json:"character_id"`
+	TotalSP       int64            `json:"total_sp"`
+	UnallocatedSP int32            `json:"unallocated_sp"`
+	Skills        []CharacterSkill `json:"skills"`
+}
+
+// TradeSkillLevels are the active levels of the skills that shape trading
+type TradeSkillLevels struct {
+	Trade           int32 `json:"trade"`
+	Retail          int32 `json:"retail"`
+	Wholesale       int32 `json:"wholesale"`
+	Tycoon          int32 `json:"tycoon"`
+	Marketing       int32 `json:"marketing"`
+	Procurement     int32 `json:"procurement"`
+	Daytrading      int32 `json:"daytrading"`
+	Accounting      int32 `json:"accounting"`
+	BrokerRelations int32 `json:"broker_relations"`
+	MarginTrading   int32 `json:"margin_trading"`
+}
+
+// TradeProfile is the effective trading capability of a character derived from its skills.
+// Broker fees do not include standings towards the station owner.
+type TradeProfile struct {
+	CharacterID          int32            `json:"character_id"`
+	Skills               TradeSkillLevels `json:"skills"`
+	MaxOrders            int              `json:"max_orders"`
+	SellOrderRange       string           `json:"sell_order_range"`   // Farthest remote sell order placement (Marketing)
+	BuyOrderRange        string           `json:"buy_order_range"`    // Farthest remote buy order placement (Procurement)
+	ModifyOrderRange     string           `json:"modify_order_range"` // Farthest remote order modification (Daytrading)
+	BrokerFee            float64          `json:"broker_fee"`
+	BrokerFeeReduction   float64          `json:"broker_fee_reduction"`
+	SalesTax             float64          `json:"sales_tax"`
+	SalesTaxReduction    float64          `json:"sales_tax_reduction"`
+	EscrowRate           float64          `json:"escrow_rate"` // Share of a buy order's value held in escrow (Margin Trading)
+	ModificationCooldown int              `json:"modification_cooldown"`
+	ModificationFeeRate  float64          `json:"modification_fee_rate"` // Broker fee charged on the new price of a modified order
+	ModificationMinFee   float64          `json:"modification_min_fee"`
+}
+
+// WalletJournalEntry is an entry of a character's wallet journal
+type WalletJournalEntry struct {
+	ID            int64     `json:"id"`
+	Date          time.Time `json:"date"`
+	RefType       string    `json:"ref_type"`
+	Amount        float64   `json:"amount"`
+	Balance       float64   `json:"balance"`
+	Tax           float64   `json:"tax,omitempty"`
+	ContextID     int64     `json:"context_id,omitempty"`
+	ContextIDType string    `json:"context_id_type,omitempty"`
+	Description   string    `json:"description"`
+	FirstPartyID  int32     `json:"first_party_id,omitempty"`
+	SecondPartyID int32     `json:"second_party_id,omitempty"`
+}
+
+// WalletTransaction is a market transaction of a character
+type WalletTransaction struct {
+	TransactionID int64     `json:"transaction_id"`
+	Date          time.Time `json:"date"`
+	TypeID        int32     `json:"type_id"`
+	Quantity      int32     `json:"quantity"`
+	UnitPrice     float64   `json:"unit_price"`
+	LocationID    int64     `json:"location_id"`
+	ClientID      int32     `json:"client_id"`
+	IsBuy         bool      `json:"is_buy"`
+	IsPersonal    bool      `json:"is_personal"`
+	JournalRefID  int64     `json:"journal_ref_id"`
+}
+
+// WalletSyncResult reports the new entries stored by a wallet sync
+type WalletSyncResult struct {
+	CharacterID    int32     `json:"character_id"`
+	JournalEntries int       `json:"journal_entries"`
+	Transactions   int       `json:"transactions"`
+	SyncedAt       time.Time `json:"synced_at"`
+}
+
+// ProfitLoss sums the realized result of sales
+type ProfitLoss struct {
+	Quantity   int64   `json:"quantity"`
+	Revenue    float64 `json:"revenue"`
+	Cost       float64 `json:"cost"` // Purchase cost of the sold units including buy broker fees
+	SalesTax   float64 `json:"sales_tax"`
+	BrokerFees float64 `json:"broker_fees"` // Sell-side broker fees
+	Profit     float64 `json:"profit"`
+}
+
+// ItemProfitLoss is the realized result of one type
+type ItemProfitLoss struct {
+	TypeID int32 `json:"type_id"`
+	ProfitLoss
+	UnmatchedQuantity int64 `json:"unmatched_quantity"` // Sold units without a known purchase, excluded from the result
+}
+
+// DailyProfitLoss is the realized result of one day (UTC)
+type DailyProfitLoss struct {
+	Date string `json:"date"`
+	ProfitLoss
+}
+
+// StationProfitLoss is the realized result of the sales at one station
+type StationProfitLoss struct {
+	LocationID int64 `json:"location_id"`
+	ProfitLoss
+}
+
+// UnrealizedPosition is remaining stock valued at the current market price
+type UnrealizedPosition struct {
+	TypeID      int32   `json:"type_id"`
+	Quantity    int64   `json:"quantity"`
+	CostBasis   float64 `json:"cost_basis"`
+	AverageCost float64 `json:"average_cost"`
+	MarketPrice float64 `json:"market_price"`
+	MarketValue float64 `json:"market_value"`
+	Profit      float64 `json:"profit"`
+	Priced      bool    `json:"priced"`
+}
+
+// ProfitLossReport answers whether trading actually made money
+type ProfitLossReport struct {
+	CharacterID           int32                `json:"character_id"`
+	Method                string               `json:"method"`
+	Realized              ProfitLoss           `json:"realized"`
+	UnallocatedBrokerFees float64              `json:"unallocated_broker_fees"` // Broker fees without a transaction, e.g. order modifications
+	ByItem                []ItemProfitLoss     `json:"by_item"`
+	ByDay                 []DailyProfitLoss    `json:"by_day"`
+	ByStation             []StationProfitLoss  `json:"by_station"`
+	Unrealized            []UnrealizedPosition `json:"unrealized"`
+	UnrealizedProfit      float64              `json:"unrealized_profit"`
+	PriceRegionID         int32                `json:"price_region_id,omitempty"`
+	GeneratedAt           time.Time            `json:"generated_at"`
+}
+
 // AuthToken represents OAuth tokens
 type AuthToken struct {
-	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
-	TokenType    string    `json:"token_type"`
-	ExpiresIn    int       `json:"expires_in"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CharacterID  int32     `json:"character_id"`
+	AccessToken   string    `json:"access_token"`
+	RefreshToken  string    `json:"refresh_token"`
+	TokenType     string    `json:"token_type"`
+	ExpiresIn     int       `json:"expires_in"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CharacterID   int32     `json:"character_id"`
+	CharacterName string    `json:"character_name"`
+	Scopes        []string  `json:"scopes"`
+	Revoked       bool      `json:"revoked"` // The SSO rejected the refresh token, the character must log in again
+}
+
+// Account groups the characters of one person. Each character belongs to one account.
+type Account struct {
+	AccountID         int64             `json:"account_id"`
+	ActiveCharacterID int32             `json:"active_character_id"`
+	CreatedAt         time.Time         `json:"created_at"`
+	Characters        []LinkedCharacter `json:"characters"`
+}
+
+// LinkedCharacter is a character linked to an account via SSO
+type LinkedCharacter struct {
+	CharacterID   int32     `json:"character_id"`
+	CharacterName string    `json:"character_name"`
+	LinkedAt      time.Time `json:"linked_at"`
+	Active        bool      `json:"active"`
+}
+
+// AccessClaims are the verified claims of an EVE SSO access token
+type AccessClaims struct {
+	CharacterID   int32     `json:"character_id"`
+	CharacterName string    `json:"character_name"`
+	Scopes        []string  `json:"scopes"`
+	ExpiresAt     time.Time `json:"expires_at"`
 }
 
 // APIResponse represents a generic API response
@@ -162,3 +430,330 @@ type CacheEntry struct {
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// SDEVersion identifies the static data export a repository is serving
+type SDEVersion struct {
+	BuildNumber int64     `json:"build_number,omitempty"` // 0 if the database carries no metadata
+	ReleaseDate string    `json:"release_date,omitempty"`
+	Checksum    string    `json:"checksum,omitempty"`
+	Path        string    `json:"path"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	LoadedAt    time.Time `json:"loaded_at"`
+}
+
+// AppSchemaVersion reports the migration state of the application database
+type AppSchemaVersion struct {
+	Driver        string    `json:"driver"`
+	Version       int       `json:"version"`
+	LatestVersion int       `json:"latest_version"` // Newest migration known to this build
+	UpToDate      bool      `json:"up_to_date"`
+	MigratedAt    time.Time `json:"migrated_at"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Root location kinds of valued assets
+const (
+	AssetLocationStation     = "station"
+	AssetLocationStructure   = "structure" // Player structure, not visible as an asset itself
+	AssetLocationSolarSystem = "solar_system"
+	AssetLocationOther       = "other"
+)
+
+// AssetTypeValue is the value of all units of one type
+type AssetTypeValue struct {
+	TypeID          int32   `json:"type_id"`
+	TypeName        string  `json:"type_name,omitempty"`
+	CategoryID      int32   `json:"category_id"`
+	CategoryName    string  `json:"category_name,omitempty"`
+	IsBlueprintCopy bool    `json:"is_blueprint_copy,omitempty"` // Copies have no market price
+	Quantity        int64   `json:"quantity"`
+	UnitPrice       float64 `json:"unit_price"`
+	Value           float64 `json:"value"`
+}
+
+// LocationValue is the value of all assets at a station, structure or in space
+type LocationValue struct {
+	LocationID   int64   `json:"location_id"`
+	LocationType string  `json:"location_type"`
+	Value        float64 `json:"value"`
+	Stacks       int     `json:"stacks"`
+	Unpriced     int     `json:"unpriced"` // Stacks without a price
+}
+
+// CategoryValue is the value of all assets of one item category
+type CategoryValue struct {
+	CategoryID   int32   `json:"category_id"`
+	CategoryName string  `json:"category_name,omitempty"`
+	Value        float64 `json:"value"`
+	Quantity     int64   `json:"quantity"`
+}
+
+// CharacterValue is the net worth of the assets of one character
+type CharacterValue struct {
+	CharacterID int32   `json:"character_id"`
+	Value       float64 `json:"value"`
+	Stacks      int     `json:"stacks"`
+}
+
+// ContainerValue is the value of a ship or container including everything in it
+type ContainerValue struct {
+	CharacterID  int32   `json:"character_id"`
+	ItemID       int64   `json:"item_id"`
+	TypeID       int32   `json:"type_id"`
+	LocationID   int64   `json:"location_id"` // Root location
+	OwnValue     float64 `json:"own_value"`
+	ContentValue float64 `json:"content_value"` // Fitted modules, cargo and nested containers
+	Value        float64 `json:"value"`
+	Items        int     `json:"items"`
+}
+
+// RouteSecurity summarizes the security of the systems along a route, both ends included
+type RouteSecurity struct {
+	MinSecurity    float64 `json:"min_security"`
+	HighSecSystems int     `json:"high_sec_systems"`
+	LowSecSystems  int     `json:"low_sec_systems"`
+	NullSecSystems int     `json:"null_sec_systems"`
+}
+
+// ConsolidationStop is a station or system holding assets worth moving to the target hub
+type ConsolidationStop struct {
+	LocationID      int64         `json:"location_id"`
+	LocationType    string        `json:"location_type"`
+	LocationName    string        `json:"location_name,omitempty"`
+	SolarSystemID   int32         `json:"solar_system_id"`
+	SolarSystemName string        `json:"solar_system_name,omitempty"`
+	RegionID        int32         `json:"region_id"`
+	Reachable       bool          `json:"reachable"` // False if no stargate route to the hub exists
+	Jumps           int           `json:"jumps"`
+	Route           []int32       `json:"route,omitempty"` // Solar systems from the location to the hub
+	Security        RouteSecurity `json:"security"`
+	Characters      []int32       `json:"characters"`
+	Stacks          int           `json:"stacks"`
+	Volume          float64       `json:"volume"` // m³ of the top level stacks, contents of ships and containers move with them
+	LocalValue      float64       `json:"local_value"`
+	HubValue        float64       `json:"hub_value"`
+	ValueGained     float64       `json:"value_gained"`
+	ValuePerM3      float64       `json:"value_per_m3"`
+	Unpriced        int           `json:"unpriced"` // Stacks without a local or hub price
+}
+
+// MarketSnapshot is the aggregated order book of one type in a region at one point in time.
+// Downsampled snapshots average the prices and volumes of all raw samples in their bucket.
+type MarketSnapshot struct {
+	RegionID   int32     `json:"region_id"`
+	TypeID     int32     `json:"type_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Resolution int       `json:"resolution"` // Bucket length in seconds, 0 for a raw sample
+	Samples    int       `json:"samples"`
+	BuyMax     float64   `json:"buy_max"`
+	SellMin    float64   `json:"sell_min"`
+	BuyVolume  int64     `json:"buy_volume"`
+	SellVolume int64     `json:"sell_volume"`
+	BuyOrders  int       `json:"buy_orders"`
+	SellOrders int       `json:"sell_orders"`
+}
+
+// PricePoint is one point of an intraday price curve
+type PricePoint struct {
+	Timestamp  time.Time `json:"timestamp"`
+	BuyMax     float64   `json:"buy_max"`
+	SellMin    float64   `json:"sell_min"`
+	Spread     float64   `json:"spread"` // Sell minus buy price relative to the sell price
+	BuyVolume  int64     `json:"buy_volume"`
+	SellVolume int64     `json:"sell_volume"`
+	Samples    int       `json:"samples"`
+}
+
+// PriceCurve is the archived price development of one type in a region
+type PriceCurve struct {
+	RegionID int32        `json:"region_id"`
+	TypeID   int32        `json:"type_id"`
+	From     time.Time    `json:"from"`
+	To       time.Time    `json:"to"`
+	Interval int          `json:"interval"` // Bucket length in seconds, 0 returns the stored points
+	Tracked  bool         `json:"tracked"`  // Whether the collector still archives this type
+	Points   []PricePoint `json:"points"`
+}
+
+// Order events inferred from consecutive order book snapshots
+const (
+	OrderEventNew         = "new"
+	OrderEventPartialFill = "partial_fill"
+	OrderEventFilled      = "filled"
+	OrderEventModified    = "modified"
+	OrderEventCancelled   = "cancelled"
+	OrderEventExpired     = "expired"
+)
+
+// OrderEvent is a change of one market order between two order book snapshots.
+// Fills of vanished orders are estimates, a vanished order at the best price of its
+// station counts as filled and any other order as cancelled.
+type OrderEvent struct {
+	RegionID      int32     `json:"region_id"`
+	TypeID        int32     `json:"type_id"`
+	OrderID       int64     `json:"order_id"`
+	Type          string    `json:"type"`
+	IsBuyOrder    bool      `json:"is_buy_order"`
+	LocationID    int64     `json:"location_id"`
+	Price         float64   `json:"price"`
+	PreviousPrice float64   `json:"previous_price,omitempty"` // Price before a modification
+	Volume        int64     `json:"volume"`                   // Traded units of fills, listed units of new orders, remaining units otherwise
+	Timestamp     time.Time `json:"timestamp"`                // Snapshot in which the change was seen
+}
+
+// OrderEventCursor is the position of an event in the oldest first event stream.
+// Events are ordered by timestamp, order ID and event type. A cursor without order
+// ID points behind all events of its second.
+type OrderEventCursor struct {
+	Timestamp time.Time
+	OrderID   int64
+	EventType string
+}
+
+// OrderEventPage is one page of the event stream. Next resumes behind the last
+// event, or at the requested position if the page is empty.
+type OrderEventPage struct {
+	Events  []OrderEvent `json:"events"`
+	Next    string       `json:"next"`
+	HasMore bool         `json:"has_more"` // More events are available right away
+}
+
+// HourlyTradeVolume is the estimated traded volume of one hour
+type HourlyTradeVolume struct {
+	Hour        time.Time `json:"hour"`
+	SoldUnits   int64     `json:"sold_units"` // Units bought from sell orders
+	SoldValue   float64   `json:"sold_value"`
+	BoughtUnits int64     `json:"bought_units"` // Units sold into buy orders
+	BoughtValue float64   `json:"bought_value"`
+	Fills       int       `json:"fills"`
+}
+
+// OrderFlowReport summarizes the order events of a type as a proxy for real sell-through
+type OrderFlowReport struct {
+	RegionID        int32               `json:"region_id"`
+	TypeID          int32               `json:"type_id"`
+	LocationID      int64               `json:"location_id,omitempty"` // 0 for the whole region
+	From            time.Time           `json:"from"`
+	To              time.Time           `json:"to"`
+	SoldUnits       int64               `json:"sold_units"`
+	SoldValue       float64             `json:"sold_value"`
+	BoughtUnits     int64               `json:"bought_units"`
+	BoughtValue     float64             `json:"bought_value"`
+	SellVelocity    float64             `json:"sell_velocity"` // Sold units per hour
+	BuyVelocity     float64             `json:"buy_velocity"`  // Bought units per hour
+	NewOrders       int                 `json:"new_orders"`
+	ModifiedOrders  int                 `json:"modified_orders"`
+	CancelledOrders int                 `json:"cancelled_orders"`
+	ExpiredOrders   int                 `json:"expired_orders"`
+	Hourly          []HourlyTradeVolume `json:"hourly"`
+}
+
+// Metrics compared by alert rules
+const (
+	AlertMetricSell     = "sell"     // Lowest sell price
+	AlertMetricBuy      = "buy"      // Highest buy price
+	AlertMetricSpread   = "spread"   // Sell minus buy price in percent of the sell price
+	AlertMetricVolume   = "volume"   // Units traded on the last day of the market history
+	AlertMetricUndercut = "undercut" // Own orders undercut or outbid
+)
+
+// Notification channels of alert rules
+const (
+	AlertChannelWebhook = "webhook"
+	AlertChannelDiscord = "discord"
+	AlertChannelEmail   = "email"
+)
+
+// AlertCondition is the parsed expression of an alert rule, e.g. "Jita sell < 5"
+// or "volume > 3x 30d average"
+type AlertCondition struct {
+	Metric      string  `json:"metric"`
+	Hub         string  `json:"hub,omitempty"`
+	RegionID    int32   `json:"region_id,omitempty"`
+	StationID   int64   `json:"station_id,omitempty"` // 0 for the whole region
+	Operator    string  `json:"operator,omitempty"`
+	Threshold   float64 `json:"threshold,omitempty"`
+	Multiplier  float64 `json:"multiplier,omitempty"`   // Compares against a multiple of the history average instead of the threshold
+	AverageDays int     `json:"average_days,omitempty"` // History days averaged for multipliers
+}
+
+// AlertRule is an alert of a character on one item. Triggered rules stay quiet
+// until their condition clears or, for undercuts, other competitors appear.
+type AlertRule struct {
+	RuleID          int64           `json:"rule_id"`
+	CharacterID     int32           `json:"character_id"`
+	Name            string          `json:"name"`
+	TypeID          int32           `json:"type_id,omitempty"` // Optional for undercut rules
+	Expression      string          `json:"expression"`
+	Condition       *AlertCondition `json:"condition,omitempty"`
+	Channels        []string        `json:"channels"` // Empty delivers to all configured channels
+	Email           string          `json:"email,omitempty"`
+	CooldownSeconds int             `json:"cooldown_seconds"` // 0 uses the default cooldown
+	Enabled         bool            `json:"enabled"`
+	Triggered       bool            `json:"triggered"`
+	LastValue       float64         `json:"last_value"`
+	LastKey         string          `json:"-"` // Identifies the undercuts of the last notification
+	LastCheckedAt   time.Time       `json:"last_checked_at"`
+	LastTriggeredAt time.Time       `json:"last_triggered_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Revision        int64           `json:"-"` // Bumped by every update, guards stored trigger state
+}
+
+// AlertNotification is sent to the channels of a triggered alert rule
+type AlertNotification struct {
+	RuleID      int64           `json:"rule_id"`
+	CharacterID int32           `json:"character_id"`
+	Name        string          `json:"name"`
+	TypeID      int32           `json:"type_id,omitempty"`
+	TypeName    string          `json:"type_name,omitempty"`
+	Expression  string          `json:"expression"`
+	Hub         string          `json:"hub,omitempty"`
+	Value       float64         `json:"value"`
+	Threshold   float64         `json:"threshold"` // Effective threshold, multiplied averages resolved
+	Message     string          `json:"message"`
+	Email       string          `json:"email,omitempty"`
+	Orders      []OrderUndercut `json:"orders,omitempty"`
+	TriggeredAt time.Time       `json:"triggered_at"`
+}
+
+// PriceUpdate is a refreshed ItemPrice pushed to price stream subscribers
+type PriceUpdate struct {
+	RegionID int32 `json:"region_id"`
+	ItemPrice
+}
+
+// Watchlist is a named list of watched types of a character
+type Watchlist struct {
+	WatchlistID int64           `json:"watchlist_id"`
+	CharacterID int32           `json:"character_id"`
+	Name        string          `json:"name"`
+	Notes       string          `json:"notes"`
+	ItemCount   int             `json:"item_count"`
+	Items       []WatchlistItem `json:"items,omitempty"` // Not included in the list of all watchlists
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// WatchlistItem is a watched type in its target region, optionally narrowed to one station
+type WatchlistItem struct {
+	TypeID    int32      `json:"type_id"`
+	TypeName  string     `json:"type_name,omitempty"`
+	RegionID  int32      `json:"region_id"`
+	StationID int64      `json:"station_id,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	Price     *ItemPrice `json:"price,omitempty"` // Live price in the region or station, nil if unavailable
+}
+
+// SavedSearch is a named item search of a character. Like the search endpoint it
+// finds published types unless Published is false, a nil Marketable disables that filter.
+type SavedSearch struct {
+	SearchID      int64     `json:"search_id"`
+	CharacterID   int32     `json:"character_id"`
+	Name          string    `json:"name"`
+	Query         string    `json:"query"`
+	CategoryID    int32     `json:"category_id,omitempty"`
+	MarketGroupID int32     `json:"market_group_id,omitempty"`
+	Published     *bool     `json:"published,omitempty"`
+	Marketable    *bool     `json:"marketable,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
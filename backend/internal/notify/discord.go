@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Discord limits embeds to 25 fields, a few are kept for the rule itself
+const maxDiscordOrderFields = 20
+
+// Embed colors of Discord alerts
+const (
+	discordColorPrice    = 0x3498db
+	discordColorUndercut = 0xe67e22
+)
+
+// DiscordMessage is the JSON body of a Discord webhook execution
+type DiscordMessage struct {
+	Content string         `json:"content"`
+	Embeds  []DiscordEmbed `json:"embeds,omitempty"`
+}
+
+// DiscordEmbed is a rich message block of a Discord message
+type DiscordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+	Fields      []DiscordField `json:"fields,omitempty"`
+}
+
+// DiscordField is a name and value pair of an embed
+type DiscordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// DiscordWebhook posts alerts as messages to a Discord channel webhook or any
+// service accepting the same format
+type DiscordWebhook struct {
+	webhook *Webhook
+}
+
+func NewDiscordWebhook(url string, options ...WebhookOption) *DiscordWebhook {
+	return &DiscordWebhook{webhook: NewWebhook(url, options...)}
+}
+
+// SendAlert posts a triggered alert rule as a message with one embed
+func (d *DiscordWebhook) SendAlert(ctx context.Context, alert models.AlertNotification) error {
+	return d.webhook.post(ctx, NewDiscordAlertMessage(alert))
+}
+
+// NewDiscordAlertMessage formats an alert, undercut orders are listed as fields
+func NewDiscordAlertMessage(alert models.AlertNotification) DiscordMessage {
+	embed := DiscordEmbed{
+		Title:       alert.Name,
+		Description: alert.Message,
+		Color:       discordColorPrice,
+		Timestamp:   alert.TriggeredAt.Format(time.RFC3339),
+		Fields: []DiscordField{
+			{Name: "Rule", Value: alert.Expression, Inline: true},
+		},
+	}
+	if alert.TypeName != "" {
+		embed.Fields = append(embed.Fields, DiscordField{Name: "Item", Value: alert.TypeName, Inline: true})
+	}
+
+	if len(alert.Orders) > 0 {
+		embed.Color = discordColorUndercut
+		for i, order := range alert.Orders {
+			if i == maxDiscordOrderFields {
+				embed.Fields = append(embed.Fields, DiscordField{Name: "More orders", Value: strconv.Itoa(len(alert.Orders) - i)})
+				break
+			}
+			embed.Fields = append(embed.Fields, DiscordField{
+				Name:  fmt.Sprintf("Order %d (%s)", order.OrderID, order.Status),
+				Value: fmt.Sprintf("%s ISK, competitor %s ISK", formatISK(order.Price), formatISK(order.CompetitorPrice)),
+			})
+		}
+	}
+
+	return DiscordMessage{Content: alert.Message, Embeds: []DiscordEmbed{embed}}
+}
+
+func formatISK(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// ErrNoRecipient is returned for alerts without an email address when no default recipient is configured
+var ErrNoRecipient = errors.New("no email recipient")
+
+// EmailSender sends alerts as plain text mails through an SMTP server. The
+// connection is upgraded with STARTTLS whenever the server offers it.
+type EmailSender struct {
+	host      string
+	port      int
+	from      string
+	recipient string
+	auth      smtp.Auth
+	timeout   time.Duration
+}
+
+// EmailOption configures the email sender
+type EmailOption func(*EmailSender)
+
+// WithSMTPAuth logs in with PLAIN authentication, which net/smtp only allows
+// over TLS or to localhost
+func WithSMTPAuth(username, password string) EmailOption {
+	return func(s *EmailSender) {
+		if username != "" {
+			s.auth = smtp.PlainAuth("", username, password, s.host)
+		}
+	}
+}
+
+// WithDefaultRecipient sets the address of alerts without their own email address
+func WithDefaultRecipient(recipient string) EmailOption {
+	return func(s *EmailSender) {
+		s.recipient = recipient
+	}
+}
+
+// WithSMTPTimeout bounds the whole delivery of one mail
+func WithSMTPTimeout(timeout time.Duration) EmailOption {
+	return func(s *EmailSender) {
+		s.timeout = timeout
+	}
+}
+
+func NewEmailSender(host string, port int, from string, options ...EmailOption) *EmailSender {
+	sender := &EmailSender{
+		host:    host,
+		port:    port,
+		from:    from,
+		timeout: 30 * time.Second,
+	}
+	for _, option := range options {
+		option(sender)
+	}
+	return sender
+}
+
+// SendAlert mails a triggered alert rule to its email address or the default recipient
+func (s *EmailSender) SendAlert(ctx context.Context, alert models.AlertNotification) error {
+	recipient := alert.Email
+	if recipient == "" {
+		recipient = s.recipient
+	}
+	if recipient == "" {
+		return ErrNoRecipient
+	}
+	return s.send(ctx, recipient, "EVE Profit alert: "+alert.Name, alertMailBody(alert))
+}
+
+// send delivers one mail, the SMTP conversation of smtp.SendMail bounded by the timeout
+func (s *EmailSender) send(ctx context.Context, recipient, subject, body string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.host, strconv.Itoa(s.port)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+	if s.auth != nil {
+		if err := client.Auth(s.auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(s.from); err != nil {
+		return fmt.Errorf("SMTP sender rejected: %w", err)
+	}
+	if err := client.Rcpt(recipient); err != nil {
+		return fmt.Errorf("SMTP recipient rejected: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP data failed: %w", err)
+	}
+	if _, err := writer.Write(s.message(recipient, subject, body)); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected mail: %w", err)
+	}
+	return client.Quit()
+}
+
+// message builds the mail with headers and CRLF line endings
+func (s *EmailSender) message(recipient, subject, body string) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return msg.Bytes()
+}
+
+func alertMailBody(alert models.AlertNotification) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\n\n", alert.Message)
+	fmt.Fprintf(&body, "Rule: %s\n", alert.Expression)
+	if alert.TypeName != "" {
+		fmt.Fprintf(&body, "Item: %s\n", alert.TypeName)
+	}
+	fmt.Fprintf(&body, "Triggered: %s\n", alert.TriggeredAt.UTC().Format(time.RFC3339))
+
+	if len(alert.Orders) > 0 {
+		body.WriteString("\nOrders:\n")
+		for _, order := range alert.Orders {
+			fmt.Fprintf(&body, "- Order %d (%s): %s ISK, competitor %s ISK\n",
+				order.OrderID, order.Status, formatISK(order.Price), formatISK(order.CompetitorPrice))
+		}
+	}
+	return body.String()
+}
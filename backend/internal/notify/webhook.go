@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Event names sent in the webhook payload
+const (
+	EventOrdersUndercut = "orders_undercut"
+	EventPriceAlert     = "price_alert"
+)
+
+// WebhookPayload is the JSON body posted to the webhook
+type WebhookPayload struct {
+	Event  string                    `json:"event"`
+	SentAt time.Time                 `json:"sent_at"`
+	Orders []models.OrderUndercut    `json:"orders,omitempty"`
+	Alert  *models.AlertNotification `json:"alert,omitempty"`
+}
+
+// Webhook posts notifications as JSON to a URL
+type Webhook struct {
+	url        string
+	httpClient *http.Client
+}
+
+// WebhookOption configures the webhook
+type WebhookOption func(*Webhook)
+
+// WithWebhookHTTPClient sets the HTTP client used for posting
+func WithWebhookHTTPClient(client *http.Client) WebhookOption {
+	return func(w *Webhook) {
+		w.httpClient = client
+	}
+}
+
+func NewWebhook(url string, options ...WebhookOption) *Webhook {
+	webhook := &Webhook{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, option := range options {
+		option(webhook)
+	}
+	return webhook
+}
+
+// NotifyUndercuts posts the newly undercut orders
+func (w *Webhook) NotifyUndercuts(ctx context.Context, undercuts []models.OrderUndercut) error {
+	return w.post(ctx, WebhookPayload{Event: EventOrdersUndercut, SentAt: time.Now(), Orders: undercuts})
+}
+
+// SendAlert posts a triggered alert rule
+func (w *Webhook) SendAlert(ctx context.Context, alert models.AlertNotification) error {
+	return w.post(ctx, WebhookPayload{Event: EventPriceAlert, SentAt: time.Now(), Alert: &alert})
+}
+
+func (w *Webhook) post(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
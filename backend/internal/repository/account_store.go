@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// ErrAccountNotFound is returned for characters that are not linked to an account
+var ErrAccountNotFound = errors.New("account not found")
+
+// AccountStore keeps accounts and their linked characters in the application database
+type AccountStore struct {
+	db *AppDB
+}
+
+// NewAccountStore fails unless the account tables were migrated
+func NewAccountStore(db *AppDB) (*AccountStore, error) {
+	if err := db.requireSchema(schemaVersionAccounts); err != nil {
+		return nil, err
+	}
+	return &AccountStore{db: db}, nil
+}
+
+// GetAccountByCharacter returns the account a character is linked to
+func (s *AccountStore) GetAccountByCharacter(characterID int32) (*models.Account, error) {
+	var accountID int64
+	err := s.db.QueryRow(`SELECT accountID FROM accountCharacters WHERE characterID = ?`, characterID).Scan(&accountID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: characterID %d", ErrAccountNotFound, characterID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return s.GetAccount(accountID)
+}
+
+// GetAccount returns an account with its characters ordered by link time
+func (s *AccountStore) GetAccount(accountID int64) (*models.Account, error) {
+	account := models.Account{AccountID: accountID}
+	var createdAt int64
+	err := s.db.QueryRow(`SELECT activeCharacterID, createdAt FROM accounts WHERE accountID = ?`, accountID).
+		Scan(&account.ActiveCharacterID, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: accountID %d", ErrAccountNotFound, accountID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	account.CreatedAt = time.Unix(createdAt, 0)
+
+	rows, err := s.db.Query(`
+		SELECT characterID, characterName, linkedAt FROM accountCharacters
+		WHERE accountID = ? ORDER BY linkedAt, characterID
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account characters: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var character models.LinkedCharacter
+		var linkedAt int64
+		if err := rows.Scan(&character.CharacterID, &character.CharacterName, &linkedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan account character: %w", err)
+		}
+		character.LinkedAt = time.Unix(linkedAt, 0)
+		character.Active = character.CharacterID == account.ActiveCharacterID
+		account.Characters = append(account.Characters, character)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateAccount creates an account with a single, active character
+func (s *AccountStore) CreateAccount(characterID int32, characterName string) (*models.Account, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	var accountID int64
+	err = tx.QueryRow(`INSERT INTO accounts (activeCharacterID, createdAt) VALUES (?, ?) RETURNING accountID`, characterID, now).
+		Scan(&accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+
+	if err := linkCharacter(tx, accountID, characterID, characterName, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit account: %w", err)
+	}
+	return s.GetAccount(accountID)
+}
+
+// LinkCharacter links a character to an account. A character linked elsewhere
+// is moved, an account left without characters is deleted.
+func (s *AccountStore) LinkCharacter(accountID int64, characterID int32, characterName string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previousAccountID int64
+	err = tx.QueryRow(`SELECT accountID FROM accountCharacters WHERE characterID = ?`, characterID).Scan(&previousAccountID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if err := linkCharacter(tx, accountID, characterID, characterName, time.Now().Unix()); err != nil {
+		return err
+	}
+	if previousAccountID != 0 && previousAccountID != accountID {
+		if err := repairAccount(tx, previousAccountID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UnlinkCharacter removes a character from its account
+func (s *AccountStore) UnlinkCharacter(accountID int64, characterID int32) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM accountCharacters WHERE accountID = ? AND characterID = ?`, accountID, characterID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink character: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("%w: character %d is not linked to account %d", ErrAccountNotFound, characterID, accountID)
+	}
+	if err := repairAccount(tx, accountID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetActiveCharacter switches the active character of an account
+func (s *AccountStore) SetActiveCharacter(accountID int64, characterID int32) error {
+	result, err := s.db.Exec(`
+		UPDATE accounts SET activeCharacterID = ?
+		WHERE accountID = ? AND EXISTS (
+			SELECT 1 FROM accountCharacters WHERE accountID = ? AND characterID = ?
+		)
+	`, characterID, accountID, accountID, characterID)
+	if err != nil {
+		return fmt.Errorf("failed to set active character: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("%w: character %d is not linked to account %d", ErrAccountNotFound, characterID, accountID)
+	}
+	return nil
+}
+
+func linkCharacter(tx *AppTx, accountID int64, characterID int32, characterName string, linkedAt int64) error {
+	_, err := tx.Exec(`
+		INSERT INTO accountCharacters (characterID, accountID, characterName, linkedAt) VALUES (?, ?, ?, ?)
+		ON CONFLICT(characterID) DO UPDATE SET
+			accountID = excluded.accountID,
+			characterName = excluded.characterName,
+			linkedAt = CASE WHEN accountCharacters.accountID = excluded.accountID
+				THEN accountCharacters.linkedAt ELSE excluded.linkedAt END
+	`, characterID, accountID, characterName, linkedAt)
+	if err != nil {
+		return fmt.Errorf("failed to link character: %w", err)
+	}
+	return nil
+}
+
+// repairAccount deletes an account without characters or picks a new active
+// character if the active one was removed
+func repairAccount(tx *AppTx, accountID int64) error {
+	var remaining int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM accountCharacters WHERE accountID = ?`, accountID).Scan(&remaining); err != nil {
+		return fmt.Errorf("failed to count account characters: %w", err)
+	}
+	if remaining == 0 {
+		if _, err := tx.Exec(`DELETE FROM accounts WHERE accountID = ?`, accountID); err != nil {
+			return fmt.Errorf("failed to delete account: %w", err)
+		}
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		UPDATE accounts SET activeCharacterID = (
+			SELECT characterID FROM accountCharacters WHERE accountID = ? ORDER BY linkedAt, characterID LIMIT 1
+		)
+		WHERE accountID = ? AND activeCharacterID NOT IN (
+			SELECT characterID FROM accountCharacters WHERE accountID = ?
+		)
+	`, accountID, accountID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to update active character: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// ErrAlertRuleNotFound is returned for alert rules that do not exist or belong to another character
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+const alertRuleColumns = `ruleID, characterID, name, typeID, expression, channels, email, cooldown, enabled,
+	triggered, lastValue, lastKey, lastCheckedAt, lastTriggeredAt, createdAt, revision`
+
+// AlertStore keeps the alert rules of characters and their trigger state in the application database
+type AlertStore struct {
+	db *AppDB
+}
+
+// NewAlertStore fails unless the alert tables were migrated
+func NewAlertStore(db *AppDB) (*AlertStore, error) {
+	if err := db.requireSchema(schemaVersionAlertRevisions); err != nil {
+		return nil, err
+	}
+	return &AlertStore{db: db}, nil
+}
+
+// CreateAlertRule stores a new rule of rule.CharacterID
+func (s *AlertStore) CreateAlertRule(rule *models.AlertRule) (*models.AlertRule, error) {
+	var ruleID int64
+	err := s.db.QueryRow(`
+		INSERT INTO alertRules (characterID, name, typeID, expression, channels, email, cooldown, enabled, createdAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ruleID
+	`, rule.CharacterID, rule.Name, rule.TypeID, rule.Expression, strings.Join(rule.Channels, ","),
+		rule.Email, rule.CooldownSeconds, rule.Enabled, time.Now().Unix()).Scan(&ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return s.GetAlertRule(rule.CharacterID, ruleID)
+}
+
+// UpdateAlertRule replaces the definition of a rule, resets its trigger state and
+// bumps its revision so a running evaluation cannot store state of the old definition
+func (s *AlertStore) UpdateAlertRule(rule *models.AlertRule) (*models.AlertRule, error) {
+	result, err := s.db.Exec(`
+		UPDATE alertRules SET name = ?, typeID = ?, expression = ?, channels = ?, email = ?, cooldown = ?, enabled = ?,
+			triggered = 0, lastValue = 0, lastKey = '', lastCheckedAt = 0, revision = revision + 1
+		WHERE ruleID = ? AND characterID = ?
+	`, rule.Name, rule.TypeID, rule.Expression, strings.Join(rule.Channels, ","), rule.Email,
+		rule.CooldownSeconds, rule.Enabled, rule.RuleID, rule.CharacterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	if err := requireAffectedRule(result, rule.RuleID); err != nil {
+		return nil, err
+	}
+	return s.GetAlertRule(rule.CharacterID, rule.RuleID)
+}
+
+// DeleteAlertRule removes a rule of a character
+func (s *AlertStore) DeleteAlertRule(characterID int32, ruleID int64) error {
+	result, err := s.db.Exec(`DELETE FROM alertRules WHERE ruleID = ? AND characterID = ?`, ruleID, characterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	return requireAffectedRule(result, ruleID)
+}
+
+// GetAlertRule returns a rule of a character
+func (s *AlertStore) GetAlertRule(characterID int32, ruleID int64) (*models.AlertRule, error) {
+	rule, err := scanAlertRule(s.db.QueryRow(`SELECT `+alertRuleColumns+` FROM alertRules WHERE ruleID = ? AND characterID = ?`,
+		ruleID, characterID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ruleID %d", ErrAlertRuleNotFound, ruleID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ListAlertRules returns the rules of a character in creation order
+func (s *AlertStore) ListAlertRules(characterID int32) ([]models.AlertRule, error) {
+	return s.queryAlertRules(`SELECT `+alertRuleColumns+` FROM alertRules WHERE characterID = ? ORDER BY ruleID`, characterID)
+}
+
+// ListEnabledAlertRules returns the enabled rules of all characters
+func (s *AlertStore) ListEnabledAlertRules() ([]models.AlertRule, error) {
+	return s.queryAlertRules(`SELECT ` + alertRuleColumns + ` FROM alertRules WHERE enabled = 1 ORDER BY ruleID`)
+}
+
+// SaveAlertRuleStates stores the trigger state of evaluated rules. Rules deleted or
+// updated since they were listed are skipped, their state belongs to the old definition.
+func (s *AlertStore) SaveAlertRuleStates(rules []models.AlertRule) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		UPDATE alertRules SET triggered = ?, lastValue = ?, lastKey = ?, lastCheckedAt = ?, lastTriggeredAt = ?
+		WHERE ruleID = ? AND revision = ?
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare alert state update: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rule := range rules {
+		if _, err := stmt.Exec(rule.Triggered, rule.LastValue, rule.LastKey,
+			unixOrZero(rule.LastCheckedAt), unixOrZero(rule.LastTriggeredAt), rule.RuleID, rule.Revision); err != nil {
+			return fmt.Errorf("failed to save alert state: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit alert states: %w", err)
+	}
+	return nil
+}
+
+func (s *AlertStore) queryAlertRules(query string, args ...interface{}) ([]models.AlertRule, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+func scanAlertRule(row rowScanner) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	var channels string
+	var lastCheckedAt, lastTriggeredAt, createdAt int64
+	if err := row.Scan(&rule.RuleID, &rule.CharacterID, &rule.Name, &rule.TypeID, &rule.Expression, &channels,
+		&rule.Email, &rule.CooldownSeconds, &rule.Enabled, &rule.Triggered, &rule.LastValue, &rule.LastKey,
+		&lastCheckedAt, &lastTriggeredAt, &createdAt, &rule.Revision); err != nil {
+		return nil, err
+	}
+	rule.Channels = []string{}
+	if channels != "" {
+		rule.Channels = strings.Split(channels, ",")
+	}
+	rule.LastCheckedAt = timeOrZero(lastCheckedAt)
+	rule.LastTriggeredAt = timeOrZero(lastTriggeredAt)
+	rule.CreatedAt = time.Unix(createdAt, 0)
+	return &rule, nil
+}
+
+func requireAffectedRule(result sql.Result, ruleID int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected alert rules: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: ruleID %d", ErrAlertRuleNotFound, ruleID)
+	}
+	return nil
+}
+
+// unixOrZero stores unset times as 0
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func timeOrZero(unix int64) time.Time {
+	if unix == 0 {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
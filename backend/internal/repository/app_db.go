@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq" // Registers the "postgres" driver
+)
+
+// Dialect selects the SQL database behind the application database
+type Dialect string
+
+// Supported application database dialects
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// ErrUnknownDialect is returned for unsupported database drivers
+var ErrUnknownDialect = errors.New("unknown database driver")
+
+// ParseDialect resolves a configured driver name such as "sqlite" or "postgres"
+func ParseDialect(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	case "postgres", "postgresql", "pg":
+		return DialectPostgres, nil
+	}
+	return "", fmt.Errorf("%w %q, use sqlite or postgres", ErrUnknownDialect, name)
+}
+
+// driverName is the database/sql driver registered for the dialect
+func (d Dialect) driverName() string {
+	if d == DialectPostgres {
+		return "postgres"
+	}
+	return "sqlite3"
+}
+
+// Rebind rewrites the ? and ?N placeholders the stores are written with into
+// the $N placeholders of Postgres. Quoted text is left alone.
+func (d Dialect) Rebind(query string) string {
+	if d != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	next, quoted := 0, false
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'':
+			quoted = !quoted
+			b.WriteByte(ch)
+		case ch == '?' && !quoted:
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			b.WriteByte('$')
+			if j > i+1 {
+				b.WriteString(query[i+1 : j]) // Numbered placeholders keep their number
+				i = j - 1
+			} else {
+				next++
+				b.WriteString(strconv.Itoa(next))
+			}
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// convertArgs stores booleans as 0 and 1 in the integer flag columns of
+// Postgres, SQLite does the same on its own
+func (d Dialect) convertArgs(args []interface{}) []interface{} {
+	if d != DialectPostgres {
+		return args
+	}
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case bool:
+			converted[i] = boolInt(value)
+		case *bool:
+			if value == nil {
+				converted[i] = nil
+			} else {
+				converted[i] = boolInt(*value)
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
+
+func boolInt(value bool) int64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// AppDB is the writable application database holding user data such as SSO
+// tokens, watchlists and market archives. Exec, Query, QueryRow, Prepare and
+// Begin accept the SQLite flavored queries of the stores on every dialect.
+type AppDB struct {
+	*sql.DB
+	dialect Dialect
+}
+
+// AppTx is a transaction on the application database
+type AppTx struct {
+	*sql.Tx
+	dialect Dialect
+}
+
+// AppStmt is a prepared statement on the application database
+type AppStmt struct {
+	*sql.Stmt
+	dialect Dialect
+}
+
+// OpenAppDB opens the application database without migrating it. The source is
+// the file path for SQLite and the connection URL for Postgres.
+func OpenAppDB(dialect Dialect, source string) (*AppDB, error) {
+	var db *sql.DB
+	var err error
+	switch dialect {
+	case DialectSQLite:
+		db, err = openSQLiteAppDB(source)
+	case DialectPostgres:
+		db, err = openPostgresAppDB(source)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownDialect, dialect)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return &AppDB{DB: db, dialect: dialect}, nil
+}
+
+// OpenAppDatabase opens the SQLite application database at dbPath and applies
+// pending migrations. Unlike the SDE it is writable and created on first use.
+func OpenAppDatabase(dbPath string) (*AppDB, error) {
+	db, err := OpenAppDB(DialectSQLite, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func openSQLiteAppDB(dbPath string) (*sql.DB, error) {
+	if dir := filepath.Dir(dbPath); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dbPath+"?_busy_timeout=5000&_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows a single writer, one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// openPostgresAppDB connects through the lib/pq driver
+func openPostgresAppDB(url string) (*sql.DB, error) {
+	if url == "" {
+		return nil, errors.New("postgres connection URL is required")
+	}
+	db, err := sql.Open(DialectPostgres.driverName(), url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, nil
+}
+
+// Dialect returns the SQL dialect of the database
+func (db *AppDB) Dialect() Dialect {
+	return db.dialect
+}
+
+func (db *AppDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.dialect.Rebind(query), db.dialect.convertArgs(args)...)
+}
+
+func (db *AppDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.dialect.Rebind(query), db.dialect.convertArgs(args)...)
+}
+
+func (db *AppDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.dialect.Rebind(query), db.dialect.convertArgs(args)...)
+}
+
+func (db *AppDB) Prepare(query string) (*AppStmt, error) {
+	stmt, err := db.DB.Prepare(db.dialect.Rebind(query))
+	if err != nil {
+		return nil, err
+	}
+	return &AppStmt{Stmt: stmt, dialect: db.dialect}, nil
+}
+
+func (db *AppDB) Begin() (*AppTx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &AppTx{Tx: tx, dialect: db.dialect}, nil
+}
+
+func (tx *AppTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.dialect.Rebind(query), tx.dialect.convertArgs(args)...)
+}
+
+func (tx *AppTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.dialect.Rebind(query), tx.dialect.convertArgs(args)...)
+}
+
+func (tx *AppTx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(tx.dialect.Rebind(query), tx.dialect.convertArgs(args)...)
+}
+
+func (tx *AppTx) Prepare(query string) (*AppStmt, error) {
+	stmt, err := tx.Tx.Prepare(tx.dialect.Rebind(query))
+	if err != nil {
+		return nil, err
+	}
+	return &AppStmt{Stmt: stmt, dialect: tx.dialect}, nil
+}
+
+func (s *AppStmt) Exec(args ...interface{}) (sql.Result, error) {
+	return s.Stmt.Exec(s.dialect.convertArgs(args)...)
+}
+
+func (s *AppStmt) Query(args ...interface{}) (*sql.Rows, error) {
+	return s.Stmt.Query(s.dialect.convertArgs(args)...)
+}
+
+func (s *AppStmt) QueryRow(args ...interface{}) *sql.Row {
+	return s.Stmt.QueryRow(s.dialect.convertArgs(args)...)
+}
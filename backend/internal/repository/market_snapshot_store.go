@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// MarketSnapshotStore archives aggregated market snapshots and optionally the
+// full order books behind them in the application database. Raw samples are
+// stored with resolution 0 and downsampled into fixed buckets as they age.
+type MarketSnapshotStore struct {
+	db *AppDB
+}
+
+// NewMarketSnapshotStore fails unless the snapshot tables were migrated
+func NewMarketSnapshotStore(db *AppDB) (*MarketSnapshotStore, error) {
+	if err := db.requireSchema(schemaVersionMarketSnapshots); err != nil {
+		return nil, err
+	}
+	return &MarketSnapshotStore{db: db}, nil
+}
+
+// SaveSnapshots stores raw snapshots, replacing samples taken in the same second
+func (s *MarketSnapshotStore) SaveSnapshots(snapshots []models.MarketSnapshot) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save snapshots: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, snapshot := range snapshots {
+		_, err := tx.Exec(`
+			INSERT INTO marketSnapshots (regionID, typeID, resolution, timestamp, samples,
+				buyMax, sellMin, buyVolume, sellVolume, buyOrders, sellOrders)
+			VALUES (?, ?, 0, ?, 1, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (regionID, typeID, resolution, timestamp) DO UPDATE SET
+				samples = 1, buyMax = excluded.buyMax, sellMin = excluded.sellMin,
+				buyVolume = excluded.buyVolume, sellVolume = excluded.sellVolume,
+				buyOrders = excluded.buyOrders, sellOrders = excluded.sellOrders
+		`, snapshot.RegionID, snapshot.TypeID, snapshot.Timestamp.Unix(), snapshot.BuyMax, snapshot.SellMin,
+			snapshot.BuyVolume, snapshot.SellVolume, snapshot.BuyOrders, snapshot.SellOrders)
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot of type %d: %w", snapshot.TypeID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save snapshots: %w", err)
+	}
+	return nil
+}
+
+// SaveOrderBook stores the complete order book of a type as seen at one point in time
+func (s *MarketSnapshotStore) SaveOrderBook(regionID, typeID int32, timestamp time.Time, orders []models.MarketOrder) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save order book: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, order := range orders {
+		_, err := tx.Exec(`
+			INSERT INTO marketOrderBooks (regionID, typeID, timestamp, orderID, locationID, systemID, price,
+				volumeTotal, volumeRemain, minVolume, isBuyOrder, duration, issued, orderRange)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (regionID, typeID, timestamp, orderID) DO UPDATE SET
+				locationID = excluded.locationID, systemID = excluded.systemID, price = excluded.price,
+				volumeTotal = excluded.volumeTotal, volumeRemain = excluded.volumeRemain, minVolume = excluded.minVolume,
+				isBuyOrder = excluded.isBuyOrder, duration = excluded.duration, issued = excluded.issued,
+				orderRange = excluded.orderRange
+		`, regionID, typeID, timestamp.Unix(), order.OrderID, order.LocationID, order.SystemID, order.Price,
+			order.VolumeTotal, order.VolumeRemain, order.MinVolume, order.IsBuyOrder, order.Duration, order.Issued.Unix(), order.Range)
+		if err != nil {
+			return fmt.Errorf("failed to save order %d: %w", order.OrderID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save order book: %w", err)
+	}
+	return nil
+}
+
+// ListSnapshots returns the raw and downsampled snapshots of a type taken
+// within [from, to), oldest first
+func (s *MarketSnapshotStore) ListSnapshots(regionID, typeID int32, from, to time.Time) ([]models.MarketSnapshot, error) {
+	rows, err := s.db.Query(`
+		SELECT resolution, timestamp, samples, buyMax, sellMin, buyVolume, sellVolume, buyOrders, sellOrders
+		FROM marketSnapshots
+		WHERE regionID = ? AND typeID = ? AND timestamp >= ? AND timestamp < ?
+		ORDER BY timestamp, resolution
+	`, regionID, typeID, from.Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []models.MarketSnapshot{}
+	for rows.Next() {
+		snapshot := models.MarketSnapshot{RegionID: regionID, TypeID: typeID}
+		var timestamp int64
+		if err := rows.Scan(&snapshot.Resolution, &timestamp, &snapshot.Samples, &snapshot.BuyMax, &snapshot.SellMin,
+			&snapshot.BuyVolume, &snapshot.SellVolume, &snapshot.BuyOrders, &snapshot.SellOrders); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+		snapshot.Timestamp = time.Unix(timestamp, 0).UTC()
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+// DownsampleSnapshots merges the raw samples taken before the cutoff into buckets
+// of the given length in seconds and deletes them. Sides without orders do not
+// lower the average price. Returns the number of merged raw samples.
+func (s *MarketSnapshotStore) DownsampleSnapshots(resolution int, before time.Time) (int64, error) {
+	if resolution <= 0 {
+		return 0, fmt.Errorf("invalid snapshot resolution: %d", resolution)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to downsample snapshots: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Buckets already holding samples are merged weighted by their sample count
+	_, err = tx.Exec(`
+		INSERT INTO marketSnapshots AS bucket (regionID, typeID, resolution, timestamp, samples,
+			buyMax, sellMin, buyVolume, sellVolume, buyOrders, sellOrders)
+		SELECT regionID, typeID, CAST(?1 AS BIGINT), (timestamp / ?1) * ?1, COUNT(*),
+			COALESCE(AVG(NULLIF(buyMax, 0)), 0), COALESCE(AVG(NULLIF(sellMin, 0)), 0),
+			CAST(AVG(buyVolume) AS BIGINT), CAST(AVG(sellVolume) AS BIGINT),
+			CAST(AVG(buyOrders) AS BIGINT), CAST(AVG(sellOrders) AS BIGINT)
+		FROM marketSnapshots
+		WHERE resolution = 0 AND timestamp < ?2
+		GROUP BY regionID, typeID, timestamp / ?1
+		ON CONFLICT (regionID, typeID, resolution, timestamp) DO UPDATE SET
+			buyMax = CASE
+				WHEN bucket.buyMax = 0 THEN excluded.buyMax
+				WHEN excluded.buyMax = 0 THEN bucket.buyMax
+				ELSE (bucket.buyMax * bucket.samples + excluded.buyMax * excluded.samples) / (bucket.samples + excluded.samples) END,
+			sellMin = CASE
+				WHEN bucket.sellMin = 0 THEN excluded.sellMin
+				WHEN excluded.sellMin = 0 THEN bucket.sellMin
+				ELSE (bucket.sellMin * bucket.samples + excluded.sellMin * excluded.samples) / (bucket.samples + excluded.samples) END,
+			buyVolume = (bucket.buyVolume * bucket.samples + excluded.buyVolume * excluded.samples) / (bucket.samples + excluded.samples),
+			sellVolume = (bucket.sellVolume * bucket.samples + excluded.sellVolume * excluded.samples) / (bucket.samples + excluded.samples),
+			buyOrders = (bucket.buyOrders * bucket.samples + excluded.buyOrders * excluded.samples) / (bucket.samples + excluded.samples),
+			sellOrders = (bucket.sellOrders * bucket.samples + excluded.sellOrders * excluded.samples) / (bucket.samples + excluded.samples),
+			samples = bucket.samples + excluded.samples
+	`, resolution, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to downsample snapshots: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM marketSnapshots WHERE resolution = 0 AND timestamp < ?`, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete downsampled snapshots: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to downsample snapshots: %w", err)
+	}
+	merged, _ := result.RowsAffected()
+	return merged, nil
+}
+
+// DeleteSnapshotsBefore drops the snapshots of one resolution taken before the cutoff
+func (s *MarketSnapshotStore) DeleteSnapshotsBefore(resolution int, before time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM marketSnapshots WHERE resolution = ? AND timestamp < ?`, resolution, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete snapshots: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
+}
+
+// DeleteOrderBooksBefore drops the order books taken before the cutoff
+func (s *MarketSnapshotStore) DeleteOrderBooksBefore(before time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM marketOrderBooks WHERE timestamp < ?`, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete order books: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
+}
+
+// PreviousOrderBook returns the latest archived order book of a type taken before
+// the given time. The timestamp is zero if no earlier order book exists.
+func (s *MarketSnapshotStore) PreviousOrderBook(regionID, typeID int32, before time.Time) (time.Time, []models.MarketOrder, error) {
+	var timestamp sql.NullInt64
+	err := s.db.QueryRow(`
+		SELECT MAX(timestamp) FROM marketOrderBooks WHERE regionID = ? AND typeID = ? AND timestamp < ?
+	`, regionID, typeID, before.Unix()).Scan(&timestamp)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to find previous order book: %w", err)
+	}
+	if !timestamp.Valid {
+		return time.Time{}, nil, nil
+	}
+
+	rows, err := s.db.Query(`
+		SELECT orderID, locationID, systemID, price, volumeTotal, volumeRemain, minVolume, isBuyOrder, duration, issued, orderRange
+		FROM marketOrderBooks WHERE regionID = ? AND typeID = ? AND timestamp = ?
+		ORDER BY orderID
+	`, regionID, typeID, timestamp.Int64)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to load order book: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []models.MarketOrder{}
+	for rows.Next() {
+		order := models.MarketOrder{TypeID: typeID}
+		var issued int64
+		if err := rows.Scan(&order.OrderID, &order.LocationID, &order.SystemID, &order.Price, &order.VolumeTotal, &order.VolumeRemain,
+			&order.MinVolume, &order.IsBuyOrder, &order.Duration, &issued, &order.Range); err != nil {
+			return time.Time{}, nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		order.Issued = time.Unix(issued, 0).UTC()
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to load order book: %w", err)
+	}
+	return time.Unix(timestamp.Int64, 0).UTC(), orders, nil
+}
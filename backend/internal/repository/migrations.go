@@ -0,0 +1,435 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+var (
+	// ErrSchemaOutdated is returned when migrations a store depends on were not applied yet
+	ErrSchemaOutdated = errors.New("application database schema is outdated")
+	// ErrSchemaTooNew is returned for databases migrated by a newer build
+	ErrSchemaTooNew = errors.New("application database schema is newer than this build")
+)
+
+// Migration is one forward-only step of the application database schema.
+// Applied migrations must never be edited, schema changes get a new version.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string // Statements in SQLite syntax separated by semicolons
+}
+
+// appMigrations lists all migrations in version order. The first versions use
+// IF NOT EXISTS so databases created before versioning are adopted as they are.
+// The SQL is written out here rather than shared with the stores, so editing a
+// store cannot change a migration that already ran.
+var appMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "character tokens",
+		SQL: `
+		CREATE TABLE IF NOT EXISTS characterTokens (
+			characterID INTEGER PRIMARY KEY,
+			characterName TEXT NOT NULL DEFAULT '',
+			accessToken BLOB NOT NULL,
+			refreshToken BLOB NOT NULL,
+			tokenType TEXT NOT NULL DEFAULT '',
+			expiresAt INTEGER NOT NULL,
+			scopes TEXT NOT NULL DEFAULT '',
+			revoked INTEGER NOT NULL DEFAULT 0,
+			updatedAt INTEGER NOT NULL
+		)
+		`,
+	},
+	{
+		Version: 2,
+		Name:    "accounts",
+		SQL: `
+		CREATE TABLE IF NOT EXISTS accounts (
+			accountID INTEGER PRIMARY KEY AUTOINCREMENT,
+			activeCharacterID INTEGER NOT NULL,
+			createdAt INTEGER NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS accountCharacters (
+			characterID INTEGER PRIMARY KEY,
+			accountID INTEGER NOT NULL REFERENCES accounts(accountID) ON DELETE CASCADE,
+			characterName TEXT NOT NULL DEFAULT '',
+			linkedAt INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_accountCharacters_accountID ON accountCharacters (accountID);
+		`,
+	},
+	{
+		Version: 3,
+		Name:    "wallet journal and transactions",
+		SQL: `
+		CREATE TABLE IF NOT EXISTS walletJournal (
+			journalID INTEGER PRIMARY KEY,
+			characterID INTEGER NOT NULL,
+			date INTEGER NOT NULL,
+			refType TEXT NOT NULL,
+			amount REAL NOT NULL DEFAULT 0,
+			balance REAL NOT NULL DEFAULT 0,
+			tax REAL NOT NULL DEFAULT 0,
+			contextID INTEGER NOT NULL DEFAULT 0,
+			contextIDType TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			firstPartyID INTEGER NOT NULL DEFAULT 0,
+			secondPartyID INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_walletJournal_characterID ON walletJournal (characterID, date);
+		CREATE TABLE IF NOT EXISTS walletTransactions (
+			transactionID INTEGER PRIMARY KEY,
+			characterID INTEGER NOT NULL,
+			date INTEGER NOT NULL,
+			typeID INTEGER NOT NULL,
+			quantity INTEGER NOT NULL,
+			unitPrice REAL NOT NULL,
+			locationID INTEGER NOT NULL,
+			clientID INTEGER NOT NULL DEFAULT 0,
+			isBuy INTEGER NOT NULL,
+			isPersonal INTEGER NOT NULL DEFAULT 1,
+			journalRefID INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_walletTransactions_characterID ON walletTransactions (characterID, date);
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "market snapshots and order books",
+		SQL: `
+		CREATE TABLE IF NOT EXISTS marketSnapshots (
+			regionID INTEGER NOT NULL,
+			typeID INTEGER NOT NULL,
+			resolution INTEGER NOT NULL,
+			timestamp INTEGER NOT NULL,
+			samples INTEGER NOT NULL DEFAULT 1,
+			buyMax REAL NOT NULL DEFAULT 0,
+			sellMin REAL NOT NULL DEFAULT 0,
+			buyVolume INTEGER NOT NULL DEFAULT 0,
+			sellVolume INTEGER NOT NULL DEFAULT 0,
+			buyOrders INTEGER NOT NULL DEFAULT 0,
+			sellOrders INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (regionID, typeID, resolution, timestamp)
+		);
+		CREATE INDEX IF NOT EXISTS idx_marketSnapshots_timestamp ON marketSnapshots (resolution, timestamp);
+		CREATE TABLE IF NOT EXISTS marketOrderBooks (
+			regionID INTEGER NOT NULL,
+			typeID INTEGER NOT NULL,
+			timestamp INTEGER NOT NULL,
+			orderID INTEGER NOT NULL,
+			locationID INTEGER NOT NULL,
+			systemID INTEGER NOT NULL DEFAULT 0,
+			price REAL NOT NULL,
+			volumeTotal INTEGER NOT NULL,
+			volumeRemain INTEGER NOT NULL,
+			minVolume INTEGER NOT NULL DEFAULT 1,
+			isBuyOrder INTEGER NOT NULL,
+			duration INTEGER NOT NULL DEFAULT 0,
+			issued INTEGER NOT NULL,
+			orderRange TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (regionID, typeID, timestamp, orderID)
+		);
+		CREATE INDEX IF NOT EXISTS idx_marketOrderBooks_timestamp ON marketOrderBooks (timestamp);
+		`,
+	},
+	{
+		Version: 5,
+		Name:    "market order events",
+		SQL: `
+		CREATE TABLE IF NOT EXISTS marketOrderEvents (
+			regionID INTEGER NOT NULL,
+			typeID INTEGER NOT NULL,
+			orderID INTEGER NOT NULL,
+			timestamp INTEGER NOT NULL,
+			eventType TEXT NOT NULL,
+			isBuyOrder INTEGER NOT NULL,
+			locationID INTEGER NOT NULL,
+			price REAL NOT NULL,
+			previousPrice REAL NOT NULL DEFAULT 0,
+			volume INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (regionID, typeID, orderID, timestamp, eventType)
+		);
+		CREATE INDEX IF NOT EXISTS idx_marketOrderEvents_type ON marketOrderEvents (regionID, typeID, timestamp);
+		CREATE INDEX IF NOT EXISTS idx_marketOrderEvents_timestamp ON marketOrderEvents (timestamp);
+		`,
+	},
+	{
+		Version: 6,
+		Name:    "alert rules",
+		SQL: `
+		CREATE TABLE IF NOT EXISTS alertRules (
+			ruleID INTEGER PRIMARY KEY AUTOINCREMENT,
+			characterID INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			typeID INTEGER NOT NULL DEFAULT 0,
+			expression TEXT NOT NULL,
+			channels TEXT NOT NULL DEFAULT '',
+			email TEXT NOT NULL DEFAULT '',
+			cooldown INTEGER NOT NULL DEFAULT 0,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			triggered INTEGER NOT NULL DEFAULT 0,
+			lastValue REAL NOT NULL DEFAULT 0,
+			lastKey TEXT NOT NULL DEFAULT '',
+			lastCheckedAt INTEGER NOT NULL DEFAULT 0,
+			lastTriggeredAt INTEGER NOT NULL DEFAULT 0,
+			createdAt INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_alertRules_characterID ON alertRules (characterID);
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "watchlists and saved searches",
+		SQL: `
+		CREATE TABLE IF NOT EXISTS watchlists (
+			watchlistID INTEGER PRIMARY KEY AUTOINCREMENT,
+			characterID INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			notes TEXT NOT NULL DEFAULT '',
+			createdAt INTEGER NOT NULL,
+			updatedAt INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_watchlists_characterID ON watchlists (characterID);
+		CREATE TABLE IF NOT EXISTS watchlistItems (
+			watchlistID INTEGER NOT NULL REFERENCES watchlists(watchlistID) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			typeID INTEGER NOT NULL,
+			regionID INTEGER NOT NULL,
+			stationID INTEGER NOT NULL DEFAULT 0,
+			note TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (watchlistID, position)
+		);
+		CREATE TABLE IF NOT EXISTS savedSearches (
+			searchID INTEGER PRIMARY KEY AUTOINCREMENT,
+			characterID INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			query TEXT NOT NULL,
+			categoryID INTEGER NOT NULL DEFAULT 0,
+			marketGroupID INTEGER NOT NULL DEFAULT 0,
+			published INTEGER,
+			marketable INTEGER,
+			createdAt INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_savedSearches_characterID ON savedSearches (characterID);
+		`,
+	},
+	{
+		Version: 8,
+		Name:    "alert rule revisions",
+		SQL: `
+		ALTER TABLE alertRules ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
+		`,
+	},
+}
+
+// Schema versions the stores depend on
+const (
+	schemaVersionTokens = iota + 1
+	schemaVersionAccounts
+	schemaVersionWallet
+	schemaVersionMarketSnapshots
+	schemaVersionOrderEvents
+	schemaVersionAlerts
+	schemaVersionWatchlists
+	schemaVersionAlertRevisions
+)
+
+const schemaMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schemaMigrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		appliedAt INTEGER NOT NULL
+	)
+`
+
+// migrationLockID serializes migrations of several instances sharing a Postgres database
+const migrationLockID = 7366206573
+
+// Migrations returns all migrations known to this build
+func Migrations() []Migration {
+	return append([]Migration(nil), appMigrations...)
+}
+
+// LatestSchemaVersion is the schema version after all migrations of this build ran
+func LatestSchemaVersion() int {
+	return appMigrations[len(appMigrations)-1].Version
+}
+
+// Migrate applies all pending migrations, each in its own transaction, and
+// returns the applied ones
+func (db *AppDB) Migrate() ([]Migration, error) {
+	if _, err := db.Exec(db.dialect.DDL(schemaMigrationsTable)); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if current > LatestSchemaVersion() {
+		return nil, fmt.Errorf("%w: database is at version %d, this build knows %d", ErrSchemaTooNew, current, LatestSchemaVersion())
+	}
+
+	var applied []Migration
+	for _, migration := range appMigrations {
+		if migration.Version <= current {
+			continue
+		}
+		ran, err := db.applyMigration(migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+// applyMigration runs one migration unless another instance applied it meanwhile
+func (db *AppDB) applyMigration(migration Migration) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if db.dialect == DialectPostgres {
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID); err != nil {
+			return false, err
+		}
+	}
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM schemaMigrations WHERE version = ?", migration.Version).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists > 0 {
+		return false, nil
+	}
+
+	for _, statement := range splitStatements(db.dialect.DDL(migration.SQL)) {
+		if _, err := tx.Exec(statement); err != nil {
+			return false, err
+		}
+	}
+	if _, err := tx.Exec("INSERT INTO schemaMigrations (version, name, appliedAt) VALUES (?, ?, ?)",
+		migration.Version, migration.Name, time.Now().Unix()); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// SchemaVersion returns the highest applied migration, 0 for new databases.
+// It only reads, so checking the schema of an unmigrated database leaves it untouched.
+func (db *AppDB) SchemaVersion() (int, error) {
+	exists, err := db.hasMigrationsTable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up migrations table: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schemaMigrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// hasMigrationsTable checks the catalog for the schemaMigrations table. Postgres
+// folds the unquoted table name to lower case.
+func (db *AppDB) hasMigrationsTable() (bool, error) {
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schemaMigrations'`
+	if db.dialect == DialectPostgres {
+		query = `SELECT COUNT(*) FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_name = 'schemamigrations'`
+	}
+	var count int
+	if err := db.QueryRow(query).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SchemaStatus reports the schema version for health checks
+func (db *AppDB) SchemaStatus() models.AppSchemaVersion {
+	status := models.AppSchemaVersion{Driver: string(db.dialect), LatestVersion: LatestSchemaVersion()}
+	exists, err := db.hasMigrationsTable()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if !exists {
+		return status // Never migrated
+	}
+
+	var appliedAt int64
+	err = db.QueryRow(`
+		SELECT version, appliedAt FROM schemaMigrations
+		ORDER BY version DESC LIMIT 1
+	`).Scan(&status.Version, &appliedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		status.Error = err.Error()
+		return status
+	}
+	status.MigratedAt = timeOrZero(appliedAt)
+	status.UpToDate = status.Version == status.LatestVersion
+	return status
+}
+
+// CheckSchema fails unless all migrations of this build were applied
+func (db *AppDB) CheckSchema() error {
+	return db.requireSchema(LatestSchemaVersion())
+}
+
+// requireSchema fails if the database was not migrated to at least version
+func (db *AppDB) requireSchema(version int) error {
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current < version {
+		return fmt.Errorf("%w: version %d is required, database is at %d", ErrSchemaOutdated, version, current)
+	}
+	return nil
+}
+
+var (
+	ddlAutoIncrement = regexp.MustCompile(`(?i)\bINTEGER PRIMARY KEY AUTOINCREMENT\b`)
+	ddlInteger       = regexp.MustCompile(`(?i)\bINTEGER\b`)
+	ddlReal          = regexp.MustCompile(`(?i)\bREAL\b`)
+	ddlBlob          = regexp.MustCompile(`(?i)\bBLOB\b`)
+)
+
+// DDL translates the SQLite column types of migrations to the dialect. SQLite
+// integers hold 64 bits, so Postgres gets BIGINT throughout.
+func (d Dialect) DDL(statements string) string {
+	if d != DialectPostgres {
+		return statements
+	}
+	statements = ddlAutoIncrement.ReplaceAllString(statements, "BIGSERIAL PRIMARY KEY")
+	statements = ddlInteger.ReplaceAllString(statements, "BIGINT")
+	statements = ddlReal.ReplaceAllString(statements, "DOUBLE PRECISION")
+	return ddlBlob.ReplaceAllString(statements, "BYTEA")
+}
+
+// splitStatements splits a migration into single statements since not every
+// driver executes several at once
+func splitStatements(statements string) []string {
+	var result []string
+	for _, statement := range strings.Split(statements, ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			result = append(result, statement)
+		}
+	}
+	return result
+}
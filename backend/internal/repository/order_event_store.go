@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// OrderEventStore keeps the order events inferred from archived order books.
+// Events outlive the order books they were derived from.
+type OrderEventStore struct {
+	db *AppDB
+}
+
+// NewOrderEventStore fails unless the order event table was migrated
+func NewOrderEventStore(db *AppDB) (*OrderEventStore, error) {
+	if err := db.requireSchema(schemaVersionOrderEvents); err != nil {
+		return nil, err
+	}
+	return &OrderEventStore{db: db}, nil
+}
+
+// SaveOrderEvents stores events, ignoring events already recorded for the same snapshot
+func (s *OrderEventStore) SaveOrderEvents(events []models.OrderEvent) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save order events: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, event := range events {
+		_, err := tx.Exec(`
+			INSERT INTO marketOrderEvents (regionID, typeID, orderID, timestamp, eventType, isBuyOrder,
+				locationID, price, previousPrice, volume)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`, event.RegionID, event.TypeID, event.OrderID, event.Timestamp.Unix(), event.Type, event.IsBuyOrder,
+			event.LocationID, event.Price, event.PreviousPrice, event.Volume)
+		if err != nil {
+			return fmt.Errorf("failed to save order event of order %d: %w", event.OrderID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save order events: %w", err)
+	}
+	return nil
+}
+
+// ListOrderEvents returns the events of a type behind the cursor and up to `until`,
+// oldest first. A location ID of 0 returns the events of the whole region and a
+// limit of 0 returns all events.
+func (s *OrderEventStore) ListOrderEvents(regionID, typeID int32, locationID int64, after models.OrderEventCursor, until time.Time, limit int) ([]models.OrderEvent, error) {
+	query := `
+		SELECT orderID, timestamp, eventType, isBuyOrder, locationID, price, previousPrice, volume
+		FROM marketOrderEvents
+		WHERE regionID = ? AND typeID = ? AND timestamp <= ? AND (CAST(? AS BIGINT) = 0 OR locationID = ?)`
+	args := []interface{}{regionID, typeID, until.Unix(), locationID, locationID}
+	if after.OrderID == 0 {
+		query += ` AND timestamp > ?`
+		args = append(args, after.Timestamp.Unix())
+	} else {
+		// Events of the cursor's second that sort behind it are still unseen
+		query += ` AND (timestamp > ? OR (timestamp = ? AND (orderID > ? OR (orderID = ? AND eventType > ?))))`
+		args = append(args, after.Timestamp.Unix(), after.Timestamp.Unix(), after.OrderID, after.OrderID, after.EventType)
+	}
+	query += ` ORDER BY timestamp, orderID, eventType`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.OrderEvent{}
+	for rows.Next() {
+		event := models.OrderEvent{RegionID: regionID, TypeID: typeID}
+		var timestamp int64
+		if err := rows.Scan(&event.OrderID, &timestamp, &event.Type, &event.IsBuyOrder, &event.LocationID,
+			&event.Price, &event.PreviousPrice, &event.Volume); err != nil {
+			return nil, fmt.Errorf("failed to scan order event: %w", err)
+		}
+		event.Timestamp = time.Unix(timestamp, 0).UTC()
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// DeleteOrderEventsBefore drops the events seen before the cutoff
+func (s *OrderEventStore) DeleteOrderEventsBefore(before time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM marketOrderEvents WHERE timestamp < ?`, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete order events: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
+}
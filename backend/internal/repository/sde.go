@@ -2,21 +2,47 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"sync"
+
+	"eve-profit2/internal/models"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// ErrItemNotFound is returned when a type does not exist in the SDE
+var ErrItemNotFound = errors.New("item not found")
+
 // SDEItem represents an item from SDE
 type SDEItem struct {
-	TypeID      int32   `json:"typeId"`
-	TypeName    string  `json:"typeName"`
-	GroupID     int32   `json:"groupId"`
-	Volume      float64 `json:"volume"`
-	MarketGroup int32   `json:"marketGroupID,omitempty"`
-	Published   bool    `json:"published"`
+	TypeID       int32   `json:"typeId"`
+	TypeName     string  `json:"typeName"`
+	GroupID      int32   `json:"groupId"`
+	GroupName    string  `json:"groupName"`
+	CategoryID   int32   `json:"categoryId"`
+	CategoryName string  `json:"categoryName"`
+	Volume       float64 `json:"volume"`
+	Mass         float64 `json:"mass"`
+	Description  string  `json:"description,omitempty"`
+	MarketGroup  int32   `json:"marketGroupID,omitempty"`
+	Published    bool    `json:"published"`
 }
 
+// sdeItemColumns selects an invTypes row joined with its group and category
+const sdeItemColumns = `
+		t.typeID, t.typeName, t.groupID, COALESCE(g.groupName, ''), COALESCE(g.categoryID, 0),
+		COALESCE(c.categoryName, ''), COALESCE(t.volume, 0), COALESCE(t.mass, 0), COALESCE(t.description, ''),
+		t.marketGroupID, t.published
+	`
+
+// sdeItemJoins joins invTypes (alias t) with invGroups and invCategories
+const sdeItemJoins = `
+		FROM invTypes t
+		LEFT JOIN invGroups g ON g.groupID = t.groupID
+		LEFT JOIN invCategories c ON c.categoryID = g.categoryID
+	`
+
 // SDEStation represents a station from SDE
 type SDEStation struct {
 	StationID   int64  `json:"stationId"`
@@ -32,12 +58,36 @@ type SDERegion struct {
 	RegionName string `json:"regionName"`
 }
 
-// SDERepository handles SDE SQLite database operations
+// SDERepository handles SDE SQLite database operations.
+// The underlying database can be swapped at runtime, see Reload.
 type SDERepository struct {
-	db *sql.DB
+	path string
+
+	mu        sync.RWMutex
+	current   *sdeHandle
+	pending   *sdeFileStamp // Changed file waiting to settle before it is loaded
+	listeners []func(models.SDEVersion)
+}
+
+// sdeHandle is one opened SDE database. It counts running queries so it can be drained.
+type sdeHandle struct {
+	db       *sql.DB
+	version  models.SDEVersion
+	stamp    sdeFileStamp
+	inFlight sync.WaitGroup
 }
 
 func NewSDERepository(dbPath string) (*SDERepository, error) {
+	handle, err := openSDEDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SDERepository{path: dbPath, current: handle}, nil
+}
+
+// openSDEDatabase opens, pings and validates an SDE database
+func openSDEDatabase(dbPath string) (*sdeHandle, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -45,51 +95,138 @@ func NewSDERepository(dbPath string) (*SDERepository, error) {
 
 	// Test connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &SDERepository{db: db}, nil
+	if err := validateSDESchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	version, stamp, err := readSDEVersion(db, dbPath)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &sdeHandle{db: db, version: version, stamp: stamp}, nil
+}
+
+// acquire returns the active database and registers a running query.
+// The release function must be called once the query and its rows are done.
+func (r *SDERepository) acquire() (*sql.DB, func()) {
+	r.mu.RLock()
+	handle := r.current
+	handle.inFlight.Add(1)
+	r.mu.RUnlock()
+
+	return handle.db, handle.inFlight.Done
 }
 
 func (r *SDERepository) Close() error {
-	if r.db != nil {
-		return r.db.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current != nil && r.current.db != nil {
+		return r.current.db.Close()
 	}
 	return nil
 }
 
 func (r *SDERepository) Ping() error {
-	if r.db == nil {
+	db, release := r.acquire()
+	defer release()
+
+	if db == nil {
 		return fmt.Errorf("database connection is nil")
 	}
-	return r.db.Ping()
+	return db.Ping()
 }
 
 // GetItemByID retrieves a single item by TypeID
 func (r *SDERepository) GetItemByID(typeID int32) (*SDEItem, error) {
-	query := `
-		SELECT typeID, typeName, groupID, volume, marketGroupID, published
-		FROM invTypes 
-		WHERE typeID = ?
+	db, release := r.acquire()
+	defer release()
+
+	query := `SELECT ` + sdeItemColumns + sdeItemJoins + `
+		WHERE t.typeID = ?
+	`
+
+	item, err := scanSDEItem(db.QueryRow(query, typeID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: typeID %d", ErrItemNotFound, typeID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	return item, nil
+}
+
+// SearchItems searches for items by name
+func (r *SDERepository) SearchItems(searchTerm string, limit int) ([]*SDEItem, error) {
+	db, release := r.acquire()
+	defer release()
+
+	query := `SELECT ` + sdeItemColumns + sdeItemJoins + `
+		WHERE t.typeName LIKE ? AND t.published = 1
+		ORDER BY t.typeName
+		LIMIT ?
 	`
 
+	rows, err := db.Query(query, "%"+searchTerm+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search items: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSDEItems(rows)
+}
+
+// GetAllItems retrieves every type including unpublished ones, e.g. to build a search index
+func (r *SDERepository) GetAllItems() ([]*SDEItem, error) {
+	db, release := r.acquire()
+	defer release()
+
+	query := `SELECT ` + sdeItemColumns + sdeItemJoins + `
+		ORDER BY t.typeID
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSDEItems(rows)
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSDEItem scans a row selected with sdeItemColumns
+func scanSDEItem(row rowScanner) (*SDEItem, error) {
 	var item SDEItem
 	var marketGroup sql.NullInt32
 
-	err := r.db.QueryRow(query, typeID).Scan(
+	err := row.Scan(
 		&item.TypeID,
 		&item.TypeName,
 		&item.GroupID,
+		&item.GroupName,
+		&item.CategoryID,
+		&item.CategoryName,
 		&item.Volume,
+		&item.Mass,
+		&item.Description,
 		&marketGroup,
 		&item.Published,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("item not found: typeID %d", typeID)
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get item: %w", err)
+		return nil, err
 	}
 
 	if marketGroup.Valid {
@@ -99,58 +236,32 @@ func (r *SDERepository) GetItemByID(typeID int32) (*SDEItem, error) {
 	return &item, nil
 }
 
-// SearchItems searches for items by name
-func (r *SDERepository) SearchItems(searchTerm string, limit int) ([]*SDEItem, error) {
-	query := `
-		SELECT typeID, typeName, groupID, volume, marketGroupID, published
-		FROM invTypes 
-		WHERE typeName LIKE ? AND published = 1
-		ORDER BY typeName
-		LIMIT ?
-	`
-
-	rows, err := r.db.Query(query, "%"+searchTerm+"%", limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search items: %w", err)
-	}
-	defer rows.Close()
-
+// scanSDEItems scans all rows selected with sdeItemColumns
+func scanSDEItems(rows *sql.Rows) ([]*SDEItem, error) {
 	var items []*SDEItem
 	for rows.Next() {
-		var item SDEItem
-		var marketGroup sql.NullInt32
-
-		err := rows.Scan(
-			&item.TypeID,
-			&item.TypeName,
-			&item.GroupID,
-			&item.Volume,
-			&marketGroup,
-			&item.Published,
-		)
+		item, err := scanSDEItem(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan item: %w", err)
 		}
-
-		if marketGroup.Valid {
-			item.MarketGroup = marketGroup.Int32
-		}
-
-		items = append(items, &item)
+		items = append(items, item)
 	}
 
-	return items, nil
+	return items, rows.Err()
 }
 
 // GetStationsBySystem retrieves all stations in a system
 func (r *SDERepository) GetStationsBySystem(systemID int32) ([]*SDEStation, error) {
+	db, release := r.acquire()
+	defer release()
+
 	query := `
 		SELECT stationID, stationName, solarSystemID, regionID, stationTypeID
 		FROM staStations 
 		WHERE solarSystemID = ?
 	`
 
-	rows, err := r.db.Query(query, systemID)
+	rows, err := db.Query(query, systemID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stations: %w", err)
 	}
@@ -179,6 +290,9 @@ func (r *SDERepository) GetStationsBySystem(systemID int32) ([]*SDEStation, erro
 
 // GetRegions retrieves all regions with limit
 func (r *SDERepository) GetRegions(limit int) ([]*SDERegion, error) {
+	db, release := r.acquire()
+	defer release()
+
 	query := `
 		SELECT regionID, regionName
 		FROM mapRegions
@@ -186,7 +300,7 @@ func (r *SDERepository) GetRegions(limit int) ([]*SDERegion, error) {
 		LIMIT ?
 	`
 
-	rows, err := r.db.Query(query, limit)
+	rows, err := db.Query(query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get regions: %w", err)
 	}
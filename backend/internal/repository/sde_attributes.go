@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Well-known dogma attribute IDs
+const (
+	AttributeMass      int32 = 4
+	AttributeTechLevel int32 = 422
+	AttributeMetaLevel int32 = 633
+)
+
+// SDEAttribute is a dogma attribute value of a type joined with its definition and unit
+type SDEAttribute struct {
+	TypeID        int32   `json:"typeId"`
+	AttributeID   int32   `json:"attributeId"`
+	AttributeName string  `json:"attributeName"`
+	DisplayName   string  `json:"displayName"`
+	UnitID        int32   `json:"unitId,omitempty"`
+	UnitName      string  `json:"unitName,omitempty"`
+	Value         float64 `json:"value"`
+	HighIsGood    bool    `json:"highIsGood"`
+	Published     bool    `json:"published"`
+}
+
+// GetTypeAttributes retrieves all dogma attributes of a type ordered by attribute ID
+func (r *SDERepository) GetTypeAttributes(typeID int32) ([]*SDEAttribute, error) {
+	attributes, err := r.GetTypesAttributes([]int32{typeID})
+	if err != nil {
+		return nil, err
+	}
+	return attributes[typeID], nil
+}
+
+// GetTypesAttributes retrieves the dogma attributes of several types at once, keyed by type ID.
+// Types without attributes are missing from the result.
+func (r *SDERepository) GetTypesAttributes(typeIDs []int32) (map[int32][]*SDEAttribute, error) {
+	result := make(map[int32][]*SDEAttribute, len(typeIDs))
+	if len(typeIDs) == 0 {
+		return result, nil
+	}
+
+	db, release := r.acquire()
+	defer release()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(typeIDs)), ", ")
+	args := make([]interface{}, 0, len(typeIDs))
+	for _, typeID := range typeIDs {
+		args = append(args, typeID)
+	}
+
+	// Fuzzwork dumps store a value either as valueInt or valueFloat
+	query := `
+		SELECT ta.typeID, ta.attributeID, COALESCE(at.attributeName, ''), COALESCE(at.displayName, ''),
+			COALESCE(at.unitID, 0), COALESCE(u.displayName, ''), COALESCE(ta.valueFloat, ta.valueInt, 0),
+			COALESCE(at.highIsGood, 1), COALESCE(at.published, 0)
+		FROM dgmTypeAttributes ta
+		LEFT JOIN dgmAttributeTypes at ON at.attributeID = ta.attributeID
+		LEFT JOIN eveUnits u ON u.unitID = at.unitID
+		WHERE ta.typeID IN (` + placeholders + `)
+		ORDER BY ta.typeID, ta.attributeID
+	`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type attributes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attribute SDEAttribute
+		err := rows.Scan(
+			&attribute.TypeID,
+			&attribute.AttributeID,
+			&attribute.AttributeName,
+			&attribute.DisplayName,
+			&attribute.UnitID,
+			&attribute.UnitName,
+			&attribute.Value,
+			&attribute.HighIsGood,
+			&attribute.Published,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan type attribute: %w", err)
+		}
+		result[attribute.TypeID] = append(result[attribute.TypeID], &attribute)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Industry activity IDs used in the SDE industry tables
+const (
+	ActivityManufacturing int32 = 1
+)
+
+// ErrBlueprintNotFound is returned when no blueprint produces the requested type
+var ErrBlueprintNotFound = errors.New("blueprint not found")
+
+// SDEBlueprint represents a blueprint activity with its product, inputs and requirements
+type SDEBlueprint struct {
+	BlueprintTypeID int32                  `json:"blueprintTypeId"`
+	ActivityID      int32                  `json:"activityId"`
+	ProductTypeID   int32                  `json:"productTypeId"`
+	ProductQuantity int32                  `json:"productQuantity"`
+	Time            int32                  `json:"time"` // Seconds per run
+	Materials       []SDEBlueprintMaterial `json:"materials"`
+	Skills          []SDEBlueprintSkill    `json:"skills"`
+}
+
+// SDEBlueprintMaterial represents a material consumed per run
+type SDEBlueprintMaterial struct {
+	TypeID   int32 `json:"typeId"`
+	Quantity int32 `json:"quantity"`
+}
+
+// SDEBlueprintSkill represents a skill required to run the activity
+type SDEBlueprintSkill struct {
+	SkillID int32 `json:"skillId"`
+	Level   int32 `json:"level"`
+}
+
+// GetBlueprintByProduct retrieves the manufacturing blueprint that produces a type
+func (r *SDERepository) GetBlueprintByProduct(productTypeID int32) (*SDEBlueprint, error) {
+	db, release := r.acquire()
+	defer release()
+
+	query := `
+		SELECT p.typeID, p.activityID, p.productTypeID, p.quantity, COALESCE(a.time, 0)
+		FROM industryActivityProducts p
+		LEFT JOIN industryActivity a ON a.typeID = p.typeID AND a.activityID = p.activityID
+		WHERE p.productTypeID = ? AND p.activityID = ?
+		ORDER BY p.typeID
+		LIMIT 1
+	`
+
+	var blueprint SDEBlueprint
+	err := db.QueryRow(query, productTypeID, ActivityManufacturing).Scan(
+		&blueprint.BlueprintTypeID,
+		&blueprint.ActivityID,
+		&blueprint.ProductTypeID,
+		&blueprint.ProductQuantity,
+		&blueprint.Time,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: productTypeID %d", ErrBlueprintNotFound, productTypeID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blueprint: %w", err)
+	}
+
+	if blueprint.Materials, err = r.getBlueprintMaterials(db, blueprint.BlueprintTypeID, blueprint.ActivityID); err != nil {
+		return nil, err
+	}
+	if blueprint.Skills, err = r.getBlueprintSkills(db, blueprint.BlueprintTypeID, blueprint.ActivityID); err != nil {
+		return nil, err
+	}
+
+	return &blueprint, nil
+}
+
+// getBlueprintMaterials retrieves the per-run materials of a blueprint activity
+func (r *SDERepository) getBlueprintMaterials(db *sql.DB, blueprintTypeID, activityID int32) ([]SDEBlueprintMaterial, error) {
+	query := `
+		SELECT materialTypeID, quantity
+		FROM industryActivityMaterials
+		WHERE typeID = ? AND activityID = ?
+		ORDER BY materialTypeID
+	`
+
+	rows, err := db.Query(query, blueprintTypeID, activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blueprint materials: %w", err)
+	}
+	defer rows.Close()
+
+	var materials []SDEBlueprintMaterial
+	for rows.Next() {
+		var material SDEBlueprintMaterial
+		if err := rows.Scan(&material.TypeID, &material.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan blueprint material: %w", err)
+		}
+		materials = append(materials, material)
+	}
+
+	return materials, rows.Err()
+}
+
+// getBlueprintSkills retrieves the skills required for a blueprint activity
+func (r *SDERepository) getBlueprintSkills(db *sql.DB, blueprintTypeID, activityID int32) ([]SDEBlueprintSkill, error) {
+	query := `
+		SELECT skillID, level
+		FROM industryActivitySkills
+		WHERE typeID = ? AND activityID = ?
+		ORDER BY skillID
+	`
+
+	rows, err := db.Query(query, blueprintTypeID, activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blueprint skills: %w", err)
+	}
+	defer rows.Close()
+
+	var skills []SDEBlueprintSkill
+	for rows.Next() {
+		var skill SDEBlueprintSkill
+		if err := rows.Scan(&skill.SkillID, &skill.Level); err != nil {
+			return nil, fmt.Errorf("failed to scan blueprint skill: %w", err)
+		}
+		skills = append(skills, skill)
+	}
+
+	return skills, rows.Err()
+}
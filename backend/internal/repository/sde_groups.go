@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SDECategory represents an item category from invCategories
+type SDECategory struct {
+	CategoryID   int32  `json:"categoryId"`
+	CategoryName string `json:"categoryName"`
+	Published    bool   `json:"published"`
+}
+
+// SDEGroup represents an item group from invGroups
+type SDEGroup struct {
+	GroupID    int32  `json:"groupId"`
+	CategoryID int32  `json:"categoryId"`
+	GroupName  string `json:"groupName"`
+	Published  bool   `json:"published"`
+}
+
+// SDEMarketGroup represents a node of the invMarketGroups tree
+type SDEMarketGroup struct {
+	MarketGroupID   int32  `json:"marketGroupId"`
+	ParentGroupID   int32  `json:"parentGroupId,omitempty"` // 0 for root groups
+	MarketGroupName string `json:"marketGroupName"`
+	Description     string `json:"description"`
+	HasTypes        bool   `json:"hasTypes"`
+}
+
+// GetCategories retrieves all published categories
+func (r *SDERepository) GetCategories() ([]*SDECategory, error) {
+	db, release := r.acquire()
+	defer release()
+
+	query := `
+		SELECT categoryID, categoryName, published
+		FROM invCategories
+		WHERE published = 1
+		ORDER BY categoryName
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []*SDECategory
+	for rows.Next() {
+		var category SDECategory
+		if err := rows.Scan(&category.CategoryID, &category.CategoryName, &category.Published); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, &category)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetGroupsByCategory retrieves all published groups of a category
+func (r *SDERepository) GetGroupsByCategory(categoryID int32) ([]*SDEGroup, error) {
+	db, release := r.acquire()
+	defer release()
+
+	query := `
+		SELECT groupID, categoryID, groupName, published
+		FROM invGroups
+		WHERE categoryID = ? AND published = 1
+		ORDER BY groupName
+	`
+
+	rows, err := db.Query(query, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*SDEGroup
+	for rows.Next() {
+		var group SDEGroup
+		if err := rows.Scan(&group.GroupID, &group.CategoryID, &group.GroupName, &group.Published); err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, &group)
+	}
+
+	return groups, rows.Err()
+}
+
+// GetMarketGroups retrieves the complete flat list of market groups
+func (r *SDERepository) GetMarketGroups() ([]*SDEMarketGroup, error) {
+	db, release := r.acquire()
+	defer release()
+
+	query := `
+		SELECT marketGroupID, parentGroupID, marketGroupName, COALESCE(description, ''), COALESCE(hasTypes, 0)
+		FROM invMarketGroups
+		ORDER BY marketGroupName
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market groups: %w", err)
+	}
+	defer rows.Close()
+
+	var marketGroups []*SDEMarketGroup
+	for rows.Next() {
+		var marketGroup SDEMarketGroup
+		var parentGroupID sql.NullInt32
+
+		err := rows.Scan(
+			&marketGroup.MarketGroupID,
+			&parentGroupID,
+			&marketGroup.MarketGroupName,
+			&marketGroup.Description,
+			&marketGroup.HasTypes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan market group: %w", err)
+		}
+
+		if parentGroupID.Valid {
+			marketGroup.ParentGroupID = parentGroupID.Int32
+		}
+
+		marketGroups = append(marketGroups, &marketGroup)
+	}
+
+	return marketGroups, rows.Err()
+}
+
+// GetItemsByMarketGroup retrieves the published types directly listed under a market group
+func (r *SDERepository) GetItemsByMarketGroup(marketGroupID int32) ([]*SDEItem, error) {
+	db, release := r.acquire()
+	defer release()
+
+	query := `SELECT ` + sdeItemColumns + sdeItemJoins + `
+		WHERE t.marketGroupID = ? AND t.published = 1
+		ORDER BY t.typeName
+	`
+
+	rows, err := db.Query(query, marketGroupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market group items: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSDEItems(rows)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrStationNotFound is returned for locations that are not NPC stations, e.g. player structures
+var ErrStationNotFound = errors.New("station not found")
+
+// SDESolarSystem represents a solar system from mapSolarSystems
+type SDESolarSystem struct {
+	SolarSystemID   int32   `json:"solarSystemId"`
+	SolarSystemName string  `json:"solarSystemName"`
+	RegionID        int32   `json:"regionId"`
+	Security        float64 `json:"security"`
+}
+
+// SDESolarSystemJump is one stargate connection from mapSolarSystemJumps
+type SDESolarSystemJump struct {
+	FromSolarSystemID int32 `json:"fromSolarSystemId"`
+	ToSolarSystemID   int32 `json:"toSolarSystemId"`
+}
+
+// GetSolarSystems retrieves all solar systems
+func (r *SDERepository) GetSolarSystems() ([]*SDESolarSystem, error) {
+	db, release := r.acquire()
+	defer release()
+
+	rows, err := db.Query(`
+		SELECT solarSystemID, COALESCE(solarSystemName, ''), COALESCE(regionID, 0), COALESCE(security, 0)
+		FROM mapSolarSystems
+		ORDER BY solarSystemID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get solar systems: %w", err)
+	}
+	defer rows.Close()
+
+	var systems []*SDESolarSystem
+	for rows.Next() {
+		var system SDESolarSystem
+		if err := rows.Scan(&system.SolarSystemID, &system.SolarSystemName, &system.RegionID, &system.Security); err != nil {
+			return nil, fmt.Errorf("failed to scan solar system: %w", err)
+		}
+		systems = append(systems, &system)
+	}
+
+	return systems, rows.Err()
+}
+
+// GetSolarSystemJumps retrieves all stargate connections. Each connection is
+// listed in both directions.
+func (r *SDERepository) GetSolarSystemJumps() ([]SDESolarSystemJump, error) {
+	db, release := r.acquire()
+	defer release()
+
+	rows, err := db.Query(`
+		SELECT fromSolarSystemID, toSolarSystemID
+		FROM mapSolarSystemJumps
+		ORDER BY fromSolarSystemID, toSolarSystemID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get solar system jumps: %w", err)
+	}
+	defer rows.Close()
+
+	var jumps []SDESolarSystemJump
+	for rows.Next() {
+		var jump SDESolarSystemJump
+		if err := rows.Scan(&jump.FromSolarSystemID, &jump.ToSolarSystemID); err != nil {
+			return nil, fmt.Errorf("failed to scan solar system jump: %w", err)
+		}
+		jumps = append(jumps, jump)
+	}
+
+	return jumps, rows.Err()
+}
+
+// GetStationByID retrieves an NPC station
+func (r *SDERepository) GetStationByID(stationID int64) (*SDEStation, error) {
+	db, release := r.acquire()
+	defer release()
+
+	var station SDEStation
+	err := db.QueryRow(`
+		SELECT stationID, COALESCE(stationName, ''), solarSystemID, regionID, COALESCE(stationTypeID, 0)
+		FROM staStations
+		WHERE stationID = ?
+	`, stationID).Scan(&station.StationID, &station.StationName, &station.SystemID, &station.RegionID, &station.TypeID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: stationID %d", ErrStationNotFound, stationID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station: %w", err)
+	}
+
+	return &station, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Category IDs with special reprocessing rules
+const (
+	CategoryAsteroid int32 = 25
+)
+
+// ErrTypeMaterialsNotFound is returned when a type yields no materials on reprocessing
+var ErrTypeMaterialsNotFound = errors.New("type materials not found")
+
+// SDETypeMaterials represents the reprocessing output of one portion of a type
+type SDETypeMaterials struct {
+	TypeID      int32                  `json:"typeId"`
+	PortionSize int32                  `json:"portionSize"`
+	CategoryID  int32                  `json:"categoryId"`
+	Materials   []SDEBlueprintMaterial `json:"materials"`
+}
+
+// GetTypeMaterials retrieves the reprocessing materials of a type from invTypeMaterials
+func (r *SDERepository) GetTypeMaterials(typeID int32) (*SDETypeMaterials, error) {
+	db, release := r.acquire()
+	defer release()
+
+	query := `
+		SELECT t.typeID, COALESCE(t.portionSize, 1), COALESCE(g.categoryID, 0)
+		FROM invTypes t
+		LEFT JOIN invGroups g ON g.groupID = t.groupID
+		WHERE t.typeID = ?
+	`
+
+	var result SDETypeMaterials
+	err := db.QueryRow(query, typeID).Scan(&result.TypeID, &result.PortionSize, &result.CategoryID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: typeID %d", ErrItemNotFound, typeID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type: %w", err)
+	}
+
+	rows, err := db.Query(`
+		SELECT materialTypeID, quantity
+		FROM invTypeMaterials
+		WHERE typeID = ?
+		ORDER BY materialTypeID
+	`, typeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type materials: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var material SDEBlueprintMaterial
+		if err := rows.Scan(&material.TypeID, &material.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan type material: %w", err)
+		}
+		result.Materials = append(result.Materials, material)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read type materials: %w", err)
+	}
+
+	if len(result.Materials) == 0 {
+		return nil, fmt.Errorf("%w: typeID %d", ErrTypeMaterialsNotFound, typeID)
+	}
+	if result.PortionSize < 1 {
+		result.PortionSize = 1
+	}
+
+	return &result, nil
+}
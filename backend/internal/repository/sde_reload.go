@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// ErrInvalidSDESchema is returned when a database lacks tables or columns the repository queries
+var ErrInvalidSDESchema = errors.New("invalid SDE schema")
+
+// SDEMetadataTable holds the build number and checksum written by the SDE importer
+const SDEMetadataTable = "sdeMetadata"
+
+// SDE metadata keys
+const (
+	SDEMetadataBuildNumber = "build_number"
+	SDEMetadataReleaseDate = "release_date"
+	SDEMetadataChecksum    = "checksum"
+	SDEMetadataImportedAt  = "imported_at"
+)
+
+// SDEDrainTimeout bounds how long a swap waits for queries on the old database
+const SDEDrainTimeout = 30 * time.Second
+
+// requiredSDEColumns lists the tables and columns the repository queries
+var requiredSDEColumns = map[string][]string{
+	"invTypes":                  {"typeID", "groupID", "typeName", "description", "mass", "volume", "portionSize", "published", "marketGroupID"},
+	"invGroups":                 {"groupID", "categoryID", "groupName", "published"},
+	"invCategories":             {"categoryID", "categoryName", "published"},
+	"invMarketGroups":           {"marketGroupID", "parentGroupID", "marketGroupName", "description", "hasTypes"},
+	"invTypeMaterials":          {"typeID", "materialTypeID", "quantity"},
+	"invMetaTypes":              {"typeID", "parentTypeID", "metaGroupID"},
+	"invMetaGroups":             {"metaGroupID", "metaGroupName"},
+	"industryActivity":          {"typeID", "activityID", "time"},
+	"industryActivityMaterials": {"typeID", "activityID", "materialTypeID", "quantity"},
+	"industryActivityProducts":  {"typeID", "activityID", "productTypeID", "quantity"},
+	"industryActivitySkills":    {"typeID", "activityID", "skillID", "level"},
+	"dgmAttributeTypes":         {"attributeID", "attributeName", "displayName", "unitID", "published", "highIsGood"},
+	"dgmTypeAttributes":         {"typeID", "attributeID", "valueInt", "valueFloat"},
+	"eveUnits":                  {"unitID", "unitName", "displayName"},
+	"staStations":               {"stationID", "stationName", "solarSystemID", "regionID", "stationTypeID"},
+	"mapRegions":                {"regionID", "regionName"},
+	"mapSolarSystems":           {"solarSystemID", "solarSystemName", "regionID", "security"},
+	"mapSolarSystemJumps":       {"fromSolarSystemID", "toSolarSystemID"},
+}
+
+// Version returns the SDE version currently being served
+func (r *SDERepository) Version() models.SDEVersion {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current.version
+}
+
+// OnSwap registers a listener that is called after a new SDE database was swapped in
+func (r *SDERepository) OnSwap(listener func(models.SDEVersion)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// Reload opens the database file again, validates it and atomically swaps it in.
+// Queries running against the old database are drained before it is closed.
+// If the new file is invalid the old database keeps serving.
+func (r *SDERepository) Reload() (models.SDEVersion, error) {
+	handle, err := openSDEDatabase(r.path)
+	if err != nil {
+		return models.SDEVersion{}, fmt.Errorf("failed to load new SDE: %w", err)
+	}
+
+	r.mu.Lock()
+	previous := r.current
+	r.current = handle
+	listeners := append([]func(models.SDEVersion){}, r.listeners...)
+	r.mu.Unlock()
+
+	drainSDEHandle(previous, SDEDrainTimeout)
+
+	for _, listener := range listeners {
+		listener(handle.version)
+	}
+	return handle.version, nil
+}
+
+// CheckForUpdate reloads the database once its file changed and stayed unchanged
+// for one more check, so half-copied files are never opened. It reports whether
+// a new database was swapped in.
+func (r *SDERepository) CheckForUpdate() (bool, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat SDE database: %w", err)
+	}
+	stamp := sdeFileStamp{modTime: info.ModTime(), size: info.Size()}
+
+	r.mu.Lock()
+	if stamp == r.current.stamp {
+		r.pending = nil
+		r.mu.Unlock()
+		return false, nil
+	}
+	if r.pending == nil || *r.pending != stamp {
+		r.pending = &stamp // Wait for the file to settle
+		r.mu.Unlock()
+		return false, nil
+	}
+	r.pending = nil
+	r.mu.Unlock()
+
+	if _, err := r.Reload(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Watch checks the database file for updates until the context is cancelled
+func (r *SDERepository) Watch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			swapped, err := r.CheckForUpdate()
+			if err != nil {
+				fmt.Printf("SDE update check failed: %v\n", err)
+				continue
+			}
+			if swapped {
+				version := r.Version()
+				fmt.Printf("Swapped in SDE build %d from %s\n", version.BuildNumber, version.Path)
+			}
+		}
+	}()
+}
+
+// sdeFileStamp identifies a version of the database file on disk
+type sdeFileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// drainSDEHandle waits for in-flight queries and closes the database
+func drainSDEHandle(handle *sdeHandle, timeout time.Duration) {
+	drained := make(chan struct{})
+	go func() {
+		handle.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		fmt.Printf("Warning: closing previous SDE database with queries still running\n")
+	}
+	handle.db.Close()
+}
+
+// validateSDESchema checks that all tables and columns used by the repository exist
+func validateSDESchema(db *sql.DB) error {
+	tables := make([]string, 0, len(requiredSDEColumns))
+	for table := range requiredSDEColumns {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var problems []string
+	for _, table := range tables {
+		columns, err := tableColumns(db, table)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			problems = append(problems, "missing table "+table)
+			continue
+		}
+		for _, column := range requiredSDEColumns[table] {
+			if !columns[strings.ToLower(column)] {
+				problems = append(problems, fmt.Sprintf("missing column %s.%s", table, column))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidSDESchema, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// tableColumns returns the lower-cased column names of a table, empty if it does not exist
+func tableColumns(db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	return columns, rows.Err()
+}
+
+// readSDEVersion describes a database file, including importer metadata if present
+func readSDEVersion(db *sql.DB, dbPath string) (models.SDEVersion, sdeFileStamp, error) {
+	version := models.SDEVersion{Path: dbPath, LoadedAt: time.Now()}
+
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		return version, sdeFileStamp{}, fmt.Errorf("failed to stat SDE database: %w", err)
+	}
+	version.ModifiedAt = info.ModTime()
+	stamp := sdeFileStamp{modTime: info.ModTime(), size: info.Size()}
+
+	columns, err := tableColumns(db, SDEMetadataTable)
+	if err != nil || len(columns) == 0 {
+		return version, stamp, err // Third party dumps carry no metadata
+	}
+
+	rows, err := db.Query(`SELECT key, value FROM ` + SDEMetadataTable)
+	if err != nil {
+		return version, stamp, fmt.Errorf("failed to read SDE metadata: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return version, stamp, fmt.Errorf("failed to scan SDE metadata: %w", err)
+		}
+		switch key {
+		case SDEMetadataBuildNumber:
+			version.BuildNumber, _ = strconv.ParseInt(value, 10, 64)
+		case SDEMetadataReleaseDate:
+			version.ReleaseDate = value
+		case SDEMetadataChecksum:
+			version.Checksum = value
+		}
+	}
+
+	return version, stamp, rows.Err()
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Meta group IDs of invMetaGroups
+const (
+	MetaGroupTechI     int32 = 1
+	MetaGroupTechII    int32 = 2
+	MetaGroupStoryline int32 = 3
+	MetaGroupFaction   int32 = 4
+	MetaGroupOfficer   int32 = 5
+	MetaGroupDeadspace int32 = 6
+)
+
+// SDETypeVariant is a published variant of a type, e.g. a named, T2 or faction module
+type SDETypeVariant struct {
+	TypeID        int32  `json:"typeId"`
+	TypeName      string `json:"typeName"`
+	ParentTypeID  int32  `json:"parentTypeId"`
+	MetaGroupID   int32  `json:"metaGroupId"`
+	MetaGroupName string `json:"metaGroupName"`
+	MarketGroup   int32  `json:"marketGroupID,omitempty"`
+}
+
+// GetTypeVariants retrieves the published variants of a type including its T1 parent.
+// Any variant can be passed, the parent is resolved through invMetaTypes. Types
+// without an invMetaTypes row are treated as Tech I.
+func (r *SDERepository) GetTypeVariants(typeID int32) ([]*SDETypeVariant, error) {
+	db, release := r.acquire()
+	defer release()
+
+	parentTypeID := typeID
+	err := db.QueryRow(`SELECT parentTypeID FROM invMetaTypes WHERE typeID = ? AND parentTypeID IS NOT NULL`, typeID).Scan(&parentTypeID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to resolve parent type: %w", err)
+	}
+
+	query := `
+		SELECT t.typeID, t.typeName, COALESCE(mt.metaGroupID, ?), COALESCE(mg.metaGroupName, ''), t.marketGroupID
+		FROM invTypes t
+		LEFT JOIN invMetaTypes mt ON mt.typeID = t.typeID
+		LEFT JOIN invMetaGroups mg ON mg.metaGroupID = COALESCE(mt.metaGroupID, ?)
+		WHERE t.published = 1 AND (t.typeID = ? OR mt.parentTypeID = ?)
+		ORDER BY t.typeID
+	`
+
+	rows, err := db.Query(query, MetaGroupTechI, MetaGroupTechI, parentTypeID, parentTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []*SDETypeVariant
+	for rows.Next() {
+		variant := SDETypeVariant{ParentTypeID: parentTypeID}
+		var marketGroup sql.NullInt32
+		err := rows.Scan(&variant.TypeID, &variant.TypeName, &variant.MetaGroupID, &variant.MetaGroupName, &marketGroup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan type variant: %w", err)
+		}
+		if marketGroup.Valid {
+			variant.MarketGroup = marketGroup.Int32
+		}
+		variants = append(variants, &variant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("%w: typeID %d", ErrItemNotFound, typeID)
+	}
+	return variants, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Token store errors
+var (
+	ErrTokenNotFound         = errors.New("token not found")
+	ErrMissingEncryptionKey  = errors.New("token encryption key is required")
+	ErrTokenDecryptionFailed = errors.New("failed to decrypt token, wrong encryption key?")
+)
+
+// SQLiteTokenStore keeps SSO tokens in the application database. Access and
+// refresh tokens are encrypted with AES-256-GCM, bound to their character ID.
+type SQLiteTokenStore struct {
+	db   *AppDB
+	aead cipher.AEAD
+}
+
+// NewSQLiteTokenStore fails unless the token table was migrated. The AES key is
+// derived from the secret with SHA-256, so the secret should be a long random value.
+func NewSQLiteTokenStore(db *AppDB, secret string) (*SQLiteTokenStore, error) {
+	if secret == "" {
+		return nil, ErrMissingEncryptionKey
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	if err := db.requireSchema(schemaVersionTokens); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteTokenStore{db: db, aead: aead}, nil
+}
+
+// SaveToken inserts or replaces the token of a character and clears a revoked flag
+func (s *SQLiteTokenStore) SaveToken(token *models.AuthToken) error {
+	accessToken, err := s.encrypt(token.CharacterID, token.AccessToken)
+	if err != nil {
+		return err
+	}
+	refreshToken, err := s.encrypt(token.CharacterID, token.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO characterTokens (characterID, characterName, accessToken, refreshToken, tokenType, expiresAt, scopes, revoked, updatedAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(characterID) DO UPDATE SET
+			characterName = excluded.characterName,
+			accessToken = excluded.accessToken,
+			refreshToken = excluded.refreshToken,
+			tokenType = excluded.tokenType,
+			expiresAt = excluded.expiresAt,
+			scopes = excluded.scopes,
+			revoked = excluded.revoked,
+			updatedAt = excluded.updatedAt
+	`, token.CharacterID, token.CharacterName, accessToken, refreshToken, token.TokenType,
+		token.ExpiresAt.Unix(), strings.Join(token.Scopes, " "), token.Revoked, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// GetToken returns the decrypted token of a character
+func (s *SQLiteTokenStore) GetToken(characterID int32) (*models.AuthToken, error) {
+	row := s.db.QueryRow(`
+		SELECT characterID, characterName, accessToken, refreshToken, tokenType, expiresAt, scopes, revoked
+		FROM characterTokens WHERE characterID = ?
+	`, characterID)
+
+	token, err := s.scanToken(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: characterID %d", ErrTokenNotFound, characterID)
+	}
+	return token, err
+}
+
+// ListTokens returns the tokens of all characters, including revoked ones
+func (s *SQLiteTokenStore) ListTokens() ([]*models.AuthToken, error) {
+	rows, err := s.db.Query(`
+		SELECT characterID, characterName, accessToken, refreshToken, tokenType, expiresAt, scopes, revoked
+		FROM characterTokens ORDER BY characterID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*models.AuthToken
+	for rows.Next() {
+		token, err := s.scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// MarkRevoked flags the token of a character as needing a new login
+func (s *SQLiteTokenStore) MarkRevoked(characterID int32) error {
+	result, err := s.db.Exec(`UPDATE characterTokens SET revoked = 1, updatedAt = ? WHERE characterID = ?`, time.Now().Unix(), characterID)
+	if err != nil {
+		return fmt.Errorf("failed to mark token revoked: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("%w: characterID %d", ErrTokenNotFound, characterID)
+	}
+	return nil
+}
+
+// DeleteToken removes the token of a character
+func (s *SQLiteTokenStore) DeleteToken(characterID int32) error {
+	if _, err := s.db.Exec(`DELETE FROM characterTokens WHERE characterID = ?`, characterID); err != nil {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteTokenStore) scanToken(row rowScanner) (*models.AuthToken, error) {
+	var token models.AuthToken
+	var accessToken, refreshToken []byte
+	var expiresAt int64
+	var scopes string
+
+	err := row.Scan(&token.CharacterID, &token.CharacterName, &accessToken, &refreshToken,
+		&token.TokenType, &expiresAt, &scopes, &token.Revoked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan token: %w", err)
+	}
+
+	if token.AccessToken, err = s.decrypt(token.CharacterID, accessToken); err != nil {
+		return nil, err
+	}
+	if token.RefreshToken, err = s.decrypt(token.CharacterID, refreshToken); err != nil {
+		return nil, err
+	}
+	token.ExpiresAt = time.Unix(expiresAt, 0)
+	token.Scopes = strings.Fields(scopes)
+	return &token, nil
+}
+
+// encrypt seals a value as nonce followed by ciphertext. The character ID is
+// authenticated data, so rows cannot be swapped between characters.
+func (s *SQLiteTokenStore) encrypt(characterID int32, value string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return s.aead.Seal(nonce, nonce, []byte(value), characterAAD(characterID)), nil
+}
+
+func (s *SQLiteTokenStore) decrypt(characterID int32, sealed []byte) (string, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", ErrTokenDecryptionFailed
+	}
+	plain, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], characterAAD(characterID))
+	if err != nil {
+		return "", ErrTokenDecryptionFailed
+	}
+	return string(plain), nil
+}
+
+func characterAAD(characterID int32) []byte {
+	aad := make([]byte, 4)
+	binary.BigEndian.PutUint32(aad, uint32(characterID))
+	return aad
+}
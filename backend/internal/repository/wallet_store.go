@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// WalletStore keeps synced wallet journals and market transactions in the
+// application database. Entries are deduplicated by their ESI ID.
+type WalletStore struct {
+	db *AppDB
+}
+
+// NewWalletStore fails unless the wallet tables were migrated
+func NewWalletStore(db *AppDB) (*WalletStore, error) {
+	if err := db.requireSchema(schemaVersionWallet); err != nil {
+		return nil, err
+	}
+	return &WalletStore{db: db}, nil
+}
+
+// SaveJournalEntries stores new journal entries and returns how many were new
+func (s *WalletStore) SaveJournalEntries(characterID int32, entries []models.WalletJournalEntry) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to save journal: %w", err)
+	}
+	defer tx.Rollback()
+
+	inserted := 0
+	for _, entry := range entries {
+		result, err := tx.Exec(`
+			INSERT INTO walletJournal (journalID, characterID, date, refType, amount, balance, tax,
+				contextID, contextIDType, description, firstPartyID, secondPartyID)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`, entry.ID, characterID, entry.Date.Unix(), entry.RefType, entry.Amount, entry.Balance, entry.Tax,
+			entry.ContextID, entry.ContextIDType, entry.Description, entry.FirstPartyID, entry.SecondPartyID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save journal entry %d: %w", entry.ID, err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			inserted++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to save journal: %w", err)
+	}
+	return inserted, nil
+}
+
+// SaveTransactions stores new market transactions and returns how many were new
+func (s *WalletStore) SaveTransactions(characterID int32, transactions []models.WalletTransaction) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to save transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	inserted := 0
+	for _, transaction := range transactions {
+		result, err := tx.Exec(`
+			INSERT INTO walletTransactions (transactionID, characterID, date, typeID, quantity, unitPrice,
+				locationID, clientID, isBuy, isPersonal, journalRefID)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`, transaction.TransactionID, characterID, transaction.Date.Unix(), transaction.TypeID, transaction.Quantity,
+			transaction.UnitPrice, transaction.LocationID, transaction.ClientID, transaction.IsBuy, transaction.IsPersonal,
+			transaction.JournalRefID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save transaction %d: %w", transaction.TransactionID, err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			inserted++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to save transactions: %w", err)
+	}
+	return inserted, nil
+}
+
+// LatestJournalID returns the highest stored journal ID of a character, 0 if none
+func (s *WalletStore) LatestJournalID(characterID int32) (int64, error) {
+	var latest int64
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(journalID), 0) FROM walletJournal WHERE characterID = ?`, characterID).Scan(&latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest journal ID: %w", err)
+	}
+	return latest, nil
+}
+
+// LatestTransactionID returns the highest stored transaction ID of a character, 0 if none
+func (s *WalletStore) LatestTransactionID(characterID int32) (int64, error) {
+	var latest int64
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(transactionID), 0) FROM walletTransactions WHERE characterID = ?`, characterID).Scan(&latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest transaction ID: %w", err)
+	}
+	return latest, nil
+}
+
+// ListJournalEntries returns the journal of a character, oldest first
+func (s *WalletStore) ListJournalEntries(characterID int32) ([]models.WalletJournalEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT journalID, date, refType, amount, balance, tax, contextID, contextIDType, description, firstPartyID, secondPartyID
+		FROM walletJournal WHERE characterID = ? ORDER BY date, journalID
+	`, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.WalletJournalEntry{}
+	for rows.Next() {
+		var entry models.WalletJournalEntry
+		var date int64
+		if err := rows.Scan(&entry.ID, &date, &entry.RefType, &entry.Amount, &entry.Balance, &entry.Tax, &entry.ContextID,
+			&entry.ContextIDType, &entry.Description, &entry.FirstPartyID, &entry.SecondPartyID); err != nil {
+			return nil, fmt.Errorf("failed to scan journal entry: %w", err)
+		}
+		entry.Date = time.Unix(date, 0).UTC()
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// ListTransactions returns the market transactions of a character, oldest first
+func (s *WalletStore) ListTransactions(characterID int32) ([]models.WalletTransaction, error) {
+	rows, err := s.db.Query(`
+		SELECT transactionID, date, typeID, quantity, unitPrice, locationID, clientID, isBuy, isPersonal, journalRefID
+		FROM walletTransactions WHERE characterID = ? ORDER BY date, transactionID
+	`, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []models.WalletTransaction{}
+	for rows.Next() {
+		var transaction models.WalletTransaction
+		var date int64
+		if err := rows.Scan(&transaction.TransactionID, &date, &transaction.TypeID, &transaction.Quantity, &transaction.UnitPrice,
+			&transaction.LocationID, &transaction.ClientID, &transaction.IsBuy, &transaction.IsPersonal, &transaction.JournalRefID); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transaction.Date = time.Unix(date, 0).UTC()
+		transactions = append(transactions, transaction)
+	}
+	return transactions, rows.Err()
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Errors for watchlists and saved searches that do not exist or belong to another character
+var (
+	ErrWatchlistNotFound   = errors.New("watchlist not found")
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+)
+
+const savedSearchColumns = `searchID, characterID, name, query, categoryID, marketGroupID, published, marketable, createdAt`
+
+// WatchlistStore keeps the watchlists and saved item searches of characters in the application database
+type WatchlistStore struct {
+	db *AppDB
+}
+
+// NewWatchlistStore fails unless the watchlist and saved search tables were migrated
+func NewWatchlistStore(db *AppDB) (*WatchlistStore, error) {
+	if err := db.requireSchema(schemaVersionWatchlists); err != nil {
+		return nil, err
+	}
+	return &WatchlistStore{db: db}, nil
+}
+
+// CreateWatchlist stores a new watchlist of list.CharacterID with its items
+func (s *WatchlistStore) CreateWatchlist(list *models.Watchlist) (*models.Watchlist, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	var watchlistID int64
+	err = tx.QueryRow(`
+		INSERT INTO watchlists (characterID, name, notes, createdAt, updatedAt) VALUES (?, ?, ?, ?, ?)
+		RETURNING watchlistID
+	`, list.CharacterID, list.Name, list.Notes, now, now).Scan(&watchlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watchlist: %w", err)
+	}
+	if err := insertWatchlistItems(tx, watchlistID, list.Items); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit watchlist: %w", err)
+	}
+	return s.GetWatchlist(list.CharacterID, watchlistID)
+}
+
+// UpdateWatchlist replaces the name, notes and items of a watchlist
+func (s *WatchlistStore) UpdateWatchlist(list *models.Watchlist) (*models.Watchlist, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE watchlists SET name = ?, notes = ?, updatedAt = ? WHERE watchlistID = ? AND characterID = ?
+	`, list.Name, list.Notes, time.Now().Unix(), list.WatchlistID, list.CharacterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update watchlist: %w", err)
+	}
+	if err := requireAffected(result, ErrWatchlistNotFound, "watchlistID", list.WatchlistID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM watchlistItems WHERE watchlistID = ?`, list.WatchlistID); err != nil {
+		return nil, fmt.Errorf("failed to replace watchlist items: %w", err)
+	}
+	if err := insertWatchlistItems(tx, list.WatchlistID, list.Items); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit watchlist: %w", err)
+	}
+	return s.GetWatchlist(list.CharacterID, list.WatchlistID)
+}
+
+// DeleteWatchlist removes a watchlist of a character with its items
+func (s *WatchlistStore) DeleteWatchlist(characterID int32, watchlistID int64) error {
+	result, err := s.db.Exec(`DELETE FROM watchlists WHERE watchlistID = ? AND characterID = ?`, watchlistID, characterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete watchlist: %w", err)
+	}
+	return requireAffected(result, ErrWatchlistNotFound, "watchlistID", watchlistID)
+}
+
+// GetWatchlist returns a watchlist of a character with its items in list order
+func (s *WatchlistStore) GetWatchlist(characterID int32, watchlistID int64) (*models.Watchlist, error) {
+	lists, err := s.queryWatchlists(`WHERE w.watchlistID = ? AND w.characterID = ?`, watchlistID, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, fmt.Errorf("%w: watchlistID %d", ErrWatchlistNotFound, watchlistID)
+	}
+	return &lists[0], nil
+}
+
+// ListWatchlists returns the watchlists of a character in creation order
+func (s *WatchlistStore) ListWatchlists(characterID int32) ([]models.Watchlist, error) {
+	return s.queryWatchlists(`WHERE w.characterID = ?`, characterID)
+}
+
+// queryWatchlists loads the matching watchlists and their items with one query
+func (s *WatchlistStore) queryWatchlists(where string, args ...interface{}) ([]models.Watchlist, error) {
+	rows, err := s.db.Query(`
+		SELECT w.watchlistID, w.characterID, w.name, w.notes, w.createdAt, w.updatedAt,
+			i.typeID, i.regionID, i.stationID, i.note
+		FROM watchlists w
+		LEFT JOIN watchlistItems i ON i.watchlistID = w.watchlistID
+		`+where+`
+		ORDER BY w.watchlistID, i.position
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlists: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []models.Watchlist{}
+	for rows.Next() {
+		var list models.Watchlist
+		var createdAt, updatedAt int64
+		var typeID, regionID, stationID sql.NullInt64
+		var note sql.NullString
+		if err := rows.Scan(&list.WatchlistID, &list.CharacterID, &list.Name, &list.Notes, &createdAt, &updatedAt,
+			&typeID, &regionID, &stationID, &note); err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist: %w", err)
+		}
+
+		if len(lists) == 0 || lists[len(lists)-1].WatchlistID != list.WatchlistID {
+			list.Items = []models.WatchlistItem{}
+			list.CreatedAt = time.Unix(createdAt, 0)
+			list.UpdatedAt = time.Unix(updatedAt, 0)
+			lists = append(lists, list)
+		}
+		if typeID.Valid { // Lists without items have a single row without item columns
+			current := &lists[len(lists)-1]
+			current.Items = append(current.Items, models.WatchlistItem{
+				TypeID:    int32(typeID.Int64),
+				RegionID:  int32(regionID.Int64),
+				StationID: stationID.Int64,
+				Note:      note.String,
+			})
+		}
+	}
+	return lists, rows.Err()
+}
+
+func insertWatchlistItems(tx *AppTx, watchlistID int64, items []models.WatchlistItem) error {
+	stmt, err := tx.Prepare(`
+		INSERT INTO watchlistItems (watchlistID, position, typeID, regionID, stationID, note) VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare watchlist item insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, item := range items {
+		if _, err := stmt.Exec(watchlistID, i, item.TypeID, item.RegionID, item.StationID, item.Note); err != nil {
+			return fmt.Errorf("failed to save watchlist item: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateSavedSearch stores a new item search of search.CharacterID
+func (s *WatchlistStore) CreateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error) {
+	var searchID int64
+	err := s.db.QueryRow(`
+		INSERT INTO savedSearches (characterID, name, query, categoryID, marketGroupID, published, marketable, createdAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING searchID
+	`, search.CharacterID, search.Name, search.Query, search.CategoryID, search.MarketGroupID,
+		search.Published, search.Marketable, time.Now().Unix()).Scan(&searchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saved search: %w", err)
+	}
+	return s.GetSavedSearch(search.CharacterID, searchID)
+}
+
+// UpdateSavedSearch replaces the query and filters of a saved search
+func (s *WatchlistStore) UpdateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error) {
+	result, err := s.db.Exec(`
+		UPDATE savedSearches SET name = ?, query = ?, categoryID = ?, marketGroupID = ?, published = ?, marketable = ?
+		WHERE searchID = ? AND characterID = ?
+	`, search.Name, search.Query, search.CategoryID, search.MarketGroupID, search.Published, search.Marketable,
+		search.SearchID, search.CharacterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update saved search: %w", err)
+	}
+	if err := requireAffected(result, ErrSavedSearchNotFound, "searchID", search.SearchID); err != nil {
+		return nil, err
+	}
+	return s.GetSavedSearch(search.CharacterID, search.SearchID)
+}
+
+// DeleteSavedSearch removes a saved search of a character
+func (s *WatchlistStore) DeleteSavedSearch(characterID int32, searchID int64) error {
+	result, err := s.db.Exec(`DELETE FROM savedSearches WHERE searchID = ? AND characterID = ?`, searchID, characterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return requireAffected(result, ErrSavedSearchNotFound, "searchID", searchID)
+}
+
+// GetSavedSearch returns a saved search of a character
+func (s *WatchlistStore) GetSavedSearch(characterID int32, searchID int64) (*models.SavedSearch, error) {
+	search, err := scanSavedSearch(s.db.QueryRow(`SELECT `+savedSearchColumns+` FROM savedSearches WHERE searchID = ? AND characterID = ?`,
+		searchID, characterID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: searchID %d", ErrSavedSearchNotFound, searchID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	return search, nil
+}
+
+// ListSavedSearches returns the saved searches of a character in creation order
+func (s *WatchlistStore) ListSavedSearches(characterID int32) ([]models.SavedSearch, error) {
+	rows, err := s.db.Query(`SELECT `+savedSearchColumns+` FROM savedSearches WHERE characterID = ? ORDER BY searchID`, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []models.SavedSearch{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, *search)
+	}
+	return searches, rows.Err()
+}
+
+func scanSavedSearch(row rowScanner) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	var published, marketable sql.NullBool
+	var createdAt int64
+	if err := row.Scan(&search.SearchID, &search.CharacterID, &search.Name, &search.Query, &search.CategoryID,
+		&search.MarketGroupID, &published, &marketable, &createdAt); err != nil {
+		return nil, err
+	}
+	if published.Valid {
+		search.Published = &published.Bool
+	}
+	if marketable.Valid {
+		search.Marketable = &marketable.Bool
+	}
+	search.CreatedAt = time.Unix(createdAt, 0)
+	return &search, nil
+}
+
+// requireAffected reports notFound when a write matched no row
+func requireAffected(result sql.Result, notFound error, column string, id int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s %d", notFound, column, id)
+	}
+	return nil
+}
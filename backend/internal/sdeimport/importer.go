@@ -0,0 +1,639 @@
+package sdeimport
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Options configures an SDE import
+type Options struct {
+	SourcePath   string // Official SDE zip archive in JSONL or YAML format
+	DatabasePath string // SQLite database in the Fuzzwork layout, created if missing
+	Force        bool   // Re-import even if the archive checksum is unchanged
+}
+
+// Report summarizes the changes of an import run
+type Report struct {
+	BuildNumber  int64          `json:"build_number"`
+	ReleaseDate  string         `json:"release_date,omitempty"`
+	Checksum     string         `json:"checksum"`
+	Skipped      bool           `json:"skipped"` // Archive was already imported
+	AddedTypes   []int32        `json:"added_types"`
+	RemovedTypes []int32        `json:"removed_types"`
+	ChangedTypes []int32        `json:"changed_types"`
+	Rows         map[string]int `json:"rows"` // Rows written per table
+}
+
+// typeRow is an invTypes row. It is comparable so unchanged types can be skipped.
+type typeRow struct {
+	TypeID        int32
+	GroupID       int32
+	TypeName      string
+	Description   string
+	Mass          float64
+	Volume        float64
+	Capacity      float64
+	PortionSize   int32
+	RaceID        sql.NullInt32
+	BasePrice     float64
+	Published     bool
+	MarketGroupID sql.NullInt32
+	IconID        sql.NullInt32
+	SoundID       sql.NullInt32
+	GraphicID     sql.NullInt32
+}
+
+// Import converts an official SDE archive into the SQLite schema used by the repository.
+// Types are diffed against the existing database, all other tables are replaced.
+func Import(opts Options) (*Report, error) {
+	src, err := openSource(opts.SourcePath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	info, err := src.buildInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		BuildNumber:  info.BuildNumber,
+		ReleaseDate:  info.ReleaseDate,
+		Checksum:     src.checksum,
+		AddedTypes:   []int32{},
+		RemovedTypes: []int32{},
+		ChangedTypes: []int32{},
+		Rows:         map[string]int{},
+	}
+
+	db, err := sql.Open("sqlite3", opts.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	for _, statement := range schema {
+		if _, err := db.Exec(statement); err != nil {
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	previousChecksum, err := readMetadata(db, MetadataChecksum)
+	if err != nil {
+		return nil, err
+	}
+	if previousChecksum == src.checksum && !opts.Force {
+		report.Skipped = true
+		return report, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback() // No-op after commit
+
+	if err := importTypes(tx, src, report); err != nil {
+		return nil, err
+	}
+	for _, importTable := range []func(*sql.Tx, *source, *Report) error{
+		importGroups,
+		importCategories,
+		importMarketGroups,
+		importMetaGroups,
+		importTypeMaterials,
+		importBlueprints,
+		importRegions,
+		importSolarSystems,
+		importStations,
+		importDogma,
+	} {
+		if err := importTable(tx, src, report); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata := map[string]string{
+		MetadataBuildNumber: strconv.FormatInt(info.BuildNumber, 10),
+		MetadataReleaseDate: info.ReleaseDate,
+		MetadataChecksum:    src.checksum,
+		MetadataImportedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+	for key, value := range metadata {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO `+MetadataTable+` (key, value) VALUES (?, ?)`, key, value); err != nil {
+			return nil, fmt.Errorf("failed to write metadata: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return report, nil
+}
+
+// readMetadata returns a metadata value or an empty string if it was never written
+func readMetadata(db *sql.DB, key string) (string, error) {
+	var value string
+	err := db.QueryRow(`SELECT value FROM `+MetadataTable+` WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read metadata: %w", err)
+	}
+	return value, nil
+}
+
+// importTypes applies the added, changed and removed types to invTypes and
+// rebuilds invMetaTypes from their variation data
+func importTypes(tx *sql.Tx, src *source, report *Report) error {
+	records, err := readDataset[typeRecord](src, "types")
+	if err != nil {
+		return err
+	}
+
+	existing, err := loadTypeRows(tx)
+	if err != nil {
+		return err
+	}
+
+	insert, err := tx.Prepare(`
+		INSERT OR REPLACE INTO invTypes (
+			typeID, groupID, typeName, description, mass, volume, capacity, portionSize,
+			raceID, basePrice, published, marketGroupID, iconID, soundID, graphicID
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare type insert: %w", err)
+	}
+	defer insert.Close()
+
+	var metaRows [][]interface{}
+	for _, record := range records {
+		if parentTypeID := record.Value.VariationParentTypeID; parentTypeID != nil {
+			metaRows = append(metaRows, []interface{}{record.Key, *parentTypeID, nullInt32(record.Value.MetaGroupID)})
+		}
+
+		row := newTypeRow(int32(record.Key), record.Value)
+		previous, exists := existing[row.TypeID]
+		delete(existing, row.TypeID)
+
+		switch {
+		case !exists:
+			report.AddedTypes = append(report.AddedTypes, row.TypeID)
+		case previous != row:
+			report.ChangedTypes = append(report.ChangedTypes, row.TypeID)
+		default:
+			continue
+		}
+
+		_, err := insert.Exec(
+			row.TypeID, row.GroupID, row.TypeName, row.Description, row.Mass, row.Volume, row.Capacity, row.PortionSize,
+			row.RaceID, row.BasePrice, row.Published, row.MarketGroupID, row.IconID, row.SoundID, row.GraphicID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write type %d: %w", row.TypeID, err)
+		}
+		report.Rows["invTypes"]++
+	}
+
+	// Whatever is left in existing is gone from the new SDE
+	for typeID := range existing {
+		if _, err := tx.Exec(`DELETE FROM invTypes WHERE typeID = ?`, typeID); err != nil {
+			return fmt.Errorf("failed to remove type %d: %w", typeID, err)
+		}
+		report.RemovedTypes = append(report.RemovedTypes, typeID)
+	}
+	sort.Slice(report.RemovedTypes, func(i, j int) bool {
+		return report.RemovedTypes[i] < report.RemovedTypes[j]
+	})
+
+	return replaceTable(tx, report, "invMetaTypes", `
+		INSERT INTO invMetaTypes (typeID, parentTypeID, metaGroupID) VALUES (?, ?, ?)
+	`, metaRows)
+}
+
+// loadTypeRows reads the currently imported types
+func loadTypeRows(tx *sql.Tx) (map[int32]typeRow, error) {
+	rows, err := tx.Query(`
+		SELECT typeID, COALESCE(groupID, 0), COALESCE(typeName, ''), COALESCE(description, ''),
+			COALESCE(mass, 0), COALESCE(volume, 0), COALESCE(capacity, 0), COALESCE(portionSize, 0),
+			raceID, COALESCE(basePrice, 0), COALESCE(published, 0), marketGroupID, iconID, soundID, graphicID
+		FROM invTypes
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing types: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[int32]typeRow)
+	for rows.Next() {
+		var row typeRow
+		err := rows.Scan(
+			&row.TypeID, &row.GroupID, &row.TypeName, &row.Description,
+			&row.Mass, &row.Volume, &row.Capacity, &row.PortionSize,
+			&row.RaceID, &row.BasePrice, &row.Published, &row.MarketGroupID, &row.IconID, &row.SoundID, &row.GraphicID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan existing type: %w", err)
+		}
+		existing[row.TypeID] = row
+	}
+
+	return existing, rows.Err()
+}
+
+func newTypeRow(typeID int32, record typeRecord) typeRow {
+	return typeRow{
+		TypeID:        typeID,
+		GroupID:       record.GroupID,
+		TypeName:      record.Name.english(),
+		Description:   record.Description.english(),
+		Mass:          record.Mass,
+		Volume:        record.Volume,
+		Capacity:      record.Capacity,
+		PortionSize:   record.PortionSize,
+		RaceID:        nullInt32(record.RaceID),
+		BasePrice:     record.BasePrice,
+		Published:     record.Published,
+		MarketGroupID: nullInt32(record.MarketGroupID),
+		IconID:        nullInt32(record.IconID),
+		SoundID:       nullInt32(record.SoundID),
+		GraphicID:     nullInt32(record.GraphicID),
+	}
+}
+
+func importGroups(tx *sql.Tx, src *source, report *Report) error {
+	records, err := readDataset[groupRecord](src, "groups")
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		group := record.Value
+		rows = append(rows, []interface{}{
+			record.Key, group.CategoryID, group.Name.english(), nullInt32(group.IconID),
+			group.UseBasePrice, group.Anchored, group.Anchorable, group.FittableNonSingleton, group.Published,
+		})
+	}
+
+	return replaceTable(tx, report, "invGroups", `
+		INSERT INTO invGroups (
+			groupID, categoryID, groupName, iconID, useBasePrice, anchored, anchorable, fittableNonSingleton, published
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rows)
+}
+
+func importCategories(tx *sql.Tx, src *source, report *Report) error {
+	records, err := readDataset[categoryRecord](src, "categories")
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		category := record.Value
+		rows = append(rows, []interface{}{record.Key, category.Name.english(), nullInt32(category.IconID), category.Published})
+	}
+
+	return replaceTable(tx, report, "invCategories", `
+		INSERT INTO invCategories (categoryID, categoryName, iconID, published) VALUES (?, ?, ?, ?)
+	`, rows)
+}
+
+func importMarketGroups(tx *sql.Tx, src *source, report *Report) error {
+	records, err := readDataset[marketGroupRecord](src, "marketGroups")
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		marketGroup := record.Value
+		rows = append(rows, []interface{}{
+			record.Key, nullInt32(marketGroup.ParentGroupID), marketGroup.Name.english(),
+			marketGroup.Description.english(), nullInt32(marketGroup.IconID), marketGroup.HasTypes,
+		})
+	}
+
+	return replaceTable(tx, report, "invMarketGroups", `
+		INSERT INTO invMarketGroups (marketGroupID, parentGroupID, marketGroupName, description, iconID, hasTypes)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, rows)
+}
+
+// importMetaGroups names the meta groups. Older archives without the dataset
+// leave invMetaGroups untouched.
+func importMetaGroups(tx *sql.Tx, src *source, report *Report) error {
+	records, err := readDataset[metaGroupRecord](src, "metaGroups")
+	if errors.Is(err, ErrDatasetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		metaGroup := record.Value
+		rows = append(rows, []interface{}{
+			record.Key, metaGroup.Name.english(), metaGroup.Description.english(), nullInt32(metaGroup.IconID),
+		})
+	}
+
+	return replaceTable(tx, report, "invMetaGroups", `
+		INSERT INTO invMetaGroups (metaGroupID, metaGroupName, description, iconID) VALUES (?, ?, ?, ?)
+	`, rows)
+}
+
+func importTypeMaterials(tx *sql.Tx, src *source, report *Report) error {
+	records, err := readDataset[typeMaterialsRecord](src, "typeMaterials")
+	if err != nil {
+		return err
+	}
+
+	var rows [][]interface{}
+	for _, record := range records {
+		for _, material := range record.Value.Materials {
+			rows = append(rows, []interface{}{record.Key, material.MaterialTypeID, material.Quantity})
+		}
+	}
+
+	return replaceTable(tx, report, "invTypeMaterials", `
+		INSERT INTO invTypeMaterials (typeID, materialTypeID, quantity) VALUES (?, ?, ?)
+	`, rows)
+}
+
+// importBlueprints splits blueprint activities into the four industryActivity tables
+func importBlueprints(tx *sql.Tx, src *source, report *Report) error {
+	records, err := readDataset[blueprintRecord](src, "blueprints")
+	if err != nil {
+		return err
+	}
+
+	var activities, materials, products, skills [][]interface{}
+	for _, record := range records {
+		for name, activity := range record.Value.Activities {
+			activityID, ok := activityIDs[name]
+			if !ok {
+				continue
+			}
+
+			activities = append(activities, []interface{}{record.Key, activityID, activity.Time})
+			for _, material := range activity.Materials {
+				materials = append(materials, []interface{}{record.Key, activityID, material.TypeID, material.Quantity})
+			}
+			for _, product := range activity.Products {
+				products = append(products, []interface{}{record.Key, activityID, product.TypeID, product.Quantity})
+			}
+			for _, skill := range activity.Skills {
+				skills = append(skills, []interface{}{record.Key, activityID, skill.TypeID, skill.Level})
+			}
+		}
+	}
+
+	tables := []struct {
+		name   string
+		insert string
+		rows   [][]interface{}
+	}{
+		{"industryActivity", `INSERT INTO industryActivity (typeID, activityID, time) VALUES (?, ?, ?)`, activities},
+		{"industryActivityMaterials", `INSERT INTO industryActivityMaterials (typeID, activityID, materialTypeID, quantity) VALUES (?, ?, ?, ?)`, materials},
+		{"industryActivityProducts", `INSERT INTO industryActivityProducts (typeID, activityID, productTypeID, quantity) VALUES (?, ?, ?, ?)`, products},
+		{"industryActivitySkills", `INSERT INTO industryActivitySkills (typeID, activityID, skillID, level) VALUES (?, ?, ?, ?)`, skills},
+	}
+	for _, table := range tables {
+		if err := replaceTable(tx, report, table.name, table.insert, table.rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importRegions(tx *sql.Tx, src *source, report *Report) error {
+	records, err := readDataset[regionRecord](src, "mapRegions")
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, []interface{}{record.Key, record.Value.Name.english()})
+	}
+
+	return replaceTable(tx, report, "mapRegions", `
+		INSERT INTO mapRegions (regionID, regionName) VALUES (?, ?)
+	`, rows)
+}
+
+// importSolarSystems fills mapSolarSystems and derives mapSolarSystemJumps from the
+// stargates. Archives without the stargate dataset leave the jump table untouched.
+func importSolarSystems(tx *sql.Tx, src *source, report *Report) error {
+	systemRecords, err := readDataset[solarSystemRecord](src, "mapSolarSystems")
+	if errors.Is(err, ErrDatasetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	systems := make(map[int64]solarSystemRecord, len(systemRecords))
+	systemRows := make([][]interface{}, 0, len(systemRecords))
+	for _, record := range systemRecords {
+		system := record.Value
+		systems[record.Key] = system
+		systemRows = append(systemRows, []interface{}{
+			system.RegionID, system.ConstellationID, record.Key, system.Name.english(), system.SecurityStatus,
+		})
+	}
+
+	err = replaceTable(tx, report, "mapSolarSystems", `
+		INSERT INTO mapSolarSystems (regionID, constellationID, solarSystemID, solarSystemName, security)
+		VALUES (?, ?, ?, ?, ?)
+	`, systemRows)
+	if err != nil {
+		return err
+	}
+
+	stargates, err := readDataset[stargateRecord](src, "mapStargates")
+	if errors.Is(err, ErrDatasetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Every connection has a stargate on both ends, so each direction is written once
+	type jump struct{ from, to int32 }
+	seen := make(map[jump]bool, len(stargates))
+	var jumpRows [][]interface{}
+	for _, record := range stargates {
+		key := jump{from: record.Value.SolarSystemID, to: record.Value.Destination.SolarSystemID}
+		if seen[key] || key.to == 0 {
+			continue
+		}
+		seen[key] = true
+
+		from, to := systems[int64(key.from)], systems[int64(key.to)]
+		jumpRows = append(jumpRows, []interface{}{
+			from.RegionID, from.ConstellationID, key.from, key.to, to.ConstellationID, to.RegionID,
+		})
+	}
+
+	return replaceTable(tx, report, "mapSolarSystemJumps", `
+		INSERT INTO mapSolarSystemJumps (
+			fromRegionID, fromConstellationID, fromSolarSystemID, toSolarSystemID, toConstellationID, toRegionID
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`, jumpRows)
+}
+
+// importStations locates NPC stations through their solar systems. The official SDE
+// does not ship station names, so staStations is left untouched if either dataset is
+// missing and stationName stays empty.
+func importStations(tx *sql.Tx, src *source, report *Report) error {
+	stations, err := readDataset[stationRecord](src, "npcStations")
+	if errors.Is(err, ErrDatasetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	systemRecords, err := readDataset[solarSystemRecord](src, "mapSolarSystems")
+	if errors.Is(err, ErrDatasetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	systems := make(map[int64]solarSystemRecord, len(systemRecords))
+	for _, record := range systemRecords {
+		systems[record.Key] = record.Value
+	}
+
+	rows := make([][]interface{}, 0, len(stations))
+	for _, record := range stations {
+		station := record.Value
+		system := systems[int64(station.SolarSystemID)]
+		rows = append(rows, []interface{}{
+			record.Key, system.SecurityStatus, station.TypeID, station.OwnerID,
+			station.SolarSystemID, system.ConstellationID, system.RegionID, "",
+		})
+	}
+
+	return replaceTable(tx, report, "staStations", `
+		INSERT INTO staStations (
+			stationID, security, stationTypeID, corporationID, solarSystemID, constellationID, regionID, stationName
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, rows)
+}
+
+// importDogma fills the attribute tables. Older archives without dogma datasets
+// leave them untouched. Units are optional as well and only provide display names.
+func importDogma(tx *sql.Tx, src *source, report *Report) error {
+	attributes, err := readDataset[dogmaAttributeRecord](src, "dogmaAttributes")
+	if errors.Is(err, ErrDatasetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	typeAttributes, err := readDataset[typeDogmaRecord](src, "typeDogma")
+	if errors.Is(err, ErrDatasetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	attributeRows := make([][]interface{}, 0, len(attributes))
+	for _, record := range attributes {
+		attribute := record.Value
+		attributeRows = append(attributeRows, []interface{}{
+			record.Key, attribute.Name, attribute.Description, nullInt32(attribute.IconID), attribute.DefaultValue,
+			attribute.Published, attribute.DisplayName.english(), nullInt32(attribute.UnitID), attribute.Stackable,
+			attribute.HighIsGood, nullInt32(attribute.CategoryID),
+		})
+	}
+
+	var valueRows [][]interface{}
+	for _, record := range typeAttributes {
+		for _, attribute := range record.Value.DogmaAttributes {
+			valueRows = append(valueRows, []interface{}{record.Key, attribute.AttributeID, attribute.Value})
+		}
+	}
+
+	err = replaceTable(tx, report, "dgmAttributeTypes", `
+		INSERT INTO dgmAttributeTypes (
+			attributeID, attributeName, description, iconID, defaultValue, published, displayName,
+			unitID, stackable, highIsGood, categoryID
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, attributeRows)
+	if err != nil {
+		return err
+	}
+
+	err = replaceTable(tx, report, "dgmTypeAttributes", `
+		INSERT INTO dgmTypeAttributes (typeID, attributeID, valueFloat) VALUES (?, ?, ?)
+	`, valueRows)
+	if err != nil {
+		return err
+	}
+
+	units, err := readDataset[dogmaUnitRecord](src, "dogmaUnits")
+	if errors.Is(err, ErrDatasetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	unitRows := make([][]interface{}, 0, len(units))
+	for _, record := range units {
+		unit := record.Value
+		unitRows = append(unitRows, []interface{}{record.Key, unit.Name, unit.DisplayName.english(), unit.Description.english()})
+	}
+
+	return replaceTable(tx, report, "eveUnits", `
+		INSERT INTO eveUnits (unitID, unitName, displayName, description) VALUES (?, ?, ?, ?)
+	`, unitRows)
+}
+
+// replaceTable deletes all rows of a table and inserts the new ones
+func replaceTable(tx *sql.Tx, report *Report, table, insert string, rows [][]interface{}) error {
+	if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", table, err)
+	}
+
+	stmt, err := tx.Prepare(insert)
+	if err != nil {
+		return fmt.Errorf("failed to prepare %s insert: %w", table, err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.Exec(row...); err != nil {
+			return fmt.Errorf("failed to write %s: %w", table, err)
+		}
+	}
+
+	report.Rows[table] = len(rows)
+	return nil
+}
+
+func nullInt32(value *int32) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *value, Valid: true}
+}
@@ -0,0 +1,143 @@
+package sdeimport
+
+// Dataset records of CCP's official SDE. Only the fields the repository
+// queries are decoded, everything else in the archive is ignored.
+
+type typeRecord struct {
+	GroupID       int32         `json:"groupID" yaml:"groupID"`
+	Name          localizedText `json:"name" yaml:"name"`
+	Description   localizedText `json:"description" yaml:"description"`
+	Mass          float64       `json:"mass" yaml:"mass"`
+	Volume        float64       `json:"volume" yaml:"volume"`
+	Capacity      float64       `json:"capacity" yaml:"capacity"`
+	PortionSize   int32         `json:"portionSize" yaml:"portionSize"`
+	RaceID        *int32        `json:"raceID" yaml:"raceID"`
+	BasePrice     float64       `json:"basePrice" yaml:"basePrice"`
+	Published     bool          `json:"published" yaml:"published"`
+	MarketGroupID *int32        `json:"marketGroupID" yaml:"marketGroupID"`
+	IconID        *int32        `json:"iconID" yaml:"iconID"`
+	SoundID       *int32        `json:"soundID" yaml:"soundID"`
+	GraphicID     *int32        `json:"graphicID" yaml:"graphicID"`
+
+	// Variation data, written to invMetaTypes
+	MetaGroupID           *int32 `json:"metaGroupID" yaml:"metaGroupID"`
+	VariationParentTypeID *int32 `json:"variationParentTypeID" yaml:"variationParentTypeID"`
+}
+
+type groupRecord struct {
+	CategoryID           int32         `json:"categoryID" yaml:"categoryID"`
+	Name                 localizedText `json:"name" yaml:"name"`
+	IconID               *int32        `json:"iconID" yaml:"iconID"`
+	UseBasePrice         bool          `json:"useBasePrice" yaml:"useBasePrice"`
+	Anchored             bool          `json:"anchored" yaml:"anchored"`
+	Anchorable           bool          `json:"anchorable" yaml:"anchorable"`
+	FittableNonSingleton bool          `json:"fittableNonSingleton" yaml:"fittableNonSingleton"`
+	Published            bool          `json:"published" yaml:"published"`
+}
+
+type categoryRecord struct {
+	Name      localizedText `json:"name" yaml:"name"`
+	IconID    *int32        `json:"iconID" yaml:"iconID"`
+	Published bool          `json:"published" yaml:"published"`
+}
+
+type metaGroupRecord struct {
+	Name        localizedText `json:"name" yaml:"name"`
+	Description localizedText `json:"description" yaml:"description"`
+	IconID      *int32        `json:"iconID" yaml:"iconID"`
+}
+
+type marketGroupRecord struct {
+	ParentGroupID *int32        `json:"parentGroupID" yaml:"parentGroupID"`
+	Name          localizedText `json:"name" yaml:"name"`
+	Description   localizedText `json:"description" yaml:"description"`
+	IconID        *int32        `json:"iconID" yaml:"iconID"`
+	HasTypes      bool          `json:"hasTypes" yaml:"hasTypes"`
+}
+
+type typeMaterialsRecord struct {
+	Materials []struct {
+		MaterialTypeID int32 `json:"materialTypeID" yaml:"materialTypeID"`
+		Quantity       int32 `json:"quantity" yaml:"quantity"`
+	} `json:"materials" yaml:"materials"`
+}
+
+type blueprintRecord struct {
+	Activities map[string]blueprintActivity `json:"activities" yaml:"activities"`
+}
+
+type blueprintActivity struct {
+	Time      int32 `json:"time" yaml:"time"`
+	Materials []struct {
+		TypeID   int32 `json:"typeID" yaml:"typeID"`
+		Quantity int32 `json:"quantity" yaml:"quantity"`
+	} `json:"materials" yaml:"materials"`
+	Products []struct {
+		TypeID   int32 `json:"typeID" yaml:"typeID"`
+		Quantity int32 `json:"quantity" yaml:"quantity"`
+	} `json:"products" yaml:"products"`
+	Skills []struct {
+		TypeID int32 `json:"typeID" yaml:"typeID"`
+		Level  int32 `json:"level" yaml:"level"`
+	} `json:"skills" yaml:"skills"`
+}
+
+type regionRecord struct {
+	Name localizedText `json:"name" yaml:"name"`
+}
+
+type solarSystemRecord struct {
+	Name            localizedText `json:"name" yaml:"name"`
+	RegionID        int32         `json:"regionID" yaml:"regionID"`
+	ConstellationID int32         `json:"constellationID" yaml:"constellationID"`
+	SecurityStatus  float64       `json:"securityStatus" yaml:"securityStatus"`
+}
+
+type stargateRecord struct {
+	SolarSystemID int32 `json:"solarSystemID" yaml:"solarSystemID"`
+	Destination   struct {
+		SolarSystemID int32 `json:"solarSystemID" yaml:"solarSystemID"`
+	} `json:"destination" yaml:"destination"`
+}
+
+type stationRecord struct {
+	SolarSystemID int32 `json:"solarSystemID" yaml:"solarSystemID"`
+	TypeID        int32 `json:"typeID" yaml:"typeID"`
+	OwnerID       int32 `json:"ownerID" yaml:"ownerID"`
+}
+
+type dogmaAttributeRecord struct {
+	Name         string        `json:"name" yaml:"name"`
+	Description  string        `json:"description" yaml:"description"`
+	DisplayName  localizedText `json:"displayName" yaml:"displayName"`
+	IconID       *int32        `json:"iconID" yaml:"iconID"`
+	DefaultValue float64       `json:"defaultValue" yaml:"defaultValue"`
+	Published    bool          `json:"published" yaml:"published"`
+	UnitID       *int32        `json:"unitID" yaml:"unitID"`
+	Stackable    bool          `json:"stackable" yaml:"stackable"`
+	HighIsGood   bool          `json:"highIsGood" yaml:"highIsGood"`
+	CategoryID   *int32        `json:"attributeCategoryID" yaml:"attributeCategoryID"`
+}
+
+type dogmaUnitRecord struct {
+	Name        string        `json:"name" yaml:"name"`
+	DisplayName localizedText `json:"displayName" yaml:"displayName"`
+	Description localizedText `json:"description" yaml:"description"`
+}
+
+type typeDogmaRecord struct {
+	DogmaAttributes []struct {
+		AttributeID int32   `json:"attributeID" yaml:"attributeID"`
+		Value       float64 `json:"value" yaml:"value"`
+	} `json:"dogmaAttributes" yaml:"dogmaAttributes"`
+}
+
+// activityIDs maps the activity names of the blueprints dataset to industryActivity IDs
+var activityIDs = map[string]int32{
+	"manufacturing":     1,
+	"research_time":     3,
+	"research_material": 4,
+	"copying":           5,
+	"invention":         8,
+	"reaction":          11,
+}
@@ -0,0 +1,166 @@
+package sdeimport
+
+import "eve-profit2/internal/repository"
+
+// MetadataTable stores the build number and checksum of the imported SDE
+const MetadataTable = repository.SDEMetadataTable
+
+// Metadata keys written on every import, read back by the repository
+const (
+	MetadataBuildNumber = repository.SDEMetadataBuildNumber
+	MetadataReleaseDate = repository.SDEMetadataReleaseDate
+	MetadataChecksum    = repository.SDEMetadataChecksum
+	MetadataImportedAt  = repository.SDEMetadataImportedAt
+)
+
+// schema mirrors the Fuzzwork table layout the SDE repository queries
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS invTypes (
+		typeID INTEGER PRIMARY KEY,
+		groupID INTEGER,
+		typeName TEXT,
+		description TEXT,
+		mass REAL,
+		volume REAL,
+		capacity REAL,
+		portionSize INTEGER,
+		raceID INTEGER,
+		basePrice REAL,
+		published INTEGER,
+		marketGroupID INTEGER,
+		iconID INTEGER,
+		soundID INTEGER,
+		graphicID INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS ix_invTypes_groupID ON invTypes (groupID)`,
+	`CREATE INDEX IF NOT EXISTS ix_invTypes_marketGroupID ON invTypes (marketGroupID)`,
+	`CREATE TABLE IF NOT EXISTS invGroups (
+		groupID INTEGER PRIMARY KEY,
+		categoryID INTEGER,
+		groupName TEXT,
+		iconID INTEGER,
+		useBasePrice INTEGER,
+		anchored INTEGER,
+		anchorable INTEGER,
+		fittableNonSingleton INTEGER,
+		published INTEGER
+	)`,
+	`CREATE TABLE IF NOT EXISTS invCategories (
+		categoryID INTEGER PRIMARY KEY,
+		categoryName TEXT,
+		iconID INTEGER,
+		published INTEGER
+	)`,
+	`CREATE TABLE IF NOT EXISTS invMarketGroups (
+		marketGroupID INTEGER PRIMARY KEY,
+		parentGroupID INTEGER,
+		marketGroupName TEXT,
+		description TEXT,
+		iconID INTEGER,
+		hasTypes INTEGER
+	)`,
+	`CREATE TABLE IF NOT EXISTS invMetaTypes (
+		typeID INTEGER PRIMARY KEY,
+		parentTypeID INTEGER,
+		metaGroupID INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS ix_invMetaTypes_parentTypeID ON invMetaTypes (parentTypeID)`,
+	`CREATE TABLE IF NOT EXISTS invMetaGroups (
+		metaGroupID INTEGER PRIMARY KEY,
+		metaGroupName TEXT,
+		description TEXT,
+		iconID INTEGER
+	)`,
+	`CREATE TABLE IF NOT EXISTS invTypeMaterials (
+		typeID INTEGER,
+		materialTypeID INTEGER,
+		quantity INTEGER,
+		PRIMARY KEY (typeID, materialTypeID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS dgmAttributeTypes (
+		attributeID INTEGER PRIMARY KEY,
+		attributeName TEXT,
+		description TEXT,
+		iconID INTEGER,
+		defaultValue REAL,
+		published INTEGER,
+		displayName TEXT,
+		unitID INTEGER,
+		stackable INTEGER,
+		highIsGood INTEGER,
+		categoryID INTEGER
+	)`,
+	`CREATE TABLE IF NOT EXISTS dgmTypeAttributes (
+		typeID INTEGER,
+		attributeID INTEGER,
+		valueInt INTEGER,
+		valueFloat REAL,
+		PRIMARY KEY (typeID, attributeID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS eveUnits (
+		unitID INTEGER PRIMARY KEY,
+		unitName TEXT,
+		displayName TEXT,
+		description TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS industryActivity (
+		typeID INTEGER,
+		activityID INTEGER,
+		time INTEGER,
+		PRIMARY KEY (typeID, activityID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS industryActivityMaterials (
+		typeID INTEGER,
+		activityID INTEGER,
+		materialTypeID INTEGER,
+		quantity INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS ix_industryActivityMaterials_typeID ON industryActivityMaterials (typeID, activityID)`,
+	`CREATE TABLE IF NOT EXISTS industryActivityProducts (
+		typeID INTEGER,
+		activityID INTEGER,
+		productTypeID INTEGER,
+		quantity INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS ix_industryActivityProducts_productTypeID ON industryActivityProducts (productTypeID, activityID)`,
+	`CREATE TABLE IF NOT EXISTS industryActivitySkills (
+		typeID INTEGER,
+		activityID INTEGER,
+		skillID INTEGER,
+		level INTEGER
+	)`,
+	`CREATE TABLE IF NOT EXISTS mapRegions (
+		regionID INTEGER PRIMARY KEY,
+		regionName TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS mapSolarSystems (
+		regionID INTEGER,
+		constellationID INTEGER,
+		solarSystemID INTEGER PRIMARY KEY,
+		solarSystemName TEXT,
+		security REAL
+	)`,
+	`CREATE TABLE IF NOT EXISTS mapSolarSystemJumps (
+		fromRegionID INTEGER,
+		fromConstellationID INTEGER,
+		fromSolarSystemID INTEGER,
+		toSolarSystemID INTEGER,
+		toConstellationID INTEGER,
+		toRegionID INTEGER,
+		PRIMARY KEY (fromSolarSystemID, toSolarSystemID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS staStations (
+		stationID INTEGER PRIMARY KEY,
+		security REAL,
+		stationTypeID INTEGER,
+		corporationID INTEGER,
+		solarSystemID INTEGER,
+		constellationID INTEGER,
+		regionID INTEGER,
+		stationName TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + MetadataTable + ` (
+		key TEXT PRIMARY KEY,
+		value TEXT
+	)`,
+}
@@ -0,0 +1,243 @@
+package sdeimport
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrDatasetNotFound is returned when a required dataset is missing from the archive
+var ErrDatasetNotFound = errors.New("dataset not found in SDE archive")
+
+// Dataset file extensions supported by CCP's official SDE archives
+const (
+	formatJSONL = ".jsonl"
+	formatYAML  = ".yaml"
+)
+
+// buildNumberPattern extracts the build number from archive names like
+// eve-online-static-data-3064089-jsonl.zip
+var buildNumberPattern = regexp.MustCompile(`static-data-(\d+)`)
+
+// localizedText holds a translated SDE string keyed by language
+type localizedText map[string]string
+
+// english returns the English text, which the repository queries use
+func (t localizedText) english() string {
+	return t["en"]
+}
+
+// UnmarshalJSON accepts plain strings as well, some datasets are not translated
+func (t *localizedText) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*t = localizedText{"en": text}
+		return nil
+	}
+
+	var translations map[string]string
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return err
+	}
+	*t = translations
+	return nil
+}
+
+// UnmarshalYAML accepts plain strings as well, some datasets are not translated
+func (t *localizedText) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = localizedText{"en": node.Value}
+		return nil
+	}
+
+	var translations map[string]string
+	if err := node.Decode(&translations); err != nil {
+		return err
+	}
+	*t = translations
+	return nil
+}
+
+// keyed is a dataset record together with its SDE key
+type keyed[T any] struct {
+	Key   int64
+	Value T
+}
+
+// BuildInfo describes the SDE release contained in an archive
+type BuildInfo struct {
+	BuildNumber int64  `json:"buildNumber" yaml:"buildNumber"`
+	ReleaseDate string `json:"releaseDate" yaml:"releaseDate"`
+}
+
+// source is an opened SDE archive
+type source struct {
+	path     string
+	checksum string
+	archive  *zip.ReadCloser
+	files    map[string]*zip.File // Dataset file name without directory -> entry
+}
+
+func openSource(archivePath string) (*source, error) {
+	checksum, err := fileChecksum(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SDE archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		if !file.FileInfo().IsDir() {
+			files[path.Base(file.Name)] = file
+		}
+	}
+
+	return &source{path: archivePath, checksum: checksum, archive: archive, files: files}, nil
+}
+
+func (s *source) Close() error {
+	return s.archive.Close()
+}
+
+// findDataset returns the JSONL or YAML file of a dataset
+func (s *source) findDataset(name string) (*zip.File, string, bool) {
+	for _, format := range []string{formatJSONL, formatYAML} {
+		if file, ok := s.files[name+format]; ok {
+			return file, format, true
+		}
+	}
+	return nil, "", false
+}
+
+// buildInfo reads the _sde dataset, falling back to the build number in the archive name
+func (s *source) buildInfo() (BuildInfo, error) {
+	var info BuildInfo
+
+	file, format, ok := s.findDataset("_sde")
+	if ok {
+		reader, err := file.Open()
+		if err != nil {
+			return info, fmt.Errorf("failed to open %s: %w", file.Name, err)
+		}
+		defer reader.Close()
+
+		if format == formatJSONL {
+			err = json.NewDecoder(reader).Decode(&info)
+		} else {
+			err = yaml.NewDecoder(reader).Decode(&info)
+		}
+		if err != nil {
+			return info, fmt.Errorf("failed to decode %s: %w", file.Name, err)
+		}
+	}
+
+	if info.BuildNumber == 0 {
+		if match := buildNumberPattern.FindStringSubmatch(path.Base(s.path)); match != nil {
+			info.BuildNumber, _ = strconv.ParseInt(match[1], 10, 64)
+		}
+	}
+	return info, nil
+}
+
+// readDataset decodes all records of a dataset sorted by key.
+// JSONL files carry the key in a _key field, YAML files are maps keyed by ID.
+func readDataset[T any](s *source, name string) ([]keyed[T], error) {
+	file, format, ok := s.findDataset(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDatasetNotFound, name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	var records []keyed[T]
+	if format == formatJSONL {
+		records, err = decodeJSONL[T](reader)
+	} else {
+		records, err = decodeYAML[T](reader)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", file.Name, err)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key < records[j].Key
+	})
+	return records, nil
+}
+
+func decodeJSONL[T any](reader io.Reader) ([]keyed[T], error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // Some records carry long descriptions
+
+	var records []keyed[T]
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+
+		var key struct {
+			Key int64 `json:"_key"`
+		}
+		if err := json.Unmarshal(data, &key); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		var value T
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, keyed[T]{Key: key.Key, Value: value})
+	}
+
+	return records, scanner.Err()
+}
+
+func decodeYAML[T any](reader io.Reader) ([]keyed[T], error) {
+	var values map[int64]T
+	if err := yaml.NewDecoder(reader).Decode(&values); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	records := make([]keyed[T], 0, len(values))
+	for key, value := range values {
+		records = append(records, keyed[T]{Key: key, Value: value})
+	}
+	return records, nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 of a file
+func fileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open SDE archive: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to checksum SDE archive: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
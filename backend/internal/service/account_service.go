@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// Account errors
+var (
+	ErrAccountNotFound          = repository.ErrAccountNotFound
+	ErrCharacterNotLinked       = errors.New("character is not linked to the account")
+	ErrCharacterDataUnavailable = errors.New("character data is not available")
+)
+
+// CharacterError reports a linked character whose data could not be loaded,
+// e.g. because its token was revoked
+type CharacterError struct {
+	CharacterID   int32  `json:"character_id"`
+	CharacterName string `json:"character_name"`
+	Error         string `json:"error"`
+}
+
+// CharacterBalance is the wallet balance of one linked character
+type CharacterBalance struct {
+	CharacterID   int32   `json:"character_id"`
+	CharacterName string  `json:"character_name"`
+	Balance       float64 `json:"balance"`
+}
+
+// AccountWallet sums the wallets of all linked characters
+type AccountWallet struct {
+	AccountID    int64              `json:"account_id"`
+	TotalBalance float64            `json:"total_balance"`
+	Characters   []CharacterBalance `json:"characters"`
+	Errors       []CharacterError   `json:"errors,omitempty"`
+}
+
+// CharacterQuantity is the quantity of a type owned by one character
+type CharacterQuantity struct {
+	CharacterID int32 `json:"character_id"`
+	Quantity    int64 `json:"quantity"`
+}
+
+// AccountAssetType sums one type over all linked characters
+type AccountAssetType struct {
+	TypeID     int32               `json:"type_id"`
+	Quantity   int64               `json:"quantity"`
+	Characters []CharacterQuantity `json:"characters"`
+}
+
+// AccountAssets sums the assets of all linked characters per type
+type AccountAssets struct {
+	AccountID int64              `json:"account_id"`
+	Types     []AccountAssetType `json:"types"`
+	Errors    []CharacterError   `json:"errors,omitempty"`
+}
+
+// AccountOrder is a market order of a linked character
+type AccountOrder struct {
+	models.CharacterOrder
+	CharacterID   int32  `json:"character_id"`
+	CharacterName string `json:"character_name"`
+}
+
+// AccountOrders lists the open orders of all linked characters
+type AccountOrders struct {
+	AccountID  int64            `json:"account_id"`
+	Orders     []AccountOrder   `json:"orders"`
+	BuyOrders  int              `json:"buy_orders"`
+	SellOrders int              `json:"sell_orders"`
+	Escrow     float64          `json:"escrow"`     // ISK held in buy orders
+	SellValue  float64          `json:"sell_value"` // Remaining volume times price of sell orders
+	Errors     []CharacterError `json:"errors,omitempty"`
+}
+
+// AccountService links several characters to one account and aggregates their data
+type AccountService struct {
+	accounts   AccountRepository
+	characters CharacterDataProvider
+}
+
+func NewAccountService(accounts AccountRepository) *AccountService {
+	return &AccountService{accounts: accounts}
+}
+
+// WithCharacterData enables the aggregated wallet, asset and order views
+func (s *AccountService) WithCharacterData(characters CharacterDataProvider) *AccountService {
+	s.characters = characters
+	return s
+}
+
+// LinkLogin records a successful SSO login. With a linking character the new
+// character joins that character's account, otherwise it keeps its account or
+// gets a new one.
+func (s *AccountService) LinkLogin(token *models.AuthToken, linkCharacterID int32) (*models.Account, error) {
+	if linkCharacterID != 0 && linkCharacterID != token.CharacterID {
+		account, err := s.accounts.GetAccountByCharacter(linkCharacterID)
+		if err != nil {
+			if errors.Is(err, ErrAccountNotFound) {
+				account, err = s.accounts.CreateAccount(linkCharacterID, "")
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := s.accounts.LinkCharacter(account.AccountID, token.CharacterID, token.CharacterName); err != nil {
+			return nil, err
+		}
+		return s.accounts.GetAccountByCharacter(token.CharacterID)
+	}
+
+	account, err := s.accounts.GetAccountByCharacter(token.CharacterID)
+	if errors.Is(err, ErrAccountNotFound) {
+		return s.accounts.CreateAccount(token.CharacterID, token.CharacterName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the name current, e.g. after a character rename
+	if err := s.accounts.LinkCharacter(account.AccountID, token.CharacterID, token.CharacterName); err != nil {
+		return nil, err
+	}
+	return s.accounts.GetAccountByCharacter(token.CharacterID)
+}
+
+// GetAccount returns the account of the authenticated character
+func (s *AccountService) GetAccount(characterID int32) (*models.Account, error) {
+	return s.accounts.GetAccountByCharacter(characterID)
+}
+
+// CanAccessCharacter reports whether the authenticated character may read the
+// data of another character, i.e. whether both are linked to the same account
+func (s *AccountService) CanAccessCharacter(authCharacterID, characterID int32) (bool, error) {
+	if authCharacterID == characterID {
+		return true, nil
+	}
+
+	account, err := s.accounts.GetAccountByCharacter(authCharacterID)
+	if errors.Is(err, ErrAccountNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return findLinkedCharacter(account, characterID) != nil, nil
+}
+
+// SetActiveCharacter switches the active character of the authenticated character's account
+func (s *AccountService) SetActiveCharacter(authCharacterID, characterID int32) (*models.Account, error) {
+	account, err := s.linkedAccount(authCharacterID, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.SetActiveCharacter(account.AccountID, characterID); err != nil {
+		return nil, err
+	}
+	return s.accounts.GetAccountByCharacter(authCharacterID)
+}
+
+// UnlinkCharacter removes another character from the authenticated character's account
+func (s *AccountService) UnlinkCharacter(authCharacterID, characterID int32) (*models.Account, error) {
+	if authCharacterID == characterID {
+		return nil, fmt.Errorf("%w: cannot unlink the character you are logged in with", ErrInvalidInput)
+	}
+
+	account, err := s.linkedAccount(authCharacterID, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.UnlinkCharacter(account.AccountID, characterID); err != nil {
+		return nil, err
+	}
+	return s.accounts.GetAccountByCharacter(authCharacterID)
+}
+
+// GetAccountWallet sums the wallet balances of all linked characters
+func (s *AccountService) GetAccountWallet(ctx context.Context, authCharacterID int32) (*AccountWallet, error) {
+	account, err := s.aggregationAccount(authCharacterID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]*models.CharacterWallet, len(account.Characters))
+	errs := s.forEachCharacter(account, func(i int, characterID int32) error {
+		wallet, err := s.characters.GetCharacterWallet(ctx, characterID)
+		wallets[i] = wallet
+		return err
+	})
+
+	result := &AccountWallet{AccountID: account.AccountID, Errors: errs}
+	for i, character := range account.Characters {
+		if wallets[i] == nil {
+			continue
+		}
+		result.TotalBalance += wallets[i].Balance
+		result.Characters = append(result.Characters, CharacterBalance{
+			CharacterID:   character.CharacterID,
+			CharacterName: character.CharacterName,
+			Balance:       wallets[i].Balance,
+		})
+	}
+	return result, nil
+}
+
+// GetAccountAssets sums the assets of all linked characters per type
+func (s *AccountService) GetAccountAssets(ctx context.Context, authCharacterID int32) (*AccountAssets, error) {
+	account, err := s.aggregationAccount(authCharacterID)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := make([][]models.CharacterAsset, len(account.Characters))
+	errs := s.forEachCharacter(account, func(i int, characterID int32) error {
+		characterAssets, err := s.characters.GetCharacterAssets(ctx, characterID)
+		assets[i] = characterAssets
+		return err
+	})
+
+	byType := make(map[int32]*AccountAssetType)
+	for i, character := range account.Characters {
+		perType := make(map[int32]int64)
+		for _, asset := range assets[i] {
+			perType[asset.TypeID] += int64(asset.Quantity)
+		}
+		for typeID, quantity := range perType {
+			total, ok := byType[typeID]
+			if !ok {
+				total = &AccountAssetType{TypeID: typeID}
+				byType[typeID] = total
+			}
+			total.Quantity += quantity
+			total.Characters = append(total.Characters, CharacterQuantity{CharacterID: character.CharacterID, Quantity: quantity})
+		}
+	}
+
+	result := &AccountAssets{AccountID: account.AccountID, Types: make([]AccountAssetType, 0, len(byType)), Errors: errs}
+	for _, total := range byType {
+		result.Types = append(result.Types, *total)
+	}
+	sort.Slice(result.Types, func(i, j int) bool { return result.Types[i].TypeID < result.Types[j].TypeID })
+	return result, nil
+}
+
+// GetAccountOrders lists the open orders of all linked characters
+func (s *AccountService) GetAccountOrders(ctx context.Context, authCharacterID int32) (*AccountOrders, error) {
+	account, err := s.aggregationAccount(authCharacterID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([][]models.CharacterOrder, len(account.Characters))
+	errs := s.forEachCharacter(account, func(i int, characterID int32) error {
+		characterOrders, err := s.characters.GetCharacterOrders(ctx, characterID)
+		orders[i] = characterOrders
+		return err
+	})
+
+	result := &AccountOrders{AccountID: account.AccountID, Orders: []AccountOrder{}, Errors: errs}
+	for i, character := range account.Characters {
+		for _, order := range orders[i] {
+			result.Orders = append(result.Orders, AccountOrder{
+				CharacterOrder: order,
+				CharacterID:    character.CharacterID,
+				CharacterName:  character.CharacterName,
+			})
+			if order.IsBuyOrder {
+				result.BuyOrders++
+				result.Escrow += order.Escrow
+			} else {
+				result.SellOrders++
+				result.SellValue += order.Price * float64(order.VolumeRemain)
+			}
+		}
+	}
+	sort.SliceStable(result.Orders, func(i, j int) bool {
+		if result.Orders[i].TypeID != result.Orders[j].TypeID {
+			return result.Orders[i].TypeID < result.Orders[j].TypeID
+		}
+		return result.Orders[i].OrderID < result.Orders[j].OrderID
+	})
+	return result, nil
+}
+
+// linkedAccount returns the account of the authenticated character if the other character is linked to it
+func (s *AccountService) linkedAccount(authCharacterID, characterID int32) (*models.Account, error) {
+	account, err := s.accounts.GetAccountByCharacter(authCharacterID)
+	if err != nil {
+		return nil, err
+	}
+	if findLinkedCharacter(account, characterID) == nil {
+		return nil, fmt.Errorf("%w: character %d", ErrCharacterNotLinked, characterID)
+	}
+	return account, nil
+}
+
+func (s *AccountService) aggregationAccount(authCharacterID int32) (*models.Account, error) {
+	if s.characters == nil {
+		return nil, ErrCharacterDataUnavailable
+	}
+	return s.accounts.GetAccountByCharacter(authCharacterID)
+}
+
+// forEachCharacter loads data of all linked characters in parallel. Failures are
+// collected instead of failing the whole account view.
+func (s *AccountService) forEachCharacter(account *models.Account, load func(i int, characterID int32) error) []CharacterError {
+	errs := make([]error, len(account.Characters))
+
+	var wg sync.WaitGroup
+	for i, character := range account.Characters {
+		wg.Add(1)
+		go func(i int, characterID int32) {
+			defer wg.Done()
+			errs[i] = load(i, characterID)
+		}(i, character.CharacterID)
+	}
+	wg.Wait()
+
+	var characterErrors []CharacterError
+	for i, err := range errs {
+		if err != nil {
+			characterErrors = append(characterErrors, CharacterError{
+				CharacterID:   account.Characters[i].CharacterID,
+				CharacterName: account.Characters[i].CharacterName,
+				Error:         err.Error(),
+			})
+		}
+	}
+	return characterErrors
+}
+
+func findLinkedCharacter(account *models.Account, characterID int32) *models.LinkedCharacter {
+	for i := range account.Characters {
+		if account.Characters[i].CharacterID == characterID {
+			return &account.Characters[i]
+		}
+	}
+	return nil
+}
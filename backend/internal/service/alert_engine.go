@@ -0,0 +1,656 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// Alert rule defaults and limits
+const (
+	DefaultAlertCooldown      = time.Hour
+	MaxAlertRulesPerCharacter = 100
+	maxAlertRuleNameLength    = 100
+)
+
+// ErrAlertRuleNotFound is returned for rules that do not exist or belong to another character
+var ErrAlertRuleNotFound = repository.ErrAlertRuleNotFound
+
+// AlertRunResult summarizes one evaluation of all enabled alert rules
+type AlertRunResult struct {
+	Evaluated   int              `json:"evaluated"`
+	Triggered   int              `json:"triggered"`
+	Errors      []AlertRuleError `json:"errors,omitempty"`
+	EvaluatedAt time.Time        `json:"evaluated_at"`
+}
+
+// AlertRuleError reports a rule that could not be evaluated or delivered
+type AlertRuleError struct {
+	RuleID int64  `json:"rule_id"`
+	Error  string `json:"error"`
+}
+
+// AlertEngine evaluates the alert rules of all characters against fresh market
+// data and delivers triggered rules to the configured notification channels.
+// A rule notifies once when its condition becomes true and then stays quiet
+// until the condition clears, undercut rules also when other competitors
+// appear. The cooldown bounds how often a flapping rule notifies.
+type AlertEngine struct {
+	store     AlertRepository
+	market    MarketDataProvider
+	undercuts UndercutChecker
+	items     ItemLookup
+	sinks     map[string]AlertSink
+	cooldown  time.Duration
+
+	evaluateMu sync.Mutex
+}
+
+func NewAlertEngine(store AlertRepository, market MarketDataProvider) *AlertEngine {
+	return &AlertEngine{
+		store:    store,
+		market:   market,
+		sinks:    make(map[string]AlertSink),
+		cooldown: DefaultAlertCooldown,
+	}
+}
+
+// WithUndercuts enables "my order undercut" rules
+func (e *AlertEngine) WithUndercuts(undercuts UndercutChecker) *AlertEngine {
+	e.undercuts = undercuts
+	return e
+}
+
+// WithItems adds type names to notifications
+func (e *AlertEngine) WithItems(items ItemLookup) *AlertEngine {
+	e.items = items
+	return e
+}
+
+// WithSink delivers triggered rules to a notification channel, e.g. "webhook"
+func (e *AlertEngine) WithSink(channel string, sink AlertSink) *AlertEngine {
+	e.sinks[channel] = sink
+	return e
+}
+
+// WithCooldown sets the minimum time between two notifications of rules without
+// their own cooldown. Zero or negative durations keep the default.
+func (e *AlertEngine) WithCooldown(cooldown time.Duration) *AlertEngine {
+	if cooldown > 0 {
+		e.cooldown = cooldown
+	}
+	return e
+}
+
+// Channels returns the configured notification channels
+func (e *AlertEngine) Channels() []string {
+	channels := make([]string, 0, len(e.sinks))
+	for channel := range e.sinks {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// ListRules returns the alert rules of a character
+func (e *AlertEngine) ListRules(characterID int32) ([]models.AlertRule, error) {
+	rules, err := e.store.ListAlertRules(characterID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i].Condition, _ = ParseAlertExpression(rules[i].Expression)
+	}
+	return rules, nil
+}
+
+// GetRule returns one alert rule of a character
+func (e *AlertEngine) GetRule(characterID int32, ruleID int64) (*models.AlertRule, error) {
+	rule, err := e.store.GetAlertRule(characterID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	rule.Condition, _ = ParseAlertExpression(rule.Expression)
+	return rule, nil
+}
+
+// CreateRule validates and stores a new alert rule of a character
+func (e *AlertEngine) CreateRule(characterID int32, rule models.AlertRule) (*models.AlertRule, error) {
+	condition, err := e.validateRule(&rule)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := e.store.ListAlertRules(characterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxAlertRulesPerCharacter {
+		return nil, fmt.Errorf("%w: at most %d alert rules per character", ErrInvalidInput, MaxAlertRulesPerCharacter)
+	}
+
+	rule.CharacterID = characterID
+	created, err := e.store.CreateAlertRule(&rule)
+	if err != nil {
+		return nil, err
+	}
+	created.Condition = condition
+	return created, nil
+}
+
+// UpdateRule replaces an alert rule of a character. The rule may notify again
+// right away if its condition still holds.
+func (e *AlertEngine) UpdateRule(characterID int32, ruleID int64, rule models.AlertRule) (*models.AlertRule, error) {
+	condition, err := e.validateRule(&rule)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.RuleID, rule.CharacterID = ruleID, characterID
+	updated, err := e.store.UpdateAlertRule(&rule)
+	if err != nil {
+		return nil, err
+	}
+	updated.Condition = condition
+	return updated, nil
+}
+
+// DeleteRule removes an alert rule of a character
+func (e *AlertEngine) DeleteRule(characterID int32, ruleID int64) error {
+	return e.store.DeleteAlertRule(characterID, ruleID)
+}
+
+// validateRule checks a rule and normalizes its name, channels and email
+func (e *AlertEngine) validateRule(rule *models.AlertRule) (*models.AlertCondition, error) {
+	condition, err := ParseAlertExpression(rule.Expression)
+	if err != nil {
+		return nil, err
+	}
+	rule.Expression = strings.TrimSpace(rule.Expression)
+
+	if rule.TypeID < 0 || (rule.TypeID == 0 && condition.Metric != models.AlertMetricUndercut) {
+		return nil, fmt.Errorf("%w: type_id is required for %s alerts", ErrInvalidInput, condition.Metric)
+	}
+	if err := validateMarketType(e.items, rule.TypeID); err != nil {
+		return nil, err
+	}
+	if rule.CooldownSeconds < 0 {
+		return nil, fmt.Errorf("%w: cooldown_seconds must not be negative", ErrInvalidInput)
+	}
+
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		rule.Name = rule.Expression
+	}
+	if len(rule.Name) > maxAlertRuleNameLength {
+		return nil, fmt.Errorf("%w: name must not exceed %d characters", ErrInvalidInput, maxAlertRuleNameLength)
+	}
+
+	channels := make([]string, 0, len(rule.Channels))
+	seen := make(map[string]bool, len(rule.Channels))
+	for _, channel := range rule.Channels {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if _, ok := e.sinks[channel]; !ok {
+			return nil, fmt.Errorf("%w: notification channel %q is not configured, available: %s",
+				ErrInvalidInput, channel, strings.Join(e.Channels(), ", "))
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	rule.Channels = channels
+
+	if rule.Email != "" {
+		address, err := mail.ParseAddress(rule.Email)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid email address %q", ErrInvalidInput, rule.Email)
+		}
+		rule.Email = address.Address
+	}
+	return condition, nil
+}
+
+// validateMarketType checks that a type exists and is traded on the market, so it
+// cannot make the market data requests of a whole region fail. Type 0 and a
+// missing item lookup skip the check.
+func validateMarketType(items ItemLookup, typeID int32) error {
+	if typeID == 0 || items == nil {
+		return nil
+	}
+	item, err := items.GetItemByID(typeID)
+	if errors.Is(err, ErrItemNotFound) {
+		return fmt.Errorf("%w: unknown type_id %d", ErrInvalidInput, typeID)
+	}
+	if err != nil {
+		return err
+	}
+	if item.MarketGroup == 0 {
+		return fmt.Errorf("%w: type %d is not traded on the market", ErrInvalidInput, typeID)
+	}
+	return nil
+}
+
+// Start evaluates all enabled alert rules in the background
+func (e *AlertEngine) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			result, err := e.Evaluate(ctx, time.Now())
+			if err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Printf("Warning: alert evaluation failed: %v\n", err)
+			}
+			if result != nil {
+				for _, ruleErr := range result.Errors {
+					fmt.Printf("Warning: alert rule %d: %s\n", ruleErr.RuleID, ruleErr.Error)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// alertCheck is one enabled rule during an evaluation
+type alertCheck struct {
+	rule      models.AlertRule
+	condition *models.AlertCondition
+}
+
+// Evaluate checks all enabled rules once, notifies triggered rules and stores
+// their trigger state. Market data is loaded once per region and undercuts once
+// for all characters with undercut rules.
+func (e *AlertEngine) Evaluate(ctx context.Context, now time.Time) (*AlertRunResult, error) {
+	e.evaluateMu.Lock()
+	defer e.evaluateMu.Unlock()
+
+	rules, err := e.store.ListEnabledAlertRules()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AlertRunResult{EvaluatedAt: now}
+	regions := make(map[int32][]*alertCheck)
+	var undercutChecks []*alertCheck
+	for _, rule := range rules {
+		condition, err := ParseAlertExpression(rule.Expression)
+		if err != nil {
+			result.Errors = append(result.Errors, AlertRuleError{RuleID: rule.RuleID, Error: err.Error()})
+			continue
+		}
+		check := &alertCheck{rule: rule, condition: condition}
+		if condition.Metric == models.AlertMetricUndercut {
+			undercutChecks = append(undercutChecks, check)
+		} else {
+			regions[condition.RegionID] = append(regions[condition.RegionID], check)
+		}
+	}
+
+	typeNames := make(map[int32]string)
+	var evaluated []models.AlertRule
+	for regionID, checks := range regions {
+		data, failed, err := e.loadMarketData(ctx, regionID, checks)
+		if err != nil {
+			return nil, err
+		}
+		for _, check := range checks {
+			if typeErr, ok := failed[check.rule.TypeID]; ok {
+				result.Errors = append(result.Errors, AlertRuleError{RuleID: check.rule.RuleID, Error: typeErr.Error()})
+				continue
+			}
+			value, threshold, known := measureAlert(check.condition, check.rule.TypeID, data)
+			matched := known && compareAlert(value, check.condition.Operator, threshold)
+			notification := models.AlertNotification{Hub: check.condition.Hub, Value: value, Threshold: threshold}
+			if known {
+				check.rule.LastValue = value
+			}
+			e.apply(ctx, check, matched, "", notification, typeNames, now, result)
+			evaluated = append(evaluated, check.rule)
+		}
+	}
+
+	if len(undercutChecks) > 0 {
+		evaluated = append(evaluated, e.evaluateUndercuts(ctx, undercutChecks, typeNames, now, result)...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Evaluated = len(evaluated)
+	if err := e.store.SaveAlertRuleStates(evaluated); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// loadMarketData fetches the orders and history of all types watched in a region.
+// One failing type fails a whole market data request, so the types are then
+// requested one by one and those still failing are returned with their error.
+func (e *AlertEngine) loadMarketData(ctx context.Context, regionID int32, checks []*alertCheck) (*MarketDataResponse, map[int32]error, error) {
+	var typeIDs []int32
+	seen := make(map[int32]bool)
+	for _, check := range checks {
+		if !seen[check.rule.TypeID] {
+			seen[check.rule.TypeID] = true
+			typeIDs = append(typeIDs, check.rule.TypeID)
+		}
+	}
+	sort.Slice(typeIDs, func(i, j int) bool { return typeIDs[i] < typeIDs[j] })
+	data, err := e.market.GetMarketData(ctx, MarketDataRequest{RegionID: regionID, TypeIDs: typeIDs})
+	if err == nil {
+		return data, nil, nil
+	}
+
+	data = &MarketDataResponse{
+		RegionID: regionID,
+		Orders:   make(map[int32][]models.MarketOrder, len(typeIDs)),
+		History:  make(map[int32][]models.MarketHistory, len(typeIDs)),
+	}
+	failed := make(map[int32]error)
+	jobs := make(chan int32)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < min(orderBookFetchWorkers, len(typeIDs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for typeID := range jobs {
+				typeData, err := e.market.GetMarketData(ctx, MarketDataRequest{RegionID: regionID, TypeIDs: []int32{typeID}})
+				mu.Lock()
+				if err != nil {
+					failed[typeID] = err
+				} else {
+					data.Orders[typeID] = typeData.Orders[typeID]
+					data.History[typeID] = typeData.History[typeID]
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, typeID := range typeIDs {
+		jobs <- typeID
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return data, failed, nil
+}
+
+// evaluateUndercuts checks the open orders of all characters with undercut rules
+func (e *AlertEngine) evaluateUndercuts(ctx context.Context, checks []*alertCheck, typeNames map[int32]string, now time.Time, result *AlertRunResult) []models.AlertRule {
+	fail := func(check *alertCheck, message string) {
+		result.Errors = append(result.Errors, AlertRuleError{RuleID: check.rule.RuleID, Error: message})
+	}
+	if e.undercuts == nil {
+		for _, check := range checks {
+			fail(check, "undercut checks are not configured")
+		}
+		return nil
+	}
+
+	var characterIDs []int32
+	seen := make(map[int32]bool)
+	for _, check := range checks {
+		if !seen[check.rule.CharacterID] {
+			seen[check.rule.CharacterID] = true
+			characterIDs = append(characterIDs, check.rule.CharacterID)
+		}
+	}
+	sort.Slice(characterIDs, func(i, j int) bool { return characterIDs[i] < characterIDs[j] })
+
+	report, err := e.undercuts.CheckCharacters(ctx, characterIDs, false)
+	if err != nil {
+		for _, check := range checks {
+			fail(check, err.Error())
+		}
+		return nil
+	}
+	failed := make(map[int32]string)
+	for _, characterErr := range report.Errors {
+		failed[characterErr.CharacterID] = characterErr.Error
+	}
+
+	var evaluated []models.AlertRule
+	for _, check := range checks {
+		if message, ok := failed[check.rule.CharacterID]; ok {
+			fail(check, message)
+			continue
+		}
+
+		var orders []models.OrderUndercut
+		for _, order := range report.Orders {
+			if order.CharacterID == check.rule.CharacterID && order.Status != models.OrderStatusLeading &&
+				(check.rule.TypeID == 0 || order.TypeID == check.rule.TypeID) {
+				orders = append(orders, order)
+			}
+		}
+		check.rule.LastValue = float64(len(orders))
+		notification := models.AlertNotification{Value: float64(len(orders)), Orders: orders}
+		e.apply(ctx, check, len(orders) > 0, undercutKey(orders), notification, typeNames, now, result)
+		evaluated = append(evaluated, check.rule)
+	}
+	return evaluated
+}
+
+// undercutKey identifies a set of undercuts by order and competitor price, so
+// the same undercuts are not notified twice
+func undercutKey(orders []models.OrderUndercut) string {
+	parts := make([]string, len(orders))
+	for i, order := range orders {
+		parts[i] = strconv.FormatInt(order.OrderID, 10) + ":" + strconv.FormatFloat(order.CompetitorPrice, 'f', -1, 64)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// apply updates the trigger state of an evaluated rule and notifies it if its
+// condition newly holds and the cooldown has passed
+func (e *AlertEngine) apply(ctx context.Context, check *alertCheck, matched bool, key string, notification models.AlertNotification, typeNames map[int32]string, now time.Time, result *AlertRunResult) {
+	rule := &check.rule
+	rule.LastCheckedAt = now
+	if !matched {
+		rule.Triggered, rule.LastKey = false, ""
+		return
+	}
+	if rule.Triggered && rule.LastKey == key {
+		return // Already notified
+	}
+	cooldown := e.cooldown
+	if rule.CooldownSeconds > 0 {
+		cooldown = time.Duration(rule.CooldownSeconds) * time.Second
+	}
+	if !rule.LastTriggeredAt.IsZero() && now.Sub(rule.LastTriggeredAt) < cooldown {
+		return // Notified once the cooldown has passed if the condition still holds
+	}
+
+	notification.RuleID = rule.RuleID
+	notification.CharacterID = rule.CharacterID
+	notification.Name = rule.Name
+	notification.TypeID = rule.TypeID
+	notification.TypeName = e.typeName(rule.TypeID, typeNames)
+	notification.Expression = rule.Expression
+	notification.Email = rule.Email
+	notification.TriggeredAt = now
+	notification.Message = alertMessage(check.condition, notification)
+
+	delivered, err := e.deliver(ctx, rule.Channels, notification)
+	if err != nil {
+		result.Errors = append(result.Errors, AlertRuleError{RuleID: rule.RuleID, Error: err.Error()})
+	}
+	if !delivered {
+		return // Retried on the next evaluation
+	}
+	rule.Triggered, rule.LastKey, rule.LastTriggeredAt = true, key, now
+	result.Triggered++
+}
+
+// deliver sends a notification to the rule's channels, by default to all
+// configured channels. It succeeds if at least one channel accepted it.
+func (e *AlertEngine) deliver(ctx context.Context, channels []string, notification models.AlertNotification) (bool, error) {
+	if len(channels) == 0 {
+		channels = e.Channels()
+	}
+	if len(channels) == 0 {
+		return true, nil // Without channels the trigger state is the notification
+	}
+
+	delivered := false
+	var errs []error
+	for _, channel := range channels {
+		sink, ok := e.sinks[channel]
+		if !ok {
+			errs = append(errs, fmt.Errorf("notification channel %s is not configured", channel))
+			continue
+		}
+		if err := sink.SendAlert(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+			continue
+		}
+		delivered = true
+	}
+	return delivered, errors.Join(errs...)
+}
+
+func (e *AlertEngine) typeName(typeID int32, names map[int32]string) string {
+	if typeID == 0 || e.items == nil {
+		return ""
+	}
+	if name, ok := names[typeID]; ok {
+		return name
+	}
+	var name string
+	if item, err := e.items.GetItemByID(typeID); err == nil {
+		name = item.TypeName
+	}
+	names[typeID] = name
+	return name
+}
+
+// measureAlert returns the current value of a rule's metric and the threshold
+// it is compared against. known is false without orders or history to judge.
+func measureAlert(condition *models.AlertCondition, typeID int32, data *MarketDataResponse) (float64, float64, bool) {
+	hub := MarketHub{RegionID: condition.RegionID, StationID: condition.StationID}
+	buyMax, sellMin := hubBestPrices(data.Orders[typeID], hub)
+	history := sortedHistory(data.History[typeID])
+
+	var value float64
+	switch condition.Metric {
+	case models.AlertMetricSell:
+		if sellMin <= 0 {
+			return 0, 0, false // No sell orders at the hub
+		}
+		value = sellMin
+	case models.AlertMetricBuy:
+		if buyMax <= 0 {
+			return 0, 0, false
+		}
+		value = buyMax
+	case models.AlertMetricSpread:
+		if buyMax <= 0 || sellMin <= 0 {
+			return 0, 0, false
+		}
+		value = (sellMin - buyMax) / sellMin * 100
+	case models.AlertMetricVolume:
+		if len(history) == 0 {
+			return 0, 0, false
+		}
+		value = float64(history[len(history)-1].Volume)
+	}
+	if condition.Multiplier <= 0 {
+		return value, condition.Threshold, true
+	}
+	// Averages cover the days before the latest history day
+	if len(history) < 2 {
+		return value, 0, false
+	}
+	days := history[max(0, len(history)-1-condition.AverageDays) : len(history)-1]
+	var sum float64
+	for _, day := range days {
+		if condition.Metric == models.AlertMetricVolume {
+			sum += float64(day.Volume)
+		} else {
+			sum += day.Average
+		}
+	}
+	return value, condition.Multiplier * sum / float64(len(days)), true
+}
+
+// hubBestPrices returns the highest buy and lowest sell price of the orders at a hub
+func hubBestPrices(book []models.MarketOrder, hub MarketHub) (float64, float64) {
+	var buyMax, sellMin float64
+	for _, order := range book {
+		if !hub.Contains(order.LocationID) || order.VolumeRemain <= 0 {
+			continue
+		}
+		if order.IsBuyOrder {
+			buyMax = max(buyMax, order.Price)
+		} else if sellMin == 0 || order.Price < sellMin {
+			sellMin = order.Price
+		}
+	}
+	return buyMax, sellMin
+}
+
+func sortedHistory(history []models.MarketHistory) []models.MarketHistory {
+	sorted := append([]models.MarketHistory(nil), history...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	return sorted
+}
+
+func compareAlert(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	}
+	return false
+}
+
+// alertMessage describes a triggered rule in one line
+func alertMessage(condition *models.AlertCondition, notification models.AlertNotification) string {
+	subject := notification.TypeName
+	if subject == "" && notification.TypeID != 0 {
+		subject = "type " + strconv.Itoa(int(notification.TypeID))
+	}
+
+	if condition.Metric == models.AlertMetricUndercut {
+		if subject == "" {
+			return fmt.Sprintf("%d of your orders are undercut or outbid", len(notification.Orders))
+		}
+		return fmt.Sprintf("%d of your %s orders are undercut or outbid", len(notification.Orders), subject)
+	}
+
+	format := func(value float64) string {
+		switch condition.Metric {
+		case models.AlertMetricSpread:
+			return strconv.FormatFloat(value, 'f', 1, 64) + "%"
+		case models.AlertMetricVolume:
+			return strconv.FormatFloat(value, 'f', 0, 64)
+		}
+		return strconv.FormatFloat(value, 'f', 2, 64) + " ISK"
+	}
+	return fmt.Sprintf("%s %s %s is %s (%s %s)", condition.Hub, subject, condition.Metric,
+		format(notification.Value), condition.Operator, format(notification.Threshold))
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"eve-profit2/internal/models"
+)
+
+// Defaults and limits of alert expressions
+const (
+	DefaultAlertAverageDays = 30
+	MaxAlertAverageDays     = 365
+)
+
+// ParseAlertExpression parses the expression of an alert rule:
+//
+//	[hub] metric [operator] value
+//
+// The hub is one of the default trade hubs and defaults to Jita. Metrics are
+// sell, buy, spread (in percent) and volume (units traded on the last history
+// day). Operators are <, <=, >, >=, above and below. Values are numbers with an optional k,
+// m or b suffix, or a multiple of the history average such as "3x 30d average",
+// where the operator may follow the multiplier. "my order undercut"
+// watches the own orders instead of the market.
+//
+// Examples: "Jita sell < 5", "spread > 15%", "volume 3x above 30-day average".
+func ParseAlertExpression(expression string) (*models.AlertCondition, error) {
+	tokens := tokenizeAlertExpression(expression)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty alert expression", ErrInvalidInput)
+	}
+	if isUndercutExpression(tokens) {
+		return &models.AlertCondition{Metric: models.AlertMetricUndercut}, nil
+	}
+
+	hub := DefaultMarketHubs[0]
+	if named, ok := HubByName(tokens[0]); ok {
+		hub = named
+		tokens = tokens[1:]
+	}
+	condition := &models.AlertCondition{Hub: hub.Name, RegionID: hub.RegionID, StationID: hub.StationID}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: alert expression %q has no metric", ErrInvalidInput, expression)
+	}
+	switch tokens[0] {
+	case models.AlertMetricSell, models.AlertMetricBuy, models.AlertMetricSpread, models.AlertMetricVolume:
+		condition.Metric = tokens[0]
+	default:
+		return nil, fmt.Errorf("%w: unknown alert metric %q, use sell, buy, spread or volume", ErrInvalidInput, tokens[0])
+	}
+	tokens = tokens[1:]
+
+	if len(tokens) > 0 {
+		if operator, ok := alertOperator(tokens[0]); ok {
+			condition.Operator = operator
+			tokens = tokens[1:]
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: alert expression %q has no value", ErrInvalidInput, expression)
+	}
+
+	if multiplier, ok := strings.CutSuffix(tokens[0], "x"); ok {
+		if err := parseAlertAverage(condition, multiplier, tokens[1:]); err != nil {
+			return nil, fmt.Errorf("%w: %v in alert expression %q", ErrInvalidInput, err, expression)
+		}
+	} else {
+		if err := parseAlertThreshold(condition, tokens); err != nil {
+			return nil, fmt.Errorf("%w: %v in alert expression %q", ErrInvalidInput, err, expression)
+		}
+	}
+
+	if condition.Operator == "" {
+		return nil, fmt.Errorf("%w: alert expression %q has no operator", ErrInvalidInput, expression)
+	}
+	return condition, nil
+}
+
+// tokenizeAlertExpression lowercases the expression and splits it into words
+// and operators, so "sell<5" and "sell < 5" are the same
+func tokenizeAlertExpression(expression string) []string {
+	var spaced strings.Builder
+	expression = strings.ToLower(expression)
+	for i := 0; i < len(expression); i++ {
+		switch ch := expression[i]; ch {
+		case '<', '>':
+			spaced.WriteString(" ")
+			spaced.WriteByte(ch)
+			if i+1 < len(expression) && expression[i+1] == '=' {
+				spaced.WriteByte('=')
+				i++
+			}
+			spaced.WriteString(" ")
+		default:
+			spaced.WriteByte(ch)
+		}
+	}
+	return strings.Fields(spaced.String())
+}
+
+func isUndercutExpression(tokens []string) bool {
+	for _, token := range tokens {
+		switch token {
+		case "my", "order", "orders", "is", "are", "get", "gets", "got":
+		case models.AlertMetricUndercut, "outbid":
+		default:
+			return false
+		}
+	}
+	last := tokens[len(tokens)-1]
+	return last == models.AlertMetricUndercut || last == "outbid"
+}
+
+// alertOperator returns the comparison operator of a token, "above" and "below" included
+func alertOperator(token string) (string, bool) {
+	switch token {
+	case "<", "<=", ">", ">=":
+		return token, true
+	case "above":
+		return ">", true
+	case "below":
+		return "<", true
+	}
+	return "", false
+}
+
+// parseAlertThreshold reads an absolute value such as 5, 1.5m or 15%
+func parseAlertThreshold(condition *models.AlertCondition, tokens []string) error {
+	if len(tokens) > 1 {
+		return fmt.Errorf("unexpected %q", strings.Join(tokens[1:], " "))
+	}
+	value := tokens[0]
+	percent := false
+	if trimmed, ok := strings.CutSuffix(value, "%"); ok {
+		if condition.Metric != models.AlertMetricSpread {
+			return fmt.Errorf("percentages are only supported for the spread")
+		}
+		value, percent = trimmed, true
+	}
+
+	scale := 1.0
+	if !percent {
+		switch {
+		case strings.HasSuffix(value, "k"):
+			scale = 1e3
+		case strings.HasSuffix(value, "m"):
+			scale = 1e6
+		case strings.HasSuffix(value, "b"):
+			scale = 1e9
+		}
+		if scale > 1 {
+			value = value[:len(value)-1]
+		}
+	}
+
+	threshold, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil || threshold < 0 {
+		return fmt.Errorf("invalid value %q", tokens[0])
+	}
+	condition.Threshold = threshold * scale
+	return nil
+}
+
+// parseAlertAverage reads a multiple of the history average such as
+// "3x 30d average" or "3x above 30-day average"
+func parseAlertAverage(condition *models.AlertCondition, multiplier string, tokens []string) error {
+	if condition.Metric == models.AlertMetricSpread {
+		return fmt.Errorf("the spread has no history average")
+	}
+	value, err := strconv.ParseFloat(multiplier, 64)
+	if err != nil || value <= 0 {
+		return fmt.Errorf("invalid multiplier %q", multiplier+"x")
+	}
+	condition.Multiplier = value
+	condition.AverageDays = DefaultAlertAverageDays
+
+	for _, token := range tokens {
+		switch token {
+		case "above", "below":
+			operator, _ := alertOperator(token)
+			if condition.Operator != "" && condition.Operator[:1] != operator {
+				return fmt.Errorf("%q contradicts the operator %s", token, condition.Operator)
+			}
+			if condition.Operator == "" {
+				condition.Operator = operator
+			}
+		case "the", "of", "average", "avg", "days", "day":
+		default:
+			days, err := strconv.Atoi(strings.TrimRight(token, "-dayst"))
+			if err != nil || days <= 0 || days > MaxAlertAverageDays {
+				return fmt.Errorf("unexpected %q", token)
+			}
+			condition.AverageDays = days
+		}
+	}
+	return nil
+}
@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// ErrNoScanAvailable is returned when no arbitrage scan has completed yet
+var ErrNoScanAvailable = errors.New("no arbitrage scan available")
+
+// Pagination defaults shared by paged endpoints
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// ArbitrageOpportunity describes buying from sell orders in one hub and
+// selling into buy orders in another hub
+type ArbitrageOpportunity struct {
+	TypeID        int32     `json:"type_id"`
+	From          MarketHub `json:"from"`
+	To            MarketHub `json:"to"`
+	BuyPrice      float64   `json:"buy_price"`  // Lowest sell order price in the source hub
+	SellPrice     float64   `json:"sell_price"` // Highest buy order price in the destination hub
+	Quantity      int64     `json:"quantity"`
+	Investment    float64   `json:"investment"`
+	Revenue       float64   `json:"revenue"` // Net of sales tax
+	Profit        float64   `json:"profit"`
+	ProfitMargin  float64   `json:"profit_margin"` // Percent of investment
+	ProfitPerUnit float64   `json:"profit_per_unit"`
+}
+
+// ArbitrageScan is the result of a complete scan over all configured hubs
+type ArbitrageScan struct {
+	Hubs          []MarketHub            `json:"hubs"`
+	Opportunities []ArbitrageOpportunity `json:"-"`
+	ScannedAt     time.Time              `json:"scanned_at"`
+	Duration      time.Duration          `json:"duration"`
+	OrderCount    int                    `json:"order_count"`
+}
+
+// ArbitragePage is a single page of opportunities sorted by profit
+type ArbitragePage struct {
+	Opportunities []ArbitrageOpportunity `json:"opportunities"`
+	Page          int                    `json:"page"`
+	PageSize      int                    `json:"page_size"`
+	Total         int                    `json:"total"`
+	ScannedAt     time.Time              `json:"scanned_at"`
+	Scanning      bool                   `json:"scanning"`
+}
+
+// ArbitrageService scans regional order books for inter-hub arbitrage
+type ArbitrageService struct {
+	client   RegionOrderClient
+	hubs     []MarketHub
+	salesTax float64
+
+	mu       sync.RWMutex
+	lastScan *ArbitrageScan
+	scanning bool
+	jobCtx   context.Context // Cancels triggered scans on shutdown
+}
+
+func NewArbitrageService(client RegionOrderClient, hubs []MarketHub, salesTax float64) *ArbitrageService {
+	if len(hubs) == 0 {
+		hubs = DefaultMarketHubs
+	}
+	return &ArbitrageService{
+		client:   client,
+		hubs:     hubs,
+		salesTax: salesTax,
+		jobCtx:   context.Background(),
+	}
+}
+
+// Start runs a scan immediately and then every interval until the context is cancelled
+func (s *ArbitrageService) Start(ctx context.Context, interval time.Duration) {
+	s.mu.Lock()
+	s.jobCtx = ctx
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.Scan(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Printf("Arbitrage scan failed: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// TriggerScan starts a scan in the background unless one is already running.
+// It reports whether a new scan was started. The scan runs on the context of
+// Start, so it stops on shutdown.
+func (s *ArbitrageService) TriggerScan() bool {
+	if !s.beginScan() {
+		return false
+	}
+
+	s.mu.RLock()
+	ctx := s.jobCtx
+	s.mu.RUnlock()
+
+	go func() {
+		defer s.endScan()
+		if _, err := s.runScan(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Printf("Arbitrage scan failed: %v\n", err)
+		}
+	}()
+	return true
+}
+
+// IsScanning reports whether a scan is currently in progress
+func (s *ArbitrageService) IsScanning() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.scanning
+}
+
+// Scan fetches all hub order books and computes every profitable opportunity
+func (s *ArbitrageService) Scan(ctx context.Context) (*ArbitrageScan, error) {
+	if !s.beginScan() {
+		return nil, fmt.Errorf("arbitrage scan already in progress")
+	}
+	defer s.endScan()
+	return s.runScan(ctx)
+}
+
+// runScan performs a scan claimed with beginScan
+func (s *ArbitrageService) runScan(ctx context.Context) (*ArbitrageScan, error) {
+	start := time.Now()
+	books, orderCount, err := s.fetchHubBooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &ArbitrageScan{
+		Hubs:          s.hubs,
+		Opportunities: s.findOpportunities(books),
+		ScannedAt:     time.Now(),
+		Duration:      time.Since(start),
+		OrderCount:    orderCount,
+	}
+
+	s.mu.Lock()
+	s.lastScan = scan
+	s.mu.Unlock()
+
+	return scan, nil
+}
+
+// GetOpportunities returns a page of the latest scan results with at least minProfit ISK profit
+func (s *ArbitrageService) GetOpportunities(page, pageSize int, minProfit float64) (*ArbitragePage, error) {
+	s.mu.RLock()
+	scan, scanning := s.lastScan, s.scanning
+	s.mu.RUnlock()
+
+	if scan == nil {
+		return nil, ErrNoScanAvailable
+	}
+
+	page, pageSize = normalizePaging(page, pageSize)
+
+	filtered := make([]ArbitrageOpportunity, 0, len(scan.Opportunities))
+	for _, opportunity := range scan.Opportunities {
+		if opportunity.Profit >= minProfit {
+			filtered = append(filtered, opportunity)
+		}
+	}
+
+	start, end := pageBounds(len(filtered), page, pageSize)
+
+	return &ArbitragePage{
+		Opportunities: filtered[start:end],
+		Page:          page,
+		PageSize:      pageSize,
+		Total:         len(filtered),
+		ScannedAt:     scan.ScannedAt,
+		Scanning:      scanning,
+	}, nil
+}
+
+// beginScan marks a scan as running, returning false if one is already active
+func (s *ArbitrageService) beginScan() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scanning {
+		return false
+	}
+	s.scanning = true
+	return true
+}
+
+// endScan clears the running flag
+func (s *ArbitrageService) endScan() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scanning = false
+}
+
+// hubBook holds the sorted hub orders of a single type
+type hubBook struct {
+	sells []models.MarketOrder // Ascending by price
+	buys  []models.MarketOrder // Descending by price
+}
+
+// regionBookResult is the outcome of fetching one region's order book
+type regionBookResult struct {
+	hubIndex int
+	books    map[int32]*hubBook
+	orders   int
+	err      error
+}
+
+// fetchHubBooks fetches the order books of all hubs concurrently
+func (s *ArbitrageService) fetchHubBooks(ctx context.Context) ([]map[int32]*hubBook, int, error) {
+	results := make(chan regionBookResult, len(s.hubs))
+	var wg sync.WaitGroup
+
+	for i, hub := range s.hubs {
+		wg.Add(1)
+		go func(index int, hub MarketHub) {
+			defer wg.Done()
+			orders, err := s.client.GetRegionOrders(ctx, hub.RegionID)
+			if err != nil {
+				results <- regionBookResult{hubIndex: index, err: fmt.Errorf("failed to get orders for region %d: %w", hub.RegionID, err)}
+				return
+			}
+			results <- regionBookResult{hubIndex: index, books: buildHubBooks(hub, orders), orders: len(orders)}
+		}(i, hub)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	books := make([]map[int32]*hubBook, len(s.hubs))
+	orderCount := 0
+	for res := range results {
+		if res.err != nil {
+			return nil, 0, res.err
+		}
+		books[res.hubIndex] = res.books
+		orderCount += res.orders
+	}
+
+	return books, orderCount, nil
+}
+
+// buildHubBooks groups the hub's orders by type and sorts them best price first
+func buildHubBooks(hub MarketHub, orders []models.MarketOrder) map[int32]*hubBook {
+	books := make(map[int32]*hubBook)
+
+	for _, order := range orders {
+		if !hub.Contains(order.LocationID) || order.VolumeRemain <= 0 {
+			continue
+		}
+		// Buy orders with a minimum volume cannot reliably absorb partial quantities
+		if order.IsBuyOrder && order.MinVolume > 1 {
+			continue
+		}
+
+		book, ok := books[order.TypeID]
+		if !ok {
+			book = &hubBook{}
+			books[order.TypeID] = book
+		}
+
+		if order.IsBuyOrder {
+			book.buys = append(book.buys, order)
+		} else {
+			book.sells = append(book.sells, order)
+		}
+	}
+
+	for _, book := range books {
+		sort.Slice(book.sells, func(i, j int) bool { return book.sells[i].Price < book.sells[j].Price })
+		sort.Slice(book.buys, func(i, j int) bool { return book.buys[i].Price > book.buys[j].Price })
+	}
+
+	return books
+}
+
+// findOpportunities compares every ordered hub pair and returns opportunities sorted by profit
+func (s *ArbitrageService) findOpportunities(books []map[int32]*hubBook) []ArbitrageOpportunity {
+	var opportunities []ArbitrageOpportunity
+
+	for from := range s.hubs {
+		for to := range s.hubs {
+			if from == to {
+				continue
+			}
+			for typeID, source := range books[from] {
+				destination, ok := books[to][typeID]
+				if !ok || len(source.sells) == 0 || len(destination.buys) == 0 {
+					continue
+				}
+				if opportunity, ok := s.matchBooks(source.sells, destination.buys); ok {
+					opportunity.TypeID = typeID
+					opportunity.From = s.hubs[from]
+					opportunity.To = s.hubs[to]
+					opportunities = append(opportunities, opportunity)
+				}
+			}
+		}
+	}
+
+	sort.Slice(opportunities, func(i, j int) bool {
+		if opportunities[i].Profit != opportunities[j].Profit {
+			return opportunities[i].Profit > opportunities[j].Profit
+		}
+		return opportunities[i].TypeID < opportunities[j].TypeID
+	})
+
+	return opportunities
+}
+
+// matchBooks walks both books while the next unit is still profitable after sales tax
+func (s *ArbitrageService) matchBooks(sells, buys []models.MarketOrder) (ArbitrageOpportunity, bool) {
+	var result ArbitrageOpportunity
+
+	sellIndex, buyIndex := 0, 0
+	sellRemain := int64(sells[0].VolumeRemain)
+	buyRemain := int64(buys[0].VolumeRemain)
+
+	for sellIndex < len(sells) && buyIndex < len(buys) {
+		cost := sells[sellIndex].Price
+		netRevenue := buys[buyIndex].Price * (1 - s.salesTax)
+		if netRevenue <= cost {
+			break
+		}
+
+		quantity := min(sellRemain, buyRemain)
+		result.Quantity += quantity
+		result.Investment += float64(quantity) * cost
+		result.Revenue += float64(quantity) * netRevenue
+
+		sellRemain -= quantity
+		buyRemain -= quantity
+		if sellRemain == 0 {
+			sellIndex++
+			if sellIndex < len(sells) {
+				sellRemain = int64(sells[sellIndex].VolumeRemain)
+			}
+		}
+		if buyRemain == 0 {
+			buyIndex++
+			if buyIndex < len(buys) {
+				buyRemain = int64(buys[buyIndex].VolumeRemain)
+			}
+		}
+	}
+
+	if result.Quantity == 0 {
+		return result, false
+	}
+
+	result.BuyPrice = sells[0].Price
+	result.SellPrice = buys[0].Price
+	result.Profit = result.Revenue - result.Investment
+	result.ProfitMargin = result.Profit / result.Investment * 100
+	result.ProfitPerUnit = result.Profit / float64(result.Quantity)
+
+	return result, true
+}
+
+// normalizePaging applies defaults and bounds to page parameters
+func normalizePaging(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return page, pageSize
+}
+
+// pageBounds returns the slice bounds of a page within total results
+func pageBounds(total, page, pageSize int) (int, int) {
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -0,0 +1,552 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Additional price sources for asset valuation, next to PriceTypeBuy and PriceTypeSell
+const (
+	PriceTypeAverage    = "average"    // ESI universe-wide average price
+	PriceTypePercentile = "percentile" // Volume weighted average of the cheapest sell orders at the hub
+)
+
+// Asset valuation defaults and limits
+const (
+	DefaultValuationPercentile = 5.0
+	DefaultTopItems            = 20
+	MaxTopItems                = 500
+
+	averagePriceTTL   = time.Hour
+	maxContainerDepth = 16 // Guards against cycles in inconsistent asset lists
+)
+
+// ValuationOptions selects how assets are priced
+type ValuationOptions struct {
+	Source     string    // PriceTypeSell, PriceTypeBuy, PriceTypeAverage or PriceTypePercentile
+	Hub        MarketHub // Hub whose order book prices the order based sources
+	Percentile float64   // Share of the hub sell volume averaged by PriceTypePercentile, in percent
+	TopItems   int       // Length of the top items list
+}
+
+// AssetValuation is the net worth of the assets of one or more characters
+type AssetValuation struct {
+	Source       string                  `json:"source"`
+	Hub          *MarketHub              `json:"hub,omitempty"` // Not set for ESI average prices
+	Percentile   float64                 `json:"percentile,omitempty"`
+	TotalValue   float64                 `json:"total_value"`
+	Stacks       int                     `json:"stacks"`
+	PricedStacks int                     `json:"priced_stacks"`
+	ByLocation   []models.LocationValue  `json:"by_location"`
+	ByCategory   []models.CategoryValue  `json:"by_category"`
+	ByCharacter  []models.CharacterValue `json:"by_character"`
+	Containers   []models.ContainerValue `json:"containers"`
+	TopItems     []models.AssetTypeValue `json:"top_items"`
+	Unpriceable  []models.AssetTypeValue `json:"unpriceable"`
+	Errors       []CharacterError        `json:"errors,omitempty"`
+	ValuedAt     time.Time               `json:"valued_at"`
+}
+
+// AssetValuationService prices character assets and breaks their value down by
+// location, item category and character
+type AssetValuationService struct {
+	assets CharacterDataProvider
+	market MarketDataProvider
+	prices MarketPriceClient
+	items  ItemLookup
+
+	averageMu      sync.RWMutex
+	averagePrices  map[int32]float64
+	averageUpdated time.Time
+}
+
+func NewAssetValuationService(assets CharacterDataProvider, market MarketDataProvider, prices MarketPriceClient) *AssetValuationService {
+	return &AssetValuationService{
+		assets: assets,
+		market: market,
+		prices: prices,
+	}
+}
+
+// WithItems resolves type names and categories for the category breakdown
+func (s *AssetValuationService) WithItems(items ItemLookup) *AssetValuationService {
+	s.items = items
+	return s
+}
+
+// NormalizeValuationOptions validates the options and fills in the defaults:
+// lowest sell price in Jita, 5th percentile and 20 top items
+func NormalizeValuationOptions(opts ValuationOptions) (ValuationOptions, error) {
+	switch opts.Source {
+	case "":
+		opts.Source = PriceTypeSell
+	case PriceTypeSell, PriceTypeBuy, PriceTypeAverage, PriceTypePercentile:
+	default:
+		return opts, fmt.Errorf("%w: unknown price source %q, use sell, buy, average or percentile", ErrInvalidInput, opts.Source)
+	}
+
+	if opts.Hub.RegionID == 0 {
+		opts.Hub = DefaultMarketHubs[0]
+	}
+	if opts.Percentile == 0 {
+		opts.Percentile = DefaultValuationPercentile
+	}
+	if opts.Percentile < 0 || opts.Percentile > 100 {
+		return opts, fmt.Errorf("%w: percentile must be between 0 and 100", ErrInvalidInput)
+	}
+	if opts.TopItems == 0 {
+		opts.TopItems = DefaultTopItems
+	}
+	if opts.TopItems < 0 || opts.TopItems > MaxTopItems {
+		return opts, fmt.Errorf("%w: top items must be between 1 and %d", ErrInvalidInput, MaxTopItems)
+	}
+	return opts, nil
+}
+
+// valuedStack is one asset stack with its resolved location and value
+type valuedStack struct {
+	characterID  int32
+	asset        models.CharacterAsset
+	rootID       int64
+	rootType     string
+	ancestors    []int64 // Containing items, innermost first
+	unitPrice    float64
+	value        float64
+	priced       bool
+	categoryID   int32
+	categoryName string
+	typeName     string
+}
+
+// ValueCharacters values the assets of the given characters. Characters whose
+// assets cannot be loaded are reported without failing the whole valuation.
+func (s *AssetValuationService) ValueCharacters(ctx context.Context, characterIDs []int32, opts ValuationOptions) (*AssetValuation, error) {
+	opts, err := NormalizeValuationOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AssetValuation{Source: opts.Source, ValuedAt: time.Now()}
+	if opts.Source != PriceTypeAverage {
+		hub := opts.Hub
+		report.Hub = &hub
+	}
+	if opts.Source == PriceTypePercentile {
+		report.Percentile = opts.Percentile
+	}
+
+	var stacks []*valuedStack
+	for _, characterID := range characterIDs {
+		assets, err := s.assets.GetCharacterAssets(ctx, characterID)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			report.Errors = append(report.Errors, CharacterError{CharacterID: characterID, Error: err.Error()})
+			continue
+		}
+		stacks = append(stacks, resolveAssetLocations(characterID, assets)...)
+	}
+
+	typeIDs := make(map[int32]bool)
+	for _, stack := range stacks {
+		typeIDs[stack.asset.TypeID] = true
+	}
+	prices, err := s.loadPrices(ctx, typeIDs, opts)
+	if err != nil {
+		return nil, err
+	}
+	items := s.lookupItems(typeIDs)
+
+	for _, stack := range stacks {
+		// Blueprint copies cannot be sold on the market, the price of their type is the original's
+		if !stack.asset.IsBlueprintCopy {
+			stack.unitPrice = prices[stack.asset.TypeID]
+		}
+		stack.priced = stack.unitPrice > 0
+		stack.value = stack.unitPrice * float64(stack.asset.Quantity)
+		if item, ok := items[stack.asset.TypeID]; ok {
+			stack.typeName = item.TypeName
+			stack.categoryID = item.CategoryID
+			stack.categoryName = item.CategoryName
+		}
+	}
+
+	summarizeValuation(report, stacks, opts.TopItems)
+	return report, nil
+}
+
+// resolveAssetLocations follows assets inside ships and containers up to the
+// station, structure or solar system they are in. An item parent that is not
+// part of the list is a player structure, which ESI does not return as an asset.
+func resolveAssetLocations(characterID int32, assets []models.CharacterAsset) []*valuedStack {
+	byItemID := make(map[int64]models.CharacterAsset, len(assets))
+	for _, asset := range assets {
+		byItemID[asset.ItemID] = asset
+	}
+
+	stacks := make([]*valuedStack, 0, len(assets))
+	for _, asset := range assets {
+		stack := &valuedStack{characterID: characterID, asset: asset}
+
+		current := asset
+		for depth := 0; current.LocationType == "item" && depth < maxContainerDepth; depth++ {
+			parent, ok := byItemID[current.LocationID]
+			if !ok {
+				break
+			}
+			stack.ancestors = append(stack.ancestors, parent.ItemID)
+			current = parent
+		}
+
+		stack.rootID = current.LocationID
+		switch current.LocationType {
+		case "station":
+			stack.rootType = models.AssetLocationStation
+		case "item":
+			stack.rootType = models.AssetLocationStructure
+		case "solar_system":
+			stack.rootType = models.AssetLocationSolarSystem
+		default:
+			stack.rootType = models.AssetLocationOther
+		}
+		stacks = append(stacks, stack)
+	}
+	return stacks
+}
+
+// loadPrices prices every type with the selected source. Types without a price are left out.
+func (s *AssetValuationService) loadPrices(ctx context.Context, typeIDs map[int32]bool, opts ValuationOptions) (map[int32]float64, error) {
+	if len(typeIDs) == 0 {
+		return map[int32]float64{}, nil
+	}
+
+	if opts.Source == PriceTypeAverage {
+		averages, err := s.getAveragePrices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		prices := make(map[int32]float64, len(typeIDs))
+		for typeID := range typeIDs {
+			if price := averages[typeID]; price > 0 {
+				prices[typeID] = price
+			}
+		}
+		return prices, nil
+	}
+
+	books, err := loadOrderBooks(ctx, s.market, opts.Hub.RegionID, typeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[int32]float64, len(typeIDs))
+	for typeID, book := range books {
+		if price := hubPrice(book, opts); price > 0 {
+			prices[typeID] = price
+		}
+	}
+	return prices, nil
+}
+
+// loadOrderBooks loads the order books of a region through the market cache.
+// One failing type fails a whole market data request, so the types are then
+// requested one by one and those still failing are left out.
+func loadOrderBooks(ctx context.Context, market MarketDataProvider, regionID int32, typeIDs map[int32]bool) (map[int32][]models.MarketOrder, error) {
+	ids := make([]int32, 0, len(typeIDs))
+	for typeID := range typeIDs {
+		ids = append(ids, typeID)
+	}
+	data, err := market.GetMarketData(ctx, MarketDataRequest{RegionID: regionID, TypeIDs: ids})
+	if err == nil {
+		return data.Orders, nil
+	}
+
+	jobs := make(chan int32)
+	books := make(map[int32][]models.MarketOrder, len(ids))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < min(orderBookFetchWorkers, len(ids)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for typeID := range jobs {
+				data, err := market.GetMarketData(ctx, MarketDataRequest{RegionID: regionID, TypeIDs: []int32{typeID}})
+				if err != nil {
+					continue // Reported as unpriceable
+				}
+				mu.Lock()
+				books[typeID] = data.Orders[typeID]
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, typeID := range ids {
+		jobs <- typeID
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// hubPrice prices one type from the orders at the hub
+func hubPrice(book []models.MarketOrder, opts ValuationOptions) float64 {
+	var sells []models.MarketOrder
+	var buyMax float64
+	for _, order := range book {
+		if !opts.Hub.Contains(order.LocationID) || order.VolumeRemain <= 0 {
+			continue
+		}
+		if order.IsBuyOrder {
+			buyMax = max(buyMax, order.Price)
+		} else {
+			sells = append(sells, order)
+		}
+	}
+
+	switch opts.Source {
+	case PriceTypeBuy:
+		return buyMax
+	case PriceTypePercentile:
+		return sellPercentilePrice(sells, opts.Percentile)
+	}
+
+	var sellMin float64
+	for _, order := range sells {
+		if sellMin == 0 || order.Price < sellMin {
+			sellMin = order.Price
+		}
+	}
+	return sellMin
+}
+
+// sellPercentilePrice averages the cheapest percent of the sell volume, weighted
+// by volume. Unlike the lowest sell order it is not moved by a single small order.
+func sellPercentilePrice(sells []models.MarketOrder, percent float64) float64 {
+	if len(sells) == 0 {
+		return 0
+	}
+	sort.Slice(sells, func(i, j int) bool { return sells[i].Price < sells[j].Price })
+
+	var total int64
+	for _, order := range sells {
+		total += int64(order.VolumeRemain)
+	}
+	target := max(float64(total)*percent/100, 1)
+
+	var volume, value float64
+	for _, order := range sells {
+		take := min(float64(order.VolumeRemain), target-volume)
+		volume += take
+		value += take * order.Price
+		if volume >= target {
+			break
+		}
+	}
+	return value / volume
+}
+
+// getAveragePrices returns cached ESI average prices, refreshing them hourly
+func (s *AssetValuationService) getAveragePrices(ctx context.Context) (map[int32]float64, error) {
+	s.averageMu.RLock()
+	if s.averagePrices != nil && time.Since(s.averageUpdated) < averagePriceTTL {
+		prices := s.averagePrices
+		s.averageMu.RUnlock()
+		return prices, nil
+	}
+	s.averageMu.RUnlock()
+
+	marketPrices, err := s.prices.GetMarketPrices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get average prices: %w", err)
+	}
+
+	prices := make(map[int32]float64, len(marketPrices))
+	for _, price := range marketPrices {
+		prices[price.TypeID] = price.AveragePrice
+	}
+
+	s.averageMu.Lock()
+	s.averagePrices = prices
+	s.averageUpdated = time.Now()
+	s.averageMu.Unlock()
+
+	return prices, nil
+}
+
+// lookupItems resolves names and categories. Unknown types stay uncategorized.
+func (s *AssetValuationService) lookupItems(typeIDs map[int32]bool) map[int32]*models.Item {
+	items := make(map[int32]*models.Item, len(typeIDs))
+	if s.items == nil {
+		return items
+	}
+	for typeID := range typeIDs {
+		item, err := s.items.GetItemByID(typeID)
+		if err != nil {
+			continue
+		}
+		items[typeID] = item
+	}
+	return items
+}
+
+// assetTypeKey groups stacks per type, keeping blueprint copies apart from originals
+type assetTypeKey struct {
+	typeID        int32
+	blueprintCopy bool
+}
+
+// summarizeValuation fills the totals and breakdowns of the report
+func summarizeValuation(report *AssetValuation, stacks []*valuedStack, topItems int) {
+	byLocation := make(map[int64]*models.LocationValue)
+	byCategory := make(map[int32]*models.CategoryValue)
+	byCharacter := make(map[int32]*models.CharacterValue)
+	byType := make(map[assetTypeKey]*models.AssetTypeValue)
+	containers := make(map[int64]*models.ContainerValue)
+
+	for _, stack := range stacks {
+		for _, ancestorID := range stack.ancestors {
+			if _, ok := containers[ancestorID]; !ok {
+				containers[ancestorID] = &models.ContainerValue{CharacterID: stack.characterID, ItemID: ancestorID, LocationID: stack.rootID}
+			}
+		}
+	}
+
+	for _, stack := range stacks {
+		report.Stacks++
+		report.TotalValue += stack.value
+
+		location, ok := byLocation[stack.rootID]
+		if !ok {
+			location = &models.LocationValue{LocationID: stack.rootID, LocationType: stack.rootType}
+			byLocation[stack.rootID] = location
+		}
+		location.Stacks++
+		location.Value += stack.value
+
+		character, ok := byCharacter[stack.characterID]
+		if !ok {
+			character = &models.CharacterValue{CharacterID: stack.characterID}
+			byCharacter[stack.characterID] = character
+		}
+		character.Stacks++
+		character.Value += stack.value
+
+		if !stack.priced {
+			location.Unpriced++
+		} else {
+			report.PricedStacks++
+			category, ok := byCategory[stack.categoryID]
+			if !ok {
+				category = &models.CategoryValue{CategoryID: stack.categoryID, CategoryName: stack.categoryName}
+				byCategory[stack.categoryID] = category
+			}
+			category.Quantity += int64(stack.asset.Quantity)
+			category.Value += stack.value
+		}
+
+		typeKey := assetTypeKey{typeID: stack.asset.TypeID, blueprintCopy: stack.asset.IsBlueprintCopy}
+		itemType, ok := byType[typeKey]
+		if !ok {
+			itemType = &models.AssetTypeValue{
+				TypeID:          stack.asset.TypeID,
+				TypeName:        stack.typeName,
+				CategoryID:      stack.categoryID,
+				CategoryName:    stack.categoryName,
+				IsBlueprintCopy: stack.asset.IsBlueprintCopy,
+				UnitPrice:       stack.unitPrice,
+			}
+			byType[typeKey] = itemType
+		}
+		itemType.Quantity += int64(stack.asset.Quantity)
+		itemType.Value += stack.value
+
+		if container, ok := containers[stack.asset.ItemID]; ok {
+			container.TypeID = stack.asset.TypeID
+			container.OwnValue = stack.value
+			container.Value += stack.value
+		}
+		for _, ancestorID := range stack.ancestors {
+			container := containers[ancestorID]
+			container.ContentValue += stack.value
+			container.Value += stack.value
+			container.Items++
+		}
+	}
+
+	report.ByLocation = make([]models.LocationValue, 0, len(byLocation))
+	for _, location := range byLocation {
+		report.ByLocation = append(report.ByLocation, *location)
+	}
+	sort.Slice(report.ByLocation, func(i, j int) bool {
+		if report.ByLocation[i].Value != report.ByLocation[j].Value {
+			return report.ByLocation[i].Value > report.ByLocation[j].Value
+		}
+		return report.ByLocation[i].LocationID < report.ByLocation[j].LocationID
+	})
+
+	report.ByCategory = make([]models.CategoryValue, 0, len(byCategory))
+	for _, category := range byCategory {
+		report.ByCategory = append(report.ByCategory, *category)
+	}
+	sort.Slice(report.ByCategory, func(i, j int) bool {
+		if report.ByCategory[i].Value != report.ByCategory[j].Value {
+			return report.ByCategory[i].Value > report.ByCategory[j].Value
+		}
+		return report.ByCategory[i].CategoryID < report.ByCategory[j].CategoryID
+	})
+
+	report.ByCharacter = make([]models.CharacterValue, 0, len(byCharacter))
+	for _, character := range byCharacter {
+		report.ByCharacter = append(report.ByCharacter, *character)
+	}
+	sort.Slice(report.ByCharacter, func(i, j int) bool {
+		return report.ByCharacter[i].CharacterID < report.ByCharacter[j].CharacterID
+	})
+
+	report.Containers = make([]models.ContainerValue, 0, len(containers))
+	for _, container := range containers {
+		report.Containers = append(report.Containers, *container)
+	}
+	sort.Slice(report.Containers, func(i, j int) bool {
+		if report.Containers[i].Value != report.Containers[j].Value {
+			return report.Containers[i].Value > report.Containers[j].Value
+		}
+		return report.Containers[i].ItemID < report.Containers[j].ItemID
+	})
+
+	report.TopItems = []models.AssetTypeValue{}
+	report.Unpriceable = []models.AssetTypeValue{}
+	for _, itemType := range byType {
+		if itemType.UnitPrice > 0 {
+			report.TopItems = append(report.TopItems, *itemType)
+		} else {
+			report.Unpriceable = append(report.Unpriceable, *itemType)
+		}
+	}
+	sort.Slice(report.TopItems, func(i, j int) bool {
+		if report.TopItems[i].Value != report.TopItems[j].Value {
+			return report.TopItems[i].Value > report.TopItems[j].Value
+		}
+		return report.TopItems[i].TypeID < report.TopItems[j].TypeID
+	})
+	if len(report.TopItems) > topItems {
+		report.TopItems = report.TopItems[:topItems]
+	}
+	sort.Slice(report.Unpriceable, func(i, j int) bool {
+		if report.Unpriceable[i].TypeID != report.Unpriceable[j].TypeID {
+			return report.Unpriceable[i].TypeID < report.Unpriceable[j].TypeID
+		}
+		return !report.Unpriceable[i].IsBlueprintCopy
+	})
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Authentication errors
+var (
+	ErrInvalidLoginState = errors.New("invalid or expired login state")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrSSORequest        = errors.New("SSO request failed")
+	ErrLoginRequired     = errors.New("login required") // The refresh token was revoked
+)
+
+const (
+	// LoginStateTTL is how long a started login can be completed
+	LoginStateTTL = 10 * time.Minute
+
+	// MaxPendingLogins caps the started logins kept in memory. The login
+	// endpoint is public, so beyond the cap the oldest login is dropped.
+	MaxPendingLogins = 10000
+
+	// TokenRefreshMargin is how long before expiry an access token is refreshed
+	TokenRefreshMargin = 2 * time.Minute
+)
+
+// SSOClient defines the contract for the EVE SSO OAuth2 endpoints
+type SSOClient interface {
+	AuthorizeURL(state, codeChallenge string, scopes []string) string
+	ExchangeCode(ctx context.Context, code, codeVerifier string) (*models.AuthToken, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*models.AuthToken, error)
+}
+
+// AccountLinker records logins in the user's account
+type AccountLinker interface {
+	LinkLogin(token *models.AuthToken, linkCharacterID int32) (*models.Account, error)
+}
+
+// LoginRequest is a started login. The nonce must be kept by the browser
+// (e.g. in a cookie) and presented again on the callback.
+type LoginRequest struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	Nonce            string    `json:"-"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// pendingLogin is a started login waiting for its callback
+type pendingLogin struct {
+	state           string
+	element         *list.Element // Position in AuthService.pendingOrder
+	nonceHash       [sha256.Size]byte
+	codeVerifier    string
+	expiresAt       time.Time
+	linkCharacterID int32 // Account of this character gets the new character, 0 for none
+}
+
+// AuthService runs the EVE SSO authorization code flow with PKCE and keeps
+// the resulting tokens per character
+type AuthService struct {
+	sso      SSOClient
+	tokens   TokenStore
+	scopes   []string
+	accounts AccountLinker
+
+	mu      sync.Mutex
+	pending map[string]*pendingLogin // Keyed by state
+	// All logins live for LoginStateTTL, so start order is expiry order
+	pendingOrder *list.List
+
+	// A refresh token must never be used twice, so refreshes run one at a time per character
+	locksMu        sync.Mutex
+	characterLocks map[int32]*sync.Mutex
+}
+
+func NewAuthService(sso SSOClient, tokens TokenStore, scopes []string) *AuthService {
+	return &AuthService{
+		sso:            sso,
+		tokens:         tokens,
+		scopes:         scopes,
+		pending:        make(map[string]*pendingLogin),
+		pendingOrder:   list.New(),
+		characterLocks: make(map[int32]*sync.Mutex),
+	}
+}
+
+// WithAccounts links every completed login to an account
+func (s *AuthService) WithAccounts(accounts AccountLinker) *AuthService {
+	s.accounts = accounts
+	return s
+}
+
+// invalidGrant is implemented by SSO errors for rejected authorization codes and refresh tokens
+type invalidGrant interface {
+	InvalidGrant() bool
+}
+
+// BeginLogin creates the state, nonce and PKCE verifier of a new login and
+// returns the SSO URL the user has to visit. A non-zero linkCharacterID is the
+// authenticated character whose account the logged in character joins.
+func (s *AuthService) BeginLogin(linkCharacterID int32) (*LoginRequest, error) {
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	login := &pendingLogin{
+		state:           state,
+		nonceHash:       sha256.Sum256([]byte(nonce)),
+		codeVerifier:    codeVerifier,
+		expiresAt:       now.Add(LoginStateTTL),
+		linkCharacterID: linkCharacterID,
+	}
+
+	s.mu.Lock()
+	s.prunePendingLocked(now)
+	for s.pendingOrder.Len() >= MaxPendingLogins {
+		s.removePendingLocked(s.pendingOrder.Front().Value.(*pendingLogin))
+	}
+	login.element = s.pendingOrder.PushBack(login)
+	s.pending[state] = login
+	s.mu.Unlock()
+
+	return &LoginRequest{
+		AuthorizationURL: s.sso.AuthorizeURL(state, codeChallenge(codeVerifier), s.scopes),
+		State:            state,
+		Nonce:            nonce,
+		ExpiresAt:        login.expiresAt,
+	}, nil
+}
+
+// CompleteLogin validates the callback state and nonce, exchanges the code and
+// stores the token of the character. A state can only be used once.
+func (s *AuthService) CompleteLogin(ctx context.Context, state, nonce, code string) (*models.AuthToken, error) {
+	if code == "" {
+		return nil, fmt.Errorf("%w: authorization code is required", ErrInvalidInput)
+	}
+
+	s.mu.Lock()
+	login, ok := s.pending[state]
+	if ok {
+		s.removePendingLocked(login)
+	}
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(login.expiresAt) {
+		return nil, ErrInvalidLoginState
+	}
+	nonceHash := sha256.Sum256([]byte(nonce))
+	if subtle.ConstantTimeCompare(nonceHash[:], login.nonceHash[:]) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidLoginState)
+	}
+
+	token, err := s.sso.ExchangeCode(ctx, code, login.codeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to exchange authorization code: %w", ErrSSORequest, err)
+	}
+
+	if err := s.tokens.SaveToken(token); err != nil {
+		return nil, fmt.Errorf("failed to store token: %w", err)
+	}
+
+	if s.accounts != nil {
+		if _, err := s.accounts.LinkLogin(token, login.linkCharacterID); err != nil {
+			return nil, fmt.Errorf("failed to link character to account: %w", err)
+		}
+	}
+	return token, nil
+}
+
+// RefreshToken issues a new access token for a character. The caller has to
+// present the character's current (possibly expired) access token.
+func (s *AuthService) RefreshToken(ctx context.Context, characterID int32, accessToken string) (*models.AuthToken, error) {
+	unlock := s.lockCharacter(characterID)
+	defer unlock()
+
+	stored, err := s.storedToken(characterID)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" || subtle.ConstantTimeCompare([]byte(accessToken), []byte(stored.AccessToken)) != 1 {
+		return nil, ErrUnauthorized
+	}
+	if stored.Revoked {
+		return nil, ErrLoginRequired
+	}
+	return s.refreshLocked(ctx, stored)
+}
+
+// ValidToken returns an access token of a character that is valid for at least
+// TokenRefreshMargin, refreshing it if needed. Background jobs use it to act on
+// behalf of characters without anyone being logged in.
+func (s *AuthService) ValidToken(ctx context.Context, characterID int32) (*models.AuthToken, error) {
+	return s.validToken(ctx, characterID, TokenRefreshMargin)
+}
+
+// validToken refreshes the token of a character if it expires within margin
+func (s *AuthService) validToken(ctx context.Context, characterID int32, margin time.Duration) (*models.AuthToken, error) {
+	unlock := s.lockCharacter(characterID)
+	defer unlock()
+
+	stored, err := s.storedToken(characterID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Revoked {
+		return nil, ErrLoginRequired
+	}
+	if time.Until(stored.ExpiresAt) > margin {
+		return stored, nil
+	}
+	return s.refreshLocked(ctx, stored)
+}
+
+// Characters lists the characters with a stored token, including revoked ones
+func (s *AuthService) Characters() ([]*models.AuthToken, error) {
+	tokens, err := s.tokens.ListTokens()
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		// Callers only need to know who is logged in
+		token.AccessToken = ""
+		token.RefreshToken = ""
+	}
+	return tokens, nil
+}
+
+// RefreshExpiring refreshes all tokens that expire within the given window and
+// returns the number of refreshed tokens. Revoked tokens are skipped.
+func (s *AuthService) RefreshExpiring(ctx context.Context, window time.Duration) (int, error) {
+	tokens, err := s.tokens.ListTokens()
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, token := range tokens {
+		if token.Revoked || time.Until(token.ExpiresAt) > window {
+			continue
+		}
+		// validToken checks again under the character lock
+		if _, err := s.validToken(ctx, token.CharacterID, window); err != nil {
+			fmt.Printf("Warning: failed to refresh token of character %d: %v\n", token.CharacterID, err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// Start refreshes expiring tokens in the background until the context is done
+func (s *AuthService) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.RefreshExpiring(ctx, interval+TokenRefreshMargin); err != nil {
+				fmt.Printf("Warning: token refresh failed: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// storedToken maps a missing token to ErrUnauthorized
+func (s *AuthService) storedToken(characterID int32) (*models.AuthToken, error) {
+	stored, err := s.tokens.GetToken(characterID)
+	if errors.Is(err, ErrTokenNotFound) {
+		return nil, ErrUnauthorized
+	}
+	return stored, err
+}
+
+// refreshLocked exchanges the stored refresh token, the character lock must be held.
+// A rejected refresh token marks the character for a new login.
+func (s *AuthService) refreshLocked(ctx context.Context, stored *models.AuthToken) (*models.AuthToken, error) {
+	token, err := s.sso.RefreshToken(ctx, stored.RefreshToken)
+	if err != nil {
+		var grantErr invalidGrant
+		if errors.As(err, &grantErr) && grantErr.InvalidGrant() {
+			if markErr := s.tokens.MarkRevoked(stored.CharacterID); markErr != nil {
+				return nil, fmt.Errorf("failed to mark token revoked: %w", markErr)
+			}
+			return nil, fmt.Errorf("%w: %w", ErrLoginRequired, err)
+		}
+		return nil, fmt.Errorf("%w: failed to refresh token: %w", ErrSSORequest, err)
+	}
+	if token.CharacterID != stored.CharacterID {
+		return nil, fmt.Errorf("%w: refreshed token belongs to character %d", ErrUnauthorized, token.CharacterID)
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = stored.RefreshToken // The SSO did not rotate it
+	}
+
+	if err := s.tokens.SaveToken(token); err != nil {
+		return nil, fmt.Errorf("failed to store token: %w", err)
+	}
+	return token, nil
+}
+
+// lockCharacter serializes token refreshes of one character
+func (s *AuthService) lockCharacter(characterID int32) func() {
+	s.locksMu.Lock()
+	lock, ok := s.characterLocks[characterID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.characterLocks[characterID] = lock
+	}
+	s.locksMu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// prunePendingLocked drops expired logins from the front of the expiry queue, s.mu must be held
+func (s *AuthService) prunePendingLocked(now time.Time) {
+	for front := s.pendingOrder.Front(); front != nil; front = s.pendingOrder.Front() {
+		login := front.Value.(*pendingLogin)
+		if !now.After(login.expiresAt) {
+			return
+		}
+		s.removePendingLocked(login)
+	}
+}
+
+// removePendingLocked forgets a started login, s.mu must be held
+func (s *AuthService) removePendingLocked(login *pendingLogin) {
+	s.pendingOrder.Remove(login.element)
+	delete(s.pending, login.state)
+}
+
+// randomToken returns 32 random bytes, base64url encoded. This also is a valid PKCE verifier.
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// codeChallenge derives the S256 PKCE challenge of a verifier
+func codeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
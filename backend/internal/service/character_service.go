@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"eve-profit2/internal/models"
+)
+
+// accessTokenKey is the context key of a caller-supplied access token
+type accessTokenKey struct{}
+
+// callerToken is an access token sent by the caller for one character
+type callerToken struct {
+	characterID int32
+	accessToken string
+}
+
+// ContextWithAccessToken attaches the caller's verified access token. The
+// CharacterService uses it for that character instead of the persisted token.
+func ContextWithAccessToken(ctx context.Context, characterID int32, accessToken string) context.Context {
+	return context.WithValue(ctx, accessTokenKey{}, callerToken{characterID: characterID, accessToken: accessToken})
+}
+
+// CharacterService loads character data from authenticated ESI endpoints and
+// caches it per character
+type CharacterService struct {
+	esiClient CharacterESIClient
+	tokens    CharacterTokenProvider
+	cache     CharacterCache
+}
+
+func NewCharacterService(esiClient CharacterESIClient, tokens CharacterTokenProvider, cache CharacterCache) *CharacterService {
+	return &CharacterService{
+		esiClient: esiClient,
+		tokens:    tokens,
+		cache:     cache,
+	}
+}
+
+// GetCharacterInfo returns the public character sheet
+func (s *CharacterService) GetCharacterInfo(ctx context.Context, characterID int32) (*models.Character, error) {
+	cacheKey := characterCacheKey("info", characterID)
+	var cached models.Character
+	if s.getCached(cacheKey, &cached) {
+		return &cached, nil
+	}
+
+	character, err := s.esiClient.GetCharacterInfo(ctx, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character %d info: %w", characterID, err)
+	}
+	s.setCached(cacheKey, character)
+	return character, nil
+}
+
+// GetCharacterAssets returns all assets of the character
+func (s *CharacterService) GetCharacterAssets(ctx context.Context, characterID int32) ([]models.CharacterAsset, error) {
+	cacheKey := characterCacheKey("assets", characterID)
+	var cached []models.CharacterAsset
+	if s.getCached(cacheKey, &cached) {
+		return cached, nil
+	}
+
+	accessToken, err := s.accessToken(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := s.esiClient.GetCharacterAssets(ctx, characterID, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character %d assets: %w", characterID, err)
+	}
+	s.setCached(cacheKey, assets)
+	return assets, nil
+}
+
+// GetCharacterWallet returns the wallet balance of the character
+func (s *CharacterService) GetCharacterWallet(ctx context.Context, characterID int32) (*models.CharacterWallet, error) {
+	cacheKey := characterCacheKey("wallet", characterID)
+	var cached models.CharacterWallet
+	if s.getCached(cacheKey, &cached) {
+		return &cached, nil
+	}
+
+	accessToken, err := s.accessToken(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := s.esiClient.GetCharacterWallet(ctx, characterID, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character %d wallet: %w", characterID, err)
+	}
+	s.setCached(cacheKey, wallet)
+	return wallet, nil
+}
+
+// GetCharacterOrders returns the open market orders of the character
+func (s *CharacterService) GetCharacterOrders(ctx context.Context, characterID int32) ([]models.CharacterOrder, error) {
+	cacheKey := characterCacheKey("orders", characterID)
+	var cached []models.CharacterOrder
+	if s.getCached(cacheKey, &cached) {
+		return cached, nil
+	}
+
+	accessToken, err := s.accessToken(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.esiClient.GetCharacterOrders(ctx, characterID, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character %d orders: %w", characterID, err)
+	}
+	s.setCached(cacheKey, orders)
+	return orders, nil
+}
+
+// GetCharacterSkills returns the trained skills of the character
+func (s *CharacterService) GetCharacterSkills(ctx context.Context, characterID int32) (*models.CharacterSkills, error) {
+	cacheKey := characterCacheKey("skills", characterID)
+	var cached models.CharacterSkills
+	if s.getCached(cacheKey, &cached) {
+		return &cached, nil
+	}
+
+	accessToken, err := s.accessToken(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := s.esiClient.GetCharacterSkills(ctx, characterID, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character %d skills: %w", characterID, err)
+	}
+	s.setCached(cacheKey, skills)
+	return skills, nil
+}
+
+// accessToken prefers the caller's token for its own character and falls back
+// to the persisted token, which is refreshed when it is about to expire
+func (s *CharacterService) accessToken(ctx context.Context, characterID int32) (string, error) {
+	return accessTokenFor(ctx, s.tokens, characterID)
+}
+
+// accessTokenFor returns the caller-supplied token of the character or its persisted token
+func accessTokenFor(ctx context.Context, tokens CharacterTokenProvider, characterID int32) (string, error) {
+	if token, ok := ctx.Value(accessTokenKey{}).(callerToken); ok && token.characterID == characterID && token.accessToken != "" {
+		return token.accessToken, nil
+	}
+	if tokens == nil {
+		return "", ErrUnauthorized
+	}
+
+	token, err := tokens.ValidToken(ctx, characterID)
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+func (s *CharacterService) getCached(key string, dest interface{}) bool {
+	if s.cache == nil {
+		return false
+	}
+	return s.cache.GetCharacterData(key, dest) == nil
+}
+
+func (s *CharacterService) setCached(key string, data interface{}) {
+	if s.cache != nil {
+		_ = s.cache.SetCharacterData(key, data) // Cache failures only cost performance
+	}
+}
+
+func characterCacheKey(kind string, characterID int32) string {
+	return fmt.Sprintf("character_%s_%d", kind, characterID)
+}
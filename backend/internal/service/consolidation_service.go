@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// ConsolidationOptions selects the target hub and how stops are judged
+type ConsolidationOptions struct {
+	Hub       MarketHub // Target hub, must name a station
+	PriceType string    // PriceTypeBuy (default) or PriceTypeSell, used locally and at the hub
+	Route     string    // RouteShortest (default) or RouteSecure
+}
+
+// ConsolidationPlan lists the locations holding assets, most valuable move first
+type ConsolidationPlan struct {
+	Hub              MarketHub                  `json:"hub"`
+	HubSolarSystemID int32                      `json:"hub_solar_system_id"`
+	PriceType        string                     `json:"price_type"`
+	Route            string                     `json:"route"`
+	Stops            []models.ConsolidationStop `json:"stops"`
+	TotalVolume      float64                    `json:"total_volume"`
+	TotalValueGained float64                    `json:"total_value_gained"`
+	Unresolved       []models.LocationValue     `json:"unresolved"` // Player structures without known solar system
+	Errors           []CharacterError           `json:"errors,omitempty"`
+	PlannedAt        time.Time                  `json:"planned_at"`
+}
+
+// ConsolidationService suggests which scattered assets are worth hauling to a trade hub
+type ConsolidationService struct {
+	assets   CharacterDataProvider
+	universe UniverseRepository
+	market   MarketDataProvider
+	items    ItemLookup
+
+	mapMu   sync.Mutex
+	starMap *starMap
+}
+
+func NewConsolidationService(assets CharacterDataProvider, universe UniverseRepository, market MarketDataProvider, items ItemLookup) *ConsolidationService {
+	return &ConsolidationService{
+		assets:   assets,
+		universe: universe,
+		market:   market,
+		items:    items,
+	}
+}
+
+// ResetMap drops the cached star map so the next plan reads the current SDE
+func (s *ConsolidationService) ResetMap() {
+	s.mapMu.Lock()
+	defer s.mapMu.Unlock()
+	s.starMap = nil
+}
+
+// normalizeConsolidationOptions validates the options and fills in the defaults
+func normalizeConsolidationOptions(opts ConsolidationOptions) (ConsolidationOptions, error) {
+	if opts.Hub.RegionID == 0 {
+		opts.Hub = DefaultMarketHubs[0]
+	}
+	if opts.Hub.StationID == 0 {
+		return opts, fmt.Errorf("%w: the target hub must be a station", ErrInvalidInput)
+	}
+
+	priceType, err := normalizePriceType(opts.PriceType)
+	if err != nil {
+		return opts, err
+	}
+	opts.PriceType = priceType
+
+	route, err := ParseRoutePreference(opts.Route)
+	if err != nil {
+		return opts, err
+	}
+	opts.Route = route
+	return opts, nil
+}
+
+// PlanCharacters groups the assets of the given characters by location and
+// compares their local value with the value at the hub. Characters whose assets
+// cannot be loaded are reported without failing the whole plan.
+func (s *ConsolidationService) PlanCharacters(ctx context.Context, characterIDs []int32, opts ConsolidationOptions) (*ConsolidationPlan, error) {
+	opts, err := normalizeConsolidationOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	starMap, err := s.getStarMap()
+	if err != nil {
+		return nil, err
+	}
+	hubStation, err := s.universe.GetStationByID(opts.Hub.StationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate hub %s: %w", opts.Hub.Name, err)
+	}
+
+	plan := &ConsolidationPlan{
+		Hub:              opts.Hub,
+		HubSolarSystemID: hubStation.SystemID,
+		PriceType:        opts.PriceType,
+		Route:            opts.Route,
+		Stops:            []models.ConsolidationStop{},
+		Unresolved:       []models.LocationValue{},
+		PlannedAt:        time.Now(),
+	}
+
+	var stacks []*valuedStack
+	for _, characterID := range characterIDs {
+		assets, err := s.assets.GetCharacterAssets(ctx, characterID)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			plan.Errors = append(plan.Errors, CharacterError{CharacterID: characterID, Error: err.Error()})
+			continue
+		}
+		stacks = append(stacks, resolveAssetLocations(characterID, assets)...)
+	}
+
+	stops := make(map[int64]*models.ConsolidationStop)
+	stopStacks := make(map[int64][]*valuedStack)
+	unresolved := make(map[int64]*models.LocationValue)
+	for _, stack := range stacks {
+		if stack.rootID == opts.Hub.StationID {
+			continue // Already at the hub
+		}
+
+		switch stack.rootType {
+		case models.AssetLocationStation, models.AssetLocationSolarSystem:
+		case models.AssetLocationStructure:
+			location, ok := unresolved[stack.rootID]
+			if !ok {
+				location = &models.LocationValue{LocationID: stack.rootID, LocationType: stack.rootType}
+				unresolved[stack.rootID] = location
+			}
+			location.Stacks++
+			continue
+		default:
+			continue // Implants, skills and the like cannot be hauled
+		}
+
+		stop, ok := stops[stack.rootID]
+		if !ok {
+			stop, err = s.newStop(starMap, stack, hubStation.SystemID, opts.Route)
+			if err != nil {
+				return nil, err
+			}
+			stops[stack.rootID] = stop
+		}
+		stop.Stacks++
+		if !containsCharacter(stop.Characters, stack.characterID) {
+			stop.Characters = append(stop.Characters, stack.characterID)
+		}
+		stopStacks[stack.rootID] = append(stopStacks[stack.rootID], stack)
+	}
+
+	if err := s.valueStops(ctx, stops, stopStacks, opts); err != nil {
+		return nil, err
+	}
+
+	for _, stop := range stops {
+		plan.Stops = append(plan.Stops, *stop)
+		plan.TotalVolume += stop.Volume
+		plan.TotalValueGained += stop.ValueGained
+	}
+	sort.Slice(plan.Stops, func(i, j int) bool {
+		if plan.Stops[i].ValueGained != plan.Stops[j].ValueGained {
+			return plan.Stops[i].ValueGained > plan.Stops[j].ValueGained
+		}
+		return plan.Stops[i].LocationID < plan.Stops[j].LocationID
+	})
+
+	for _, location := range unresolved {
+		plan.Unresolved = append(plan.Unresolved, *location)
+	}
+	sort.Slice(plan.Unresolved, func(i, j int) bool { return plan.Unresolved[i].LocationID < plan.Unresolved[j].LocationID })
+	return plan, nil
+}
+
+// newStop locates a station or solar system and routes it to the hub
+func (s *ConsolidationService) newStop(starMap *starMap, stack *valuedStack, hubSystemID int32, preference string) (*models.ConsolidationStop, error) {
+	stop := &models.ConsolidationStop{LocationID: stack.rootID, LocationType: stack.rootType, Characters: []int32{}}
+
+	if stack.rootType == models.AssetLocationStation {
+		station, err := s.universe.GetStationByID(stack.rootID)
+		if err != nil && !errors.Is(err, repository.ErrStationNotFound) {
+			return nil, err
+		}
+		if station != nil {
+			stop.LocationName = station.StationName
+			stop.SolarSystemID = station.SystemID
+			stop.RegionID = station.RegionID
+		}
+	} else {
+		stop.SolarSystemID = int32(stack.rootID)
+	}
+
+	if system, ok := starMap.systems[stop.SolarSystemID]; ok {
+		stop.SolarSystemName = system.SolarSystemName
+		stop.RegionID = system.RegionID
+	}
+
+	route, ok := starMap.route(stop.SolarSystemID, hubSystemID, preference)
+	if ok {
+		stop.Reachable = true
+		stop.Jumps = len(route) - 1
+		stop.Route = route
+		stop.Security = starMap.routeSecurity(route)
+	}
+	return stop, nil
+}
+
+// valueStops prices the stacks of every stop from the orders at the stop and at
+// the hub station. Assets in space have no local market and no local value.
+// Types whose market data cannot be loaded count as unpriced.
+func (s *ConsolidationService) valueStops(ctx context.Context, stops map[int64]*models.ConsolidationStop, stopStacks map[int64][]*valuedStack, opts ConsolidationOptions) error {
+	regionTypes := make(map[int32]map[int32]bool)
+	addType := func(regionID, typeID int32) {
+		if regionTypes[regionID] == nil {
+			regionTypes[regionID] = make(map[int32]bool)
+		}
+		regionTypes[regionID][typeID] = true
+	}
+	for locationID, stacks := range stopStacks {
+		stop := stops[locationID]
+		for _, stack := range stacks {
+			addType(opts.Hub.RegionID, stack.asset.TypeID)
+			if stop.LocationType == models.AssetLocationStation && stop.RegionID > 0 {
+				addType(stop.RegionID, stack.asset.TypeID)
+			}
+		}
+	}
+
+	books := make(map[int32]map[int32][]models.MarketOrder, len(regionTypes))
+	for regionID, types := range regionTypes {
+		regionBooks, err := loadOrderBooks(ctx, s.market, regionID, types)
+		if err != nil {
+			return fmt.Errorf("failed to get market data of region %d: %w", regionID, err)
+		}
+		books[regionID] = regionBooks
+	}
+
+	hub := ValuationOptions{Source: opts.PriceType, Hub: opts.Hub}
+	items := make(map[int32]*models.Item)
+	for locationID, stacks := range stopStacks {
+		stop := stops[locationID]
+		local := ValuationOptions{Source: opts.PriceType, Hub: MarketHub{RegionID: stop.RegionID, StationID: stop.LocationID}}
+		for _, stack := range stacks {
+			quantity := float64(stack.asset.Quantity)
+			if len(stack.ancestors) == 0 {
+				stop.Volume += s.itemVolume(items, stack.asset.TypeID) * quantity
+			}
+
+			var localUnitPrice, hubUnitPrice float64
+			// Blueprint copies cannot be sold on the market
+			if !stack.asset.IsBlueprintCopy {
+				if stop.LocationType == models.AssetLocationStation {
+					localUnitPrice = hubPrice(books[stop.RegionID][stack.asset.TypeID], local)
+				}
+				hubUnitPrice = hubPrice(books[opts.Hub.RegionID][stack.asset.TypeID], hub)
+			}
+			if localUnitPrice <= 0 && hubUnitPrice <= 0 {
+				stop.Unpriced++
+				continue
+			}
+			stop.LocalValue += localUnitPrice * quantity
+			stop.HubValue += hubUnitPrice * quantity
+		}
+
+		stop.ValueGained = stop.HubValue - stop.LocalValue
+		if stop.Volume > 0 {
+			stop.ValuePerM3 = stop.ValueGained / stop.Volume
+		}
+	}
+	return nil
+}
+
+// itemVolume returns the volume of one unit, zero if the type is unknown
+func (s *ConsolidationService) itemVolume(items map[int32]*models.Item, typeID int32) float64 {
+	item, ok := items[typeID]
+	if !ok && s.items != nil {
+		item, _ = s.items.GetItemByID(typeID)
+		items[typeID] = item
+	}
+	if item == nil {
+		return 0
+	}
+	return item.Volume
+}
+
+// getStarMap builds the star map on first use
+func (s *ConsolidationService) getStarMap() (*starMap, error) {
+	s.mapMu.Lock()
+	defer s.mapMu.Unlock()
+
+	if s.starMap == nil {
+		starMap, err := newStarMap(s.universe)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load star map: %w", err)
+		}
+		s.starMap = starMap
+	}
+	return s.starMap, nil
+}
+
+func containsCharacter(characterIDs []int32, characterID int32) bool {
+	for _, id := range characterIDs {
+		if id == characterID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// ErrNotManufacturable is returned when no blueprint produces the requested type
+var ErrNotManufacturable = errors.New("type cannot be manufactured")
+
+// Industry constants
+const (
+	SCCSurchargeRate     = 0.04 // Secure Commerce Commission surcharge on the estimated item value
+	DefaultMaxBuildDepth = 5
+	MaxMaterialEff       = 10
+	MaxTimeEff           = 20
+	MaxRuns              = 1000000 // Keeps component quantities far from overflowing
+
+	DecisionBuild = "build"
+	DecisionBuy   = "buy"
+
+	adjustedPriceTTL = time.Hour
+)
+
+// ManufacturingRequest describes a manufacturing job and its facility
+type ManufacturingRequest struct {
+	TypeID                      int32   `json:"type_id"`
+	Runs                        int32   `json:"runs"`
+	MaterialEfficiency          int32   `json:"material_efficiency"`
+	TimeEfficiency              int32   `json:"time_efficiency"`
+	ComponentMaterialEfficiency int32   `json:"component_material_efficiency"`
+	ComponentTimeEfficiency     int32   `json:"component_time_efficiency"`
+	FacilityMaterialBonus       float64 `json:"facility_material_bonus"` // Fraction, e.g. 0.01 for 1%
+	FacilityTimeBonus           float64 `json:"facility_time_bonus"`     // Fraction, e.g. 0.15 for 15%
+	SystemCostIndex             float64 `json:"system_cost_index"`       // Fraction, e.g. 0.05 for 5%
+	FacilityTax                 float64 `json:"facility_tax"`            // Fraction, e.g. 0.0025
+	RegionID                    int32   `json:"region_id"`
+	MaxDepth                    int     `json:"max_depth"`
+}
+
+// ManufacturingPlan is the costed build of one type, including component builds
+type ManufacturingPlan struct {
+	TypeID           int32                          `json:"type_id"`
+	BlueprintTypeID  int32                          `json:"blueprint_type_id"`
+	Runs             int64                          `json:"runs"`
+	OutputQuantity   int64                          `json:"output_quantity"`
+	BuildTimeSeconds int64                          `json:"build_time_seconds"`
+	Materials        []MaterialRequirement          `json:"materials"`
+	Skills           []repository.SDEBlueprintSkill `json:"skills"`
+	EstimatedValue   float64                        `json:"estimated_item_value"`
+	MaterialCost     float64                        `json:"material_cost"`
+	JobCost          float64                        `json:"job_cost"`
+	TotalCost        float64                        `json:"total_cost"`
+	UnitCost         float64                        `json:"unit_cost"`
+}
+
+// MaterialRequirement is a material input with its build-vs-buy decision
+type MaterialRequirement struct {
+	TypeID        int32              `json:"type_id"`
+	Quantity      int64              `json:"quantity"`
+	UnitPrice     float64            `json:"unit_price"` // Lowest sell price in the region
+	BuyCost       float64            `json:"buy_cost"`
+	BuildCost     float64            `json:"build_cost,omitempty"`
+	Decision      string             `json:"decision"`
+	EffectiveCost float64            `json:"effective_cost"`
+	Build         *ManufacturingPlan `json:"build,omitempty"`
+
+	baseQuantity int64 // Quantity before efficiency bonuses, used for the estimated item value
+}
+
+// ManufacturingResult compares the manufacturing cost with the product's market value
+type ManufacturingResult struct {
+	RegionID        int32              `json:"region_id"`
+	Plan            *ManufacturingPlan `json:"plan"`
+	MarketUnitPrice float64            `json:"market_unit_price"`
+	MarketValue     float64            `json:"market_value"`
+	Profit          float64            `json:"profit"`
+	ProfitMargin    float64            `json:"profit_margin"` // Percent of total cost
+	Decision        string             `json:"decision"`
+}
+
+// IndustryService calculates manufacturing costs from SDE blueprints and market prices
+type IndustryService struct {
+	blueprints  BlueprintRepository
+	market      MarketDataProvider
+	priceClient MarketPriceClient
+
+	adjustedMux     sync.RWMutex
+	adjustedPrices  map[int32]float64
+	adjustedUpdated time.Time
+}
+
+func NewIndustryService(blueprints BlueprintRepository, market MarketDataProvider, priceClient MarketPriceClient) *IndustryService {
+	return &IndustryService{
+		blueprints:  blueprints,
+		market:      market,
+		priceClient: priceClient,
+	}
+}
+
+// CalculateManufacturing plans and prices a manufacturing job including build-vs-buy per component
+func (s *IndustryService) CalculateManufacturing(ctx context.Context, req ManufacturingRequest) (*ManufacturingResult, error) {
+	req, err := s.normalizeManufacturingRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	typeIDs := make(map[int32]bool)
+	plan, err := s.planBuild(req.TypeID, int64(req.Runs), req, 0, typeIDs, map[int32]bool{})
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, fmt.Errorf("%w: typeID %d", ErrNotManufacturable, req.TypeID)
+	}
+
+	sellPrices, err := s.fetchSellPrices(ctx, req.RegionID, typeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustedPrices, err := s.getAdjustedPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.costPlan(plan, req, sellPrices, adjustedPrices)
+
+	result := &ManufacturingResult{
+		RegionID:        req.RegionID,
+		Plan:            plan,
+		MarketUnitPrice: sellPrices[req.TypeID],
+		MarketValue:     sellPrices[req.TypeID] * float64(plan.OutputQuantity),
+	}
+	result.Profit = result.MarketValue - plan.TotalCost
+	if plan.TotalCost > 0 {
+		result.ProfitMargin = result.Profit / plan.TotalCost * 100
+	}
+	result.Decision = DecisionBuild
+	if result.MarketValue > 0 && result.Profit < 0 {
+		result.Decision = DecisionBuy
+	}
+
+	return result, nil
+}
+
+// normalizeManufacturingRequest validates the request and applies defaults
+func (s *IndustryService) normalizeManufacturingRequest(req ManufacturingRequest) (ManufacturingRequest, error) {
+	if req.TypeID <= 0 {
+		return req, fmt.Errorf("%w: type ID must be positive", ErrInvalidInput)
+	}
+	if req.Runs == 0 {
+		req.Runs = 1
+	}
+	if req.Runs < 0 || req.Runs > MaxRuns {
+		return req, fmt.Errorf("%w: runs must be between 1 and %d", ErrInvalidInput, MaxRuns)
+	}
+	if !efficiencyInRange(req.MaterialEfficiency, MaxMaterialEff) || !efficiencyInRange(req.ComponentMaterialEfficiency, MaxMaterialEff) {
+		return req, fmt.Errorf("%w: material efficiency must be between 0 and %d", ErrInvalidInput, MaxMaterialEff)
+	}
+	if !efficiencyInRange(req.TimeEfficiency, MaxTimeEff) || !efficiencyInRange(req.ComponentTimeEfficiency, MaxTimeEff) {
+		return req, fmt.Errorf("%w: time efficiency must be between 0 and %d", ErrInvalidInput, MaxTimeEff)
+	}
+	for _, fraction := range []float64{req.FacilityMaterialBonus, req.FacilityTimeBonus, req.SystemCostIndex, req.FacilityTax} {
+		if fraction < 0 || fraction >= 1 {
+			return req, fmt.Errorf("%w: bonuses, cost index and tax must be fractions between 0 and 1", ErrInvalidInput)
+		}
+	}
+	if req.RegionID == 0 {
+		req.RegionID = DefaultHubRegion
+	}
+	if req.MaxDepth <= 0 {
+		req.MaxDepth = DefaultMaxBuildDepth
+	}
+	return req, nil
+}
+
+// efficiencyInRange checks a blueprint efficiency level
+func efficiencyInRange(value, maximum int32) bool {
+	return value >= 0 && value <= maximum
+}
+
+// planBuild resolves the blueprint tree for producing at least quantity units of a type.
+// It returns nil if the type has no manufacturing blueprint.
+func (s *IndustryService) planBuild(typeID int32, quantity int64, req ManufacturingRequest, depth int, typeIDs, path map[int32]bool) (*ManufacturingPlan, error) {
+	typeIDs[typeID] = true
+
+	blueprint, err := s.blueprints.GetBlueprintByProduct(typeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrBlueprintNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if blueprint.ProductQuantity <= 0 {
+		return nil, nil
+	}
+
+	materialEfficiency, timeEfficiency := req.MaterialEfficiency, req.TimeEfficiency
+	if depth > 0 {
+		materialEfficiency, timeEfficiency = req.ComponentMaterialEfficiency, req.ComponentTimeEfficiency
+	}
+
+	runs := (quantity + int64(blueprint.ProductQuantity) - 1) / int64(blueprint.ProductQuantity)
+	if depth == 0 {
+		runs = int64(req.Runs)
+	}
+
+	plan := &ManufacturingPlan{
+		TypeID:           typeID,
+		BlueprintTypeID:  blueprint.BlueprintTypeID,
+		Runs:             runs,
+		OutputQuantity:   runs * int64(blueprint.ProductQuantity),
+		BuildTimeSeconds: buildTime(blueprint.Time, runs, timeEfficiency, req.FacilityTimeBonus),
+		Skills:           blueprint.Skills,
+	}
+
+	path[typeID] = true
+	defer delete(path, typeID)
+
+	for _, material := range blueprint.Materials {
+		requirement := MaterialRequirement{
+			TypeID:       material.TypeID,
+			Quantity:     materialQuantity(material.Quantity, runs, materialEfficiency, req.FacilityMaterialBonus),
+			Decision:     DecisionBuy,
+			baseQuantity: int64(material.Quantity) * runs,
+		}
+		typeIDs[material.TypeID] = true
+
+		if depth+1 < req.MaxDepth && !path[material.TypeID] {
+			component, err := s.planBuild(material.TypeID, requirement.Quantity, req, depth+1, typeIDs, path)
+			if err != nil {
+				return nil, err
+			}
+			requirement.Build = component
+		}
+
+		plan.Materials = append(plan.Materials, requirement)
+	}
+
+	return plan, nil
+}
+
+// costPlan prices the plan bottom-up and decides build or buy for each component
+func (s *IndustryService) costPlan(plan *ManufacturingPlan, req ManufacturingRequest, sellPrices, adjustedPrices map[int32]float64) {
+	for i := range plan.Materials {
+		material := &plan.Materials[i]
+
+		// Job costs are based on the unmodified material quantities
+		plan.EstimatedValue += float64(material.baseQuantity) * adjustedPrices[material.TypeID]
+
+		material.UnitPrice = sellPrices[material.TypeID]
+		material.BuyCost = material.UnitPrice * float64(material.Quantity)
+		material.EffectiveCost = material.BuyCost
+
+		if material.Build != nil {
+			s.costPlan(material.Build, req, sellPrices, adjustedPrices)
+			material.BuildCost = material.Build.UnitCost * float64(material.Quantity)
+
+			if material.UnitPrice == 0 || material.BuildCost < material.BuyCost {
+				material.Decision = DecisionBuild
+				material.EffectiveCost = material.BuildCost
+			}
+		}
+
+		plan.MaterialCost += material.EffectiveCost
+	}
+
+	plan.JobCost = plan.EstimatedValue * (req.SystemCostIndex + req.FacilityTax + SCCSurchargeRate)
+	plan.TotalCost = plan.MaterialCost + plan.JobCost
+	if plan.OutputQuantity > 0 {
+		plan.UnitCost = plan.TotalCost / float64(plan.OutputQuantity)
+	}
+}
+
+// materialQuantity applies blueprint ME and facility bonuses to a per-run material quantity
+func materialQuantity(baseQuantity int32, runs int64, materialEfficiency int32, facilityBonus float64) int64 {
+	if baseQuantity == 1 {
+		return runs
+	}
+
+	adjusted := float64(baseQuantity) * float64(runs) * (1 - float64(materialEfficiency)/100) * (1 - facilityBonus)
+	quantity := int64(math.Ceil(math.Round(adjusted*100) / 100))
+	return max(quantity, runs)
+}
+
+// buildTime applies blueprint TE and facility bonuses to the base job duration
+func buildTime(baseSeconds int32, runs int64, timeEfficiency int32, facilityBonus float64) int64 {
+	seconds := float64(baseSeconds) * float64(runs) * (1 - float64(timeEfficiency)/100) * (1 - facilityBonus)
+	return int64(math.Ceil(seconds))
+}
+
+// fetchSellPrices retrieves the lowest regional sell price for all types
+func (s *IndustryService) fetchSellPrices(ctx context.Context, regionID int32, typeIDs map[int32]bool) (map[int32]float64, error) {
+	req := MarketDataRequest{RegionID: regionID}
+	for typeID := range typeIDs {
+		req.TypeIDs = append(req.TypeIDs, typeID)
+	}
+
+	data, err := s.market.GetMarketData(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market data: %w", err)
+	}
+
+	prices := make(map[int32]float64, len(data.Data))
+	for typeID, price := range data.Data {
+		prices[typeID] = price.SellMin
+	}
+	return prices, nil
+}
+
+// getAdjustedPrices returns cached ESI adjusted prices, refreshing them hourly
+func (s *IndustryService) getAdjustedPrices(ctx context.Context) (map[int32]float64, error) {
+	s.adjustedMux.RLock()
+	if s.adjustedPrices != nil && time.Since(s.adjustedUpdated) < adjustedPriceTTL {
+		prices := s.adjustedPrices
+		s.adjustedMux.RUnlock()
+		return prices, nil
+	}
+	s.adjustedMux.RUnlock()
+
+	marketPrices, err := s.priceClient.GetMarketPrices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adjusted prices: %w", err)
+	}
+
+	prices := adjustedPriceMap(marketPrices)
+
+	s.adjustedMux.Lock()
+	s.adjustedPrices = prices
+	s.adjustedUpdated = time.Now()
+	s.adjustedMux.Unlock()
+
+	return prices, nil
+}
+
+// adjustedPriceMap indexes ESI adjusted prices by type
+func adjustedPriceMap(marketPrices []models.MarketPrice) map[int32]float64 {
+	prices := make(map[int32]float64, len(marketPrices))
+	for _, price := range marketPrices {
+		prices[price.TypeID] = price.AdjustedPrice
+	}
+	return prices
+}
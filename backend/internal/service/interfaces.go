@@ -3,14 +3,19 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
 )
 
 // Common service errors
 var (
 	ErrItemNotFound = errors.New("item not found")
 	ErrInvalidID    = errors.New("invalid ID")
+	ErrInvalidInput = errors.New("invalid input")
+
+	ErrMarketGroupNotFound = errors.New("market group not found")
 )
 
 // ESIClient interface defines the contract for ESI API operations
@@ -19,3 +24,198 @@ type ESIClient interface {
 	GetMarketHistory(ctx context.Context, regionID int32, typeID int32) ([]models.MarketHistory, error)
 	GetTypeInfo(ctx context.Context, typeID int32) (*models.TypeInfo, error)
 }
+
+// RegionOrderClient defines the contract for fetching complete regional order books
+type RegionOrderClient interface {
+	GetRegionOrders(ctx context.Context, regionID int32) ([]models.MarketOrder, error)
+}
+
+// MarketDataProvider defines the contract for aggregated regional market data
+type MarketDataProvider interface {
+	GetMarketData(ctx context.Context, req MarketDataRequest) (*MarketDataResponse, error)
+}
+
+// MarketPriceClient defines the contract for universe-wide average and adjusted prices
+type MarketPriceClient interface {
+	GetMarketPrices(ctx context.Context) ([]models.MarketPrice, error)
+}
+
+// BlueprintRepository defines the contract for SDE blueprint lookups
+type BlueprintRepository interface {
+	GetBlueprintByProduct(productTypeID int32) (*repository.SDEBlueprint, error)
+}
+
+// TypeMaterialRepository defines the contract for SDE reprocessing material lookups
+type TypeMaterialRepository interface {
+	GetTypeMaterials(typeID int32) (*repository.SDETypeMaterials, error)
+}
+
+// TypeVariantRepository defines the contract for SDE meta variant and dogma attribute lookups
+type TypeVariantRepository interface {
+	GetTypeVariants(typeID int32) ([]*repository.SDETypeVariant, error)
+	GetTypesAttributes(typeIDs []int32) (map[int32][]*repository.SDEAttribute, error)
+}
+
+// SDECache defines the contract for caching static data lookups
+type SDECache interface {
+	SetSDEData(key string, data interface{}) error
+	GetSDEData(key string, dest interface{}) error
+}
+
+// AccountRepository defines the contract for storing accounts and their linked characters
+type AccountRepository interface {
+	GetAccountByCharacter(characterID int32) (*models.Account, error)
+	CreateAccount(characterID int32, characterName string) (*models.Account, error)
+	LinkCharacter(accountID int64, characterID int32, characterName string) error
+	UnlinkCharacter(accountID int64, characterID int32) error
+	SetActiveCharacter(accountID int64, characterID int32) error
+}
+
+// CharacterDataProvider defines the contract for authenticated per-character ESI data
+type CharacterDataProvider interface {
+	GetCharacterWallet(ctx context.Context, characterID int32) (*models.CharacterWallet, error)
+	GetCharacterAssets(ctx context.Context, characterID int32) ([]models.CharacterAsset, error)
+	GetCharacterOrders(ctx context.Context, characterID int32) ([]models.CharacterOrder, error)
+}
+
+// CharacterESIClient defines the contract for authenticated character ESI endpoints
+type CharacterESIClient interface {
+	GetCharacterInfo(ctx context.Context, characterID int32) (*models.Character, error)
+	GetCharacterAssets(ctx context.Context, characterID int32, accessToken string) ([]models.CharacterAsset, error)
+	GetCharacterWallet(ctx context.Context, characterID int32, accessToken string) (*models.CharacterWallet, error)
+	GetCharacterOrders(ctx context.Context, characterID int32, accessToken string) ([]models.CharacterOrder, error)
+	GetCharacterSkills(ctx context.Context, characterID int32, accessToken string) (*models.CharacterSkills, error)
+}
+
+// CharacterTokenProvider defines the contract for persisted, refreshed SSO access tokens
+type CharacterTokenProvider interface {
+	ValidToken(ctx context.Context, characterID int32) (*models.AuthToken, error)
+}
+
+// CharacterCache defines the contract for caching per-character data
+type CharacterCache interface {
+	SetCharacterData(key string, data interface{}) error
+	GetCharacterData(key string, dest interface{}) error
+}
+
+// WalletRepository defines the contract for storing synced wallet journals and transactions
+type WalletRepository interface {
+	SaveJournalEntries(characterID int32, entries []models.WalletJournalEntry) (int, error)
+	SaveTransactions(characterID int32, transactions []models.WalletTransaction) (int, error)
+	LatestJournalID(characterID int32) (int64, error)
+	LatestTransactionID(characterID int32) (int64, error)
+	ListJournalEntries(characterID int32) ([]models.WalletJournalEntry, error)
+	ListTransactions(characterID int32) ([]models.WalletTransaction, error)
+}
+
+// WalletESIClient defines the contract for the wallet journal and transaction ESI endpoints
+type WalletESIClient interface {
+	GetWalletJournal(ctx context.Context, characterID int32, accessToken string, page int) ([]models.WalletJournalEntry, int, error)
+	GetWalletTransactions(ctx context.Context, characterID int32, accessToken string, fromID int64) ([]models.WalletTransaction, error)
+}
+
+// CharacterTokenSource defines the contract for background jobs acting on all logged in characters
+type CharacterTokenSource interface {
+	CharacterTokenProvider
+	Characters() ([]*models.AuthToken, error)
+}
+
+// MarketOrderClient defines the contract for fetching the regional order book of one type
+type MarketOrderClient interface {
+	GetMarketOrders(ctx context.Context, regionID int32, typeID int32) ([]models.MarketOrder, error)
+}
+
+// CostBasisProvider defines the contract for the average purchase cost of a character's remaining stock per type
+type CostBasisProvider interface {
+	AverageCosts(characterID int32) (map[int32]float64, error)
+}
+
+// UndercutNotifier defines the contract for reporting newly undercut orders
+type UndercutNotifier interface {
+	NotifyUndercuts(ctx context.Context, undercuts []models.OrderUndercut) error
+}
+
+// ItemLookup defines the contract for resolving type names and categories
+type ItemLookup interface {
+	GetItemByID(typeID int32) (*models.Item, error)
+}
+
+// UniverseRepository defines the contract for SDE solar systems, stargates and NPC stations
+type UniverseRepository interface {
+	GetSolarSystems() ([]*repository.SDESolarSystem, error)
+	GetSolarSystemJumps() ([]repository.SDESolarSystemJump, error)
+	GetStationByID(stationID int64) (*repository.SDEStation, error)
+}
+
+// TradeProfileProvider defines the contract for the skill based trading capability of a character
+type TradeProfileProvider interface {
+	GetTradeProfile(ctx context.Context, characterID int32) (*models.TradeProfile, error)
+}
+
+// MarketSnapshotRepository defines the contract for the market snapshot time-series archive
+type MarketSnapshotRepository interface {
+	SaveSnapshots(snapshots []models.MarketSnapshot) error
+	SaveOrderBook(regionID, typeID int32, timestamp time.Time, orders []models.MarketOrder) error
+	ListSnapshots(regionID, typeID int32, from, to time.Time) ([]models.MarketSnapshot, error)
+	DownsampleSnapshots(resolution int, before time.Time) (int64, error)
+	DeleteSnapshotsBefore(resolution int, before time.Time) (int64, error)
+	DeleteOrderBooksBefore(before time.Time) (int64, error)
+}
+
+// OrderBookHistory defines the contract for reading archived order books
+type OrderBookHistory interface {
+	PreviousOrderBook(regionID, typeID int32, before time.Time) (time.Time, []models.MarketOrder, error)
+}
+
+// OrderEventRepository defines the contract for storing inferred order events
+type OrderEventRepository interface {
+	SaveOrderEvents(events []models.OrderEvent) error
+	ListOrderEvents(regionID, typeID int32, locationID int64, after models.OrderEventCursor, until time.Time, limit int) ([]models.OrderEvent, error)
+	DeleteOrderEventsBefore(before time.Time) (int64, error)
+}
+
+// OrderBookRecorder defines the contract for consumers of freshly archived order books
+type OrderBookRecorder interface {
+	RecordOrderBook(regionID, typeID int32, takenAt time.Time, orders []models.MarketOrder) error
+	Compact(now time.Time) error
+}
+
+// AlertRepository defines the contract for storing alert rules and their trigger state
+type AlertRepository interface {
+	CreateAlertRule(rule *models.AlertRule) (*models.AlertRule, error)
+	UpdateAlertRule(rule *models.AlertRule) (*models.AlertRule, error)
+	DeleteAlertRule(characterID int32, ruleID int64) error
+	GetAlertRule(characterID int32, ruleID int64) (*models.AlertRule, error)
+	ListAlertRules(characterID int32) ([]models.AlertRule, error)
+	ListEnabledAlertRules() ([]models.AlertRule, error)
+	SaveAlertRuleStates(rules []models.AlertRule) error
+}
+
+// UndercutChecker defines the contract for checking the open orders of characters against the market
+type UndercutChecker interface {
+	CheckCharacters(ctx context.Context, characterIDs []int32, all bool) (*UndercutReport, error)
+}
+
+// AlertSink defines the contract for delivering triggered alerts to one notification channel
+type AlertSink interface {
+	SendAlert(ctx context.Context, alert models.AlertNotification) error
+}
+
+// WatchlistRepository defines the contract for storing watchlists and saved item searches
+type WatchlistRepository interface {
+	CreateWatchlist(list *models.Watchlist) (*models.Watchlist, error)
+	UpdateWatchlist(list *models.Watchlist) (*models.Watchlist, error)
+	DeleteWatchlist(characterID int32, watchlistID int64) error
+	GetWatchlist(characterID int32, watchlistID int64) (*models.Watchlist, error)
+	ListWatchlists(characterID int32) ([]models.Watchlist, error)
+	CreateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error)
+	UpdateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error)
+	DeleteSavedSearch(characterID int32, searchID int64) error
+	GetSavedSearch(characterID int32, searchID int64) (*models.SavedSearch, error)
+	ListSavedSearches(characterID int32) ([]models.SavedSearch, error)
+}
+
+// ItemSearcher defines the contract for ranked and filtered item searches
+type ItemSearcher interface {
+	Search(opts ItemSearchOptions) (*ItemSearchResult, error)
+}
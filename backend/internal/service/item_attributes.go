@@ -0,0 +1,179 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// MaxCompareItems limits how many types can be compared at once
+const MaxCompareItems = 10
+
+// GetItemAttributes returns the published dogma attributes of an item including
+// its meta and tech level
+func (s *ItemService) GetItemAttributes(typeID int32) (*models.ItemAttributes, error) {
+	if s.sdeRepo == nil {
+		return nil, fmt.Errorf("SDE repository not available")
+	}
+
+	cacheKey := fmt.Sprintf("attributes_%d", typeID)
+	if s.cacheManager != nil {
+		var cached models.ItemAttributes
+		if err := s.cacheManager.GetSDEData(cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	item, err := s.GetItemByID(typeID)
+	if err != nil {
+		return nil, err
+	}
+
+	sdeAttributes, err := s.sdeRepo.GetTypeAttributes(typeID)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := toItemAttributes(item, sdeAttributes)
+	if s.cacheManager != nil {
+		_ = s.cacheManager.SetSDEData(cacheKey, attributes) // Cache failures only cost performance
+	}
+
+	return attributes, nil
+}
+
+// CompareItems lists the attributes of 2 to MaxCompareItems types side by side.
+// Duplicate type IDs are compared once.
+func (s *ItemService) CompareItems(typeIDs []int32) (*models.ItemComparison, error) {
+	unique := make([]int32, 0, len(typeIDs))
+	seen := make(map[int32]bool, len(typeIDs))
+	for _, typeID := range typeIDs {
+		if typeID <= 0 {
+			return nil, fmt.Errorf("%w: type ID must be positive", ErrInvalidInput)
+		}
+		if !seen[typeID] {
+			seen[typeID] = true
+			unique = append(unique, typeID)
+		}
+	}
+	if len(unique) < 2 || len(unique) > MaxCompareItems {
+		return nil, fmt.Errorf("%w: compare between 2 and %d types", ErrInvalidInput, MaxCompareItems)
+	}
+
+	items := make([]*models.ItemAttributes, 0, len(unique))
+	for _, typeID := range unique {
+		item, err := s.GetItemAttributes(typeID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return compareItemAttributes(items), nil
+}
+
+// toItemAttributes keeps the published attributes and extracts meta and tech level
+func toItemAttributes(item *models.Item, sdeAttributes []*repository.SDEAttribute) *models.ItemAttributes {
+	result := &models.ItemAttributes{
+		TypeID:     item.TypeID,
+		TypeName:   item.TypeName,
+		Attributes: []models.ItemAttribute{},
+	}
+
+	for _, attribute := range sdeAttributes {
+		switch attribute.AttributeID {
+		case repository.AttributeMetaLevel:
+			result.MetaLevel = int32(attribute.Value)
+		case repository.AttributeTechLevel:
+			result.TechLevel = int32(attribute.Value)
+		}
+
+		if !attribute.Published {
+			continue
+		}
+
+		name := attribute.DisplayName
+		if name == "" {
+			name = attribute.AttributeName
+		}
+		result.Attributes = append(result.Attributes, models.ItemAttribute{
+			AttributeID: attribute.AttributeID,
+			Name:        attribute.AttributeName,
+			DisplayName: name,
+			Value:       attribute.Value,
+			Unit:        attribute.UnitName,
+			HighIsGood:  attribute.HighIsGood,
+		})
+	}
+
+	return result
+}
+
+// compareItemAttributes builds one row per attribute that any of the items has
+func compareItemAttributes(items []*models.ItemAttributes) *models.ItemComparison {
+	comparison := &models.ItemComparison{
+		Items:      make([]models.ComparedItem, 0, len(items)),
+		Attributes: []*models.ComparedAttribute{},
+	}
+
+	rows := make(map[int32]*models.ComparedAttribute)
+	for column, item := range items {
+		comparison.Items = append(comparison.Items, models.ComparedItem{
+			TypeID:    item.TypeID,
+			TypeName:  item.TypeName,
+			MetaLevel: item.MetaLevel,
+			TechLevel: item.TechLevel,
+		})
+
+		for _, attribute := range item.Attributes {
+			row, ok := rows[attribute.AttributeID]
+			if !ok {
+				row = &models.ComparedAttribute{
+					AttributeID: attribute.AttributeID,
+					Name:        attribute.Name,
+					DisplayName: attribute.DisplayName,
+					Unit:        attribute.Unit,
+					HighIsGood:  attribute.HighIsGood,
+					Values:      make([]*float64, len(items)),
+				}
+				rows[attribute.AttributeID] = row
+				comparison.Attributes = append(comparison.Attributes, row)
+			}
+			value := attribute.Value
+			row.Values[column] = &value
+		}
+	}
+
+	sort.Slice(comparison.Attributes, func(i, j int) bool {
+		return comparison.Attributes[i].AttributeID < comparison.Attributes[j].AttributeID
+	})
+
+	for _, row := range comparison.Attributes {
+		markBestValue(row, comparison.Items)
+	}
+	return comparison
+}
+
+// markBestValue sets Differs and, if the values differ, the type with the best value.
+// A missing attribute counts as different but never as best.
+func markBestValue(row *models.ComparedAttribute, items []models.ComparedItem) {
+	var best *float64
+	for column, value := range row.Values {
+		if value == nil || (best != nil && *value != *best) {
+			row.Differs = true
+		}
+		if value == nil {
+			continue
+		}
+		if best == nil || (row.HighIsGood && *value > *best) || (!row.HighIsGood && *value < *best) {
+			best = value
+			row.BestTypeID = items[column].TypeID
+		}
+	}
+
+	if !row.Differs {
+		row.BestTypeID = 0
+	}
+}
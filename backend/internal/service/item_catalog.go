@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// GetCategories retrieves all published item categories
+func (s *ItemService) GetCategories() ([]*models.ItemCategory, error) {
+	if s.sdeRepo == nil {
+		return nil, fmt.Errorf("SDE repository not available")
+	}
+
+	sdeCategories, err := s.sdeRepo.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]*models.ItemCategory, 0, len(sdeCategories))
+	for _, sdeCategory := range sdeCategories {
+		categories = append(categories, &models.ItemCategory{
+			CategoryID:   sdeCategory.CategoryID,
+			CategoryName: sdeCategory.CategoryName,
+		})
+	}
+	return categories, nil
+}
+
+// GetGroupsByCategory retrieves all published groups of a category
+func (s *ItemService) GetGroupsByCategory(categoryID int32) ([]*models.ItemGroup, error) {
+	if s.sdeRepo == nil {
+		return nil, fmt.Errorf("SDE repository not available")
+	}
+
+	sdeGroups, err := s.sdeRepo.GetGroupsByCategory(categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*models.ItemGroup, 0, len(sdeGroups))
+	for _, sdeGroup := range sdeGroups {
+		groups = append(groups, &models.ItemGroup{
+			GroupID:    sdeGroup.GroupID,
+			GroupName:  sdeGroup.GroupName,
+			CategoryID: sdeGroup.CategoryID,
+		})
+	}
+	return groups, nil
+}
+
+// GetMarketGroupTree returns the root nodes of the market group hierarchy
+func (s *ItemService) GetMarketGroupTree() ([]*models.MarketGroup, error) {
+	if err := s.loadMarketGroups(); err != nil {
+		return nil, err
+	}
+
+	s.marketGroupMux.RLock()
+	defer s.marketGroupMux.RUnlock()
+	return s.marketGroupTree, nil
+}
+
+// GetMarketGroup returns a single market group node including its subtree
+func (s *ItemService) GetMarketGroup(marketGroupID int32) (*models.MarketGroup, error) {
+	if err := s.loadMarketGroups(); err != nil {
+		return nil, err
+	}
+
+	s.marketGroupMux.RLock()
+	defer s.marketGroupMux.RUnlock()
+
+	node, ok := s.marketGroupIndex[marketGroupID]
+	if !ok {
+		return nil, fmt.Errorf("%w: marketGroupID %d", ErrMarketGroupNotFound, marketGroupID)
+	}
+	return node, nil
+}
+
+// GetMarketGroupTypes lists the published types directly under a market group
+func (s *ItemService) GetMarketGroupTypes(marketGroupID int32) ([]*models.Item, error) {
+	if _, err := s.GetMarketGroup(marketGroupID); err != nil {
+		return nil, err
+	}
+
+	sdeItems, err := s.sdeRepo.GetItemsByMarketGroup(marketGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toModelItems(sdeItems), nil
+}
+
+// loadMarketGroups builds the market group tree once and keeps it in memory
+func (s *ItemService) loadMarketGroups() error {
+	if s.sdeRepo == nil {
+		return fmt.Errorf("SDE repository not available")
+	}
+
+	s.marketGroupMux.RLock()
+	loaded := s.marketGroupIndex != nil
+	s.marketGroupMux.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	sdeMarketGroups, err := s.sdeRepo.GetMarketGroups()
+	if err != nil {
+		return err
+	}
+
+	tree, index := buildMarketGroupTree(sdeMarketGroups)
+
+	s.marketGroupMux.Lock()
+	s.marketGroupTree = tree
+	s.marketGroupIndex = index
+	s.marketGroupMux.Unlock()
+
+	return nil
+}
+
+// buildMarketGroupTree links flat market groups to their parents.
+// Groups whose parent is missing are treated as roots.
+func buildMarketGroupTree(sdeMarketGroups []*repository.SDEMarketGroup) ([]*models.MarketGroup, map[int32]*models.MarketGroup) {
+	index := make(map[int32]*models.MarketGroup, len(sdeMarketGroups))
+	for _, sdeMarketGroup := range sdeMarketGroups {
+		index[sdeMarketGroup.MarketGroupID] = &models.MarketGroup{
+			MarketGroupID: sdeMarketGroup.MarketGroupID,
+			ParentGroupID: sdeMarketGroup.ParentGroupID,
+			Name:          sdeMarketGroup.MarketGroupName,
+			Description:   sdeMarketGroup.Description,
+			HasTypes:      sdeMarketGroup.HasTypes,
+		}
+	}
+
+	var roots []*models.MarketGroup
+	for _, sdeMarketGroup := range sdeMarketGroups {
+		node := index[sdeMarketGroup.MarketGroupID]
+		if parent, ok := index[node.ParentGroupID]; ok && node.ParentGroupID != 0 {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortMarketGroups(roots)
+	for _, node := range index {
+		sortMarketGroups(node.Children)
+	}
+
+	return roots, index
+}
+
+// sortMarketGroups orders sibling nodes by name like the in-game market browser
+func sortMarketGroups(nodes []*models.MarketGroup) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// Search paging limits
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 200
+)
+
+// Ranking tiers added on top of the token relevance score
+const (
+	exactNameBonus  = 3.0
+	namePrefixBonus = 2.0
+)
+
+// ItemSearchOptions describes a ranked item search with filters.
+// Nil Published/Marketable pointers disable the respective filter.
+type ItemSearchOptions struct {
+	Query         string
+	Limit         int
+	Offset        int
+	CategoryID    int32
+	MarketGroupID int32 // Includes all sub groups
+	Published     *bool
+	Marketable    *bool // Types with a market group can be traded on the market
+}
+
+// ItemSearchResult is one page of ranked search results
+type ItemSearchResult struct {
+	Items  []*models.Item `json:"items"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// searchEntry is an indexed type with its normalized name
+type searchEntry struct {
+	item      *models.Item
+	published bool
+	name      string
+	tokens    []string
+}
+
+// itemSearchIndex is an in-memory trigram index over all type names
+type itemSearchIndex struct {
+	entries  []searchEntry
+	trigrams map[string][]int // Trigram -> entry positions, each position at most once
+}
+
+// scoredEntry is a matching entry with its relevance
+type scoredEntry struct {
+	entry *searchEntry
+	score float64
+}
+
+// BuildSearchIndex (re)builds the in-memory search index from the SDE
+func (s *ItemService) BuildSearchIndex() error {
+	if s.sdeRepo == nil {
+		return fmt.Errorf("SDE repository not available")
+	}
+
+	sdeItems, err := s.sdeRepo.GetAllItems()
+	if err != nil {
+		return err
+	}
+
+	index := newItemSearchIndex(sdeItems)
+
+	s.searchMux.Lock()
+	s.searchIndex = index
+	s.searchMux.Unlock()
+
+	return nil
+}
+
+// ReloadSDE drops the market group tree and rebuilds the search index after
+// the SDE repository swapped in a new database
+func (s *ItemService) ReloadSDE() error {
+	s.marketGroupMux.Lock()
+	s.marketGroupTree = nil
+	s.marketGroupIndex = nil
+	s.marketGroupMux.Unlock()
+
+	return s.BuildSearchIndex()
+}
+
+// Search runs a ranked, typo tolerant search. Exact name matches come first,
+// followed by name prefix matches and then by token relevance.
+func (s *ItemService) Search(opts ItemSearchOptions) (*ItemSearchResult, error) {
+	opts, err := normalizeSearchOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := s.getSearchIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var marketGroupIDs map[int32]bool
+	if opts.MarketGroupID != 0 {
+		marketGroup, err := s.GetMarketGroup(opts.MarketGroupID)
+		if err != nil {
+			return nil, err
+		}
+		marketGroupIDs = make(map[int32]bool)
+		collectMarketGroupIDs(marketGroup, marketGroupIDs)
+	}
+
+	matches := index.search(opts.Query, func(entry *searchEntry) bool {
+		return matchesSearchFilters(entry, opts, marketGroupIDs)
+	})
+
+	result := &ItemSearchResult{
+		Items:  []*models.Item{},
+		Total:  len(matches),
+		Limit:  opts.Limit,
+		Offset: opts.Offset,
+	}
+	for i := opts.Offset; i < len(matches) && i < opts.Offset+opts.Limit; i++ {
+		result.Items = append(result.Items, matches[i].entry.item)
+	}
+
+	return result, nil
+}
+
+// getSearchIndex returns the search index, building it on first use
+func (s *ItemService) getSearchIndex() (*itemSearchIndex, error) {
+	s.searchMux.RLock()
+	index := s.searchIndex
+	s.searchMux.RUnlock()
+	if index != nil {
+		return index, nil
+	}
+
+	if err := s.BuildSearchIndex(); err != nil {
+		return nil, err
+	}
+
+	s.searchMux.RLock()
+	defer s.searchMux.RUnlock()
+	return s.searchIndex, nil
+}
+
+// normalizeSearchOptions validates the query and applies paging defaults
+func normalizeSearchOptions(opts ItemSearchOptions) (ItemSearchOptions, error) {
+	if len(normalizeSearchTokens(opts.Query)) == 0 {
+		return opts, fmt.Errorf("%w: search query must contain letters or digits", ErrInvalidInput)
+	}
+	if opts.Limit < 0 || opts.Offset < 0 {
+		return opts, fmt.Errorf("%w: limit and offset must not be negative", ErrInvalidInput)
+	}
+	if opts.CategoryID < 0 || opts.MarketGroupID < 0 {
+		return opts, fmt.Errorf("%w: category and market group IDs must be positive", ErrInvalidInput)
+	}
+
+	if opts.Limit == 0 {
+		opts.Limit = DefaultSearchLimit
+	}
+	if opts.Limit > MaxSearchLimit {
+		opts.Limit = MaxSearchLimit
+	}
+	return opts, nil
+}
+
+// matchesSearchFilters applies category, market group and published/marketable filters
+func matchesSearchFilters(entry *searchEntry, opts ItemSearchOptions, marketGroupIDs map[int32]bool) bool {
+	if opts.CategoryID != 0 && entry.item.CategoryID != opts.CategoryID {
+		return false
+	}
+	if marketGroupIDs != nil && !marketGroupIDs[entry.item.MarketGroup] {
+		return false
+	}
+	if opts.Published != nil && entry.published != *opts.Published {
+		return false
+	}
+	if opts.Marketable != nil && (entry.item.MarketGroup != 0) != *opts.Marketable {
+		return false
+	}
+	return true
+}
+
+// collectMarketGroupIDs adds a market group and all of its descendants to ids
+func collectMarketGroupIDs(node *models.MarketGroup, ids map[int32]bool) {
+	ids[node.MarketGroupID] = true
+	for _, child := range node.Children {
+		collectMarketGroupIDs(child, ids)
+	}
+}
+
+func newItemSearchIndex(sdeItems []*repository.SDEItem) *itemSearchIndex {
+	index := &itemSearchIndex{
+		entries:  make([]searchEntry, 0, len(sdeItems)),
+		trigrams: make(map[string][]int),
+	}
+
+	for _, sdeItem := range sdeItems {
+		tokens := normalizeSearchTokens(sdeItem.TypeName)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		position := len(index.entries)
+		index.entries = append(index.entries, searchEntry{
+			item:      toModelItem(sdeItem),
+			published: sdeItem.Published,
+			name:      strings.Join(tokens, " "),
+			tokens:    tokens,
+		})
+
+		seen := make(map[string]bool)
+		for _, token := range tokens {
+			for _, trigram := range tokenTrigrams(token) {
+				if !seen[trigram] {
+					seen[trigram] = true
+					index.trigrams[trigram] = append(index.trigrams[trigram], position)
+				}
+			}
+		}
+	}
+
+	return index
+}
+
+// search returns all entries matching every query token, best match first
+func (idx *itemSearchIndex) search(query string, filter func(*searchEntry) bool) []scoredEntry {
+	queryTokens := normalizeSearchTokens(query)
+	normalizedQuery := strings.Join(queryTokens, " ")
+
+	var matches []scoredEntry
+	for _, position := range idx.candidates(queryTokens) {
+		entry := &idx.entries[position]
+		if !filter(entry) {
+			continue
+		}
+
+		score, ok := scoreTokens(queryTokens, entry.tokens)
+		if !ok {
+			continue
+		}
+		if entry.name == normalizedQuery {
+			score += exactNameBonus
+		} else if strings.HasPrefix(entry.name, normalizedQuery) {
+			score += namePrefixBonus
+		}
+
+		matches = append(matches, scoredEntry{entry: entry, score: score})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if len(a.entry.name) != len(b.entry.name) {
+			return len(a.entry.name) < len(b.entry.name)
+		}
+		return a.entry.item.TypeName < b.entry.item.TypeName
+	})
+
+	return matches
+}
+
+// candidates collects entries sharing at least one trigram with the query.
+// Tokens too short for a meaningful trigram fall back to a full scan.
+func (idx *itemSearchIndex) candidates(queryTokens []string) []int {
+	for _, token := range queryTokens {
+		if len(token) < 3 {
+			all := make([]int, len(idx.entries))
+			for i := range all {
+				all[i] = i
+			}
+			return all
+		}
+	}
+
+	seen := make(map[int]bool)
+	var positions []int
+	for _, token := range queryTokens {
+		for _, trigram := range tokenTrigrams(token) {
+			for _, position := range idx.trigrams[trigram] {
+				if !seen[position] {
+					seen[position] = true
+					positions = append(positions, position)
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// scoreTokens averages the best match of every query token against the name tokens.
+// Query tokens may appear in any order but each one has to match.
+func scoreTokens(queryTokens, nameTokens []string) (float64, bool) {
+	var total float64
+	for _, queryToken := range queryTokens {
+		best := 0.0
+		for _, nameToken := range nameTokens {
+			if score := scoreToken(queryToken, nameToken); score > best {
+				best = score
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total / float64(len(queryTokens)), true
+}
+
+// scoreToken rates how well a query token matches a single name token
+func scoreToken(queryToken, nameToken string) float64 {
+	switch {
+	case queryToken == nameToken:
+		return 1.0
+	case strings.HasPrefix(nameToken, queryToken):
+		return 0.8 + 0.1*float64(len(queryToken))/float64(len(nameToken))
+	case len(queryToken) >= 3 && strings.Contains(nameToken, queryToken):
+		return 0.6
+	}
+
+	maxTypos := allowedTypos(queryToken)
+	if maxTypos == 0 {
+		return 0
+	}
+	if distance := levenshtein(queryToken, nameToken); distance <= maxTypos {
+		return 0.5 - 0.1*float64(distance)
+	}
+	// Partially typed words with a typo, e.g. "trtan" for "tritanium"
+	queryLength, nameRunes := len([]rune(queryToken)), []rune(nameToken)
+	for length := queryLength - maxTypos; length <= queryLength+maxTypos; length++ {
+		if length < 1 || length >= len(nameRunes) {
+			continue
+		}
+		if distance := levenshtein(queryToken, string(nameRunes[:length])); distance <= maxTypos {
+			return 0.4 - 0.1*float64(distance)
+		}
+	}
+	return 0
+}
+
+// allowedTypos scales the edit distance tolerance with the token length
+func allowedTypos(token string) int {
+	switch {
+	case len(token) < 4:
+		return 0
+	case len(token) < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// normalizeSearchTokens lower-cases text and splits it on everything but letters and digits
+func normalizeSearchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokenTrigrams returns the trigrams of a token padded with word boundaries
+func tokenTrigrams(token string) []string {
+	padded := []rune("$" + token + "$")
+	if len(padded) < 3 {
+		return nil
+	}
+
+	trigrams := make([]string, 0, len(padded)-2)
+	for i := 0; i+3 <= len(padded); i++ {
+		trigrams = append(trigrams, string(padded[i:i+3]))
+	}
+	return trigrams
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
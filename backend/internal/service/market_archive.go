@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Snapshot resolutions in seconds
+const (
+	SnapshotResolutionRaw    = 0
+	SnapshotResolutionHourly = 3600
+)
+
+// Default retention of archived market data
+const (
+	DefaultRawSnapshotRetention    = 48 * time.Hour
+	DefaultHourlySnapshotRetention = 90 * 24 * time.Hour
+	DefaultPriceCurveRange         = 24 * time.Hour
+)
+
+// MarketArchiveResult summarizes one collector run
+type MarketArchiveResult struct {
+	Snapshots  int           `json:"snapshots"`
+	OrderBooks int           `json:"order_books"`
+	Errors     []RegionError `json:"errors,omitempty"`
+	TakenAt    time.Time     `json:"taken_at"`
+}
+
+// RegionError reports a region whose market data could not be loaded
+type RegionError struct {
+	RegionID int32  `json:"region_id"`
+	Error    string `json:"error"`
+}
+
+// MarketArchive periodically snapshots the aggregated prices and optionally the
+// full order books of tracked types, so intraday price curves outlive the market
+// cache. Raw samples are downsampled to hourly buckets once they exceed the raw
+// retention and hourly buckets are dropped after the hourly retention.
+type MarketArchive struct {
+	store     MarketSnapshotRepository
+	market    MarketDataProvider
+	regionIDs []int32
+	typeIDs   []int32
+
+	orderBooks      bool
+	recorder        OrderBookRecorder
+	rawRetention    time.Duration
+	hourlyRetention time.Duration
+}
+
+func NewMarketArchive(store MarketSnapshotRepository, market MarketDataProvider, regionIDs, typeIDs []int32) *MarketArchive {
+	return &MarketArchive{
+		store:           store,
+		market:          market,
+		regionIDs:       regionIDs,
+		typeIDs:         typeIDs,
+		rawRetention:    DefaultRawSnapshotRetention,
+		hourlyRetention: DefaultHourlySnapshotRetention,
+	}
+}
+
+// WithOrderBooks also archives every order behind the aggregated prices.
+// Order books are not downsampled and kept for the raw retention only.
+func (a *MarketArchive) WithOrderBooks() *MarketArchive {
+	a.orderBooks = true
+	return a
+}
+
+// WithOrderFlow archives order books and hands each one to the recorder, which
+// is compacted along with the archive
+func (a *MarketArchive) WithOrderFlow(recorder OrderBookRecorder) *MarketArchive {
+	a.orderBooks = true
+	a.recorder = recorder
+	return a
+}
+
+// WithRetention sets how long raw samples and hourly buckets are kept.
+// Zero or negative durations keep the defaults.
+func (a *MarketArchive) WithRetention(raw, hourly time.Duration) *MarketArchive {
+	if raw > 0 {
+		a.rawRetention = raw
+	}
+	if hourly > 0 {
+		a.hourlyRetention = hourly
+	}
+	return a
+}
+
+// Collect snapshots the tracked types of every tracked region. Regions that
+// fail are reported without stopping the others.
+func (a *MarketArchive) Collect(ctx context.Context) (*MarketArchiveResult, error) {
+	result := &MarketArchiveResult{TakenAt: time.Now()}
+	if len(a.typeIDs) == 0 {
+		return result, nil
+	}
+
+	for _, regionID := range a.regionIDs {
+		data, err := a.market.GetMarketData(ctx, MarketDataRequest{RegionID: regionID, TypeIDs: a.typeIDs})
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, RegionError{RegionID: regionID, Error: err.Error()})
+			continue
+		}
+
+		snapshots := make([]models.MarketSnapshot, 0, len(data.Data))
+		for typeID, price := range data.Data {
+			snapshot := models.MarketSnapshot{
+				RegionID:   regionID,
+				TypeID:     typeID,
+				Timestamp:  sampledAt(price),
+				Samples:    1,
+				BuyMax:     price.BuyMax,
+				SellMin:    price.SellMin,
+				BuyVolume:  price.BuyVolume,
+				SellVolume: price.SellVolume,
+			}
+			for _, order := range data.Orders[typeID] {
+				if order.IsBuyOrder {
+					snapshot.BuyOrders++
+				} else {
+					snapshot.SellOrders++
+				}
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+		if err := a.store.SaveSnapshots(snapshots); err != nil {
+			return nil, err
+		}
+		result.Snapshots += len(snapshots)
+
+		if !a.orderBooks {
+			continue
+		}
+		for typeID, orders := range data.Orders {
+			price, ok := data.Data[typeID]
+			if !ok {
+				continue
+			}
+			takenAt := sampledAt(price)
+			if err := a.store.SaveOrderBook(regionID, typeID, takenAt, orders); err != nil {
+				return nil, err
+			}
+			if a.recorder != nil {
+				if err := a.recorder.RecordOrderBook(regionID, typeID, takenAt, orders); err != nil {
+					return nil, err
+				}
+			}
+			result.OrderBooks++
+		}
+	}
+	return result, nil
+}
+
+// sampledAt is the fetch time of a type's market data, so types served from
+// the market cache are not sampled twice
+func sampledAt(price *models.ItemPrice) time.Time {
+	return price.LastUpdated.Truncate(time.Second)
+}
+
+// Compact applies the retention policy as of now: raw samples older than the raw
+// retention become hourly buckets, which are deleted after the hourly retention
+func (a *MarketArchive) Compact(now time.Time) error {
+	// Only complete hours are downsampled so a bucket never mixes in later samples
+	rawCutoff := now.Add(-a.rawRetention).Truncate(time.Hour)
+	if _, err := a.store.DownsampleSnapshots(SnapshotResolutionHourly, rawCutoff); err != nil {
+		return err
+	}
+	if _, err := a.store.DeleteOrderBooksBefore(now.Add(-a.rawRetention)); err != nil {
+		return err
+	}
+	if _, err := a.store.DeleteSnapshotsBefore(SnapshotResolutionHourly, now.Add(-a.hourlyRetention)); err != nil {
+		return err
+	}
+	if a.recorder != nil {
+		return a.recorder.Compact(now)
+	}
+	return nil
+}
+
+// Start collects and compacts the archive in the background until the context is done
+func (a *MarketArchive) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if result, err := a.Collect(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Printf("Warning: market snapshot failed: %v\n", err)
+			} else if result != nil {
+				for _, regionErr := range result.Errors {
+					fmt.Printf("Warning: market snapshot of region %d failed: %s\n", regionErr.RegionID, regionErr.Error)
+				}
+			}
+			if err := a.Compact(time.Now()); err != nil {
+				fmt.Printf("Warning: market archive compaction failed: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Tracks reports whether the collector archives a type in a region
+func (a *MarketArchive) Tracks(regionID, typeID int32) bool {
+	return containsID(a.regionIDs, regionID) && containsID(a.typeIDs, typeID)
+}
+
+// PriceCurve returns the archived prices of a type within [from, to). A positive
+// interval averages the stored points into buckets of that length, otherwise the
+// raw samples and hourly buckets are returned as stored.
+func (a *MarketArchive) PriceCurve(regionID, typeID int32, from, to time.Time, interval time.Duration) (*models.PriceCurve, error) {
+	if regionID <= 0 || typeID <= 0 {
+		return nil, fmt.Errorf("%w: region and type are required", ErrInvalidInput)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	if interval < 0 || (interval > 0 && interval < time.Minute) {
+		return nil, fmt.Errorf("%w: the interval must be at least one minute", ErrInvalidInput)
+	}
+
+	snapshots, err := a.store.ListSnapshots(regionID, typeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := &models.PriceCurve{
+		RegionID: regionID,
+		TypeID:   typeID,
+		From:     from.UTC(),
+		To:       to.UTC(),
+		Interval: int(interval / time.Second),
+		Tracked:  a.Tracks(regionID, typeID),
+		Points:   []models.PricePoint{},
+	}
+	if interval == 0 {
+		for _, snapshot := range snapshots {
+			curve.Points = append(curve.Points, pricePoint(snapshot.Timestamp, []models.MarketSnapshot{snapshot}))
+		}
+		return curve, nil
+	}
+
+	var bucket []models.MarketSnapshot
+	var bucketStart time.Time
+	for _, snapshot := range snapshots {
+		start := snapshot.Timestamp.Truncate(interval)
+		if len(bucket) > 0 && !start.Equal(bucketStart) {
+			curve.Points = append(curve.Points, pricePoint(bucketStart, bucket))
+			bucket = bucket[:0]
+		}
+		bucketStart = start
+		bucket = append(bucket, snapshot)
+	}
+	if len(bucket) > 0 {
+		curve.Points = append(curve.Points, pricePoint(bucketStart, bucket))
+	}
+	return curve, nil
+}
+
+// pricePoint averages snapshots weighted by their samples. Sides without
+// orders do not lower the average price.
+func pricePoint(timestamp time.Time, snapshots []models.MarketSnapshot) models.PricePoint {
+	point := models.PricePoint{Timestamp: timestamp.UTC()}
+	var buySamples, sellSamples int
+	var buySum, sellSum, buyVolume, sellVolume float64
+	for _, snapshot := range snapshots {
+		samples := max(snapshot.Samples, 1)
+		point.Samples += samples
+		if snapshot.BuyMax > 0 {
+			buySum += snapshot.BuyMax * float64(samples)
+			buySamples += samples
+		}
+		if snapshot.SellMin > 0 {
+			sellSum += snapshot.SellMin * float64(samples)
+			sellSamples += samples
+		}
+		buyVolume += float64(snapshot.BuyVolume) * float64(samples)
+		sellVolume += float64(snapshot.SellVolume) * float64(samples)
+	}
+
+	if buySamples > 0 {
+		point.BuyMax = buySum / float64(buySamples)
+	}
+	if sellSamples > 0 {
+		point.SellMin = sellSum / float64(sellSamples)
+	}
+	if point.Samples > 0 {
+		point.BuyVolume = int64(buyVolume / float64(point.Samples))
+		point.SellVolume = int64(sellVolume / float64(point.Samples))
+	}
+	if point.BuyMax > 0 && point.SellMin > 0 {
+		point.Spread = (point.SellMin - point.BuyMax) / point.SellMin
+	}
+	return point
+}
+
+func containsID(ids []int32, id int32) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
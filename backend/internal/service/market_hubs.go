@@ -0,0 +1,71 @@
+package service
+
+import "strings"
+
+// Well-known region IDs of the main trade hubs
+const (
+	RegionTheForge   int32 = 10000002
+	RegionDomain     int32 = 10000043
+	RegionSinqLaison int32 = 10000032
+	RegionHeimatar   int32 = 10000030
+	RegionMetropolis int32 = 10000042
+	DefaultHubRegion int32 = RegionTheForge
+)
+
+// Base trading fees without skills or standings
+const (
+	DefaultSalesTax  = 0.075
+	DefaultBrokerFee = 0.03
+)
+
+// MarketHub identifies the trade hub station of a region.
+// A StationID of 0 means all stations of the region are considered.
+type MarketHub struct {
+	Name      string `json:"name"`
+	RegionID  int32  `json:"region_id"`
+	StationID int64  `json:"station_id,omitempty"`
+}
+
+// DefaultMarketHubs are the five main empire trade hubs
+var DefaultMarketHubs = []MarketHub{
+	{Name: "Jita", RegionID: RegionTheForge, StationID: 60003760},
+	{Name: "Amarr", RegionID: RegionDomain, StationID: 60008494},
+	{Name: "Dodixie", RegionID: RegionSinqLaison, StationID: 60011866},
+	{Name: "Rens", RegionID: RegionHeimatar, StationID: 60004588},
+	{Name: "Hek", RegionID: RegionMetropolis, StationID: 60005686},
+}
+
+// HubsForRegions resolves region IDs to market hubs.
+// Regions without a known hub station fall back to the whole region.
+func HubsForRegions(regionIDs []int32) []MarketHub {
+	hubs := make([]MarketHub, 0, len(regionIDs))
+	for _, regionID := range regionIDs {
+		hubs = append(hubs, HubForRegion(regionID))
+	}
+	return hubs
+}
+
+// HubForRegion returns the known trade hub of a region or a region-wide pseudo hub
+func HubForRegion(regionID int32) MarketHub {
+	for _, hub := range DefaultMarketHubs {
+		if hub.RegionID == regionID {
+			return hub
+		}
+	}
+	return MarketHub{RegionID: regionID}
+}
+
+// HubByName looks up one of the default hubs by name, ignoring case
+func HubByName(name string) (MarketHub, bool) {
+	for _, hub := range DefaultMarketHubs {
+		if strings.EqualFold(hub.Name, name) {
+			return hub, true
+		}
+	}
+	return MarketHub{}, false
+}
+
+// Contains reports whether an order location belongs to the hub
+func (h MarketHub) Contains(locationID int64) bool {
+	return h.StationID == 0 || h.StationID == locationID
+}
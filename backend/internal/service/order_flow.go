@@ -0,0 +1,302 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Limits of the order flow queries
+const (
+	DefaultOrderEventRetention = 30 * 24 * time.Hour
+	DefaultOrderEventLimit     = 500
+	MaxOrderEventLimit         = 5000
+	MaxOrderFlowRange          = 30 * 24 * time.Hour
+)
+
+// OrderFlowService infers order events from consecutive archived order books.
+// Order books are handed over by the market archive right after they were saved.
+type OrderFlowService struct {
+	books     OrderBookHistory
+	events    OrderEventRepository
+	retention time.Duration
+}
+
+func NewOrderFlowService(books OrderBookHistory, events OrderEventRepository) *OrderFlowService {
+	return &OrderFlowService{
+		books:     books,
+		events:    events,
+		retention: DefaultOrderEventRetention,
+	}
+}
+
+// WithRetention sets how long order events are kept, zero keeps the default
+func (s *OrderFlowService) WithRetention(retention time.Duration) *OrderFlowService {
+	if retention > 0 {
+		s.retention = retention
+	}
+	return s
+}
+
+// RecordOrderBook diffs an order book against the previous archived book of the
+// same type and stores the resulting events. The first book of a type only
+// becomes the baseline. Recording the same book twice stores no duplicates.
+func (s *OrderFlowService) RecordOrderBook(regionID, typeID int32, takenAt time.Time, orders []models.MarketOrder) error {
+	previousAt, previous, err := s.books.PreviousOrderBook(regionID, typeID, takenAt)
+	if err != nil {
+		return err
+	}
+	if previousAt.IsZero() {
+		return nil
+	}
+
+	events := DiffOrderBooks(previous, orders, takenAt)
+	for i := range events {
+		events[i].RegionID = regionID
+		events[i].TypeID = typeID
+	}
+	return s.events.SaveOrderEvents(events)
+}
+
+// Compact drops order events older than the retention
+func (s *OrderFlowService) Compact(now time.Time) error {
+	_, err := s.events.DeleteOrderEventsBefore(now.Add(-s.retention))
+	return err
+}
+
+// Events returns a page of the order events of a type, oldest first. The page starts
+// behind the cursor of a previous page or, without cursor, after `since`. Clients
+// follow the stream by passing the returned next cursor.
+func (s *OrderFlowService) Events(regionID, typeID int32, locationID int64, since time.Time, cursor string, limit int) (*models.OrderEventPage, error) {
+	if regionID <= 0 || typeID <= 0 {
+		return nil, fmt.Errorf("%w: region and type are required", ErrInvalidInput)
+	}
+	if limit <= 0 {
+		limit = DefaultOrderEventLimit
+	}
+	limit = min(limit, MaxOrderEventLimit)
+
+	after := models.OrderEventCursor{Timestamp: since}
+	if cursor != "" {
+		var err error
+		if after, err = ParseOrderEventCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// One extra event tells whether the stream continues behind this page
+	events, err := s.events.ListOrderEvents(regionID, typeID, locationID, after, time.Now(), limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.OrderEventPage{Events: events, HasMore: len(events) > limit}
+	if page.HasMore {
+		page.Events = events[:limit]
+	}
+	if len(page.Events) > 0 {
+		last := page.Events[len(page.Events)-1]
+		after = models.OrderEventCursor{Timestamp: last.Timestamp, OrderID: last.OrderID, EventType: last.Type}
+	}
+	page.Next = FormatOrderEventCursor(after)
+	return page, nil
+}
+
+// FormatOrderEventCursor encodes a cursor as an opaque URL-safe token
+func FormatOrderEventCursor(cursor models.OrderEventCursor) string {
+	raw := fmt.Sprintf("%d:%d:%s", cursor.Timestamp.Unix(), cursor.OrderID, cursor.EventType)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseOrderEventCursor decodes a token of FormatOrderEventCursor
+func ParseOrderEventCursor(token string) (models.OrderEventCursor, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidInput)
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return models.OrderEventCursor{}, invalid
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 {
+		return models.OrderEventCursor{}, invalid
+	}
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return models.OrderEventCursor{}, invalid
+	}
+	orderID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || orderID < 0 {
+		return models.OrderEventCursor{}, invalid
+	}
+
+	return models.OrderEventCursor{Timestamp: time.Unix(timestamp, 0).UTC(), OrderID: orderID, EventType: parts[2]}, nil
+}
+
+// Velocity estimates the traded volume within (from, to] per hour from the fills
+// between order book snapshots. Hours without fills are reported as zero.
+func (s *OrderFlowService) Velocity(regionID, typeID int32, locationID int64, from, to time.Time) (*models.OrderFlowReport, error) {
+	if regionID <= 0 || typeID <= 0 {
+		return nil, fmt.Errorf("%w: region and type are required", ErrInvalidInput)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	if to.Sub(from) > MaxOrderFlowRange {
+		return nil, fmt.Errorf("%w: the range must not exceed %d days", ErrInvalidInput, int(MaxOrderFlowRange.Hours()/24))
+	}
+
+	events, err := s.events.ListOrderEvents(regionID, typeID, locationID, models.OrderEventCursor{Timestamp: from}, to, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.OrderFlowReport{
+		RegionID:   regionID,
+		TypeID:     typeID,
+		LocationID: locationID,
+		From:       from.UTC(),
+		To:         to.UTC(),
+		Hourly:     []models.HourlyTradeVolume{},
+	}
+	hourly := make(map[int64]*models.HourlyTradeVolume)
+	for hour := from.UTC().Truncate(time.Hour); hour.Before(to); hour = hour.Add(time.Hour) {
+		report.Hourly = append(report.Hourly, models.HourlyTradeVolume{Hour: hour})
+	}
+	for i := range report.Hourly {
+		hourly[report.Hourly[i].Hour.Unix()] = &report.Hourly[i]
+	}
+
+	for _, event := range events {
+		switch event.Type {
+		case models.OrderEventNew:
+			report.NewOrders++
+			continue
+		case models.OrderEventModified:
+			report.ModifiedOrders++
+			continue
+		case models.OrderEventCancelled:
+			report.CancelledOrders++
+			continue
+		case models.OrderEventExpired:
+			report.ExpiredOrders++
+			continue
+		}
+
+		// Fills happened some time before the snapshot that revealed them
+		bucket := hourly[event.Timestamp.Add(-time.Second).Truncate(time.Hour).Unix()]
+		value := event.Price * float64(event.Volume)
+		if event.IsBuyOrder {
+			report.BoughtUnits += event.Volume
+			report.BoughtValue += value
+		} else {
+			report.SoldUnits += event.Volume
+			report.SoldValue += value
+		}
+		if bucket == nil {
+			continue
+		}
+		bucket.Fills++
+		if event.IsBuyOrder {
+			bucket.BoughtUnits += event.Volume
+			bucket.BoughtValue += value
+		} else {
+			bucket.SoldUnits += event.Volume
+			bucket.SoldValue += value
+		}
+	}
+
+	hours := to.Sub(from).Hours()
+	report.SellVelocity = float64(report.SoldUnits) / hours
+	report.BuyVelocity = float64(report.BoughtUnits) / hours
+	return report, nil
+}
+
+// DiffOrderBooks compares two order books of one type by order ID. Vanished
+// orders count as filled if they held the best price of their side at their
+// station, as expired if their duration ran out and as cancelled otherwise.
+// Both fills and modifications of one order are reported when both happened.
+func DiffOrderBooks(previous, current []models.MarketOrder, takenAt time.Time) []models.OrderEvent {
+	currentByID := make(map[int64]models.MarketOrder, len(current))
+	for _, order := range current {
+		currentByID[order.OrderID] = order
+	}
+	previousByID := make(map[int64]models.MarketOrder, len(previous))
+	for _, order := range previous {
+		previousByID[order.OrderID] = order
+	}
+	best := bestStationPrices(previous)
+
+	var events []models.OrderEvent
+	newEvent := func(order models.MarketOrder, eventType string, volume int64) models.OrderEvent {
+		return models.OrderEvent{
+			TypeID:     order.TypeID,
+			OrderID:    order.OrderID,
+			Type:       eventType,
+			IsBuyOrder: order.IsBuyOrder,
+			LocationID: order.LocationID,
+			Price:      order.Price,
+			Volume:     volume,
+			Timestamp:  takenAt,
+		}
+	}
+
+	for _, before := range previous {
+		after, ok := currentByID[before.OrderID]
+		if !ok {
+			remaining := int64(before.VolumeRemain)
+			expiresAt := before.Issued.Add(time.Duration(before.Duration) * 24 * time.Hour)
+			switch {
+			case best[stationSide{before.LocationID, before.IsBuyOrder}] == before.Price:
+				events = append(events, newEvent(before, models.OrderEventFilled, remaining))
+			case before.Duration > 0 && !expiresAt.After(takenAt):
+				events = append(events, newEvent(before, models.OrderEventExpired, remaining))
+			default:
+				events = append(events, newEvent(before, models.OrderEventCancelled, remaining))
+			}
+			continue
+		}
+
+		if traded := before.VolumeRemain - after.VolumeRemain; traded > 0 {
+			fill := newEvent(before, models.OrderEventPartialFill, int64(traded))
+			events = append(events, fill)
+		}
+		if after.Price != before.Price {
+			modified := newEvent(after, models.OrderEventModified, int64(after.VolumeRemain))
+			modified.PreviousPrice = before.Price
+			events = append(events, modified)
+		}
+	}
+
+	for _, order := range current {
+		if _, ok := previousByID[order.OrderID]; !ok {
+			events = append(events, newEvent(order, models.OrderEventNew, int64(order.VolumeRemain)))
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].OrderID < events[j].OrderID })
+	return events
+}
+
+// stationSide identifies the buy or sell side of the market at one station
+type stationSide struct {
+	locationID int64
+	isBuyOrder bool
+}
+
+// bestStationPrices returns the highest buy and lowest sell price per station
+func bestStationPrices(orders []models.MarketOrder) map[stationSide]float64 {
+	best := make(map[stationSide]float64)
+	for _, order := range orders {
+		side := stationSide{order.LocationID, order.IsBuyOrder}
+		price, ok := best[side]
+		if !ok || (order.IsBuyOrder && order.Price > price) || (!order.IsBuyOrder && order.Price < price) {
+			best[side] = order.Price
+		}
+	}
+	return best
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// Price stream defaults and limits
+const (
+	DefaultPriceStreamBuffer = 256
+	MaxPriceStreamKeys       = 500
+)
+
+// ErrSlowSubscriber closes subscriptions whose buffer ran full
+var ErrSlowSubscriber = errors.New("subscriber did not keep up with price updates")
+
+// PriceKey identifies the price of one type in one region
+type PriceKey struct {
+	RegionID int32 `json:"region_id"`
+	TypeID   int32 `json:"type_id"`
+}
+
+// PriceSubscription receives the price updates of its keys until it is closed
+type PriceSubscription struct {
+	keys    []PriceKey
+	updates chan models.PriceUpdate
+	done    chan struct{}
+	err     error
+	sent    map[PriceKey]time.Time // LastUpdated of the last delivered price per key
+}
+
+// Updates delivers the current prices right after subscribing and every newer price afterwards
+func (s *PriceSubscription) Updates() <-chan models.PriceUpdate {
+	return s.updates
+}
+
+// Done is closed when the subscription ends
+func (s *PriceSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the stream closed the subscription, nil after unsubscribing.
+// Only valid once Done is closed.
+func (s *PriceSubscription) Err() error {
+	return s.err
+}
+
+// Keys returns the subscribed region and type pairs
+func (s *PriceSubscription) Keys() []PriceKey {
+	return s.keys
+}
+
+// PriceStream pushes refreshed ItemPrices to subscribers of (region, type)
+// pairs. The refresher loads all subscribed types of a region with one market
+// data request, and every fresh response of the market service is fanned out
+// to all subscribers of its types, so subscribers never cause extra ESI
+// requests of their own. Each subscription buffers a bounded number of updates,
+// subscribers that fall behind are closed instead of slowing down the others.
+type PriceStream struct {
+	market MarketDataProvider
+	items  ItemLookup
+	buffer int
+
+	mu          sync.Mutex
+	subscribers map[PriceKey]map[*PriceSubscription]struct{}
+}
+
+func NewPriceStream(market MarketDataProvider) *PriceStream {
+	return &PriceStream{
+		market:      market,
+		buffer:      DefaultPriceStreamBuffer,
+		subscribers: make(map[PriceKey]map[*PriceSubscription]struct{}),
+	}
+}
+
+// WithBuffer sets how many updates a subscription may lag behind before it is
+// closed, on top of one update per subscribed key. Zero or negative sizes keep the default.
+func (p *PriceStream) WithBuffer(size int) *PriceStream {
+	if size > 0 {
+		p.buffer = size
+	}
+	return p
+}
+
+// WithItems rejects subscriptions to unknown types and types not traded on the market
+func (p *PriceStream) WithItems(items ItemLookup) *PriceStream {
+	p.items = items
+	return p
+}
+
+// Subscribe registers a subscription for the given keys and queues their
+// current prices. The subscription ends when ctx is done or on Unsubscribe.
+func (p *PriceStream) Subscribe(ctx context.Context, keys []PriceKey) (*PriceSubscription, error) {
+	unique := make([]PriceKey, 0, len(keys))
+	seen := make(map[PriceKey]bool, len(keys))
+	for _, key := range keys {
+		if key.RegionID <= 0 || key.TypeID <= 0 {
+			return nil, fmt.Errorf("%w: invalid region %d or type %d", ErrInvalidInput, key.RegionID, key.TypeID)
+		}
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("%w: no types to subscribe to", ErrInvalidInput)
+	}
+	if len(unique) > MaxPriceStreamKeys {
+		return nil, fmt.Errorf("%w: at most %d types per subscription", ErrInvalidInput, MaxPriceStreamKeys)
+	}
+	validated := make(map[int32]bool, len(unique))
+	for _, key := range unique {
+		if validated[key.TypeID] {
+			continue
+		}
+		if err := validateMarketType(p.items, key.TypeID); err != nil {
+			return nil, err
+		}
+		validated[key.TypeID] = true
+	}
+
+	sub := &PriceSubscription{
+		keys:    unique,
+		updates: make(chan models.PriceUpdate, p.buffer+len(unique)),
+		done:    make(chan struct{}),
+		sent:    make(map[PriceKey]time.Time, len(unique)),
+	}
+	p.mu.Lock()
+	for _, key := range unique {
+		if p.subscribers[key] == nil {
+			p.subscribers[key] = make(map[*PriceSubscription]struct{})
+		}
+		p.subscribers[key][sub] = struct{}{}
+	}
+	p.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.Unsubscribe(sub)
+		case <-sub.done:
+		}
+	}()
+
+	// Current prices, usually from the market cache. Failed types follow with the next refresh.
+	for regionID, typeIDs := range groupPriceKeys(unique) {
+		_ = p.refreshRegion(ctx, regionID, typeIDs)
+	}
+	return sub, nil
+}
+
+// Unsubscribe ends a subscription
+func (p *PriceStream) Unsubscribe(sub *PriceSubscription) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.close(sub, nil)
+}
+
+// Close ends all subscriptions, e.g. when the server shuts down
+func (p *PriceStream) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, subs := range p.subscribers {
+		for sub := range subs {
+			p.close(sub, nil)
+		}
+	}
+}
+
+// Publish delivers the prices of a market data response to their subscribers.
+// Prices not newer than the last one delivered to a subscriber are skipped.
+func (p *PriceStream) Publish(data *MarketDataResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for typeID, price := range data.Data {
+		key := PriceKey{RegionID: data.RegionID, TypeID: typeID}
+		for sub := range p.subscribers[key] {
+			if sent, ok := sub.sent[key]; ok && !price.LastUpdated.After(sent) {
+				continue
+			}
+			select {
+			case sub.updates <- models.PriceUpdate{RegionID: data.RegionID, ItemPrice: *price}:
+				sub.sent[key] = price.LastUpdated
+			default:
+				p.close(sub, ErrSlowSubscriber)
+			}
+		}
+	}
+}
+
+// close removes a subscription from all keys, the caller holds p.mu
+func (p *PriceStream) close(sub *PriceSubscription, reason error) {
+	select {
+	case <-sub.done:
+		return
+	default:
+	}
+
+	for _, key := range sub.keys {
+		delete(p.subscribers[key], sub)
+		if len(p.subscribers[key]) == 0 {
+			delete(p.subscribers, key)
+		}
+	}
+	sub.err = reason
+	close(sub.done)
+}
+
+// Refresh loads the prices of all subscribed types with one request per region
+// and publishes them. Types that fail are retried on the next refresh.
+func (p *PriceStream) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	keys := make([]PriceKey, 0, len(p.subscribers))
+	for key := range p.subscribers {
+		keys = append(keys, key)
+	}
+	p.mu.Unlock()
+
+	var errs []error
+	for regionID, typeIDs := range groupPriceKeys(keys) {
+		if err := p.refreshRegion(ctx, regionID, typeIDs); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			errs = append(errs, fmt.Errorf("region %d: %w", regionID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refreshRegion publishes the prices of the given types of one region. One failing
+// type fails a whole market data request, so the types are then requested one by
+// one and only the types still failing are reported.
+func (p *PriceStream) refreshRegion(ctx context.Context, regionID int32, typeIDs []int32) error {
+	data, err := p.market.GetMarketData(ctx, MarketDataRequest{RegionID: regionID, TypeIDs: typeIDs})
+	if err == nil {
+		p.Publish(data)
+		return nil
+	}
+	if len(typeIDs) == 1 || ctx.Err() != nil {
+		return err
+	}
+
+	jobs := make(chan int32)
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for i := 0; i < min(orderBookFetchWorkers, len(typeIDs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for typeID := range jobs {
+				typeData, err := p.market.GetMarketData(ctx, MarketDataRequest{RegionID: regionID, TypeIDs: []int32{typeID}})
+				if err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("type %d: %w", typeID, err))
+					mu.Unlock()
+					continue
+				}
+				p.Publish(typeData)
+			}
+		}()
+	}
+	for _, typeID := range typeIDs {
+		jobs <- typeID
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// Start refreshes the subscribed prices in the background
+func (p *PriceStream) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := p.Refresh(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Printf("Warning: price stream refresh failed: %v\n", err)
+			}
+		}
+	}()
+}
+
+// groupPriceKeys returns the sorted type IDs per region, sorted so that equal
+// sets share one market cache entry
+func groupPriceKeys(keys []PriceKey) map[int32][]int32 {
+	regions := make(map[int32][]int32)
+	for _, key := range keys {
+		regions[key.RegionID] = append(regions[key.RegionID], key.TypeID)
+	}
+	for _, typeIDs := range regions {
+		sort.Slice(typeIDs, func(i, j int) bool { return typeIDs[i] < typeIDs[j] })
+	}
+	return regions
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// CostMethod decides which purchases a sale is matched against
+type CostMethod string
+
+const (
+	CostMethodFIFO    CostMethod = "fifo"    // Oldest purchases are sold first
+	CostMethodAverage CostMethod = "average" // Every sale costs the running average purchase price
+)
+
+// Journal reference types that carry trading fees
+const (
+	journalRefBrokerFee      = "brokers_fee"
+	journalRefTransactionTax = "transaction_tax"
+	contextMarketTransaction = "market_transaction_id"
+)
+
+// ParseCostMethod parses a cost method, defaulting to FIFO
+func ParseCostMethod(value string) (CostMethod, error) {
+	switch CostMethod(value) {
+	case "", CostMethodFIFO:
+		return CostMethodFIFO, nil
+	case CostMethodAverage:
+		return CostMethodAverage, nil
+	}
+	return "", fmt.Errorf("%w: unknown cost method %q, use fifo or average", ErrInvalidInput, value)
+}
+
+// costLot is a purchase that has not been sold completely
+type costLot struct {
+	quantity int64
+	unitCost float64
+}
+
+// inventory tracks the remaining purchases of one type
+type inventory struct {
+	lots     []costLot // FIFO only
+	quantity int64
+	cost     float64
+}
+
+func (inv *inventory) add(quantity int64, unitCost float64) {
+	inv.lots = append(inv.lots, costLot{quantity: quantity, unitCost: unitCost})
+	inv.quantity += quantity
+	inv.cost += float64(quantity) * unitCost
+}
+
+// consume removes up to quantity units and returns how many were available and their cost
+func (inv *inventory) consume(method CostMethod, quantity int64) (int64, float64) {
+	matched := min(quantity, inv.quantity)
+	if matched <= 0 {
+		return 0, 0
+	}
+
+	var cost float64
+	if method == CostMethodAverage {
+		cost = inv.cost / float64(inv.quantity) * float64(matched)
+	} else {
+		remaining := matched
+		for remaining > 0 {
+			lot := &inv.lots[0]
+			used := min(remaining, lot.quantity)
+			cost += float64(used) * lot.unitCost
+			lot.quantity -= used
+			remaining -= used
+			if lot.quantity == 0 {
+				inv.lots = inv.lots[1:]
+			}
+		}
+	}
+
+	inv.quantity -= matched
+	inv.cost -= cost
+	if inv.quantity == 0 {
+		inv.cost = 0 // Avoid float residue on an empty stock
+	}
+	return matched, cost
+}
+
+// CalculateProfitLoss matches sales against purchases per type. Sales taxes and
+// broker fees are taken from the journal and assigned to the transaction they
+// reference: buy side fees raise the purchase cost, sell side fees lower the
+// result. Broker fees without a known transaction (e.g. order modifications)
+// count against the day they were paid. Sold units without a known purchase
+// are reported as unmatched and left out of the result.
+func CalculateProfitLoss(transactions []models.WalletTransaction, journal []models.WalletJournalEntry, method CostMethod) *models.ProfitLossReport {
+	sorted := make([]models.WalletTransaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Date.Equal(sorted[j].Date) {
+			return sorted[i].Date.Before(sorted[j].Date)
+		}
+		return sorted[i].TransactionID < sorted[j].TransactionID
+	})
+
+	known := make(map[int64]bool, len(sorted))
+	for _, transaction := range sorted {
+		known[transaction.TransactionID] = true
+	}
+
+	report := &models.ProfitLossReport{Method: string(method), GeneratedAt: time.Now()}
+	taxes := make(map[int64]float64)
+	brokerFees := make(map[int64]float64)
+	byDay := make(map[string]*models.DailyProfitLoss)
+
+	for _, entry := range journal {
+		fee := -entry.Amount // Fees are booked as negative amounts
+		linked := entry.ContextIDType == contextMarketTransaction && known[entry.ContextID]
+		switch {
+		case entry.RefType == journalRefTransactionTax && linked:
+			taxes[entry.ContextID] += fee
+		case entry.RefType == journalRefBrokerFee && linked:
+			brokerFees[entry.ContextID] += fee
+		case entry.RefType == journalRefBrokerFee:
+			report.UnallocatedBrokerFees += fee
+			day := dailyProfitLoss(byDay, entry.Date)
+			day.BrokerFees += fee
+			day.Profit -= fee
+		}
+	}
+
+	stock := make(map[int32]*inventory)
+	byItem := make(map[int32]*models.ItemProfitLoss)
+	byStation := make(map[int64]*models.StationProfitLoss)
+
+	for _, transaction := range sorted {
+		quantity := int64(transaction.Quantity)
+		if quantity <= 0 {
+			continue
+		}
+		inv, ok := stock[transaction.TypeID]
+		if !ok {
+			inv = &inventory{}
+			stock[transaction.TypeID] = inv
+		}
+
+		if transaction.IsBuy {
+			inv.add(quantity, transaction.UnitPrice+brokerFees[transaction.TransactionID]/float64(quantity))
+			continue
+		}
+
+		item, ok := byItem[transaction.TypeID]
+		if !ok {
+			item = &models.ItemProfitLoss{TypeID: transaction.TypeID}
+			byItem[transaction.TypeID] = item
+		}
+
+		matched, cost := inv.consume(method, quantity)
+		item.UnmatchedQuantity += quantity - matched
+		if matched == 0 {
+			continue
+		}
+
+		// Fees of partly matched sales are split like the quantity
+		share := float64(matched) / float64(quantity)
+		sale := models.ProfitLoss{
+			Quantity:   matched,
+			Revenue:    transaction.UnitPrice * float64(matched),
+			Cost:       cost,
+			SalesTax:   taxes[transaction.TransactionID] * share,
+			BrokerFees: brokerFees[transaction.TransactionID] * share,
+		}
+		sale.Profit = sale.Revenue - sale.Cost - sale.SalesTax - sale.BrokerFees
+
+		addProfitLoss(&item.ProfitLoss, sale)
+		addProfitLoss(&dailyProfitLoss(byDay, transaction.Date).ProfitLoss, sale)
+		station, ok := byStation[transaction.LocationID]
+		if !ok {
+			station = &models.StationProfitLoss{LocationID: transaction.LocationID}
+			byStation[transaction.LocationID] = station
+		}
+		addProfitLoss(&station.ProfitLoss, sale)
+	}
+
+	report.ByItem = make([]models.ItemProfitLoss, 0, len(byItem))
+	for _, item := range byItem {
+		report.ByItem = append(report.ByItem, *item)
+	}
+	sort.Slice(report.ByItem, func(i, j int) bool {
+		if report.ByItem[i].Profit != report.ByItem[j].Profit {
+			return report.ByItem[i].Profit > report.ByItem[j].Profit
+		}
+		return report.ByItem[i].TypeID < report.ByItem[j].TypeID
+	})
+
+	report.ByDay = make([]models.DailyProfitLoss, 0, len(byDay))
+	for _, day := range byDay {
+		report.ByDay = append(report.ByDay, *day)
+		addProfitLoss(&report.Realized, day.ProfitLoss)
+	}
+	sort.Slice(report.ByDay, func(i, j int) bool { return report.ByDay[i].Date < report.ByDay[j].Date })
+
+	report.ByStation = make([]models.StationProfitLoss, 0, len(byStation))
+	for _, station := range byStation {
+		report.ByStation = append(report.ByStation, *station)
+	}
+	sort.Slice(report.ByStation, func(i, j int) bool {
+		if report.ByStation[i].Profit != report.ByStation[j].Profit {
+			return report.ByStation[i].Profit > report.ByStation[j].Profit
+		}
+		return report.ByStation[i].LocationID < report.ByStation[j].LocationID
+	})
+
+	report.Unrealized = []models.UnrealizedPosition{}
+	for typeID, inv := range stock {
+		if inv.quantity <= 0 {
+			continue
+		}
+		report.Unrealized = append(report.Unrealized, models.UnrealizedPosition{
+			TypeID:      typeID,
+			Quantity:    inv.quantity,
+			CostBasis:   inv.cost,
+			AverageCost: inv.cost / float64(inv.quantity),
+		})
+	}
+	sort.Slice(report.Unrealized, func(i, j int) bool { return report.Unrealized[i].TypeID < report.Unrealized[j].TypeID })
+
+	return report
+}
+
+// ApplyMarketPrices values the unrealized positions with the given prices per type
+func ApplyMarketPrices(report *models.ProfitLossReport, prices map[int32]float64) {
+	report.UnrealizedProfit = 0
+	for i := range report.Unrealized {
+		position := &report.Unrealized[i]
+		price, ok := prices[position.TypeID]
+		if !ok || price <= 0 {
+			continue
+		}
+		position.Priced = true
+		position.MarketPrice = price
+		position.MarketValue = price * float64(position.Quantity)
+		position.Profit = position.MarketValue - position.CostBasis
+		report.UnrealizedProfit += position.Profit
+	}
+}
+
+func dailyProfitLoss(byDay map[string]*models.DailyProfitLoss, date time.Time) *models.DailyProfitLoss {
+	key := date.UTC().Format("2006-01-02")
+	day, ok := byDay[key]
+	if !ok {
+		day = &models.DailyProfitLoss{Date: key}
+		byDay[key] = day
+	}
+	return day
+}
+
+func addProfitLoss(total *models.ProfitLoss, add models.ProfitLoss) {
+	total.Quantity += add.Quantity
+	total.Revenue += add.Revenue
+	total.Cost += add.Cost
+	total.SalesTax += add.SalesTax
+	total.BrokerFees += add.BrokerFees
+	total.Profit += add.Profit
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// ErrNotReprocessable is returned for types that yield no materials
+var ErrNotReprocessable = errors.New("type cannot be reprocessed")
+
+// Price sources for valuing items at a hub
+const (
+	PriceTypeBuy  = "buy"  // Highest buy order, i.e. instant sell value
+	PriceTypeSell = "sell" // Lowest sell order, i.e. instant buy cost
+)
+
+// Reprocessing constants
+const (
+	DefaultReprocessingYield = 0.50 // Base yield of NPC stations and refineries without rigs
+
+	reprocessingSkillBonus           = 0.03 // Reprocessing, per level
+	reprocessingEfficiencySkillBonus = 0.02 // Reprocessing Efficiency, per level
+	oreProcessingSkillBonus          = 0.02 // Ore specific processing skills, per level
+	scrapmetalProcessingSkillBonus   = 0.02 // Scrapmetal Processing, per level
+	maxSkillLevel                    = 5
+
+	DecisionReprocess = "reprocess"
+	DecisionSell      = "sell"
+)
+
+// ReprocessingRequest describes the items to reprocess and the reprocessing setup
+type ReprocessingRequest struct {
+	TypeID                      int32   `json:"type_id"`
+	Quantity                    int64   `json:"quantity"`
+	RegionID                    int32   `json:"region_id"`
+	PriceType                   string  `json:"price_type"`
+	BaseYield                   float64 `json:"base_yield"` // Station or structure base yield, e.g. 0.5
+	RigBonus                    float64 `json:"rig_bonus"`  // Added to the base yield, e.g. 0.02
+	ReprocessingLevel           int32   `json:"reprocessing_level"`
+	ReprocessingEfficiencyLevel int32   `json:"reprocessing_efficiency_level"`
+	OreProcessingLevel          int32   `json:"ore_processing_level"`
+	ScrapmetalProcessingLevel   int32   `json:"scrapmetal_processing_level"`
+	ImplantBonus                float64 `json:"implant_bonus"` // e.g. 0.04 for a RX-804 implant
+	TaxRate                     float64 `json:"tax_rate"`      // Station reprocessing tax, e.g. 0.05
+}
+
+// ReprocessedMaterial is one material output with its market value
+type ReprocessedMaterial struct {
+	TypeID    int32   `json:"type_id"`
+	Quantity  int64   `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Value     float64 `json:"value"`
+}
+
+// ReprocessingResult compares reprocessing with selling the items as-is
+type ReprocessingResult struct {
+	TypeID              int32                 `json:"type_id"`
+	RegionID            int32                 `json:"region_id"`
+	PriceType           string                `json:"price_type"`
+	IsOre               bool                  `json:"is_ore"`
+	Yield               float64               `json:"yield"`
+	Quantity            int64                 `json:"quantity"`
+	ReprocessedQuantity int64                 `json:"reprocessed_quantity"`
+	LeftoverQuantity    int64                 `json:"leftover_quantity"` // Units below a full portion
+	Materials           []ReprocessedMaterial `json:"materials"`
+	MaterialValue       float64               `json:"material_value"`
+	Tax                 float64               `json:"tax"`
+	ReprocessValue      float64               `json:"reprocess_value"`
+	UnitPrice           float64               `json:"unit_price"`
+	SellValue           float64               `json:"sell_value"` // Value of the reprocessed units sold as-is
+	ISKDelta            float64               `json:"isk_delta"`
+	Decision            string                `json:"decision"`
+}
+
+// ReprocessingService compares reprocessed material value with market prices
+type ReprocessingService struct {
+	materials TypeMaterialRepository
+	market    MarketDataProvider
+}
+
+func NewReprocessingService(materials TypeMaterialRepository, market MarketDataProvider) *ReprocessingService {
+	return &ReprocessingService{
+		materials: materials,
+		market:    market,
+	}
+}
+
+// CalculateReprocessing computes the reprocessed output of a stack and the ISK delta to selling it
+func (s *ReprocessingService) CalculateReprocessing(ctx context.Context, req ReprocessingRequest) (*ReprocessingResult, error) {
+	req, err := s.normalizeReprocessingRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	typeMaterials, err := s.materials.GetTypeMaterials(req.TypeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrItemNotFound) {
+			return nil, ErrItemNotFound
+		}
+		if errors.Is(err, repository.ErrTypeMaterialsNotFound) {
+			return nil, fmt.Errorf("%w: typeID %d", ErrNotReprocessable, req.TypeID)
+		}
+		return nil, err
+	}
+
+	isOre := typeMaterials.CategoryID == repository.CategoryAsteroid
+	portions := req.Quantity / int64(typeMaterials.PortionSize)
+
+	result := &ReprocessingResult{
+		TypeID:              req.TypeID,
+		RegionID:            req.RegionID,
+		PriceType:           req.PriceType,
+		IsOre:               isOre,
+		Yield:               reprocessingYield(req, isOre),
+		Quantity:            req.Quantity,
+		ReprocessedQuantity: portions * int64(typeMaterials.PortionSize),
+	}
+	result.LeftoverQuantity = req.Quantity - result.ReprocessedQuantity
+
+	prices, err := s.fetchPrices(ctx, req, typeMaterials)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, material := range typeMaterials.Materials {
+		quantity := int64(math.Floor(float64(portions) * float64(material.Quantity) * result.Yield))
+		unitPrice := prices[material.TypeID]
+		result.Materials = append(result.Materials, ReprocessedMaterial{
+			TypeID:    material.TypeID,
+			Quantity:  quantity,
+			UnitPrice: unitPrice,
+			Value:     float64(quantity) * unitPrice,
+		})
+		result.MaterialValue += float64(quantity) * unitPrice
+	}
+
+	result.Tax = result.MaterialValue * req.TaxRate
+	result.ReprocessValue = result.MaterialValue - result.Tax
+	result.UnitPrice = prices[req.TypeID]
+	result.SellValue = float64(result.ReprocessedQuantity) * result.UnitPrice
+	result.ISKDelta = result.ReprocessValue - result.SellValue
+
+	result.Decision = DecisionSell
+	if result.ISKDelta > 0 {
+		result.Decision = DecisionReprocess
+	}
+
+	return result, nil
+}
+
+// normalizeReprocessingRequest validates the request and applies defaults
+func (s *ReprocessingService) normalizeReprocessingRequest(req ReprocessingRequest) (ReprocessingRequest, error) {
+	if req.TypeID <= 0 {
+		return req, fmt.Errorf("%w: type ID must be positive", ErrInvalidInput)
+	}
+	if req.Quantity <= 0 {
+		return req, fmt.Errorf("%w: quantity must be positive", ErrInvalidInput)
+	}
+	for _, level := range []int32{req.ReprocessingLevel, req.ReprocessingEfficiencyLevel, req.OreProcessingLevel, req.ScrapmetalProcessingLevel} {
+		if level < 0 || level > maxSkillLevel {
+			return req, fmt.Errorf("%w: skill levels must be between 0 and %d", ErrInvalidInput, maxSkillLevel)
+		}
+	}
+	for _, fraction := range []float64{req.BaseYield, req.RigBonus, req.ImplantBonus, req.TaxRate} {
+		if fraction < 0 || fraction >= 1 {
+			return req, fmt.Errorf("%w: yields, bonuses and tax must be fractions between 0 and 1", ErrInvalidInput)
+		}
+	}
+
+	priceType, err := normalizePriceType(req.PriceType)
+	if err != nil {
+		return req, err
+	}
+	req.PriceType = priceType
+
+	if req.BaseYield == 0 {
+		req.BaseYield = DefaultReprocessingYield
+	}
+	if req.RegionID == 0 {
+		req.RegionID = DefaultHubRegion
+	}
+	return req, nil
+}
+
+// reprocessingYield calculates the effective yield from facility, skills and implants.
+// Non-ore items only benefit from Scrapmetal Processing.
+func reprocessingYield(req ReprocessingRequest, isOre bool) float64 {
+	yield := req.BaseYield + req.RigBonus
+
+	if isOre {
+		yield *= 1 + reprocessingSkillBonus*float64(req.ReprocessingLevel)
+		yield *= 1 + reprocessingEfficiencySkillBonus*float64(req.ReprocessingEfficiencyLevel)
+		yield *= 1 + oreProcessingSkillBonus*float64(req.OreProcessingLevel)
+		yield *= 1 + req.ImplantBonus
+	} else {
+		yield *= 1 + scrapmetalProcessingSkillBonus*float64(req.ScrapmetalProcessingLevel)
+	}
+
+	return math.Min(yield, 1)
+}
+
+// fetchPrices retrieves prices of the item and its materials using the requested price source
+func (s *ReprocessingService) fetchPrices(ctx context.Context, req ReprocessingRequest, typeMaterials *repository.SDETypeMaterials) (map[int32]float64, error) {
+	marketReq := MarketDataRequest{RegionID: req.RegionID, TypeIDs: []int32{req.TypeID}}
+	for _, material := range typeMaterials.Materials {
+		marketReq.TypeIDs = append(marketReq.TypeIDs, material.TypeID)
+	}
+
+	data, err := s.market.GetMarketData(ctx, marketReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market data: %w", err)
+	}
+
+	prices := make(map[int32]float64, len(data.Data))
+	for typeID, price := range data.Data {
+		prices[typeID] = priceForType(price, req.PriceType)
+	}
+	return prices, nil
+}
+
+// normalizePriceType validates a price source, defaulting to buy orders
+func normalizePriceType(priceType string) (string, error) {
+	switch priceType {
+	case "":
+		return PriceTypeBuy, nil
+	case PriceTypeBuy, PriceTypeSell:
+		return priceType, nil
+	default:
+		return "", fmt.Errorf("%w: price type must be %q or %q", ErrInvalidInput, PriceTypeBuy, PriceTypeSell)
+	}
+}
+
+// priceForType selects the buy or sell side of an aggregated item price
+func priceForType(price *models.ItemPrice, priceType string) float64 {
+	if price == nil {
+		return 0
+	}
+	if priceType == PriceTypeSell {
+		return price.SellMin
+	}
+	return price.BuyMax
+}
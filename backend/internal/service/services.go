@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -16,6 +17,9 @@ type MarketService struct {
 	cache     map[string]interface{}
 	cacheMux  sync.RWMutex
 	cacheTTL  time.Duration
+
+	listenerMu sync.RWMutex
+	listeners  []func(*MarketDataResponse)
 }
 
 func NewMarketService(esiClient ESIClient) *MarketService {
@@ -26,20 +30,28 @@ func NewMarketService(esiClient ESIClient) *MarketService {
 	}
 }
 
-// getCacheKey generates a cache key for market data
-func (s *MarketService) getCacheKey(regionID int32, typeIDs []int32) string {
-	return fmt.Sprintf("market_%d_%v", regionID, typeIDs)
+// marketTypeEntry is the cached market data of one type in one region. Types
+// are cached one by one, so requests for different sets of types share fetches.
+type marketTypeEntry struct {
+	orders  []models.MarketOrder
+	history []models.MarketHistory
+	price   *models.ItemPrice
+}
+
+// getCacheKey generates a cache key for the market data of one type
+func (s *MarketService) getCacheKey(regionID, typeID int32) string {
+	return fmt.Sprintf("market_%d_%d", regionID, typeID)
 }
 
 // getCachedData retrieves data from cache if available and not expired
-func (s *MarketService) getCachedData(key string) (*MarketDataResponse, bool) {
+func (s *MarketService) getCachedData(key string) (*marketTypeEntry, bool) {
 	s.cacheMux.RLock()
 	defer s.cacheMux.RUnlock()
 
 	if data, exists := s.cache[key]; exists {
-		if response, ok := data.(*MarketDataResponse); ok {
-			if time.Since(response.UpdatedAt) < s.cacheTTL {
-				return response, true
+		if entry, ok := data.(*marketTypeEntry); ok {
+			if time.Since(entry.price.LastUpdated) < s.cacheTTL {
+				return entry, true
 			}
 		}
 	}
@@ -47,7 +59,7 @@ func (s *MarketService) getCachedData(key string) (*MarketDataResponse, bool) {
 }
 
 // setCachedData stores data in cache
-func (s *MarketService) setCachedData(key string, data *MarketDataResponse) {
+func (s *MarketService) setCachedData(key string, data *marketTypeEntry) {
 	s.cacheMux.Lock()
 	defer s.cacheMux.Unlock()
 	s.cache[key] = data
@@ -65,7 +77,7 @@ type MarketDataResponse struct {
 	Data      map[int32]*models.ItemPrice      `json:"data"`
 	Orders    map[int32][]models.MarketOrder   `json:"orders,omitempty"`
 	History   map[int32][]models.MarketHistory `json:"history,omitempty"`
-	UpdatedAt time.Time                        `json:"updated_at"`
+	UpdatedAt time.Time                        `json:"updated_at"` // Fetch time of the oldest type, see ItemPrice.LastUpdated per type
 }
 
 // GetMarketData retrieves comprehensive market data for specified types in a region
@@ -74,23 +86,67 @@ func (s *MarketService) GetMarketData(ctx context.Context, req MarketDataRequest
 		return nil, err
 	}
 
-	// Check cache first
-	cacheKey := s.getCacheKey(req.RegionID, req.TypeIDs)
-	if cachedData, found := s.getCachedData(cacheKey); found {
-		return cachedData, nil
+	response := &MarketDataResponse{
+		RegionID: req.RegionID,
+		Data:     make(map[int32]*models.ItemPrice, len(req.TypeIDs)),
+		Orders:   make(map[int32][]models.MarketOrder, len(req.TypeIDs)),
+		History:  make(map[int32][]models.MarketHistory, len(req.TypeIDs)),
 	}
 
-	results, err := s.fetchMarketDataConcurrently(ctx, req)
-	if err != nil {
-		return nil, err
+	// Check cache first, only missing or expired types are fetched
+	var missing []int32
+	for _, typeID := range req.TypeIDs {
+		if entry, found := s.getCachedData(s.getCacheKey(req.RegionID, typeID)); found {
+			addMarketTypeEntry(response, typeID, entry)
+		} else {
+			missing = append(missing, typeID)
+		}
 	}
 
-	response := s.aggregateMarketDataResponse(req.RegionID, results)
-	s.setCachedData(cacheKey, response)
+	if len(missing) > 0 {
+		results, err := s.fetchMarketDataConcurrently(ctx, MarketDataRequest{RegionID: req.RegionID, TypeIDs: missing})
+		if err != nil {
+			return nil, err
+		}
+
+		fresh := s.aggregateMarketDataResponse(req.RegionID, results)
+		for typeID, price := range fresh.Data {
+			entry := &marketTypeEntry{orders: fresh.Orders[typeID], history: fresh.History[typeID], price: price}
+			s.setCachedData(s.getCacheKey(req.RegionID, typeID), entry)
+			addMarketTypeEntry(response, typeID, entry)
+		}
+		s.notifyListeners(fresh)
+	}
 
 	return response, nil
 }
 
+// addMarketTypeEntry adds the data of one type, the response ages with its oldest type
+func addMarketTypeEntry(response *MarketDataResponse, typeID int32, entry *marketTypeEntry) {
+	response.Data[typeID] = entry.price
+	response.Orders[typeID] = entry.orders
+	response.History[typeID] = entry.history
+	if response.UpdatedAt.IsZero() || entry.price.LastUpdated.Before(response.UpdatedAt) {
+		response.UpdatedAt = entry.price.LastUpdated
+	}
+}
+
+// OnUpdate registers a listener that is called with the freshly fetched types
+// of every request, cached types are not repeated
+func (s *MarketService) OnUpdate(listener func(*MarketDataResponse)) {
+	s.listenerMu.Lock()
+	defer s.listenerMu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *MarketService) notifyListeners(response *MarketDataResponse) {
+	s.listenerMu.RLock()
+	defer s.listenerMu.RUnlock()
+	for _, listener := range s.listeners {
+		listener(response)
+	}
+}
+
 // validateMarketDataRequest validates the market data request
 func (s *MarketService) validateMarketDataRequest(req MarketDataRequest) error {
 	if len(req.TypeIDs) == 0 {
@@ -241,7 +297,14 @@ func (s *MarketService) calculateItemPrice(orders []models.MarketOrder, _ []mode
 // ItemService handles SDE item operations
 type ItemService struct {
 	sdeRepo      *repository.SDERepository
-	cacheManager interface{}
+	cacheManager SDECache
+
+	marketGroupMux   sync.RWMutex
+	marketGroupTree  []*models.MarketGroup
+	marketGroupIndex map[int32]*models.MarketGroup
+
+	searchMux   sync.RWMutex
+	searchIndex *itemSearchIndex
 }
 
 func NewItemService(sdeRepo interface{}, cacheManager interface{}) *ItemService {
@@ -249,10 +312,16 @@ func NewItemService(sdeRepo interface{}, cacheManager interface{}) *ItemService
 	if !ok {
 		return &ItemService{}
 	}
-	return &ItemService{
-		sdeRepo:      repo,
-		cacheManager: cacheManager,
+	service := &ItemService{sdeRepo: repo}
+	if sdeCache, ok := cacheManager.(SDECache); ok {
+		service.cacheManager = sdeCache
 	}
+	return service
+}
+
+// getItemCacheKey generates a cache key for a single item
+func (s *ItemService) getItemCacheKey(typeID int32) string {
+	return fmt.Sprintf("item_%d", typeID)
 }
 
 // GetItemByID retrieves an item by its type ID
@@ -261,50 +330,63 @@ func (s *ItemService) GetItemByID(typeID int32) (*models.Item, error) {
 		return nil, fmt.Errorf("SDE repository not available")
 	}
 
+	cacheKey := s.getItemCacheKey(typeID)
+	if s.cacheManager != nil {
+		var cached models.Item
+		if err := s.cacheManager.GetSDEData(cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
 	sdeItem, err := s.sdeRepo.GetItemByID(typeID)
 	if err != nil {
 		// Check if item was not found
-		if err.Error() == fmt.Sprintf("item not found: typeID %d", typeID) {
+		if errors.Is(err, repository.ErrItemNotFound) {
 			return nil, ErrItemNotFound
 		}
 		return nil, err
 	}
 
-	// Convert SDE item to models.Item
-	item := &models.Item{
-		TypeID:   sdeItem.TypeID,
-		TypeName: sdeItem.TypeName,
-		GroupID:  sdeItem.GroupID,
-		Volume:   sdeItem.Volume,
-		Mass:     0, // Mass not available in SDE
+	item := toModelItem(sdeItem)
+	if s.cacheManager != nil {
+		_ = s.cacheManager.SetSDEData(cacheKey, item) // Cache failures only cost performance
 	}
 
 	return item, nil
 }
 
-// SearchItems searches for items by name pattern
+// SearchItems returns the best ranked published items for a search term
 func (s *ItemService) SearchItems(pattern string) ([]*models.Item, error) {
-	if s.sdeRepo == nil {
-		return nil, fmt.Errorf("SDE repository not available")
-	}
-
-	sdeItems, err := s.sdeRepo.SearchItems(pattern, 50) // Default limit of 50
+	published := true
+	result, err := s.Search(ItemSearchOptions{Query: pattern, Published: &published})
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert SDE items to models.Item pointers
-	var items []*models.Item
-	for _, sdeItem := range sdeItems {
-		item := &models.Item{
-			TypeID:   sdeItem.TypeID,
-			TypeName: sdeItem.TypeName,
-			GroupID:  sdeItem.GroupID,
-			Volume:   sdeItem.Volume,
-			Mass:     0, // Mass not available in SDE
-		}
-		items = append(items, item)
+	return result.Items, nil
+}
+
+// toModelItem converts an SDE item to models.Item
+func toModelItem(sdeItem *repository.SDEItem) *models.Item {
+	return &models.Item{
+		TypeID:       sdeItem.TypeID,
+		TypeName:     sdeItem.TypeName,
+		GroupID:      sdeItem.GroupID,
+		GroupName:    sdeItem.GroupName,
+		CategoryID:   sdeItem.CategoryID,
+		CategoryName: sdeItem.CategoryName,
+		Volume:       sdeItem.Volume,
+		Mass:         sdeItem.Mass,
+		Description:  sdeItem.Description,
+		MarketGroup:  sdeItem.MarketGroup,
 	}
+}
 
-	return items, nil
+// toModelItems converts SDE items to models.Item pointers
+func toModelItems(sdeItems []*repository.SDEItem) []*models.Item {
+	items := make([]*models.Item, 0, len(sdeItems))
+	for _, sdeItem := range sdeItems {
+		items = append(items, toModelItem(sdeItem))
+	}
+	return items
 }
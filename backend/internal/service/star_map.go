@@ -0,0 +1,168 @@
+package service
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// Route preferences of the autopilot
+const (
+	RouteShortest = "shortest" // Fewest jumps
+	RouteSecure   = "secure"   // Avoids low and null security systems where possible
+)
+
+// Security levels as shown in game, where the status is rounded to one decimal
+const (
+	highSecMinimum = 0.45
+	lowSecMinimum  = 0.0
+
+	// insecureSystemPenalty makes a secure route take up to this many high security
+	// jumps to avoid a single low or null security system
+	insecureSystemPenalty = 1000
+)
+
+// starMap is the stargate graph of New Eden
+type starMap struct {
+	systems map[int32]*repository.SDESolarSystem
+	gates   map[int32][]int32
+}
+
+// newStarMap builds the graph from the SDE solar systems and their jumps
+func newStarMap(universe UniverseRepository) (*starMap, error) {
+	systems, err := universe.GetSolarSystems()
+	if err != nil {
+		return nil, err
+	}
+	jumps, err := universe.GetSolarSystemJumps()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &starMap{
+		systems: make(map[int32]*repository.SDESolarSystem, len(systems)),
+		gates:   make(map[int32][]int32, len(systems)),
+	}
+	for _, system := range systems {
+		m.systems[system.SolarSystemID] = system
+	}
+	for _, jump := range jumps {
+		m.gates[jump.FromSolarSystemID] = append(m.gates[jump.FromSolarSystemID], jump.ToSolarSystemID)
+	}
+	return m, nil
+}
+
+// route returns the solar systems from one system to another, both included.
+// It returns false if the systems are not connected by stargates.
+func (m *starMap) route(from, to int32, preference string) ([]int32, bool) {
+	if from == to {
+		return []int32{from}, true
+	}
+
+	distance := map[int32]int{from: 0}
+	previous := make(map[int32]int32)
+	queue := &routeQueue{{systemID: from}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(routeStep)
+		if current.systemID == to {
+			break
+		}
+		if current.cost > distance[current.systemID] {
+			continue // Stale entry, a cheaper way was found later
+		}
+
+		for _, next := range m.gates[current.systemID] {
+			cost := current.cost + m.jumpCost(next, preference)
+			if known, ok := distance[next]; ok && known <= cost {
+				continue
+			}
+			distance[next] = cost
+			previous[next] = current.systemID
+			heap.Push(queue, routeStep{systemID: next, cost: cost})
+		}
+	}
+
+	if _, ok := distance[to]; !ok {
+		return nil, false
+	}
+	route := []int32{to}
+	for systemID := to; systemID != from; {
+		systemID = previous[systemID]
+		route = append(route, systemID)
+	}
+	for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+		route[i], route[j] = route[j], route[i]
+	}
+	return route, true
+}
+
+// jumpCost is the cost of jumping into a system
+func (m *starMap) jumpCost(systemID int32, preference string) int {
+	if preference == RouteSecure && m.security(systemID) < highSecMinimum {
+		return insecureSystemPenalty
+	}
+	return 1
+}
+
+// security returns the security status of a system, unknown systems count as null security
+func (m *starMap) security(systemID int32) float64 {
+	if system, ok := m.systems[systemID]; ok {
+		return system.Security
+	}
+	return -1
+}
+
+// routeSecurity summarizes the systems of a route
+func (m *starMap) routeSecurity(route []int32) models.RouteSecurity {
+	summary := models.RouteSecurity{MinSecurity: math.Inf(1)}
+	for _, systemID := range route {
+		security := m.security(systemID)
+		summary.MinSecurity = math.Min(summary.MinSecurity, security)
+		switch {
+		case security >= highSecMinimum:
+			summary.HighSecSystems++
+		case security > lowSecMinimum:
+			summary.LowSecSystems++
+		default:
+			summary.NullSecSystems++
+		}
+	}
+	if len(route) == 0 {
+		summary.MinSecurity = 0
+	}
+	return summary
+}
+
+// ParseRoutePreference parses a route preference, defaulting to the shortest route
+func ParseRoutePreference(value string) (string, error) {
+	switch value {
+	case "", RouteShortest:
+		return RouteShortest, nil
+	case RouteSecure:
+		return RouteSecure, nil
+	}
+	return "", fmt.Errorf("%w: unknown route %q, use shortest or secure", ErrInvalidInput, value)
+}
+
+// routeStep is a queued system with the cost to reach it
+type routeStep struct {
+	systemID int32
+	cost     int
+}
+
+// routeQueue is a min-heap of route steps ordered by cost
+type routeQueue []routeStep
+
+func (q routeQueue) Len() int            { return len(q) }
+func (q routeQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q routeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x interface{}) { *q = append(*q, x.(routeStep)) }
+func (q *routeQueue) Pop() interface{} {
+	old := *q
+	step := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return step
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// ErrTokenNotFound is shared with the SQLite store so callers can check either
+var ErrTokenNotFound = repository.ErrTokenNotFound
+
+// TokenStore keeps the SSO tokens of each character
+type TokenStore interface {
+	SaveToken(token *models.AuthToken) error               // Clears the revoked flag unless the token sets it
+	GetToken(characterID int32) (*models.AuthToken, error) // ErrTokenNotFound if the character never logged in
+	ListTokens() ([]*models.AuthToken, error)
+	MarkRevoked(characterID int32) error
+	DeleteToken(characterID int32) error
+}
+
+// MemoryTokenStore keeps tokens in memory, they are lost on restart
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[int32]models.AuthToken
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[int32]models.AuthToken)}
+}
+
+// SaveToken stores a copy of the token, replacing the previous one of the character
+func (s *MemoryTokenStore) SaveToken(token *models.AuthToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *token
+	stored.Scopes = append([]string(nil), token.Scopes...)
+	s.tokens[token.CharacterID] = stored
+	return nil
+}
+
+// GetToken returns a copy of the stored token
+func (s *MemoryTokenStore) GetToken(characterID int32) (*models.AuthToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.tokens[characterID]
+	if !ok {
+		return nil, fmt.Errorf("%w: characterID %d", ErrTokenNotFound, characterID)
+	}
+	token.Scopes = append([]string(nil), token.Scopes...)
+	return &token, nil
+}
+
+// ListTokens returns copies of all tokens ordered by character ID
+func (s *MemoryTokenStore) ListTokens() ([]*models.AuthToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tokens := make([]*models.AuthToken, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		token.Scopes = append([]string(nil), token.Scopes...)
+		tokens = append(tokens, &token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CharacterID < tokens[j].CharacterID })
+	return tokens, nil
+}
+
+// MarkRevoked flags the token of a character as needing a new login
+func (s *MemoryTokenStore) MarkRevoked(characterID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[characterID]
+	if !ok {
+		return fmt.Errorf("%w: characterID %d", ErrTokenNotFound, characterID)
+	}
+	token.Revoked = true
+	s.tokens[characterID] = token
+	return nil
+}
+
+// DeleteToken removes the token of a character
+func (s *MemoryTokenStore) DeleteToken(characterID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, characterID)
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"eve-profit2/internal/models"
+)
+
+// Type IDs of the trade skills
+const (
+	SkillTrade           int32 = 3443
+	SkillRetail          int32 = 3444
+	SkillBrokerRelations int32 = 3446
+	SkillProcurement     int32 = 16594
+	SkillDaytrading      int32 = 16595
+	SkillWholesale       int32 = 16596
+	SkillMarginTrading   int32 = 16597
+	SkillMarketing       int32 = 16598
+	SkillAccounting      int32 = 16622
+	SkillTycoon          int32 = 18580
+)
+
+// Trade skill bonuses
+const (
+	baseOrderSlots         = 5
+	tradeOrderSlots        = 4  // Trade, per level
+	retailOrderSlots       = 8  // Retail, per level
+	wholesaleOrderSlots    = 16 // Wholesale, per level
+	tycoonOrderSlots       = 32 // Tycoon, per level
+	brokerRelationsBonus   = 0.003
+	accountingBonus        = 0.11 // Relative sales tax reduction per level
+	marginTradingBonus     = 0.25 // Relative escrow reduction per level
+	orderModifyCooldownSec = 300
+)
+
+// remoteOrderRanges maps the level of Marketing, Procurement and Daytrading to the
+// farthest order range, using the range names of the ESI order endpoints
+var remoteOrderRanges = [maxSkillLevel + 1]string{"station", "solarsystem", "5", "10", "20", "region"}
+
+// TradeProfileFromSkills derives order slots, remote order ranges and fees from the
+// active skill levels. Alpha clones use their active rather than trained levels.
+func TradeProfileFromSkills(skills *models.CharacterSkills) *models.TradeProfile {
+	levels := make(map[int32]int32, len(skills.Skills))
+	for _, skill := range skills.Skills {
+		levels[skill.SkillID] = min(max(skill.ActiveSkillLevel, 0), maxSkillLevel)
+	}
+
+	profile := &models.TradeProfile{
+		CharacterID: skills.CharacterID,
+		Skills: models.TradeSkillLevels{
+			Trade:           levels[SkillTrade],
+			Retail:          levels[SkillRetail],
+			Wholesale:       levels[SkillWholesale],
+			Tycoon:          levels[SkillTycoon],
+			Marketing:       levels[SkillMarketing],
+			Procurement:     levels[SkillProcurement],
+			Daytrading:      levels[SkillDaytrading],
+			Accounting:      levels[SkillAccounting],
+			BrokerRelations: levels[SkillBrokerRelations],
+			MarginTrading:   levels[SkillMarginTrading],
+		},
+		ModificationCooldown: orderModifyCooldownSec,
+		ModificationMinFee:   MinBrokerFee,
+	}
+	s := profile.Skills
+
+	profile.MaxOrders = baseOrderSlots +
+		tradeOrderSlots*int(s.Trade) +
+		retailOrderSlots*int(s.Retail) +
+		wholesaleOrderSlots*int(s.Wholesale) +
+		tycoonOrderSlots*int(s.Tycoon)
+
+	profile.SellOrderRange = remoteOrderRanges[s.Marketing]
+	profile.BuyOrderRange = remoteOrderRanges[s.Procurement]
+	profile.ModifyOrderRange = remoteOrderRanges[s.Daytrading]
+
+	profile.BrokerFeeReduction = brokerRelationsBonus * float64(s.BrokerRelations)
+	profile.BrokerFee = roundRate(DefaultBrokerFee - profile.BrokerFeeReduction)
+	profile.SalesTaxReduction = roundRate(DefaultSalesTax * accountingBonus * float64(s.Accounting))
+	profile.SalesTax = roundRate(DefaultSalesTax - profile.SalesTaxReduction)
+	profile.EscrowRate = roundRate(math.Pow(1-marginTradingBonus, float64(s.MarginTrading)))
+	profile.ModificationFeeRate = profile.BrokerFee
+	return profile
+}
+
+// GetTradeProfile derives the trading capability of a character from its skills
+func (s *CharacterService) GetTradeProfile(ctx context.Context, characterID int32) (*models.TradeProfile, error) {
+	skills, err := s.GetCharacterSkills(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	return TradeProfileFromSkills(skills), nil
+}
+
+// roundRate drops floating point noise from fee rates
+func roundRate(rate float64) float64 {
+	return math.Round(rate*1e6) / 1e6
+}
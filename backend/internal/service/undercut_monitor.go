@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// MinBrokerFee is the smallest broker fee the market charges per order change
+const MinBrokerFee = 100.0
+
+// orderBookFetchWorkers bounds concurrent order book requests
+const orderBookFetchWorkers = 8
+
+// UndercutReport lists the competition checks of all open orders of the checked characters
+type UndercutReport struct {
+	CheckedOrders int                    `json:"checked_orders"`
+	Undercut      int                    `json:"undercut"` // Undercut sell plus outbid buy orders
+	Orders        []models.OrderUndercut `json:"orders"`
+	Errors        []CharacterError       `json:"errors,omitempty"`
+	CheckedAt     time.Time              `json:"checked_at"`
+}
+
+// UndercutMonitor checks open character orders against the competing orders of
+// the regional order books
+type UndercutMonitor struct {
+	orders     CharacterDataProvider
+	books      MarketOrderClient
+	costs      CostBasisProvider
+	notifier   UndercutNotifier
+	characters CharacterTokenSource
+	profiles   TradeProfileProvider
+
+	fees tradeFees
+
+	notifiedMu sync.Mutex
+	notified   map[int64]float64 // Order ID -> competitor price of the last notification
+}
+
+func NewUndercutMonitor(orders CharacterDataProvider, books MarketOrderClient) *UndercutMonitor {
+	return &UndercutMonitor{
+		orders:   orders,
+		books:    books,
+		fees:     tradeFees{brokerFee: DefaultBrokerFee, salesTax: DefaultSalesTax},
+		notified: make(map[int64]float64),
+	}
+}
+
+// WithFees sets the broker fee and sales tax rates. The relist discount lowers
+// the broker fee charged for price changes, e.g. 0.5 for 50%.
+func (m *UndercutMonitor) WithFees(brokerFee, salesTax, relistDiscount float64) *UndercutMonitor {
+	m.fees = tradeFees{brokerFee: brokerFee, salesTax: salesTax, relistDiscount: relistDiscount}
+	return m
+}
+
+// WithTradeProfiles uses each character's skill based broker fee and sales tax.
+// Characters whose skills cannot be loaded fall back to the configured fees.
+func (m *UndercutMonitor) WithTradeProfiles(profiles TradeProfileProvider) *UndercutMonitor {
+	m.profiles = profiles
+	return m
+}
+
+// WithCostBasis judges sell order reprices against the purchase cost of the stock
+func (m *UndercutMonitor) WithCostBasis(costs CostBasisProvider) *UndercutMonitor {
+	m.costs = costs
+	return m
+}
+
+// WithNotifier reports newly undercut orders found by the background check
+func (m *UndercutMonitor) WithNotifier(notifier UndercutNotifier) *UndercutMonitor {
+	m.notifier = notifier
+	return m
+}
+
+// WithCharacters sets the characters checked in the background
+func (m *UndercutMonitor) WithCharacters(characters CharacterTokenSource) *UndercutMonitor {
+	m.characters = characters
+	return m
+}
+
+// CheckCharacters checks the open orders of the given characters. Unless all is
+// set only undercut and outbid orders are listed. Characters whose orders cannot
+// be loaded are reported without failing the whole check.
+func (m *UndercutMonitor) CheckCharacters(ctx context.Context, characterIDs []int32, all bool) (*UndercutReport, error) {
+	report := &UndercutReport{Orders: []models.OrderUndercut{}, CheckedAt: time.Now()}
+
+	type characterOrder struct {
+		characterID int32
+		order       models.CharacterOrder
+	}
+	var orders []characterOrder
+	own := make(map[int64]bool)
+	for _, characterID := range characterIDs {
+		characterOrders, err := m.orders.GetCharacterOrders(ctx, characterID)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			report.Errors = append(report.Errors, CharacterError{CharacterID: characterID, Error: err.Error()})
+			continue
+		}
+		for _, order := range characterOrders {
+			orders = append(orders, characterOrder{characterID: characterID, order: order})
+			own[order.OrderID] = true
+		}
+	}
+
+	keys := make(map[orderBookKey]bool)
+	for _, entry := range orders {
+		keys[orderBookKey{regionID: entry.order.RegionID, typeID: entry.order.TypeID}] = true
+	}
+	books, err := fetchOrderBooks(ctx, m.books, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var costs map[int32]map[int32]float64
+	if m.costs != nil {
+		costs = make(map[int32]map[int32]float64)
+		for _, characterID := range characterIDs {
+			characterCosts, err := m.costs.AverageCosts(characterID)
+			if err != nil {
+				// Reprices are then judged against the buy orders at the station
+				fmt.Printf("Warning: failed to load cost basis of character %d: %v\n", characterID, err)
+				continue
+			}
+			costs[characterID] = characterCosts
+		}
+	}
+
+	fees := m.characterFees(ctx, characterIDs)
+	for _, entry := range orders {
+		book := books[orderBookKey{regionID: entry.order.RegionID, typeID: entry.order.TypeID}]
+		cost, costKnown := costs[entry.characterID][entry.order.TypeID]
+		check := m.checkOrder(entry.order, book, own, cost, costKnown, fees[entry.characterID])
+		check.CharacterID = entry.characterID
+
+		report.CheckedOrders++
+		if check.Status != models.OrderStatusLeading {
+			report.Undercut++
+		}
+		if all || check.Status != models.OrderStatusLeading {
+			report.Orders = append(report.Orders, check)
+		}
+	}
+
+	sort.SliceStable(report.Orders, func(i, j int) bool {
+		return report.Orders[i].DifferencePercent > report.Orders[j].DifferencePercent
+	})
+	return report, nil
+}
+
+// characterFees returns the fees of every character, skill based where possible
+func (m *UndercutMonitor) characterFees(ctx context.Context, characterIDs []int32) map[int32]tradeFees {
+	fees := make(map[int32]tradeFees, len(characterIDs))
+	for _, characterID := range characterIDs {
+		fees[characterID] = m.fees
+		if m.profiles == nil {
+			continue
+		}
+		profile, err := m.profiles.GetTradeProfile(ctx, characterID)
+		if err != nil {
+			fmt.Printf("Warning: failed to load trade profile of character %d: %v\n", characterID, err)
+			continue
+		}
+		fees[characterID] = tradeFees{brokerFee: profile.BrokerFee, salesTax: profile.SalesTax, relistDiscount: m.fees.relistDiscount}
+	}
+	return fees
+}
+
+// Start checks the orders of all characters with a stored token in the
+// background and notifies about orders that became undercut since the last check
+func (m *UndercutMonitor) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := m.checkAndNotify(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Printf("Warning: undercut check failed: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// checkAndNotify runs one background check
+func (m *UndercutMonitor) checkAndNotify(ctx context.Context) error {
+	if m.characters == nil {
+		return nil
+	}
+	tokens, err := m.characters.Characters()
+	if err != nil {
+		return err
+	}
+
+	var characterIDs []int32
+	for _, token := range tokens {
+		if !token.Revoked {
+			characterIDs = append(characterIDs, token.CharacterID)
+		}
+	}
+	if len(characterIDs) == 0 {
+		return nil
+	}
+
+	report, err := m.CheckCharacters(ctx, characterIDs, false)
+	if err != nil {
+		return err
+	}
+
+	fresh := m.newUndercuts(report.Orders)
+	if len(fresh) == 0 || m.notifier == nil {
+		return nil
+	}
+	return m.notifier.NotifyUndercuts(ctx, fresh)
+}
+
+// newUndercuts returns undercut orders that were not notified with the same
+// competitor price before and forgets orders that lead again
+func (m *UndercutMonitor) newUndercuts(undercuts []models.OrderUndercut) []models.OrderUndercut {
+	m.notifiedMu.Lock()
+	defer m.notifiedMu.Unlock()
+
+	current := make(map[int64]float64, len(undercuts))
+	var fresh []models.OrderUndercut
+	for _, undercut := range undercuts {
+		current[undercut.OrderID] = undercut.CompetitorPrice
+		if price, ok := m.notified[undercut.OrderID]; !ok || price != undercut.CompetitorPrice {
+			fresh = append(fresh, undercut)
+		}
+	}
+	m.notified = current
+	return fresh
+}
+
+// tradeFees are the fee rates used to judge reprices
+type tradeFees struct {
+	brokerFee      float64
+	salesTax       float64
+	relistDiscount float64 // Share of the broker fee waived for price changes
+}
+
+// orderBookKey identifies the order book of one type in one region
+type orderBookKey struct {
+	regionID int32
+	typeID   int32
+}
+
+// fetchOrderBooks loads the order books of all keys with a few parallel requests
+func fetchOrderBooks(ctx context.Context, client MarketOrderClient, keys map[orderBookKey]bool) (map[orderBookKey][]models.MarketOrder, error) {
+	jobs := make(chan orderBookKey)
+	books := make(map[orderBookKey][]models.MarketOrder, len(keys))
+	var mu sync.Mutex
+	var firstErr error
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(orderBookFetchWorkers, len(keys)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				orders, err := client.GetMarketOrders(ctx, key.regionID, key.typeID)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("failed to get orders of type %d in region %d: %w", key.typeID, key.regionID, err)
+				}
+				books[key] = orders
+				mu.Unlock()
+			}
+		}()
+	}
+	for key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return books, nil
+}
+
+// checkOrder compares an order with the best competing order in range
+func (m *UndercutMonitor) checkOrder(order models.CharacterOrder, book []models.MarketOrder, own map[int64]bool, cost float64, costKnown bool, fees tradeFees) models.OrderUndercut {
+	check := models.OrderUndercut{
+		OrderID:      order.OrderID,
+		TypeID:       order.TypeID,
+		RegionID:     order.RegionID,
+		LocationID:   order.LocationID,
+		IsBuyOrder:   order.IsBuyOrder,
+		Price:        order.Price,
+		VolumeRemain: order.VolumeRemain,
+		Status:       models.OrderStatusLeading,
+	}
+
+	// Character orders do not carry the solar system, the book does
+	var systemID int32
+	for _, candidate := range book {
+		if candidate.OrderID == order.OrderID {
+			systemID = candidate.SystemID
+			break
+		}
+	}
+
+	var best *models.MarketOrder
+	var bestSell, bestBuy float64 // Best prices at the station, used to judge reprices
+	for i := range book {
+		candidate := &book[i]
+		if own[candidate.OrderID] || candidate.VolumeRemain <= 0 {
+			continue
+		}
+		if candidate.LocationID == order.LocationID {
+			if !candidate.IsBuyOrder && (bestSell == 0 || candidate.Price < bestSell) {
+				bestSell = candidate.Price
+			}
+			if candidate.IsBuyOrder && candidate.Price > bestBuy {
+				bestBuy = candidate.Price
+			}
+		}
+
+		if candidate.IsBuyOrder != order.IsBuyOrder {
+			continue
+		}
+		if order.IsBuyOrder {
+			if candidate.Price > order.Price && buyRangeCovers(*candidate, order.LocationID, systemID) && (best == nil || candidate.Price > best.Price) {
+				best = candidate
+			}
+		} else if candidate.LocationID == order.LocationID && candidate.Price < order.Price && (best == nil || candidate.Price < best.Price) {
+			best = candidate
+		}
+	}
+	if best == nil {
+		return check
+	}
+
+	check.CompetitorOrderID = best.OrderID
+	check.CompetitorPrice = best.Price
+	check.Difference = math.Abs(order.Price - best.Price)
+	if order.Price > 0 {
+		check.DifferencePercent = check.Difference / order.Price * 100
+	}
+
+	volume := float64(order.VolumeRemain)
+	if order.IsBuyOrder {
+		check.Status = models.OrderStatusOutbid
+		check.SuggestedPrice = best.Price + PriceTick(best.Price)
+	} else {
+		check.Status = models.OrderStatusUndercut
+		check.SuggestedPrice = best.Price - PriceTick(best.Price)
+	}
+	check.ModificationFee = math.Max(MinBrokerFee, fees.brokerFee*(1-fees.relistDiscount)*check.SuggestedPrice*volume)
+
+	if order.IsBuyOrder {
+		// The bought volume must still sell at the station after fees
+		if bestSell > 0 {
+			check.ProfitKnown = true
+			check.ExpectedProfit = (bestSell*(1-fees.salesTax-fees.brokerFee)-check.SuggestedPrice)*volume - check.ModificationFee
+		}
+	} else {
+		// Without a known purchase cost the reprice must beat dumping into the best buy order
+		floor, known := cost, costKnown
+		if !known && bestBuy > 0 {
+			floor, known = bestBuy*(1-fees.salesTax), true
+		}
+		if known {
+			check.ProfitKnown = true
+			check.ExpectedProfit = (check.SuggestedPrice*(1-fees.salesTax)-floor)*volume - check.ModificationFee
+		}
+	}
+	check.RepriceProfitable = check.ProfitKnown && check.ExpectedProfit > 0
+	return check
+}
+
+// buyRangeCovers reports whether a buy order can buy from sellers at the
+// station. Jump ranges count within the solar system only because the monitor
+// has no jump map.
+func buyRangeCovers(buy models.MarketOrder, locationID int64, systemID int32) bool {
+	switch buy.Range {
+	case "region":
+		return true
+	case "station":
+		return buy.LocationID == locationID
+	case "solarsystem":
+		return systemID != 0 && buy.SystemID == systemID
+	}
+	if jumps, err := strconv.Atoi(buy.Range); err == nil && jumps >= 0 {
+		return buy.LocationID == locationID || (systemID != 0 && buy.SystemID == systemID)
+	}
+	return buy.LocationID == locationID
+}
+
+// PriceTick is the smallest price step at the given price. The market accepts
+// four significant digits and at least 0.01 ISK.
+func PriceTick(price float64) float64 {
+	if price <= 0 {
+		return 0.01
+	}
+	return math.Max(math.Pow(10, math.Floor(math.Log10(price))-3), 0.01)
+}
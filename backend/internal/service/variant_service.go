@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// Attribute comparison results of a variant against its T1 parent
+const (
+	ComparisonBetter  = "better"
+	ComparisonEqual   = "equal"
+	ComparisonWorse   = "worse"
+	ComparisonMissing = "missing" // The variant lacks an attribute of the T1 type
+
+	attributeTolerance = 1e-9
+)
+
+// variantIgnoredAttributes describe the variant itself rather than its performance
+var variantIgnoredAttributes = map[int32]bool{
+	repository.AttributeMetaLevel: true,
+	repository.AttributeTechLevel: true,
+}
+
+// VariantRequest selects a module and the hub whose prices are compared
+type VariantRequest struct {
+	TypeID    int32  `json:"type_id"`   // Any variant, the T1 parent is resolved
+	RegionID  int32  `json:"region_id"` // Defaults to The Forge
+	Hub       string `json:"hub"`       // Name of a default hub, overrides RegionID
+	PriceType string `json:"price_type"`
+}
+
+// AttributeDelta compares one key attribute of a variant with the T1 type
+type AttributeDelta struct {
+	AttributeID  int32    `json:"attribute_id"`
+	DisplayName  string   `json:"display_name"`
+	Unit         string   `json:"unit,omitempty"`
+	HighIsGood   bool     `json:"high_is_good"`
+	Value        *float64 `json:"value"` // nil if the variant lacks the attribute
+	BaseValue    *float64 `json:"base_value"`
+	Delta        float64  `json:"delta"`
+	DeltaPercent float64  `json:"delta_percent"`
+	Comparison   string   `json:"comparison"`
+}
+
+// VariantPrice is one variant with its hub price and attribute deltas
+type VariantPrice struct {
+	TypeID        int32            `json:"type_id"`
+	TypeName      string           `json:"type_name"`
+	MetaGroupID   int32            `json:"meta_group_id"`
+	MetaGroupName string           `json:"meta_group_name"`
+	MetaLevel     int32            `json:"meta_level"`
+	TechLevel     int32            `json:"tech_level"`
+	Price         float64          `json:"price"`
+	HasPrice      bool             `json:"has_price"`
+	PriceDelta    float64          `json:"price_delta"` // Price minus T1 price, 0 if either has no price
+	IsBase        bool             `json:"is_base"`
+	Cheaper       bool             `json:"cheaper"`
+	EqualOrBetter bool             `json:"equal_or_better"` // No key attribute is worse than on the T1 type
+	Highlight     bool             `json:"highlight"`       // Cheaper and equal or better
+	Attributes    []AttributeDelta `json:"attributes"`
+}
+
+// VariantPriceResult lists all variants of a module priced at one hub
+type VariantPriceResult struct {
+	BaseTypeID      int32          `json:"base_type_id"`
+	BaseTypeName    string         `json:"base_type_name"`
+	BasePrice       float64        `json:"base_price"`
+	Hub             MarketHub      `json:"hub"`
+	PriceType       string         `json:"price_type"`
+	Variants        []VariantPrice `json:"variants"`
+	BestValueTypeID int32          `json:"best_value_type_id,omitempty"` // Cheapest highlighted variant
+}
+
+// VariantService prices the meta variants of a module against its T1 version
+type VariantService struct {
+	variants TypeVariantRepository
+	market   MarketDataProvider
+}
+
+func NewVariantService(variants TypeVariantRepository, market MarketDataProvider) *VariantService {
+	return &VariantService{
+		variants: variants,
+		market:   market,
+	}
+}
+
+// FindVariants lists all variants of a module with hub prices and key attribute deltas.
+// Key attributes are the published attributes whose values differ between variants.
+func (s *VariantService) FindVariants(ctx context.Context, req VariantRequest) (*VariantPriceResult, error) {
+	req, hub, err := normalizeVariantRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sdeVariants, err := s.variants.GetTypeVariants(req.TypeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrItemNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	// Officer and event variants without a market group are never listed, pricing them
+	// would only fail the region request for every other variant
+	typeIDs := make([]int32, 0, len(sdeVariants))
+	marketTypeIDs := make([]int32, 0, len(sdeVariants))
+	for _, variant := range sdeVariants {
+		typeIDs = append(typeIDs, variant.TypeID)
+		if variant.MarketGroup != 0 {
+			marketTypeIDs = append(marketTypeIDs, variant.TypeID)
+		}
+	}
+
+	attributes, err := s.variants.GetTypesAttributes(typeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := map[int32]*models.ItemPrice{}
+	if len(marketTypeIDs) > 0 {
+		data, err := s.market.GetMarketData(ctx, MarketDataRequest{RegionID: hub.RegionID, TypeIDs: marketTypeIDs})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get market data: %w", err)
+		}
+		prices = data.Data
+	}
+
+	variants := make([]VariantPrice, 0, len(sdeVariants))
+	attributeValues := make(map[int32]map[int32]*repository.SDEAttribute, len(sdeVariants))
+	for _, sdeVariant := range sdeVariants {
+		itemAttributes := toItemAttributes(&models.Item{TypeID: sdeVariant.TypeID}, attributes[sdeVariant.TypeID])
+		price := priceForType(prices[sdeVariant.TypeID], req.PriceType)
+
+		variants = append(variants, VariantPrice{
+			TypeID:        sdeVariant.TypeID,
+			TypeName:      sdeVariant.TypeName,
+			MetaGroupID:   sdeVariant.MetaGroupID,
+			MetaGroupName: sdeVariant.MetaGroupName,
+			MetaLevel:     itemAttributes.MetaLevel,
+			TechLevel:     itemAttributes.TechLevel,
+			Price:         price,
+			HasPrice:      price > 0,
+			IsBase:        sdeVariant.TypeID == sdeVariant.ParentTypeID,
+		})
+
+		byID := make(map[int32]*repository.SDEAttribute)
+		for _, attribute := range attributes[sdeVariant.TypeID] {
+			if attribute.Published && !variantIgnoredAttributes[attribute.AttributeID] {
+				byID[attribute.AttributeID] = attribute
+			}
+		}
+		attributeValues[sdeVariant.TypeID] = byID
+	}
+
+	sort.SliceStable(variants, func(i, j int) bool {
+		if variants[i].MetaLevel != variants[j].MetaLevel {
+			return variants[i].MetaLevel < variants[j].MetaLevel
+		}
+		return variants[i].TypeID < variants[j].TypeID
+	})
+
+	base := baseVariant(variants)
+	result := &VariantPriceResult{
+		BaseTypeID:   base.TypeID,
+		BaseTypeName: base.TypeName,
+		BasePrice:    base.Price,
+		Hub:          hub,
+		PriceType:    req.PriceType,
+	}
+
+	keyAttributes := keyAttributeIDs(attributeValues)
+	bestPrice := math.Inf(1)
+	for i := range variants {
+		variant := &variants[i]
+		compareVariant(variant, base, keyAttributes, attributeValues)
+
+		if variant.Highlight && variant.Price < bestPrice {
+			bestPrice = variant.Price
+			result.BestValueTypeID = variant.TypeID
+		}
+	}
+	result.Variants = variants
+
+	return result, nil
+}
+
+// normalizeVariantRequest validates the request and resolves the hub
+func normalizeVariantRequest(req VariantRequest) (VariantRequest, MarketHub, error) {
+	if req.TypeID <= 0 {
+		return req, MarketHub{}, fmt.Errorf("%w: type ID must be positive", ErrInvalidInput)
+	}
+	if req.RegionID < 0 {
+		return req, MarketHub{}, fmt.Errorf("%w: region ID must be positive", ErrInvalidInput)
+	}
+
+	// Fitting a ship means buying modules, so sell orders are the default price source
+	if req.PriceType == "" {
+		req.PriceType = PriceTypeSell
+	}
+	priceType, err := normalizePriceType(req.PriceType)
+	if err != nil {
+		return req, MarketHub{}, err
+	}
+	req.PriceType = priceType
+
+	if req.Hub != "" {
+		hub, ok := HubByName(req.Hub)
+		if !ok {
+			return req, MarketHub{}, fmt.Errorf("%w: unknown hub %q", ErrInvalidInput, req.Hub)
+		}
+		req.RegionID = hub.RegionID
+		return req, hub, nil
+	}
+
+	if req.RegionID == 0 {
+		req.RegionID = DefaultHubRegion
+	}
+	return req, HubForRegion(req.RegionID), nil
+}
+
+// baseVariant returns the T1 parent. If it is not published the lowest variant takes its place.
+func baseVariant(variants []VariantPrice) *VariantPrice {
+	for i := range variants {
+		if variants[i].IsBase {
+			return &variants[i]
+		}
+	}
+	variants[0].IsBase = true
+	return &variants[0]
+}
+
+// keyAttributeIDs returns the attributes whose values differ between the variants, sorted by ID
+func keyAttributeIDs(attributeValues map[int32]map[int32]*repository.SDEAttribute) []int32 {
+	values := make(map[int32][]float64)
+	for _, byID := range attributeValues {
+		for attributeID, attribute := range byID {
+			values[attributeID] = append(values[attributeID], attribute.Value)
+		}
+	}
+
+	var keys []int32
+	for attributeID, variantValues := range values {
+		differs := len(variantValues) < len(attributeValues) // Some variants lack the attribute
+		for _, value := range variantValues[1:] {
+			if !nearlyEqual(value, variantValues[0]) {
+				differs = true
+			}
+		}
+		if differs {
+			keys = append(keys, attributeID)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// compareVariant fills the attribute deltas and the price flags of a variant
+func compareVariant(variant, base *VariantPrice, keyAttributes []int32, attributeValues map[int32]map[int32]*repository.SDEAttribute) {
+	variant.Attributes = make([]AttributeDelta, 0, len(keyAttributes))
+	variant.EqualOrBetter = true
+
+	baseAttributes := attributeValues[base.TypeID]
+	variantAttributes := attributeValues[variant.TypeID]
+	for _, attributeID := range keyAttributes {
+		baseAttribute := baseAttributes[attributeID]
+		attribute := variantAttributes[attributeID]
+
+		definition := attribute
+		if definition == nil {
+			definition = baseAttribute
+		}
+		delta := AttributeDelta{
+			AttributeID: attributeID,
+			DisplayName: definition.DisplayName,
+			Unit:        definition.UnitName,
+			HighIsGood:  definition.HighIsGood,
+		}
+		if delta.DisplayName == "" {
+			delta.DisplayName = definition.AttributeName
+		}
+
+		switch {
+		case baseAttribute == nil:
+			// Extra attributes of a variant are shown but never make it worse
+			value := attribute.Value
+			delta.Value = &value
+			delta.Comparison = ComparisonBetter
+		case attribute == nil:
+			baseValue := baseAttribute.Value
+			delta.BaseValue = &baseValue
+			delta.Comparison = ComparisonMissing
+		default:
+			value, baseValue := attribute.Value, baseAttribute.Value
+			delta.Value, delta.BaseValue = &value, &baseValue
+			delta.Delta = value - baseValue
+			if baseValue != 0 {
+				delta.DeltaPercent = delta.Delta / math.Abs(baseValue) * 100
+			}
+			delta.Comparison = compareAttributeValues(value, baseValue, definition.HighIsGood)
+		}
+
+		if delta.Comparison == ComparisonWorse || delta.Comparison == ComparisonMissing {
+			variant.EqualOrBetter = false
+		}
+		variant.Attributes = append(variant.Attributes, delta)
+	}
+
+	if variant.HasPrice && base.HasPrice {
+		variant.PriceDelta = variant.Price - base.Price
+		variant.Cheaper = variant.Price < base.Price
+	}
+	variant.Highlight = !variant.IsBase && variant.Cheaper && variant.EqualOrBetter
+}
+
+// compareAttributeValues rates a variant value against the T1 value
+func compareAttributeValues(value, baseValue float64, highIsGood bool) string {
+	switch {
+	case nearlyEqual(value, baseValue):
+		return ComparisonEqual
+	case (value > baseValue) == highIsGood:
+		return ComparisonBetter
+	default:
+		return ComparisonWorse
+	}
+}
+
+func nearlyEqual(a, b float64) bool {
+	return math.Abs(a-b) <= attributeTolerance*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
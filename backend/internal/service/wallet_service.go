@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// maxTransactionBatches bounds how far a sync walks back through the
+// transaction history; ESI keeps about 30 days anyway
+const maxTransactionBatches = 20
+
+// DefaultPriceRegionID values remaining stock in The Forge unless a region is requested
+const DefaultPriceRegionID int32 = 10000002
+
+// WalletService syncs wallet journals and market transactions into the app
+// database and calculates the profit and loss of a character's trading
+type WalletService struct {
+	store     WalletRepository
+	esiClient WalletESIClient
+	tokens    CharacterTokenSource
+	market    MarketDataProvider
+
+	syncMu sync.Mutex // One sync at a time keeps the incremental cursors consistent
+}
+
+func NewWalletService(store WalletRepository, esiClient WalletESIClient, tokens CharacterTokenSource) *WalletService {
+	return &WalletService{
+		store:     store,
+		esiClient: esiClient,
+		tokens:    tokens,
+	}
+}
+
+// WithMarketData values remaining stock for the unrealized profit and loss
+func (s *WalletService) WithMarketData(market MarketDataProvider) *WalletService {
+	s.market = market
+	return s
+}
+
+// SyncCharacter pulls journal entries and transactions newer than the stored
+// ones. Entries already stored are skipped by their ID.
+func (s *WalletService) SyncCharacter(ctx context.Context, characterID int32) (*models.WalletSyncResult, error) {
+	accessToken, err := accessTokenFor(ctx, s.tokens, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	journalEntries, err := s.syncJournal(ctx, characterID, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.syncTransactions(ctx, characterID, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WalletSyncResult{
+		CharacterID:    characterID,
+		JournalEntries: journalEntries,
+		Transactions:   transactions,
+		SyncedAt:       time.Now(),
+	}, nil
+}
+
+// SyncAll syncs every character with a stored, not revoked token and returns
+// the number of synced characters. Failing characters do not stop the others.
+func (s *WalletService) SyncAll(ctx context.Context) (int, error) {
+	tokens, err := s.tokens.Characters()
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	var errs []error
+	for _, token := range tokens {
+		if token.Revoked {
+			continue
+		}
+		if _, err := s.SyncCharacter(ctx, token.CharacterID); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return synced, err
+			}
+			errs = append(errs, fmt.Errorf("character %d: %w", token.CharacterID, err))
+			continue
+		}
+		synced++
+	}
+	return synced, errors.Join(errs...)
+}
+
+// Start syncs all characters in the background until the context is done
+func (s *WalletService) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.SyncAll(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Printf("Warning: wallet sync failed: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// GetProfitLoss calculates realized profit and loss from the synced history and
+// values the remaining stock with the lowest sell price in the price region
+func (s *WalletService) GetProfitLoss(ctx context.Context, characterID int32, method CostMethod, priceRegionID int32) (*models.ProfitLossReport, error) {
+	if priceRegionID <= 0 {
+		priceRegionID = DefaultPriceRegionID
+	}
+
+	transactions, err := s.store.ListTransactions(characterID)
+	if err != nil {
+		return nil, err
+	}
+	journal, err := s.store.ListJournalEntries(characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := CalculateProfitLoss(transactions, journal, method)
+	report.CharacterID = characterID
+
+	if s.market != nil && len(report.Unrealized) > 0 {
+		typeIDs := make([]int32, len(report.Unrealized))
+		for i, position := range report.Unrealized {
+			typeIDs[i] = position.TypeID
+		}
+
+		marketData, err := s.market.GetMarketData(ctx, MarketDataRequest{RegionID: priceRegionID, TypeIDs: typeIDs})
+		if err != nil {
+			// Realized results stay useful without prices
+			fmt.Printf("Warning: failed to price unrealized positions: %v\n", err)
+		} else {
+			prices := make(map[int32]float64, len(marketData.Data))
+			for typeID, price := range marketData.Data {
+				prices[typeID] = price.SellMin
+				if price.SellMin <= 0 {
+					prices[typeID] = price.BuyMax
+				}
+			}
+			ApplyMarketPrices(report, prices)
+			report.PriceRegionID = priceRegionID
+		}
+	}
+	return report, nil
+}
+
+// AverageCosts returns the FIFO purchase cost per unit of the remaining stock per type
+func (s *WalletService) AverageCosts(characterID int32) (map[int32]float64, error) {
+	transactions, err := s.store.ListTransactions(characterID)
+	if err != nil {
+		return nil, err
+	}
+	journal, err := s.store.ListJournalEntries(characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := CalculateProfitLoss(transactions, journal, CostMethodFIFO)
+	costs := make(map[int32]float64, len(report.Unrealized))
+	for _, position := range report.Unrealized {
+		costs[position.TypeID] = position.AverageCost
+	}
+	return costs, nil
+}
+
+// syncJournal walks the journal pages until it reaches stored entries. Entries
+// are only saved after a complete walk, since the stored newest entry is where
+// the next sync stops: saving the newest pages of a failed walk would leave a
+// gap behind them that is never fetched again.
+func (s *WalletService) syncJournal(ctx context.Context, characterID int32, accessToken string) (int, error) {
+	latest, err := s.store.LatestJournalID(characterID)
+	if err != nil {
+		return 0, err
+	}
+
+	var walked []models.WalletJournalEntry
+	totalPages := 1
+	for page := 1; page <= totalPages; page++ {
+		entries, pages, err := s.esiClient.GetWalletJournal(ctx, characterID, accessToken, page)
+		if err != nil {
+			return 0, err
+		}
+		totalPages = pages
+		walked = append(walked, entries...)
+
+		if reachedJournalID(entries, latest) {
+			break
+		}
+	}
+	return s.store.SaveJournalEntries(characterID, walked)
+}
+
+// syncTransactions walks back from the newest transaction until it reaches
+// stored ones and, like syncJournal, saves them after a complete walk only
+func (s *WalletService) syncTransactions(ctx context.Context, characterID int32, accessToken string) (int, error) {
+	latest, err := s.store.LatestTransactionID(characterID)
+	if err != nil {
+		return 0, err
+	}
+
+	var walked []models.WalletTransaction
+	var fromID int64
+	for batch := 0; batch < maxTransactionBatches; batch++ {
+		transactions, err := s.esiClient.GetWalletTransactions(ctx, characterID, accessToken, fromID)
+		if err != nil {
+			return 0, err
+		}
+		if len(transactions) == 0 {
+			break
+		}
+		walked = append(walked, transactions...)
+
+		oldest := transactions[0].TransactionID
+		for _, transaction := range transactions {
+			oldest = min(oldest, transaction.TransactionID)
+		}
+		if oldest <= latest {
+			break
+		}
+		fromID = oldest - 1
+	}
+	return s.store.SaveTransactions(characterID, walked)
+}
+
+// reachedJournalID reports whether a page contains the stored entry or older ones
+func reachedJournalID(entries []models.WalletJournalEntry, latest int64) bool {
+	if latest == 0 {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.ID <= latest {
+			return true
+		}
+	}
+	return false
+}
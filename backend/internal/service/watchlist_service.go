@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+)
+
+// Watchlist and saved search limits
+const (
+	MaxWatchlistsPerCharacter    = 50
+	MaxWatchlistItems            = MaxPriceStreamKeys
+	MaxSavedSearchesPerCharacter = 100
+	maxWatchlistNameLength       = 100
+	maxWatchlistNotesLength      = 2000
+)
+
+// Errors for watchlists and saved searches that do not exist or belong to another character
+var (
+	ErrWatchlistNotFound   = repository.ErrWatchlistNotFound
+	ErrSavedSearchNotFound = repository.ErrSavedSearchNotFound
+)
+
+// WatchlistService manages the named watchlists and saved item searches of
+// characters. A single watchlist is returned with live prices, loaded with one
+// market data request per target region for all items, so a page showing a
+// whole list needs a single call instead of one per item.
+type WatchlistService struct {
+	store  WatchlistRepository
+	market MarketDataProvider
+	items  ItemLookup
+	search ItemSearcher
+}
+
+func NewWatchlistService(store WatchlistRepository, market MarketDataProvider) *WatchlistService {
+	return &WatchlistService{
+		store:  store,
+		market: market,
+	}
+}
+
+// WithItems adds type names to watchlist items and rejects types not traded on the market
+func (s *WatchlistService) WithItems(items ItemLookup) *WatchlistService {
+	s.items = items
+	return s
+}
+
+// WithSearch enables running saved searches
+func (s *WatchlistService) WithSearch(search ItemSearcher) *WatchlistService {
+	s.search = search
+	return s
+}
+
+// ListWatchlists returns the name, notes and item count of the watchlists of a
+// character. Pricing all lists at once could mean thousands of types, so items
+// and prices are only returned by GetWatchlist.
+func (s *WatchlistService) ListWatchlists(characterID int32) ([]models.Watchlist, error) {
+	lists, err := s.store.ListWatchlists(characterID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lists {
+		lists[i].ItemCount = len(lists[i].Items)
+		lists[i].Items = nil
+	}
+	return lists, nil
+}
+
+// GetWatchlist returns one watchlist of a character with live prices
+func (s *WatchlistService) GetWatchlist(ctx context.Context, characterID int32, watchlistID int64) (*models.Watchlist, error) {
+	list, err := s.store.GetWatchlist(characterID, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	return s.pricedWatchlist(ctx, list)
+}
+
+// CreateWatchlist validates and stores a new watchlist of a character
+func (s *WatchlistService) CreateWatchlist(ctx context.Context, characterID int32, list models.Watchlist) (*models.Watchlist, error) {
+	if err := s.validateWatchlist(&list); err != nil {
+		return nil, err
+	}
+	existing, err := s.store.ListWatchlists(characterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxWatchlistsPerCharacter {
+		return nil, fmt.Errorf("%w: at most %d watchlists per character", ErrInvalidInput, MaxWatchlistsPerCharacter)
+	}
+
+	list.CharacterID = characterID
+	created, err := s.store.CreateWatchlist(&list)
+	if err != nil {
+		return nil, err
+	}
+	return s.pricedWatchlist(ctx, created)
+}
+
+// UpdateWatchlist replaces the name, notes and items of a watchlist of a character
+func (s *WatchlistService) UpdateWatchlist(ctx context.Context, characterID int32, watchlistID int64, list models.Watchlist) (*models.Watchlist, error) {
+	if err := s.validateWatchlist(&list); err != nil {
+		return nil, err
+	}
+
+	list.WatchlistID, list.CharacterID = watchlistID, characterID
+	updated, err := s.store.UpdateWatchlist(&list)
+	if err != nil {
+		return nil, err
+	}
+	return s.pricedWatchlist(ctx, updated)
+}
+
+// validateWatchlist normalizes a watchlist and checks that all item types are
+// traded on the market, so no item can fail the price request of its whole region
+func (s *WatchlistService) validateWatchlist(list *models.Watchlist) error {
+	if err := validateWatchlist(list); err != nil {
+		return err
+	}
+
+	validated := make(map[int32]bool, len(list.Items))
+	for _, item := range list.Items {
+		if validated[item.TypeID] {
+			continue
+		}
+		if err := validateMarketType(s.items, item.TypeID); err != nil {
+			return err
+		}
+		validated[item.TypeID] = true
+	}
+	return nil
+}
+
+// DeleteWatchlist removes a watchlist of a character
+func (s *WatchlistService) DeleteWatchlist(characterID int32, watchlistID int64) error {
+	return s.store.DeleteWatchlist(characterID, watchlistID)
+}
+
+// pricedWatchlist adds the item count, type names and live prices to all items.
+// Items of regions whose market data is unavailable keep a nil price.
+func (s *WatchlistService) pricedWatchlist(ctx context.Context, list *models.Watchlist) (*models.Watchlist, error) {
+	var keys []PriceKey
+	seen := make(map[PriceKey]bool)
+	for _, item := range list.Items {
+		key := PriceKey{RegionID: item.RegionID, TypeID: item.TypeID}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	markets := make(map[int32]*MarketDataResponse)
+	for regionID, typeIDs := range groupPriceKeys(keys) {
+		data, err := s.market.GetMarketData(ctx, MarketDataRequest{RegionID: regionID, TypeIDs: typeIDs})
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			continue
+		}
+		markets[regionID] = data
+	}
+
+	names := make(map[int32]string)
+	for i := range list.Items {
+		item := &list.Items[i]
+		item.TypeName = s.typeName(item.TypeID, names)
+		if data, ok := markets[item.RegionID]; ok {
+			item.Price = watchlistItemPrice(data, item)
+		}
+	}
+	list.ItemCount = len(list.Items)
+	return list, nil
+}
+
+func (s *WatchlistService) typeName(typeID int32, names map[int32]string) string {
+	if s.items == nil {
+		return ""
+	}
+	if name, ok := names[typeID]; ok {
+		return name
+	}
+	if item, err := s.items.GetItemByID(typeID); err == nil {
+		names[typeID] = item.TypeName
+	}
+	return names[typeID]
+}
+
+// watchlistItemPrice returns the regional price of an item, or for items
+// narrowed to a station the price of the orders at that station
+func watchlistItemPrice(data *MarketDataResponse, item *models.WatchlistItem) *models.ItemPrice {
+	regional, ok := data.Data[item.TypeID]
+	if !ok {
+		return nil
+	}
+	price := *regional
+	if item.StationID == 0 {
+		return &price
+	}
+
+	price.BuyMax, price.SellMin, price.BuyVolume, price.SellVolume = 0, 0, 0, 0
+	for _, order := range data.Orders[item.TypeID] {
+		if order.LocationID != item.StationID || order.VolumeRemain <= 0 {
+			continue
+		}
+		if order.IsBuyOrder {
+			price.BuyMax = max(price.BuyMax, order.Price)
+			price.BuyVolume += int64(order.VolumeRemain)
+		} else {
+			if price.SellMin == 0 || order.Price < price.SellMin {
+				price.SellMin = order.Price
+			}
+			price.SellVolume += int64(order.VolumeRemain)
+		}
+	}
+	return &price
+}
+
+// validateWatchlist checks a watchlist and resolves the target region of its items.
+// Items default to The Forge, the region of trade hub stations is known.
+func validateWatchlist(list *models.Watchlist) error {
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if len(list.Name) > maxWatchlistNameLength {
+		return fmt.Errorf("%w: name must not exceed %d characters", ErrInvalidInput, maxWatchlistNameLength)
+	}
+	if len(list.Notes) > maxWatchlistNotesLength {
+		return fmt.Errorf("%w: notes must not exceed %d characters", ErrInvalidInput, maxWatchlistNotesLength)
+	}
+	if len(list.Items) > MaxWatchlistItems {
+		return fmt.Errorf("%w: at most %d items per watchlist", ErrInvalidInput, MaxWatchlistItems)
+	}
+
+	items := make([]models.WatchlistItem, 0, len(list.Items))
+	for _, item := range list.Items {
+		if item.TypeID <= 0 || item.RegionID < 0 || item.StationID < 0 {
+			return fmt.Errorf("%w: invalid type %d, region %d or station %d", ErrInvalidInput, item.TypeID, item.RegionID, item.StationID)
+		}
+		if len(item.Note) > maxWatchlistNotesLength {
+			return fmt.Errorf("%w: notes must not exceed %d characters", ErrInvalidInput, maxWatchlistNotesLength)
+		}
+		if item.RegionID == 0 {
+			item.RegionID = DefaultHubRegion
+			if item.StationID != 0 {
+				hub, ok := hubByStation(item.StationID)
+				if !ok {
+					return fmt.Errorf("%w: region_id is required for station %d", ErrInvalidInput, item.StationID)
+				}
+				item.RegionID = hub.RegionID
+			}
+		}
+		items = append(items, models.WatchlistItem{
+			TypeID:    item.TypeID,
+			RegionID:  item.RegionID,
+			StationID: item.StationID,
+			Note:      strings.TrimSpace(item.Note),
+		})
+	}
+	list.Items = items
+	return nil
+}
+
+// hubByStation looks up one of the default hubs by its station
+func hubByStation(stationID int64) (MarketHub, bool) {
+	for _, hub := range DefaultMarketHubs {
+		if hub.StationID == stationID {
+			return hub, true
+		}
+	}
+	return MarketHub{}, false
+}
+
+// ListSearches returns the saved item searches of a character
+func (s *WatchlistService) ListSearches(characterID int32) ([]models.SavedSearch, error) {
+	return s.store.ListSavedSearches(characterID)
+}
+
+// GetSearch returns one saved item search of a character
+func (s *WatchlistService) GetSearch(characterID int32, searchID int64) (*models.SavedSearch, error) {
+	return s.store.GetSavedSearch(characterID, searchID)
+}
+
+// CreateSearch validates and stores a new item search of a character
+func (s *WatchlistService) CreateSearch(characterID int32, search models.SavedSearch) (*models.SavedSearch, error) {
+	if err := validateSavedSearch(&search); err != nil {
+		return nil, err
+	}
+	existing, err := s.store.ListSavedSearches(characterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxSavedSearchesPerCharacter {
+		return nil, fmt.Errorf("%w: at most %d saved searches per character", ErrInvalidInput, MaxSavedSearchesPerCharacter)
+	}
+
+	search.CharacterID = characterID
+	return s.store.CreateSavedSearch(&search)
+}
+
+// UpdateSearch replaces the query and filters of a saved search of a character
+func (s *WatchlistService) UpdateSearch(characterID int32, searchID int64, search models.SavedSearch) (*models.SavedSearch, error) {
+	if err := validateSavedSearch(&search); err != nil {
+		return nil, err
+	}
+
+	search.SearchID, search.CharacterID = searchID, characterID
+	return s.store.UpdateSavedSearch(&search)
+}
+
+// DeleteSearch removes a saved search of a character
+func (s *WatchlistService) DeleteSearch(characterID int32, searchID int64) error {
+	return s.store.DeleteSavedSearch(characterID, searchID)
+}
+
+// RunSearch runs a saved search, returning one page of its current results
+func (s *WatchlistService) RunSearch(characterID int32, searchID int64, limit, offset int) (*ItemSearchResult, error) {
+	if s.search == nil {
+		return nil, errors.New("item search is not available")
+	}
+	search, err := s.store.GetSavedSearch(characterID, searchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.search.Search(ItemSearchOptions{
+		Query:         search.Query,
+		Limit:         limit,
+		Offset:        offset,
+		CategoryID:    search.CategoryID,
+		MarketGroupID: search.MarketGroupID,
+		Published:     search.Published,
+		Marketable:    search.Marketable,
+	})
+}
+
+// validateSavedSearch checks a saved search and defaults its name and published filter
+func validateSavedSearch(search *models.SavedSearch) error {
+	search.Query = strings.TrimSpace(search.Query)
+	if len(normalizeSearchTokens(search.Query)) == 0 {
+		return fmt.Errorf("%w: search query must contain letters or digits", ErrInvalidInput)
+	}
+	if search.CategoryID < 0 || search.MarketGroupID < 0 {
+		return fmt.Errorf("%w: category and market group IDs must be positive", ErrInvalidInput)
+	}
+
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" {
+		search.Name = search.Query
+	}
+	if len(search.Name) > maxWatchlistNameLength {
+		return fmt.Errorf("%w: name must not exceed %d characters", ErrInvalidInput, maxWatchlistNameLength)
+	}
+	if search.Published == nil {
+		published := true
+		search.Published = &published
+	}
+	return nil
+}
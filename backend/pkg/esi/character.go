@@ -0,0 +1,116 @@
+package esi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// esiCharacter is the public character sheet as returned by ESI
+type esiCharacter struct {
+	Name           string    `json:"name"`
+	CorporationID  int32     `json:"corporation_id"`
+	AllianceID     int32     `json:"alliance_id"`
+	Birthday       time.Time `json:"birthday"`
+	SecurityStatus float64   `json:"security_status"`
+}
+
+// GetCharacterInfo retrieves the public character sheet. The endpoint needs no token.
+func (c *ESIClient) GetCharacterInfo(ctx context.Context, characterID int32) (*models.Character, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v5/characters/%d/", c.baseURL, characterID)
+
+	var character esiCharacter
+	if err := c.executeWithRetry(ctx, url, &character); err != nil {
+		return nil, err
+	}
+	return &models.Character{
+		CharacterID:    characterID,
+		CharacterName:  character.Name,
+		CorporationID:  character.CorporationID,
+		AllianceID:     character.AllianceID,
+		Birthday:       character.Birthday,
+		SecurityStatus: character.SecurityStatus,
+	}, nil
+}
+
+// GetCharacterAssets retrieves all assets of a character. All pages announced by
+// the X-Pages header are fetched sequentially.
+func (c *ESIClient) GetCharacterAssets(ctx context.Context, characterID int32, accessToken string) ([]models.CharacterAsset, error) {
+	allAssets := []models.CharacterAsset{}
+
+	totalPages := 1
+	for page := 1; page <= totalPages; page++ {
+		if err := c.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		url := fmt.Sprintf("%s/v5/characters/%d/assets/?page=%d", c.baseURL, characterID, page)
+
+		var assets []models.CharacterAsset
+		header, err := c.executeAuthenticated(ctx, url, accessToken, &assets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get page %d of character %d assets: %w", page, characterID, err)
+		}
+
+		allAssets = append(allAssets, assets...)
+
+		if page == 1 {
+			totalPages = parsePageCount(header)
+		}
+	}
+
+	return allAssets, nil
+}
+
+// GetCharacterWallet retrieves the wallet balance of a character
+func (c *ESIClient) GetCharacterWallet(ctx context.Context, characterID int32, accessToken string) (*models.CharacterWallet, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1/characters/%d/wallet/", c.baseURL, characterID)
+
+	// ESI returns the balance as a bare number
+	var balance float64
+	if _, err := c.executeAuthenticated(ctx, url, accessToken, &balance); err != nil {
+		return nil, err
+	}
+	return &models.CharacterWallet{Balance: balance}, nil
+}
+
+// GetCharacterOrders retrieves the open market orders of a character
+func (c *ESIClient) GetCharacterOrders(ctx context.Context, characterID int32, accessToken string) ([]models.CharacterOrder, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v2/characters/%d/orders/", c.baseURL, characterID)
+
+	orders := []models.CharacterOrder{}
+	if _, err := c.executeAuthenticated(ctx, url, accessToken, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetCharacterSkills retrieves the trained skills and skill points of a character
+func (c *ESIClient) GetCharacterSkills(ctx context.Context, characterID int32, accessToken string) (*models.CharacterSkills, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v4/characters/%d/skills/", c.baseURL, characterID)
+
+	var skills models.CharacterSkills
+	if _, err := c.executeAuthenticated(ctx, url, accessToken, &skills); err != nil {
+		return nil, err
+	}
+	skills.CharacterID = characterID
+	return &skills, nil
+}
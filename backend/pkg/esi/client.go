@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,12 +18,17 @@ const (
 	HeaderContentType = "Content-Type"
 	HeaderAccept      = "Accept"
 
+	HeaderAuthorization = "Authorization"
+
 	// Content types
 	ContentTypeJSON = "application/json"
 
 	// User agent
 	UserAgentValue = "EVE-Profit2/1.0"
 
+	// Pagination header returned by paged ESI endpoints
+	HeaderPages = "X-Pages"
+
 	// Error message templates
 	ErrCreateRequest  = "failed to create request: %w"
 	ErrRequestFailed  = "request failed: %w"
@@ -123,43 +129,59 @@ func (c *ESIClient) waitForRateLimit(ctx context.Context) error {
 
 // executeWithRetry performs HTTP request with retry logic
 func (c *ESIClient) executeWithRetry(ctx context.Context, url string, result interface{}) error {
+	_, err := c.executeWithRetryHeaders(ctx, url, result)
+	return err
+}
+
+// executeWithRetryHeaders performs HTTP request with retry logic and returns the response headers
+func (c *ESIClient) executeWithRetryHeaders(ctx context.Context, url string, result interface{}) (http.Header, error) {
+	return c.executeAuthenticated(ctx, url, "", result)
+}
+
+// executeAuthenticated performs HTTP request with retry logic, sending the
+// access token as Bearer token unless it is empty
+func (c *ESIClient) executeAuthenticated(ctx context.Context, url, accessToken string, result interface{}) (http.Header, error) {
 	var lastErr error
 
 	for attempt := 0; attempt <= c.retryLimit; attempt++ {
-		if err := c.performRequest(ctx, url, result); err != nil {
+		header, err := c.performRequest(ctx, url, accessToken, result)
+		if err != nil {
 			lastErr = err
 			if c.shouldRetry(err) && attempt < c.retryLimit {
 				continue
 			}
-			return lastErr
+			return nil, lastErr
 		}
-		return nil
+		return header, nil
 	}
-	return lastErr
+	return nil, lastErr
 }
 
 // performRequest performs a single HTTP request
-func (c *ESIClient) performRequest(ctx context.Context, url string, result interface{}) error {
-	req, err := c.createRequest(ctx, url)
+func (c *ESIClient) performRequest(ctx context.Context, url, accessToken string, result interface{}) (http.Header, error) {
+	req, err := c.createRequest(ctx, url, accessToken)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf(ErrRequestFailed, err)
+		return nil, fmt.Errorf(ErrRequestFailed, err)
 	}
 	defer resp.Body.Close()
 
 	if err := c.handleHTTPError(resp); err != nil {
-		return err
+		return nil, err
 	}
 
-	return json.NewDecoder(resp.Body).Decode(result)
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf(ErrDecodeResponse, err)
+	}
+	return resp.Header, nil
 }
 
 // createRequest creates a properly configured HTTP request
-func (c *ESIClient) createRequest(ctx context.Context, url string) (*http.Request, error) {
+func (c *ESIClient) createRequest(ctx context.Context, url, accessToken string) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf(ErrCreateRequest, err)
@@ -167,6 +189,9 @@ func (c *ESIClient) createRequest(ctx context.Context, url string) (*http.Reques
 
 	req.Header.Set(HeaderUserAgent, UserAgentValue)
 	req.Header.Set(HeaderAccept, ContentTypeJSON)
+	if accessToken != "" {
+		req.Header.Set(HeaderAuthorization, "Bearer "+accessToken)
+	}
 	return req, nil
 }
 
@@ -215,3 +240,54 @@ func (c *ESIClient) GetTypeInfo(ctx context.Context, typeID int32) (*models.Type
 	}
 	return &typeInfo, nil
 }
+
+// GetRegionOrders retrieves the complete order book (buy and sell orders of all types) for a region.
+// All pages announced by the X-Pages header are fetched sequentially.
+func (c *ESIClient) GetRegionOrders(ctx context.Context, regionID int32) ([]models.MarketOrder, error) {
+	var allOrders []models.MarketOrder
+
+	totalPages := 1
+	for page := 1; page <= totalPages; page++ {
+		if err := c.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		url := fmt.Sprintf("%s/v1/markets/%d/orders/?order_type=all&page=%d", c.baseURL, regionID, page)
+
+		var orders []models.MarketOrder
+		header, err := c.executeWithRetryHeaders(ctx, url, &orders)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get page %d of region %d orders: %w", page, regionID, err)
+		}
+
+		allOrders = append(allOrders, orders...)
+
+		if page == 1 {
+			totalPages = parsePageCount(header)
+		}
+	}
+
+	return allOrders, nil
+}
+
+// parsePageCount reads the X-Pages header, defaulting to a single page
+func parsePageCount(header http.Header) int {
+	pages, err := strconv.Atoi(header.Get(HeaderPages))
+	if err != nil || pages < 1 {
+		return 1
+	}
+	return pages
+}
+
+// GetMarketPrices retrieves average and adjusted prices for all types
+func (c *ESIClient) GetMarketPrices(ctx context.Context) ([]models.MarketPrice, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1/markets/prices/", c.baseURL)
+
+	var prices []models.MarketPrice
+	err := c.executeWithRetry(ctx, url, &prices)
+	return prices, err
+}
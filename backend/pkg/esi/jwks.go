@@ -0,0 +1,390 @@
+package esi
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// EVE SSO token verification defaults
+const (
+	DefaultSSOJWKSURL = "https://login.eveonline.com/oauth/jwks"
+	DefaultSSOIssuer  = "https://login.eveonline.com"
+
+	// SSOAudience is part of the aud claim of every EVE SSO token, next to the client ID
+	SSOAudience = "EVE Online"
+
+	DefaultJWKSRefreshInterval = time.Hour
+
+	// jwksUnknownKeyBackoff limits refetches caused by tokens with an unknown key ID
+	jwksUnknownKeyBackoff = 30 * time.Second
+
+	// jwksRetryBackoff delays the next fetch after the SSO failed to deliver the key set
+	jwksRetryBackoff = 30 * time.Second
+
+	// tokenClockSkew tolerates small clock differences to the SSO
+	tokenClockSkew = 30 * time.Second
+)
+
+// JWKSVerifier verifies EVE SSO access tokens against the SSO's JSON Web Key Set.
+// The key set is cached and refreshed in the background after the refresh interval.
+// Only tokens naming an unknown key wait for a fetch.
+type JWKSVerifier struct {
+	jwksURL         string
+	issuers         []string
+	audiences       []string
+	refreshInterval time.Duration
+	httpClient      *http.Client
+
+	mu           sync.Mutex
+	keys         map[string]crypto.PublicKey
+	refreshAt    time.Time  // Next scheduled fetch, pushed back by the retry backoff after failures
+	fetchErr     error      // Result of the last fetch
+	unknownFetch time.Time  // Last fetch caused by an unknown key ID
+	fetching     *jwksFetch // Running fetch, shared by all verifications that need it
+}
+
+// jwksFetch is a key set download that concurrent verifications wait for
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// JWKSOption configures the JWKS verifier
+type JWKSOption func(*JWKSVerifier)
+
+// NewJWKSVerifier creates a verifier. All audiences must be present in a token's
+// aud claim, the issuer is accepted with or without https:// as EVE SSO uses both.
+func NewJWKSVerifier(jwksURL, issuer string, audiences []string, options ...JWKSOption) *JWKSVerifier {
+	verifier := &JWKSVerifier{
+		jwksURL:         jwksURL,
+		issuers:         []string{issuer, strings.TrimPrefix(issuer, "https://")},
+		audiences:       audiences,
+		refreshInterval: DefaultJWKSRefreshInterval,
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+	}
+
+	for _, option := range options {
+		option(verifier)
+	}
+	return verifier
+}
+
+// WithJWKSRefreshInterval sets how long a fetched key set is used
+func WithJWKSRefreshInterval(interval time.Duration) JWKSOption {
+	return func(v *JWKSVerifier) {
+		v.refreshInterval = interval
+	}
+}
+
+// WithJWKSHTTPClient sets the HTTP client used to fetch the key set
+func WithJWKSHTTPClient(httpClient *http.Client) JWKSOption {
+	return func(v *JWKSVerifier) {
+		v.httpClient = httpClient
+	}
+}
+
+// jwtHeader is the JOSE header of an access token
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// VerifyAccessToken checks signature, issuer, audience and expiry of an access token
+func (v *JWKSVerifier) VerifyAccessToken(ctx context.Context, accessToken string) (*models.AccessClaims, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	key, err := v.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Algorithm, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	claims, err := ParseTokenClaims(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	characterID, err := claims.CharacterID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AccessClaims{
+		CharacterID:   characterID,
+		CharacterName: claims.Name,
+		Scopes:        claims.Scopes,
+		ExpiresAt:     claims.ExpiresAt.Time,
+	}, nil
+}
+
+// validateClaims checks issuer, audience and expiry
+func (v *JWKSVerifier) validateClaims(claims *TokenClaims) error {
+	validIssuer := false
+	for _, issuer := range v.issuers {
+		if claims.Issuer == issuer {
+			validIssuer = true
+		}
+	}
+	if !validIssuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+
+	for _, audience := range v.audiences {
+		if !containsString(claims.Audience, audience) {
+			return fmt.Errorf("%w: audience %q missing", ErrInvalidToken, audience)
+		}
+	}
+
+	if claims.ExpiresAt.IsZero() || time.Now().After(claims.ExpiresAt.Add(tokenClockSkew)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	return nil
+}
+
+// key returns the public key with the given ID. Cached keys are returned right
+// away and a due refresh runs in the background, so the SSO is never waited for
+// while the key set is usable. Unknown key IDs wait for a fetch, which is limited
+// by the unknown key backoff, and so does the very first verification.
+func (v *JWKSVerifier) key(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	now := time.Now()
+	due := !now.Before(v.refreshAt)
+	_, known := v.keys[keyID]
+
+	switch {
+	case known || (v.keys != nil && now.Sub(v.unknownFetch) < jwksUnknownKeyBackoff):
+		if due {
+			v.startFetchLocked(ctx)
+		}
+		defer v.mu.Unlock()
+		return v.lookupLocked(keyID)
+	case v.keys == nil && !due:
+		// No key set yet and the last fetch failed within the retry backoff
+		err := v.fetchErr
+		v.mu.Unlock()
+		return nil, err
+	}
+
+	if v.keys != nil {
+		v.unknownFetch = now
+	}
+	fetch := v.startFetchLocked(ctx)
+	v.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if fetch.err != nil && v.keys == nil {
+		return nil, fetch.err
+	}
+	return v.lookupLocked(keyID)
+}
+
+// startFetchLocked returns the running fetch or starts one, v.mu must be held
+func (v *JWKSVerifier) startFetchLocked(ctx context.Context) *jwksFetch {
+	if v.fetching != nil {
+		return v.fetching
+	}
+
+	fetch := &jwksFetch{done: make(chan struct{})}
+	v.fetching = fetch
+	// The fetch outlives the request that started it because other verifications wait for it
+	go v.runFetch(context.WithoutCancel(ctx), fetch)
+	return fetch
+}
+
+// runFetch downloads the key set and swaps it in under the mutex. After a failure
+// the next fetch is delayed by the retry backoff instead of being retried by every
+// verification.
+func (v *JWKSVerifier) runFetch(ctx context.Context, fetch *jwksFetch) {
+	keys, err := v.fetchKeys(ctx)
+
+	v.mu.Lock()
+	if err == nil {
+		v.keys = keys
+		v.refreshAt = time.Now().Add(v.refreshInterval)
+	} else {
+		v.refreshAt = time.Now().Add(jwksRetryBackoff)
+		if v.keys != nil {
+			// Keep verifying with the old key set until the SSO is reachable again
+			fmt.Printf("Warning: failed to refresh SSO key set: %v\n", err)
+		}
+	}
+	v.fetchErr = err
+	fetch.err = err
+	v.fetching = nil
+	v.mu.Unlock()
+
+	close(fetch.done)
+}
+
+// lookupLocked returns a cached key, v.mu must be held
+func (v *JWKSVerifier) lookupLocked(keyID string) (crypto.PublicKey, error) {
+	key, ok := v.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, keyID)
+	}
+	return key, nil
+}
+
+// jsonWebKey is one key of a JWKS document
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// fetchKeys downloads the key set. Keys of unsupported types are skipped.
+func (v *JWKSVerifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf(ErrCreateRequest, err)
+	}
+	req.Header.Set(HeaderAccept, ContentTypeJSON)
+	req.Header.Set(HeaderUserAgent, UserAgentValue)
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(ErrRequestFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("SSO key set request failed: status %d", resp.StatusCode)
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf(ErrDecodeResponse, err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	return keys, nil
+}
+
+// publicKey converts an RSA or P-256 key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+// verifySignature checks an RS256 or ES256 signature. Other algorithms, including "none", are rejected.
+func verifySignature(algorithm string, key crypto.PublicKey, signedContent string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signedContent))
+
+	switch algorithm {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature) != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, algorithm)
+	}
+	return nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, fmt.Errorf("malformed key parameter")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
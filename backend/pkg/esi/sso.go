@@ -0,0 +1,284 @@
+package esi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve-profit2/internal/models"
+)
+
+// ErrInvalidToken is returned when an SSO access token cannot be parsed
+var ErrInvalidToken = errors.New("invalid SSO token")
+
+// ErrInvalidGrant is returned when the SSO rejects an authorization code or refresh token
+var ErrInvalidGrant = errors.New("invalid grant")
+
+// GrantError is returned when the SSO rejects an authorization code or refresh
+// token, e.g. because the user revoked the application. It matches ErrInvalidGrant.
+type GrantError struct {
+	Description string
+}
+
+func (e *GrantError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrInvalidGrant, e.Description)
+}
+
+func (e *GrantError) Unwrap() error {
+	return ErrInvalidGrant
+}
+
+// InvalidGrant lets callers detect rejected grants without importing this package
+func (e *GrantError) InvalidGrant() bool {
+	return true
+}
+
+// EVE SSO defaults
+const (
+	DefaultSSOAuthorizeURL = "https://login.eveonline.com/v2/oauth/authorize"
+	DefaultSSOTokenURL     = "https://login.eveonline.com/v2/oauth/token"
+
+	ContentTypeForm = "application/x-www-form-urlencoded"
+
+	// characterSubjectPrefix precedes the character ID in the sub claim
+	characterSubjectPrefix = "CHARACTER:EVE:"
+)
+
+// SSOClient implements the OAuth2 authorization code flow with PKCE against EVE SSO
+type SSOClient struct {
+	authorizeURL string
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	callbackURL  string
+	httpClient   *http.Client
+}
+
+// SSOOption configures the SSO client
+type SSOOption func(*SSOClient)
+
+// NewSSOClient creates an SSO client for a registered EVE application.
+// The client secret is optional, PKCE alone is enough for public clients.
+func NewSSOClient(clientID, clientSecret, callbackURL string, options ...SSOOption) *SSOClient {
+	client := &SSOClient{
+		authorizeURL: DefaultSSOAuthorizeURL,
+		tokenURL:     DefaultSSOTokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		callbackURL:  callbackURL,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// WithSSOEndpoints sets the authorize and token endpoints, e.g. of a local fake SSO
+func WithSSOEndpoints(authorizeURL, tokenURL string) SSOOption {
+	return func(c *SSOClient) {
+		c.authorizeURL = authorizeURL
+		c.tokenURL = tokenURL
+	}
+}
+
+// WithSSOHTTPClient sets the HTTP client used for token requests
+func WithSSOHTTPClient(httpClient *http.Client) SSOOption {
+	return func(c *SSOClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// AuthorizeURL builds the login URL the user is sent to
+func (c *SSOClient) AuthorizeURL(state, codeChallenge string, scopes []string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"redirect_uri":          {c.callbackURL},
+		"client_id":             {c.clientID},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(c.authorizeURL, "?") {
+		separator = "&"
+	}
+	return c.authorizeURL + separator + params.Encode()
+}
+
+// ExchangeCode trades an authorization code and its PKCE verifier for tokens
+func (c *SSOClient) ExchangeCode(ctx context.Context, code, codeVerifier string) (*models.AuthToken, error) {
+	return c.requestToken(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {codeVerifier},
+		"redirect_uri":  {c.callbackURL},
+	})
+}
+
+// RefreshToken trades a refresh token for a new access token.
+// The SSO may rotate the refresh token, the returned one must be stored.
+func (c *SSOClient) RefreshToken(ctx context.Context, refreshToken string) (*models.AuthToken, error) {
+	return c.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// tokenResponse is the JSON body of the SSO token endpoint
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// tokenErrorResponse is the OAuth2 error body of the SSO token endpoint
+type tokenErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// requestToken posts a token request and fills in the character from the access token
+func (c *SSOClient) requestToken(ctx context.Context, form url.Values) (*models.AuthToken, error) {
+	form.Set("client_id", c.clientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf(ErrCreateRequest, err)
+	}
+	req.Header.Set(HeaderContentType, ContentTypeForm)
+	req.Header.Set(HeaderAccept, ContentTypeJSON)
+	req.Header.Set(HeaderUserAgent, UserAgentValue)
+	if c.clientSecret != "" {
+		req.SetBasicAuth(c.clientID, c.clientSecret)
+	}
+
+	issuedAt := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(ErrRequestFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr tokenErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&oauthErr)
+		if oauthErr.Error == "invalid_grant" {
+			return nil, &GrantError{Description: oauthErr.ErrorDescription}
+		}
+		return nil, fmt.Errorf("SSO token request failed: status %d %s", resp.StatusCode, oauthErr.Error)
+	}
+
+	var body tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf(ErrDecodeResponse, err)
+	}
+
+	claims, err := ParseTokenClaims(body.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	characterID, err := claims.CharacterID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthToken{
+		AccessToken:   body.AccessToken,
+		RefreshToken:  body.RefreshToken,
+		TokenType:     body.TokenType,
+		ExpiresIn:     body.ExpiresIn,
+		ExpiresAt:     issuedAt.Add(time.Duration(body.ExpiresIn) * time.Second),
+		CharacterID:   characterID,
+		CharacterName: claims.Name,
+		Scopes:        claims.Scopes,
+	}, nil
+}
+
+// TokenClaims are the claims of an EVE SSO access token (a JWT)
+type TokenClaims struct {
+	Subject   string      `json:"sub"`
+	Name      string      `json:"name"`
+	Owner     string      `json:"owner"`
+	Issuer    string      `json:"iss"`
+	Audience  stringList  `json:"aud"`
+	Scopes    stringList  `json:"scp"`
+	ExpiresAt numericTime `json:"exp"`
+	IssuedAt  numericTime `json:"iat"`
+}
+
+// CharacterID extracts the character ID from the "CHARACTER:EVE:<id>" subject
+func (c *TokenClaims) CharacterID() (int32, error) {
+	if !strings.HasPrefix(c.Subject, characterSubjectPrefix) {
+		return 0, fmt.Errorf("%w: unexpected subject %q", ErrInvalidToken, c.Subject)
+	}
+	characterID, err := strconv.ParseInt(strings.TrimPrefix(c.Subject, characterSubjectPrefix), 10, 32)
+	if err != nil || characterID <= 0 {
+		return 0, fmt.Errorf("%w: unexpected subject %q", ErrInvalidToken, c.Subject)
+	}
+	return int32(characterID), nil
+}
+
+// ParseTokenClaims decodes the claims of an access token without verifying its
+// signature. This is only safe for tokens received directly from the token endpoint.
+func ParseTokenClaims(accessToken string) (*TokenClaims, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrInvalidToken)
+	}
+
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	return &claims, nil
+}
+
+// stringList accepts a JSON string or an array of strings. EVE SSO sends a single
+// scope or audience as a plain string.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// numericTime is a JWT NumericDate, seconds since the epoch
+type numericTime struct {
+	time.Time
+}
+
+func (t *numericTime) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	t.Time = time.Unix(int64(seconds), 0)
+	return nil
+}
@@ -0,0 +1,46 @@
+package esi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"eve-profit2/internal/models"
+)
+
+// GetWalletJournal retrieves one page of a character's wallet journal, newest
+// entries first, and returns the number of pages announced by X-Pages
+func (c *ESIClient) GetWalletJournal(ctx context.Context, characterID int32, accessToken string, page int) ([]models.WalletJournalEntry, int, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v6/characters/%d/wallet/journal/?page=%d", c.baseURL, characterID, page)
+
+	var entries []models.WalletJournalEntry
+	header, err := c.executeAuthenticated(ctx, endpoint, accessToken, &entries)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get page %d of character %d wallet journal: %w", page, characterID, err)
+	}
+	return entries, parsePageCount(header), nil
+}
+
+// GetWalletTransactions retrieves up to 2500 market transactions of a character,
+// newest first. A fromID > 0 returns transactions with that ID or older.
+func (c *ESIClient) GetWalletTransactions(ctx context.Context, characterID int32, accessToken string, fromID int64) ([]models.WalletTransaction, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/characters/%d/wallet/transactions/", c.baseURL, characterID)
+	if fromID > 0 {
+		endpoint += "?" + url.Values{"from_id": {strconv.FormatInt(fromID, 10)}}.Encode()
+	}
+
+	var transactions []models.WalletTransaction
+	if _, err := c.executeAuthenticated(ctx, endpoint, accessToken, &transactions); err != nil {
+		return nil, fmt.Errorf("failed to get character %d wallet transactions: %w", characterID, err)
+	}
+	return transactions, nil
+}
@@ -0,0 +1,284 @@
+package fixtures
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// testSDESchema mirrors the subset of the Fuzzwork SDE schema used by the repository
+var testSDESchema = []string{
+	`CREATE TABLE invTypes (
+		typeID INTEGER PRIMARY KEY,
+		groupID INTEGER,
+		typeName TEXT,
+		description TEXT,
+		mass REAL,
+		volume REAL,
+		capacity REAL,
+		portionSize INTEGER,
+		raceID INTEGER,
+		basePrice REAL,
+		published INTEGER,
+		marketGroupID INTEGER,
+		iconID INTEGER,
+		soundID INTEGER,
+		graphicID INTEGER
+	)`,
+	`CREATE TABLE invGroups (
+		groupID INTEGER PRIMARY KEY,
+		categoryID INTEGER,
+		groupName TEXT,
+		iconID INTEGER,
+		useBasePrice INTEGER,
+		anchored INTEGER,
+		anchorable INTEGER,
+		fittableNonSingleton INTEGER,
+		published INTEGER
+	)`,
+	`CREATE TABLE invCategories (
+		categoryID INTEGER PRIMARY KEY,
+		categoryName TEXT,
+		iconID INTEGER,
+		published INTEGER
+	)`,
+	`CREATE TABLE invMarketGroups (
+		marketGroupID INTEGER PRIMARY KEY,
+		parentGroupID INTEGER,
+		marketGroupName TEXT,
+		description TEXT,
+		iconID INTEGER,
+		hasTypes INTEGER
+	)`,
+	`CREATE TABLE dgmAttributeTypes (
+		attributeID INTEGER PRIMARY KEY,
+		attributeName TEXT,
+		description TEXT,
+		iconID INTEGER,
+		defaultValue REAL,
+		published INTEGER,
+		displayName TEXT,
+		unitID INTEGER,
+		stackable INTEGER,
+		highIsGood INTEGER,
+		categoryID INTEGER
+	)`,
+	`CREATE TABLE dgmTypeAttributes (
+		typeID INTEGER,
+		attributeID INTEGER,
+		valueInt INTEGER,
+		valueFloat REAL
+	)`,
+	`CREATE TABLE eveUnits (
+		unitID INTEGER PRIMARY KEY,
+		unitName TEXT,
+		displayName TEXT,
+		description TEXT
+	)`,
+	`CREATE TABLE invMetaTypes (
+		typeID INTEGER PRIMARY KEY,
+		parentTypeID INTEGER,
+		metaGroupID INTEGER
+	)`,
+	`CREATE TABLE invMetaGroups (
+		metaGroupID INTEGER PRIMARY KEY,
+		metaGroupName TEXT,
+		description TEXT,
+		iconID INTEGER
+	)`,
+	`CREATE TABLE invTypeMaterials (
+		typeID INTEGER,
+		materialTypeID INTEGER,
+		quantity INTEGER
+	)`,
+	`CREATE TABLE staStations (
+		stationID INTEGER PRIMARY KEY,
+		security REAL,
+		stationTypeID INTEGER,
+		corporationID INTEGER,
+		solarSystemID INTEGER,
+		constellationID INTEGER,
+		regionID INTEGER,
+		stationName TEXT
+	)`,
+	`CREATE TABLE mapRegions (
+		regionID INTEGER PRIMARY KEY,
+		regionName TEXT
+	)`,
+	`CREATE TABLE mapSolarSystems (
+		regionID INTEGER,
+		constellationID INTEGER,
+		solarSystemID INTEGER PRIMARY KEY,
+		solarSystemName TEXT,
+		security REAL
+	)`,
+	`CREATE TABLE mapSolarSystemJumps (
+		fromRegionID INTEGER,
+		fromConstellationID INTEGER,
+		fromSolarSystemID INTEGER,
+		toSolarSystemID INTEGER,
+		toConstellationID INTEGER,
+		toRegionID INTEGER
+	)`,
+	`CREATE TABLE industryActivity (
+		typeID INTEGER,
+		activityID INTEGER,
+		time INTEGER
+	)`,
+	`CREATE TABLE industryActivityMaterials (
+		typeID INTEGER,
+		activityID INTEGER,
+		materialTypeID INTEGER,
+		quantity INTEGER
+	)`,
+	`CREATE TABLE industryActivityProducts (
+		typeID INTEGER,
+		activityID INTEGER,
+		productTypeID INTEGER,
+		quantity INTEGER
+	)`,
+	`CREATE TABLE industryActivitySkills (
+		typeID INTEGER,
+		activityID INTEGER,
+		skillID INTEGER,
+		level INTEGER
+	)`,
+}
+
+// testSDEData seeds a few well-known types: minerals, Veldspar, a Rifter and its blueprint,
+// cap boosters, an unpublished hull, four 200mm autocannon variants with dogma attributes
+// and a small star map around Jita with a high and a low security route to Nourvukaiken
+var testSDEData = []string{
+	`INSERT INTO invTypes (typeID, groupID, typeName, description, mass, volume, portionSize, published, marketGroupID) VALUES
+		(34, 18, 'Tritanium', 'The most common ore type in the known universe.', 0, 0.01, 1, 1, 1857),
+		(35, 18, 'Pyerite', 'Probably the most widely used ore.', 0, 0.01, 1, 1, 1857),
+		(36, 18, 'Mexallon', 'Very flexible metallic mineral.', 0, 0.01, 1, 1, 1857),
+		(1230, 462, 'Veldspar', 'The most common ore type in the known universe.', 0, 0.1, 100, 1, 518),
+		(587, 25, 'Rifter', 'The Rifter is a very powerful combat frigate.', 1067000, 27289, 1, 1, 64),
+		(691, 105, 'Rifter Blueprint', '', 0, 0.01, 1, 1, 261),
+		(3380, 268, 'Industry', 'Allows basic operation of factories.', 0, 0.01, 1, 1, 369),
+		(263, 87, 'Cap Booster 25', 'Provides a quick injection of power into your capacitor.', 0, 0.75, 1, 1, 139),
+		(3554, 87, 'Cap Booster 800', 'Provides a quick injection of power into your capacitor.', 0, 32, 1, 1, 139),
+		(29984, 25, 'Rifter Prototype', 'Unreleased test hull.', 1067000, 27289, 1, 0, NULL),
+		(2873, 55, '200mm AutoCannon I', 'A small projectile turret.', 500, 5, 1, 1, 574),
+		(9071, 55, '200mm Light Carbine Repeating Cannon I', 'A named projectile turret.', 500, 5, 1, 1, 574),
+		(2881, 55, '200mm AutoCannon II', 'An advanced projectile turret.', 500, 5, 1, 1, 574),
+		(13777, 55, 'Republic Fleet 200mm AutoCannon', 'A faction projectile turret.', 500, 5, 1, 1, 574)`,
+	`INSERT INTO invGroups (groupID, categoryID, groupName, published) VALUES
+		(18, 4, 'Mineral', 1),
+		(25, 6, 'Frigate', 1),
+		(105, 9, 'Frigate Blueprint', 1),
+		(55, 7, 'Projectile Weapon', 1),
+		(87, 8, 'Capacitor Booster Charge', 1),
+		(268, 16, 'Production', 1),
+		(462, 25, 'Veldspar', 1)`,
+	`INSERT INTO invCategories (categoryID, categoryName, published) VALUES
+		(4, 'Material', 1),
+		(6, 'Ship', 1),
+		(7, 'Module', 1),
+		(8, 'Charge', 1),
+		(9, 'Blueprint', 1),
+		(16, 'Skill', 1),
+		(25, 'Asteroid', 1),
+		(29, 'Abstract', 0)`,
+	`INSERT INTO invMarketGroups (marketGroupID, parentGroupID, marketGroupName, description, hasTypes) VALUES
+		(4, NULL, 'Ships', 'Capsuleer spaceships of all sizes and roles.', 0),
+		(1361, 4, 'Frigates', 'Small, fast vessels.', 0),
+		(64, 1361, 'Minmatar', 'Minmatar frigate designs.', 1),
+		(475, NULL, 'Manufacture & Research', 'Materials and blueprints.', 0),
+		(533, 475, 'Materials', 'Raw and refined materials.', 0),
+		(1857, 533, 'Minerals', 'Refined minerals.', 1),
+		(54, 475, 'Ore', 'Raw asteroid ore.', 0),
+		(518, 54, 'Veldspar', 'Veldspar variants.', 1),
+		(9, NULL, 'Ship Equipment', 'Modules for ship fittings.', 0),
+		(574, 9, 'Projectile Turrets', 'Small projectile turrets.', 1)`,
+	`INSERT INTO eveUnits (unitID, unitName, displayName) VALUES
+		(2, 'Kilogram', 'kg'),
+		(101, 'Milliseconds', 's'),
+		(104, 'Multiplier', 'x'),
+		(106, 'Teraflops', 'tf'),
+		(107, 'MegaWatts', 'MW')`,
+	`INSERT INTO dgmAttributeTypes (attributeID, attributeName, published, displayName, unitID, highIsGood) VALUES
+		(4, 'mass', 1, 'Mass', 2, 1),
+		(30, 'power', 1, 'Powergrid Usage', 107, 0),
+		(50, 'cpu', 1, 'CPU usage', 106, 0),
+		(51, 'speed', 1, 'Rate of fire', 101, 0),
+		(64, 'damageMultiplier', 1, 'Damage Modifier', 104, 1),
+		(422, 'techLevel', 1, 'Tech Level', NULL, 1),
+		(633, 'metaLevelOld', 1, 'Meta Level', NULL, 1),
+		(1211, 'heatDamage', 0, NULL, NULL, 0)`,
+	`INSERT INTO dgmTypeAttributes (typeID, attributeID, valueInt, valueFloat) VALUES
+		(587, 4, NULL, 1067000),
+		(587, 422, 1, NULL),
+		(2873, 30, NULL, 4), (2873, 50, NULL, 7), (2873, 51, NULL, 3000), (2873, 64, NULL, 2.25),
+		(2873, 422, 1, NULL), (2873, 633, 0, NULL), (2873, 1211, NULL, 1.5),
+		(9071, 30, NULL, 4), (9071, 50, NULL, 6), (9071, 51, NULL, 2850), (9071, 64, NULL, 2.36),
+		(9071, 422, 1, NULL), (9071, 633, 1, NULL),
+		(2881, 30, NULL, 5), (2881, 50, NULL, 8), (2881, 51, NULL, 2700), (2881, 64, NULL, 2.7),
+		(2881, 422, 2, NULL), (2881, 633, 5, NULL),
+		(13777, 30, NULL, 4), (13777, 50, NULL, 7), (13777, 51, NULL, 2565), (13777, 64, NULL, 2.7),
+		(13777, 422, 1, NULL), (13777, 633, 8, NULL)`,
+	`INSERT INTO invMetaGroups (metaGroupID, metaGroupName) VALUES
+		(1, 'Tech I'),
+		(2, 'Tech II'),
+		(4, 'Faction'),
+		(5, 'Officer'),
+		(6, 'Deadspace')`,
+	`INSERT INTO invMetaTypes (typeID, parentTypeID, metaGroupID) VALUES
+		(9071, 2873, 1),
+		(2881, 2873, 2),
+		(13777, 2873, 4)`,
+	`INSERT INTO invTypeMaterials (typeID, materialTypeID, quantity) VALUES
+		(1230, 34, 400),
+		(587, 34, 32000),
+		(587, 35, 6000),
+		(587, 36, 2500)`,
+	`INSERT INTO staStations (stationID, security, stationTypeID, solarSystemID, constellationID, regionID, stationName) VALUES
+		(60003760, 0.9, 1529, 30000142, 20000020, 10000002, 'Jita IV - Moon 4 - Caldari Navy Assembly Plant'),
+		(60001774, 0.8, 1529, 30002812, 20000410, 10000016, 'Nourvukaiken V - Moon 1 - Caldari Business Tribunal')`,
+	`INSERT INTO mapSolarSystems (regionID, constellationID, solarSystemID, solarSystemName, security) VALUES
+		(10000002, 20000020, 30000142, 'Jita', 0.9459),
+		(10000002, 20000020, 30000144, 'Perimeter', 0.9),
+		(10000002, 20000020, 30000139, 'Urlen', 0.96),
+		(10000016, 20000410, 30002813, 'Tama', 0.3),
+		(10000016, 20000410, 30002812, 'Nourvukaiken', 0.77)`,
+	`INSERT INTO mapSolarSystemJumps (fromSolarSystemID, toSolarSystemID) VALUES
+		(30000142, 30000144), (30000144, 30000142),
+		(30000144, 30000139), (30000139, 30000144),
+		(30000139, 30002812), (30002812, 30000139),
+		(30000142, 30002813), (30002813, 30000142),
+		(30002813, 30002812), (30002812, 30002813)`,
+	`INSERT INTO mapRegions (regionID, regionName) VALUES
+		(10000002, 'The Forge'),
+		(10000043, 'Domain')`,
+	`INSERT INTO industryActivity (typeID, activityID, time) VALUES (691, 1, 6000)`,
+	`INSERT INTO industryActivityMaterials (typeID, activityID, materialTypeID, quantity) VALUES
+		(691, 1, 34, 32000),
+		(691, 1, 35, 6000),
+		(691, 1, 36, 2500)`,
+	`INSERT INTO industryActivityProducts (typeID, activityID, productTypeID, quantity) VALUES (691, 1, 587, 1)`,
+	`INSERT INTO industryActivitySkills (typeID, activityID, skillID, level) VALUES (691, 1, 3380, 1)`,
+}
+
+// CreateTestSDEDatabase creates a small SDE SQLite database in a temporary directory
+// and returns its path
+func CreateTestSDEDatabase(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test-sde.sqlite")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to create test SDE database: %v", err)
+	}
+	defer db.Close()
+
+	for _, statement := range append(testSDESchema, testSDEData...) {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("failed to seed test SDE database: %v", err)
+		}
+	}
+
+	return path
+}
@@ -100,3 +100,23 @@ func TestCacheManagerSDEData(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, retrievedData)
 }
+
+func TestCacheManagerResetSDEDataKeepsMarketData(t *testing.T) {
+	// Arrange
+	cacheManager, err := cache.NewCacheManager()
+	require.NoError(t, err)
+	defer cacheManager.Close()
+
+	require.NoError(t, cacheManager.SetSDEData("item_34", map[string]string{"name": "Tritanium"}))
+	require.NoError(t, cacheManager.SetMarketData("market_34", map[string]float64{"sell": 5.5}, time.Minute))
+
+	// Act
+	err = cacheManager.ResetSDEData()
+
+	// Assert
+	require.NoError(t, err)
+	var item map[string]string
+	assert.Error(t, cacheManager.GetSDEData("item_34", &item))
+	var price map[string]float64
+	assert.NoError(t, cacheManager.GetMarketData("market_34", &price))
+}
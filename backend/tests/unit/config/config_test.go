@@ -21,7 +21,8 @@ func TestConfigLoadWithDefaults(t *testing.T) {
 	assert.Equal(t, "9000", cfg.ServerPort)
 	assert.Equal(t, "https://esi.evetech.net", cfg.ESIBaseURL)
 	assert.Equal(t, 150, cfg.ESIRateLimit)
-	assert.True(t, cfg.DebugMode) // Default is true in development
+	assert.True(t, cfg.DebugMode)         // Default is true in development
+	assert.False(t, cfg.TokenStoreMemory) // SSO tokens are persisted by default
 }
 
 func TestConfigLoadWithEnvironmentVariables(t *testing.T) {
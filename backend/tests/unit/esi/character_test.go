@@ -0,0 +1,126 @@
+package esi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/pkg/esi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestESIClientCharacterEndpoints tests the authenticated character endpoints
+func TestESIClientCharacterEndpoints(t *testing.T) {
+	t.Run("should map the public character sheet without token", func(t *testing.T) {
+		// Given: ESI server with a character sheet
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v5/characters/90000001/", r.URL.Path)
+			assert.Empty(t, r.Header.Get("Authorization"))
+			w.Header().Set(testContentType, testApplicationJSON)
+			w.Write([]byte(`{"name": "Test Pilot", "corporation_id": 98000001, "birthday": "2015-03-24T11:37:00Z", "security_status": 2.5}`))
+		}))
+		defer server.Close()
+
+		// When
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+		character, err := client.GetCharacterInfo(context.Background(), 90000001)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, int32(90000001), character.CharacterID)
+		assert.Equal(t, "Test Pilot", character.CharacterName)
+		assert.Equal(t, int32(98000001), character.CorporationID)
+		assert.Equal(t, 2.5, character.SecurityStatus)
+	})
+
+	t.Run("should fetch all asset pages with the access token", func(t *testing.T) {
+		// Given: ESI server with two asset pages
+		var requestedPages []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v5/characters/90000001/assets/", r.URL.Path)
+			assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+			requestedPages = append(requestedPages, r.URL.Query().Get("page"))
+			w.Header().Set(testContentType, testApplicationJSON)
+			w.Header().Set("X-Pages", "2")
+			w.Write([]byte(`[{"item_id": 1, "type_id": 34, "location_id": 60003760, "location_flag": "Hangar", "location_type": "station", "quantity": 1000}]`))
+		}))
+		defer server.Close()
+
+		// When
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+		assets, err := client.GetCharacterAssets(context.Background(), 90000001, "access-token")
+
+		// Then
+		require.NoError(t, err)
+		assert.Len(t, assets, 2)
+		assert.Equal(t, []string{"1", "2"}, requestedPages)
+		assert.Equal(t, "Hangar", assets[0].LocationFlag)
+	})
+
+	t.Run("should decode the bare wallet balance", func(t *testing.T) {
+		// Given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/characters/90000001/wallet/", r.URL.Path)
+			w.Header().Set(testContentType, testApplicationJSON)
+			w.Write([]byte(`1234567.89`))
+		}))
+		defer server.Close()
+
+		// When
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+		wallet, err := client.GetCharacterWallet(context.Background(), 90000001, "access-token")
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1234567.89, wallet.Balance)
+	})
+
+	t.Run("should decode orders and skills", func(t *testing.T) {
+		// Given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(testContentType, testApplicationJSON)
+			switch r.URL.Path {
+			case "/v2/characters/90000001/orders/":
+				w.Write([]byte(`[{"order_id": 5, "type_id": 34, "region_id": 10000002, "is_buy_order": true, "price": 4.5, "volume_remain": 100, "escrow": 450}]`))
+			case "/v4/characters/90000001/skills/":
+				w.Write([]byte(`{"total_sp": 5000000, "unallocated_sp": 1000, "skills": [{"skill_id": 3443, "trained_skill_level": 4, "active_skill_level": 4, "skillpoints_in_skill": 45255}]}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+
+		// When
+		orders, ordersErr := client.GetCharacterOrders(context.Background(), 90000001, "access-token")
+		skills, skillsErr := client.GetCharacterSkills(context.Background(), 90000001, "access-token")
+
+		// Then
+		require.NoError(t, ordersErr)
+		require.NoError(t, skillsErr)
+		require.Len(t, orders, 1)
+		assert.Equal(t, 450.0, orders[0].Escrow)
+		assert.Equal(t, int32(90000001), skills.CharacterID)
+		assert.Equal(t, int64(5000000), skills.TotalSP)
+		require.Len(t, skills.Skills, 1)
+		assert.Equal(t, int32(4), skills.Skills[0].TrainedSkillLevel)
+	})
+
+	t.Run("should fail on forbidden responses", func(t *testing.T) {
+		// Given: Token without the wallet scope
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		// When
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL), esi.WithRetryAttempts(0))
+		_, err := client.GetCharacterWallet(context.Background(), 90000001, "access-token")
+
+		// Then
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,337 @@
+package esi_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"eve-profit2/pkg/esi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "https://login.eveonline.com"
+
+// signRS256 creates a signed JWT with the given key ID
+func signRS256(t *testing.T, key *rsa.PrivateKey, keyID string, claims map[string]interface{}) string {
+	t.Helper()
+	content := jwtContent(t, "RS256", keyID, claims)
+	digest := sha256.Sum256([]byte(content))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return content + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func jwtContent(t *testing.T, algorithm, keyID string, claims map[string]interface{}) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": algorithm, "kid": keyID, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func rsaJWK(key *rsa.PrivateKey, keyID string) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": keyID,
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":  "CHARACTER:EVE:90000001",
+		"name": "Test Pilot",
+		"iss":  testIssuer,
+		"aud":  []string{"client-id", esi.SSOAudience},
+		"scp":  []string{"esi-wallet.read_character_wallet.v1"},
+		"exp":  time.Now().Add(20 * time.Minute).Unix(),
+	}
+}
+
+// newJWKSServer serves the current key set and counts fetches
+func newJWKSServer(t *testing.T, keys *atomic.Value, fetches *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(fetches, 1)
+		w.Header().Set(testContentType, testApplicationJSON)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys.Load()})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJWKSVerifierVerifyAccessToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var keys atomic.Value
+	keys.Store([]map[string]string{rsaJWK(key, "JWT-Signature-Key")})
+	var fetches int32
+	server := newJWKSServer(t, &keys, &fetches)
+	verifier := esi.NewJWKSVerifier(server.URL, testIssuer, []string{"client-id", esi.SSOAudience})
+
+	tests := []struct {
+		name        string
+		token       func() string
+		expectError bool
+	}{
+		{
+			name:  "should accept valid token",
+			token: func() string { return signRS256(t, key, "JWT-Signature-Key", validClaims()) },
+		},
+		{
+			name: "should accept issuer without scheme",
+			token: func() string {
+				claims := validClaims()
+				claims["iss"] = "login.eveonline.com"
+				return signRS256(t, key, "JWT-Signature-Key", claims)
+			},
+		},
+		{
+			name:        "should reject foreign signature",
+			token:       func() string { return signRS256(t, otherKey, "JWT-Signature-Key", validClaims()) },
+			expectError: true,
+		},
+		{
+			name: "should reject wrong issuer",
+			token: func() string {
+				claims := validClaims()
+				claims["iss"] = "https://evil.example"
+				return signRS256(t, key, "JWT-Signature-Key", claims)
+			},
+			expectError: true,
+		},
+		{
+			name: "should reject token for another application",
+			token: func() string {
+				claims := validClaims()
+				claims["aud"] = []string{"other-client", esi.SSOAudience}
+				return signRS256(t, key, "JWT-Signature-Key", claims)
+			},
+			expectError: true,
+		},
+		{
+			name: "should reject expired token",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-5 * time.Minute).Unix()
+				return signRS256(t, key, "JWT-Signature-Key", claims)
+			},
+			expectError: true,
+		},
+		{
+			name: "should reject unsigned token",
+			token: func() string {
+				return jwtContent(t, "none", "JWT-Signature-Key", validClaims()) + "."
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When: Verifying the token
+			claims, err := verifier.VerifyAccessToken(context.Background(), tt.token())
+
+			// Then: Valid tokens yield the character and scopes
+			if tt.expectError {
+				assert.ErrorIs(t, err, esi.ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int32(90000001), claims.CharacterID)
+			assert.Equal(t, "Test Pilot", claims.CharacterName)
+			assert.Equal(t, []string{"esi-wallet.read_character_wallet.v1"}, claims.Scopes)
+		})
+	}
+
+	// And: The key set was fetched only once
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func TestJWKSVerifierKeyRotation(t *testing.T) {
+	// Given: A key set that is rotated after the first verification
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var keys atomic.Value
+	keys.Store([]map[string]string{rsaJWK(oldKey, "key-1")})
+	var fetches int32
+	server := newJWKSServer(t, &keys, &fetches)
+	verifier := esi.NewJWKSVerifier(server.URL, testIssuer, []string{esi.SSOAudience})
+
+	_, err = verifier.VerifyAccessToken(context.Background(), signRS256(t, oldKey, "key-1", validClaims()))
+	require.NoError(t, err)
+
+	// When: A token signed with the new key arrives
+	keys.Store([]map[string]string{rsaJWK(newKey, "key-2")})
+	claims, err := verifier.VerifyAccessToken(context.Background(), signRS256(t, newKey, "key-2", validClaims()))
+
+	// Then: The unknown key ID triggers a refetch
+	require.NoError(t, err)
+	assert.Equal(t, int32(90000001), claims.CharacterID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+
+	// And: Another unknown key ID does not hit the SSO again right away
+	_, err = verifier.VerifyAccessToken(context.Background(), signRS256(t, newKey, "key-3", validClaims()))
+	assert.ErrorIs(t, err, esi.ErrInvalidToken)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestJWKSVerifierRefreshInterval(t *testing.T) {
+	// Given: A verifier whose key set expires immediately
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var keys atomic.Value
+	keys.Store([]map[string]string{rsaJWK(key, "key-1")})
+	var fetches int32
+	server := newJWKSServer(t, &keys, &fetches)
+	verifier := esi.NewJWKSVerifier(server.URL, testIssuer, nil, esi.WithJWKSRefreshInterval(0))
+	token := signRS256(t, key, "key-1", validClaims())
+
+	// When: Verifying twice
+	_, err = verifier.VerifyAccessToken(context.Background(), token)
+	require.NoError(t, err)
+	_, err = verifier.VerifyAccessToken(context.Background(), token)
+	require.NoError(t, err)
+
+	// Then: The second verification used the cached key and refreshed it in the background
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&fetches) == 2 }, time.Second, time.Millisecond)
+}
+
+func TestJWKSVerifierFailedRefreshBacksOff(t *testing.T) {
+	// Given: A verifier whose key set expires immediately and an SSO that fails after the first fetch
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set(testContentType, testApplicationJSON)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{rsaJWK(key, "key-1")}})
+	}))
+	t.Cleanup(server.Close)
+	verifier := esi.NewJWKSVerifier(server.URL, testIssuer, nil, esi.WithJWKSRefreshInterval(0))
+	token := signRS256(t, key, "key-1", validClaims())
+	_, err = verifier.VerifyAccessToken(context.Background(), token)
+	require.NoError(t, err)
+
+	// When: The background refresh fails
+	_, err = verifier.VerifyAccessToken(context.Background(), token)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&fetches) == 2 }, time.Second, time.Millisecond)
+
+	// Then: The stale key set keeps verifying tokens without hitting the SSO on every request
+	for i := 0; i < 5; i++ {
+		_, err = verifier.VerifyAccessToken(context.Background(), token)
+		require.NoError(t, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestJWKSVerifierSlowRefetch(t *testing.T) {
+	// Given: A verifier with a cached key set and an SSO that stalls on the next fetch
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			<-release
+		}
+		w.Header().Set(testContentType, testApplicationJSON)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{rsaJWK(key, "key-1")}})
+	}))
+	t.Cleanup(server.Close)
+	verifier := esi.NewJWKSVerifier(server.URL, testIssuer, nil)
+	known := signRS256(t, key, "key-1", validClaims())
+	_, err = verifier.VerifyAccessToken(context.Background(), known)
+	require.NoError(t, err)
+
+	// When: Tokens with an unknown key wait for the refetch
+	results := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := verifier.VerifyAccessToken(context.Background(), signRS256(t, key, "key-2", validClaims()))
+			results <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&fetches) == 2 }, time.Second, time.Millisecond)
+
+	// Then: Tokens with a known key are verified meanwhile
+	done := make(chan error, 1)
+	go func() {
+		_, err := verifier.VerifyAccessToken(context.Background(), known)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("verification of a known key waited for the key set fetch")
+	}
+
+	// And: The waiting verifications share one fetch
+	close(release)
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, <-results, esi.ErrInvalidToken)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestJWKSVerifierES256(t *testing.T) {
+	// Given: An EC key set
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pad := func(n *big.Int) []byte { return n.FillBytes(make([]byte, 32)) }
+	var keys atomic.Value
+	keys.Store([]map[string]string{{
+		"kty": "EC", "kid": "ec-1", "crv": "P-256",
+		"x": base64.RawURLEncoding.EncodeToString(pad(key.X)),
+		"y": base64.RawURLEncoding.EncodeToString(pad(key.Y)),
+	}})
+	var fetches int32
+	server := newJWKSServer(t, &keys, &fetches)
+	verifier := esi.NewJWKSVerifier(server.URL, testIssuer, []string{esi.SSOAudience})
+
+	content := jwtContent(t, "ES256", "ec-1", validClaims())
+	digest := sha256.Sum256([]byte(content))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	token := content + "." + base64.RawURLEncoding.EncodeToString(append(pad(r), pad(s)...))
+
+	// When: Verifying the token
+	claims, err := verifier.VerifyAccessToken(context.Background(), token)
+
+	// Then: The EC signature is accepted
+	require.NoError(t, err)
+	assert.Equal(t, int32(90000001), claims.CharacterID)
+}
@@ -239,3 +239,28 @@ func isHistoryRequest(req string) bool {
 		req == "/v1/markets/10000002/history/?type_id=35" ||
 		req == "/v1/markets/10000002/history/?type_id=36"
 }
+
+// TestESIClientGetMarketPrices tests retrieving universe-wide adjusted prices
+func TestESIClientGetMarketPrices(t *testing.T) {
+	t.Run("should retrieve average and adjusted prices", func(t *testing.T) {
+		// Given: Mock ESI server responding with market prices
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/markets/prices/", r.URL.Path)
+
+			w.Header().Set(testContentTypeHeader, testJSONContentType)
+			w.Write([]byte(`[{"type_id": 34, "average_price": 4.2, "adjusted_price": 3.9}]`))
+		}))
+		defer server.Close()
+
+		// When: ESI Client fetches market prices
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+		prices, err := client.GetMarketPrices(context.Background())
+
+		// Then: Should return the prices
+		assert.NoError(t, err)
+		assert.Len(t, prices, 1)
+		assert.Equal(t, int32(34), prices[0].TypeID)
+		assert.Equal(t, 4.2, prices[0].AveragePrice)
+		assert.Equal(t, 3.9, prices[0].AdjustedPrice)
+	})
+}
@@ -0,0 +1,94 @@
+package esi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/pkg/esi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestESIClientGetRegionOrders tests fetching complete paged regional order books
+func TestESIClientGetRegionOrders(t *testing.T) {
+	t.Run("should fetch all pages announced by X-Pages", func(t *testing.T) {
+		// Given: ESI server with a three page order book
+		var mu sync.Mutex
+		var requestedPages []string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/markets/10000002/orders/", r.URL.Path)
+			assert.Equal(t, "all", r.URL.Query().Get("order_type"))
+
+			page := r.URL.Query().Get("page")
+			mu.Lock()
+			requestedPages = append(requestedPages, page)
+			mu.Unlock()
+
+			w.Header().Set(testContentType, testApplicationJSON)
+			w.Header().Set("X-Pages", "3")
+			json.NewEncoder(w).Encode([]models.MarketOrder{
+				{OrderID: 1, TypeID: 34, Price: 5.0},
+			})
+		}))
+		defer server.Close()
+
+		// When: ESI Client fetches the region order book
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+		orders, err := client.GetRegionOrders(context.Background(), 10000002)
+
+		// Then: Every page should be requested once and merged
+		require.NoError(t, err)
+		assert.Len(t, orders, 3)
+		assert.Equal(t, []string{"1", "2", "3"}, requestedPages)
+	})
+
+	t.Run("should treat a missing X-Pages header as a single page", func(t *testing.T) {
+		// Given: ESI server without pagination header
+		requestCount := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			w.Header().Set(testContentType, testApplicationJSON)
+			w.Write([]byte(`[{"order_id": 1, "type_id": 34}]`))
+		}))
+		defer server.Close()
+
+		// When
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+		orders, err := client.GetRegionOrders(context.Background(), 10000002)
+
+		// Then
+		require.NoError(t, err)
+		assert.Len(t, orders, 1)
+		assert.Equal(t, 1, requestCount)
+	})
+
+	t.Run("should fail when a page cannot be fetched", func(t *testing.T) {
+		// Given: ESI server failing on the second page
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set(testContentType, testApplicationJSON)
+			w.Header().Set("X-Pages", "2")
+			w.Write([]byte(`[]`))
+		}))
+		defer server.Close()
+
+		// When
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+		orders, err := client.GetRegionOrders(context.Background(), 10000002)
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, orders)
+		assert.Contains(t, err.Error(), "page 2")
+	})
+}
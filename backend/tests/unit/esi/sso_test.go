@@ -0,0 +1,189 @@
+package esi_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"eve-profit2/pkg/esi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccessToken builds an unsigned JWT carrying EVE SSO claims
+func fakeAccessToken(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+}
+
+func TestSSOClientAuthorizeURL(t *testing.T) {
+	// Given: A client against a custom authorize endpoint
+	client := esi.NewSSOClient("client-id", "", "http://localhost:9000/callback",
+		esi.WithSSOEndpoints("https://sso.example/authorize", "https://sso.example/token"))
+
+	// When: Building the login URL
+	loginURL, err := url.Parse(client.AuthorizeURL("state-1", "challenge-1", []string{"esi-wallet.read_character_wallet.v1", "esi-assets.read_assets.v1"}))
+	require.NoError(t, err)
+
+	// Then: All OAuth2 and PKCE parameters are present
+	query := loginURL.Query()
+	assert.Equal(t, "sso.example", loginURL.Host)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "client-id", query.Get("client_id"))
+	assert.Equal(t, "http://localhost:9000/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "challenge-1", query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "esi-wallet.read_character_wallet.v1 esi-assets.read_assets.v1", query.Get("scope"))
+}
+
+func TestSSOClientExchangeCode(t *testing.T) {
+	t.Run("should exchange code and verifier for a character token", func(t *testing.T) {
+		// Given: A fake SSO that checks the PKCE verifier against the challenge
+		verifier := "test-verifier-with-enough-entropy-0123456789"
+		sum := sha256.Sum256([]byte(verifier))
+		challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+		accessToken := fakeAccessToken(t, map[string]interface{}{
+			"sub":  "CHARACTER:EVE:90000001",
+			"name": "Test Pilot",
+			"scp":  []string{"esi-wallet.read_character_wallet.v1", "esi-assets.read_assets.v1"},
+			"exp":  time.Now().Add(20 * time.Minute).Unix(),
+		})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, esi.ContentTypeForm, r.Header.Get("Content-Type"))
+			assert.Equal(t, "authorization_code", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "auth-code", r.PostForm.Get("code"))
+			assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+
+			user, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "client-id", user)
+			assert.Equal(t, "secret", password)
+
+			got := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+			if base64.RawURLEncoding.EncodeToString(got[:]) != challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  accessToken,
+				"token_type":    "Bearer",
+				"expires_in":    1199,
+				"refresh_token": "refresh-1",
+			})
+		}))
+		defer server.Close()
+
+		client := esi.NewSSOClient("client-id", "secret", "http://localhost:9000/callback",
+			esi.WithSSOEndpoints(server.URL+"/authorize", server.URL+"/token"))
+
+		// When: Exchanging the code
+		token, err := client.ExchangeCode(context.Background(), "auth-code", verifier)
+
+		// Then: The token carries the character from the JWT claims
+		require.NoError(t, err)
+		assert.Equal(t, int32(90000001), token.CharacterID)
+		assert.Equal(t, "Test Pilot", token.CharacterName)
+		assert.Equal(t, "refresh-1", token.RefreshToken)
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.Len(t, token.Scopes, 2)
+		assert.WithinDuration(t, time.Now().Add(1199*time.Second), token.ExpiresAt, 5*time.Second)
+
+		// And: A wrong verifier is rejected as invalid grant
+		_, err = client.ExchangeCode(context.Background(), "auth-code", "wrong-verifier")
+		assert.True(t, errors.Is(err, esi.ErrInvalidGrant))
+	})
+}
+
+func TestSSOClientRefreshToken(t *testing.T) {
+	// Given: A fake SSO answering refresh requests with a single scope as plain string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "refresh-1", r.PostForm.Get("refresh_token"))
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fakeAccessToken(t, map[string]interface{}{
+				"sub":  "CHARACTER:EVE:90000001",
+				"name": "Test Pilot",
+				"scp":  "esi-wallet.read_character_wallet.v1",
+			}),
+			"token_type":    "Bearer",
+			"expires_in":    1199,
+			"refresh_token": "refresh-2",
+		})
+	}))
+	defer server.Close()
+
+	client := esi.NewSSOClient("client-id", "", "http://localhost:9000/callback",
+		esi.WithSSOEndpoints(server.URL+"/authorize", server.URL+"/token"))
+
+	// When: Refreshing the token
+	token, err := client.RefreshToken(context.Background(), "refresh-1")
+
+	// Then: The rotated refresh token and the scope are returned
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-2", token.RefreshToken)
+	assert.Equal(t, []string{"esi-wallet.read_character_wallet.v1"}, token.Scopes)
+}
+
+func TestParseTokenClaims(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		expectedID  int32
+		expectError bool
+	}{
+		{
+			name:       "should parse character subject",
+			token:      fakeAccessToken(t, map[string]interface{}{"sub": "CHARACTER:EVE:123", "aud": []string{"client-id", "EVE Online"}}),
+			expectedID: 123,
+		},
+		{
+			name:        "should reject non JWT",
+			token:       "not-a-jwt",
+			expectError: true,
+		},
+		{
+			name:        "should reject unexpected subject",
+			token:       fakeAccessToken(t, map[string]interface{}{"sub": "CORPORATION:EVE:123"}),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := esi.ParseTokenClaims(tt.token)
+			if err == nil {
+				var characterID int32
+				characterID, err = claims.CharacterID()
+				if !tt.expectError {
+					assert.Equal(t, tt.expectedID, characterID)
+				}
+			}
+
+			if tt.expectError {
+				assert.ErrorIs(t, err, esi.ErrInvalidToken)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
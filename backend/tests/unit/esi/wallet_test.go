@@ -0,0 +1,65 @@
+package esi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/pkg/esi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestESIClientWalletEndpoints tests the wallet journal and transaction endpoints
+func TestESIClientWalletEndpoints(t *testing.T) {
+	t.Run("should return a journal page with the page count", func(t *testing.T) {
+		// Given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v6/characters/90000001/wallet/journal/", r.URL.Path)
+			assert.Equal(t, "2", r.URL.Query().Get("page"))
+			assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+			w.Header().Set(testContentType, testApplicationJSON)
+			w.Header().Set("X-Pages", "3")
+			w.Write([]byte(`[{"id": 7, "date": "2026-10-01T12:00:00Z", "ref_type": "transaction_tax", "amount": -15, "context_id": 2, "context_id_type": "market_transaction_id"}]`))
+		}))
+		defer server.Close()
+
+		// When
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+		entries, pages, err := client.GetWalletJournal(context.Background(), 90000001, "access-token", 2)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 3, pages)
+		require.Len(t, entries, 1)
+		assert.Equal(t, int64(2), entries[0].ContextID)
+		assert.Equal(t, -15.0, entries[0].Amount)
+	})
+
+	t.Run("should page transactions with from_id", func(t *testing.T) {
+		// Given
+		var fromIDs []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/characters/90000001/wallet/transactions/", r.URL.Path)
+			fromIDs = append(fromIDs, r.URL.Query().Get("from_id"))
+			w.Header().Set(testContentType, testApplicationJSON)
+			w.Write([]byte(`[{"transaction_id": 5, "date": "2026-10-01T12:00:00Z", "type_id": 34, "quantity": 10, "unit_price": 5.5, "location_id": 60003760, "is_buy": true, "journal_ref_id": 9}]`))
+		}))
+		defer server.Close()
+		client := esi.NewESIClient(esi.WithBaseURL(server.URL))
+
+		// When
+		_, err := client.GetWalletTransactions(context.Background(), 90000001, "access-token", 0)
+		require.NoError(t, err)
+		transactions, err := client.GetWalletTransactions(context.Background(), 90000001, "access-token", 4)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "4"}, fromIDs)
+		require.Len(t, transactions, 1)
+		assert.True(t, transactions[0].IsBuy)
+		assert.Equal(t, int64(9), transactions[0].JournalRefID)
+	})
+}
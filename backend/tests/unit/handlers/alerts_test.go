@@ -0,0 +1,186 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAlertEngine for testing
+type MockAlertEngine struct {
+	mock.Mock
+}
+
+func (m *MockAlertEngine) ListRules(characterID int32) ([]models.AlertRule, error) {
+	args := m.Called(characterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertEngine) GetRule(characterID int32, ruleID int64) (*models.AlertRule, error) {
+	args := m.Called(characterID, ruleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertEngine) CreateRule(characterID int32, rule models.AlertRule) (*models.AlertRule, error) {
+	args := m.Called(characterID, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertEngine) UpdateRule(characterID int32, ruleID int64, rule models.AlertRule) (*models.AlertRule, error) {
+	args := m.Called(characterID, ruleID, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertEngine) DeleteRule(characterID int32, ruleID int64) error {
+	return m.Called(characterID, ruleID).Error(0)
+}
+
+func (m *MockAlertEngine) Channels() []string {
+	return []string{models.AlertChannelWebhook}
+}
+
+func setupAlertRouter(mockAlerts *MockAlertEngine, authenticated bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewAlertHandler(mockAlerts)
+
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) { c.Set(middleware.ContextCharacterID, int32(90000001)) })
+	}
+	router.GET("/api/v1/alerts", handler.ListRules)
+	router.POST("/api/v1/alerts", handler.CreateRule)
+	router.GET("/api/v1/alerts/:ruleID", handler.GetRule)
+	router.PUT("/api/v1/alerts/:ruleID", handler.UpdateRule)
+	router.DELETE("/api/v1/alerts/:ruleID", handler.DeleteRule)
+	return router
+}
+
+func TestAlertHandler(t *testing.T) {
+	rule := models.AlertRule{Name: "Cheap", TypeID: 34, Expression: "Jita sell < 5", Enabled: true}
+	disabled := rule
+	disabled.Enabled = false
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            string
+		unauthenticated bool
+		mockSetup       func(*MockAlertEngine)
+		expectedStatus  int
+	}{
+		{
+			name:   "should list the rules of the authenticated character",
+			method: "GET",
+			path:   "/api/v1/alerts",
+			mockSetup: func(m *MockAlertEngine) {
+				m.On("ListRules", int32(90000001)).Return([]models.AlertRule{rule}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "should create enabled rules by default",
+			method: "POST",
+			path:   "/api/v1/alerts",
+			body:   `{"name":"Cheap","type_id":34,"expression":"Jita sell < 5"}`,
+			mockSetup: func(m *MockAlertEngine) {
+				m.On("CreateRule", int32(90000001), rule).Return(&rule, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "should replace a rule",
+			method: "PUT",
+			path:   "/api/v1/alerts/7",
+			body:   `{"name":"Cheap","type_id":34,"expression":"Jita sell < 5","enabled":false}`,
+			mockSetup: func(m *MockAlertEngine) {
+				m.On("UpdateRule", int32(90000001), int64(7), disabled).Return(&disabled, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "should return 400 for rejected rules",
+			method: "POST",
+			path:   "/api/v1/alerts",
+			body:   `{"type_id":34,"expression":"sell"}`,
+			mockSetup: func(m *MockAlertEngine) {
+				m.On("CreateRule", int32(90000001), mock.Anything).Return(nil, fmt.Errorf("%w: no operator", service.ErrInvalidInput))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 without expression",
+			method:         "POST",
+			path:           "/api/v1/alerts",
+			body:           `{"type_id":34}`,
+			mockSetup:      func(m *MockAlertEngine) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 for invalid rule IDs",
+			method:         "GET",
+			path:           "/api/v1/alerts/abc",
+			mockSetup:      func(m *MockAlertEngine) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "should return 404 for rules of other characters",
+			method: "DELETE",
+			path:   "/api/v1/alerts/8",
+			mockSetup: func(m *MockAlertEngine) {
+				m.On("DeleteRule", int32(90000001), int64(8)).Return(service.ErrAlertRuleNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:            "should return 401 without authentication",
+			method:          "GET",
+			path:            "/api/v1/alerts",
+			unauthenticated: true,
+			mockSetup:       func(m *MockAlertEngine) {},
+			expectedStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockAlerts := new(MockAlertEngine)
+			tt.mockSetup(mockAlerts)
+			router := setupAlertRouter(mockAlerts, !tt.unauthenticated)
+
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockAlerts.AssertExpectations(t)
+		})
+	}
+}
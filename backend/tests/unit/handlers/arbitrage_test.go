@@ -0,0 +1,149 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockArbitrageService for testing
+type MockArbitrageService struct {
+	mock.Mock
+}
+
+func (m *MockArbitrageService) GetOpportunities(page, pageSize int, minProfit float64) (*service.ArbitragePage, error) {
+	args := m.Called(page, pageSize, minProfit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ArbitragePage), args.Error(1)
+}
+
+func (m *MockArbitrageService) TriggerScan() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func setupArbitrageRouter(mockService *MockArbitrageService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewArbitrageHandler(mockService)
+
+	router := gin.New()
+	router.GET("/api/v1/arbitrage/opportunities", handler.GetOpportunities)
+	router.POST("/api/v1/arbitrage/scan", handler.TriggerScan)
+	return router
+}
+
+func TestArbitrageHandlerGetOpportunities(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockArbitrageService)
+		expectedStatus int
+	}{
+		{
+			name:  "should return requested page",
+			query: "?page=2&page_size=10&min_profit=1000000",
+			mockSetup: func(m *MockArbitrageService) {
+				m.On("GetOpportunities", 2, 10, 1000000.0).Return(&service.ArbitragePage{
+					Opportunities: []service.ArbitrageOpportunity{{TypeID: 34, Profit: 2000000}},
+					Page:          2,
+					PageSize:      10,
+					Total:         11,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "should use default paging",
+			query: "",
+			mockSetup: func(m *MockArbitrageService) {
+				m.On("GetOpportunities", 1, service.DefaultPageSize, 0.0).Return(&service.ArbitragePage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for invalid page",
+			query:          "?page=abc",
+			mockSetup:      func(m *MockArbitrageService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 for invalid min_profit",
+			query:          "?min_profit=lots",
+			mockSetup:      func(m *MockArbitrageService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "should return 503 before the first scan",
+			query: "",
+			mockSetup: func(m *MockArbitrageService) {
+				m.On("GetOpportunities", 1, service.DefaultPageSize, 0.0).Return(nil, service.ErrNoScanAvailable)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := &MockArbitrageService{}
+			tt.mockSetup(mockService)
+			router := setupArbitrageRouter(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/arbitrage/opportunities"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestArbitrageHandlerTriggerScan(t *testing.T) {
+	t.Run("should accept a new scan", func(t *testing.T) {
+		// Arrange
+		mockService := &MockArbitrageService{}
+		mockService.On("TriggerScan").Return(true)
+		router := setupArbitrageRouter(mockService)
+
+		// Act
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/arbitrage/scan", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, true, response["success"])
+	})
+
+	t.Run("should reject a scan while one is running", func(t *testing.T) {
+		// Arrange
+		mockService := &MockArbitrageService{}
+		mockService.On("TriggerScan").Return(false)
+		router := setupArbitrageRouter(mockService)
+
+		// Act
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/arbitrage/scan", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -0,0 +1,245 @@
+package handlers_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/service"
+	"eve-profit2/pkg/esi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeSSOServer issues codes for logins and checks the PKCE verifier on exchange
+func newFakeSSOServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	challenges := make(map[string]string) // code -> challenge
+
+	accessToken := func(n int) string {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"sub":  "CHARACTER:EVE:90000001",
+			"name": "Test Pilot",
+			"scp":  []string{"esi-wallet.read_character_wallet.v1"},
+			"jti":  n,
+		})
+		return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+	}
+	issued := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		// The fake user approves immediately and is sent back with a code
+		mu.Lock()
+		challenges["code-1"] = r.URL.Query().Get("code_challenge")
+		mu.Unlock()
+		callback := r.URL.Query().Get("redirect_uri") + "?" + url.Values{"code": {"code-1"}, "state": {r.URL.Query().Get("state")}}.Encode()
+		http.Redirect(w, r, callback, http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.PostForm.Get("grant_type") == "authorization_code" {
+			sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+			if challenges[r.PostForm.Get("code")] != base64.RawURLEncoding.EncodeToString(sum[:]) {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+		}
+
+		issued++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  accessToken(issued),
+			"token_type":    "Bearer",
+			"expires_in":    1199,
+			"refresh_token": "refresh-token",
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func setupAuthRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	sso := newFakeSSOServer(t)
+	t.Cleanup(sso.Close)
+
+	client := esi.NewSSOClient("client-id", "", "http://localhost:9000/callback",
+		esi.WithSSOEndpoints(sso.URL+"/authorize", sso.URL+"/token"))
+	authService := service.NewAuthService(client, service.NewMemoryTokenStore(), []string{"esi-wallet.read_character_wallet.v1"})
+	handler := handlers.NewAuthHandler(authService)
+
+	router := gin.New()
+	router.GET("/api/v1/auth/login", handler.InitiateLogin)
+	router.GET("/callback", handler.HandleCallback)
+	router.POST("/api/v1/auth/refresh", handler.RefreshToken)
+	return router
+}
+
+// loginThroughFakeSSO runs InitiateLogin and follows the fake SSO redirect,
+// returning the callback URL and the nonce cookie
+func loginThroughFakeSSO(t *testing.T, router *gin.Engine) (*url.URL, *http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/login?redirect=true", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+
+	var nonce *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == handlers.NonceCookieName {
+			nonce = cookie
+		}
+	}
+	require.NotNil(t, nonce)
+	assert.True(t, nonce.HttpOnly)
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirect.Get(w.Header().Get("Location"))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return callback, nonce
+}
+
+func TestAuthHandlerLoginFlow(t *testing.T) {
+	t.Run("should log in and refresh against the fake SSO", func(t *testing.T) {
+		// Arrange
+		router := setupAuthRouter(t)
+		callback, nonce := loginThroughFakeSSO(t, router)
+
+		// Act
+		req := httptest.NewRequest(http.MethodGet, "/callback?"+callback.RawQuery, nil)
+		req.AddCookie(nonce)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "refresh-token")
+
+		var response struct {
+			Data handlers.LoginResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int32(90000001), response.Data.CharacterID)
+		assert.Equal(t, "Test Pilot", response.Data.CharacterName)
+
+		// Act: Refresh with the issued access token
+		refresh := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", strings.NewReader(`{"character_id":90000001}`))
+		refresh.Header.Set("Content-Type", "application/json")
+		refresh.Header.Set("Authorization", "Bearer "+response.Data.AccessToken)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, refresh)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		var refreshed struct {
+			Data handlers.LoginResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshed))
+		assert.NotEqual(t, response.Data.AccessToken, refreshed.Data.AccessToken)
+	})
+
+	tests := []struct {
+		name           string
+		modify         func(req *http.Request, callback *url.URL, nonce *http.Cookie) string
+		expectedStatus int
+	}{
+		{
+			name: "should reject callback without nonce cookie",
+			modify: func(req *http.Request, callback *url.URL, nonce *http.Cookie) string {
+				return callback.RawQuery
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should reject tampered state",
+			modify: func(req *http.Request, callback *url.URL, nonce *http.Cookie) string {
+				req.AddCookie(nonce)
+				query := callback.Query()
+				query.Set("state", "tampered")
+				return query.Encode()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should report invalid code as SSO failure",
+			modify: func(req *http.Request, callback *url.URL, nonce *http.Cookie) string {
+				req.AddCookie(nonce)
+				query := callback.Query()
+				query.Set("code", "unknown-code")
+				return query.Encode()
+			},
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			name: "should pass on SSO errors",
+			modify: func(req *http.Request, callback *url.URL, nonce *http.Cookie) string {
+				req.AddCookie(nonce)
+				return "error=access_denied&state=" + url.QueryEscape(callback.Query().Get("state"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := setupAuthRouter(t)
+			callback, nonce := loginThroughFakeSSO(t, router)
+			req := httptest.NewRequest(http.MethodGet, "/callback", nil)
+			req.URL.RawQuery = tt.modify(req, callback, nonce)
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAuthHandlerRefreshTokenUnauthorized(t *testing.T) {
+	// Arrange
+	router := setupAuthRouter(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", strings.NewReader(`{"character_id":90000001}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer forged")
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthHandlerLoginNotConfigured(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewAuthHandler(nil)
+	router := gin.New()
+	router.GET("/api/v1/auth/login", handler.InitiateLogin)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/login", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
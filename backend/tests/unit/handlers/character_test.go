@@ -0,0 +1,124 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubCharacterService returns fixed data and fails for characters without token
+type stubCharacterService struct{}
+
+func (s *stubCharacterService) GetCharacterInfo(ctx context.Context, characterID int32) (*models.Character, error) {
+	return &models.Character{CharacterID: characterID, CharacterName: "Test Pilot"}, nil
+}
+
+func (s *stubCharacterService) GetCharacterAssets(ctx context.Context, characterID int32) ([]models.CharacterAsset, error) {
+	return []models.CharacterAsset{{ItemID: 1, TypeID: 34, Quantity: 100}}, nil
+}
+
+func (s *stubCharacterService) GetCharacterWallet(ctx context.Context, characterID int32) (*models.CharacterWallet, error) {
+	if characterID == 90000002 {
+		return nil, service.ErrLoginRequired
+	}
+	return &models.CharacterWallet{Balance: 1000}, nil
+}
+
+func (s *stubCharacterService) GetCharacterOrders(ctx context.Context, characterID int32) ([]models.CharacterOrder, error) {
+	return []models.CharacterOrder{}, nil
+}
+
+func (s *stubCharacterService) GetCharacterSkills(ctx context.Context, characterID int32) (*models.CharacterSkills, error) {
+	return &models.CharacterSkills{CharacterID: characterID}, nil
+}
+
+func (s *stubCharacterService) GetTradeProfile(ctx context.Context, characterID int32) (*models.TradeProfile, error) {
+	return &models.TradeProfile{CharacterID: characterID, MaxOrders: 305}, nil
+}
+
+func TestCharacterHandlerEndpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		service        handlers.CharacterService
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "should return character info",
+			service:        &stubCharacterService{},
+			path:           "/api/v1/characters/90000001/info",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"character_name":"Test Pilot"`,
+		},
+		{
+			name:           "should return assets",
+			service:        &stubCharacterService{},
+			path:           "/api/v1/characters/90000001/assets",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"type_id":34`,
+		},
+		{
+			name:           "should require login for a revoked character",
+			service:        &stubCharacterService{},
+			path:           "/api/v1/characters/90000002/wallet",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should return the trade profile",
+			service:        &stubCharacterService{},
+			path:           "/api/v1/characters/90000001/trade-profile",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"max_orders":305`,
+		},
+		{
+			name:           "should reject invalid character ID",
+			service:        &stubCharacterService{},
+			path:           "/api/v1/characters/abc/skills",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should report missing service",
+			path:           "/api/v1/characters/90000001/orders",
+			expectedStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			handler := handlers.NewCharacterHandler(tt.service)
+			router := gin.New()
+			characters := router.Group("/api/v1/characters/:characterID", func(c *gin.Context) {
+				c.Set(middleware.ContextCharacterID, int32(90000001))
+				c.Set(middleware.ContextAccessToken, "caller-token")
+			})
+			characters.GET("/info", handler.GetCharacterInfo)
+			characters.GET("/assets", handler.GetAssets)
+			characters.GET("/wallet", handler.GetWallet)
+			characters.GET("/orders", handler.GetOrders)
+			characters.GET("/skills", handler.GetSkills)
+			characters.GET("/trade-profile", handler.GetTradeProfile)
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubConsolidationPlanner records the planned characters and options
+type stubConsolidationPlanner struct {
+	characterIDs []int32
+	opts         service.ConsolidationOptions
+}
+
+func (s *stubConsolidationPlanner) PlanCharacters(ctx context.Context, characterIDs []int32, opts service.ConsolidationOptions) (*service.ConsolidationPlan, error) {
+	s.characterIDs, s.opts = characterIDs, opts
+	return &service.ConsolidationPlan{Hub: opts.Hub}, nil
+}
+
+func TestConsolidationHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		accounts           handlers.AccountLookup
+		expectedStatus     int
+		expectedCharacters []int32
+		expectedHub        string
+		expectedRoute      string
+	}{
+		{name: "should plan one character", path: "/characters/90000001/assets/consolidation?hub=Dodixie&route=secure", expectedStatus: http.StatusOK, expectedCharacters: []int32{90000001}, expectedHub: "Dodixie", expectedRoute: service.RouteSecure},
+		{name: "should plan all linked characters", path: "/account/assets/consolidation", accounts: &stubAccountLookup{}, expectedStatus: http.StatusOK, expectedCharacters: []int32{90000001, 90000002}},
+		{name: "should reject unknown hubs", path: "/characters/90000001/assets/consolidation?hub=Perimeter", expectedStatus: http.StatusBadRequest},
+		{name: "should report missing accounts", path: "/account/assets/consolidation", expectedStatus: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			planner := &stubConsolidationPlanner{}
+			handler := handlers.NewConsolidationHandler(planner)
+			if tt.accounts != nil {
+				handler.WithAccounts(tt.accounts)
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(middleware.ContextCharacterID, int32(90000001)) })
+			router.GET("/characters/:characterID/assets/consolidation", handler.GetCharacterPlan)
+			router.GET("/account/assets/consolidation", handler.GetAccountPlan)
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedCharacters, planner.characterIDs)
+			assert.Equal(t, tt.expectedHub, planner.opts.Hub.Name)
+			assert.Equal(t, tt.expectedRoute, planner.opts.Route)
+		})
+	}
+}
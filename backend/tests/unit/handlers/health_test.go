@@ -1,14 +1,17 @@
 package handlers_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHealthHandlerHealthCheck(t *testing.T) {
@@ -29,3 +32,89 @@ func TestHealthHandlerHealthCheck(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "healthy")
 	assert.Contains(t, w.Body.String(), "eve-profit2-backend")
 }
+
+// staticSDEVersion reports a fixed SDE version
+type staticSDEVersion struct {
+	version models.SDEVersion
+}
+
+func (s staticSDEVersion) Version() models.SDEVersion {
+	return s.version
+}
+
+func TestHealthHandlerReportsSDEVersion(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	healthHandler := handlers.NewHealthHandler().WithSDEVersion(staticSDEVersion{
+		version: models.SDEVersion{BuildNumber: 3064089, Checksum: "abc123", Path: "data/sde.sqlite"},
+	})
+	router.GET("/health", healthHandler.HealthCheck)
+
+	// Act
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.SDE)
+	assert.Equal(t, int64(3064089), response.SDE.BuildNumber)
+	assert.Equal(t, "abc123", response.SDE.Checksum)
+}
+
+// staticAppSchema reports a fixed application database schema state
+type staticAppSchema struct {
+	status models.AppSchemaVersion
+}
+
+func (s staticAppSchema) SchemaStatus() models.AppSchemaVersion {
+	return s.status
+}
+
+func TestHealthHandlerReportsAppSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         models.AppSchemaVersion
+		expectedStatus string
+	}{
+		{
+			name:           "should be healthy with all migrations applied",
+			status:         models.AppSchemaVersion{Driver: "sqlite", Version: 7, LatestVersion: 7, UpToDate: true},
+			expectedStatus: "healthy",
+		},
+		{
+			name:           "should be degraded with pending migrations",
+			status:         models.AppSchemaVersion{Driver: "postgres", Version: 5, LatestVersion: 7},
+			expectedStatus: "degraded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			healthHandler := handlers.NewHealthHandler().WithAppSchema(staticAppSchema{status: tt.status})
+			router.GET("/health", healthHandler.HealthCheck)
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var response handlers.HealthResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			require.NotNil(t, response.AppSchema)
+			assert.Equal(t, tt.status.Version, response.AppSchema.Version)
+			assert.Equal(t, tt.status.Driver, response.AppSchema.Driver)
+		})
+	}
+}
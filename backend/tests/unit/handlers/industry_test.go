@@ -0,0 +1,151 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockIndustryService for testing
+type MockIndustryService struct {
+	mock.Mock
+}
+
+func (m *MockIndustryService) CalculateManufacturing(ctx context.Context, req service.ManufacturingRequest) (*service.ManufacturingResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ManufacturingResult), args.Error(1)
+}
+
+func setupIndustryRouter(industryService handlers.IndustryServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewIndustryHandler(industryService)
+
+	router := gin.New()
+	router.POST("/api/v1/industry/manufacturing", handler.CalculateManufacturing)
+	return router
+}
+
+func postJSON(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIndustryHandlerCalculateManufacturing(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockIndustryService)
+		expectedStatus int
+	}{
+		{
+			name: "should return manufacturing result",
+			body: `{"type_id": 587, "runs": 10, "material_efficiency": 10}`,
+			mockSetup: func(m *MockIndustryService) {
+				m.On("CalculateManufacturing", service.ManufacturingRequest{TypeID: 587, Runs: 10, MaterialEfficiency: 10}).
+					Return(&service.ManufacturingResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for malformed body",
+			body:           `{"type_id": "rifter"}`,
+			mockSetup:      func(m *MockIndustryService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 400 for invalid input",
+			body: `{"type_id": 587, "runs": -1}`,
+			mockSetup: func(m *MockIndustryService) {
+				m.On("CalculateManufacturing", service.ManufacturingRequest{TypeID: 587, Runs: -1}).
+					Return(nil, service.ErrInvalidInput)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 404 for items without blueprint",
+			body: `{"type_id": 34}`,
+			mockSetup: func(m *MockIndustryService) {
+				m.On("CalculateManufacturing", service.ManufacturingRequest{TypeID: 34}).
+					Return(nil, service.ErrNotManufacturable)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "should return 500 for service failures",
+			body: `{"type_id": 587}`,
+			mockSetup: func(m *MockIndustryService) {
+				m.On("CalculateManufacturing", service.ManufacturingRequest{TypeID: 587}).
+					Return(nil, errors.New("esi unavailable"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockIndustryService)
+			tt.mockSetup(mockService)
+			router := setupIndustryRouter(mockService)
+
+			// Act
+			w := postJSON(router, "/api/v1/industry/manufacturing", tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, response["success"])
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIndustryHandlerValidatesRequest(t *testing.T) {
+	// Validation runs before any repository or market access
+	router := setupIndustryRouter(service.NewIndustryService(nil, nil, nil))
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing type ID", body: `{"runs": 1}`},
+		{name: "negative type ID", body: `{"type_id": -587}`},
+		{name: "negative runs", body: `{"type_id": 587, "runs": -5}`},
+		{name: "runs above maximum", body: `{"type_id": 587, "runs": 1000001}`},
+		{name: "material efficiency above range", body: `{"type_id": 587, "material_efficiency": 11}`},
+		{name: "negative material efficiency", body: `{"type_id": 587, "material_efficiency": -1}`},
+		{name: "time efficiency above range", body: `{"type_id": 587, "time_efficiency": 21}`},
+		{name: "component material efficiency above range", body: `{"type_id": 587, "component_material_efficiency": 11}`},
+		{name: "facility tax of 100%", body: `{"type_id": 587, "facility_tax": 1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(router, "/api/v1/industry/manufacturing", tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Contains(t, response["error"], "invalid input")
+		})
+	}
+}
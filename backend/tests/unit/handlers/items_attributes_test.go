@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockItemAttributesService for testing attribute and comparison endpoints
+type MockItemAttributesService struct {
+	MockItemService
+}
+
+func (m *MockItemAttributesService) GetItemAttributes(typeID int32) (*models.ItemAttributes, error) {
+	args := m.Called(typeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemAttributes), args.Error(1)
+}
+
+func (m *MockItemAttributesService) CompareItems(typeIDs []int32) (*models.ItemComparison, error) {
+	args := m.Called(typeIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemComparison), args.Error(1)
+}
+
+func setupAttributesRouter(itemService handlers.ItemServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewItemHandler(itemService)
+
+	router := gin.New()
+	router.GET("/api/v1/items/compare", handler.CompareItems)
+	router.GET("/api/v1/items/:item_id/attributes", handler.GetItemAttributes)
+	return router
+}
+
+func TestItemHandlerGetItemAttributes(t *testing.T) {
+	// Arrange
+	mockService := new(MockItemAttributesService)
+	mockService.On("GetItemAttributes", int32(2881)).Return(&models.ItemAttributes{
+		TypeID:    2881,
+		TypeName:  "200mm AutoCannon II",
+		MetaLevel: 5,
+		TechLevel: 2,
+		Attributes: []models.ItemAttribute{
+			{AttributeID: 50, Name: "cpu", DisplayName: "CPU usage", Value: 8, Unit: "tf"},
+		},
+	}, nil)
+	router := setupAttributesRouter(mockService)
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/items/2881/attributes", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                  `json:"success"`
+		Data    models.ItemAttributes `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, int32(2), response.Data.TechLevel)
+	require.Len(t, response.Data.Attributes, 1)
+	assert.Equal(t, "tf", response.Data.Attributes[0].Unit)
+	mockService.AssertExpectations(t)
+}
+
+func TestItemHandlerAttributeEndpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		mockSetup      func(*MockItemAttributesService)
+		expectedStatus int
+	}{
+		{
+			name:           "should return 400 for invalid item ID",
+			path:           "/api/v1/items/abc/attributes",
+			mockSetup:      func(m *MockItemAttributesService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 404 for unknown item",
+			path: "/api/v1/items/999/attributes",
+			mockSetup: func(m *MockItemAttributesService) {
+				m.On("GetItemAttributes", int32(999)).Return(nil, service.ErrItemNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "should compare comma separated types",
+			path: "/api/v1/items/compare?type_ids=2873,2881",
+			mockSetup: func(m *MockItemAttributesService) {
+				m.On("CompareItems", []int32{2873, 2881}).Return(&models.ItemComparison{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should compare repeated type parameters",
+			path: "/api/v1/items/compare?type_ids=2873&type_ids=9071",
+			mockSetup: func(m *MockItemAttributesService) {
+				m.On("CompareItems", []int32{2873, 9071}).Return(&models.ItemComparison{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for malformed type IDs",
+			path:           "/api/v1/items/compare?type_ids=2873,abc",
+			mockSetup:      func(m *MockItemAttributesService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 400 when the service rejects the selection",
+			path: "/api/v1/items/compare?type_ids=2873",
+			mockSetup: func(m *MockItemAttributesService) {
+				m.On("CompareItems", []int32{2873}).Return(nil, fmt.Errorf("%w: compare between 2 and 10 types", service.ErrInvalidInput))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 404 when a compared type is unknown",
+			path: "/api/v1/items/compare?type_ids=2873,999",
+			mockSetup: func(m *MockItemAttributesService) {
+				m.On("CompareItems", []int32{2873, 999}).Return(nil, service.ErrItemNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockItemAttributesService)
+			tt.mockSetup(mockService)
+			router := setupAttributesRouter(mockService)
+
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemHandlerAttributesNotAvailable(t *testing.T) {
+	// Arrange: the plain mock does not implement the attributes interface
+	router := setupAttributesRouter(new(MockItemService))
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/items/2873/attributes", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
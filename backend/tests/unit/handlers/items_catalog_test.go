@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockItemCatalogService for testing category and market group browsing
+type MockItemCatalogService struct {
+	MockItemService
+}
+
+func (m *MockItemCatalogService) GetCategories() ([]*models.ItemCategory, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ItemCategory), args.Error(1)
+}
+
+func (m *MockItemCatalogService) GetGroupsByCategory(categoryID int32) ([]*models.ItemGroup, error) {
+	args := m.Called(categoryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ItemGroup), args.Error(1)
+}
+
+func (m *MockItemCatalogService) GetMarketGroupTree() ([]*models.MarketGroup, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.MarketGroup), args.Error(1)
+}
+
+func (m *MockItemCatalogService) GetMarketGroup(marketGroupID int32) (*models.MarketGroup, error) {
+	args := m.Called(marketGroupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MarketGroup), args.Error(1)
+}
+
+func (m *MockItemCatalogService) GetMarketGroupTypes(marketGroupID int32) ([]*models.Item, error) {
+	args := m.Called(marketGroupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Item), args.Error(1)
+}
+
+func setupCatalogRouter(itemService handlers.ItemServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewItemHandler(itemService)
+
+	router := gin.New()
+	router.GET("/api/v1/items/categories", handler.GetCategories)
+	router.GET("/api/v1/items/categories/:category_id/groups", handler.GetCategoryGroups)
+	router.GET("/api/v1/market-groups", handler.GetMarketGroups)
+	router.GET("/api/v1/market-groups/:market_group_id", handler.GetMarketGroup)
+	router.GET("/api/v1/market-groups/:market_group_id/types", handler.GetMarketGroupTypes)
+	return router
+}
+
+func TestItemHandlerGetMarketGroups(t *testing.T) {
+	// Arrange
+	mockService := new(MockItemCatalogService)
+	minerals := &models.MarketGroup{MarketGroupID: 1857, ParentGroupID: 533, Name: "Minerals", HasTypes: true}
+	mockService.On("GetMarketGroupTree").Return([]*models.MarketGroup{
+		{MarketGroupID: 533, Name: "Materials", Children: []*models.MarketGroup{minerals}},
+	}, nil)
+	router := setupCatalogRouter(mockService)
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/market-groups", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                  `json:"success"`
+		Data    []*models.MarketGroup `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "Minerals", response.Data[0].Children[0].Name)
+}
+
+func TestItemHandlerCatalogEndpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		mockSetup      func(*MockItemCatalogService)
+		expectedStatus int
+	}{
+		{
+			name: "should return categories",
+			path: "/api/v1/items/categories",
+			mockSetup: func(m *MockItemCatalogService) {
+				m.On("GetCategories").Return([]*models.ItemCategory{{CategoryID: 4, CategoryName: "Material"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should return groups of a category",
+			path: "/api/v1/items/categories/4/groups",
+			mockSetup: func(m *MockItemCatalogService) {
+				m.On("GetGroupsByCategory", int32(4)).Return([]*models.ItemGroup{{GroupID: 18, GroupName: "Mineral", CategoryID: 4}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for invalid category ID",
+			path:           "/api/v1/items/categories/abc/groups",
+			mockSetup:      func(m *MockItemCatalogService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return a single market group",
+			path: "/api/v1/market-groups/1857",
+			mockSetup: func(m *MockItemCatalogService) {
+				m.On("GetMarketGroup", int32(1857)).Return(&models.MarketGroup{MarketGroupID: 1857, Name: "Minerals"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should return 404 for unknown market group",
+			path: "/api/v1/market-groups/999",
+			mockSetup: func(m *MockItemCatalogService) {
+				m.On("GetMarketGroup", int32(999)).Return(nil, fmt.Errorf("%w: marketGroupID 999", service.ErrMarketGroupNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "should return types of a market group",
+			path: "/api/v1/market-groups/1857/types",
+			mockSetup: func(m *MockItemCatalogService) {
+				m.On("GetMarketGroupTypes", int32(1857)).Return([]*models.Item{{TypeID: 34, TypeName: "Tritanium"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for invalid market group ID",
+			path:           "/api/v1/market-groups/-1/types",
+			mockSetup:      func(m *MockItemCatalogService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockItemCatalogService)
+			tt.mockSetup(mockService)
+			router := setupCatalogRouter(mockService)
+
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemHandlerCatalogNotAvailable(t *testing.T) {
+	// Arrange: the plain mock does not implement the catalog interface
+	router := setupCatalogRouter(new(MockItemService))
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/market-groups", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
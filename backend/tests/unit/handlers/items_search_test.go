@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockItemSearchService for testing ranked and filtered search
+type MockItemSearchService struct {
+	MockItemService
+}
+
+func (m *MockItemSearchService) Search(opts service.ItemSearchOptions) (*service.ItemSearchResult, error) {
+	args := m.Called(opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ItemSearchResult), args.Error(1)
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func TestItemHandlerSearchWithOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockItemSearchService)
+		expectedStatus int
+	}{
+		{
+			name:  "should default to published items and default limit",
+			query: "?q=rifter",
+			mockSetup: func(m *MockItemSearchService) {
+				m.On("Search", service.ItemSearchOptions{
+					Query:     "rifter",
+					Limit:     service.DefaultSearchLimit,
+					Published: boolPtr(true),
+				}).Return(&service.ItemSearchResult{Items: []*models.Item{{TypeID: 587, TypeName: "Rifter"}}, Total: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "should pass paging and filters",
+			query: "?q=cap+booster&limit=10&offset=20&category_id=8&market_group_id=139&published=false&marketable=true",
+			mockSetup: func(m *MockItemSearchService) {
+				m.On("Search", service.ItemSearchOptions{
+					Query:         "cap booster",
+					Limit:         10,
+					Offset:        20,
+					CategoryID:    8,
+					MarketGroupID: 139,
+					Published:     boolPtr(false),
+					Marketable:    boolPtr(true),
+				}).Return(&service.ItemSearchResult{Items: []*models.Item{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for invalid limit",
+			query:          "?q=rifter&limit=0",
+			mockSetup:      func(m *MockItemSearchService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 for invalid marketable flag",
+			query:          "?q=rifter&marketable=maybe",
+			mockSetup:      func(m *MockItemSearchService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "should return 404 for unknown market group",
+			query: "?q=rifter&market_group_id=999",
+			mockSetup: func(m *MockItemSearchService) {
+				m.On("Search", service.ItemSearchOptions{
+					Query:         "rifter",
+					Limit:         service.DefaultSearchLimit,
+					MarketGroupID: 999,
+					Published:     boolPtr(true),
+				}).Return(nil, fmt.Errorf("%w: marketGroupID 999", service.ErrMarketGroupNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:  "should return 400 for queries without searchable text",
+			query: "?q=!!",
+			mockSetup: func(m *MockItemSearchService) {
+				m.On("Search", service.ItemSearchOptions{
+					Query:     "!!",
+					Limit:     service.DefaultSearchLimit,
+					Published: boolPtr(true),
+				}).Return(nil, fmt.Errorf("%w: search query must contain letters or digits", service.ErrInvalidInput))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockItemSearchService)
+			tt.mockSetup(mockService)
+
+			router := gin.New()
+			router.GET("/api/v1/items/search", handlers.NewItemHandler(mockService).SearchItems)
+
+			// Act
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/v1/items/search"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemHandlerSearchResponseIncludesPaging(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockItemSearchService)
+	mockService.On("Search", service.ItemSearchOptions{
+		Query:     "cap booster",
+		Limit:     1,
+		Offset:    1,
+		Published: boolPtr(true),
+	}).Return(&service.ItemSearchResult{
+		Items:  []*models.Item{{TypeID: 3554, TypeName: "Cap Booster 800"}},
+		Total:  2,
+		Limit:  1,
+		Offset: 1,
+	}, nil)
+
+	router := gin.New()
+	router.GET("/api/v1/items/search", handlers.NewItemHandler(mockService).SearchItems)
+
+	// Act
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/items/search?q=cap+booster&limit=1&offset=1", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.ItemSearchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, 1, response.Offset)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "Cap Booster 800", response.Data[0].TypeName)
+}
@@ -0,0 +1,120 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockMarketArchive for testing
+type MockMarketArchive struct {
+	mock.Mock
+}
+
+func (m *MockMarketArchive) PriceCurve(regionID, typeID int32, from, to time.Time, interval time.Duration) (*models.PriceCurve, error) {
+	args := m.Called(regionID, typeID, from, to, interval)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PriceCurve), args.Error(1)
+}
+
+func setupMarketArchiveRouter(mockArchive *MockMarketArchive) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewMarketArchiveHandler(mockArchive)
+
+	router := gin.New()
+	router.GET("/api/v1/items/:item_id/intraday", handler.GetPriceCurve)
+	return router
+}
+
+func TestMarketArchiveHandlerGetPriceCurve(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		path           string
+		mockSetup      func(*MockMarketArchive)
+		expectedStatus int
+	}{
+		{
+			name: "should pass range, hub and interval",
+			path: "/api/v1/items/34/intraday?hub=Amarr&from=2026-10-01T00:00:00Z&to=2026-10-01T12:00:00Z&interval=15m",
+			mockSetup: func(m *MockMarketArchive) {
+				m.On("PriceCurve", service.RegionDomain, int32(34), from, to, 15*time.Minute).
+					Return(&models.PriceCurve{RegionID: service.RegionDomain, TypeID: 34}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should default to the last day in The Forge",
+			path: "/api/v1/items/34/intraday?to=2026-10-01T12:00:00Z",
+			mockSetup: func(m *MockMarketArchive) {
+				m.On("PriceCurve", service.RegionTheForge, int32(34), to.Add(-24*time.Hour), to, time.Duration(0)).
+					Return(&models.PriceCurve{RegionID: service.RegionTheForge, TypeID: 34}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for invalid item ID",
+			path:           "/api/v1/items/abc/intraday",
+			mockSetup:      func(m *MockMarketArchive) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 for invalid timestamps",
+			path:           "/api/v1/items/34/intraday?from=yesterday",
+			mockSetup:      func(m *MockMarketArchive) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 for unknown hub",
+			path:           "/api/v1/items/34/intraday?hub=Perimeter",
+			mockSetup:      func(m *MockMarketArchive) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 400 for rejected ranges",
+			path: "/api/v1/items/34/intraday?from=2026-10-01T12:00:00Z&to=2026-10-01T00:00:00Z",
+			mockSetup: func(m *MockMarketArchive) {
+				m.On("PriceCurve", service.RegionTheForge, int32(34), to, from, time.Duration(0)).Return(nil, service.ErrInvalidInput)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockArchive := new(MockMarketArchive)
+			tt.mockSetup(mockArchive)
+			router := setupMarketArchiveRouter(mockArchive)
+
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, true, response["success"])
+			}
+			mockArchive.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrderFlow for testing
+type MockOrderFlow struct {
+	mock.Mock
+}
+
+func (m *MockOrderFlow) Events(regionID, typeID int32, locationID int64, since time.Time, cursor string, limit int) (*models.OrderEventPage, error) {
+	args := m.Called(regionID, typeID, locationID, since, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrderEventPage), args.Error(1)
+}
+
+func (m *MockOrderFlow) Velocity(regionID, typeID int32, locationID int64, from, to time.Time) (*models.OrderFlowReport, error) {
+	args := m.Called(regionID, typeID, locationID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrderFlowReport), args.Error(1)
+}
+
+func setupOrderFlowRouter(mockOrderFlow *MockOrderFlow) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewOrderFlowHandler(mockOrderFlow)
+
+	router := gin.New()
+	router.GET("/api/v1/items/:item_id/order-events", handler.GetOrderEvents)
+	router.GET("/api/v1/items/:item_id/order-flow", handler.GetVelocity)
+	return router
+}
+
+func TestOrderFlowHandler(t *testing.T) {
+	since := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		path           string
+		mockSetup      func(*MockOrderFlow)
+		expectedStatus int
+	}{
+		{
+			name: "should narrow events to the hub station",
+			path: "/api/v1/items/34/order-events?hub=Jita&since=2026-10-01T12:00:00Z&limit=50",
+			mockSetup: func(m *MockOrderFlow) {
+				m.On("Events", service.RegionTheForge, int32(34), int64(60003760), since, "", 50).Return(&models.OrderEventPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should pass the cursor of the previous page",
+			path: "/api/v1/items/34/order-events?cursor=MTc1OTMyMDAwMDoxOm5ldw&since=2026-10-01T12:00:00Z",
+			mockSetup: func(m *MockOrderFlow) {
+				m.On("Events", service.RegionTheForge, int32(34), int64(0), since, "MTc1OTMyMDAwMDoxOm5ldw", service.DefaultOrderEventLimit).
+					Return(&models.OrderEventPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should return 400 for invalid cursors",
+			path: "/api/v1/items/34/order-events?cursor=garbage&since=2026-10-01T12:00:00Z",
+			mockSetup: func(m *MockOrderFlow) {
+				m.On("Events", service.RegionTheForge, int32(34), int64(0), since, "garbage", service.DefaultOrderEventLimit).
+					Return(nil, service.ErrInvalidInput)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should report the velocity of a whole region",
+			path: "/api/v1/items/34/order-flow?region_id=10000043&to=2026-10-02T00:00:00Z",
+			mockSetup: func(m *MockOrderFlow) {
+				m.On("Velocity", service.RegionDomain, int32(34), int64(0), to.Add(-24*time.Hour), to).
+					Return(&models.OrderFlowReport{RegionID: service.RegionDomain, TypeID: 34}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for invalid since",
+			path:           "/api/v1/items/34/order-events?since=1h",
+			mockSetup:      func(m *MockOrderFlow) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 for invalid limit",
+			path:           "/api/v1/items/34/order-events?limit=0",
+			mockSetup:      func(m *MockOrderFlow) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 for invalid location",
+			path:           "/api/v1/items/34/order-flow?location_id=jita",
+			mockSetup:      func(m *MockOrderFlow) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 400 for rejected ranges",
+			path: "/api/v1/items/34/order-flow?from=2026-10-02T00:00:00Z&to=2026-10-01T12:00:00Z",
+			mockSetup: func(m *MockOrderFlow) {
+				m.On("Velocity", service.RegionTheForge, int32(34), int64(0), to, since).Return(nil, service.ErrInvalidInput)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockOrderFlow := new(MockOrderFlow)
+			tt.mockSetup(mockOrderFlow)
+			router := setupOrderFlowRouter(mockOrderFlow)
+
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockOrderFlow.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedMarketData prices every requested type at 5 ISK
+type fixedMarketData struct{}
+
+func (fixedMarketData) GetMarketData(ctx context.Context, req service.MarketDataRequest) (*service.MarketDataResponse, error) {
+	response := &service.MarketDataResponse{RegionID: req.RegionID, Data: make(map[int32]*models.ItemPrice)}
+	for _, typeID := range req.TypeIDs {
+		response.Data[typeID] = &models.ItemPrice{TypeID: typeID, SellMin: 5, LastUpdated: time.Now()}
+	}
+	return response, nil
+}
+
+func setupPriceStreamRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewPriceStreamHandler(service.NewPriceStream(fixedMarketData{}))
+
+	router := gin.New()
+	router.GET("/api/v1/market/stream", handler.StreamPrices)
+	return router
+}
+
+func TestPriceStreamHandlerStreamsPrices(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(setupPriceStreamRouter())
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/v1/market/stream?pairs=10000043:35&type_ids=34", nil)
+	require.NoError(t, err)
+
+	// Act
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < 2 && scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "data:") {
+			events = append(events, line)
+		}
+	}
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Len(t, events, 2)
+	joined := strings.Join(events, "\n")
+	assert.Contains(t, joined, `"region_id":10000043,"type_id":35`)
+	assert.Contains(t, joined, `"region_id":10000002,"type_id":34`) // The Forge by default
+}
+
+func TestPriceStreamHandlerRejectsInvalidSubscriptions(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "should require types", path: "/api/v1/market/stream"},
+		{name: "should reject malformed pairs", path: "/api/v1/market/stream?pairs=10000002-34"},
+		{name: "should reject invalid type IDs", path: "/api/v1/market/stream?type_ids=abc"},
+		{name: "should reject unknown hubs", path: "/api/v1/market/stream?type_ids=34&hub=nowhere"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := setupPriceStreamRouter()
+
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReprocessingService for testing
+type MockReprocessingService struct {
+	mock.Mock
+}
+
+func (m *MockReprocessingService) CalculateReprocessing(ctx context.Context, req service.ReprocessingRequest) (*service.ReprocessingResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ReprocessingResult), args.Error(1)
+}
+
+func setupReprocessingRouter(reprocessingService handlers.ReprocessingServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewReprocessingHandler(reprocessingService)
+
+	router := gin.New()
+	router.POST("/api/v1/industry/reprocessing", handler.CalculateReprocessing)
+	return router
+}
+
+func TestReprocessingHandlerCalculateReprocessing(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockReprocessingService)
+		expectedStatus int
+	}{
+		{
+			name: "should return reprocessing result",
+			body: `{"type_id": 1230, "quantity": 1000, "price_type": "buy"}`,
+			mockSetup: func(m *MockReprocessingService) {
+				m.On("CalculateReprocessing", service.ReprocessingRequest{TypeID: 1230, Quantity: 1000, PriceType: "buy"}).
+					Return(&service.ReprocessingResult{TypeID: 1230, Decision: service.DecisionReprocess}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for malformed body",
+			body:           `{"type_id": 1230, "quantity": "lots"}`,
+			mockSetup:      func(m *MockReprocessingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 400 for invalid input",
+			body: `{"type_id": 1230, "quantity": 100, "price_type": "median"}`,
+			mockSetup: func(m *MockReprocessingService) {
+				m.On("CalculateReprocessing", service.ReprocessingRequest{TypeID: 1230, Quantity: 100, PriceType: "median"}).
+					Return(nil, service.ErrInvalidInput)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 404 for unknown item",
+			body: `{"type_id": 999999, "quantity": 1}`,
+			mockSetup: func(m *MockReprocessingService) {
+				m.On("CalculateReprocessing", service.ReprocessingRequest{TypeID: 999999, Quantity: 1}).
+					Return(nil, service.ErrItemNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "should return 422 for items without materials",
+			body: `{"type_id": 29668, "quantity": 1}`,
+			mockSetup: func(m *MockReprocessingService) {
+				m.On("CalculateReprocessing", service.ReprocessingRequest{TypeID: 29668, Quantity: 1}).
+					Return(nil, service.ErrNotReprocessable)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "should return 500 for service failures",
+			body: `{"type_id": 1230, "quantity": 1}`,
+			mockSetup: func(m *MockReprocessingService) {
+				m.On("CalculateReprocessing", service.ReprocessingRequest{TypeID: 1230, Quantity: 1}).
+					Return(nil, errors.New("esi unavailable"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockReprocessingService)
+			tt.mockSetup(mockService)
+			router := setupReprocessingRouter(mockService)
+
+			// Act
+			w := postJSON(router, "/api/v1/industry/reprocessing", tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, response["success"])
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReprocessingHandlerValidatesRequest(t *testing.T) {
+	// Validation runs before any repository or market access
+	router := setupReprocessingRouter(service.NewReprocessingService(nil, nil))
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing type ID", body: `{"quantity": 100}`},
+		{name: "negative type ID", body: `{"type_id": -1230, "quantity": 100}`},
+		{name: "missing quantity", body: `{"type_id": 1230}`},
+		{name: "negative quantity", body: `{"type_id": 1230, "quantity": -100}`},
+		{name: "skill level above 5", body: `{"type_id": 1230, "quantity": 100, "reprocessing_level": 6}`},
+		{name: "negative skill level", body: `{"type_id": 1230, "quantity": 100, "ore_processing_level": -1}`},
+		{name: "base yield of 100%", body: `{"type_id": 1230, "quantity": 100, "base_yield": 1}`},
+		{name: "unknown price type", body: `{"type_id": 1230, "quantity": 100, "price_type": "median"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(router, "/api/v1/industry/reprocessing", tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Contains(t, response["error"], "invalid input")
+		})
+	}
+}
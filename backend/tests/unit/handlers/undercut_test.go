@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubUndercutMonitor records the checked characters
+type stubUndercutMonitor struct {
+	characterIDs []int32
+	all          bool
+}
+
+func (s *stubUndercutMonitor) CheckCharacters(ctx context.Context, characterIDs []int32, all bool) (*service.UndercutReport, error) {
+	s.characterIDs, s.all = characterIDs, all
+	return &service.UndercutReport{CheckedOrders: len(characterIDs)}, nil
+}
+
+// stubAccountLookup links an alt to every character
+type stubAccountLookup struct{}
+
+func (s *stubAccountLookup) GetAccount(characterID int32) (*models.Account, error) {
+	return &models.Account{Characters: []models.LinkedCharacter{{CharacterID: characterID}, {CharacterID: 90000002}}}, nil
+}
+
+func TestUndercutHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		accounts           handlers.AccountLookup
+		expectedStatus     int
+		expectedCharacters []int32
+		expectedAll        bool
+	}{
+		{name: "should check one character", path: "/characters/90000001/orders/undercuts?all=true", expectedStatus: http.StatusOK, expectedCharacters: []int32{90000001}, expectedAll: true},
+		{name: "should check all linked characters", path: "/account/undercuts", accounts: &stubAccountLookup{}, expectedStatus: http.StatusOK, expectedCharacters: []int32{90000001, 90000002}},
+		{name: "should report missing accounts", path: "/account/undercuts", expectedStatus: http.StatusNotImplemented},
+		{name: "should reject invalid character", path: "/characters/0/orders/undercuts", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			monitor := &stubUndercutMonitor{}
+			handler := handlers.NewUndercutHandler(monitor)
+			if tt.accounts != nil {
+				handler.WithAccounts(tt.accounts)
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(middleware.ContextCharacterID, int32(90000001)) })
+			router.GET("/characters/:characterID/orders/undercuts", handler.GetCharacterUndercuts)
+			router.GET("/account/undercuts", handler.GetAccountUndercuts)
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedCharacters, monitor.characterIDs)
+			assert.Equal(t, tt.expectedAll, monitor.all)
+		})
+	}
+}
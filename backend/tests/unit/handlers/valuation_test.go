@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAssetValuation records the valued characters and options
+type stubAssetValuation struct {
+	characterIDs []int32
+	opts         service.ValuationOptions
+}
+
+func (s *stubAssetValuation) ValueCharacters(ctx context.Context, characterIDs []int32, opts service.ValuationOptions) (*service.AssetValuation, error) {
+	s.characterIDs, s.opts = characterIDs, opts
+	return &service.AssetValuation{Source: opts.Source}, nil
+}
+
+func TestAssetValuationHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		accounts           handlers.AccountLookup
+		expectedStatus     int
+		expectedCharacters []int32
+		expectedSource     string
+		expectedHub        string
+	}{
+		{name: "should value one character", path: "/characters/90000001/assets/valuation?source=buy&hub=amarr", expectedStatus: http.StatusOK, expectedCharacters: []int32{90000001}, expectedSource: service.PriceTypeBuy, expectedHub: "Amarr"},
+		{name: "should value all linked characters", path: "/account/networth?top=5", accounts: &stubAccountLookup{}, expectedStatus: http.StatusOK, expectedCharacters: []int32{90000001, 90000002}, expectedSource: service.PriceTypeSell, expectedHub: "Jita"},
+		{name: "should reject unknown sources", path: "/characters/90000001/assets/valuation?source=median", expectedStatus: http.StatusBadRequest},
+		{name: "should reject unknown hubs", path: "/account/networth?hub=Perimeter", accounts: &stubAccountLookup{}, expectedStatus: http.StatusBadRequest},
+		{name: "should report missing accounts", path: "/account/networth", expectedStatus: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			valuation := &stubAssetValuation{}
+			handler := handlers.NewAssetValuationHandler(valuation)
+			if tt.accounts != nil {
+				handler.WithAccounts(tt.accounts)
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(middleware.ContextCharacterID, int32(90000001)) })
+			router.GET("/characters/:characterID/assets/valuation", handler.GetCharacterValuation)
+			router.GET("/account/networth", handler.GetAccountNetWorth)
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedCharacters, valuation.characterIDs)
+			assert.Equal(t, tt.expectedSource, valuation.opts.Source)
+			assert.Equal(t, tt.expectedHub, valuation.opts.Hub.Name)
+		})
+	}
+}
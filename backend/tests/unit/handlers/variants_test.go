@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockVariantService for testing
+type MockVariantService struct {
+	mock.Mock
+}
+
+func (m *MockVariantService) FindVariants(ctx context.Context, req service.VariantRequest) (*service.VariantPriceResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.VariantPriceResult), args.Error(1)
+}
+
+func setupVariantRouter(mockService *MockVariantService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewVariantHandler(mockService)
+
+	router := gin.New()
+	router.GET("/api/v1/items/:item_id/variants", handler.GetVariants)
+	return router
+}
+
+func TestVariantHandlerGetVariants(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		mockSetup      func(*MockVariantService)
+		expectedStatus int
+	}{
+		{
+			name: "should pass hub and price type",
+			path: "/api/v1/items/2873/variants?hub=Amarr&price_type=buy",
+			mockSetup: func(m *MockVariantService) {
+				m.On("FindVariants", service.VariantRequest{TypeID: 2873, Hub: "Amarr", PriceType: "buy"}).
+					Return(&service.VariantPriceResult{BaseTypeID: 2873, BestValueTypeID: 9071}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should pass region",
+			path: "/api/v1/items/2873/variants?region_id=10000043",
+			mockSetup: func(m *MockVariantService) {
+				m.On("FindVariants", service.VariantRequest{TypeID: 2873, RegionID: 10000043}).
+					Return(&service.VariantPriceResult{BaseTypeID: 2873}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should return 400 for invalid item ID",
+			path:           "/api/v1/items/abc/variants",
+			mockSetup:      func(m *MockVariantService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 for invalid region",
+			path:           "/api/v1/items/2873/variants?region_id=forge",
+			mockSetup:      func(m *MockVariantService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 400 for unknown hub",
+			path: "/api/v1/items/2873/variants?hub=Perimeter",
+			mockSetup: func(m *MockVariantService) {
+				m.On("FindVariants", service.VariantRequest{TypeID: 2873, Hub: "Perimeter"}).Return(nil, service.ErrInvalidInput)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return 404 for unknown item",
+			path: "/api/v1/items/999999/variants",
+			mockSetup: func(m *MockVariantService) {
+				m.On("FindVariants", service.VariantRequest{TypeID: 999999}).Return(nil, service.ErrItemNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockVariantService)
+			tt.mockSetup(mockService)
+			router := setupVariantRouter(mockService)
+
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, true, response["success"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
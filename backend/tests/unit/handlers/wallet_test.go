@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubWalletService records the requested cost method
+type stubWalletService struct {
+	method service.CostMethod
+}
+
+func (s *stubWalletService) SyncCharacter(ctx context.Context, characterID int32) (*models.WalletSyncResult, error) {
+	return nil, service.ErrLoginRequired
+}
+
+func (s *stubWalletService) GetProfitLoss(ctx context.Context, characterID int32, method service.CostMethod, priceRegionID int32) (*models.ProfitLossReport, error) {
+	s.method = method
+	return &models.ProfitLossReport{CharacterID: characterID, Method: string(method)}, nil
+}
+
+func TestWalletHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedMethod service.CostMethod
+	}{
+		{name: "should default to FIFO", method: http.MethodGet, path: "/characters/90000001/profit", expectedStatus: http.StatusOK, expectedMethod: service.CostMethodFIFO},
+		{name: "should accept average cost", method: http.MethodGet, path: "/characters/90000001/profit?method=average", expectedStatus: http.StatusOK, expectedMethod: service.CostMethodAverage},
+		{name: "should reject unknown method", method: http.MethodGet, path: "/characters/90000001/profit?method=lifo", expectedStatus: http.StatusBadRequest},
+		{name: "should reject invalid region", method: http.MethodGet, path: "/characters/90000001/profit?region_id=x", expectedStatus: http.StatusBadRequest},
+		{name: "should require login for sync", method: http.MethodPost, path: "/characters/90000001/wallet/sync", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			walletService := &stubWalletService{}
+			handler := handlers.NewWalletHandler(walletService)
+			router := gin.New()
+			router.GET("/characters/:characterID/profit", handler.GetProfitLoss)
+			router.POST("/characters/:characterID/wallet/sync", handler.SyncWallet)
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedMethod, walletService.method)
+		})
+	}
+}
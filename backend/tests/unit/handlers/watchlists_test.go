@@ -0,0 +1,259 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"eve-profit2/internal/api/handlers"
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWatchlistService for testing
+type MockWatchlistService struct {
+	mock.Mock
+}
+
+func (m *MockWatchlistService) ListWatchlists(characterID int32) ([]models.Watchlist, error) {
+	args := m.Called(characterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Watchlist), args.Error(1)
+}
+
+func (m *MockWatchlistService) GetWatchlist(ctx context.Context, characterID int32, watchlistID int64) (*models.Watchlist, error) {
+	args := m.Called(characterID, watchlistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Watchlist), args.Error(1)
+}
+
+func (m *MockWatchlistService) CreateWatchlist(ctx context.Context, characterID int32, list models.Watchlist) (*models.Watchlist, error) {
+	args := m.Called(characterID, list)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Watchlist), args.Error(1)
+}
+
+func (m *MockWatchlistService) UpdateWatchlist(ctx context.Context, characterID int32, watchlistID int64, list models.Watchlist) (*models.Watchlist, error) {
+	args := m.Called(characterID, watchlistID, list)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Watchlist), args.Error(1)
+}
+
+func (m *MockWatchlistService) DeleteWatchlist(characterID int32, watchlistID int64) error {
+	return m.Called(characterID, watchlistID).Error(0)
+}
+
+func (m *MockWatchlistService) ListSearches(characterID int32) ([]models.SavedSearch, error) {
+	args := m.Called(characterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SavedSearch), args.Error(1)
+}
+
+func (m *MockWatchlistService) GetSearch(characterID int32, searchID int64) (*models.SavedSearch, error) {
+	args := m.Called(characterID, searchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func (m *MockWatchlistService) CreateSearch(characterID int32, search models.SavedSearch) (*models.SavedSearch, error) {
+	args := m.Called(characterID, search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func (m *MockWatchlistService) UpdateSearch(characterID int32, searchID int64, search models.SavedSearch) (*models.SavedSearch, error) {
+	args := m.Called(characterID, searchID, search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func (m *MockWatchlistService) DeleteSearch(characterID int32, searchID int64) error {
+	return m.Called(characterID, searchID).Error(0)
+}
+
+func (m *MockWatchlistService) RunSearch(characterID int32, searchID int64, limit, offset int) (*service.ItemSearchResult, error) {
+	args := m.Called(characterID, searchID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ItemSearchResult), args.Error(1)
+}
+
+func setupWatchlistRouter(mockWatchlists *MockWatchlistService, authenticated bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewWatchlistHandler(mockWatchlists)
+
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) { c.Set(middleware.ContextCharacterID, int32(90000001)) })
+	}
+	router.GET("/api/v1/watchlists", handler.ListWatchlists)
+	router.POST("/api/v1/watchlists", handler.CreateWatchlist)
+	router.GET("/api/v1/watchlists/:watchlistID", handler.GetWatchlist)
+	router.PUT("/api/v1/watchlists/:watchlistID", handler.UpdateWatchlist)
+	router.DELETE("/api/v1/watchlists/:watchlistID", handler.DeleteWatchlist)
+	router.POST("/api/v1/searches", handler.CreateSearch)
+	router.DELETE("/api/v1/searches/:searchID", handler.DeleteSearch)
+	router.GET("/api/v1/searches/:searchID/results", handler.RunSearch)
+	return router
+}
+
+func TestWatchlistHandler(t *testing.T) {
+	minerals := models.Watchlist{Name: "Minerals", Items: []models.WatchlistItem{
+		{TypeID: 34, RegionID: service.RegionDomain, StationID: 60008494},
+		{TypeID: 35, RegionID: service.RegionTheForge, Note: "bulk"},
+	}}
+	marketable := true
+	ammo := models.SavedSearch{Query: "antimatter", Marketable: &marketable}
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            string
+		unauthenticated bool
+		mockSetup       func(*MockWatchlistService)
+		expectedStatus  int
+	}{
+		{
+			name:   "should list the watchlists of the authenticated character",
+			method: "GET",
+			path:   "/api/v1/watchlists",
+			mockSetup: func(m *MockWatchlistService) {
+				m.On("ListWatchlists", int32(90000001)).Return([]models.Watchlist{minerals}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "should resolve hub names of items",
+			method: "POST",
+			path:   "/api/v1/watchlists",
+			body:   `{"name":"Minerals","items":[{"type_id":34,"hub":"amarr"},{"type_id":35,"region_id":10000002,"note":"bulk"}]}`,
+			mockSetup: func(m *MockWatchlistService) {
+				m.On("CreateWatchlist", int32(90000001), minerals).Return(&minerals, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "should return 400 for unknown hubs",
+			method:         "PUT",
+			path:           "/api/v1/watchlists/3",
+			body:           `{"name":"Minerals","items":[{"type_id":34,"hub":"nowhere"}]}`,
+			mockSetup:      func(m *MockWatchlistService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "should return 400 for rejected watchlists",
+			method: "PUT",
+			path:   "/api/v1/watchlists/3",
+			body:   `{"name":"Minerals","items":[{"type_id":-1}]}`,
+			mockSetup: func(m *MockWatchlistService) {
+				m.On("UpdateWatchlist", int32(90000001), int64(3), mock.Anything).Return(nil, fmt.Errorf("%w: invalid type", service.ErrInvalidInput))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should return 400 without name",
+			method:         "POST",
+			path:           "/api/v1/watchlists",
+			body:           `{"items":[]}`,
+			mockSetup:      func(m *MockWatchlistService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "should return 404 for watchlists of other characters",
+			method: "GET",
+			path:   "/api/v1/watchlists/8",
+			mockSetup: func(m *MockWatchlistService) {
+				m.On("GetWatchlist", int32(90000001), int64(8)).Return(nil, service.ErrWatchlistNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "should return 400 for invalid watchlist IDs",
+			method:         "DELETE",
+			path:           "/api/v1/watchlists/abc",
+			mockSetup:      func(m *MockWatchlistService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "should save searches",
+			method: "POST",
+			path:   "/api/v1/searches",
+			body:   `{"query":"antimatter","marketable":true}`,
+			mockSetup: func(m *MockWatchlistService) {
+				m.On("CreateSearch", int32(90000001), ammo).Return(&ammo, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "should run saved searches with paging",
+			method: "GET",
+			path:   "/api/v1/searches/5/results?limit=10&offset=20",
+			mockSetup: func(m *MockWatchlistService) {
+				m.On("RunSearch", int32(90000001), int64(5), 10, 20).Return(&service.ItemSearchResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "should return 404 for deleted searches",
+			method: "DELETE",
+			path:   "/api/v1/searches/5",
+			mockSetup: func(m *MockWatchlistService) {
+				m.On("DeleteSearch", int32(90000001), int64(5)).Return(service.ErrSavedSearchNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:            "should return 401 without authentication",
+			method:          "GET",
+			path:            "/api/v1/watchlists",
+			unauthenticated: true,
+			mockSetup:       func(m *MockWatchlistService) {},
+			expectedStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockWatchlists := new(MockWatchlistService)
+			tt.mockSetup(mockWatchlists)
+			router := setupWatchlistRouter(mockWatchlists, !tt.unauthenticated)
+
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockWatchlists.AssertExpectations(t)
+		})
+	}
+}
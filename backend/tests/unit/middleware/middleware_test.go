@@ -0,0 +1,179 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/api/middleware"
+	"eve-profit2/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubVerifier accepts a single token
+type stubVerifier struct {
+	token  string
+	claims *models.AccessClaims
+}
+
+func (v *stubVerifier) VerifyAccessToken(ctx context.Context, accessToken string) (*models.AccessClaims, error) {
+	if accessToken != v.token {
+		return nil, errors.New("invalid token")
+	}
+	return v.claims, nil
+}
+
+// stubAuthorizer links the listed characters to the authenticated one
+type stubAuthorizer struct {
+	linked map[int32]bool
+}
+
+func (a *stubAuthorizer) CanAccessCharacter(authCharacterID, characterID int32) (bool, error) {
+	return authCharacterID == characterID || a.linked[characterID], nil
+}
+
+func setupAuthRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	verifier := &stubVerifier{
+		token: "valid-token",
+		claims: &models.AccessClaims{
+			CharacterID: 90000001,
+			Scopes:      []string{"esi-wallet.read_character_wallet.v1"},
+		},
+	}
+
+	echo := func(c *gin.Context) {
+		characterID, _ := middleware.CharacterIDFromContext(c)
+		c.JSON(http.StatusOK, gin.H{"character_id": characterID, "scopes": middleware.ScopesFromContext(c)})
+	}
+
+	router := gin.New()
+	router.GET("/me", middleware.RequireAuth(verifier), echo)
+	router.GET("/wallet", middleware.RequireScopes(verifier, "esi-wallet.read_character_wallet.v1"), echo)
+	router.GET("/assets", middleware.RequireScopes(verifier, "esi-assets.read_assets.v1"), echo)
+	router.GET("/login", middleware.OptionalAuth(verifier), echo)
+	router.GET("/characters/:characterID", middleware.RequireAuth(verifier), middleware.RequireCharacterAccess(&stubAuthorizer{linked: map[int32]bool{90000003: true}}, "characterID"), echo)
+	return router
+}
+
+func TestRequireAuth(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "should accept valid token",
+			path:           "/me",
+			authorization:  "Bearer valid-token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should reject missing token",
+			path:           "/me",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should reject invalid token",
+			path:           "/me",
+			authorization:  "Bearer forged",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should reject other schemes",
+			path:           "/me",
+			authorization:  "Basic valid-token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should accept granted scope",
+			path:           "/wallet",
+			authorization:  "Bearer valid-token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should reject missing scope",
+			path:           "/assets",
+			authorization:  "Bearer valid-token",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should allow own character",
+			path:           "/characters/90000001",
+			authorization:  "Bearer valid-token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should allow linked character",
+			path:           "/characters/90000003",
+			authorization:  "Bearer valid-token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should pass optional auth without token",
+			path:           "/login",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should reject invalid token on optional auth",
+			path:           "/login",
+			authorization:  "Bearer forged",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should reject other character",
+			path:           "/characters/90000002",
+			authorization:  "Bearer valid-token",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := setupAuthRouter()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK && tt.authorization != "" {
+				assert.Contains(t, w.Body.String(), `"character_id":90000001`)
+			}
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "should extract the token", header: "Bearer abc", expected: "abc"},
+		{name: "should ignore the scheme case", header: "bearer  abc ", expected: "abc"},
+		{name: "should reject other schemes", header: "Basic abc", expected: ""},
+		{name: "should reject empty headers", header: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			token := middleware.BearerToken(tt.header)
+
+			// Assert
+			assert.Equal(t, tt.expected, token)
+		})
+	}
+}
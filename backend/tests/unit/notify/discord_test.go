@@ -0,0 +1,59 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscordWebhookSendAlert(t *testing.T) {
+	// Arrange: Local receiver standing in for the Discord webhook
+	var received notify.DiscordMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	alert := models.AlertNotification{
+		Name:        "Undercuts",
+		Expression:  "my order undercut",
+		Message:     "1 of your orders are undercut or outbid",
+		Orders:      []models.OrderUndercut{{OrderID: 1, Status: models.OrderStatusUndercut, Price: 5, CompetitorPrice: 4.99}},
+		TriggeredAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	err := notify.NewDiscordWebhook(server.URL).SendAlert(context.Background(), alert)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, alert.Message, received.Content)
+	require.Len(t, received.Embeds, 1)
+	assert.Equal(t, "Undercuts", received.Embeds[0].Title)
+	assert.Equal(t, "2026-10-01T12:00:00Z", received.Embeds[0].Timestamp)
+	require.Len(t, received.Embeds[0].Fields, 2)
+	assert.Equal(t, "5.00 ISK, competitor 4.99 ISK", received.Embeds[0].Fields[1].Value)
+}
+
+func TestDiscordAlertMessageLimitsOrderFields(t *testing.T) {
+	// Arrange
+	alert := models.AlertNotification{Name: "Undercuts", Orders: make([]models.OrderUndercut, 30)}
+
+	// Act
+	message := notify.NewDiscordAlertMessage(alert)
+
+	// Assert
+	fields := message.Embeds[0].Fields
+	assert.LessOrEqual(t, len(fields), 25)
+	assert.Equal(t, "10", fields[len(fields)-1].Value)
+}
@@ -0,0 +1,125 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedMail is one mail accepted by the fake SMTP server
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTPServer accepts mails without TLS or authentication, standing in
+// for a real SMTP server
+func startFakeSMTPServer(t *testing.T) (string, int, <-chan receivedMail) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	mails := make(chan receivedMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+
+		var mail receivedMail
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				mail.from = strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				mail.to = append(mail.to, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				mail.data = data.String()
+				mails <- mail
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, mails
+}
+
+func TestEmailSenderSendAlert(t *testing.T) {
+	// Arrange
+	host, port, mails := startFakeSMTPServer(t)
+	sender := notify.NewEmailSender(host, port, "alerts@example.com", notify.WithDefaultRecipient("team@example.com"))
+	alert := models.AlertNotification{
+		Name:        "Cheap Tritanium",
+		Expression:  "Jita sell < 5",
+		TypeName:    "Tritanium",
+		Message:     "Jita Tritanium sell is 4.50 ISK (< 5.00 ISK)",
+		Email:       "pilot@example.com",
+		TriggeredAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	err := sender.SendAlert(context.Background(), alert)
+
+	// Assert
+	require.NoError(t, err)
+	mail := <-mails
+	assert.Equal(t, "alerts@example.com", mail.from)
+	assert.Equal(t, []string{"pilot@example.com"}, mail.to) // The rule's address wins over the default
+	assert.Contains(t, mail.data, "Subject: EVE Profit alert: Cheap Tritanium\r\n")
+	assert.Contains(t, mail.data, "Jita Tritanium sell is 4.50 ISK (< 5.00 ISK)\r\n")
+	assert.Contains(t, mail.data, "Item: Tritanium\r\n")
+}
+
+func TestEmailSenderRequiresRecipient(t *testing.T) {
+	// Arrange
+	sender := notify.NewEmailSender("127.0.0.1", 1, "alerts@example.com")
+
+	// Act
+	err := sender.SendAlert(context.Background(), models.AlertNotification{Name: "a"})
+
+	// Assert
+	assert.ErrorIs(t, err, notify.ErrNoRecipient)
+}
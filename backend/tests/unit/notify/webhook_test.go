@@ -0,0 +1,70 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifyUndercuts(t *testing.T) {
+	// Arrange: Local receiver standing in for the webhook
+	var received notify.WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	webhook := notify.NewWebhook(server.URL)
+
+	// Act
+	err := webhook.NotifyUndercuts(context.Background(), []models.OrderUndercut{{OrderID: 1, Status: models.OrderStatusUndercut}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, notify.EventOrdersUndercut, received.Event)
+	require.Len(t, received.Orders, 1)
+	assert.Equal(t, int64(1), received.Orders[0].OrderID)
+}
+
+func TestWebhookReportsFailedDelivery(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// Act
+	err := notify.NewWebhook(server.URL).NotifyUndercuts(context.Background(), nil)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestWebhookSendAlert(t *testing.T) {
+	// Arrange
+	var received notify.WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	// Act
+	err := notify.NewWebhook(server.URL).SendAlert(context.Background(), models.AlertNotification{RuleID: 7, Message: "Jita Tritanium sell is 4.50 ISK (< 5.00 ISK)"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, notify.EventPriceAlert, received.Event)
+	require.NotNil(t, received.Alert)
+	assert.Equal(t, int64(7), received.Alert.RuleID)
+	assert.Empty(t, received.Orders)
+}
@@ -0,0 +1,97 @@
+package repository_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccountStore(t *testing.T) *repository.AccountStore {
+	t.Helper()
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewAccountStore(db)
+	require.NoError(t, err)
+	return store
+}
+
+func TestAccountStoreLinkCharacters(t *testing.T) {
+	// Arrange
+	store := newTestAccountStore(t)
+	account, err := store.CreateAccount(90000001, "Main")
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, store.LinkCharacter(account.AccountID, 90000002, "Jita Alt"))
+	require.NoError(t, store.LinkCharacter(account.AccountID, 90000003, "Amarr Alt"))
+	linked, err := store.GetAccountByCharacter(90000003)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, account.AccountID, linked.AccountID)
+	assert.Equal(t, int32(90000001), linked.ActiveCharacterID)
+	require.Len(t, linked.Characters, 3)
+	assert.True(t, linked.Characters[0].Active)
+	assert.Equal(t, "Jita Alt", linked.Characters[1].CharacterName)
+}
+
+func TestAccountStoreSetActiveCharacter(t *testing.T) {
+	// Arrange
+	store := newTestAccountStore(t)
+	account, err := store.CreateAccount(90000001, "Main")
+	require.NoError(t, err)
+	require.NoError(t, store.LinkCharacter(account.AccountID, 90000002, "Alt"))
+
+	// Act
+	require.NoError(t, store.SetActiveCharacter(account.AccountID, 90000002))
+	err = store.SetActiveCharacter(account.AccountID, 12345)
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrAccountNotFound)
+	updated, err := store.GetAccount(account.AccountID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(90000002), updated.ActiveCharacterID)
+}
+
+func TestAccountStoreMoveCharacter(t *testing.T) {
+	// Arrange: Two accounts, the alt is active on its own
+	store := newTestAccountStore(t)
+	main, err := store.CreateAccount(90000001, "Main")
+	require.NoError(t, err)
+	alt, err := store.CreateAccount(90000002, "Alt")
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, store.LinkCharacter(main.AccountID, 90000002, "Alt"))
+
+	// Assert: The emptied account is gone
+	moved, err := store.GetAccountByCharacter(90000002)
+	require.NoError(t, err)
+	assert.Equal(t, main.AccountID, moved.AccountID)
+	_, err = store.GetAccount(alt.AccountID)
+	assert.ErrorIs(t, err, repository.ErrAccountNotFound)
+}
+
+func TestAccountStoreUnlinkActiveCharacter(t *testing.T) {
+	// Arrange
+	store := newTestAccountStore(t)
+	account, err := store.CreateAccount(90000001, "Main")
+	require.NoError(t, err)
+	require.NoError(t, store.LinkCharacter(account.AccountID, 90000002, "Alt"))
+	require.NoError(t, store.SetActiveCharacter(account.AccountID, 90000002))
+
+	// Act
+	require.NoError(t, store.UnlinkCharacter(account.AccountID, 90000002))
+
+	// Assert: Another character becomes active
+	updated, err := store.GetAccount(account.AccountID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(90000001), updated.ActiveCharacterID)
+	assert.Len(t, updated.Characters, 1)
+
+	_, err = store.GetAccountByCharacter(90000002)
+	assert.ErrorIs(t, err, repository.ErrAccountNotFound)
+	assert.ErrorIs(t, store.UnlinkCharacter(account.AccountID, 90000002), repository.ErrAccountNotFound)
+}
@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAlertStore(t *testing.T) *repository.AlertStore {
+	t.Helper()
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewAlertStore(db)
+	require.NoError(t, err)
+	return store
+}
+
+func TestAlertStoreRuleLifecycle(t *testing.T) {
+	// Arrange
+	store := newTestAlertStore(t)
+	rule := &models.AlertRule{CharacterID: 90000001, Name: "Cheap Tritanium", TypeID: 34, Expression: "Jita sell < 5",
+		Channels: []string{"webhook", "email"}, Email: "pilot@example.com", CooldownSeconds: 600, Enabled: true}
+
+	// Act
+	created, err := store.CreateAlertRule(rule)
+	require.NoError(t, err)
+	created.Expression = "Jita sell < 4"
+	created.Channels = nil
+	updated, updateErr := store.UpdateAlertRule(created)
+	_, otherErr := store.GetAlertRule(90000002, created.RuleID)
+	deleteOtherErr := store.DeleteAlertRule(90000002, created.RuleID)
+	deleteErr := store.DeleteAlertRule(90000001, created.RuleID)
+	_, goneErr := store.GetAlertRule(90000001, created.RuleID)
+
+	// Assert
+	assert.Equal(t, []string{"webhook", "email"}, rule.Channels)
+	require.NoError(t, updateErr)
+	assert.Equal(t, "Jita sell < 4", updated.Expression)
+	assert.Equal(t, []string{}, updated.Channels)
+	assert.Equal(t, "pilot@example.com", updated.Email)
+	assert.Equal(t, 600, updated.CooldownSeconds)
+	assert.True(t, updated.Enabled)
+	assert.True(t, updated.LastTriggeredAt.IsZero())
+	assert.ErrorIs(t, otherErr, repository.ErrAlertRuleNotFound) // Rules are private to their character
+	assert.ErrorIs(t, deleteOtherErr, repository.ErrAlertRuleNotFound)
+	assert.NoError(t, deleteErr)
+	assert.ErrorIs(t, goneErr, repository.ErrAlertRuleNotFound)
+}
+
+func TestAlertStoreSavesTriggerState(t *testing.T) {
+	// Arrange
+	store := newTestAlertStore(t)
+	enabled, err := store.CreateAlertRule(&models.AlertRule{CharacterID: 90000001, Name: "a", TypeID: 34, Expression: "sell < 5", Enabled: true})
+	require.NoError(t, err)
+	_, err = store.CreateAlertRule(&models.AlertRule{CharacterID: 90000002, Name: "b", TypeID: 35, Expression: "buy > 9", Enabled: false})
+	require.NoError(t, err)
+	checkedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	rules, listErr := store.ListEnabledAlertRules()
+	require.NoError(t, listErr)
+	require.Len(t, rules, 1)
+	rules[0].Triggered, rules[0].LastValue, rules[0].LastKey = true, 4.5, "1:4.5"
+	rules[0].LastCheckedAt, rules[0].LastTriggeredAt = checkedAt, checkedAt
+	saveErr := store.SaveAlertRuleStates(append(rules, models.AlertRule{RuleID: 999})) // Deleted rules are skipped
+	saved, getErr := store.GetAlertRule(90000001, enabled.RuleID)
+
+	// Assert
+	require.NoError(t, saveErr)
+	require.NoError(t, getErr)
+	assert.True(t, saved.Triggered)
+	assert.InDelta(t, 4.5, saved.LastValue, 0.0001)
+	assert.Equal(t, "1:4.5", saved.LastKey)
+	assert.True(t, saved.LastTriggeredAt.Equal(checkedAt))
+	assert.True(t, saved.LastCheckedAt.Equal(checkedAt))
+}
+
+func TestAlertStoreSkipsStateOfRulesUpdatedDuringEvaluation(t *testing.T) {
+	// Arrange: a rule is listed for evaluation and then edited by its owner
+	store := newTestAlertStore(t)
+	created, err := store.CreateAlertRule(&models.AlertRule{CharacterID: 90000001, Name: "a", TypeID: 34, Expression: "sell < 5", Enabled: true})
+	require.NoError(t, err)
+	rules, err := store.ListEnabledAlertRules()
+	require.NoError(t, err)
+	created.Expression = "sell < 4"
+	_, err = store.UpdateAlertRule(created)
+	require.NoError(t, err)
+
+	// Act
+	rules[0].Triggered, rules[0].LastValue = true, 4.5
+	saveErr := store.SaveAlertRuleStates(rules)
+	saved, getErr := store.GetAlertRule(90000001, created.RuleID)
+
+	// Assert: the updated rule keeps its reset state
+	require.NoError(t, saveErr)
+	require.NoError(t, getErr)
+	assert.False(t, saved.Triggered)
+	assert.Zero(t, saved.LastValue)
+	assert.Equal(t, "sell < 4", saved.Expression)
+}
@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMarketSnapshotStore(t *testing.T) *repository.MarketSnapshotStore {
+	t.Helper()
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewMarketSnapshotStore(db)
+	require.NoError(t, err)
+	return store
+}
+
+func TestMarketSnapshotStoreDownsamplesIntoHourlyBuckets(t *testing.T) {
+	// Arrange
+	store := newTestMarketSnapshotStore(t)
+	hour := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveSnapshots([]models.MarketSnapshot{
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour.Add(5 * time.Minute), BuyMax: 4, SellMin: 5, SellVolume: 100, SellOrders: 2},
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour.Add(35 * time.Minute), BuyMax: 6, SellMin: 0, SellVolume: 300, SellOrders: 4},
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour.Add(65 * time.Minute), BuyMax: 5, SellMin: 6},
+	}))
+
+	// Act
+	merged, err := store.DownsampleSnapshots(3600, hour.Add(time.Hour))
+	require.NoError(t, err)
+	snapshots, listErr := store.ListSnapshots(10000002, 34, hour, hour.Add(2*time.Hour))
+
+	// Assert
+	require.NoError(t, listErr)
+	assert.Equal(t, int64(2), merged)
+	require.Len(t, snapshots, 2)
+
+	bucket := snapshots[0]
+	assert.Equal(t, 3600, bucket.Resolution)
+	assert.True(t, bucket.Timestamp.Equal(hour))
+	assert.Equal(t, 2, bucket.Samples)
+	assert.InDelta(t, 5, bucket.BuyMax, 0.0001)
+	assert.InDelta(t, 5, bucket.SellMin, 0.0001) // The sample without sell orders is ignored
+	assert.Equal(t, int64(200), bucket.SellVolume)
+	assert.Equal(t, 3, bucket.SellOrders)
+
+	assert.Equal(t, 0, snapshots[1].Resolution) // The later hour stays raw
+}
+
+func TestMarketSnapshotStoreMergesLateSamplesIntoExistingBuckets(t *testing.T) {
+	// Arrange
+	store := newTestMarketSnapshotStore(t)
+	hour := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveSnapshots([]models.MarketSnapshot{
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour, BuyMax: 4, SellMin: 6},
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour.Add(10 * time.Minute), BuyMax: 4, SellMin: 6},
+	}))
+	_, err := store.DownsampleSnapshots(3600, hour.Add(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, store.SaveSnapshots([]models.MarketSnapshot{
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour.Add(20 * time.Minute), BuyMax: 7, SellMin: 9},
+	}))
+
+	// Act
+	_, err = store.DownsampleSnapshots(3600, hour.Add(time.Hour))
+	require.NoError(t, err)
+	snapshots, listErr := store.ListSnapshots(10000002, 34, hour, hour.Add(time.Hour))
+
+	// Assert
+	require.NoError(t, listErr)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, 3, snapshots[0].Samples)
+	assert.InDelta(t, 5, snapshots[0].BuyMax, 0.0001)
+	assert.InDelta(t, 7, snapshots[0].SellMin, 0.0001)
+}
+
+func TestMarketSnapshotStoreRetention(t *testing.T) {
+	// Arrange
+	store := newTestMarketSnapshotStore(t)
+	old := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveSnapshots([]models.MarketSnapshot{
+		{RegionID: 10000002, TypeID: 34, Timestamp: old, BuyMax: 4},
+		{RegionID: 10000002, TypeID: 34, Timestamp: recent, BuyMax: 5},
+	}))
+	_, err := store.DownsampleSnapshots(3600, recent)
+	require.NoError(t, err)
+	orders := []models.MarketOrder{{OrderID: 1, TypeID: 34, LocationID: 60003760, Price: 5, VolumeTotal: 10, VolumeRemain: 10, Issued: old}}
+	require.NoError(t, store.SaveOrderBook(10000002, 34, old, orders))
+	require.NoError(t, store.SaveOrderBook(10000002, 34, recent, orders))
+
+	// Act
+	deletedSnapshots, err := store.DeleteSnapshotsBefore(3600, recent)
+	require.NoError(t, err)
+	deletedBooks, err := store.DeleteOrderBooksBefore(recent)
+	require.NoError(t, err)
+	snapshots, listErr := store.ListSnapshots(10000002, 34, old, recent.Add(time.Hour))
+
+	// Assert
+	require.NoError(t, listErr)
+	assert.Equal(t, int64(1), deletedSnapshots)
+	assert.Equal(t, int64(1), deletedBooks)
+	require.Len(t, snapshots, 1)
+	assert.True(t, snapshots[0].Timestamp.Equal(recent))
+}
+
+func TestMarketSnapshotStorePreviousOrderBook(t *testing.T) {
+	// Arrange
+	store := newTestMarketSnapshotStore(t)
+	first := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(5 * time.Minute)
+	order := models.MarketOrder{OrderID: 1, TypeID: 34, LocationID: 60003760, SystemID: 30000142, Price: 5,
+		VolumeTotal: 100, VolumeRemain: 100, MinVolume: 1, Duration: 90, Issued: first.Add(-time.Hour), Range: "region"}
+	require.NoError(t, store.SaveOrderBook(10000002, 34, first, []models.MarketOrder{order}))
+	order.VolumeRemain = 40
+	require.NoError(t, store.SaveOrderBook(10000002, 34, second, []models.MarketOrder{order}))
+
+	// Act
+	takenAt, orders, err := store.PreviousOrderBook(10000002, 34, second)
+	noneAt, _, noneErr := store.PreviousOrderBook(10000002, 34, first)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, noneErr)
+	assert.True(t, takenAt.Equal(first))
+	require.Len(t, orders, 1)
+	order.VolumeRemain = 100
+	assert.Equal(t, order, orders[0])
+	assert.True(t, noneAt.IsZero())
+}
@@ -0,0 +1,203 @@
+package repository_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openUnmigratedAppDB(t *testing.T) *repository.AppDB {
+	t.Helper()
+	db, err := repository.OpenAppDB(repository.DialectSQLite, filepath.Join(t.TempDir(), "app.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestAppDBMigrateAppliesAllMigrationsOnce(t *testing.T) {
+	// Arrange
+	db := openUnmigratedAppDB(t)
+
+	// Act
+	applied, err := db.Migrate()
+	require.NoError(t, err)
+	again, againErr := db.Migrate()
+	version, versionErr := db.SchemaVersion()
+	status := db.SchemaStatus()
+
+	// Assert
+	assert.Len(t, applied, len(repository.Migrations()))
+	require.NoError(t, againErr)
+	assert.Empty(t, again)
+	require.NoError(t, versionErr)
+	assert.Equal(t, repository.LatestSchemaVersion(), version)
+	assert.Equal(t, "sqlite", status.Driver)
+	assert.True(t, status.UpToDate)
+	assert.False(t, status.MigratedAt.IsZero())
+	assert.NoError(t, db.CheckSchema())
+}
+
+func TestAppDBStoresRequireTheirMigrations(t *testing.T) {
+	// Arrange
+	db := openUnmigratedAppDB(t)
+
+	// Act
+	_, err := repository.NewWatchlistStore(db)
+	status := db.SchemaStatus()
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrSchemaOutdated)
+	assert.ErrorIs(t, db.CheckSchema(), repository.ErrSchemaOutdated)
+	assert.Empty(t, status.Error)
+	assert.Equal(t, 0, status.Version)
+	assert.False(t, status.UpToDate)
+
+	// Checking the schema does not write to the database
+	var tables int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables))
+	assert.Equal(t, 0, tables)
+}
+
+func TestMigrationsAreFrozen(t *testing.T) {
+	// Applied migrations must never change, schema changes get a new version.
+	// Only append hashes here, never update one.
+	frozen := []string{
+		"36911669b0232ad6cdee63e5dbc967e38ad248cd3c8c1e2b39ebd1c2ae964906",
+		"d01c724acf3f1a1d5faa8ef4e19729a79474c6f5d355d703dae39548d6c223ad",
+		"cbb7ccc9bc340be7b845b97d69c08310da3cc862cb55bf67fff205fd6d1a8ce6",
+		"98dbb92d6fbf99ba9488a67407d9ece4c6075baf78f1fa67c7809797dd896929",
+		"1af1fd4a9658f875d4177d6c52cd71f988f7999a8fffec991fc4dde1abbe5bde",
+		"ceeaa6adc98dac00b4579d902a643f0d428975ed5a4e5969aa7337423e7b8e19",
+		"c97351e40555c6fbd39af2552af4cc4751e88c854b98a5d999ea02c037997a4f",
+		"d985a66d0dfc03b291afe4d7d3f113b2e481faeb139d6e5504f7b945a6afa544",
+	}
+
+	migrations := repository.Migrations()
+	require.GreaterOrEqual(t, len(migrations), len(frozen))
+	for i, hash := range frozen {
+		sum := sha256.Sum256([]byte(migrations[i].SQL))
+		assert.Equal(t, hash, hex.EncodeToString(sum[:]), "migration %d was edited", migrations[i].Version)
+	}
+}
+
+func TestAppDBMigrateAdoptsUnversionedDatabases(t *testing.T) {
+	// Arrange
+	db := openUnmigratedAppDB(t)
+	_, err := db.Exec(`
+		CREATE TABLE characterTokens (
+			characterID INTEGER PRIMARY KEY,
+			characterName TEXT NOT NULL DEFAULT '',
+			accessToken BLOB NOT NULL,
+			refreshToken BLOB NOT NULL,
+			tokenType TEXT NOT NULL DEFAULT '',
+			expiresAt INTEGER NOT NULL,
+			scopes TEXT NOT NULL DEFAULT '',
+			revoked INTEGER NOT NULL DEFAULT 0,
+			updatedAt INTEGER NOT NULL
+		)
+	`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO characterTokens (characterID, accessToken, refreshToken, expiresAt, updatedAt) VALUES (1, x'00', x'00', 0, 0)`)
+	require.NoError(t, err)
+
+	// Act
+	_, err = db.Migrate()
+
+	// Assert
+	require.NoError(t, err)
+	var tokens int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM characterTokens`).Scan(&tokens))
+	assert.Equal(t, 1, tokens) // Existing data is kept
+}
+
+func TestAppDBMigrateRejectsNewerSchemas(t *testing.T) {
+	// Arrange
+	db := openUnmigratedAppDB(t)
+	_, err := db.Migrate()
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO schemaMigrations (version, name, appliedAt) VALUES (?, 'from the future', 0)`,
+		repository.LatestSchemaVersion()+1)
+	require.NoError(t, err)
+
+	// Act
+	_, err = db.Migrate()
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrSchemaTooNew)
+}
+
+func TestParseDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   string
+		expected repository.Dialect
+		wantErr  bool
+	}{
+		{name: "should default to SQLite", driver: "", expected: repository.DialectSQLite},
+		{name: "should accept the SQLite driver name", driver: "sqlite3", expected: repository.DialectSQLite},
+		{name: "should accept postgresql", driver: " PostgreSQL ", expected: repository.DialectPostgres},
+		{name: "should reject unknown drivers", driver: "mysql", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			dialect, err := repository.ParseDialect(tt.driver)
+
+			// Assert
+			if tt.wantErr {
+				assert.ErrorIs(t, err, repository.ErrUnknownDialect)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, dialect)
+		})
+	}
+}
+
+func TestDialectRebind(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  repository.Dialect
+		query    string
+		expected string
+	}{
+		{name: "should keep SQLite queries", dialect: repository.DialectSQLite,
+			query: "SELECT * FROM a WHERE b = ? AND c = ?", expected: "SELECT * FROM a WHERE b = ? AND c = ?"},
+		{name: "should number Postgres placeholders", dialect: repository.DialectPostgres,
+			query: "SELECT * FROM a WHERE b = ? AND (? = 0 OR c = ?)", expected: "SELECT * FROM a WHERE b = $1 AND ($2 = 0 OR c = $3)"},
+		{name: "should keep numbered placeholders", dialect: repository.DialectPostgres,
+			query: "SELECT ?1, ?2 / ?1", expected: "SELECT $1, $2 / $1"},
+		{name: "should skip quoted text", dialect: repository.DialectPostgres,
+			query: "SELECT '?' WHERE a = ?", expected: "SELECT '?' WHERE a = $1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			query := tt.dialect.Rebind(tt.query)
+
+			// Assert
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestDialectDDLTranslatesColumnTypesForPostgres(t *testing.T) {
+	// Arrange
+	ddl := `CREATE TABLE a (id INTEGER PRIMARY KEY AUTOINCREMENT, price REAL NOT NULL, token BLOB, note TEXT)`
+
+	// Act
+	sqlite := repository.DialectSQLite.DDL(ddl)
+	postgres := repository.DialectPostgres.DDL(ddl)
+
+	// Assert
+	assert.Equal(t, ddl, sqlite)
+	assert.Equal(t, `CREATE TABLE a (id BIGSERIAL PRIMARY KEY, price DOUBLE PRECISION NOT NULL, token BYTEA, note TEXT)`, postgres)
+}
@@ -0,0 +1,91 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderEventStoreListsEventsOfOneStation(t *testing.T) {
+	// Arrange
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewOrderEventStore(db)
+	require.NoError(t, err)
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	events := []models.OrderEvent{
+		{RegionID: 10000002, TypeID: 34, OrderID: 1, Type: models.OrderEventPartialFill, LocationID: 60003760, Price: 5, Volume: 10, Timestamp: at},
+		{RegionID: 10000002, TypeID: 34, OrderID: 2, Type: models.OrderEventNew, LocationID: 60008494, Price: 6, Volume: 20, Timestamp: at},
+		{RegionID: 10000002, TypeID: 34, OrderID: 1, Type: models.OrderEventModified, LocationID: 60003760, Price: 4.9, PreviousPrice: 5, Volume: 90, Timestamp: at.Add(5 * time.Minute)},
+	}
+
+	// Act
+	require.NoError(t, store.SaveOrderEvents(events))
+	require.NoError(t, store.SaveOrderEvents(events[:1])) // Recorded twice
+	jita, err := store.ListOrderEvents(10000002, 34, 60003760, models.OrderEventCursor{Timestamp: at.Add(-time.Minute)}, at.Add(time.Hour), 0)
+	require.NoError(t, err)
+	region, err := store.ListOrderEvents(10000002, 34, 0, models.OrderEventCursor{Timestamp: at.Add(-time.Minute)}, at.Add(time.Hour), 2)
+	require.NoError(t, err)
+	after, err := store.ListOrderEvents(10000002, 34, 0, models.OrderEventCursor{Timestamp: at}, at.Add(time.Hour), 0)
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, jita, 2)
+	assert.Equal(t, events[0], jita[0])
+	assert.Equal(t, events[2], jita[1])
+	assert.Len(t, region, 2)
+	require.Len(t, after, 1) // Events at exactly `after` were already received
+	assert.Equal(t, models.OrderEventModified, after[0].Type)
+}
+
+func TestOrderEventStoreResumesBehindCursor(t *testing.T) {
+	// Arrange: more events share one snapshot second than fit on a page
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewOrderEventStore(db)
+	require.NoError(t, err)
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveOrderEvents([]models.OrderEvent{
+		{RegionID: 10000002, TypeID: 34, OrderID: 1, Type: models.OrderEventModified, Timestamp: at},
+		{RegionID: 10000002, TypeID: 34, OrderID: 1, Type: models.OrderEventPartialFill, Timestamp: at},
+		{RegionID: 10000002, TypeID: 34, OrderID: 2, Type: models.OrderEventNew, Timestamp: at},
+		{RegionID: 10000002, TypeID: 34, OrderID: 1, Type: models.OrderEventFilled, Timestamp: at.Add(5 * time.Minute)},
+	}))
+
+	// Act
+	first, err := store.ListOrderEvents(10000002, 34, 0, models.OrderEventCursor{Timestamp: at.Add(-time.Minute)}, at.Add(time.Hour), 1)
+	require.NoError(t, err)
+	rest, err := store.ListOrderEvents(10000002, 34, 0,
+		models.OrderEventCursor{Timestamp: first[0].Timestamp, OrderID: first[0].OrderID, EventType: first[0].Type}, at.Add(time.Hour), 0)
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, first, 1)
+	assert.Equal(t, models.OrderEventModified, first[0].Type)
+	require.Len(t, rest, 3) // The other events of the same second are not skipped
+	assert.Equal(t, models.OrderEventPartialFill, rest[0].Type)
+	assert.Equal(t, int64(2), rest[1].OrderID)
+	assert.Equal(t, models.OrderEventFilled, rest[2].Type)
+}
+
+func TestOrderEventStoreDeletesOldEvents(t *testing.T) {
+	// Arrange
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewOrderEventStore(db)
+	require.NoError(t, err)
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveOrderEvents([]models.OrderEvent{
+		{RegionID: 10000002, TypeID: 34, OrderID: 1, Type: models.OrderEventNew, Timestamp: at.Add(-48 * time.Hour)},
+		{RegionID: 10000002, TypeID: 34, OrderID: 2, Type: models.OrderEventNew, Timestamp: at},
+	}))
+
+	// Act
+	deleted, err := store.DeleteOrderEventsBefore(at.Add(-24 * time.Hour))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
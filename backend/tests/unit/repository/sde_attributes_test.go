@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDERepositoryGetItemByIDPopulatesMassAndDescription(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	item, err := repo.GetItemByID(587) // Rifter
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1067000.0, item.Mass)
+	assert.Equal(t, "The Rifter is a very powerful combat frigate.", item.Description)
+}
+
+func TestSDERepositoryGetTypeAttributes(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	attributes, err := repo.GetTypeAttributes(2873) // 200mm AutoCannon I
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, attributes, 7) // Unpublished attributes are returned as well
+
+	byID := make(map[int32]*repository.SDEAttribute)
+	for _, attribute := range attributes {
+		byID[attribute.AttributeID] = attribute
+	}
+
+	rateOfFire := byID[51]
+	require.NotNil(t, rateOfFire)
+	assert.Equal(t, "Rate of fire", rateOfFire.DisplayName)
+	assert.Equal(t, "s", rateOfFire.UnitName)
+	assert.Equal(t, 3000.0, rateOfFire.Value)
+	assert.False(t, rateOfFire.HighIsGood)
+
+	// Integer values are stored in valueInt
+	assert.Equal(t, 1.0, byID[repository.AttributeTechLevel].Value)
+	assert.False(t, byID[1211].Published)
+}
+
+func TestSDERepositoryGetTypesAttributes(t *testing.T) {
+	tests := []struct {
+		name          string
+		typeIDs       []int32
+		expectedTypes int
+	}{
+		{"several types", []int32{2873, 2881, 587}, 3},
+		{"type without attributes", []int32{34}, 0},
+		{"no types", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := newFixtureRepository(t)
+
+			// Act
+			attributes, err := repo.GetTypesAttributes(tt.typeIDs)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Len(t, attributes, tt.expectedTypes)
+		})
+	}
+}
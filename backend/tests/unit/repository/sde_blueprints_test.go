@@ -0,0 +1,45 @@
+package repository_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/repository"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDERepositoryGetBlueprintByProduct(t *testing.T) {
+	// Arrange
+	repo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	// Act
+	blueprint, err := repo.GetBlueprintByProduct(587) // Rifter
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(691), blueprint.BlueprintTypeID)
+	assert.Equal(t, repository.ActivityManufacturing, blueprint.ActivityID)
+	assert.Equal(t, int32(1), blueprint.ProductQuantity)
+	assert.Equal(t, int32(6000), blueprint.Time)
+	require.Len(t, blueprint.Materials, 3)
+	assert.Equal(t, repository.SDEBlueprintMaterial{TypeID: 34, Quantity: 32000}, blueprint.Materials[0])
+	assert.Equal(t, []repository.SDEBlueprintSkill{{SkillID: 3380, Level: 1}}, blueprint.Skills)
+}
+
+func TestSDERepositoryGetBlueprintByProductNotFound(t *testing.T) {
+	// Arrange
+	repo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	// Act
+	blueprint, err := repo.GetBlueprintByProduct(34) // Tritanium cannot be manufactured
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrBlueprintNotFound)
+	assert.Nil(t, blueprint)
+}
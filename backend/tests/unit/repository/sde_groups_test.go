@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/repository"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFixtureRepository(t *testing.T) *repository.SDERepository {
+	repo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestSDERepositoryGetItemByIDPopulatesGroupAndCategory(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	item, err := repo.GetItemByID(587) // Rifter
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Frigate", item.GroupName)
+	assert.Equal(t, int32(6), item.CategoryID)
+	assert.Equal(t, "Ship", item.CategoryName)
+	assert.Equal(t, int32(64), item.MarketGroup)
+}
+
+func TestSDERepositoryGetCategories(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	categories, err := repo.GetCategories()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, categories, 7) // Unpublished categories are excluded
+	assert.Equal(t, "Asteroid", categories[0].CategoryName)
+}
+
+func TestSDERepositoryGetGroupsByCategory(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	groups, err := repo.GetGroupsByCategory(6)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "Frigate", groups[0].GroupName)
+}
+
+func TestSDERepositoryGetMarketGroups(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	marketGroups, err := repo.GetMarketGroups()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, marketGroups, 10)
+
+	byID := make(map[int32]*repository.SDEMarketGroup)
+	for _, marketGroup := range marketGroups {
+		byID[marketGroup.MarketGroupID] = marketGroup
+	}
+	assert.Equal(t, int32(0), byID[4].ParentGroupID)
+	assert.Equal(t, int32(1361), byID[64].ParentGroupID)
+	assert.True(t, byID[64].HasTypes)
+}
+
+func TestSDERepositoryGetItemsByMarketGroup(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	items, err := repo.GetItemsByMarketGroup(1857) // Minerals
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, "Mexallon", items[0].TypeName)
+	assert.Equal(t, "Material", items[0].CategoryName)
+}
+
+func TestSDERepositoryGetAllItemsIncludesUnpublished(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	items, err := repo.GetAllItems()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, items, 14)
+
+	var prototype *repository.SDEItem
+	for _, item := range items {
+		if item.TypeID == 29984 {
+			prototype = item
+		}
+	}
+	require.NotNil(t, prototype)
+	assert.False(t, prototype.Published)
+	assert.Equal(t, int32(0), prototype.MarketGroup)
+}
@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/repository"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDERepositoryGetSolarSystems(t *testing.T) {
+	// Arrange
+	repo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	// Act
+	systems, err := repo.GetSolarSystems()
+	jumps, jumpsErr := repo.GetSolarSystemJumps()
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, jumpsErr)
+	require.Len(t, systems, 5)
+	assert.Equal(t, repository.SDESolarSystem{SolarSystemID: 30000139, SolarSystemName: "Urlen", RegionID: 10000002, Security: 0.96}, *systems[0])
+	assert.Len(t, jumps, 10)
+	assert.Contains(t, jumps, repository.SDESolarSystemJump{FromSolarSystemID: 30000142, ToSolarSystemID: 30002813})
+}
+
+func TestSDERepositoryGetStationByID(t *testing.T) {
+	// Arrange
+	repo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	// Act
+	station, err := repo.GetStationByID(60003760)
+	_, missingErr := repo.GetStationByID(1035466617946) // Player structure
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(30000142), station.SystemID)
+	assert.Equal(t, int32(10000002), station.RegionID)
+	assert.ErrorIs(t, missingErr, repository.ErrStationNotFound)
+}
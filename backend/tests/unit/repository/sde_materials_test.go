@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/repository"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDERepositoryGetTypeMaterials(t *testing.T) {
+	// Arrange
+	repo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	// Act
+	materials, err := repo.GetTypeMaterials(1230) // Veldspar
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(100), materials.PortionSize)
+	assert.Equal(t, repository.CategoryAsteroid, materials.CategoryID)
+	assert.Equal(t, []repository.SDEBlueprintMaterial{{TypeID: 34, Quantity: 400}}, materials.Materials)
+}
+
+func TestSDERepositoryGetTypeMaterialsErrors(t *testing.T) {
+	// Arrange
+	repo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	// Act
+	_, mineralErr := repo.GetTypeMaterials(34) // Minerals cannot be reprocessed
+	_, missingErr := repo.GetTypeMaterials(999999)
+
+	// Assert
+	assert.ErrorIs(t, mineralErr, repository.ErrTypeMaterialsNotFound)
+	assert.ErrorIs(t, missingErr, repository.ErrItemNotFound)
+}
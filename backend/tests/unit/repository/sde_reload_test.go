@@ -0,0 +1,173 @@
+package repository_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// execSDE runs statements against an SDE database file
+func execSDE(t *testing.T, path string, statements ...string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, statement := range statements {
+		_, err := db.Exec(statement)
+		require.NoError(t, err)
+	}
+}
+
+// replaceSDEFile atomically moves a new database over the served one with a newer timestamp
+func replaceSDEFile(t *testing.T, newPath, servedPath string) {
+	t.Helper()
+
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(newPath, future, future))
+	require.NoError(t, os.Rename(newPath, servedPath))
+}
+
+func TestSDERepositoryRejectsInvalidSchema(t *testing.T) {
+	// Arrange: a database without most SDE tables
+	path := filepath.Join(t.TempDir(), "broken.sqlite")
+	execSDE(t, path, `CREATE TABLE invTypes (typeID INTEGER PRIMARY KEY, typeName TEXT)`)
+
+	// Act
+	repo, err := repository.NewSDERepository(path)
+
+	// Assert
+	assert.Nil(t, repo)
+	assert.ErrorIs(t, err, repository.ErrInvalidSDESchema)
+	assert.Contains(t, err.Error(), "missing column invTypes.groupID")
+	assert.Contains(t, err.Error(), "missing table invGroups")
+}
+
+func TestSDERepositoryVersionFromMetadata(t *testing.T) {
+	// Arrange
+	path := fixtures.CreateTestSDEDatabase(t)
+	execSDE(t, path,
+		`CREATE TABLE `+repository.SDEMetadataTable+` (key TEXT PRIMARY KEY, value TEXT)`,
+		`INSERT INTO `+repository.SDEMetadataTable+` VALUES ('build_number', '3064089'), ('checksum', 'abc123')`,
+	)
+
+	// Act
+	repo, err := repository.NewSDERepository(path)
+	require.NoError(t, err)
+	defer repo.Close()
+	version := repo.Version()
+
+	// Assert
+	assert.Equal(t, int64(3064089), version.BuildNumber)
+	assert.Equal(t, "abc123", version.Checksum)
+	assert.Equal(t, path, version.Path)
+	assert.False(t, version.ModifiedAt.IsZero())
+}
+
+func TestSDERepositoryHotSwapsChangedFile(t *testing.T) {
+	// Arrange
+	servedPath := fixtures.CreateTestSDEDatabase(t)
+	repo, err := repository.NewSDERepository(servedPath)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var swappedTo []models.SDEVersion
+	repo.OnSwap(func(version models.SDEVersion) {
+		swappedTo = append(swappedTo, version)
+	})
+
+	newPath := fixtures.CreateTestSDEDatabase(t)
+	execSDE(t, newPath,
+		`UPDATE invTypes SET typeName = 'Tritanium II' WHERE typeID = 34`,
+		`CREATE TABLE `+repository.SDEMetadataTable+` (key TEXT PRIMARY KEY, value TEXT)`,
+		`INSERT INTO `+repository.SDEMetadataTable+` VALUES ('build_number', '3070000')`,
+	)
+
+	// Act
+	unchanged, err := repo.CheckForUpdate()
+	require.NoError(t, err)
+	replaceSDEFile(t, newPath, servedPath)
+	settling, err := repo.CheckForUpdate()
+	require.NoError(t, err)
+	swapped, err := repo.CheckForUpdate()
+	require.NoError(t, err)
+
+	// Assert
+	assert.False(t, unchanged)
+	assert.False(t, settling, "a changed file is only loaded once it stopped changing")
+	assert.True(t, swapped)
+	require.Len(t, swappedTo, 1)
+	assert.Equal(t, int64(3070000), swappedTo[0].BuildNumber)
+	assert.Equal(t, int64(3070000), repo.Version().BuildNumber)
+
+	item, err := repo.GetItemByID(34)
+	require.NoError(t, err)
+	assert.Equal(t, "Tritanium II", item.TypeName)
+}
+
+func TestSDERepositoryKeepsServingWhenNewFileIsInvalid(t *testing.T) {
+	// Arrange
+	servedPath := fixtures.CreateTestSDEDatabase(t)
+	repo, err := repository.NewSDERepository(servedPath)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	brokenPath := filepath.Join(t.TempDir(), "broken.sqlite")
+	execSDE(t, brokenPath, `CREATE TABLE invTypes (typeID INTEGER PRIMARY KEY)`)
+
+	// Act
+	replaceSDEFile(t, brokenPath, servedPath)
+	_, err = repo.Reload()
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrInvalidSDESchema)
+
+	item, err := repo.GetItemByID(34)
+	require.NoError(t, err)
+	assert.Equal(t, "Tritanium", item.TypeName)
+}
+
+func TestSDERepositoryReloadDuringQueries(t *testing.T) {
+	// Arrange
+	path := fixtures.CreateTestSDEDatabase(t)
+	repo, err := repository.NewSDERepository(path)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	// Act: queries keep running while the database is swapped repeatedly
+	var wg sync.WaitGroup
+	errs := make(chan error, 400)
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if _, err := repo.GetBlueprintByProduct(587); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		_, err := repo.Reload()
+		require.NoError(t, err)
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDERepositoryGetTypeVariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		typeID int32
+	}{
+		{"from the T1 parent", 2873},
+		{"from a T2 variant", 2881},
+		{"from a faction variant", 13777},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := newFixtureRepository(t)
+
+			// Act
+			variants, err := repo.GetTypeVariants(tt.typeID)
+
+			// Assert
+			require.NoError(t, err)
+			require.Len(t, variants, 4)
+
+			metaGroups := make(map[int32]string)
+			for _, variant := range variants {
+				assert.Equal(t, int32(2873), variant.ParentTypeID)
+				metaGroups[variant.TypeID] = variant.MetaGroupName
+			}
+			assert.Equal(t, "Tech I", metaGroups[2873]) // Parents have no invMetaTypes row
+			assert.Equal(t, "Tech II", metaGroups[2881])
+			assert.Equal(t, "Faction", metaGroups[13777])
+		})
+	}
+}
+
+func TestSDERepositoryGetTypeVariantsWithoutVariants(t *testing.T) {
+	// Arrange
+	repo := newFixtureRepository(t)
+
+	// Act
+	variants, err := repo.GetTypeVariants(587) // Rifter
+	_, missingErr := repo.GetTypeVariants(999999)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, variants, 1)
+	assert.Equal(t, repository.MetaGroupTechI, variants[0].MetaGroupID)
+	assert.ErrorIs(t, missingErr, repository.ErrItemNotFound)
+}
@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestAppDB(t *testing.T) (*repository.AppDB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data", "app.sqlite")
+	db, err := repository.OpenAppDatabase(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+func testToken(characterID int32) *models.AuthToken {
+	return &models.AuthToken{
+		AccessToken:   "access-secret",
+		RefreshToken:  "refresh-secret",
+		TokenType:     "Bearer",
+		ExpiresAt:     time.Now().Add(20 * time.Minute).Truncate(time.Second),
+		CharacterID:   characterID,
+		CharacterName: "Test Pilot",
+		Scopes:        []string{"esi-wallet.read_character_wallet.v1", "esi-assets.read_assets.v1"},
+	}
+}
+
+func TestSQLiteTokenStoreRoundTrip(t *testing.T) {
+	// Arrange
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewSQLiteTokenStore(db, "test-key")
+	require.NoError(t, err)
+	token := testToken(90000001)
+
+	// Act
+	require.NoError(t, store.SaveToken(token))
+	stored, err := store.GetToken(90000001)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, token.AccessToken, stored.AccessToken)
+	assert.Equal(t, token.RefreshToken, stored.RefreshToken)
+	assert.Equal(t, token.Scopes, stored.Scopes)
+	assert.True(t, token.ExpiresAt.Equal(stored.ExpiresAt))
+	assert.False(t, stored.Revoked)
+}
+
+func TestSQLiteTokenStoreEncryptsAtRest(t *testing.T) {
+	// Arrange
+	db, path := openTestAppDB(t)
+	store, err := repository.NewSQLiteTokenStore(db, "test-key")
+	require.NoError(t, err)
+	require.NoError(t, store.SaveToken(testToken(90000001)))
+
+	// Act
+	var accessToken, refreshToken []byte
+	err = db.QueryRow(`SELECT accessToken, refreshToken FROM characterTokens`).Scan(&accessToken, &refreshToken)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotContains(t, string(accessToken), "access-secret")
+	assert.NotContains(t, string(refreshToken), "refresh-secret")
+
+	// A store with another key cannot read the tokens
+	otherDB, err := repository.OpenAppDatabase(path)
+	require.NoError(t, err)
+	defer otherDB.Close()
+	otherStore, err := repository.NewSQLiteTokenStore(otherDB, "other-key")
+	require.NoError(t, err)
+	_, err = otherStore.GetToken(90000001)
+	assert.ErrorIs(t, err, repository.ErrTokenDecryptionFailed)
+}
+
+func TestSQLiteTokenStoreRevocation(t *testing.T) {
+	// Arrange
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewSQLiteTokenStore(db, "test-key")
+	require.NoError(t, err)
+	require.NoError(t, store.SaveToken(testToken(90000001)))
+	require.NoError(t, store.SaveToken(testToken(90000002)))
+
+	// Act
+	require.NoError(t, store.MarkRevoked(90000001))
+	tokens, err := store.ListTokens()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.True(t, tokens[0].Revoked)
+	assert.False(t, tokens[1].Revoked)
+
+	// A new login clears the flag
+	require.NoError(t, store.SaveToken(testToken(90000001)))
+	stored, err := store.GetToken(90000001)
+	require.NoError(t, err)
+	assert.False(t, stored.Revoked)
+}
+
+func TestSQLiteTokenStoreErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		act         func(store *repository.SQLiteTokenStore) error
+		expectedErr error
+	}{
+		{
+			name: "should report unknown character",
+			act: func(store *repository.SQLiteTokenStore) error {
+				_, err := store.GetToken(12345)
+				return err
+			},
+			expectedErr: repository.ErrTokenNotFound,
+		},
+		{
+			name:        "should report revoking unknown character",
+			act:         func(store *repository.SQLiteTokenStore) error { return store.MarkRevoked(12345) },
+			expectedErr: repository.ErrTokenNotFound,
+		},
+		{
+			name: "should not find deleted token",
+			act: func(store *repository.SQLiteTokenStore) error {
+				if err := store.SaveToken(testToken(90000001)); err != nil {
+					return err
+				}
+				if err := store.DeleteToken(90000001); err != nil {
+					return err
+				}
+				_, err := store.GetToken(90000001)
+				return err
+			},
+			expectedErr: repository.ErrTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db, _ := openTestAppDB(t)
+			store, err := repository.NewSQLiteTokenStore(db, "test-key")
+			require.NoError(t, err)
+
+			// Act
+			err = tt.act(store)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+
+	t.Run("should require an encryption key", func(t *testing.T) {
+		db, _ := openTestAppDB(t)
+		_, err := repository.NewSQLiteTokenStore(db, "")
+		assert.ErrorIs(t, err, repository.ErrMissingEncryptionKey)
+	})
+}
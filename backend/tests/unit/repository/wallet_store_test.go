@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletStoreDeduplicatesByID(t *testing.T) {
+	// Arrange
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewWalletStore(db)
+	require.NoError(t, err)
+	date := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	transactions := []models.WalletTransaction{
+		{TransactionID: 2, Date: date.Add(time.Hour), TypeID: 34, Quantity: 50, UnitPrice: 6, LocationID: 60003760},
+		{TransactionID: 1, Date: date, TypeID: 34, Quantity: 100, UnitPrice: 5, LocationID: 60003760, IsBuy: true},
+	}
+	journal := []models.WalletJournalEntry{
+		{ID: 10, Date: date, RefType: "transaction_tax", Amount: -15, ContextID: 2, ContextIDType: "market_transaction_id"},
+	}
+
+	// Act
+	first, err := store.SaveTransactions(90000001, transactions)
+	require.NoError(t, err)
+	second, err := store.SaveTransactions(90000001, transactions[:1])
+	require.NoError(t, err)
+	journalCount, err := store.SaveJournalEntries(90000001, journal)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 2, first)
+	assert.Zero(t, second)
+	assert.Equal(t, 1, journalCount)
+
+	stored, err := store.ListTransactions(90000001)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, int64(1), stored[0].TransactionID) // Oldest first
+	assert.True(t, stored[0].IsBuy)
+	assert.True(t, stored[0].Date.Equal(date))
+
+	entries, err := store.ListJournalEntries(90000001)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "market_transaction_id", entries[0].ContextIDType)
+
+	latestTransaction, err := store.LatestTransactionID(90000001)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), latestTransaction)
+	latestJournal, err := store.LatestJournalID(90000002)
+	require.NoError(t, err)
+	assert.Zero(t, latestJournal)
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWatchlistStore(t *testing.T) *repository.WatchlistStore {
+	t.Helper()
+	db, _ := openTestAppDB(t)
+	store, err := repository.NewWatchlistStore(db)
+	require.NoError(t, err)
+	return store
+}
+
+func TestWatchlistStoreWatchlistLifecycle(t *testing.T) {
+	// Arrange
+	store := newTestWatchlistStore(t)
+	list := &models.Watchlist{CharacterID: 90000001, Name: "Minerals", Notes: "Restock weekly", Items: []models.WatchlistItem{
+		{TypeID: 35, RegionID: 10000002, StationID: 60003760, Note: "Jita only"},
+		{TypeID: 34, RegionID: 10000043},
+	}}
+
+	// Act
+	created, err := store.CreateWatchlist(list)
+	require.NoError(t, err)
+	_, err = store.CreateWatchlist(&models.Watchlist{CharacterID: 90000001, Name: "Empty"})
+	require.NoError(t, err)
+	created.Name = "Ores"
+	created.Items = []models.WatchlistItem{{TypeID: 1230, RegionID: 10000002}}
+	updated, updateErr := store.UpdateWatchlist(created)
+	lists, listErr := store.ListWatchlists(90000001)
+	_, otherErr := store.GetWatchlist(90000002, created.WatchlistID)
+	deleteOtherErr := store.DeleteWatchlist(90000002, created.WatchlistID)
+	deleteErr := store.DeleteWatchlist(90000001, created.WatchlistID)
+	_, goneErr := store.GetWatchlist(90000001, created.WatchlistID)
+
+	// Assert
+	require.Len(t, list.Items, 2)
+	require.NoError(t, updateErr)
+	assert.Equal(t, "Ores", updated.Name)
+	assert.Equal(t, "Restock weekly", updated.Notes)
+	assert.Equal(t, []models.WatchlistItem{{TypeID: 1230, RegionID: 10000002}}, updated.Items)
+	require.NoError(t, listErr)
+	require.Len(t, lists, 2)
+	assert.Len(t, lists[0].Items, 1)
+	assert.Equal(t, []models.WatchlistItem{}, lists[1].Items)
+	assert.ErrorIs(t, otherErr, repository.ErrWatchlistNotFound) // Watchlists are private to their character
+	assert.ErrorIs(t, deleteOtherErr, repository.ErrWatchlistNotFound)
+	assert.NoError(t, deleteErr)
+	assert.ErrorIs(t, goneErr, repository.ErrWatchlistNotFound)
+}
+
+func TestWatchlistStoreKeepsItemOrder(t *testing.T) {
+	// Arrange
+	store := newTestWatchlistStore(t)
+	items := []models.WatchlistItem{{TypeID: 40, RegionID: 10000002}, {TypeID: 34, RegionID: 10000002}, {TypeID: 37, RegionID: 10000002}}
+
+	// Act
+	created, err := store.CreateWatchlist(&models.Watchlist{CharacterID: 90000001, Name: "Ordered", Items: items})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, items, created.Items)
+}
+
+func TestWatchlistStoreSavedSearchLifecycle(t *testing.T) {
+	// Arrange
+	store := newTestWatchlistStore(t)
+	published, marketable := true, false
+
+	// Act
+	created, err := store.CreateSavedSearch(&models.SavedSearch{CharacterID: 90000001, Name: "Ammo", Query: "antimatter",
+		CategoryID: 8, Published: &published})
+	require.NoError(t, err)
+	created.Marketable = &marketable
+	created.Published = nil
+	updated, updateErr := store.UpdateSavedSearch(created)
+	searches, listErr := store.ListSavedSearches(90000001)
+	updateOtherErr := func() error {
+		other := *created
+		other.CharacterID = 90000002
+		_, err := store.UpdateSavedSearch(&other)
+		return err
+	}()
+	deleteErr := store.DeleteSavedSearch(90000001, created.SearchID)
+	_, goneErr := store.GetSavedSearch(90000001, created.SearchID)
+
+	// Assert
+	require.NoError(t, updateErr)
+	assert.Equal(t, "antimatter", updated.Query)
+	assert.Equal(t, int32(8), updated.CategoryID)
+	assert.Nil(t, updated.Published)
+	require.NotNil(t, updated.Marketable)
+	assert.False(t, *updated.Marketable)
+	require.NoError(t, listErr)
+	assert.Len(t, searches, 1)
+	assert.ErrorIs(t, updateOtherErr, repository.ErrSavedSearchNotFound)
+	assert.NoError(t, deleteErr)
+	assert.ErrorIs(t, goneErr, repository.ErrSavedSearchNotFound)
+}
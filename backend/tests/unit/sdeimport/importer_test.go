@@ -0,0 +1,306 @@
+package sdeimport_test
+
+import (
+	"archive/zip"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/sdeimport"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testArchiveFiles is a minimal official SDE archive in JSONL format
+func testArchiveFiles() map[string]string {
+	return map[string]string{
+		"_sde.jsonl": `{"_key":"sde","buildNumber":3064089,"releaseDate":"2025-10-01T11:00:00Z"}`,
+		"types.jsonl": `{"_key":34,"groupID":18,"name":{"en":"Tritanium","de":"Tritanium"},"volume":0.01,"portionSize":1,"published":true,"marketGroupID":1857}
+{"_key":35,"groupID":18,"name":{"en":"Pyerite"},"volume":0.01,"portionSize":1,"published":true,"marketGroupID":1857}
+{"_key":587,"groupID":25,"name":{"en":"Rifter"},"description":{"en":"A fast frigate."},"mass":1067000,"volume":27289,"portionSize":1,"published":true,"marketGroupID":64}
+{"_key":691,"groupID":105,"name":{"en":"Rifter Blueprint"},"volume":0.01,"portionSize":1,"published":true}`,
+		"groups.jsonl": `{"_key":18,"categoryID":4,"name":{"en":"Mineral"},"published":true}
+{"_key":25,"categoryID":6,"name":{"en":"Frigate"},"published":true}
+{"_key":105,"categoryID":9,"name":{"en":"Frigate Blueprint"},"published":true}`,
+		"categories.jsonl": `{"_key":4,"name":{"en":"Material"},"published":true}
+{"_key":6,"name":{"en":"Ship"},"published":true}
+{"_key":9,"name":{"en":"Blueprint"},"published":true}`,
+		"marketGroups.jsonl": `{"_key":533,"name":{"en":"Materials"},"hasTypes":false}
+{"_key":1857,"parentGroupID":533,"name":{"en":"Minerals"},"description":{"en":"Refined minerals."},"hasTypes":true}
+{"_key":64,"name":{"en":"Frigates"},"hasTypes":true}`,
+		"typeMaterials.jsonl": `{"_key":587,"materials":[{"materialTypeID":34,"quantity":32000},{"materialTypeID":35,"quantity":6000}]}`,
+		"blueprints.jsonl":    `{"_key":691,"blueprintTypeID":691,"activities":{"manufacturing":{"time":6000,"materials":[{"typeID":34,"quantity":32000},{"typeID":35,"quantity":6000}],"products":[{"typeID":587,"quantity":1}],"skills":[{"typeID":3380,"level":1}]},"copying":{"time":4800}}}`,
+		"mapRegions.jsonl":    `{"_key":10000002,"name":{"en":"The Forge"}}`,
+		"mapSolarSystems.jsonl": `{"_key":30000142,"name":{"en":"Jita"},"regionID":10000002,"constellationID":20000020,"securityStatus":0.945}
+{"_key":30000144,"name":{"en":"Perimeter"},"regionID":10000002,"constellationID":20000020,"securityStatus":0.9}`,
+		"mapStargates.jsonl": `{"_key":50001248,"solarSystemID":30000142,"destination":{"solarSystemID":30000144,"stargateID":50001249}}
+{"_key":50001249,"solarSystemID":30000144,"destination":{"solarSystemID":30000142,"stargateID":50001248}}`,
+		"npcStations.jsonl": `{"_key":60003760,"solarSystemID":30000142,"typeID":1529,"ownerID":1000035}`,
+		"dogmaAttributes.jsonl": `{"_key":4,"attributeID":4,"name":"mass","displayName":{"en":"Mass"},"unitID":2,"published":true,"highIsGood":true}
+{"_key":422,"attributeID":422,"name":"techLevel","displayName":{"en":"Tech Level"},"published":true,"highIsGood":true}`,
+		"dogmaUnits.jsonl": `{"_key":2,"name":"Kilogram","displayName":"kg","description":{"en":"Mass"}}`,
+		"typeDogma.jsonl":  `{"_key":587,"dogmaAttributes":[{"attributeID":4,"value":1067000},{"attributeID":422,"value":1}],"dogmaEffects":[]}`,
+	}
+}
+
+// writeArchive zips dataset files into a temporary archive below a directory like the official SDE
+func writeArchive(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+
+	archivePath := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(archivePath)
+	require.NoError(t, err)
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for fileName, content := range files {
+		entry, err := writer.Create("sde/" + fileName)
+		require.NoError(t, err)
+		_, err = entry.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	return archivePath
+}
+
+func readMetadata(t *testing.T, dbPath, key string) string {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var value string
+	require.NoError(t, db.QueryRow(`SELECT value FROM `+sdeimport.MetadataTable+` WHERE key = ?`, key).Scan(&value))
+	return value
+}
+
+func TestImportJSONLArchive(t *testing.T) {
+	// Arrange
+	archivePath := writeArchive(t, "eve-online-static-data-3064089-jsonl.zip", testArchiveFiles())
+	dbPath := filepath.Join(t.TempDir(), "sde.sqlite")
+
+	// Act
+	report, err := sdeimport.Import(sdeimport.Options{SourcePath: archivePath, DatabasePath: dbPath})
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, report.Skipped)
+	assert.Equal(t, int64(3064089), report.BuildNumber)
+	assert.Len(t, report.Checksum, 64)
+	assert.Equal(t, []int32{34, 35, 587, 691}, report.AddedTypes)
+	assert.Empty(t, report.RemovedTypes)
+	assert.Empty(t, report.ChangedTypes)
+	assert.Equal(t, 2, report.Rows["industryActivity"]) // Manufacturing and copying
+	assert.Equal(t, "3064089", readMetadata(t, dbPath, sdeimport.MetadataBuildNumber))
+	assert.Equal(t, report.Checksum, readMetadata(t, dbPath, sdeimport.MetadataChecksum))
+
+	// The repository can query the imported database
+	repo, err := repository.NewSDERepository(dbPath)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	item, err := repo.GetItemByID(587)
+	require.NoError(t, err)
+	assert.Equal(t, "Rifter", item.TypeName)
+	assert.Equal(t, "Frigate", item.GroupName)
+	assert.Equal(t, "Ship", item.CategoryName)
+
+	blueprint, err := repo.GetBlueprintByProduct(587)
+	require.NoError(t, err)
+	assert.Equal(t, int32(691), blueprint.BlueprintTypeID)
+	assert.Len(t, blueprint.Materials, 2)
+
+	materials, err := repo.GetTypeMaterials(587)
+	require.NoError(t, err)
+	assert.Len(t, materials.Materials, 2)
+
+	marketGroups, err := repo.GetMarketGroups()
+	require.NoError(t, err)
+	assert.Len(t, marketGroups, 3)
+
+	stations, err := repo.GetStationsBySystem(30000142)
+	require.NoError(t, err)
+	require.Len(t, stations, 1)
+	assert.Equal(t, int32(10000002), stations[0].RegionID)
+	assert.Equal(t, 1067000.0, item.Mass)
+
+	systems, err := repo.GetSolarSystems()
+	require.NoError(t, err)
+	require.Len(t, systems, 2)
+	assert.Equal(t, "Jita", systems[0].SolarSystemName)
+	jumps, err := repo.GetSolarSystemJumps()
+	require.NoError(t, err)
+	assert.Equal(t, []repository.SDESolarSystemJump{
+		{FromSolarSystemID: 30000142, ToSolarSystemID: 30000144},
+		{FromSolarSystemID: 30000144, ToSolarSystemID: 30000142},
+	}, jumps)
+	assert.Equal(t, "A fast frigate.", item.Description)
+
+	attributes, err := repo.GetTypeAttributes(587)
+	require.NoError(t, err)
+	require.Len(t, attributes, 2)
+	assert.Equal(t, "Mass", attributes[0].DisplayName)
+	assert.Equal(t, "kg", attributes[0].UnitName) // Plain strings are accepted for display names
+	assert.Equal(t, 1.0, attributes[1].Value)
+}
+
+func TestImportReportsTypeChanges(t *testing.T) {
+	// Arrange
+	dbPath := filepath.Join(t.TempDir(), "sde.sqlite")
+	_, err := sdeimport.Import(sdeimport.Options{
+		SourcePath:   writeArchive(t, "initial.zip", testArchiveFiles()),
+		DatabasePath: dbPath,
+	})
+	require.NoError(t, err)
+
+	files := testArchiveFiles()
+	files["_sde.jsonl"] = `{"_key":"sde","buildNumber":3070000}`
+	files["types.jsonl"] = `{"_key":34,"groupID":18,"name":{"en":"Tritanium"},"volume":0.01,"portionSize":1,"published":true,"marketGroupID":1857}
+{"_key":36,"groupID":18,"name":{"en":"Mexallon"},"volume":0.01,"portionSize":1,"published":true,"marketGroupID":1857}
+{"_key":587,"groupID":25,"name":{"en":"Rifter"},"description":{"en":"A rebalanced frigate."},"mass":1067000,"volume":27289,"portionSize":1,"published":true,"marketGroupID":64}
+{"_key":691,"groupID":105,"name":{"en":"Rifter Blueprint"},"volume":0.01,"portionSize":1,"published":true}`
+
+	// Act
+	report, err := sdeimport.Import(sdeimport.Options{
+		SourcePath:   writeArchive(t, "update.zip", files),
+		DatabasePath: dbPath,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(3070000), report.BuildNumber)
+	assert.Equal(t, []int32{36}, report.AddedTypes)
+	assert.Equal(t, []int32{35}, report.RemovedTypes)
+	assert.Equal(t, []int32{587}, report.ChangedTypes)
+	assert.Equal(t, 2, report.Rows["invTypes"]) // Unchanged types are not rewritten
+	assert.Equal(t, "3070000", readMetadata(t, dbPath, sdeimport.MetadataBuildNumber))
+
+	repo, err := repository.NewSDERepository(dbPath)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	_, err = repo.GetItemByID(35)
+	assert.ErrorIs(t, err, repository.ErrItemNotFound)
+}
+
+func TestImportMetaVariants(t *testing.T) {
+	// Arrange
+	files := testArchiveFiles()
+	files["types.jsonl"] += `
+{"_key":2873,"groupID":55,"name":{"en":"200mm AutoCannon I"},"volume":5,"portionSize":1,"published":true,"metaGroupID":1}
+{"_key":2881,"groupID":55,"name":{"en":"200mm AutoCannon II"},"volume":5,"portionSize":1,"published":true,"metaGroupID":2,"variationParentTypeID":2873}`
+	files["metaGroups.jsonl"] = `{"_key":1,"name":{"en":"Tech I"}}
+{"_key":2,"name":{"en":"Tech II"}}`
+	dbPath := filepath.Join(t.TempDir(), "sde.sqlite")
+
+	// Act
+	report, err := sdeimport.Import(sdeimport.Options{
+		SourcePath:   writeArchive(t, "variants.zip", files),
+		DatabasePath: dbPath,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Rows["invMetaTypes"]) // Only variants have a parent
+	assert.Equal(t, 2, report.Rows["invMetaGroups"])
+
+	repo, err := repository.NewSDERepository(dbPath)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	variants, err := repo.GetTypeVariants(2881)
+	require.NoError(t, err)
+	require.Len(t, variants, 2)
+	assert.Equal(t, "Tech I", variants[0].MetaGroupName)
+	assert.Equal(t, "Tech II", variants[1].MetaGroupName)
+}
+
+func TestImportSkipsAlreadyImportedArchive(t *testing.T) {
+	// Arrange
+	archivePath := writeArchive(t, "sde.zip", testArchiveFiles())
+	dbPath := filepath.Join(t.TempDir(), "sde.sqlite")
+	_, err := sdeimport.Import(sdeimport.Options{SourcePath: archivePath, DatabasePath: dbPath})
+	require.NoError(t, err)
+
+	// Act
+	skipped, err := sdeimport.Import(sdeimport.Options{SourcePath: archivePath, DatabasePath: dbPath})
+	require.NoError(t, err)
+	forced, err := sdeimport.Import(sdeimport.Options{SourcePath: archivePath, DatabasePath: dbPath, Force: true})
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, skipped.Skipped)
+	assert.False(t, forced.Skipped)
+	assert.Empty(t, forced.AddedTypes)
+	assert.Empty(t, forced.ChangedTypes)
+	assert.Equal(t, 1, forced.Rows["mapRegions"])
+}
+
+func TestImportYAMLArchive(t *testing.T) {
+	// Arrange
+	files := map[string]string{
+		"_sde.yaml": "buildNumber: 3064089\nreleaseDate: '2025-10-01'\n",
+		"types.yaml": `34:
+  groupID: 18
+  name:
+    en: Tritanium
+  volume: 0.01
+  portionSize: 1
+  published: true
+  marketGroupID: 1857
+`,
+		"groups.yaml":        "18:\n  categoryID: 4\n  name:\n    en: Mineral\n  published: true\n",
+		"categories.yaml":    "4:\n  name:\n    en: Material\n  published: true\n",
+		"marketGroups.yaml":  "1857:\n  name:\n    en: Minerals\n  hasTypes: true\n",
+		"typeMaterials.yaml": "",
+		"blueprints.yaml":    "",
+		"mapRegions.yaml":    "10000002:\n  name:\n    en: The Forge\n",
+	}
+	archivePath := writeArchive(t, "eve-online-static-data-3064089-yaml.zip", files)
+	dbPath := filepath.Join(t.TempDir(), "sde.sqlite")
+
+	// Act
+	report, err := sdeimport.Import(sdeimport.Options{SourcePath: archivePath, DatabasePath: dbPath})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(3064089), report.BuildNumber)
+	assert.Equal(t, []int32{34}, report.AddedTypes)
+
+	repo, err := repository.NewSDERepository(dbPath)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	item, err := repo.GetItemByID(34)
+	require.NoError(t, err)
+	assert.Equal(t, "Tritanium", item.TypeName)
+	assert.Equal(t, "Material", item.CategoryName)
+}
+
+func TestImportMissingDataset(t *testing.T) {
+	// Arrange
+	files := testArchiveFiles()
+	delete(files, "groups.jsonl")
+	archivePath := writeArchive(t, "sde.zip", files)
+	dbPath := filepath.Join(t.TempDir(), "sde.sqlite")
+
+	// Act
+	_, err := sdeimport.Import(sdeimport.Options{SourcePath: archivePath, DatabasePath: dbPath})
+
+	// Assert
+	assert.ErrorIs(t, err, sdeimport.ErrDatasetNotFound)
+
+	// The failed import must not leave partial data behind
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM invTypes`).Scan(&count))
+	assert.Zero(t, count)
+}
@@ -0,0 +1,196 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCharacterData serves fixed wallets, assets and orders per character
+type stubCharacterData struct {
+	wallets map[int32]float64
+	assets  map[int32][]models.CharacterAsset
+	orders  map[int32][]models.CharacterOrder
+	failing map[int32]bool
+}
+
+func (s *stubCharacterData) GetCharacterWallet(ctx context.Context, characterID int32) (*models.CharacterWallet, error) {
+	if s.failing[characterID] {
+		return nil, service.ErrLoginRequired
+	}
+	return &models.CharacterWallet{Balance: s.wallets[characterID]}, nil
+}
+
+func (s *stubCharacterData) GetCharacterAssets(ctx context.Context, characterID int32) ([]models.CharacterAsset, error) {
+	if s.failing[characterID] {
+		return nil, service.ErrLoginRequired
+	}
+	return s.assets[characterID], nil
+}
+
+func (s *stubCharacterData) GetCharacterOrders(ctx context.Context, characterID int32) ([]models.CharacterOrder, error) {
+	if s.failing[characterID] {
+		return nil, service.ErrLoginRequired
+	}
+	return s.orders[characterID], nil
+}
+
+func newTestAccountService(t *testing.T) *service.AccountService {
+	t.Helper()
+	db, err := repository.OpenAppDatabase(filepath.Join(t.TempDir(), "app.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := repository.NewAccountStore(db)
+	require.NoError(t, err)
+	return service.NewAccountService(store)
+}
+
+// linkAccount logs in a main and links the alts to it
+func linkAccount(t *testing.T, accounts *service.AccountService, mainID int32, altIDs ...int32) {
+	t.Helper()
+	_, err := accounts.LinkLogin(&models.AuthToken{CharacterID: mainID, CharacterName: "Main"}, 0)
+	require.NoError(t, err)
+	for _, altID := range altIDs {
+		_, err := accounts.LinkLogin(&models.AuthToken{CharacterID: altID, CharacterName: "Alt"}, mainID)
+		require.NoError(t, err)
+	}
+}
+
+func TestAccountServiceLinkLogin(t *testing.T) {
+	// Arrange
+	accounts := newTestAccountService(t)
+
+	// Act
+	linkAccount(t, accounts, 90000001, 90000002)
+	account, err := accounts.GetAccount(90000002)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, account.Characters, 2)
+	assert.Equal(t, int32(90000001), account.ActiveCharacterID)
+
+	// Logging in again keeps the account
+	again, err := accounts.LinkLogin(&models.AuthToken{CharacterID: 90000002, CharacterName: "Renamed Alt"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, account.AccountID, again.AccountID)
+	assert.Equal(t, "Renamed Alt", again.Characters[1].CharacterName)
+}
+
+func TestAccountServiceCanAccessCharacter(t *testing.T) {
+	accounts := newTestAccountService(t)
+	linkAccount(t, accounts, 90000001, 90000002)
+	linkAccount(t, accounts, 90000010)
+
+	tests := []struct {
+		name            string
+		authCharacterID int32
+		characterID     int32
+		expected        bool
+	}{
+		{name: "should allow own character", authCharacterID: 90000001, characterID: 90000001, expected: true},
+		{name: "should allow linked alt", authCharacterID: 90000001, characterID: 90000002, expected: true},
+		{name: "should allow main from alt", authCharacterID: 90000002, characterID: 90000001, expected: true},
+		{name: "should deny foreign character", authCharacterID: 90000001, characterID: 90000010, expected: false},
+		{name: "should deny unknown caller", authCharacterID: 12345, characterID: 90000001, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			allowed, err := accounts.CanAccessCharacter(tt.authCharacterID, tt.characterID)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+}
+
+func TestAccountServiceSwitchAndUnlink(t *testing.T) {
+	// Arrange
+	accounts := newTestAccountService(t)
+	linkAccount(t, accounts, 90000001, 90000002, 90000003)
+	linkAccount(t, accounts, 90000010)
+
+	// Act
+	account, err := accounts.SetActiveCharacter(90000001, 90000003)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(90000003), account.ActiveCharacterID)
+
+	_, err = accounts.SetActiveCharacter(90000001, 90000010)
+	assert.ErrorIs(t, err, service.ErrCharacterNotLinked)
+
+	_, err = accounts.UnlinkCharacter(90000001, 90000001)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	account, err = accounts.UnlinkCharacter(90000001, 90000003)
+	require.NoError(t, err)
+	assert.Len(t, account.Characters, 2)
+	assert.Equal(t, int32(90000001), account.ActiveCharacterID)
+}
+
+func TestAccountServiceAggregates(t *testing.T) {
+	// Arrange: Main in Jita, alt in Amarr with a revoked third character
+	accounts := newTestAccountService(t)
+	linkAccount(t, accounts, 90000001, 90000002, 90000003)
+	accounts.WithCharacterData(&stubCharacterData{
+		wallets: map[int32]float64{90000001: 1_000_000, 90000002: 250_000},
+		assets: map[int32][]models.CharacterAsset{
+			90000001: {{TypeID: 34, Quantity: 1000}, {TypeID: 34, Quantity: 500}, {TypeID: 587, Quantity: 1}},
+			90000002: {{TypeID: 34, Quantity: 200}},
+		},
+		orders: map[int32][]models.CharacterOrder{
+			90000001: {{OrderID: 1, TypeID: 34, IsBuyOrder: true, Escrow: 5000}},
+			90000002: {{OrderID: 2, TypeID: 34, Price: 6, VolumeRemain: 100}},
+		},
+		failing: map[int32]bool{90000003: true},
+	})
+
+	// Act
+	wallet, err := accounts.GetAccountWallet(context.Background(), 90000002)
+	require.NoError(t, err)
+	assets, err := accounts.GetAccountAssets(context.Background(), 90000002)
+	require.NoError(t, err)
+	orders, err := accounts.GetAccountOrders(context.Background(), 90000002)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 1_250_000.0, wallet.TotalBalance)
+	assert.Len(t, wallet.Characters, 2)
+	require.Len(t, wallet.Errors, 1)
+	assert.Equal(t, int32(90000003), wallet.Errors[0].CharacterID)
+
+	require.Len(t, assets.Types, 2)
+	assert.Equal(t, int32(34), assets.Types[0].TypeID)
+	assert.Equal(t, int64(1700), assets.Types[0].Quantity)
+	assert.Len(t, assets.Types[0].Characters, 2)
+
+	assert.Len(t, orders.Orders, 2)
+	assert.Equal(t, 1, orders.BuyOrders)
+	assert.Equal(t, 1, orders.SellOrders)
+	assert.Equal(t, 5000.0, orders.Escrow)
+	assert.Equal(t, 600.0, orders.SellValue)
+}
+
+func TestAccountServiceWithoutCharacterData(t *testing.T) {
+	// Arrange
+	accounts := newTestAccountService(t)
+	linkAccount(t, accounts, 90000001)
+
+	// Act
+	_, err := accounts.GetAccountWallet(context.Background(), 90000001)
+
+	// Assert
+	assert.True(t, errors.Is(err, service.ErrCharacterDataUnavailable))
+}
@@ -0,0 +1,255 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink stands in for a notification channel
+type recordingSink struct {
+	alerts  []models.AlertNotification
+	failing bool
+}
+
+func (s *recordingSink) SendAlert(ctx context.Context, alert models.AlertNotification) error {
+	if s.failing {
+		return errors.New("receiver unavailable")
+	}
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+// fixedUndercuts reports fixed undercut checks
+type fixedUndercuts struct {
+	orders []models.OrderUndercut
+}
+
+func (f *fixedUndercuts) CheckCharacters(ctx context.Context, characterIDs []int32, all bool) (*service.UndercutReport, error) {
+	return &service.UndercutReport{Orders: f.orders}, nil
+}
+
+func newTestAlertEngine(t *testing.T, market service.MarketDataProvider) (*service.AlertEngine, *recordingSink) {
+	t.Helper()
+	db, err := repository.OpenAppDatabase(filepath.Join(t.TempDir(), "app.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	store, err := repository.NewAlertStore(db)
+	require.NoError(t, err)
+
+	sink := &recordingSink{}
+	engine := service.NewAlertEngine(store, market).WithSink(models.AlertChannelWebhook, sink)
+	return engine, sink
+}
+
+func sellOrders(prices ...float64) map[int32]map[int32][]models.MarketOrder {
+	orders := []models.MarketOrder{{OrderID: 100, LocationID: amarrStation, Price: 1, VolumeRemain: 10}} // Outside Jita
+	for i, price := range prices {
+		orders = append(orders, models.MarketOrder{OrderID: int64(i + 1), LocationID: jitaStation, Price: price, VolumeRemain: 10})
+	}
+	return map[int32]map[int32][]models.MarketOrder{service.RegionTheForge: {34: orders}}
+}
+
+func TestAlertEngineNotifiesOnceUntilConditionClears(t *testing.T) {
+	// Arrange
+	market := &snapshotMarketData{orders: sellOrders(4.5, 6)}
+	engine, sink := newTestAlertEngine(t, market)
+	_, err := engine.CreateRule(90000001, models.AlertRule{TypeID: 34, Expression: "Jita sell < 5", Enabled: true})
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	first, err := engine.Evaluate(context.Background(), now)
+	require.NoError(t, err)
+	repeated, err := engine.Evaluate(context.Background(), now.Add(5*time.Minute))
+	require.NoError(t, err)
+	market.orders = sellOrders(6)
+	_, err = engine.Evaluate(context.Background(), now.Add(10*time.Minute))
+	require.NoError(t, err)
+	market.orders = sellOrders(4.8)
+	coolingDown, err := engine.Evaluate(context.Background(), now.Add(15*time.Minute))
+	require.NoError(t, err)
+	afterCooldown, err := engine.Evaluate(context.Background(), now.Add(time.Hour))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 1, first.Triggered)
+	assert.Equal(t, 0, repeated.Triggered)
+	assert.Equal(t, 0, coolingDown.Triggered)
+	assert.Equal(t, 1, afterCooldown.Triggered)
+	require.Len(t, sink.alerts, 2)
+	assert.InDelta(t, 4.5, sink.alerts[0].Value, 0.0001)
+	assert.InDelta(t, 5, sink.alerts[0].Threshold, 0.0001)
+	assert.Equal(t, "Jita type 34 sell is 4.50 ISK (< 5.00 ISK)", sink.alerts[0].Message)
+	assert.InDelta(t, 4.8, sink.alerts[1].Value, 0.0001)
+
+	rules, err := engine.ListRules(90000001)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.True(t, rules[0].Triggered)
+	assert.True(t, rules[0].LastTriggeredAt.Equal(now.Add(time.Hour)))
+}
+
+func TestAlertEngineComparesAgainstHistoryAverage(t *testing.T) {
+	// Arrange
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	var history []models.MarketHistory
+	for i := 0; i < 40; i++ {
+		history = append(history, models.MarketHistory{Date: day.AddDate(0, 0, i), Average: 5, Volume: 100})
+	}
+	history[0].Volume = 10000 // Older than the averaged 30 days
+	history[len(history)-1].Volume = 400
+	market := &snapshotMarketData{
+		orders:  sellOrders(5),
+		history: map[int32]map[int32][]models.MarketHistory{service.RegionTheForge: {34: history, 35: history}},
+	}
+	engine, sink := newTestAlertEngine(t, market)
+	_, err := engine.CreateRule(90000001, models.AlertRule{TypeID: 34, Expression: "volume 3x above 30-day average", Enabled: true})
+	require.NoError(t, err)
+	_, err = engine.CreateRule(90000001, models.AlertRule{TypeID: 35, Expression: "volume > 5x average", Enabled: true})
+	require.NoError(t, err)
+
+	// Act
+	result, err := engine.Evaluate(context.Background(), time.Now())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Evaluated)
+	assert.Len(t, market.requests, 1) // One fetch for all rules of a region
+	require.Len(t, sink.alerts, 1)
+	assert.Equal(t, int32(34), sink.alerts[0].TypeID)
+	assert.InDelta(t, 400, sink.alerts[0].Value, 0.0001)
+	assert.InDelta(t, 300, sink.alerts[0].Threshold, 0.0001)
+}
+
+func TestAlertEngineNotifiesNewUndercutsOnly(t *testing.T) {
+	// Arrange
+	undercuts := &fixedUndercuts{orders: []models.OrderUndercut{
+		{CharacterID: 90000001, OrderID: 1, TypeID: 34, Status: models.OrderStatusUndercut, Price: 5, CompetitorPrice: 4.9},
+		{CharacterID: 90000001, OrderID: 2, TypeID: 35, Status: models.OrderStatusUndercut, Price: 9, CompetitorPrice: 8},
+		{CharacterID: 90000002, OrderID: 3, TypeID: 34, Status: models.OrderStatusOutbid, Price: 4, CompetitorPrice: 4.1},
+	}}
+	engine, sink := newTestAlertEngine(t, &snapshotMarketData{})
+	engine.WithUndercuts(undercuts)
+	_, err := engine.CreateRule(90000001, models.AlertRule{TypeID: 34, Expression: "my order undercut", CooldownSeconds: 60, Enabled: true})
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	_, err = engine.Evaluate(context.Background(), now)
+	require.NoError(t, err)
+	_, err = engine.Evaluate(context.Background(), now.Add(2*time.Minute)) // Same competitor
+	require.NoError(t, err)
+	undercuts.orders[0].CompetitorPrice = 4.8
+	_, err = engine.Evaluate(context.Background(), now.Add(4*time.Minute))
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, sink.alerts, 2)
+	require.Len(t, sink.alerts[0].Orders, 1)
+	assert.Equal(t, int64(1), sink.alerts[0].Orders[0].OrderID)
+	assert.Equal(t, "1 of your type 34 orders are undercut or outbid", sink.alerts[0].Message)
+	assert.InDelta(t, 4.8, sink.alerts[1].Orders[0].CompetitorPrice, 0.0001)
+}
+
+func TestAlertEngineRetriesFailedDeliveries(t *testing.T) {
+	// Arrange
+	engine, sink := newTestAlertEngine(t, &snapshotMarketData{orders: sellOrders(4.5)})
+	rule, err := engine.CreateRule(90000001, models.AlertRule{TypeID: 34, Expression: "sell < 5", Enabled: true})
+	require.NoError(t, err)
+	sink.failing = true
+	now := time.Now()
+
+	// Act
+	failed, err := engine.Evaluate(context.Background(), now)
+	require.NoError(t, err)
+	sink.failing = false
+	retried, err := engine.Evaluate(context.Background(), now.Add(time.Minute))
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, failed.Errors, 1)
+	assert.Equal(t, rule.RuleID, failed.Errors[0].RuleID)
+	assert.Equal(t, 0, failed.Triggered)
+	assert.Equal(t, 1, retried.Triggered)
+	assert.Len(t, sink.alerts, 1)
+}
+
+func TestAlertEngineSkipsOnlyRulesOfFailingTypes(t *testing.T) {
+	// Arrange: Market data of Pyerite fails, which fails every request containing it
+	market := &fakeOrderBookMarket{
+		books:   map[int32][]models.MarketOrder{34: sellOrders(4.5)[service.RegionTheForge][34]},
+		failing: map[int32]bool{35: true},
+	}
+	engine, sink := newTestAlertEngine(t, market)
+	_, err := engine.CreateRule(90000001, models.AlertRule{TypeID: 34, Expression: "sell < 5", Enabled: true})
+	require.NoError(t, err)
+	failing, err := engine.CreateRule(90000002, models.AlertRule{TypeID: 35, Expression: "sell < 5", Enabled: true})
+	require.NoError(t, err)
+
+	// Act
+	result, err := engine.Evaluate(context.Background(), time.Now())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Evaluated)
+	assert.Equal(t, 1, result.Triggered)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, failing.RuleID, result.Errors[0].RuleID)
+	assert.Len(t, sink.alerts, 1)
+}
+
+func TestAlertEngineRejectsTypesWithoutMarket(t *testing.T) {
+	// Arrange
+	engine, _ := newTestAlertEngine(t, &snapshotMarketData{})
+	engine.WithItems(&fakeItems{items: map[int32]models.Item{
+		34: {TypeID: 34, TypeName: "Tritanium", MarketGroup: 1857},
+		23: {TypeID: 23, TypeName: "Cargo Container"}, // Not on the market
+	}})
+
+	// Act
+	_, unknownErr := engine.CreateRule(90000001, models.AlertRule{TypeID: 99999, Expression: "sell < 5"})
+	_, unmarketableErr := engine.CreateRule(90000001, models.AlertRule{TypeID: 23, Expression: "sell < 5"})
+	rule, err := engine.CreateRule(90000001, models.AlertRule{TypeID: 34, Expression: "sell < 5"})
+	require.NoError(t, err)
+	_, updateErr := engine.UpdateRule(90000001, rule.RuleID, models.AlertRule{TypeID: 23, Expression: "sell < 5"})
+
+	// Assert
+	assert.ErrorIs(t, unknownErr, service.ErrInvalidInput)
+	assert.ErrorIs(t, unmarketableErr, service.ErrInvalidInput)
+	assert.ErrorIs(t, updateErr, service.ErrInvalidInput)
+}
+
+func TestAlertEngineValidatesRules(t *testing.T) {
+	engine, _ := newTestAlertEngine(t, &snapshotMarketData{})
+
+	tests := []struct {
+		name string
+		rule models.AlertRule
+	}{
+		{name: "should reject invalid expressions", rule: models.AlertRule{TypeID: 34, Expression: "sell"}},
+		{name: "should require a type for market rules", rule: models.AlertRule{Expression: "sell < 5"}},
+		{name: "should reject unconfigured channels", rule: models.AlertRule{TypeID: 34, Expression: "sell < 5", Channels: []string{"discord"}}},
+		{name: "should reject invalid email addresses", rule: models.AlertRule{TypeID: 34, Expression: "sell < 5", Email: "pilot"}},
+		{name: "should reject negative cooldowns", rule: models.AlertRule{TypeID: 34, Expression: "sell < 5", CooldownSeconds: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := engine.CreateRule(90000001, tt.rule)
+
+			// Assert
+			assert.ErrorIs(t, err, service.ErrInvalidInput)
+		})
+	}
+}
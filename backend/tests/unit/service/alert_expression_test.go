@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlertExpression(t *testing.T) {
+	jita := models.AlertCondition{Hub: "Jita", RegionID: service.RegionTheForge, StationID: jitaStation}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   models.AlertCondition
+	}{
+		{
+			name:       "should parse a hub price threshold",
+			expression: "Amarr buy >= 1.5m",
+			expected:   models.AlertCondition{Hub: "Amarr", RegionID: service.RegionDomain, StationID: amarrStation, Metric: "buy", Operator: ">=", Threshold: 1.5e6},
+		},
+		{
+			name:       "should default to Jita without spaces around the operator",
+			expression: "SELL<5",
+			expected:   withMetric(jita, "sell", "<", 5),
+		},
+		{
+			name:       "should parse spreads in percent",
+			expression: "spread > 15%",
+			expected:   withMetric(jita, "spread", ">", 15),
+		},
+		{
+			name:       "should parse multiples of the history average",
+			expression: "volume 3x above 30-day average",
+			expected:   withAverage(withMetric(jita, "volume", ">", 0), 3, 30),
+		},
+		{
+			name:       "should parse averages with an operator and custom days",
+			expression: "jita sell < 0.8x 7d avg",
+			expected:   withAverage(withMetric(jita, "sell", "<", 0), 0.8, 7),
+		},
+		{
+			name:       "should parse undercut watches",
+			expression: "my order undercut",
+			expected:   models.AlertCondition{Metric: models.AlertMetricUndercut},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			condition, err := service.ParseAlertExpression(tt.expression)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *condition)
+		})
+	}
+}
+
+func TestParseAlertExpressionRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "should reject empty expressions", expression: "  "},
+		{name: "should reject unknown metrics", expression: "Jita margin > 5"},
+		{name: "should reject missing operators", expression: "sell 5"},
+		{name: "should reject missing values", expression: "sell <"},
+		{name: "should reject percentages of prices", expression: "sell < 5%"},
+		{name: "should reject spread averages", expression: "spread > 2x average"},
+		{name: "should reject contradicting operators", expression: "volume < 3x above average"},
+		{name: "should reject trailing words", expression: "sell < 5 please"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := service.ParseAlertExpression(tt.expression)
+
+			// Assert
+			assert.ErrorIs(t, err, service.ErrInvalidInput)
+		})
+	}
+}
+
+func withMetric(condition models.AlertCondition, metric, operator string, threshold float64) models.AlertCondition {
+	condition.Metric, condition.Operator, condition.Threshold = metric, operator, threshold
+	return condition
+}
+
+func withAverage(condition models.AlertCondition, multiplier float64, days int) models.AlertCondition {
+	condition.Multiplier, condition.AverageDays = multiplier, days
+	return condition
+}
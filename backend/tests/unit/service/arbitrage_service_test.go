@@ -0,0 +1,207 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRegionOrderClient implements the service.RegionOrderClient interface for testing
+type MockRegionOrderClient struct {
+	mock.Mock
+}
+
+func (m *MockRegionOrderClient) GetRegionOrders(ctx context.Context, regionID int32) ([]models.MarketOrder, error) {
+	args := m.Called(ctx, regionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.MarketOrder), args.Error(1)
+}
+
+var (
+	testJitaHub  = service.MarketHub{Name: "Jita", RegionID: service.RegionTheForge, StationID: 60003760}
+	testAmarrHub = service.MarketHub{Name: "Amarr", RegionID: service.RegionDomain, StationID: 60008494}
+)
+
+func testOrder(orderID int64, typeID int32, locationID int64, price float64, volume int32, isBuy bool) models.MarketOrder {
+	return models.MarketOrder{
+		OrderID:      orderID,
+		TypeID:       typeID,
+		LocationID:   locationID,
+		Price:        price,
+		VolumeTotal:  volume,
+		VolumeRemain: volume,
+		MinVolume:    1,
+		IsBuyOrder:   isBuy,
+	}
+}
+
+func TestArbitrageServiceScanFindsProfitableOpportunities(t *testing.T) {
+	// Arrange: Tritanium is cheap in Jita and bought high in Amarr
+	mockClient := new(MockRegionOrderClient)
+	mockClient.On("GetRegionOrders", mock.Anything, service.RegionTheForge).Return([]models.MarketOrder{
+		testOrder(1, 34, 60003760, 4.0, 100, false),
+		testOrder(2, 34, 60003760, 5.0, 100, false),
+		testOrder(3, 34, 60099999, 1.0, 100, false), // Outside the hub station
+	}, nil)
+	mockClient.On("GetRegionOrders", mock.Anything, service.RegionDomain).Return([]models.MarketOrder{
+		testOrder(4, 34, 60008494, 10.0, 150, true),
+		testOrder(5, 34, 60008494, 4.5, 1000, true), // Unprofitable after tax
+	}, nil)
+
+	arbitrageService := service.NewArbitrageService(mockClient, []service.MarketHub{testJitaHub, testAmarrHub}, 0.1)
+
+	// Act
+	scan, err := arbitrageService.Scan(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, scan.Opportunities, 1)
+	assert.Equal(t, 5, scan.OrderCount)
+
+	opportunity := scan.Opportunities[0]
+	assert.Equal(t, int32(34), opportunity.TypeID)
+	assert.Equal(t, "Jita", opportunity.From.Name)
+	assert.Equal(t, "Amarr", opportunity.To.Name)
+	assert.Equal(t, int64(150), opportunity.Quantity)
+	assert.InDelta(t, 650.0, opportunity.Investment, 0.001) // 100*4 + 50*5
+	assert.InDelta(t, 1350.0, opportunity.Revenue, 0.001)   // 150*10*0.9
+	assert.InDelta(t, 700.0, opportunity.Profit, 0.001)
+	assert.Equal(t, 4.0, opportunity.BuyPrice)
+	assert.Equal(t, 10.0, opportunity.SellPrice)
+	mockClient.AssertExpectations(t)
+}
+
+func TestArbitrageServiceGetOpportunitiesPagesByProfit(t *testing.T) {
+	// Arrange: three types with different profits from Jita to Amarr
+	mockClient := new(MockRegionOrderClient)
+	mockClient.On("GetRegionOrders", mock.Anything, service.RegionTheForge).Return([]models.MarketOrder{
+		testOrder(1, 34, 60003760, 1.0, 10, false),
+		testOrder(2, 35, 60003760, 1.0, 10, false),
+		testOrder(3, 36, 60003760, 1.0, 10, false),
+	}, nil)
+	mockClient.On("GetRegionOrders", mock.Anything, service.RegionDomain).Return([]models.MarketOrder{
+		testOrder(4, 34, 60008494, 2.0, 10, true),
+		testOrder(5, 35, 60008494, 4.0, 10, true),
+		testOrder(6, 36, 60008494, 3.0, 10, true),
+	}, nil)
+
+	arbitrageService := service.NewArbitrageService(mockClient, []service.MarketHub{testJitaHub, testAmarrHub}, 0)
+	_, err := arbitrageService.Scan(context.Background())
+	require.NoError(t, err)
+
+	// Act
+	firstPage, err := arbitrageService.GetOpportunities(1, 2, 0)
+	require.NoError(t, err)
+	secondPage, err := arbitrageService.GetOpportunities(2, 2, 0)
+	require.NoError(t, err)
+	filtered, err := arbitrageService.GetOpportunities(1, 10, 15)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 3, firstPage.Total)
+	require.Len(t, firstPage.Opportunities, 2)
+	assert.Equal(t, int32(35), firstPage.Opportunities[0].TypeID)
+	assert.Equal(t, int32(36), firstPage.Opportunities[1].TypeID)
+	require.Len(t, secondPage.Opportunities, 1)
+	assert.Equal(t, int32(34), secondPage.Opportunities[0].TypeID)
+	assert.Equal(t, 2, filtered.Total)
+}
+
+func TestArbitrageServiceGetOpportunitiesWithoutScan(t *testing.T) {
+	// Arrange
+	arbitrageService := service.NewArbitrageService(new(MockRegionOrderClient), nil, service.DefaultSalesTax)
+
+	// Act
+	page, err := arbitrageService.GetOpportunities(1, 10, 0)
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrNoScanAvailable)
+	assert.Nil(t, page)
+}
+
+func TestArbitrageServiceScanPropagatesClientErrors(t *testing.T) {
+	// Arrange
+	mockClient := new(MockRegionOrderClient)
+	mockClient.On("GetRegionOrders", mock.Anything, service.RegionTheForge).Return([]models.MarketOrder{}, nil)
+	mockClient.On("GetRegionOrders", mock.Anything, service.RegionDomain).Return(nil, assert.AnError)
+
+	arbitrageService := service.NewArbitrageService(mockClient, []service.MarketHub{testJitaHub, testAmarrHub}, 0)
+
+	// Act
+	scan, err := arbitrageService.Scan(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, scan)
+	assert.False(t, arbitrageService.IsScanning())
+}
+
+// blockingRegionOrders holds every request until it is released or cancelled
+type blockingRegionOrders struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingRegionOrders() *blockingRegionOrders {
+	return &blockingRegionOrders{started: make(chan struct{}, 8), release: make(chan struct{})}
+}
+
+func (b *blockingRegionOrders) GetRegionOrders(ctx context.Context, regionID int32) ([]models.MarketOrder, error) {
+	b.started <- struct{}{}
+	select {
+	case <-b.release:
+		return []models.MarketOrder{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestArbitrageServiceTriggerScanClaimsTheScan(t *testing.T) {
+	// Arrange
+	client := newBlockingRegionOrders()
+	arbitrageService := service.NewArbitrageService(client, []service.MarketHub{testJitaHub}, 0)
+
+	// Act
+	started := arbitrageService.TriggerScan()
+	scanning := arbitrageService.IsScanning()
+	again := arbitrageService.TriggerScan()
+	close(client.release)
+
+	// Assert
+	assert.True(t, started)
+	assert.True(t, scanning) // Claimed before TriggerScan returns
+	assert.False(t, again)
+	assert.Eventually(t, func() bool {
+		_, err := arbitrageService.GetOpportunities(1, 10, 0)
+		return err == nil && !arbitrageService.IsScanning()
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestArbitrageServiceTriggerScanStopsOnShutdown(t *testing.T) {
+	// Arrange
+	client := newBlockingRegionOrders()
+	arbitrageService := service.NewArbitrageService(client, []service.MarketHub{testJitaHub}, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	arbitrageService.Start(ctx, time.Hour)
+	<-client.started
+	client.release <- struct{}{} // Completes the initial scan of Start
+	require.Eventually(t, func() bool { return !arbitrageService.IsScanning() }, time.Second, 5*time.Millisecond)
+
+	// Act
+	require.True(t, arbitrageService.TriggerScan())
+	<-client.started
+	cancel()
+
+	// Assert
+	assert.Eventually(t, func() bool { return !arbitrageService.IsScanning() }, time.Second, 5*time.Millisecond)
+}
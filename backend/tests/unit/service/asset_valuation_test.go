@@ -0,0 +1,275 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStructureID int64 = 1035466617946
+
+// fakeAveragePrices serves fixed ESI average prices
+type fakeAveragePrices struct {
+	prices []models.MarketPrice
+}
+
+func (f *fakeAveragePrices) GetMarketPrices(ctx context.Context) ([]models.MarketPrice, error) {
+	return f.prices, nil
+}
+
+// fakeItems resolves a fixed set of types
+type fakeItems struct {
+	items map[int32]models.Item
+}
+
+func (f *fakeItems) GetItemByID(typeID int32) (*models.Item, error) {
+	item, ok := f.items[typeID]
+	if !ok {
+		return nil, service.ErrItemNotFound
+	}
+	return &item, nil
+}
+
+// fakeOrderBookMarket serves order books as market data. Like MarketService it
+// fails whole requests containing a failing type.
+type fakeOrderBookMarket struct {
+	books   map[int32][]models.MarketOrder
+	failing map[int32]bool
+}
+
+func (f *fakeOrderBookMarket) GetMarketData(ctx context.Context, req service.MarketDataRequest) (*service.MarketDataResponse, error) {
+	response := &service.MarketDataResponse{RegionID: req.RegionID, Orders: make(map[int32][]models.MarketOrder)}
+	for _, typeID := range req.TypeIDs {
+		if f.failing[typeID] {
+			return nil, errors.New("ESI unavailable")
+		}
+		response.Orders[typeID] = f.books[typeID]
+	}
+	return response, nil
+}
+
+// newTestAssetValuationService sets up a fitted Rifter in Jita carrying a
+// container with Tritanium, Pyerite in a structure and an unknown type in space
+func newTestAssetValuationService(failingTypes ...int32) *service.AssetValuationService {
+	characterData := &stubCharacterData{
+		assets: map[int32][]models.CharacterAsset{
+			90000001: {
+				{ItemID: 1, TypeID: 587, LocationID: jitaStation, LocationType: "station", LocationFlag: "Hangar", Quantity: 1, IsSingleton: true},
+				{ItemID: 2, TypeID: 3831, LocationID: 1, LocationType: "item", LocationFlag: "HiSlot0", Quantity: 1, IsSingleton: true},
+				{ItemID: 3, TypeID: 3297, LocationID: 1, LocationType: "item", LocationFlag: "Cargo", Quantity: 1, IsSingleton: true},
+				{ItemID: 4, TypeID: 34, LocationID: 3, LocationType: "item", LocationFlag: "Unlocked", Quantity: 1000},
+				{ItemID: 5, TypeID: 35, LocationID: testStructureID, LocationType: "item", LocationFlag: "Hangar", Quantity: 100},
+				{ItemID: 6, TypeID: 99999, LocationID: int64(jitaSystem), LocationType: "solar_system", LocationFlag: "AutoFit", Quantity: 1},
+			},
+		},
+		failing: map[int32]bool{90000002: true},
+	}
+	market := &fakeOrderBookMarket{failing: make(map[int32]bool), books: map[int32][]models.MarketOrder{
+		587: {
+			{OrderID: 1, TypeID: 587, LocationID: jitaStation, Price: 500000, VolumeRemain: 5},
+			{OrderID: 2, TypeID: 587, LocationID: 60000001, Price: 400000, VolumeRemain: 5},
+			{OrderID: 3, TypeID: 587, LocationID: jitaStation, Price: 450000, VolumeRemain: 5, IsBuyOrder: true},
+		},
+		3831: {
+			{OrderID: 4, TypeID: 3831, LocationID: jitaStation, Price: 1000, VolumeRemain: 20},
+		},
+		3297: {
+			{OrderID: 5, TypeID: 3297, LocationID: jitaStation, Price: 100, VolumeRemain: 20, IsBuyOrder: true},
+		},
+		34: {
+			{OrderID: 6, TypeID: 34, LocationID: jitaStation, Price: 6, VolumeRemain: 900},
+			{OrderID: 7, TypeID: 34, LocationID: jitaStation, Price: 4, VolumeRemain: 10},
+			{OrderID: 8, TypeID: 34, LocationID: jitaStation, Price: 5, VolumeRemain: 90},
+		},
+		35: {
+			{OrderID: 9, TypeID: 35, LocationID: jitaStation, Price: 10, VolumeRemain: 1000},
+		},
+	}}
+	for _, typeID := range failingTypes {
+		market.failing[typeID] = true
+	}
+	prices := &fakeAveragePrices{prices: []models.MarketPrice{{TypeID: 34, AveragePrice: 5.5}, {TypeID: 587, AveragePrice: 480000}}}
+	items := &fakeItems{items: map[int32]models.Item{
+		587:  {TypeID: 587, TypeName: "Rifter", CategoryID: 6, CategoryName: "Ship"},
+		3831: {TypeID: 3831, TypeName: "Medium Shield Extender I", CategoryID: 7, CategoryName: "Module"},
+		3297: {TypeID: 3297, TypeName: "Small Secure Container", CategoryID: 2, CategoryName: "Celestial"},
+		34:   {TypeID: 34, TypeName: "Tritanium", CategoryID: 4, CategoryName: "Material"},
+		35:   {TypeID: 35, TypeName: "Pyerite", CategoryID: 4, CategoryName: "Material"},
+	}}
+	return service.NewAssetValuationService(characterData, market, prices).WithItems(items)
+}
+
+func TestAssetValuationResolvesLocationsAndContainers(t *testing.T) {
+	// Arrange
+	valuation := newTestAssetValuationService()
+
+	// Act
+	report, err := valuation.ValueCharacters(context.Background(), []int32{90000001}, service.ValuationOptions{TopItems: 2})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, service.PriceTypeSell, report.Source)
+	assert.Equal(t, "Jita", report.Hub.Name)
+	assert.InDelta(t, 506000, report.TotalValue, 0.001)
+	assert.Equal(t, 6, report.Stacks)
+	assert.Equal(t, 4, report.PricedStacks)
+
+	// Items in the ship and the container count towards the station
+	require.Len(t, report.ByLocation, 3)
+	assert.Equal(t, models.LocationValue{LocationID: jitaStation, LocationType: models.AssetLocationStation, Value: 505000, Stacks: 4, Unpriced: 1}, report.ByLocation[0])
+	assert.Equal(t, models.LocationValue{LocationID: testStructureID, LocationType: models.AssetLocationStructure, Value: 1000, Stacks: 1}, report.ByLocation[1])
+	assert.Equal(t, models.AssetLocationSolarSystem, report.ByLocation[2].LocationType)
+
+	require.Len(t, report.Containers, 2)
+	ship := report.Containers[0]
+	assert.Equal(t, int32(587), ship.TypeID)
+	assert.InDelta(t, 500000, ship.OwnValue, 0.001)
+	assert.InDelta(t, 5000, ship.ContentValue, 0.001)
+	assert.Equal(t, 3, ship.Items)
+	assert.Equal(t, int64(3), report.Containers[1].ItemID)
+	assert.InDelta(t, 4000, report.Containers[1].Value, 0.001)
+
+	require.Len(t, report.ByCategory, 3)
+	assert.Equal(t, "Ship", report.ByCategory[0].CategoryName)
+	assert.Equal(t, models.CategoryValue{CategoryID: 4, CategoryName: "Material", Value: 5000, Quantity: 1100}, report.ByCategory[1])
+
+	assert.Equal(t, []models.CharacterValue{{CharacterID: 90000001, Value: 506000, Stacks: 6}}, report.ByCharacter)
+
+	require.Len(t, report.TopItems, 2)
+	assert.Equal(t, int32(587), report.TopItems[0].TypeID)
+	assert.Equal(t, int32(34), report.TopItems[1].TypeID)
+	assert.InDelta(t, 4, report.TopItems[1].UnitPrice, 0.0001)
+
+	require.Len(t, report.Unpriceable, 2)
+	assert.Equal(t, int32(3297), report.Unpriceable[0].TypeID)
+	assert.Equal(t, int32(99999), report.Unpriceable[1].TypeID)
+}
+
+func TestAssetValuationPriceSources(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        string
+		typeID        int32
+		expectedPrice float64
+	}{
+		{name: "should use the highest hub buy order", source: service.PriceTypeBuy, typeID: 587, expectedPrice: 450000},
+		{name: "should average the cheapest sell volume", source: service.PriceTypePercentile, typeID: 34, expectedPrice: 4.8},
+		{name: "should use ESI average prices", source: service.PriceTypeAverage, typeID: 34, expectedPrice: 5.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			valuation := newTestAssetValuationService()
+
+			// Act
+			report, err := valuation.ValueCharacters(context.Background(), []int32{90000001}, service.ValuationOptions{Source: tt.source, TopItems: service.MaxTopItems})
+
+			// Assert
+			require.NoError(t, err)
+			var found bool
+			for _, item := range report.TopItems {
+				if item.TypeID == tt.typeID {
+					found = true
+					assert.InDelta(t, tt.expectedPrice, item.UnitPrice, 0.0001)
+				}
+			}
+			assert.True(t, found)
+		})
+	}
+}
+
+func TestAssetValuationLeavesTypesWithFailingMarketDataUnpriced(t *testing.T) {
+	// Arrange
+	valuation := newTestAssetValuationService(35)
+
+	// Act
+	report, err := valuation.ValueCharacters(context.Background(), []int32{90000001}, service.ValuationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.InDelta(t, 505000, report.TotalValue, 0.001) // Without the Pyerite
+	assert.Equal(t, 3, report.PricedStacks)
+	var unpriceable []int32
+	for _, item := range report.Unpriceable {
+		unpriceable = append(unpriceable, item.TypeID)
+	}
+	assert.Equal(t, []int32{35, 3297, 99999}, unpriceable)
+}
+
+func TestAssetValuationLeavesBlueprintCopiesUnpriced(t *testing.T) {
+	// Arrange: A Rifter Blueprint original and a copy of it in Jita
+	characterData := &stubCharacterData{assets: map[int32][]models.CharacterAsset{
+		90000001: {
+			{ItemID: 1, TypeID: 691, LocationID: jitaStation, LocationType: "station", Quantity: 1, IsSingleton: true},
+			{ItemID: 2, TypeID: 691, LocationID: jitaStation, LocationType: "station", Quantity: 1, IsSingleton: true, IsBlueprintCopy: true},
+		},
+	}}
+	market := &fakeOrderBookMarket{books: map[int32][]models.MarketOrder{
+		691: {{OrderID: 1, TypeID: 691, LocationID: jitaStation, Price: 2500000, VolumeRemain: 3}},
+	}}
+	valuation := service.NewAssetValuationService(characterData, market, &fakeAveragePrices{})
+
+	// Act
+	report, err := valuation.ValueCharacters(context.Background(), []int32{90000001}, service.ValuationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.InDelta(t, 2500000, report.TotalValue, 0.001)
+	assert.Equal(t, 1, report.PricedStacks)
+	require.Len(t, report.TopItems, 1)
+	assert.False(t, report.TopItems[0].IsBlueprintCopy)
+	require.Len(t, report.Unpriceable, 1)
+	assert.Equal(t, int32(691), report.Unpriceable[0].TypeID)
+	assert.True(t, report.Unpriceable[0].IsBlueprintCopy)
+}
+
+func TestAssetValuationReportsFailingCharacters(t *testing.T) {
+	// Arrange
+	valuation := newTestAssetValuationService()
+
+	// Act
+	report, err := valuation.ValueCharacters(context.Background(), []int32{90000001, 90000002}, service.ValuationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, int32(90000002), report.Errors[0].CharacterID)
+	assert.Len(t, report.ByCharacter, 1)
+}
+
+func TestNormalizeValuationOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    service.ValuationOptions
+		wantErr bool
+	}{
+		{name: "should accept defaults", opts: service.ValuationOptions{}},
+		{name: "should reject unknown sources", opts: service.ValuationOptions{Source: "median"}, wantErr: true},
+		{name: "should reject percentiles above 100", opts: service.ValuationOptions{Percentile: 150}, wantErr: true},
+		{name: "should reject too long top lists", opts: service.ValuationOptions{TopItems: service.MaxTopItems + 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			opts, err := service.NormalizeValuationOptions(tt.opts)
+
+			// Assert
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, service.ErrInvalidInput))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, service.PriceTypeSell, opts.Source)
+			assert.Equal(t, service.DefaultValuationPercentile, opts.Percentile)
+			assert.Equal(t, service.DefaultTopItems, opts.TopItems)
+		})
+	}
+}
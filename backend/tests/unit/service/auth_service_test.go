@@ -0,0 +1,231 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSO records the PKCE parameters and issues tokens for one character
+type fakeSSO struct {
+	challenges   map[string]string // state -> code challenge
+	refreshToken string
+	refreshErr   error
+	characterID  int32
+	issued       int
+}
+
+func newFakeSSO() *fakeSSO {
+	return &fakeSSO{challenges: make(map[string]string), characterID: 90000001}
+}
+
+func (f *fakeSSO) AuthorizeURL(state, codeChallenge string, scopes []string) string {
+	f.challenges[state] = codeChallenge
+	return "https://sso.example/authorize?" + url.Values{"state": {state}, "code_challenge": {codeChallenge}}.Encode()
+}
+
+func (f *fakeSSO) ExchangeCode(ctx context.Context, code, codeVerifier string) (*models.AuthToken, error) {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	for _, challenge := range f.challenges {
+		if challenge == base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return f.issue("refresh-1"), nil
+		}
+	}
+	return nil, errors.New("invalid_grant")
+}
+
+func (f *fakeSSO) RefreshToken(ctx context.Context, refreshToken string) (*models.AuthToken, error) {
+	if f.refreshErr != nil {
+		return nil, f.refreshErr
+	}
+	f.refreshToken = refreshToken
+	return f.issue(""), nil
+}
+
+func (f *fakeSSO) issue(refreshToken string) *models.AuthToken {
+	f.issued++
+	return &models.AuthToken{
+		AccessToken:  fmt.Sprintf("access-%d", f.issued),
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    time.Now().Add(20 * time.Minute),
+		CharacterID:  f.characterID,
+	}
+}
+
+func TestAuthServiceLogin(t *testing.T) {
+	t.Run("should complete login with matching state and nonce", func(t *testing.T) {
+		// Arrange
+		sso := newFakeSSO()
+		store := service.NewMemoryTokenStore()
+		authService := service.NewAuthService(sso, store, []string{"esi-wallet.read_character_wallet.v1"})
+
+		// Act
+		login, err := authService.BeginLogin(0)
+		require.NoError(t, err)
+		token, err := authService.CompleteLogin(context.Background(), login.State, login.Nonce, "auth-code")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int32(90000001), token.CharacterID)
+		assert.Contains(t, login.AuthorizationURL, "code_challenge=")
+
+		stored, err := store.GetToken(90000001)
+		require.NoError(t, err)
+		assert.Equal(t, token.AccessToken, stored.AccessToken)
+	})
+
+	tests := []struct {
+		name        string
+		complete    func(*service.AuthService, *service.LoginRequest) error
+		expectedErr error
+	}{
+		{
+			name: "should reject unknown state",
+			complete: func(s *service.AuthService, login *service.LoginRequest) error {
+				_, err := s.CompleteLogin(context.Background(), "unknown", login.Nonce, "auth-code")
+				return err
+			},
+			expectedErr: service.ErrInvalidLoginState,
+		},
+		{
+			name: "should reject wrong nonce",
+			complete: func(s *service.AuthService, login *service.LoginRequest) error {
+				_, err := s.CompleteLogin(context.Background(), login.State, "other-browser", "auth-code")
+				return err
+			},
+			expectedErr: service.ErrInvalidLoginState,
+		},
+		{
+			name: "should reject reused state",
+			complete: func(s *service.AuthService, login *service.LoginRequest) error {
+				if _, err := s.CompleteLogin(context.Background(), login.State, login.Nonce, "auth-code"); err != nil {
+					return err
+				}
+				_, err := s.CompleteLogin(context.Background(), login.State, login.Nonce, "auth-code")
+				return err
+			},
+			expectedErr: service.ErrInvalidLoginState,
+		},
+		{
+			name: "should reject missing code",
+			complete: func(s *service.AuthService, login *service.LoginRequest) error {
+				_, err := s.CompleteLogin(context.Background(), login.State, login.Nonce, "")
+				return err
+			},
+			expectedErr: service.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			authService := service.NewAuthService(newFakeSSO(), service.NewMemoryTokenStore(), nil)
+			login, err := authService.BeginLogin(0)
+			require.NoError(t, err)
+
+			// Act
+			err = tt.complete(authService, login)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestAuthServicePendingLoginCap(t *testing.T) {
+	// Arrange
+	authService := service.NewAuthService(newFakeSSO(), service.NewMemoryTokenStore(), nil)
+	oldest, err := authService.BeginLogin(0)
+	require.NoError(t, err)
+	second, err := authService.BeginLogin(0)
+	require.NoError(t, err)
+
+	// Act
+	for i := 0; i < service.MaxPendingLogins-1; i++ {
+		_, err := authService.BeginLogin(0)
+		require.NoError(t, err)
+	}
+
+	// Assert
+	_, err = authService.CompleteLogin(context.Background(), oldest.State, oldest.Nonce, "auth-code")
+	assert.ErrorIs(t, err, service.ErrInvalidLoginState)
+	_, err = authService.CompleteLogin(context.Background(), second.State, second.Nonce, "auth-code")
+	assert.NoError(t, err)
+}
+
+func TestAuthServiceRefreshToken(t *testing.T) {
+	setup := func(t *testing.T) (*fakeSSO, *service.MemoryTokenStore, *service.AuthService, *models.AuthToken) {
+		sso := newFakeSSO()
+		store := service.NewMemoryTokenStore()
+		authService := service.NewAuthService(sso, store, nil)
+		login, err := authService.BeginLogin(0)
+		require.NoError(t, err)
+		token, err := authService.CompleteLogin(context.Background(), login.State, login.Nonce, "auth-code")
+		require.NoError(t, err)
+		return sso, store, authService, token
+	}
+
+	t.Run("should refresh with the current access token", func(t *testing.T) {
+		// Arrange
+		sso, store, authService, token := setup(t)
+
+		// Act
+		refreshed, err := authService.RefreshToken(context.Background(), token.CharacterID, token.AccessToken)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "refresh-1", sso.refreshToken)
+		assert.NotEqual(t, token.AccessToken, refreshed.AccessToken)
+		assert.Equal(t, "refresh-1", refreshed.RefreshToken, "kept when the SSO does not rotate it")
+
+		stored, err := store.GetToken(token.CharacterID)
+		require.NoError(t, err)
+		assert.Equal(t, refreshed.AccessToken, stored.AccessToken)
+	})
+
+	t.Run("should reject a wrong access token", func(t *testing.T) {
+		// Arrange
+		_, _, authService, token := setup(t)
+
+		// Act
+		_, err := authService.RefreshToken(context.Background(), token.CharacterID, "forged")
+
+		// Assert
+		assert.ErrorIs(t, err, service.ErrUnauthorized)
+	})
+
+	t.Run("should reject unknown characters", func(t *testing.T) {
+		// Arrange
+		_, _, authService, token := setup(t)
+
+		// Act
+		_, err := authService.RefreshToken(context.Background(), 12345, token.AccessToken)
+
+		// Assert
+		assert.ErrorIs(t, err, service.ErrUnauthorized)
+	})
+
+	t.Run("should report SSO failures", func(t *testing.T) {
+		// Arrange
+		sso, _, authService, token := setup(t)
+		sso.refreshErr = errors.New("connection refused")
+
+		// Act
+		_, err := authService.RefreshToken(context.Background(), token.CharacterID, token.AccessToken)
+
+		// Assert
+		assert.ErrorIs(t, err, service.ErrSSORequest)
+	})
+}
@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eve-profit2/internal/cache"
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCharacterESI records the access tokens used per call
+type fakeCharacterESI struct {
+	tokens []string
+	calls  int
+}
+
+func (f *fakeCharacterESI) GetCharacterInfo(ctx context.Context, characterID int32) (*models.Character, error) {
+	f.calls++
+	return &models.Character{CharacterID: characterID, CharacterName: "Test Pilot"}, nil
+}
+
+func (f *fakeCharacterESI) GetCharacterAssets(ctx context.Context, characterID int32, accessToken string) ([]models.CharacterAsset, error) {
+	f.calls++
+	f.tokens = append(f.tokens, accessToken)
+	return []models.CharacterAsset{{ItemID: 1, TypeID: 34, Quantity: 100}}, nil
+}
+
+func (f *fakeCharacterESI) GetCharacterWallet(ctx context.Context, characterID int32, accessToken string) (*models.CharacterWallet, error) {
+	f.calls++
+	f.tokens = append(f.tokens, accessToken)
+	return &models.CharacterWallet{Balance: 1000}, nil
+}
+
+func (f *fakeCharacterESI) GetCharacterOrders(ctx context.Context, characterID int32, accessToken string) ([]models.CharacterOrder, error) {
+	f.calls++
+	f.tokens = append(f.tokens, accessToken)
+	return nil, errors.New("ESI server error: status 502")
+}
+
+func (f *fakeCharacterESI) GetCharacterSkills(ctx context.Context, characterID int32, accessToken string) (*models.CharacterSkills, error) {
+	f.calls++
+	f.tokens = append(f.tokens, accessToken)
+	return &models.CharacterSkills{CharacterID: characterID, TotalSP: 5000000}, nil
+}
+
+// fakeTokenProvider serves persisted tokens per character
+type fakeTokenProvider struct {
+	tokens map[int32]string
+}
+
+func (f *fakeTokenProvider) ValidToken(ctx context.Context, characterID int32) (*models.AuthToken, error) {
+	token, ok := f.tokens[characterID]
+	if !ok {
+		return nil, service.ErrUnauthorized
+	}
+	return &models.AuthToken{CharacterID: characterID, AccessToken: token}, nil
+}
+
+func newTestCharacterService(t *testing.T, esiClient service.CharacterESIClient) *service.CharacterService {
+	t.Helper()
+	cacheManager, err := cache.NewCacheManager()
+	require.NoError(t, err)
+	t.Cleanup(func() { cacheManager.Close() })
+
+	tokens := &fakeTokenProvider{tokens: map[int32]string{90000001: "persisted-main", 90000002: "persisted-alt"}}
+	return service.NewCharacterService(esiClient, tokens, cacheManager)
+}
+
+func TestCharacterServiceAccessTokens(t *testing.T) {
+	// Arrange: The caller sent its own token for the main
+	esiClient := &fakeCharacterESI{}
+	characters := newTestCharacterService(t, esiClient)
+	ctx := service.ContextWithAccessToken(context.Background(), 90000001, "caller-main")
+
+	// Act
+	_, mainErr := characters.GetCharacterWallet(ctx, 90000001)
+	_, altErr := characters.GetCharacterWallet(ctx, 90000002)
+	_, unknownErr := characters.GetCharacterWallet(ctx, 90000003)
+
+	// Assert: The alt uses its persisted token
+	require.NoError(t, mainErr)
+	require.NoError(t, altErr)
+	assert.ErrorIs(t, unknownErr, service.ErrUnauthorized)
+	assert.Equal(t, []string{"caller-main", "persisted-alt"}, esiClient.tokens)
+}
+
+func TestCharacterServiceCachesPerCharacter(t *testing.T) {
+	// Arrange
+	esiClient := &fakeCharacterESI{}
+	characters := newTestCharacterService(t, esiClient)
+	ctx := context.Background()
+
+	// Act
+	first, err := characters.GetCharacterAssets(ctx, 90000001)
+	require.NoError(t, err)
+	second, err := characters.GetCharacterAssets(ctx, 90000001)
+	require.NoError(t, err)
+	_, err = characters.GetCharacterAssets(ctx, 90000002)
+	require.NoError(t, err)
+	info, err := characters.GetCharacterInfo(ctx, 90000001)
+	require.NoError(t, err)
+	skills, err := characters.GetCharacterSkills(ctx, 90000001)
+	require.NoError(t, err)
+
+	// Assert: The second asset request is served from the cache
+	assert.Equal(t, first, second)
+	assert.Equal(t, 4, esiClient.calls)
+	assert.Equal(t, "Test Pilot", info.CharacterName)
+	assert.Equal(t, int64(5000000), skills.TotalSP)
+}
+
+func TestCharacterServiceDoesNotCacheFailures(t *testing.T) {
+	// Arrange
+	esiClient := &fakeCharacterESI{}
+	characters := newTestCharacterService(t, esiClient)
+
+	// Act
+	_, firstErr := characters.GetCharacterOrders(context.Background(), 90000001)
+	_, secondErr := characters.GetCharacterOrders(context.Background(), 90000001)
+
+	// Assert
+	assert.Error(t, firstErr)
+	assert.Error(t, secondErr)
+	assert.Equal(t, 2, esiClient.calls)
+}
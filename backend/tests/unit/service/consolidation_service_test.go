@@ -0,0 +1,215 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nourvukaikenStation int64 = 60001774
+
+// newTestConsolidationService places Tritanium and a Rifter with Pyerite in its cargo
+// in Nourvukaiken, Pyerite in a player structure and Tritanium already in Jita.
+// Both regions have better buy orders away from the stations that must be ignored.
+// regionalOrderBooks serves order books per region. Like MarketService it fails
+// whole requests containing a failing type.
+type regionalOrderBooks struct {
+	books   map[int32]map[int32][]models.MarketOrder
+	failing map[int32]bool
+}
+
+func (f *regionalOrderBooks) GetMarketData(ctx context.Context, req service.MarketDataRequest) (*service.MarketDataResponse, error) {
+	response := &service.MarketDataResponse{RegionID: req.RegionID, Orders: make(map[int32][]models.MarketOrder)}
+	for _, typeID := range req.TypeIDs {
+		if f.failing[typeID] {
+			return nil, errors.New("ESI unavailable")
+		}
+		response.Orders[typeID] = f.books[req.RegionID][typeID]
+	}
+	return response, nil
+}
+
+func buyOrder(typeID int32, locationID int64, price float64) models.MarketOrder {
+	return models.MarketOrder{TypeID: typeID, LocationID: locationID, Price: price, VolumeRemain: 1000, IsBuyOrder: true}
+}
+
+func newTestConsolidationService(t *testing.T, failingTypes ...int32) *service.ConsolidationService {
+	t.Helper()
+	sdeRepo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { sdeRepo.Close() })
+
+	characterData := &stubCharacterData{
+		assets: map[int32][]models.CharacterAsset{
+			90000001: {
+				{ItemID: 1, TypeID: 34, LocationID: nourvukaikenStation, LocationType: "station", LocationFlag: "Hangar", Quantity: 10000},
+				{ItemID: 2, TypeID: 587, LocationID: nourvukaikenStation, LocationType: "station", LocationFlag: "Hangar", Quantity: 1, IsSingleton: true},
+				{ItemID: 3, TypeID: 35, LocationID: 2, LocationType: "item", LocationFlag: "Cargo", Quantity: 1000},
+				{ItemID: 4, TypeID: 35, LocationID: testStructureID, LocationType: "item", LocationFlag: "Hangar", Quantity: 5},
+				{ItemID: 5, TypeID: 34, LocationID: jitaStation, LocationType: "station", LocationFlag: "Hangar", Quantity: 1},
+			},
+		},
+		failing: map[int32]bool{90000002: true},
+	}
+	market := &regionalOrderBooks{failing: make(map[int32]bool), books: map[int32]map[int32][]models.MarketOrder{
+		10000016: {
+			34:  {buyOrder(34, nourvukaikenStation, 3), buyOrder(34, 60000001, 3.5)},
+			587: {buyOrder(587, nourvukaikenStation, 300000)},
+			35:  {buyOrder(35, nourvukaikenStation, 8)},
+		},
+		10000002: {
+			34:  {buyOrder(34, jitaStation, 4), buyOrder(34, 60000002, 4.5)},
+			587: {buyOrder(587, jitaStation, 400000), buyOrder(587, 60000002, 420000)},
+			35:  {buyOrder(35, jitaStation, 10)},
+		},
+	}}
+	for _, typeID := range failingTypes {
+		market.failing[typeID] = true
+	}
+	items := &fakeItems{items: map[int32]models.Item{
+		34:  {TypeID: 34, Volume: 0.01},
+		35:  {TypeID: 35, Volume: 0.01},
+		587: {TypeID: 587, Volume: 27289},
+	}}
+	return service.NewConsolidationService(characterData, sdeRepo, market, items)
+}
+
+func TestConsolidationPlanGroupsAssetsByStation(t *testing.T) {
+	// Arrange
+	planner := newTestConsolidationService(t)
+
+	// Act
+	plan, err := planner.PlanCharacters(context.Background(), []int32{90000001, 90000002}, service.ConsolidationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Jita", plan.Hub.Name)
+	assert.Equal(t, int32(30000142), plan.HubSolarSystemID)
+	require.Len(t, plan.Errors, 1)
+
+	// Assets in Jita stay, the structure cannot be routed
+	require.Len(t, plan.Stops, 1)
+	require.Len(t, plan.Unresolved, 1)
+	assert.Equal(t, testStructureID, plan.Unresolved[0].LocationID)
+
+	stop := plan.Stops[0]
+	assert.Equal(t, nourvukaikenStation, stop.LocationID)
+	assert.Equal(t, "Nourvukaiken", stop.SolarSystemName)
+	assert.Equal(t, int32(10000016), stop.RegionID)
+	assert.Equal(t, []int32{90000001}, stop.Characters)
+	assert.Equal(t, 3, stop.Stacks)
+
+	// The cargo moves inside the Rifter and adds no volume
+	assert.InDelta(t, 27389, stop.Volume, 0.001)
+	assert.InDelta(t, 338000, stop.LocalValue, 0.001)
+	assert.InDelta(t, 450000, stop.HubValue, 0.001)
+	assert.InDelta(t, 112000, stop.ValueGained, 0.001)
+	assert.InDelta(t, 112000, plan.TotalValueGained, 0.001)
+
+	// The shortest route leads through low security Tama
+	assert.True(t, stop.Reachable)
+	assert.Equal(t, 2, stop.Jumps)
+	assert.Equal(t, []int32{30002812, 30002813, 30000142}, stop.Route)
+	assert.Equal(t, models.RouteSecurity{MinSecurity: 0.3, HighSecSystems: 2, LowSecSystems: 1}, stop.Security)
+}
+
+func TestConsolidationPlanSecureRoute(t *testing.T) {
+	// Arrange
+	planner := newTestConsolidationService(t)
+
+	// Act
+	plan, err := planner.PlanCharacters(context.Background(), []int32{90000001}, service.ConsolidationOptions{Route: service.RouteSecure})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, plan.Stops, 1)
+	assert.Equal(t, 3, plan.Stops[0].Jumps)
+	assert.Equal(t, []int32{30002812, 30000139, 30000144, 30000142}, plan.Stops[0].Route)
+	assert.Equal(t, 0, plan.Stops[0].Security.LowSecSystems)
+}
+
+func TestConsolidationPlanCountsTypesWithFailingMarketDataUnpriced(t *testing.T) {
+	// Arrange
+	planner := newTestConsolidationService(t, 35)
+
+	// Act
+	plan, err := planner.PlanCharacters(context.Background(), []int32{90000001}, service.ConsolidationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, plan.Stops, 1)
+	stop := plan.Stops[0]
+	assert.Equal(t, 1, stop.Unpriced) // The Pyerite in the Rifter's cargo
+	assert.InDelta(t, 330000, stop.LocalValue, 0.001)
+	assert.InDelta(t, 440000, stop.HubValue, 0.001)
+}
+
+func TestConsolidationPlanValuesAssetsInTheHubRegion(t *testing.T) {
+	// Arrange: Tritanium in space in Perimeter, next to Jita, and a blueprint copy in Nourvukaiken
+	sdeRepo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { sdeRepo.Close() })
+	characterData := &stubCharacterData{assets: map[int32][]models.CharacterAsset{
+		90000001: {
+			{ItemID: 1, TypeID: 34, LocationID: 30000144, LocationType: "solar_system", LocationFlag: "Hangar", Quantity: 1000},
+			{ItemID: 2, TypeID: 691, LocationID: nourvukaikenStation, LocationType: "station", LocationFlag: "Hangar", Quantity: 1, IsBlueprintCopy: true},
+		},
+	}}
+	market := &regionalOrderBooks{books: map[int32]map[int32][]models.MarketOrder{
+		10000002: {
+			34:  {buyOrder(34, jitaStation, 4)},
+			691: {buyOrder(691, jitaStation, 2000000)},
+		},
+		10000016: {691: {buyOrder(691, nourvukaikenStation, 1500000)}},
+	}}
+	planner := service.NewConsolidationService(characterData, sdeRepo, market, &fakeItems{})
+
+	// Act
+	plan, err := planner.PlanCharacters(context.Background(), []int32{90000001}, service.ConsolidationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, plan.Stops, 2)
+	space := plan.Stops[0]
+	assert.Equal(t, int64(30000144), space.LocationID)
+	assert.Equal(t, int32(10000002), space.RegionID)
+	assert.InDelta(t, 0, space.LocalValue, 0.001)
+	assert.InDelta(t, 4000, space.ValueGained, 0.001)
+
+	blueprintCopy := plan.Stops[1]
+	assert.Equal(t, nourvukaikenStation, blueprintCopy.LocationID)
+	assert.Equal(t, 1, blueprintCopy.Unpriced)
+	assert.InDelta(t, 0, blueprintCopy.HubValue, 0.001)
+}
+
+func TestConsolidationPlanRejectsInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts service.ConsolidationOptions
+	}{
+		{name: "should reject unknown routes", opts: service.ConsolidationOptions{Route: "fastest"}},
+		{name: "should reject unknown price types", opts: service.ConsolidationOptions{PriceType: "average"}},
+		{name: "should reject region wide hubs", opts: service.ConsolidationOptions{Hub: service.MarketHub{RegionID: 10000016}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			planner := newTestConsolidationService(t)
+
+			// Act
+			_, err := planner.PlanCharacters(context.Background(), []int32{90000001}, tt.opts)
+
+			// Assert
+			assert.ErrorIs(t, err, service.ErrInvalidInput)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockBlueprintRepository implements the service.BlueprintRepository interface for testing
+type MockBlueprintRepository struct {
+	blueprints map[int32]*repository.SDEBlueprint
+}
+
+func (m *MockBlueprintRepository) GetBlueprintByProduct(productTypeID int32) (*repository.SDEBlueprint, error) {
+	if blueprint, ok := m.blueprints[productTypeID]; ok {
+		return blueprint, nil
+	}
+	return nil, fmt.Errorf("%w: productTypeID %d", repository.ErrBlueprintNotFound, productTypeID)
+}
+
+// MockMarketDataProvider implements the service.MarketDataProvider interface for testing
+type MockMarketDataProvider struct {
+	mock.Mock
+}
+
+func (m *MockMarketDataProvider) GetMarketData(ctx context.Context, req service.MarketDataRequest) (*service.MarketDataResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.MarketDataResponse), args.Error(1)
+}
+
+// MockMarketPriceClient implements the service.MarketPriceClient interface for testing
+type MockMarketPriceClient struct {
+	mock.Mock
+}
+
+func (m *MockMarketPriceClient) GetMarketPrices(ctx context.Context) ([]models.MarketPrice, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.MarketPrice), args.Error(1)
+}
+
+// sellPriceData builds a market data response from lowest sell prices
+func sellPriceData(regionID int32, sellPrices map[int32]float64) *service.MarketDataResponse {
+	response := &service.MarketDataResponse{RegionID: regionID, Data: map[int32]*models.ItemPrice{}}
+	for typeID, price := range sellPrices {
+		response.Data[typeID] = &models.ItemPrice{TypeID: typeID, SellMin: price}
+	}
+	return response
+}
+
+// newTestIndustryService wires a product (100) built from a component (200) and Tritanium (34)
+func newTestIndustryService(sellPrices map[int32]float64) (*service.IndustryService, *MockMarketDataProvider) {
+	blueprints := &MockBlueprintRepository{blueprints: map[int32]*repository.SDEBlueprint{
+		100: {
+			BlueprintTypeID: 1100, ActivityID: 1, ProductTypeID: 100, ProductQuantity: 1, Time: 3600,
+			Materials: []repository.SDEBlueprintMaterial{{TypeID: 200, Quantity: 10}, {TypeID: 34, Quantity: 100}},
+		},
+		200: {
+			BlueprintTypeID: 1200, ActivityID: 1, ProductTypeID: 200, ProductQuantity: 1, Time: 600,
+			Materials: []repository.SDEBlueprintMaterial{{TypeID: 34, Quantity: 5}},
+		},
+	}}
+
+	market := new(MockMarketDataProvider)
+	market.On("GetMarketData", mock.Anything, mock.Anything).Return(sellPriceData(service.RegionTheForge, sellPrices), nil)
+
+	priceClient := new(MockMarketPriceClient)
+	priceClient.On("GetMarketPrices", mock.Anything).Return([]models.MarketPrice{
+		{TypeID: 34, AdjustedPrice: 1},
+		{TypeID: 200, AdjustedPrice: 10},
+	}, nil)
+
+	return service.NewIndustryService(blueprints, market, priceClient), market
+}
+
+func TestIndustryServiceCalculateManufacturingBuildsCheaperComponents(t *testing.T) {
+	// Arrange: component 200 costs 20 ISK to buy but ~5.45 ISK to build
+	industryService, market := newTestIndustryService(map[int32]float64{100: 500, 200: 20, 34: 1})
+
+	// Act
+	result, err := industryService.CalculateManufacturing(context.Background(), service.ManufacturingRequest{
+		TypeID:          100,
+		SystemCostIndex: 0.05,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	plan := result.Plan
+	require.Len(t, plan.Materials, 2)
+
+	component := plan.Materials[0]
+	assert.Equal(t, int32(200), component.TypeID)
+	assert.Equal(t, int64(10), component.Quantity)
+	assert.Equal(t, service.DecisionBuild, component.Decision)
+	require.NotNil(t, component.Build)
+	assert.Equal(t, int64(10), component.Build.Runs)
+	assert.InDelta(t, 54.5, component.Build.TotalCost, 0.001) // 50 minerals + 50*(0.05+0.04) job cost
+	assert.InDelta(t, 200.0, component.BuyCost, 0.001)
+	assert.InDelta(t, 54.5, component.EffectiveCost, 0.001)
+
+	assert.Equal(t, service.DecisionBuy, plan.Materials[1].Decision)
+	assert.InDelta(t, 200.0, plan.EstimatedValue, 0.001)
+	assert.InDelta(t, 18.0, plan.JobCost, 0.001)
+	assert.InDelta(t, 172.5, plan.TotalCost, 0.001)
+	assert.Equal(t, int64(3600), plan.BuildTimeSeconds)
+
+	assert.InDelta(t, 500.0, result.MarketValue, 0.001)
+	assert.InDelta(t, 327.5, result.Profit, 0.001)
+	assert.Equal(t, service.DecisionBuild, result.Decision)
+	market.AssertNumberOfCalls(t, "GetMarketData", 1)
+}
+
+func TestIndustryServiceCalculateManufacturingAppliesEfficiencies(t *testing.T) {
+	// Arrange: buying the component is cheaper than building it
+	industryService, _ := newTestIndustryService(map[int32]float64{100: 100, 200: 1, 34: 1})
+
+	// Act
+	result, err := industryService.CalculateManufacturing(context.Background(), service.ManufacturingRequest{
+		TypeID:                100,
+		Runs:                  2,
+		MaterialEfficiency:    10,
+		TimeEfficiency:        20,
+		FacilityMaterialBonus: 0.01,
+		MaxDepth:              1,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	plan := result.Plan
+	assert.Equal(t, int64(2), plan.OutputQuantity)
+	assert.Equal(t, int64(18), plan.Materials[0].Quantity)  // ceil(10*2*0.9*0.99) = ceil(17.82)
+	assert.Equal(t, int64(179), plan.Materials[1].Quantity) // ceil(100*2*0.9*0.99) = ceil(178.2)
+	assert.Nil(t, plan.Materials[0].Build)                  // Depth limited
+	assert.Equal(t, service.DecisionBuy, plan.Materials[0].Decision)
+	assert.Equal(t, int64(5760), plan.BuildTimeSeconds) // 3600*2*0.8
+}
+
+func TestIndustryServiceCalculateManufacturingErrors(t *testing.T) {
+	industryService, _ := newTestIndustryService(map[int32]float64{})
+
+	t.Run("should reject invalid material efficiency", func(t *testing.T) {
+		_, err := industryService.CalculateManufacturing(context.Background(), service.ManufacturingRequest{
+			TypeID:             100,
+			MaterialEfficiency: 11,
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidInput)
+	})
+
+	t.Run("should reject runs above the maximum", func(t *testing.T) {
+		_, err := industryService.CalculateManufacturing(context.Background(), service.ManufacturingRequest{
+			TypeID: 100,
+			Runs:   service.MaxRuns + 1,
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidInput)
+	})
+
+	t.Run("should report types without blueprint", func(t *testing.T) {
+		_, err := industryService.CalculateManufacturing(context.Background(), service.ManufacturingRequest{TypeID: 34})
+		assert.ErrorIs(t, err, service.ErrNotManufacturable)
+	})
+}
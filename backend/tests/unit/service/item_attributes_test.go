@@ -0,0 +1,163 @@
+package service_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findComparedAttribute(comparison *models.ItemComparison, attributeID int32) *models.ComparedAttribute {
+	for _, attribute := range comparison.Attributes {
+		if attribute.AttributeID == attributeID {
+			return attribute
+		}
+	}
+	return nil
+}
+
+func TestItemServiceGetItemByIDPopulatesMass(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	item, err := itemService.GetItemByID(587) // Rifter
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1067000.0, item.Mass)
+	assert.NotEmpty(t, item.Description)
+}
+
+func TestItemServiceGetItemAttributes(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	attributes, err := itemService.GetItemAttributes(2881) // 200mm AutoCannon II
+	cached, cachedErr := itemService.GetItemAttributes(2881)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, cachedErr)
+	assert.Equal(t, "200mm AutoCannon II", attributes.TypeName)
+	assert.Equal(t, int32(5), attributes.MetaLevel)
+	assert.Equal(t, int32(2), attributes.TechLevel)
+	assert.Len(t, attributes.Attributes, 6)
+	assert.Equal(t, "Powergrid Usage", attributes.Attributes[0].DisplayName)
+	assert.Equal(t, "MW", attributes.Attributes[0].Unit)
+	assert.Equal(t, attributes, cached)
+}
+
+func TestItemServiceGetItemAttributesSkipsUnpublished(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	attributes, err := itemService.GetItemAttributes(2873)
+
+	// Assert
+	require.NoError(t, err)
+	for _, attribute := range attributes.Attributes {
+		assert.NotEqual(t, int32(1211), attribute.AttributeID)
+	}
+}
+
+func TestItemServiceGetItemAttributesNotFound(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	_, err := itemService.GetItemAttributes(999999)
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrItemNotFound)
+}
+
+func TestItemServiceCompareItems(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	comparison, err := itemService.CompareItems([]int32{2873, 9071, 2881, 2873})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, comparison.Items, 3) // Duplicates are compared once
+	assert.Equal(t, int32(1), comparison.Items[1].MetaLevel)
+	assert.Equal(t, int32(2), comparison.Items[2].TechLevel)
+
+	damage := findComparedAttribute(comparison, 64)
+	require.NotNil(t, damage)
+	assert.True(t, damage.Differs)
+	assert.Equal(t, int32(2881), damage.BestTypeID) // High is good
+
+	cpu := findComparedAttribute(comparison, 50)
+	require.NotNil(t, cpu)
+	assert.Equal(t, int32(9071), cpu.BestTypeID) // Low is good
+	require.Len(t, cpu.Values, 3)
+	assert.Equal(t, 7.0, *cpu.Values[0])
+
+	power := findComparedAttribute(comparison, 30)
+	require.NotNil(t, power)
+	assert.True(t, power.Differs)
+}
+
+func TestItemServiceCompareItemsEqualValues(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	comparison, err := itemService.CompareItems([]int32{2873, 9071})
+
+	// Assert
+	require.NoError(t, err)
+	power := findComparedAttribute(comparison, 30)
+	require.NotNil(t, power)
+	assert.False(t, power.Differs)
+	assert.Zero(t, power.BestTypeID)
+}
+
+func TestItemServiceCompareItemsMissingAttribute(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	comparison, err := itemService.CompareItems([]int32{587, 2873}) // Rifter has no rate of fire
+
+	// Assert
+	require.NoError(t, err)
+	rateOfFire := findComparedAttribute(comparison, 51)
+	require.NotNil(t, rateOfFire)
+	assert.Nil(t, rateOfFire.Values[0])
+	assert.True(t, rateOfFire.Differs)
+	assert.Equal(t, int32(2873), rateOfFire.BestTypeID)
+}
+
+func TestItemServiceCompareItemsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		typeIDs []int32
+	}{
+		{"single type", []int32{2873}},
+		{"same type twice", []int32{2873, 2873}},
+		{"negative type", []int32{2873, -1}},
+		{"too many types", []int32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			itemService := newFixtureItemService(t)
+
+			// Act
+			_, err := itemService.CompareItems(tt.typeIDs)
+
+			// Assert
+			assert.ErrorIs(t, err, service.ErrInvalidInput)
+		})
+	}
+}
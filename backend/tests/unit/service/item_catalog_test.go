@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/cache"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFixtureItemService(t *testing.T) *service.ItemService {
+	sdeRepo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { sdeRepo.Close() })
+
+	cacheManager, err := cache.NewCacheManager()
+	require.NoError(t, err)
+	t.Cleanup(func() { cacheManager.Close() })
+
+	return service.NewItemService(sdeRepo, cacheManager)
+}
+
+func TestItemServiceGetItemByIDPopulatesNames(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	item, err := itemService.GetItemByID(34)
+	cachedItem, cachedErr := itemService.GetItemByID(34)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, cachedErr)
+	assert.Equal(t, "Mineral", item.GroupName)
+	assert.Equal(t, int32(4), item.CategoryID)
+	assert.Equal(t, "Material", item.CategoryName)
+	assert.Equal(t, item, cachedItem)
+}
+
+func TestItemServiceGetMarketGroupTree(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	tree, err := itemService.GetMarketGroupTree()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, tree, 3)
+	assert.Equal(t, "Manufacture & Research", tree[0].Name)
+	assert.Equal(t, "Ship Equipment", tree[1].Name)
+	assert.Equal(t, "Ships", tree[2].Name)
+
+	materials := tree[0].Children
+	require.Len(t, materials, 2)
+	assert.Equal(t, "Materials", materials[0].Name)
+	assert.Equal(t, "Ore", materials[1].Name)
+	assert.Equal(t, "Minerals", materials[0].Children[0].Name)
+}
+
+func TestItemServiceGetMarketGroupAndTypes(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	frigates, err := itemService.GetMarketGroup(1361)
+	require.NoError(t, err)
+	types, err := itemService.GetMarketGroupTypes(64)
+	require.NoError(t, err)
+	_, missingErr := itemService.GetMarketGroupTypes(999)
+
+	// Assert
+	require.Len(t, frigates.Children, 1)
+	assert.Equal(t, int32(64), frigates.Children[0].MarketGroupID)
+	require.Len(t, types, 1)
+	assert.Equal(t, "Rifter", types[0].TypeName)
+	assert.ErrorIs(t, missingErr, service.ErrMarketGroupNotFound)
+}
+
+func TestItemServiceGetCategoriesAndGroups(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	categories, err := itemService.GetCategories()
+	require.NoError(t, err)
+	groups, err := itemService.GetGroupsByCategory(4)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Len(t, categories, 7)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "Mineral", groups[0].GroupName)
+}
@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typeNames(items []*models.Item) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.TypeName)
+	}
+	return names
+}
+
+func TestItemServiceSearchRanking(t *testing.T) {
+	itemService := newFixtureItemService(t)
+	require.NoError(t, itemService.BuildSearchIndex())
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "should rank exact matches before longer names",
+			query:    "rifter",
+			expected: []string{"Rifter", "Rifter Blueprint"},
+		},
+		{
+			name:     "should match multiple tokens regardless of order",
+			query:    "blueprint rifter",
+			expected: []string{"Rifter Blueprint"},
+		},
+		{
+			name:     "should match token prefixes",
+			query:    "cap boost",
+			expected: []string{"Cap Booster 25", "Cap Booster 800"},
+		},
+		{
+			name:     "should prefer the exact name among prefix matches",
+			query:    "Cap Booster 800",
+			expected: []string{"Cap Booster 800"},
+		},
+		{
+			name:     "should tolerate typos",
+			query:    "tritanum",
+			expected: []string{"Tritanium"},
+		},
+		{
+			name:     "should tolerate typos in partially typed words",
+			query:    "trtan",
+			expected: []string{"Tritanium"},
+		},
+		{
+			name:     "should ignore case and punctuation",
+			query:    "  MEXALLON! ",
+			expected: []string{"Mexallon"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result, err := itemService.Search(service.ItemSearchOptions{Query: tt.query})
+
+			// Assert
+			require.NoError(t, err)
+			require.NotEmpty(t, result.Items)
+			assert.Equal(t, tt.expected, typeNames(result.Items)[:len(tt.expected)])
+		})
+	}
+}
+
+func TestItemServiceSearchFilters(t *testing.T) {
+	itemService := newFixtureItemService(t)
+	published, unpublished, marketable := true, false, true
+
+	t.Run("should filter by published state", func(t *testing.T) {
+		result, err := itemService.Search(service.ItemSearchOptions{Query: "rifter", Published: &published})
+		require.NoError(t, err)
+		assert.NotContains(t, typeNames(result.Items), "Rifter Prototype")
+
+		result, err = itemService.Search(service.ItemSearchOptions{Query: "rifter", Published: &unpublished})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Rifter Prototype"}, typeNames(result.Items))
+	})
+
+	t.Run("should filter marketable types", func(t *testing.T) {
+		result, err := itemService.Search(service.ItemSearchOptions{Query: "rifter", Marketable: &marketable})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Rifter", "Rifter Blueprint"}, typeNames(result.Items))
+	})
+
+	t.Run("should filter by category", func(t *testing.T) {
+		result, err := itemService.Search(service.ItemSearchOptions{Query: "rifter", CategoryID: 9})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Rifter Blueprint"}, typeNames(result.Items))
+	})
+
+	t.Run("should filter by market group including sub groups", func(t *testing.T) {
+		result, err := itemService.Search(service.ItemSearchOptions{Query: "rifter", MarketGroupID: 4}) // Ships
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Rifter"}, typeNames(result.Items))
+	})
+
+	t.Run("should report unknown market groups", func(t *testing.T) {
+		_, err := itemService.Search(service.ItemSearchOptions{Query: "rifter", MarketGroupID: 999})
+		assert.ErrorIs(t, err, service.ErrMarketGroupNotFound)
+	})
+}
+
+func TestItemServiceSearchPaging(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	firstPage, err := itemService.Search(service.ItemSearchOptions{Query: "cap booster", Limit: 1})
+	require.NoError(t, err)
+	secondPage, err := itemService.Search(service.ItemSearchOptions{Query: "cap booster", Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	pastEnd, err := itemService.Search(service.ItemSearchOptions{Query: "cap booster", Offset: 10})
+	require.NoError(t, err)
+	_, invalidErr := itemService.Search(service.ItemSearchOptions{Query: "!!"})
+
+	// Assert
+	assert.Equal(t, 2, firstPage.Total)
+	assert.Equal(t, []string{"Cap Booster 25"}, typeNames(firstPage.Items))
+	assert.Equal(t, []string{"Cap Booster 800"}, typeNames(secondPage.Items))
+	assert.Empty(t, pastEnd.Items)
+	assert.Equal(t, service.DefaultSearchLimit, pastEnd.Limit)
+	assert.ErrorIs(t, invalidErr, service.ErrInvalidInput)
+}
+
+func TestItemServiceSearchItemsReturnsPublishedOnly(t *testing.T) {
+	// Arrange
+	itemService := newFixtureItemService(t)
+
+	// Act
+	items, err := itemService.SearchItems("rifter")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Rifter", "Rifter Blueprint"}, typeNames(items))
+}
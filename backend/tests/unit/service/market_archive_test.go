@@ -0,0 +1,216 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshotMarketData serves fixed order books and histories per region, fetched at a settable time
+type snapshotMarketData struct {
+	orders    map[int32]map[int32][]models.MarketOrder
+	history   map[int32]map[int32][]models.MarketHistory
+	failing   map[int32]bool
+	updatedAt time.Time
+	requests  []service.MarketDataRequest
+}
+
+func (f *snapshotMarketData) GetMarketData(ctx context.Context, req service.MarketDataRequest) (*service.MarketDataResponse, error) {
+	f.requests = append(f.requests, req)
+	if f.failing[req.RegionID] {
+		return nil, errors.New("ESI unavailable")
+	}
+	response := &service.MarketDataResponse{
+		RegionID:  req.RegionID,
+		Data:      make(map[int32]*models.ItemPrice),
+		Orders:    make(map[int32][]models.MarketOrder),
+		History:   make(map[int32][]models.MarketHistory),
+		UpdatedAt: f.updatedAt,
+	}
+	for _, typeID := range req.TypeIDs {
+		price := &models.ItemPrice{TypeID: typeID, LastUpdated: f.updatedAt}
+		for _, order := range f.orders[req.RegionID][typeID] {
+			if order.IsBuyOrder {
+				price.BuyMax = max(price.BuyMax, order.Price)
+				price.BuyVolume += int64(order.VolumeRemain)
+			} else {
+				if price.SellMin == 0 || order.Price < price.SellMin {
+					price.SellMin = order.Price
+				}
+				price.SellVolume += int64(order.VolumeRemain)
+			}
+		}
+		response.Data[typeID] = price
+		response.Orders[typeID] = f.orders[req.RegionID][typeID]
+		response.History[typeID] = f.history[req.RegionID][typeID]
+	}
+	return response, nil
+}
+
+func newTestMarketArchive(t *testing.T, market service.MarketDataProvider) (*service.MarketArchive, *repository.MarketSnapshotStore) {
+	t.Helper()
+	db, err := repository.OpenAppDatabase(filepath.Join(t.TempDir(), "app.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	store, err := repository.NewMarketSnapshotStore(db)
+	require.NoError(t, err)
+	return service.NewMarketArchive(store, market, []int32{10000002, 10000043}, []int32{34}), store
+}
+
+func TestMarketArchiveCollectsSnapshots(t *testing.T) {
+	// Arrange
+	takenAt := time.Date(2026, 10, 1, 12, 5, 0, 0, time.UTC)
+	market := &snapshotMarketData{
+		orders: map[int32]map[int32][]models.MarketOrder{10000002: {34: {
+			{OrderID: 1, Price: 5, VolumeRemain: 1000},
+			{OrderID: 2, Price: 6, VolumeRemain: 500},
+			{OrderID: 3, Price: 4, VolumeRemain: 2000, IsBuyOrder: true},
+		}}},
+		failing:   map[int32]bool{10000043: true},
+		updatedAt: takenAt,
+	}
+	archive, store := newTestMarketArchive(t, market)
+
+	// Act
+	result, err := archive.Collect(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Snapshots)
+	assert.Zero(t, result.OrderBooks)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, int32(10000043), result.Errors[0].RegionID)
+
+	snapshots, err := store.ListSnapshots(10000002, 34, takenAt, takenAt.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, models.MarketSnapshot{
+		RegionID: 10000002, TypeID: 34, Timestamp: takenAt, Samples: 1,
+		BuyMax: 4, SellMin: 5, BuyVolume: 2000, SellVolume: 1500, BuyOrders: 1, SellOrders: 2,
+	}, snapshots[0])
+}
+
+func TestMarketArchiveCollectsOrderBooks(t *testing.T) {
+	// Arrange
+	market := &snapshotMarketData{
+		orders:    map[int32]map[int32][]models.MarketOrder{10000002: {34: {{OrderID: 1, Price: 5, VolumeRemain: 1000}}}},
+		updatedAt: time.Now(),
+	}
+	archive, _ := newTestMarketArchive(t, market)
+	archive.WithOrderBooks()
+
+	// Act
+	result, err := archive.Collect(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.OrderBooks) // Both regions, The Domain without orders
+}
+
+func TestMarketArchivePriceCurve(t *testing.T) {
+	// Arrange
+	hour := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	market := &snapshotMarketData{}
+	archive, store := newTestMarketArchive(t, market)
+	require.NoError(t, store.SaveSnapshots([]models.MarketSnapshot{
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour, BuyMax: 4, SellMin: 5, SellVolume: 100},
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour.Add(10 * time.Minute), BuyMax: 4, SellMin: 6, SellVolume: 300},
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour.Add(20 * time.Minute), BuyMax: 5, SellMin: 0},
+		{RegionID: 10000002, TypeID: 34, Timestamp: hour.Add(40 * time.Minute), BuyMax: 4, SellMin: 5},
+	}))
+
+	tests := []struct {
+		name           string
+		interval       time.Duration
+		expectedPoints []models.PricePoint
+	}{
+		{
+			name:     "should return stored points without interval",
+			interval: 0,
+			expectedPoints: []models.PricePoint{
+				{Timestamp: hour, BuyMax: 4, SellMin: 5, Spread: 0.2, SellVolume: 100, Samples: 1},
+				{Timestamp: hour.Add(10 * time.Minute), BuyMax: 4, SellMin: 6, Spread: 1.0 / 3, SellVolume: 300, Samples: 1},
+				{Timestamp: hour.Add(20 * time.Minute), BuyMax: 5, Samples: 1},
+				{Timestamp: hour.Add(40 * time.Minute), BuyMax: 4, SellMin: 5, Spread: 0.2, Samples: 1},
+			},
+		},
+		{
+			name:     "should average points into buckets",
+			interval: 30 * time.Minute,
+			expectedPoints: []models.PricePoint{
+				{Timestamp: hour, BuyMax: 13.0 / 3, SellMin: 5.5, Spread: (5.5 - 13.0/3) / 5.5, SellVolume: 133, Samples: 3},
+				{Timestamp: hour.Add(30 * time.Minute), BuyMax: 4, SellMin: 5, Spread: 0.2, Samples: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			curve, err := archive.PriceCurve(10000002, 34, hour, hour.Add(time.Hour), tt.interval)
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, curve.Tracked)
+			require.Len(t, curve.Points, len(tt.expectedPoints))
+			for i, expected := range tt.expectedPoints {
+				point := curve.Points[i]
+				assert.True(t, expected.Timestamp.Equal(point.Timestamp))
+				assert.InDelta(t, expected.BuyMax, point.BuyMax, 0.0001)
+				assert.InDelta(t, expected.SellMin, point.SellMin, 0.0001)
+				assert.InDelta(t, expected.Spread, point.Spread, 0.0001)
+				assert.Equal(t, expected.SellVolume, point.SellVolume)
+				assert.Equal(t, expected.Samples, point.Samples)
+			}
+		})
+	}
+}
+
+func TestMarketArchiveCompactAppliesRetention(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 10, 10, 12, 30, 0, 0, time.UTC)
+	archive, store := newTestMarketArchive(t, &snapshotMarketData{})
+	archive.WithRetention(24*time.Hour, 7*24*time.Hour)
+	require.NoError(t, store.SaveSnapshots([]models.MarketSnapshot{
+		{RegionID: 10000002, TypeID: 34, Timestamp: now.Add(-30 * 24 * time.Hour), BuyMax: 3},           // Beyond the hourly retention
+		{RegionID: 10000002, TypeID: 34, Timestamp: now.Add(-48 * time.Hour), BuyMax: 4},                // Downsampled
+		{RegionID: 10000002, TypeID: 34, Timestamp: now.Add(-24*time.Hour - 10*time.Minute), BuyMax: 5}, // Its hour is not complete yet
+		{RegionID: 10000002, TypeID: 34, Timestamp: now.Add(-time.Hour), BuyMax: 6},
+	}))
+
+	// Act
+	err := archive.Compact(now)
+
+	// Assert
+	require.NoError(t, err)
+	snapshots, listErr := store.ListSnapshots(10000002, 34, now.Add(-60*24*time.Hour), now)
+	require.NoError(t, listErr)
+	require.Len(t, snapshots, 3)
+	assert.Equal(t, service.SnapshotResolutionHourly, snapshots[0].Resolution)
+	assert.True(t, snapshots[0].Timestamp.Equal(now.Add(-48*time.Hour).Truncate(time.Hour)))
+	assert.Equal(t, service.SnapshotResolutionRaw, snapshots[1].Resolution)
+	assert.Equal(t, service.SnapshotResolutionRaw, snapshots[2].Resolution)
+}
+
+func TestMarketArchivePriceCurveRejectsInvalidInput(t *testing.T) {
+	// Arrange
+	archive, _ := newTestMarketArchive(t, &snapshotMarketData{})
+	now := time.Now()
+
+	// Act
+	_, reversedErr := archive.PriceCurve(10000002, 34, now, now.Add(-time.Hour), 0)
+	_, intervalErr := archive.PriceCurve(10000002, 34, now.Add(-time.Hour), now, time.Second)
+
+	// Assert
+	assert.ErrorIs(t, reversedErr, service.ErrInvalidInput)
+	assert.ErrorIs(t, intervalErr, service.ErrInvalidInput)
+}
@@ -69,3 +69,32 @@ func TestMarketServiceWithMockDataShouldProcessOrders(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestMarketServiceCachesTypesAcrossRequests(t *testing.T) {
+	// Arrange
+	mockClient := new(MockESIClient)
+	for _, typeID := range []int32{34, 35} {
+		mockClient.On("GetMarketOrders", mock.Anything, int32(10000002), typeID).Return(fixtures.TestMarketOrders, nil).Once()
+		mockClient.On("GetMarketHistory", mock.Anything, int32(10000002), typeID).Return([]models.MarketHistory{}, nil).Once()
+	}
+	marketService := service.NewMarketService(mockClient)
+	var updates [][]int32
+	marketService.OnUpdate(func(data *service.MarketDataResponse) {
+		var typeIDs []int32
+		for typeID := range data.Data {
+			typeIDs = append(typeIDs, typeID)
+		}
+		updates = append(updates, typeIDs)
+	})
+
+	// Act
+	_, firstErr := marketService.GetMarketData(context.Background(), service.MarketDataRequest{RegionID: 10000002, TypeIDs: []int32{34}})
+	both, bothErr := marketService.GetMarketData(context.Background(), service.MarketDataRequest{RegionID: 10000002, TypeIDs: []int32{35, 34}})
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, bothErr)
+	assert.Len(t, both.Data, 2)
+	assert.Equal(t, [][]int32{{34}, {35}}, updates) // Listeners only see fetched types
+	mockClient.AssertExpectations(t)                // Type 34 was fetched once
+}
@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffOrderBooks(t *testing.T) {
+	takenAt := time.Date(2026, 10, 1, 12, 5, 0, 0, time.UTC)
+	issued := takenAt.Add(-24 * time.Hour)
+	sell := func(orderID int64, price float64, remain int32) models.MarketOrder {
+		return models.MarketOrder{OrderID: orderID, TypeID: 34, LocationID: jitaStation, Price: price, VolumeTotal: 1000, VolumeRemain: remain, Duration: 90, Issued: issued}
+	}
+
+	tests := []struct {
+		name     string
+		previous []models.MarketOrder
+		current  []models.MarketOrder
+		expected []models.OrderEvent
+	}{
+		{
+			name:     "should report new orders",
+			previous: []models.MarketOrder{sell(1, 5, 1000)},
+			current:  []models.MarketOrder{sell(1, 5, 1000), sell(2, 4.9, 500)},
+			expected: []models.OrderEvent{
+				{OrderID: 2, Type: models.OrderEventNew, Price: 4.9, Volume: 500},
+			},
+		},
+		{
+			name:     "should report partial fills at the previous price",
+			previous: []models.MarketOrder{sell(1, 5, 1000)},
+			current:  []models.MarketOrder{sell(1, 5, 700)},
+			expected: []models.OrderEvent{
+				{OrderID: 1, Type: models.OrderEventPartialFill, Price: 5, Volume: 300},
+			},
+		},
+		{
+			name:     "should report fills and modifications of one order",
+			previous: []models.MarketOrder{sell(1, 5, 1000)},
+			current:  []models.MarketOrder{sell(1, 4.8, 900)},
+			expected: []models.OrderEvent{
+				{OrderID: 1, Type: models.OrderEventPartialFill, Price: 5, Volume: 100},
+				{OrderID: 1, Type: models.OrderEventModified, Price: 4.8, PreviousPrice: 5, Volume: 900},
+			},
+		},
+		{
+			name:     "should count vanished best orders as filled",
+			previous: []models.MarketOrder{sell(1, 5, 400), sell(2, 6, 1000)},
+			current:  []models.MarketOrder{sell(2, 6, 1000)},
+			expected: []models.OrderEvent{
+				{OrderID: 1, Type: models.OrderEventFilled, Price: 5, Volume: 400},
+			},
+		},
+		{
+			name:     "should count other vanished orders as cancelled",
+			previous: []models.MarketOrder{sell(1, 5, 400), sell(2, 6, 1000)},
+			current:  []models.MarketOrder{sell(1, 5, 400)},
+			expected: []models.OrderEvent{
+				{OrderID: 2, Type: models.OrderEventCancelled, Price: 6, Volume: 1000},
+			},
+		},
+		{
+			name: "should count vanished orders past their duration as expired",
+			previous: []models.MarketOrder{
+				sell(1, 5, 400),
+				{OrderID: 2, TypeID: 34, LocationID: jitaStation, Price: 6, VolumeRemain: 1000, Duration: 1, Issued: issued},
+			},
+			current: []models.MarketOrder{sell(1, 5, 400)},
+			expected: []models.OrderEvent{
+				{OrderID: 2, Type: models.OrderEventExpired, Price: 6, Volume: 1000},
+			},
+		},
+		{
+			name: "should judge the best price per station",
+			previous: []models.MarketOrder{
+				sell(1, 5, 400),
+				{OrderID: 2, TypeID: 34, LocationID: amarrStation, Price: 6, VolumeRemain: 1000, Duration: 90, Issued: issued},
+			},
+			current: []models.MarketOrder{sell(1, 5, 400)},
+			expected: []models.OrderEvent{
+				{OrderID: 2, Type: models.OrderEventFilled, LocationID: amarrStation, Price: 6, Volume: 1000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			events := service.DiffOrderBooks(tt.previous, tt.current, takenAt)
+
+			// Assert
+			for i := range tt.expected {
+				tt.expected[i].TypeID = 34
+				tt.expected[i].Timestamp = takenAt
+				if tt.expected[i].LocationID == 0 {
+					tt.expected[i].LocationID = jitaStation
+				}
+			}
+			assert.Equal(t, tt.expected, events)
+		})
+	}
+}
+
+func newTestOrderFlow(t *testing.T, market service.MarketDataProvider) (*service.MarketArchive, *service.OrderFlowService) {
+	t.Helper()
+	db, err := repository.OpenAppDatabase(filepath.Join(t.TempDir(), "app.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	snapshots, err := repository.NewMarketSnapshotStore(db)
+	require.NoError(t, err)
+	events, err := repository.NewOrderEventStore(db)
+	require.NoError(t, err)
+
+	orderFlow := service.NewOrderFlowService(snapshots, events)
+	archive := service.NewMarketArchive(snapshots, market, []int32{10000002}, []int32{34}).WithOrderFlow(orderFlow)
+	return archive, orderFlow
+}
+
+func TestOrderFlowEstimatesHourlyVolume(t *testing.T) {
+	// Arrange
+	start := time.Now().UTC().Add(-3 * time.Hour).Truncate(time.Hour).Add(55 * time.Minute)
+	issued := start.Add(-time.Hour)
+	market := &snapshotMarketData{updatedAt: start}
+	archive, orderFlow := newTestOrderFlow(t, market)
+	books := [][]models.MarketOrder{
+		{
+			{OrderID: 1, TypeID: 34, LocationID: jitaStation, Price: 5, VolumeRemain: 1000, Duration: 90, Issued: issued},
+			{OrderID: 2, TypeID: 34, LocationID: jitaStation, Price: 4, VolumeRemain: 5000, IsBuyOrder: true, Duration: 90, Issued: issued},
+		},
+		{
+			{OrderID: 1, TypeID: 34, LocationID: jitaStation, Price: 5, VolumeRemain: 600, Duration: 90, Issued: issued},
+			{OrderID: 2, TypeID: 34, LocationID: jitaStation, Price: 4, VolumeRemain: 5000, IsBuyOrder: true, Duration: 90, Issued: issued},
+		},
+		{
+			{OrderID: 2, TypeID: 34, LocationID: jitaStation, Price: 4, VolumeRemain: 3000, IsBuyOrder: true, Duration: 90, Issued: issued},
+			{OrderID: 3, TypeID: 34, LocationID: jitaStation, Price: 5.5, VolumeRemain: 800, Duration: 90, Issued: start},
+		},
+	}
+
+	// Act
+	for i, book := range books {
+		market.updatedAt = start.Add(time.Duration(i) * 5 * time.Minute)
+		market.orders = map[int32]map[int32][]models.MarketOrder{10000002: {34: book}}
+		_, err := archive.Collect(context.Background())
+		require.NoError(t, err)
+	}
+	_, err := archive.Collect(context.Background()) // Cached market data adds no events
+	require.NoError(t, err)
+	report, err := orderFlow.Velocity(10000002, 34, jitaStation, start.Add(-55*time.Minute), start.Add(65*time.Minute))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), report.SoldUnits) // 400 partially, then the remaining 600 at the best price
+	assert.InDelta(t, 5000, report.SoldValue, 0.001)
+	assert.Equal(t, int64(2000), report.BoughtUnits)
+	assert.Equal(t, 1, report.NewOrders)
+	assert.InDelta(t, 500, report.SellVelocity, 0.001)
+	assert.InDelta(t, 1000, report.BuyVelocity, 0.001)
+
+	require.Len(t, report.Hourly, 2)
+	assert.Equal(t, models.HourlyTradeVolume{Hour: start.Truncate(time.Hour), SoldUnits: 400, SoldValue: 2000, Fills: 1}, report.Hourly[0])
+	assert.Equal(t, models.HourlyTradeVolume{Hour: start.Truncate(time.Hour).Add(time.Hour), SoldUnits: 600, SoldValue: 3000, BoughtUnits: 2000, BoughtValue: 8000, Fills: 2}, report.Hourly[1])
+
+	page, err := orderFlow.Events(10000002, 34, 0, start, "", 0)
+	require.NoError(t, err)
+	assert.Len(t, page.Events, 4)
+	assert.False(t, page.HasMore)
+}
+
+func TestOrderFlowPagesEventsWithCursor(t *testing.T) {
+	// Arrange: three events in the same snapshot second
+	db, err := repository.OpenAppDatabase(filepath.Join(t.TempDir(), "app.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	events, err := repository.NewOrderEventStore(db)
+	require.NoError(t, err)
+	orderFlow := service.NewOrderFlowService(nil, events)
+	at := time.Now().UTC().Truncate(time.Second).Add(-time.Minute)
+	require.NoError(t, events.SaveOrderEvents([]models.OrderEvent{
+		{RegionID: 10000002, TypeID: 34, OrderID: 1, Type: models.OrderEventNew, Timestamp: at},
+		{RegionID: 10000002, TypeID: 34, OrderID: 2, Type: models.OrderEventNew, Timestamp: at},
+		{RegionID: 10000002, TypeID: 34, OrderID: 3, Type: models.OrderEventNew, Timestamp: at},
+	}))
+
+	// Act
+	first, err := orderFlow.Events(10000002, 34, 0, at.Add(-time.Hour), "", 2)
+	require.NoError(t, err)
+	second, err := orderFlow.Events(10000002, 34, 0, at.Add(-time.Hour), first.Next, 2)
+	require.NoError(t, err)
+	idle, err := orderFlow.Events(10000002, 34, 0, at.Add(-time.Hour), second.Next, 2)
+	require.NoError(t, err)
+	_, invalidErr := orderFlow.Events(10000002, 34, 0, at, "not a cursor", 2)
+
+	// Assert
+	require.Len(t, first.Events, 2)
+	assert.True(t, first.HasMore)
+	require.Len(t, second.Events, 1)
+	assert.Equal(t, int64(3), second.Events[0].OrderID) // Not skipped although it shares the second
+	assert.False(t, second.HasMore)
+	assert.Empty(t, idle.Events)
+	assert.Equal(t, second.Next, idle.Next) // Polling keeps the position
+	assert.ErrorIs(t, invalidErr, service.ErrInvalidInput)
+}
+
+func TestOrderFlowRejectsInvalidRanges(t *testing.T) {
+	// Arrange
+	_, orderFlow := newTestOrderFlow(t, &snapshotMarketData{})
+	now := time.Now()
+
+	// Act
+	_, reversedErr := orderFlow.Velocity(10000002, 34, 0, now, now.Add(-time.Hour))
+	_, tooLongErr := orderFlow.Velocity(10000002, 34, 0, now.Add(-60*24*time.Hour), now)
+
+	// Assert
+	assert.ErrorIs(t, reversedErr, service.ErrInvalidInput)
+	assert.ErrorIs(t, tooLongErr, service.ErrInvalidInput)
+}
@@ -0,0 +1,196 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drainUpdates(sub *service.PriceSubscription) []models.PriceUpdate {
+	var updates []models.PriceUpdate
+	for {
+		select {
+		case update := <-sub.Updates():
+			updates = append(updates, update)
+		default:
+			return updates
+		}
+	}
+}
+
+func TestPriceStreamFansOutOneFetch(t *testing.T) {
+	// Arrange
+	fetchedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	market := &snapshotMarketData{orders: sellOrders(5), updatedAt: fetchedAt}
+	stream := service.NewPriceStream(market)
+	tritanium := service.PriceKey{RegionID: service.RegionTheForge, TypeID: 34}
+	pyerite := service.PriceKey{RegionID: service.RegionTheForge, TypeID: 35}
+	first, err := stream.Subscribe(context.Background(), []service.PriceKey{tritanium, tritanium})
+	require.NoError(t, err)
+	second, err := stream.Subscribe(context.Background(), []service.PriceKey{pyerite, tritanium})
+	require.NoError(t, err)
+	initialFirst, initialSecond := drainUpdates(first), drainUpdates(second)
+	market.requests = nil
+
+	// Act
+	stream.Publish(&service.MarketDataResponse{RegionID: service.RegionTheForge, Data: map[int32]*models.ItemPrice{
+		34: {TypeID: 34, SellMin: 5, LastUpdated: fetchedAt}, // Already delivered
+	}})
+	unchanged := drainUpdates(first)
+	market.orders, market.updatedAt = sellOrders(4.5), fetchedAt.Add(5*time.Minute)
+	refreshErr := stream.Refresh(context.Background())
+
+	// Assert
+	assert.Equal(t, []service.PriceKey{tritanium}, first.Keys())
+	assert.Len(t, initialFirst, 1)
+	assert.Len(t, initialSecond, 2)
+	assert.Empty(t, unchanged)
+	require.NoError(t, refreshErr)
+	require.Len(t, market.requests, 1) // One fetch for the union of all subscriptions
+	assert.Equal(t, []int32{34, 35}, market.requests[0].TypeIDs)
+	updates := drainUpdates(first)
+	require.Len(t, updates, 1)
+	assert.Equal(t, service.RegionTheForge, updates[0].RegionID)
+	assert.InDelta(t, 1, updates[0].SellMin, 0.0001) // Cheapest order in the region
+	assert.True(t, updates[0].LastUpdated.Equal(fetchedAt.Add(5*time.Minute)))
+	assert.Len(t, drainUpdates(second), 2)
+}
+
+func TestPriceStreamClosesSlowSubscribers(t *testing.T) {
+	// Arrange
+	fetchedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	market := &snapshotMarketData{orders: sellOrders(5), updatedAt: fetchedAt}
+	stream := service.NewPriceStream(market).WithBuffer(1)
+	sub, err := stream.Subscribe(context.Background(), []service.PriceKey{{RegionID: service.RegionTheForge, TypeID: 34}})
+	require.NoError(t, err)
+
+	// Act
+	for i := 1; i <= 2; i++ {
+		market.updatedAt = fetchedAt.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, stream.Refresh(context.Background()))
+	}
+	market.requests = nil
+	refreshErr := stream.Refresh(context.Background())
+
+	// Assert
+	<-sub.Done()
+	assert.ErrorIs(t, sub.Err(), service.ErrSlowSubscriber)
+	assert.Len(t, drainUpdates(sub), 2) // Queued updates remain readable
+	assert.NoError(t, refreshErr)
+	assert.Empty(t, market.requests) // No subscribers left to refresh
+}
+
+func TestPriceStreamUnsubscribesWhenContextEnds(t *testing.T) {
+	// Arrange
+	stream := service.NewPriceStream(&snapshotMarketData{updatedAt: time.Now()})
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := stream.Subscribe(ctx, []service.PriceKey{{RegionID: service.RegionTheForge, TypeID: 34}})
+	require.NoError(t, err)
+
+	// Act
+	cancel()
+
+	// Assert
+	select {
+	case <-sub.Done():
+		assert.NoError(t, sub.Err())
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+}
+
+func TestPriceStreamValidatesKeys(t *testing.T) {
+	stream := service.NewPriceStream(&snapshotMarketData{})
+	tooMany := make([]service.PriceKey, service.MaxPriceStreamKeys+1)
+	for i := range tooMany {
+		tooMany[i] = service.PriceKey{RegionID: service.RegionTheForge, TypeID: int32(i + 1)}
+	}
+
+	tests := []struct {
+		name string
+		keys []service.PriceKey
+	}{
+		{name: "should require keys", keys: nil},
+		{name: "should reject invalid regions", keys: []service.PriceKey{{TypeID: 34}}},
+		{name: "should reject invalid types", keys: []service.PriceKey{{RegionID: service.RegionTheForge}}},
+		{name: "should limit the keys per subscription", keys: tooMany},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := stream.Subscribe(context.Background(), tt.keys)
+
+			// Assert
+			assert.ErrorIs(t, err, service.ErrInvalidInput)
+		})
+	}
+}
+
+func TestPriceStreamRejectsTypesOffTheMarket(t *testing.T) {
+	// Arrange
+	stream := service.NewPriceStream(&snapshotMarketData{}).WithItems(&fakeItems{items: map[int32]models.Item{
+		34:    {TypeID: 34, MarketGroup: 1857},
+		29668: {TypeID: 29668}, // No market group
+	}})
+
+	// Act
+	_, validErr := stream.Subscribe(context.Background(), []service.PriceKey{{RegionID: service.RegionTheForge, TypeID: 34}})
+	_, unknownErr := stream.Subscribe(context.Background(), []service.PriceKey{{RegionID: service.RegionTheForge, TypeID: 999999}})
+	_, unmarketableErr := stream.Subscribe(context.Background(), []service.PriceKey{
+		{RegionID: service.RegionTheForge, TypeID: 34}, {RegionID: service.RegionTheForge, TypeID: 29668},
+	})
+
+	// Assert
+	assert.NoError(t, validErr)
+	assert.ErrorIs(t, unknownErr, service.ErrInvalidInput)
+	assert.ErrorIs(t, unmarketableErr, service.ErrInvalidInput)
+}
+
+// failingPriceMarket serves prices and, like MarketService, fails whole requests
+// containing a failing type
+type failingPriceMarket struct {
+	failing   map[int32]bool
+	updatedAt time.Time
+}
+
+func (f *failingPriceMarket) GetMarketData(ctx context.Context, req service.MarketDataRequest) (*service.MarketDataResponse, error) {
+	response := &service.MarketDataResponse{RegionID: req.RegionID, Data: make(map[int32]*models.ItemPrice)}
+	for _, typeID := range req.TypeIDs {
+		if f.failing[typeID] {
+			return nil, errors.New("ESI unavailable")
+		}
+		response.Data[typeID] = &models.ItemPrice{TypeID: typeID, SellMin: 5, LastUpdated: f.updatedAt}
+	}
+	return response, nil
+}
+
+func TestPriceStreamRefreshesTypesOneByOneWhenTheRegionFails(t *testing.T) {
+	// Arrange
+	market := &failingPriceMarket{failing: map[int32]bool{}, updatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	stream := service.NewPriceStream(market)
+	sub, err := stream.Subscribe(context.Background(), []service.PriceKey{
+		{RegionID: service.RegionTheForge, TypeID: 34}, {RegionID: service.RegionTheForge, TypeID: 35},
+	})
+	require.NoError(t, err)
+	drainUpdates(sub)
+
+	// Act
+	market.failing[35] = true
+	market.updatedAt = market.updatedAt.Add(5 * time.Minute)
+	refreshErr := stream.Refresh(context.Background())
+
+	// Assert
+	require.Error(t, refreshErr)
+	assert.Contains(t, refreshErr.Error(), "type 35")
+	updates := drainUpdates(sub)
+	require.Len(t, updates, 1) // The working type is still refreshed
+	assert.Equal(t, int32(34), updates[0].TypeID)
+}
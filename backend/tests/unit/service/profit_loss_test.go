@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	jitaStation  int64 = 60003760
+	amarrStation int64 = 60008494
+)
+
+func tradingDay(day int) time.Time {
+	return time.Date(2026, 10, day, 12, 0, 0, 0, time.UTC)
+}
+
+// tradingHistory buys Tritanium twice, sells most of it in Jita and sells
+// Pyerite that was bought before the synced history
+func tradingHistory() ([]models.WalletTransaction, []models.WalletJournalEntry) {
+	transactions := []models.WalletTransaction{
+		{TransactionID: 3, Date: tradingDay(3), TypeID: 34, Quantity: 150, UnitPrice: 15, LocationID: jitaStation},
+		{TransactionID: 1, Date: tradingDay(1), TypeID: 34, Quantity: 100, UnitPrice: 10, LocationID: jitaStation, IsBuy: true},
+		{TransactionID: 2, Date: tradingDay(2), TypeID: 34, Quantity: 100, UnitPrice: 12, LocationID: jitaStation, IsBuy: true},
+		{TransactionID: 4, Date: tradingDay(3), TypeID: 35, Quantity: 20, UnitPrice: 5, LocationID: amarrStation},
+	}
+	journal := []models.WalletJournalEntry{
+		{ID: 11, Date: tradingDay(1), RefType: "brokers_fee", Amount: -20, ContextID: 1, ContextIDType: "market_transaction_id"},
+		{ID: 12, Date: tradingDay(2), RefType: "brokers_fee", Amount: -10},
+		{ID: 13, Date: tradingDay(3), RefType: "transaction_tax", Amount: -45, ContextID: 3, ContextIDType: "market_transaction_id"},
+		{ID: 14, Date: tradingDay(3), RefType: "brokers_fee", Amount: -30, ContextID: 3, ContextIDType: "market_transaction_id"},
+		{ID: 15, Date: tradingDay(3), RefType: "market_transaction", Amount: 2250, ContextID: 3, ContextIDType: "market_transaction_id"},
+	}
+	return transactions, journal
+}
+
+func TestCalculateProfitLossFIFO(t *testing.T) {
+	// Arrange
+	transactions, journal := tradingHistory()
+
+	// Act
+	report := service.CalculateProfitLoss(transactions, journal, service.CostMethodFIFO)
+
+	// Assert: 100 @ 10.20 (incl. broker fee) and 50 @ 12 were sold
+	require.Len(t, report.ByItem, 2)
+	tritanium := report.ByItem[0]
+	assert.Equal(t, int32(34), tritanium.TypeID)
+	assert.Equal(t, int64(150), tritanium.Quantity)
+	assert.InDelta(t, 2250, tritanium.Revenue, 0.001)
+	assert.InDelta(t, 1620, tritanium.Cost, 0.001)
+	assert.InDelta(t, 45, tritanium.SalesTax, 0.001)
+	assert.InDelta(t, 30, tritanium.BrokerFees, 0.001)
+	assert.InDelta(t, 555, tritanium.Profit, 0.001)
+
+	// Pyerite without purchase is not guessed
+	assert.Equal(t, int32(35), report.ByItem[1].TypeID)
+	assert.Equal(t, int64(20), report.ByItem[1].UnmatchedQuantity)
+	assert.Zero(t, report.ByItem[1].Revenue)
+
+	// The unallocated broker fee counts against its day and the total
+	assert.InDelta(t, 10, report.UnallocatedBrokerFees, 0.001)
+	assert.InDelta(t, 545, report.Realized.Profit, 0.001)
+	require.Len(t, report.ByDay, 2)
+	assert.Equal(t, "2026-10-02", report.ByDay[0].Date)
+	assert.InDelta(t, -10, report.ByDay[0].Profit, 0.001)
+	assert.InDelta(t, 555, report.ByDay[1].Profit, 0.001)
+
+	require.Len(t, report.ByStation, 1)
+	assert.Equal(t, jitaStation, report.ByStation[0].LocationID)
+
+	// 50 units bought at 12 remain
+	require.Len(t, report.Unrealized, 1)
+	assert.Equal(t, int64(50), report.Unrealized[0].Quantity)
+	assert.InDelta(t, 600, report.Unrealized[0].CostBasis, 0.001)
+}
+
+func TestCalculateProfitLossAverageCost(t *testing.T) {
+	// Arrange
+	transactions, journal := tradingHistory()
+
+	// Act
+	report := service.CalculateProfitLoss(transactions, journal, service.CostMethodAverage)
+
+	// Assert: Every unit costs (1020 + 1200) / 200 = 11.10
+	assert.Equal(t, "average", report.Method)
+	assert.InDelta(t, 1665, report.ByItem[0].Cost, 0.001)
+	assert.InDelta(t, 510, report.ByItem[0].Profit, 0.001)
+	require.Len(t, report.Unrealized, 1)
+	assert.InDelta(t, 11.1, report.Unrealized[0].AverageCost, 0.001)
+}
+
+func TestApplyMarketPrices(t *testing.T) {
+	// Arrange
+	transactions, journal := tradingHistory()
+	transactions = append(transactions, models.WalletTransaction{
+		TransactionID: 5, Date: tradingDay(4), TypeID: 36, Quantity: 10, UnitPrice: 50, IsBuy: true,
+	})
+	report := service.CalculateProfitLoss(transactions, journal, service.CostMethodFIFO)
+
+	// Act: Mexallon has no price
+	service.ApplyMarketPrices(report, map[int32]float64{34: 14})
+
+	// Assert
+	require.Len(t, report.Unrealized, 2)
+	assert.True(t, report.Unrealized[0].Priced)
+	assert.InDelta(t, 700, report.Unrealized[0].MarketValue, 0.001)
+	assert.InDelta(t, 100, report.Unrealized[0].Profit, 0.001)
+	assert.False(t, report.Unrealized[1].Priced)
+	assert.InDelta(t, 100, report.UnrealizedProfit, 0.001)
+}
+
+func TestParseCostMethod(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected service.CostMethod
+		wantErr  bool
+	}{
+		{input: "", expected: service.CostMethodFIFO},
+		{input: "fifo", expected: service.CostMethodFIFO},
+		{input: "average", expected: service.CostMethodAverage},
+		{input: "lifo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			// Act
+			method, err := service.ParseCostMethod(tt.input)
+
+			// Assert
+			if tt.wantErr {
+				assert.ErrorIs(t, err, service.ErrInvalidInput)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, method)
+		})
+	}
+}
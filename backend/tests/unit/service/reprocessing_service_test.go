@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// buyPriceData builds a market data response from highest buy prices
+func buyPriceData(regionID int32, buyPrices map[int32]float64) *service.MarketDataResponse {
+	response := &service.MarketDataResponse{RegionID: regionID, Data: map[int32]*models.ItemPrice{}}
+	for typeID, price := range buyPrices {
+		response.Data[typeID] = &models.ItemPrice{TypeID: typeID, BuyMax: price, SellMin: price * 2}
+	}
+	return response
+}
+
+func newTestReprocessingService(t *testing.T, buyPrices map[int32]float64) *service.ReprocessingService {
+	sdeRepo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { sdeRepo.Close() })
+
+	market := new(MockMarketDataProvider)
+	market.On("GetMarketData", mock.Anything, mock.Anything).Return(buyPriceData(service.RegionTheForge, buyPrices), nil)
+
+	return service.NewReprocessingService(sdeRepo, market)
+}
+
+func TestReprocessingServiceOreWithSkillsAndTax(t *testing.T) {
+	// Arrange
+	reprocessingService := newTestReprocessingService(t, map[int32]float64{1230: 15, 34: 5})
+
+	// Act
+	result, err := reprocessingService.CalculateReprocessing(context.Background(), service.ReprocessingRequest{
+		TypeID:                      1230, // Veldspar, portion size 100
+		Quantity:                    250,
+		ReprocessingLevel:           5,
+		ReprocessingEfficiencyLevel: 5,
+		OreProcessingLevel:          4,
+		ImplantBonus:                0.04,
+		TaxRate:                     0.05,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.IsOre)
+	assert.InDelta(t, 0.710424, result.Yield, 0.000001) // 0.5 * 1.15 * 1.10 * 1.08 * 1.04
+	assert.Equal(t, int64(200), result.ReprocessedQuantity)
+	assert.Equal(t, int64(50), result.LeftoverQuantity)
+	require.Len(t, result.Materials, 1)
+	assert.Equal(t, int64(568), result.Materials[0].Quantity) // floor(2 * 400 * 0.710424)
+	assert.InDelta(t, 2840.0, result.MaterialValue, 0.001)
+	assert.InDelta(t, 142.0, result.Tax, 0.001)
+	assert.InDelta(t, 3000.0, result.SellValue, 0.001)
+	assert.InDelta(t, -302.0, result.ISKDelta, 0.001)
+	assert.Equal(t, service.DecisionSell, result.Decision)
+}
+
+func TestReprocessingServiceModuleUsesScrapmetalProcessing(t *testing.T) {
+	// Arrange: the Rifter sells for less than its minerals
+	reprocessingService := newTestReprocessingService(t, map[int32]float64{587: 100000, 34: 5, 35: 10, 36: 40})
+
+	// Act
+	result, err := reprocessingService.CalculateReprocessing(context.Background(), service.ReprocessingRequest{
+		TypeID:                    587,
+		Quantity:                  1,
+		ReprocessingLevel:         5, // Ignored for non-ore items
+		ScrapmetalProcessingLevel: 5,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, result.IsOre)
+	assert.InDelta(t, 0.55, result.Yield, 0.000001)
+	require.Len(t, result.Materials, 3)
+	assert.Equal(t, int64(17600), result.Materials[0].Quantity)
+	assert.InDelta(t, 88000+33000+55000, result.ReprocessValue, 0.001)
+	assert.Equal(t, service.DecisionReprocess, result.Decision)
+	assert.Equal(t, service.PriceTypeBuy, result.PriceType)
+}
+
+func TestReprocessingServiceErrors(t *testing.T) {
+	reprocessingService := newTestReprocessingService(t, map[int32]float64{})
+
+	t.Run("should reject invalid skill levels", func(t *testing.T) {
+		_, err := reprocessingService.CalculateReprocessing(context.Background(), service.ReprocessingRequest{
+			TypeID: 1230, Quantity: 100, ReprocessingLevel: 6,
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidInput)
+	})
+
+	t.Run("should reject unknown price types", func(t *testing.T) {
+		_, err := reprocessingService.CalculateReprocessing(context.Background(), service.ReprocessingRequest{
+			TypeID: 1230, Quantity: 100, PriceType: "median",
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidInput)
+	})
+
+	t.Run("should report items without materials", func(t *testing.T) {
+		_, err := reprocessingService.CalculateReprocessing(context.Background(), service.ReprocessingRequest{
+			TypeID: 34, Quantity: 100,
+		})
+		assert.ErrorIs(t, err, service.ErrNotReprocessable)
+	})
+
+	t.Run("should report unknown items", func(t *testing.T) {
+		_, err := reprocessingService.CalculateReprocessing(context.Background(), service.ReprocessingRequest{
+			TypeID: 999999, Quantity: 1,
+		})
+		assert.ErrorIs(t, err, service.ErrItemNotFound)
+	})
+}
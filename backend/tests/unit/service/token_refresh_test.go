@@ -0,0 +1,184 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// grantError mimics the SSO client error for a rejected refresh token
+type grantError struct{}
+
+func (grantError) Error() string      { return "invalid grant: token revoked" }
+func (grantError) InvalidGrant() bool { return true }
+
+// rotatingSSO rotates the refresh token on every use and rejects reused ones
+type rotatingSSO struct {
+	mu        sync.Mutex
+	current   string
+	refreshes int32
+	revoked   bool
+}
+
+func (f *rotatingSSO) AuthorizeURL(state, codeChallenge string, scopes []string) string {
+	return ""
+}
+
+func (f *rotatingSSO) ExchangeCode(ctx context.Context, code, codeVerifier string) (*models.AuthToken, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (f *rotatingSSO) RefreshToken(ctx context.Context, refreshToken string) (*models.AuthToken, error) {
+	time.Sleep(10 * time.Millisecond) // Widen the window for concurrent refreshes
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.revoked || refreshToken != f.current {
+		return nil, grantError{}
+	}
+
+	n := atomic.AddInt32(&f.refreshes, 1)
+	f.current = fmt.Sprintf("refresh-%d", n)
+	return &models.AuthToken{
+		AccessToken:  fmt.Sprintf("access-%d", n),
+		RefreshToken: f.current,
+		ExpiresAt:    time.Now().Add(20 * time.Minute),
+		CharacterID:  90000001,
+	}, nil
+}
+
+func setupRefreshTest(t *testing.T, expiresIn time.Duration) (*rotatingSSO, service.TokenStore, *service.AuthService) {
+	t.Helper()
+	sso := &rotatingSSO{current: "refresh-0"}
+	store := service.NewMemoryTokenStore()
+	require.NoError(t, store.SaveToken(&models.AuthToken{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(expiresIn),
+		CharacterID:  90000001,
+	}))
+	return sso, store, service.NewAuthService(sso, store, nil)
+}
+
+func TestAuthServiceValidToken(t *testing.T) {
+	tests := []struct {
+		name              string
+		expiresIn         time.Duration
+		expectedToken     string
+		expectedRefreshes int32
+	}{
+		{
+			name:              "should reuse token that is still valid",
+			expiresIn:         10 * time.Minute,
+			expectedToken:     "access-0",
+			expectedRefreshes: 0,
+		},
+		{
+			name:              "should refresh token before it expires",
+			expiresIn:         time.Minute,
+			expectedToken:     "access-1",
+			expectedRefreshes: 1,
+		},
+		{
+			name:              "should refresh expired token",
+			expiresIn:         -time.Hour,
+			expectedToken:     "access-1",
+			expectedRefreshes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			sso, _, authService := setupRefreshTest(t, tt.expiresIn)
+
+			// Act
+			token, err := authService.ValidToken(context.Background(), 90000001)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedToken, token.AccessToken)
+			assert.Equal(t, tt.expectedRefreshes, atomic.LoadInt32(&sso.refreshes))
+		})
+	}
+}
+
+func TestAuthServiceValidTokenConcurrent(t *testing.T) {
+	// Arrange
+	sso, _, authService := setupRefreshTest(t, -time.Minute)
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = authService.ValidToken(context.Background(), 90000001)
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert: One refresh, the refresh token was never reused
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&sso.refreshes))
+}
+
+func TestAuthServiceRevokedToken(t *testing.T) {
+	// Arrange
+	sso, store, authService := setupRefreshTest(t, -time.Minute)
+	sso.revoked = true
+
+	// Act
+	_, err := authService.ValidToken(context.Background(), 90000001)
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrLoginRequired)
+	stored, err := store.GetToken(90000001)
+	require.NoError(t, err)
+	assert.True(t, stored.Revoked)
+
+	// Later calls fail without asking the SSO again
+	sso.revoked = false
+	_, err = authService.ValidToken(context.Background(), 90000001)
+	assert.ErrorIs(t, err, service.ErrLoginRequired)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&sso.refreshes))
+
+	characters, err := authService.Characters()
+	require.NoError(t, err)
+	require.Len(t, characters, 1)
+	assert.True(t, characters[0].Revoked)
+	assert.Empty(t, characters[0].RefreshToken)
+}
+
+func TestAuthServiceRefreshExpiring(t *testing.T) {
+	// Arrange
+	sso, store, authService := setupRefreshTest(t, 3*time.Minute)
+	require.NoError(t, store.SaveToken(&models.AuthToken{
+		AccessToken: "other", RefreshToken: "other", CharacterID: 90000002,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	// Act
+	refreshed, err := authService.RefreshExpiring(context.Background(), 5*time.Minute)
+
+	// Assert: Only the token expiring within the window was refreshed
+	require.NoError(t, err)
+	assert.Equal(t, 1, refreshed)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&sso.refreshes))
+
+	stored, err := store.GetToken(90000001)
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-1", stored.RefreshToken)
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allTradeSkills returns every trade skill at the given trained and active level
+func allTradeSkills(trained, active int32) *models.CharacterSkills {
+	skills := &models.CharacterSkills{CharacterID: 90000001}
+	for _, skillID := range []int32{
+		service.SkillTrade, service.SkillRetail, service.SkillWholesale, service.SkillTycoon,
+		service.SkillMarketing, service.SkillProcurement, service.SkillDaytrading,
+		service.SkillAccounting, service.SkillBrokerRelations, service.SkillMarginTrading,
+	} {
+		skills.Skills = append(skills.Skills, models.CharacterSkill{SkillID: skillID, TrainedSkillLevel: trained, ActiveSkillLevel: active})
+	}
+	return skills
+}
+
+func TestTradeProfileFromSkills(t *testing.T) {
+	tests := []struct {
+		name              string
+		skills            *models.CharacterSkills
+		expectedOrders    int
+		expectedRange     string
+		expectedBroker    float64
+		expectedSalesTax  float64
+		expectedEscrow    float64
+		expectedReduction float64
+	}{
+		{name: "should use base values without skills", skills: &models.CharacterSkills{CharacterID: 90000001}, expectedOrders: 5, expectedRange: "station", expectedBroker: 0.03, expectedSalesTax: 0.075, expectedEscrow: 1},
+		{name: "should apply all skills at level V", skills: allTradeSkills(5, 5), expectedOrders: 305, expectedRange: "region", expectedBroker: 0.015, expectedSalesTax: 0.03375, expectedEscrow: 0.237305, expectedReduction: 0.04125},
+		{name: "should use active levels of alpha clones", skills: allTradeSkills(5, 0), expectedOrders: 5, expectedRange: "station", expectedBroker: 0.03, expectedSalesTax: 0.075, expectedEscrow: 1},
+		{name: "should map remote ranges by level", skills: allTradeSkills(3, 3), expectedOrders: 185, expectedRange: "10", expectedBroker: 0.021, expectedSalesTax: 0.05025, expectedEscrow: 0.421875, expectedReduction: 0.02475},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			profile := service.TradeProfileFromSkills(tt.skills)
+
+			// Assert
+			assert.Equal(t, int32(90000001), profile.CharacterID)
+			assert.Equal(t, tt.expectedOrders, profile.MaxOrders)
+			assert.Equal(t, tt.expectedRange, profile.SellOrderRange)
+			assert.Equal(t, tt.expectedRange, profile.BuyOrderRange)
+			assert.Equal(t, tt.expectedRange, profile.ModifyOrderRange)
+			assert.InDelta(t, tt.expectedBroker, profile.BrokerFee, 1e-9)
+			assert.InDelta(t, tt.expectedBroker, profile.ModificationFeeRate, 1e-9)
+			assert.InDelta(t, tt.expectedSalesTax, profile.SalesTax, 1e-9)
+			assert.InDelta(t, tt.expectedReduction, profile.SalesTaxReduction, 1e-9)
+			assert.InDelta(t, tt.expectedEscrow, profile.EscrowRate, 1e-9)
+			assert.Equal(t, 300, profile.ModificationCooldown)
+		})
+	}
+}
+
+func TestCharacterServiceGetTradeProfile(t *testing.T) {
+	// Arrange
+	characters := newTestCharacterService(t, &fakeCharacterESI{})
+
+	// Act
+	profile, err := characters.GetTradeProfile(context.Background(), 90000001)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(90000001), profile.CharacterID)
+	assert.Equal(t, 5, profile.MaxOrders)
+}
+
+// fixedTradeProfiles serves one profile for every character
+type fixedTradeProfiles struct {
+	profile *models.TradeProfile
+}
+
+func (f *fixedTradeProfiles) GetTradeProfile(ctx context.Context, characterID int32) (*models.TradeProfile, error) {
+	return f.profile, nil
+}
+
+func TestUndercutMonitorUsesTradeProfiles(t *testing.T) {
+	// Arrange: Broker Relations and Accounting at V
+	profiles := &fixedTradeProfiles{profile: service.TradeProfileFromSkills(allTradeSkills(5, 5))}
+	monitor := newTestUndercutMonitor().
+		WithCostBasis(&fakeCostBasis{costs: map[int32]float64{34: 6}}).
+		WithTradeProfiles(profiles)
+
+	// Act
+	report, err := monitor.CheckCharacters(context.Background(), []int32{90000001}, false)
+
+	// Assert
+	require.NoError(t, err)
+	for _, order := range report.Orders {
+		switch order.OrderID {
+		case 1:
+			assert.InDelta(t, 216.97125, order.ExpectedProfit, 0.001)
+		case 2:
+			assert.InDelta(t, service.MinBrokerFee, order.ModificationFee, 0.001)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jitaSystem int32 = 30000142
+
+// fakeOrderBooks serves fixed regional order books per type
+type fakeOrderBooks struct {
+	books map[int32][]models.MarketOrder
+}
+
+func (f *fakeOrderBooks) GetMarketOrders(ctx context.Context, regionID int32, typeID int32) ([]models.MarketOrder, error) {
+	return f.books[typeID], nil
+}
+
+// fakeCostBasis returns fixed purchase costs
+type fakeCostBasis struct {
+	costs map[int32]float64
+}
+
+func (f *fakeCostBasis) AverageCosts(characterID int32) (map[int32]float64, error) {
+	return f.costs, nil
+}
+
+// recordingNotifier collects notified orders
+type recordingNotifier struct {
+	mu    sync.Mutex
+	calls [][]models.OrderUndercut
+}
+
+func (r *recordingNotifier) NotifyUndercuts(ctx context.Context, undercuts []models.OrderUndercut) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, undercuts)
+	return nil
+}
+
+func (r *recordingNotifier) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+// newTestUndercutMonitor sets up an undercut sell order and an outbid buy order in
+// Jita plus a leading sell order in Amarr
+func newTestUndercutMonitor() *service.UndercutMonitor {
+	characterData := &stubCharacterData{orders: map[int32][]models.CharacterOrder{
+		90000001: {
+			{OrderID: 1, TypeID: 34, RegionID: 10000002, LocationID: jitaStation, Price: 10, VolumeRemain: 100},
+			{OrderID: 2, TypeID: 35, RegionID: 10000002, LocationID: jitaStation, Price: 5, VolumeRemain: 1000, IsBuyOrder: true, Range: "station"},
+			{OrderID: 3, TypeID: 34, RegionID: 10000002, LocationID: amarrStation, Price: 8.5, VolumeRemain: 10},
+		},
+	}}
+	books := &fakeOrderBooks{books: map[int32][]models.MarketOrder{
+		34: {
+			{OrderID: 1, TypeID: 34, LocationID: jitaStation, SystemID: jitaSystem, Price: 10, VolumeRemain: 100},
+			{OrderID: 3, TypeID: 34, LocationID: amarrStation, Price: 8.5, VolumeRemain: 10},
+			{OrderID: 101, TypeID: 34, LocationID: jitaStation, SystemID: jitaSystem, Price: 9.5, VolumeRemain: 50},
+			{OrderID: 102, TypeID: 34, LocationID: jitaStation, SystemID: jitaSystem, Price: 8, VolumeRemain: 500, IsBuyOrder: true, Range: "station"},
+			{OrderID: 103, TypeID: 34, LocationID: amarrStation, Price: 9, VolumeRemain: 50},
+		},
+		35: {
+			{OrderID: 2, TypeID: 35, LocationID: jitaStation, SystemID: jitaSystem, Price: 5, VolumeRemain: 1000, IsBuyOrder: true, Range: "station"},
+			{OrderID: 201, TypeID: 35, LocationID: 60000001, SystemID: 30000001, Price: 5.5, VolumeRemain: 1000, IsBuyOrder: true, Range: "region"},
+			{OrderID: 202, TypeID: 35, LocationID: 60000002, SystemID: 30000002, Price: 6, VolumeRemain: 1000, IsBuyOrder: true, Range: "station"},
+			{OrderID: 203, TypeID: 35, LocationID: jitaStation, SystemID: jitaSystem, Price: 7, VolumeRemain: 1000},
+		},
+	}}
+	return service.NewUndercutMonitor(characterData, books)
+}
+
+func TestUndercutMonitorCheckCharacters(t *testing.T) {
+	// Arrange
+	monitor := newTestUndercutMonitor().WithCostBasis(&fakeCostBasis{costs: map[int32]float64{34: 6}})
+
+	// Act
+	report, err := monitor.CheckCharacters(context.Background(), []int32{90000001}, true)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.CheckedOrders)
+	assert.Equal(t, 2, report.Undercut)
+	require.Len(t, report.Orders, 3)
+
+	orders := make(map[int64]models.OrderUndercut)
+	for _, order := range report.Orders {
+		orders[order.OrderID] = order
+	}
+
+	// The sell order is undercut by 0.50, reselling at 9.49 beats the cost of 6
+	sell := orders[1]
+	assert.Equal(t, models.OrderStatusUndercut, sell.Status)
+	assert.Equal(t, int64(101), sell.CompetitorOrderID)
+	assert.InDelta(t, 0.5, sell.Difference, 0.0001)
+	assert.InDelta(t, 5, sell.DifferencePercent, 0.0001)
+	assert.InDelta(t, 9.49, sell.SuggestedPrice, 0.0001)
+	assert.InDelta(t, service.MinBrokerFee, sell.ModificationFee, 0.0001)
+	assert.InDelta(t, 177.825, sell.ExpectedProfit, 0.001)
+	assert.True(t, sell.RepriceProfitable)
+
+	// The region wide buy order covers Jita, the station order elsewhere does not
+	buy := orders[2]
+	assert.Equal(t, models.OrderStatusOutbid, buy.Status)
+	assert.Equal(t, int64(201), buy.CompetitorOrderID)
+	assert.InDelta(t, 5.51, buy.SuggestedPrice, 0.0001)
+	assert.InDelta(t, 165.3, buy.ModificationFee, 0.001)
+	assert.InDelta(t, 589.7, buy.ExpectedProfit, 0.001)
+	assert.True(t, buy.RepriceProfitable)
+
+	// Competition at other stations does not matter for sell orders
+	assert.Equal(t, models.OrderStatusLeading, orders[3].Status)
+}
+
+func TestUndercutMonitorFallsBackToBuyOrders(t *testing.T) {
+	// Arrange: No purchase cost, a reprice must beat selling into the 8.00 buy order
+	monitor := newTestUndercutMonitor().WithFees(0.03, 0.075, 0)
+
+	// Act
+	report, err := monitor.CheckCharacters(context.Background(), []int32{90000001}, false)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Orders, 2)
+	for _, order := range report.Orders {
+		if order.OrderID == 1 {
+			assert.True(t, order.ProfitKnown)
+			assert.InDelta(t, 37.825, order.ExpectedProfit, 0.001)
+		}
+	}
+}
+
+func TestUndercutMonitorReportsFailingCharacters(t *testing.T) {
+	// Arrange
+	monitor := service.NewUndercutMonitor(&stubCharacterData{failing: map[int32]bool{90000002: true}}, &fakeOrderBooks{})
+
+	// Act
+	report, err := monitor.CheckCharacters(context.Background(), []int32{90000002}, false)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, int32(90000002), report.Errors[0].CharacterID)
+}
+
+func TestUndercutMonitorNotifiesOnce(t *testing.T) {
+	// Arrange
+	notifier := &recordingNotifier{}
+	tokens := &fakeTokenSource{fakeTokenProvider{tokens: map[int32]string{90000001: "persisted-main"}}}
+	monitor := newTestUndercutMonitor().WithNotifier(notifier).WithCharacters(tokens)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	monitor.Start(ctx, 10*time.Millisecond)
+
+	// Assert: Later checks find the same competitors and stay quiet
+	require.Eventually(t, func() bool { return notifier.callCount() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, notifier.callCount())
+	assert.Len(t, notifier.calls[0], 2)
+}
+
+func TestPriceTick(t *testing.T) {
+	tests := []struct {
+		price    float64
+		expected float64
+	}{
+		{price: 5.5, expected: 0.01},
+		{price: 1234.5, expected: 1},
+		{price: 98765432, expected: 10000},
+		{price: 0, expected: 0.01},
+	}
+
+	for _, tt := range tests {
+		assert.InDelta(t, tt.expected, service.PriceTick(tt.price), 1e-9)
+	}
+}
@@ -0,0 +1,205 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+	"eve-profit2/tests/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVariantService(t *testing.T, sellPrices map[int32]float64) (*service.VariantService, *MockMarketDataProvider) {
+	sdeRepo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { sdeRepo.Close() })
+
+	market := new(MockMarketDataProvider)
+	market.On("GetMarketData", mock.Anything, mock.Anything).Return(sellPriceData(service.RegionTheForge, sellPrices), nil)
+
+	return service.NewVariantService(sdeRepo, market), market
+}
+
+func findVariant(result *service.VariantPriceResult, typeID int32) *service.VariantPrice {
+	for i := range result.Variants {
+		if result.Variants[i].TypeID == typeID {
+			return &result.Variants[i]
+		}
+	}
+	return nil
+}
+
+func TestVariantServiceHighlightsCheaperEqualOrBetterVariants(t *testing.T) {
+	// Arrange: the named and the faction cannon beat the T1 and cost less
+	variantService, _ := newTestVariantService(t, map[int32]float64{2873: 10000, 9071: 5000, 2881: 50000, 13777: 8000})
+
+	// Act
+	result, err := variantService.FindVariants(context.Background(), service.VariantRequest{TypeID: 2881})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(2873), result.BaseTypeID)
+	assert.Equal(t, 10000.0, result.BasePrice)
+	assert.Equal(t, service.PriceTypeSell, result.PriceType)
+	assert.Equal(t, "Jita", result.Hub.Name)
+	require.Len(t, result.Variants, 4)
+	assert.Equal(t, []int32{2873, 9071, 2881, 13777}, []int32{
+		result.Variants[0].TypeID, result.Variants[1].TypeID, result.Variants[2].TypeID, result.Variants[3].TypeID,
+	}) // Sorted by meta level
+
+	named := findVariant(result, 9071)
+	assert.True(t, named.Cheaper)
+	assert.True(t, named.EqualOrBetter)
+	assert.True(t, named.Highlight)
+	assert.Equal(t, -5000.0, named.PriceDelta)
+
+	faction := findVariant(result, 13777)
+	assert.True(t, faction.Highlight)
+	assert.Equal(t, "Faction", faction.MetaGroupName)
+	assert.Equal(t, int32(8), faction.MetaLevel)
+
+	techII := findVariant(result, 2881)
+	assert.False(t, techII.EqualOrBetter) // Needs more CPU and powergrid
+	assert.False(t, techII.Highlight)
+	assert.Equal(t, int32(2), techII.TechLevel)
+
+	base := findVariant(result, 2873)
+	assert.True(t, base.IsBase)
+	assert.False(t, base.Highlight)
+
+	assert.Equal(t, int32(9071), result.BestValueTypeID)
+}
+
+func TestVariantServiceAttributeDeltas(t *testing.T) {
+	// Arrange
+	variantService, _ := newTestVariantService(t, map[int32]float64{2873: 10000})
+
+	// Act
+	result, err := variantService.FindVariants(context.Background(), service.VariantRequest{TypeID: 2873})
+
+	// Assert
+	require.NoError(t, err)
+	techII := findVariant(result, 2881)
+	require.Len(t, techII.Attributes, 4) // Powergrid, CPU, rate of fire and damage differ
+
+	deltas := make(map[int32]service.AttributeDelta)
+	for _, delta := range techII.Attributes {
+		deltas[delta.AttributeID] = delta
+	}
+	assert.Equal(t, service.ComparisonWorse, deltas[50].Comparison)
+	assert.InDelta(t, 1.0, deltas[50].Delta, 0.0001)
+	assert.Equal(t, service.ComparisonBetter, deltas[51].Comparison) // Lower rate of fire is better
+	assert.InDelta(t, -10.0, deltas[51].DeltaPercent, 0.0001)
+	assert.Equal(t, "s", deltas[51].Unit)
+	assert.Equal(t, service.ComparisonBetter, deltas[64].Comparison)
+
+	named := findVariant(result, 9071)
+	for _, delta := range named.Attributes {
+		if delta.AttributeID == 30 {
+			assert.Equal(t, service.ComparisonEqual, delta.Comparison)
+		}
+	}
+}
+
+func TestVariantServiceWithoutPrices(t *testing.T) {
+	// Arrange: nobody sells the named cannon
+	variantService, _ := newTestVariantService(t, map[int32]float64{2873: 10000})
+
+	// Act
+	result, err := variantService.FindVariants(context.Background(), service.VariantRequest{TypeID: 2873})
+
+	// Assert
+	require.NoError(t, err)
+	named := findVariant(result, 9071)
+	assert.False(t, named.HasPrice)
+	assert.False(t, named.Cheaper)
+	assert.False(t, named.Highlight)
+	assert.Zero(t, named.PriceDelta)
+	assert.Zero(t, result.BestValueTypeID)
+}
+
+// unlistedVariantRepository adds a variant without market group to the fixture cannon family
+type unlistedVariantRepository struct {
+	*repository.SDERepository
+}
+
+func (r unlistedVariantRepository) GetTypeVariants(typeID int32) ([]*repository.SDETypeVariant, error) {
+	variants, err := r.SDERepository.GetTypeVariants(typeID)
+	if err != nil {
+		return nil, err
+	}
+	return append(variants, &repository.SDETypeVariant{
+		TypeID: 99001, TypeName: "Unlisted 200mm AutoCannon", ParentTypeID: 2873, MetaGroupID: repository.MetaGroupOfficer, MetaGroupName: "Officer",
+	}), nil
+}
+
+func TestVariantServiceSkipsPricingOfUnlistedVariants(t *testing.T) {
+	// Arrange
+	sdeRepo, err := repository.NewSDERepository(fixtures.CreateTestSDEDatabase(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { sdeRepo.Close() })
+
+	market := new(MockMarketDataProvider)
+	market.On("GetMarketData", mock.Anything, mock.Anything).Return(sellPriceData(service.RegionTheForge, map[int32]float64{2873: 10000}), nil)
+	variantService := service.NewVariantService(unlistedVariantRepository{sdeRepo}, market)
+
+	// Act
+	result, err := variantService.FindVariants(context.Background(), service.VariantRequest{TypeID: 2873})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, result.Variants, 5)
+	unlisted := findVariant(result, 99001)
+	require.NotNil(t, unlisted)
+	assert.False(t, unlisted.HasPrice)
+	assert.False(t, unlisted.Highlight)
+	market.AssertCalled(t, "GetMarketData", mock.Anything, mock.MatchedBy(func(req service.MarketDataRequest) bool {
+		return len(req.TypeIDs) == 4 && !slices.Contains(req.TypeIDs, 99001)
+	}))
+}
+
+func TestVariantServiceResolvesHubByName(t *testing.T) {
+	// Arrange
+	variantService, market := newTestVariantService(t, map[int32]float64{})
+
+	// Act
+	result, err := variantService.FindVariants(context.Background(), service.VariantRequest{TypeID: 2873, Hub: "amarr"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, service.RegionDomain, result.Hub.RegionID)
+	market.AssertCalled(t, "GetMarketData", mock.Anything, mock.MatchedBy(func(req service.MarketDataRequest) bool {
+		return req.RegionID == service.RegionDomain && len(req.TypeIDs) == 4
+	}))
+}
+
+func TestVariantServiceInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           service.VariantRequest
+		expectedError error
+	}{
+		{"invalid type", service.VariantRequest{TypeID: 0}, service.ErrInvalidInput},
+		{"unknown hub", service.VariantRequest{TypeID: 2873, Hub: "Perimeter"}, service.ErrInvalidInput},
+		{"invalid price type", service.VariantRequest{TypeID: 2873, PriceType: "average"}, service.ErrInvalidInput},
+		{"unknown type", service.VariantRequest{TypeID: 999999}, service.ErrItemNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			variantService, _ := newTestVariantService(t, map[int32]float64{})
+
+			// Act
+			_, err := variantService.FindVariants(context.Background(), tt.req)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWalletESI serves a journal in pages of two and transactions in batches of two, newest first
+type fakeWalletESI struct {
+	journal      []models.WalletJournalEntry // Newest first
+	transactions []models.WalletTransaction  // Newest first
+	journalPages []int
+	fromIDs      []int64
+	failPages    bool // Fails every journal page but the first
+	failBatches  bool // Fails every transaction batch but the newest
+}
+
+func (f *fakeWalletESI) GetWalletJournal(ctx context.Context, characterID int32, accessToken string, page int) ([]models.WalletJournalEntry, int, error) {
+	f.journalPages = append(f.journalPages, page)
+	if f.failPages && page > 1 {
+		return nil, 0, errors.New("ESI unavailable")
+	}
+	pages := (len(f.journal) + 1) / 2
+	start := (page - 1) * 2
+	if start >= len(f.journal) {
+		return nil, pages, nil
+	}
+	return f.journal[start:min(start+2, len(f.journal))], pages, nil
+}
+
+func (f *fakeWalletESI) GetWalletTransactions(ctx context.Context, characterID int32, accessToken string, fromID int64) ([]models.WalletTransaction, error) {
+	f.fromIDs = append(f.fromIDs, fromID)
+	if f.failBatches && fromID != 0 {
+		return nil, errors.New("ESI unavailable")
+	}
+	var batch []models.WalletTransaction
+	for _, transaction := range f.transactions {
+		if (fromID == 0 || transaction.TransactionID <= fromID) && len(batch) < 2 {
+			batch = append(batch, transaction)
+		}
+	}
+	return batch, nil
+}
+
+// fakeTokenSource lists the characters of fakeTokenProvider
+type fakeTokenSource struct {
+	fakeTokenProvider
+}
+
+func (f *fakeTokenSource) Characters() ([]*models.AuthToken, error) {
+	var tokens []*models.AuthToken
+	for characterID := range f.tokens {
+		tokens = append(tokens, &models.AuthToken{CharacterID: characterID})
+	}
+	return tokens, nil
+}
+
+// fakeMarketData prices every type at a fixed sell price
+type fakeMarketData struct {
+	sellMin float64
+}
+
+func (f *fakeMarketData) GetMarketData(ctx context.Context, req service.MarketDataRequest) (*service.MarketDataResponse, error) {
+	response := &service.MarketDataResponse{RegionID: req.RegionID, Data: make(map[int32]*models.ItemPrice)}
+	for _, typeID := range req.TypeIDs {
+		response.Data[typeID] = &models.ItemPrice{TypeID: typeID, SellMin: f.sellMin}
+	}
+	return response, nil
+}
+
+func newTestWalletService(t *testing.T, esiClient service.WalletESIClient) *service.WalletService {
+	t.Helper()
+	db, err := repository.OpenAppDatabase(filepath.Join(t.TempDir(), "app.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := repository.NewWalletStore(db)
+	require.NoError(t, err)
+	tokens := &fakeTokenSource{fakeTokenProvider{tokens: map[int32]string{90000001: "persisted-main"}}}
+	return service.NewWalletService(store, esiClient, tokens)
+}
+
+func TestWalletServiceSyncIsIncremental(t *testing.T) {
+	// Arrange
+	transactions, journal := tradingHistory()
+	esiClient := &fakeWalletESI{
+		journal:      []models.WalletJournalEntry{journal[4], journal[3], journal[2], journal[1], journal[0]},
+		transactions: []models.WalletTransaction{transactions[3], transactions[0], transactions[2], transactions[1]},
+	}
+	wallet := newTestWalletService(t, esiClient)
+
+	// Act
+	first, err := wallet.SyncCharacter(context.Background(), 90000001)
+	require.NoError(t, err)
+	esiClient.journalPages, esiClient.fromIDs = nil, nil
+	second, err := wallet.SyncCharacter(context.Background(), 90000001)
+	require.NoError(t, err)
+
+	// Assert: The first sync walks the whole history, the second stops at stored entries
+	assert.Equal(t, 5, first.JournalEntries)
+	assert.Equal(t, 4, first.Transactions)
+	assert.Zero(t, second.JournalEntries)
+	assert.Zero(t, second.Transactions)
+	assert.Equal(t, []int{1}, esiClient.journalPages)
+	assert.Equal(t, []int64{0}, esiClient.fromIDs)
+}
+
+func TestWalletServiceSyncBackfillsAfterFailedWalk(t *testing.T) {
+	tests := []struct {
+		name            string
+		client          func(*fakeWalletESI)
+		expectedJournal int // Entries fetched by the second sync
+	}{
+		{name: "should refetch journal pages after page 2 failed", client: func(f *fakeWalletESI) { f.failPages = true }, expectedJournal: 5},
+		{name: "should refetch transactions after an older batch failed", client: func(f *fakeWalletESI) { f.failBatches = true }, expectedJournal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			transactions, journal := tradingHistory()
+			esiClient := &fakeWalletESI{
+				journal:      []models.WalletJournalEntry{journal[4], journal[3], journal[2], journal[1], journal[0]},
+				transactions: []models.WalletTransaction{transactions[3], transactions[0], transactions[2], transactions[1]},
+			}
+			tt.client(esiClient)
+			wallet := newTestWalletService(t, esiClient)
+
+			// Act
+			_, failedErr := wallet.SyncCharacter(context.Background(), 90000001)
+			esiClient.failPages, esiClient.failBatches = false, false
+			synced, err := wallet.SyncCharacter(context.Background(), 90000001)
+
+			// Assert: Nothing of the failed walk was kept, so the next sync fetches all of it
+			assert.Error(t, failedErr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedJournal, synced.JournalEntries)
+			assert.Equal(t, 4, synced.Transactions)
+		})
+	}
+}
+
+func TestWalletServiceGetProfitLoss(t *testing.T) {
+	// Arrange
+	transactions, journal := tradingHistory()
+	esiClient := &fakeWalletESI{journal: journal, transactions: []models.WalletTransaction{transactions[3], transactions[0], transactions[2], transactions[1]}}
+	wallet := newTestWalletService(t, esiClient).WithMarketData(&fakeMarketData{sellMin: 14})
+	synced, err := wallet.SyncAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, synced)
+
+	// Act
+	report, err := wallet.GetProfitLoss(context.Background(), 90000001, service.CostMethodFIFO, 0)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(90000001), report.CharacterID)
+	assert.Equal(t, service.DefaultPriceRegionID, report.PriceRegionID)
+	assert.InDelta(t, 545, report.Realized.Profit, 0.001)
+	assert.InDelta(t, 100, report.UnrealizedProfit, 0.001)
+}
+
+func TestWalletServiceSyncRequiresToken(t *testing.T) {
+	// Arrange
+	wallet := newTestWalletService(t, &fakeWalletESI{})
+
+	// Act
+	_, err := wallet.SyncCharacter(context.Background(), 90000002)
+
+	// Assert
+	assert.ErrorIs(t, err, service.ErrUnauthorized)
+}
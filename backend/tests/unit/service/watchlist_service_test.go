@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"eve-profit2/internal/models"
+	"eve-profit2/internal/repository"
+	"eve-profit2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSearch remembers the options of the last search
+type recordingSearch struct {
+	opts service.ItemSearchOptions
+}
+
+func (s *recordingSearch) Search(opts service.ItemSearchOptions) (*service.ItemSearchResult, error) {
+	s.opts = opts
+	return &service.ItemSearchResult{Items: []*models.Item{}, Limit: opts.Limit, Offset: opts.Offset}, nil
+}
+
+func newTestWatchlistService(t *testing.T, market service.MarketDataProvider) *service.WatchlistService {
+	t.Helper()
+	db, err := repository.OpenAppDatabase(filepath.Join(t.TempDir(), "app.sqlite"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	store, err := repository.NewWatchlistStore(db)
+	require.NoError(t, err)
+	return service.NewWatchlistService(store, market)
+}
+
+func TestWatchlistServicePricesWatchlistWithOneRequestPerRegion(t *testing.T) {
+	// Arrange
+	market := &snapshotMarketData{
+		orders:  sellOrders(4.5, 6),
+		failing: map[int32]bool{service.RegionHeimatar: true},
+	}
+	watchlists := newTestWatchlistService(t, market).
+		WithItems(&fakeItems{items: map[int32]models.Item{
+			34: {TypeID: 34, TypeName: "Tritanium", MarketGroup: 1857},
+			35: {TypeID: 35, TypeName: "Pyerite", MarketGroup: 1857},
+		}})
+	created, err := watchlists.CreateWatchlist(context.Background(), 90000001, models.Watchlist{Name: "Jita", Items: []models.WatchlistItem{
+		{TypeID: 34, StationID: jitaStation, Note: " undercut target "},
+		{TypeID: 34},
+		{TypeID: 35},
+		{TypeID: 34, RegionID: service.RegionHeimatar},
+	}})
+	require.NoError(t, err)
+	market.requests = nil
+
+	// Act
+	list, err := watchlists.GetWatchlist(context.Background(), 90000001, created.WatchlistID)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, market.requests, 2) // The Forge and Heimatar
+	for _, req := range market.requests {
+		if req.RegionID == service.RegionTheForge {
+			assert.Equal(t, []int32{34, 35}, req.TypeIDs)
+		}
+	}
+	assert.Equal(t, 4, list.ItemCount)
+	items := list.Items
+	assert.Equal(t, service.RegionTheForge, items[0].RegionID) // Region of the hub station
+	assert.Equal(t, "undercut target", items[0].Note)
+	assert.Equal(t, "Tritanium", items[0].TypeName)
+	require.NotNil(t, items[0].Price)
+	assert.InDelta(t, 4.5, items[0].Price.SellMin, 0.0001)
+	assert.Equal(t, int64(20), items[0].Price.SellVolume)
+	require.NotNil(t, items[1].Price)
+	assert.InDelta(t, 1, items[1].Price.SellMin, 0.0001) // Whole region
+	assert.NotNil(t, items[2].Price)
+	assert.Nil(t, items[3].Price) // Market data unavailable
+}
+
+func TestWatchlistServiceListsWatchlistsWithoutPrices(t *testing.T) {
+	// Arrange
+	market := &snapshotMarketData{orders: sellOrders(4.5)}
+	watchlists := newTestWatchlistService(t, market)
+	for _, name := range []string{"Minerals", "Empty"} {
+		list := models.Watchlist{Name: name}
+		if name == "Minerals" {
+			list.Items = []models.WatchlistItem{{TypeID: 34}, {TypeID: 35}}
+		}
+		_, err := watchlists.CreateWatchlist(context.Background(), 90000001, list)
+		require.NoError(t, err)
+	}
+	market.requests = nil
+
+	// Act
+	lists, err := watchlists.ListWatchlists(90000001)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, market.requests)
+	require.Len(t, lists, 2)
+	assert.Equal(t, "Minerals", lists[0].Name)
+	assert.Equal(t, 2, lists[0].ItemCount)
+	assert.Nil(t, lists[0].Items)
+	assert.Equal(t, 0, lists[1].ItemCount)
+}
+
+func TestWatchlistServiceValidatesWatchlists(t *testing.T) {
+	watchlists := newTestWatchlistService(t, &snapshotMarketData{}).WithItems(&fakeItems{items: map[int32]models.Item{
+		34:    {TypeID: 34, MarketGroup: 1857},
+		29668: {TypeID: 29668}, // No market group
+	}})
+
+	tests := []struct {
+		name string
+		list models.Watchlist
+	}{
+		{name: "should require a name", list: models.Watchlist{Name: "  "}},
+		{name: "should reject long names", list: models.Watchlist{Name: strings.Repeat("a", 101)}},
+		{name: "should reject invalid types", list: models.Watchlist{Name: "a", Items: []models.WatchlistItem{{TypeID: 0}}}},
+		{name: "should reject unknown types", list: models.Watchlist{Name: "a", Items: []models.WatchlistItem{{TypeID: 999999}}}},
+		{name: "should reject types off the market", list: models.Watchlist{Name: "a", Items: []models.WatchlistItem{{TypeID: 34}, {TypeID: 29668}}}},
+		{name: "should require the region of unknown stations", list: models.Watchlist{Name: "a", Items: []models.WatchlistItem{{TypeID: 34, StationID: 1022734985679}}}},
+		{name: "should limit the items", list: models.Watchlist{Name: "a", Items: make([]models.WatchlistItem, service.MaxWatchlistItems+1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := watchlists.CreateWatchlist(context.Background(), 90000001, tt.list)
+
+			// Assert
+			assert.ErrorIs(t, err, service.ErrInvalidInput)
+		})
+	}
+}
+
+func TestWatchlistServiceUpdatesOwnWatchlistsOnly(t *testing.T) {
+	// Arrange
+	watchlists := newTestWatchlistService(t, &snapshotMarketData{})
+	created, err := watchlists.CreateWatchlist(context.Background(), 90000001, models.Watchlist{Name: "Minerals"})
+	require.NoError(t, err)
+
+	// Act
+	_, otherErr := watchlists.UpdateWatchlist(context.Background(), 90000002, created.WatchlistID, models.Watchlist{Name: "Mine"})
+	updated, err := watchlists.UpdateWatchlist(context.Background(), 90000001, created.WatchlistID,
+		models.Watchlist{Name: "Minerals", Items: []models.WatchlistItem{{TypeID: 34}}})
+
+	// Assert
+	assert.ErrorIs(t, otherErr, service.ErrWatchlistNotFound)
+	require.NoError(t, err)
+	require.Len(t, updated.Items, 1)
+	assert.Equal(t, service.RegionTheForge, updated.Items[0].RegionID)
+}
+
+func TestWatchlistServiceRunsSavedSearches(t *testing.T) {
+	// Arrange
+	search := &recordingSearch{}
+	watchlists := newTestWatchlistService(t, &snapshotMarketData{}).WithSearch(search)
+	marketable := true
+	saved, err := watchlists.CreateSearch(90000001, models.SavedSearch{Query: " antimatter charge ", CategoryID: 8, Marketable: &marketable})
+	require.NoError(t, err)
+
+	// Act
+	_, runErr := watchlists.RunSearch(90000001, saved.SearchID, 20, 40)
+	_, otherErr := watchlists.RunSearch(90000002, saved.SearchID, 20, 0)
+	_, invalidErr := watchlists.CreateSearch(90000001, models.SavedSearch{Query: "--"})
+
+	// Assert
+	assert.Equal(t, "antimatter charge", saved.Name) // Named after the query
+	require.NoError(t, runErr)
+	assert.Equal(t, "antimatter charge", search.opts.Query)
+	assert.Equal(t, int32(8), search.opts.CategoryID)
+	assert.Equal(t, 20, search.opts.Limit)
+	assert.Equal(t, 40, search.opts.Offset)
+	require.NotNil(t, search.opts.Published)
+	assert.True(t, *search.opts.Published) // Published types by default
+	require.NotNil(t, search.opts.Marketable)
+	assert.ErrorIs(t, otherErr, service.ErrSavedSearchNotFound)
+	assert.ErrorIs(t, invalidErr, service.ErrInvalidInput)
+}